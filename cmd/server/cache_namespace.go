@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+)
+
+// cacheMigrationTimeout bounds a whole namespace migration
+const cacheMigrationTimeout = 10 * time.Minute
+
+// runMigrateCacheNamespaceCommand moves the application's Redis keys from the
+// --from namespace prefix into the configured namespace, writes a JSON report
+// to out and returns the process exit code
+func runMigrateCacheNamespaceCommand(cfg *config.Config, args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("migrate-cache-namespace", flag.ContinueOnError)
+	from := flags.String("from", "", `key prefix to move keys from, e.g. "staging:"; empty moves keys written without a namespace`)
+	dryRun := flags.Bool("dry-run", false, "count the keys that would be moved without moving them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheMigrationTimeout)
+	defer cancel()
+
+	migration, err := cache.MigrateNamespace(ctx, cfg, *from, *dryRun)
+	if migration != nil {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(migration); encodeErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write migration report: %v\n", encodeErr)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cache namespace migration failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
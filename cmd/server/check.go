@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/contentpolicy"
+	"demo-go/internal/domain"
+	"demo-go/internal/hygiene"
+	"demo-go/internal/keys"
+	"demo-go/internal/middleware"
+	"demo-go/internal/oidc"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+	"demo-go/internal/siem"
+	"demo-go/internal/snapshot"
+)
+
+// Check outcomes. Warnings do not fail the check command.
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+)
+
+// minJWTSecretLength is the shortest HS256 secret accepted without a warning
+const minJWTSecretLength = 32
+
+// CheckResult is the outcome of one pre-flight check
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Detail     string  `json:"detail,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// CheckReport is printed by `server check`
+type CheckReport struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// runCheckCommand validates configuration and dependencies without starting
+// the server, writes a JSON report to out and returns the process exit code
+func runCheckCommand(cfg *config.Config, out io.Writer) int {
+	report := runChecks(cfg)
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write check report: %v\n", err)
+		return 1
+	}
+
+	if report.Status == CheckFail {
+		return 1
+	}
+	return 0
+}
+
+func runChecks(cfg *config.Config) CheckReport {
+	checks := []struct {
+		name string
+		run  func(*config.Config) (string, string)
+	}{
+		{"config", checkConfig},
+		{"jwt", checkJWT},
+		{"secrets_hygiene", checkSecretsHygiene},
+		{"mongodb", checkMongoDB},
+		{"redis", checkRedis},
+	}
+
+	report := CheckReport{Status: CheckPass, CheckedAt: time.Now().UTC()}
+	for _, check := range checks {
+		start := time.Now()
+		status, detail := check.run(cfg)
+		report.Checks = append(report.Checks, CheckResult{
+			Name:       check.name,
+			Status:     status,
+			Detail:     detail,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		})
+
+		switch {
+		case status == CheckFail:
+			report.Status = CheckFail
+		case status == CheckWarn && report.Status == CheckPass:
+			report.Status = CheckWarn
+		}
+	}
+	return report
+}
+
+// checkConfig catches settings the server would reject or misapply at startup
+func checkConfig(cfg *config.Config) (string, string) {
+	var problems []string
+
+	switch repositoryType := os.Getenv("REPOSITORY_TYPE"); repositoryType {
+	case "", "memory", "mongodb":
+	default:
+		problems = append(problems, "unsupported REPOSITORY_TYPE: "+repositoryType)
+	}
+	switch cfg.JWT.TokenFormat {
+	case config.TokenFormatJWT, config.TokenFormatOpaque:
+	default:
+		problems = append(problems, "unsupported TOKEN_FORMAT: "+cfg.JWT.TokenFormat)
+	}
+	if _, err := cache.KeyPrefix(cfg.Cache.Namespace); err != nil {
+		problems = append(problems, "invalid cache namespace: "+err.Error())
+	}
+	if _, err := service.ParseCacheStrategies(cfg.Cache.Strategies); err != nil {
+		problems = append(problems, "invalid CACHE_STRATEGIES: "+err.Error())
+	}
+	if err := service.ValidateTrustedIssuers(cfg.JWT); err != nil {
+		problems = append(problems, "invalid JWT_TRUSTED_ISSUERS: "+err.Error())
+	}
+	if err := middleware.NewJWTMiddleware(nil).AddSkipRules(cfg.JWT.SkipPaths...); err != nil {
+		problems = append(problems, "invalid JWT_SKIP_PATHS: "+err.Error())
+	}
+	if cfg.ContentPolicy.Enabled {
+		if _, err := contentpolicy.New(cfg.ContentPolicy); err != nil {
+			problems = append(problems, "invalid content policy: "+err.Error())
+		}
+	}
+	if cfg.SIEM.Enabled {
+		if _, err := siem.NewSink(cfg.SIEM); err != nil {
+			problems = append(problems, "invalid SIEM settings: "+err.Error())
+		}
+	}
+	if cfg.OIDC.Enabled {
+		if err := oidc.ValidateConfig(cfg.OIDC); err != nil {
+			problems = append(problems, "invalid OIDC settings: "+err.Error())
+		}
+	}
+	if cfg.Transfer.Enabled && cfg.Transfer.SigningKey == "" {
+		problems = append(problems, "ACCOUNT_TRANSFER_SIGNING_KEY is required when account transfer is enabled")
+	}
+	if cfg.Snapshot.Enabled {
+		if _, err := snapshot.NewStore(cfg.Snapshot); err != nil {
+			problems = append(problems, "invalid snapshot storage: "+err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return CheckFail, strings.Join(problems, "; ")
+	}
+
+	if cfg.Server.RequestTimeout > 0 && cfg.Server.WriteTimeout > 0 && cfg.Server.RequestTimeout >= cfg.Server.WriteTimeout {
+		return CheckWarn, "SERVER_REQUEST_TIMEOUT is not below SERVER_WRITE_TIMEOUT, so timed out requests get no response"
+	}
+	return CheckPass, ""
+}
+
+// checkJWT signs and validates a probe token with the configured key and claims
+func checkJWT(cfg *config.Config) (string, string) {
+	switch cfg.JWT.TokenFormat {
+	case config.TokenFormatJWT:
+	case config.TokenFormatOpaque:
+		return CheckSkip, "opaque tokens are not signed"
+	default:
+		return CheckSkip, "unsupported token format"
+	}
+	if cfg.JWT.Keys.Source != config.JWTKeySourceStatic {
+		return checkJWTKeySource(cfg)
+	}
+	if cfg.JWT.SecretKey == "" {
+		return CheckFail, "JWT_SECRET is empty"
+	}
+
+	tokens := service.NewJWTTokenService(cfg)
+	token, err := tokens.GenerateToken(&domain.User{ID: "preflight", Email: "preflight@localhost", Role: "user"})
+	if err != nil {
+		return CheckFail, "failed to sign a token: " + err.Error()
+	}
+	if _, err := tokens.ValidateToken(token); err != nil {
+		return CheckFail, "a freshly signed token does not validate: " + err.Error()
+	}
+
+	switch {
+	case cfg.JWT.SecretKey == config.DefaultJWTSecret:
+		return CheckWarn, "JWT_SECRET is the built-in development key"
+	case len(cfg.JWT.SecretKey) < minJWTSecretLength:
+		return CheckWarn, fmt.Sprintf("JWT_SECRET is shorter than %d bytes", minJWTSecretLength)
+	}
+	return CheckPass, ""
+}
+
+// checkJWTKeySource loads the key set from the file or URL key source and
+// signs and verifies a token with it
+func checkJWTKeySource(cfg *config.Config) (string, string) {
+	source, err := keys.NewSource(cfg.JWT.Keys)
+	if err != nil {
+		return CheckFail, err.Error()
+	}
+	set, err := source.Load(context.Background())
+	if err != nil {
+		return CheckFail, "failed to load JWT keys: " + err.Error()
+	}
+
+	tokens := service.NewJWTTokenServiceWithKeys(cfg, keys.NewStaticProvider(set))
+	token, err := tokens.GenerateToken(&domain.User{ID: "preflight", Email: "preflight@localhost", Role: "user"})
+	if err != nil {
+		return CheckFail, "failed to sign a token: " + err.Error()
+	}
+	if _, err := tokens.ValidateToken(token); err != nil {
+		return CheckFail, "a freshly signed token does not validate: " + err.Error()
+	}
+
+	for id, key := range set.Keys {
+		if key.PublicKey == nil && len(key.Secret) < minJWTSecretLength {
+			return CheckWarn, fmt.Sprintf("JWT key %q is shorter than %d bytes", id, minJWTSecretLength)
+		}
+	}
+	return CheckPass, fmt.Sprintf("%d keys, current key %q", len(set.Keys), set.Current.ID)
+}
+
+// checkSecretsHygiene reports insecure defaults; they fail the check where
+// they would stop the server from starting
+func checkSecretsHygiene(cfg *config.Config) (string, string) {
+	var unacknowledged []string
+	for _, finding := range hygiene.Check(cfg) {
+		if !finding.Acknowledged {
+			unacknowledged = append(unacknowledged, finding.Message)
+		}
+	}
+	switch {
+	case len(unacknowledged) == 0:
+		return CheckPass, ""
+	case cfg.Hygiene.Production():
+		return CheckFail, strings.Join(unacknowledged, "; ")
+	}
+	return CheckWarn, strings.Join(unacknowledged, "; ")
+}
+
+// checkMongoDB connects and pings MongoDB when it is the configured repository
+func checkMongoDB(cfg *config.Config) (string, string) {
+	if os.Getenv("REPOSITORY_TYPE") != "mongodb" {
+		return CheckSkip, "in-memory repository"
+	}
+
+	client, err := repository.NewMongoClient(cfg)
+	if err != nil {
+		return CheckFail, "failed to connect: " + err.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MongoDisconnectTimeout)
+	defer cancel()
+	_ = client.Disconnect(ctx)
+	return CheckPass, ""
+}
+
+// checkRedis connects and pings Redis when it is the configured cache. The
+// server runs without a cache when Redis is down, so this is a warning.
+func checkRedis(cfg *config.Config) (string, string) {
+	if cfg.Cache.Type != "redis" {
+		return CheckSkip, "cache disabled"
+	}
+
+	redisCache, err := cache.NewRedisCache(cfg)
+	if err != nil {
+		return CheckWarn, "unreachable, the server would start without a cache: " + err.Error()
+	}
+	_ = redisCache.Close()
+	return CheckPass, ""
+}
@@ -6,23 +6,81 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"time"
 
+	"demo-go/internal/assembler"
 	"demo-go/internal/cache"
 	"demo-go/internal/config"
+	"demo-go/internal/contentpolicy"
+	"demo-go/internal/demo"
 	"demo-go/internal/domain"
+	"demo-go/internal/emailpolicy"
+	"demo-go/internal/fieldcrypt"
+	"demo-go/internal/fieldpolicy"
 	"demo-go/internal/handler"
+	"demo-go/internal/hooks"
+	"demo-go/internal/hygiene"
+	"demo-go/internal/keys"
+	"demo-go/internal/lifecycle"
 	"demo-go/internal/logger"
 	"demo-go/internal/middleware"
+	"demo-go/internal/oidc"
+	"demo-go/internal/outbox"
+	"demo-go/internal/passwordhash"
+	"demo-go/internal/passwordpolicy"
+	"demo-go/internal/policy"
+	"demo-go/internal/ratelimit"
 	"demo-go/internal/repository"
+	"demo-go/internal/response"
+	"demo-go/internal/retention"
 	"demo-go/internal/routes"
+	"demo-go/internal/security"
 	"demo-go/internal/service"
+	"demo-go/internal/siem"
+	"demo-go/internal/snapshot"
+	"demo-go/internal/telemetry"
+	"demo-go/internal/usage"
+
+	"github.com/gorilla/mux"
 )
 
 // MongoDB disconnect timeout
 const MongoDisconnectTimeout = 10 * time.Second
 
+// SIEMFlushTimeout bounds how long shutdown waits for buffered security events
+const SIEMFlushTimeout = 5 * time.Second
+
+// TelemetryStopTimeout bounds how long shutdown waits for an in-flight telemetry report
+const TelemetryStopTimeout = 2 * time.Second
+
+// KeyProviderStopTimeout bounds how long shutdown waits for an in-flight JWT key reload
+const KeyProviderStopTimeout = 6 * time.Second
+
+// RetentionStopTimeout bounds how long shutdown waits for an in-flight retention purge
+const RetentionStopTimeout = 5 * time.Second
+
+// DemoResetTimeout bounds the demo seeding at startup, and how long shutdown
+// waits for an in-flight demo reset
+const DemoResetTimeout = 30 * time.Second
+
+// CacheFlushTimeout bounds how long shutdown waits for queued write-behind cache updates
+const CacheFlushTimeout = 5 * time.Second
+
+// EmailOutboxStopTimeout bounds how long shutdown waits for an in-flight email
+// delivery; an interrupted delivery is retried after the next start
+const EmailOutboxStopTimeout = 5 * time.Second
+
+// UsageStopTimeout bounds how long shutdown waits for the last usage flush and rollup
+const UsageStopTimeout = 10 * time.Second
+
+// AnnouncementLoadTimeout bounds loading the announcement at startup
+const AnnouncementLoadTimeout = 5 * time.Second
+
+// RoleLoadTimeout bounds creating the built-in roles and loading the roles at startup
+const RoleLoadTimeout = 10 * time.Second
+
 func main() {
 	// Initialize logger first
 	loggerConfig := logger.DefaultConfig()
@@ -41,6 +99,32 @@ func main() {
 
 	// Load configuration
 	cfg := config.Load()
+	response.APIVersion = cfg.Server.APIVersion
+
+	// `server check` validates configuration and dependencies, then exits
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		code := runCheckCommand(cfg, os.Stdout)
+		_ = logger.GetGlobal().Sync()
+		os.Exit(code)
+	}
+	// `server snapshot` creates, lists and restores users snapshots, then exits
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		code := runSnapshotCommand(cfg, os.Args[2:], os.Stdout)
+		_ = logger.GetGlobal().Sync()
+		os.Exit(code)
+	}
+	// `server migrate-cache-namespace` moves Redis keys into the configured namespace, then exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate-cache-namespace" {
+		code := runMigrateCacheNamespaceCommand(cfg, os.Args[2:], os.Stdout)
+		_ = logger.GetGlobal().Sync()
+		os.Exit(code)
+	}
+	// `server reencrypt-fields` rewrites encrypted fields under the current key, then exits
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt-fields" {
+		code := runReencryptFieldsCommand(cfg, os.Args[2:], os.Stdout)
+		_ = logger.GetGlobal().Sync()
+		os.Exit(code)
+	}
 
 	log.Info("Starting Clean Architecture API server",
 		"host", cfg.Server.Host,
@@ -49,68 +133,696 @@ func main() {
 	)
 
 	// Initialize dependencies
-	server, cleanup, err := initializeServer(cfg, logger.GetGlobal())
+	subsystems, err := initializeServer(cfg, logger.GetGlobal())
 	if err != nil {
 		log.Error("Failed to initialize server", "error", err)
 		os.Exit(1)
 	}
-	defer cleanup()
 
-	// Start server
-	go func() {
-		log.Info("Server listening",
-			"address", fmt.Sprintf("http://%s:%s", cfg.Server.Host, cfg.Server.Port),
-		)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Error("Server failed to start", "error", err)
-			os.Exit(1)
-		}
-	}()
+	// Start background work and the HTTP server; a failure stops what started
+	if err := subsystems.Start(context.Background()); err != nil {
+		log.Error("Server failed to start", "error", err)
+		os.Exit(1)
+	}
 
-	// Wait for interrupt signal to gracefully shutdown
+	// Wait for an interrupt signal, or a listener failing, to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	failed := false
+	select {
+	case <-quit:
+		log.Info("Shutting down server")
+	case err := <-subsystems.Failed():
+		log.Error("Server failed, shutting down", "error", err)
+		failed = true
+	}
 
-	log.Info("Shutting down server")
+	// The listeners stop first, then each subsystem before those it depends on
+	if err := subsystems.Stop(context.Background()); err != nil {
+		log.Error("Server did not shut down cleanly", "error", err)
+	} else {
+		log.Info("Server stopped gracefully")
+	}
+	if failed {
+		_ = logger.GetGlobal().Sync()
+		os.Exit(1)
+	}
+}
 
-	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
-	defer cancel()
+// initializeServer sets up all dependencies and returns the subsystems to
+// start, the HTTP server last among them
+func initializeServer(cfg *config.Config, baseLogger *logger.Logger) (*lifecycle.Manager, error) {
+	log := baseLogger.ForComponent("server")
 
-	// Shutdown server
-	if err := server.Shutdown(ctx); err != nil {
-		log.Error("Server forced to shutdown", "error", err)
+	// Each subsystem registers how it starts and stops; when initialization
+	// fails, what was set up so far is released
+	subsystems := lifecycle.NewManager()
+	fail := func(err error) (*lifecycle.Manager, error) {
+		_ = subsystems.Stop(context.Background())
+		return nil, err
 	}
 
-	log.Info("Server stopped gracefully")
-}
+	// Insecure defaults are logged, and refused in production
+	if err := hygiene.Enforce(cfg, log); err != nil {
+		return nil, err
+	}
 
-// initializeServer sets up all dependencies and returns the HTTP server
-func initializeServer(cfg *config.Config, baseLogger *logger.Logger) (*http.Server, func(), error) {
-	log := baseLogger.ForComponent("server")
+	// Initialize repositories
+	repos, repositoryCleanup, err := initializeRepositories(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	subsystems.MustRegister(lifecycle.Hook{Name: "repositories", Stop: lifecycle.CloseFunc(repositoryCleanup), Timeout: MongoDisconnectTimeout})
+
+	// User writes are mirrored to another store while migrating to it
+	migrationCleanup, err := initializeRepositoryMigration(cfg, repos, log)
+	if err != nil {
+		return fail(err)
+	}
+	subsystems.MustRegister(lifecycle.Hook{
+		Name:      "repository_migration",
+		DependsOn: []string{"repositories"},
+		Stop:      lifecycle.CloseFunc(migrationCleanup),
+		Timeout:   MongoDisconnectTimeout,
+	})
+	userRepo := repos.users
+
+	// Sensitive fields are encrypted before they are stored
+	encryptionKeys, err := initializeFieldEncryption(cfg, repos, log)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Security events are shipped to a SIEM when configured
+	shipper, err := initializeSIEMShipper(cfg, log)
+	if err != nil {
+		return fail(err)
+	}
+	if shipper != nil {
+		subsystems.MustRegister(lifecycle.Hook{Name: "siem", Stop: shipper.Close, Timeout: SIEMFlushTimeout})
+	}
+
+	if _, err := cache.KeyPrefix(cfg.Cache.Namespace); err != nil {
+		return fail(fmt.Errorf("invalid cache namespace: %w", err))
+	}
+	cacheService, cacheCleanup := initializeCache(cfg, log)
+	subsystems.MustRegister(lifecycle.Hook{Name: "cache", Stop: lifecycle.CloseFunc(cacheCleanup)})
 
-	// Initialize repository
-	userRepo, cleanup, err := initializeRepository(cfg, log)
+	// Rate limit and lockout counters of every feature share one store
+	limiter, err := initializeLimiter(cfg, cacheService, log)
 	if err != nil {
-		return nil, nil, err
+		return fail(err)
 	}
 
+	// Integrator hooks fire on lifecycle and security events
+	hookRegistry := hooks.NewRegistry(cfg.Hooks.Timeout)
+	registerHooks(hookRegistry)
+	securityEvents := security.NewEventService(limiter, hookRegistry, cfg.Security)
+	security.RegisterLoginHooks(hookRegistry, securityEvents)
+
 	// Initialize services
-	userService, cacheCleanup := initializeServices(cfg, userRepo, log)
+	auditService := service.NewAuditService(repos.audit)
+	if shipper != nil {
+		auditService = siem.NewAuditService(auditService, shipper)
+	}
+	auditService = security.NewAuditService(auditService, securityEvents)
+	userServiceOpts := []service.UserServiceOption{
+		service.WithAuditService(auditService),
+		service.WithRolePolicy(cfg.Roles),
+		service.WithMaxPageLimit(cfg.Pagination.MaxLimit),
+	}
+	passwordPolicy := passwordpolicy.New(cfg.Passwords)
+	passwordHasher, err := passwordhash.New(cfg.PasswordHash)
+	if err != nil {
+		return fail(fmt.Errorf("invalid password hashing configuration: %w", err))
+	}
+	userServiceOpts = append(userServiceOpts, service.WithPasswordPolicy(passwordPolicy), service.WithPasswordHasher(passwordHasher))
+	if cfg.Passwords.BreachCheck {
+		log.Info("Breached password check enabled", "url", cfg.Passwords.BreachCheckURL)
+	}
+	// Admin-defined roles; users can then only be assigned roles that exist
+	var roleService domain.RoleService
+	if cfg.Roles.Custom.Enabled {
+		roleService, err = initializeRoles(cfg, repos, auditService, log)
+		if err != nil {
+			return fail(err)
+		}
+		userServiceOpts = append(userServiceOpts, service.WithRoleCatalog(roleService))
+	}
+	if cfg.ContentPolicy.Enabled {
+		namePolicy, err := contentpolicy.New(cfg.ContentPolicy)
+		if err != nil {
+			return fail(err)
+		}
+		log.Info("Name content policy enabled", "blocklist_terms", len(cfg.ContentPolicy.Blocklist))
+		userServiceOpts = append(userServiceOpts, service.WithNamePolicy(namePolicy))
+	}
+	var emailPolicy *emailpolicy.Policy
+	if cfg.EmailDomains.Enabled {
+		emailPolicy, err = emailpolicy.New(cfg.EmailDomains)
+		if err != nil {
+			return fail(fmt.Errorf("invalid email domain policy: %w", err))
+		}
+		log.Info("Email domain policy enabled",
+			"allowed_domains", len(cfg.EmailDomains.Allow), "denied_domains", len(cfg.EmailDomains.Deny))
+		userServiceOpts = append(userServiceOpts, service.WithEmailDomainPolicy(emailPolicy))
+	}
+	naming, err := response.ParseNaming(cfg.Response.FieldNaming)
+	if err != nil {
+		return fail(fmt.Errorf("invalid RESPONSE_FIELD_NAMING: %w", err))
+	}
+	response.DefaultFormat = response.Format{Naming: naming, Envelope: cfg.Response.Envelope}
+	response.FormatHeaders = cfg.Response.FormatHeaders
+	response.DemoMode = cfg.Demo.Enabled
+	tokenService, keyProvider, err := initializeTokenService(cfg, cacheService, log)
+	if err != nil {
+		return fail(err)
+	}
+	cacheOpts, writeBehind, err := initializeCacheStrategies(cfg, cacheService, log)
+	if err != nil {
+		return fail(err)
+	}
+	if writeBehind != nil {
+		// Queued cache writes are applied before the cache connection closes
+		subsystems.MustRegister(lifecycle.Hook{
+			Name:      "cache_write_behind",
+			DependsOn: []string{"cache"},
+			Stop:      writeBehind.Close,
+			Timeout:   CacheFlushTimeout,
+		})
+	}
+	userService := initializeServices(cfg, userRepo, tokenService, cacheService, cacheOpts, userServiceOpts...)
+
+	// Hooks fire on lifecycle events, so only the command side is wrapped
+	if shipper != nil {
+		siem.RegisterAuthHooks(hookRegistry, shipper)
+		siem.RegisterSecurityHooks(hookRegistry, shipper)
+	}
+	userService = service.ComposeUserService(userService, service.NewHookedUserService(userService, hookRegistry))
+
+	// Last-seen tracking feeds the presence shown on user responses
+	var presence domain.PresenceService
+	if cfg.Presence.Enabled {
+		presence = service.NewPresenceService(initializePresenceStore(cacheService), repos.preferences, cfg.Presence)
+	}
+
+	// Enrich user responses with data kept outside the user record
+	userService = service.NewAssembledUserService(userService, initializeResponseAssembler(cfg, repos.preferences, presence))
+
+	// Repeated lookups of the same user within one request are served from the request memo
+	userService = service.ComposeUserService(
+		service.NewMemoizedUserQueries(userService),
+		service.NewMemoResettingUserCommands(userService),
+	)
+
+	// Registrations with an invite code get the invite's role; invite-only
+	// servers refuse registrations without one
+	if cfg.Invites.InviteOnly && !cfg.Invites.Enabled {
+		return fail(fmt.Errorf("INVITE_ONLY requires INVITES_ENABLED"))
+	}
+	var inviteService domain.InviteService
+	if cfg.Invites.Enabled {
+		log.Info("Invites enabled", "invite_only", cfg.Invites.InviteOnly, "ttl", cfg.Invites.TTL)
+		if cfg.Invites.InviteOnly && cfg.OIDC.Enabled && cfg.OIDC.AutoProvision {
+			log.Warn("OIDC_AUTO_PROVISION creates accounts without invites")
+		}
+		var roleCatalog domain.RoleCatalog
+		if roleService != nil {
+			roleCatalog = roleService
+		}
+		inviteService = service.NewInviteService(repos.invites, userRepo, roleCatalog, auditService, cfg.Invites)
+		userService = service.ComposeUserService(
+			userService,
+			service.NewInviteUserCommands(userService, inviteService, cfg.Invites.InviteOnly),
+		)
+	}
 
-	// Combine cleanup functions
-	combinedCleanup := func() {
-		cacheCleanup()
-		cleanup()
+	// Emails with repeated failed logins are locked out; the decorator is outermost
+	// so inner layers and hooks still see plain invalid-credential errors
+	var loginThrottleLimiter ratelimit.Limiter
+	if cfg.LoginThrottle.Enabled {
+		log.Info("Login throttling enabled",
+			"max_failures", cfg.LoginThrottle.MaxFailures, "window", cfg.LoginThrottle.Window,
+			"ip_max_failures", cfg.LoginThrottle.IPMaxFailures, "ip_window", cfg.LoginThrottle.IPWindow,
+		)
+		loginThrottleLimiter = limiter
+		userService = service.ComposeUserService(
+			userService,
+			service.NewThrottledUserCommands(userService, loginThrottleLimiter, cfg.LoginThrottle),
+		)
+	}
+
+	// With the outbox, emails are recorded and delivered in the background with retries
+	emailSender := service.NewEmailSender(cfg.Email)
+	var emailOutbox *outbox.Outbox
+	if cfg.Email.Outbox.Enabled {
+		emailOutbox = outbox.New(cfg.Email.Outbox, repos.emails, emailSender, auditService)
+		subsystems.MustRegister(lifecycle.Hook{
+			Name:      "email_outbox",
+			DependsOn: []string{"repositories"},
+			Start:     startFunc(emailOutbox.Start),
+			Stop:      emailOutbox.Close,
+			Timeout:   EmailOutboxStopTimeout,
+		})
+		emailSender = emailOutbox
+	}
+
+	// Login attempts are recorded outside the throttle, so lockouts show up too
+	if cfg.SuspiciousLogin.Enabled && !cfg.LoginHistory.Enabled {
+		return fail(fmt.Errorf("suspicious login detection requires LOGIN_HISTORY_ENABLED"))
+	}
+	var loginHistory domain.LoginHistoryService
+	if cfg.LoginHistory.Enabled {
+		log.Info("Login history enabled", "retention", cfg.LoginHistory.Retention)
+		loginHistory = service.NewLoginHistoryService(repos.logins, userRepo)
+		if cfg.SuspiciousLogin.Enabled {
+			log.Info("Suspicious login detection enabled",
+				"new_country", cfg.SuspiciousLogin.NewCountry, "new_device", cfg.SuspiciousLogin.NewDevice, "notify", cfg.SuspiciousLogin.Notify)
+			var notifier service.EmailSender
+			if cfg.SuspiciousLogin.Notify {
+				notifier = emailSender
+			}
+			loginHistory = service.NewSuspiciousLoginDetector(loginHistory, repos.logins, userRepo, securityEvents, notifier, cfg.SuspiciousLogin)
+		}
+		userService = service.ComposeUserService(
+			userService,
+			service.NewLoginHistoryUserCommands(userService, loginHistory, userRepo),
+		)
+	}
+
+	// Opt-in anonymous usage reporting; the payload is always inspectable by admins
+	telemetryCollector := telemetry.NewCollector(cfg.Telemetry, cfg.Server.AppVersion, telemetryFeatures(cfg), userRepo)
+	subsystems.MustRegister(lifecycle.Hook{
+		Name:      "telemetry",
+		DependsOn: []string{"repositories"},
+		Start:     startFunc(telemetryCollector.Start),
+		Stop:      telemetryCollector.Close,
+		Timeout:   TelemetryStopTimeout,
+	})
+
+	// Scheduled purges of data past its retention period; admins can always see a dry run
+	retentionEngine := retention.NewEngine(cfg.Retention, userRepo, repos.audit)
+	subsystems.MustRegister(lifecycle.Hook{
+		Name:      "retention",
+		DependsOn: []string{"repositories"},
+		Start:     startFunc(retentionEngine.Start),
+		Stop:      retentionEngine.Close,
+		Timeout:   RetentionStopTimeout,
+	})
+
+	// Rotated keys and deny-lists are reloaded in the background
+	if keyProvider != nil {
+		subsystems.MustRegister(lifecycle.Hook{Name: "jwt_keys", Start: startFunc(keyProvider.Start), Stop: keyProvider.Close, Timeout: KeyProviderStopTimeout})
+	}
+	if encryptionKeys != nil {
+		subsystems.MustRegister(lifecycle.Hook{Name: "field_encryption_keys", Start: startFunc(encryptionKeys.Start), Stop: encryptionKeys.Close, Timeout: KeyProviderStopTimeout})
+	}
+	if emailPolicy != nil {
+		subsystems.MustRegister(lifecycle.Hook{Name: "email_domain_policy", Start: startFunc(emailPolicy.Start), Stop: emailPolicy.Close, Timeout: KeyProviderStopTimeout})
 	}
 
 	// Initialize handlers and middleware
+	// Per-user revocations must outlive both access and refresh tokens
+	refreshTTL := cfg.JWT.RefreshExpiration
+	if cfg.JWT.RememberMeExpiration > refreshTTL {
+		refreshTTL = cfg.JWT.RememberMeExpiration
+	}
+	revocationTTL := cfg.JWT.Expiration
+	if refreshTTL > revocationTTL {
+		revocationTTL = refreshTTL
+	}
+	tokenRevocations := service.NewTokenRevocationService(initializeTokenRevocationStore(cacheService), userRepo, auditService, revocationTTL)
+	refreshTokenStore := initializeRefreshTokenStore(repos, cacheService)
+	// Sessions are refresh token families; the refresh token service records them
+	var sessions service.RecordingSessionService
+	refreshTokenOpts := []service.RefreshTokenServiceOption{service.WithRememberMe(cfg.JWT.RememberMeExpiration)}
+	if cfg.JWT.TrackSessions {
+		sessions = service.NewSessionService(repos.sessions, refreshTokenStore, tokenService, tokenRevocations, refreshTTL)
+		refreshTokenOpts = append(refreshTokenOpts, service.WithSessionRecorder(sessions))
+	}
+	refreshTokens := service.NewRefreshTokenService(
+		refreshTokenStore,
+		userService,
+		securityEvents,
+		tokenRevocations,
+		cfg.JWT.RefreshExpiration,
+		refreshTokenOpts...,
+	)
+	cookieSessions, err := initializeCookieSessions(cfg, log)
+	if err != nil {
+		return fail(err)
+	}
 	userHandler := handler.NewUserHandler(userService)
-	jwtMiddleware := middleware.NewJWTMiddleware(service.NewJWTTokenService(cfg))
+	userHandler.SetRefreshTokenService(refreshTokens)
+	userHandler.SetTokenRevocationService(tokenRevocations)
+	if cookieSessions != nil {
+		userHandler.SetCookieSessions(cookieSessions)
+	}
+	if checker, ok := cacheService.(domain.HealthChecker); ok {
+		userHandler.AddHealthCheck("cache", checker)
+	}
+	if keyProvider != nil {
+		userHandler.AddHealthCheck("jwt_keys", keyProvider)
+	}
+	if encryptionKeys != nil {
+		userHandler.AddHealthCheck("field_encryption_keys", encryptionKeys)
+	}
+	if repos.writeBuffer != nil {
+		userHandler.AddHealthCheck("mongodb_write_buffer", repos.writeBuffer)
+	}
+	if repos.migration != nil {
+		userHandler.AddHealthCheck("repository_migration", repos.migration)
+	}
+	if writeBehind != nil {
+		userHandler.AddHealthCheck("cache_write_behind", writeBehind)
+	}
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(tokenRevocations)
+	jwtMiddleware.SetRoleScopes(cfg.Roles.Scopes)
+	var policyEngine domain.PolicyEngine = roleService
+	if roleService == nil {
+		matrix, err := policy.NewMatrix(cfg.Policy.Permissions)
+		if err != nil {
+			return fail(fmt.Errorf("invalid POLICY_PERMISSIONS: %w", err))
+		}
+		policyEngine = matrix
+	}
+	jwtMiddleware.SetPolicyEngine(policyEngine)
+	if len(cfg.JWT.TrustedIssuers) > 0 {
+		grants := make(map[string][]string, len(cfg.JWT.TrustedIssuers))
+		for _, issuer := range cfg.JWT.TrustedIssuers {
+			grants[issuer.Tenant] = issuer.Permissions
+		}
+		tenantPolicy, err := policy.NewMatrix(grants)
+		if err != nil {
+			return fail(fmt.Errorf("invalid JWT_TENANT_*_PERMISSIONS: %w", err))
+		}
+		jwtMiddleware.SetTenantPolicy(tenantPolicy)
+	}
+	// Other users' private fields are only shown to roles allowed to see them
+	userHandler.SetFieldFilter(fieldpolicy.New(policyEngine))
+	userHandler.SetAuthorizer(jwtMiddleware)
+	if presence != nil {
+		jwtMiddleware.SetPresenceService(presence)
+	}
+	if cfg.Roles.RecheckInterval > 0 {
+		log.Info("Token roles are rechecked against the user store", "interval", cfg.Roles.RecheckInterval)
+		jwtMiddleware.SetRoleCheckService(service.NewRoleCheckService(userRepo, cfg.Roles.RecheckInterval))
+	}
+	if err := jwtMiddleware.AddSkipRules(cfg.JWT.SkipPaths...); err != nil {
+		return fail(fmt.Errorf("invalid JWT_SKIP_PATHS: %w", err))
+	}
+	var routeOverrides domain.RouteOverrideService
+	if cfg.JWT.RouteOverrides.Enabled {
+		log.Info("Runtime route overrides enabled", "refresh_interval", cfg.JWT.RouteOverrides.RefreshInterval)
+		routeOverrides = service.NewRouteOverrideService(repos.overrides, auditService, cfg.JWT.RouteOverrides.RefreshInterval)
+		jwtMiddleware.SetRouteOverrideService(routeOverrides)
+	}
+	var announcements domain.AnnouncementService
+	if cfg.Announcements.Enabled {
+		announcements = service.NewAnnouncementService(
+			initializeAnnouncementStore(repos, cacheService), auditService, cfg.Announcements.RefreshInterval,
+		)
+		ctx, cancel := context.WithTimeout(context.Background(), AnnouncementLoadTimeout)
+		if err := announcements.Reload(ctx); err != nil {
+			// Not fatal: the announcement is retried on the next refresh
+			log.Warn("Failed to load the announcement", "error", err)
+		}
+		cancel()
+		log.Info("Announcements enabled", "refresh_interval", cfg.Announcements.RefreshInterval)
+		userHandler.SetAnnouncementService(announcements)
+	}
+
+	preAuthMiddleware := []mux.MiddlewareFunc{middleware.HooksMiddleware(hookRegistry), middleware.RequestMemo}
+	if cfg.SuspiciousLogin.CountryHeader != "" {
+		preAuthMiddleware = append(preAuthMiddleware, middleware.ClientCountry(cfg.SuspiciousLogin.CountryHeader))
+	}
+	if cfg.Server.RequestTimeout > 0 {
+		// Storage calls take the smaller of their own timeout and what the request has left
+		preAuthMiddleware = append(preAuthMiddleware, middleware.RequestBudget(cfg.Server.RequestTimeout))
+	}
+	if hmacMiddleware := initializeHMACMiddleware(cfg, cacheService, log); hmacMiddleware != nil {
+		// Signed machine-to-machine requests are verified before JWT authentication
+		preAuthMiddleware = append(preAuthMiddleware, hmacMiddleware.Authenticate)
+	}
+	var apiKeyService domain.APIKeyService
+	if cfg.APIKeys.Enabled {
+		log.Info("API keys enabled", "max_per_user", cfg.APIKeys.MaxPerUser)
+		apiKeyService = service.NewAPIKeyService(repos.apiKeys, userRepo, auditService, cfg.Roles.Default, cfg.APIKeys)
+		// Requests with an X-API-Key header are authenticated without a JWT
+		preAuthMiddleware = append(preAuthMiddleware, middleware.NewAPIKeyMiddleware(apiKeyService, cfg.Roles.Default).Authenticate)
+	}
+	if cookieSessions != nil {
+		// Session cookies become bearer tokens once their CSRF token is checked
+		preAuthMiddleware = append(preAuthMiddleware, cookieSessions.Authenticate)
+	}
 
 	// Setup routes and server
-	router := routes.NewRouter(userHandler, jwtMiddleware, baseLogger)
+	router := routes.NewRouter(userHandler, jwtMiddleware, baseLogger, preAuthMiddleware...)
+	router.SetVersion(cfg.Server.AppVersion)
+	router.SetCORSOrigins(cfg.CORS.AllowedOrigins)
+	if err := middleware.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fail(fmt.Errorf("invalid TRUSTED_PROXIES: %w", err))
+	}
+	router.AddRouteGroup("Audit Routes", routes.NewAuditRoutes(handler.NewAuditHandler(auditService)))
+	revocationHandler := handler.NewTokenRevocationHandler(tokenRevocations, refreshTokens)
+	if cookieSessions != nil {
+		revocationHandler.SetCookieSessions(cookieSessions)
+	}
+	router.AddRouteGroup("Token Revocation Routes", routes.NewTokenRevocationRoutes(revocationHandler))
+	if cfg.AdminSearch.Enabled {
+		// Sessions are only searched when they are tracked
+		var searchSessions domain.SessionRepository
+		if cfg.JWT.TrackSessions {
+			searchSessions = repos.sessions
+		}
+		adminSearch := service.NewAdminSearchService(userService, auditService, searchSessions, cfg.AdminSearch)
+		router.AddRouteGroup("Admin Search Routes", routes.NewAdminSearchRoutes(handler.NewAdminSearchHandler(adminSearch, jwtMiddleware)))
+	}
+	if inviteService != nil {
+		router.AddRouteGroup("Invite Routes", routes.NewInviteRoutes(handler.NewInviteHandler(inviteService, jwtMiddleware)))
+	}
+	if loginHistory != nil {
+		router.AddRouteGroup("Login History Routes", routes.NewLoginHistoryRoutes(handler.NewLoginHistoryHandler(loginHistory)))
+	}
+	if sessions != nil {
+		router.AddRouteGroup("Session Routes", routes.NewSessionRoutes(handler.NewSessionHandler(sessions)))
+	}
+	if cfg.Transfer.Enabled {
+		if cfg.Transfer.SigningKey == "" {
+			return fail(fmt.Errorf("ACCOUNT_TRANSFER_SIGNING_KEY is required when account transfer is enabled"))
+		}
+		transferService := service.NewTransferService(userRepo, repos.preferences, repos.notes, auditService, cacheService, cfg.Transfer)
+		router.AddRouteGroup("Transfer Routes", routes.NewTransferRoutes(handler.NewTransferHandler(transferService)))
+	}
+	if cfg.Snapshot.Enabled {
+		snapshotStore, err := snapshot.NewStore(cfg.Snapshot)
+		if err != nil {
+			return fail(fmt.Errorf("failed to initialize snapshot storage: %w", err))
+		}
+		log.Info("Users snapshots enabled", "storage", cfg.Snapshot.Storage)
+		snapshotService := service.NewSnapshotService(snapshotStore, userRepo, auditService, cacheService, cfg.Snapshot)
+		router.AddRouteGroup("Snapshot Routes", routes.NewSnapshotRoutes(handler.NewSnapshotHandler(snapshotService)))
+	}
+	router.AddRouteGroup("Note Routes", routes.NewNoteRoutes(
+		handler.NewNoteHandler(service.NewNoteService(repos.notes, userRepo)),
+		cfg.Roles.NoteRoles,
+	))
+	if apiKeyService != nil {
+		router.AddRouteGroup("API Key Routes", routes.NewAPIKeyRoutes(handler.NewAPIKeyHandler(apiKeyService)))
+	}
+	var clientService domain.ClientService
+	if cfg.Clients.Enabled {
+		log.Info("Client credentials grant enabled", "token_ttl", cfg.Clients.TokenTTL, "token_exchange", cfg.Clients.TokenExchange)
+		var clientOpts []service.ClientServiceOption
+		if cfg.Clients.TokenExchange {
+			clientOpts = append(clientOpts, service.WithTokenExchange(tokenRevocations, cfg.Roles.Scopes))
+		}
+		clientService = service.NewClientService(repos.clients, tokenService, auditService, cfg.Roles.Default, cfg.Clients, clientOpts...)
+		router.AddRouteGroup("Client Routes", routes.NewClientRoutes(handler.NewClientHandler(clientService), cfg.Clients.TokenExchange))
+	}
+	if routeOverrides != nil {
+		router.AddRouteGroup("Route Override Routes", routes.NewRouteOverrideRoutes(
+			handler.NewRouteOverrideHandler(routeOverrides, jwtMiddleware.SkipRules),
+		))
+	}
+	if announcements != nil {
+		router.AddRouteGroup("Announcement Routes", routes.NewAnnouncementRoutes(handler.NewAnnouncementHandler(announcements)))
+	}
+	if publisher, ok := tokenService.(domain.PublicKeyPublisher); ok {
+		router.AddRouteGroup("JWKS Routes", routes.NewJWKSRoutes(handler.NewJWKSHandler(publisher)))
+	}
+	router.AddRouteGroup("Security Routes", routes.NewSecurityRoutes(handler.NewSecurityHandler(securityEvents)))
+	router.AddRouteGroup("Auth Metrics Routes", routes.NewAuthMetricsRoutes(handler.NewAuthMetricsHandler(jwtMiddleware.Metrics())))
+	router.AddRouteGroup("Policy Routes", routes.NewPolicyRoutes(handler.NewPolicyHandler(policyEngine, userService)))
+	if roleService != nil {
+		router.AddRouteGroup("Role Routes", routes.NewRoleRoutes(handler.NewRoleHandler(roleService, userService)))
+	}
+	router.AddRouteGroup("Telemetry Routes", routes.NewTelemetryRoutes(handler.NewTelemetryHandler(telemetryCollector)))
+	router.AddRouteGroup("Retention Routes", routes.NewRetentionRoutes(handler.NewRetentionHandler(retentionEngine)))
+	router.AddRouteGroup("Preferences Routes", routes.NewPreferencesRoutes(
+		handler.NewPreferencesHandler(service.NewPreferencesService(repos.preferences)),
+	))
+
+	if cfg.PublicProfile.Enabled {
+		log.Info("Public profiles and WebFinger discovery enabled", "base_url", cfg.PublicProfile.BaseURL)
+		profileService := service.NewPublicProfileService(userRepo, repos.preferences, cfg.PublicProfile, cfg.Profile.AvatarURLTemplate)
+		router.AddRouteGroup("Public Profile Routes", routes.NewPublicProfileRoutes(handler.NewPublicProfileHandler(profileService)))
+	}
+
+	if cfg.Usage.Enabled {
+		log.Info("API usage analytics enabled", "flush_interval", cfg.Usage.FlushInterval, "rollup_interval", cfg.Usage.RollupInterval)
+		usageTracker := usage.New(cfg.Usage, initializeUsageCounterStore(cacheService), repos.usage)
+		// Counts are flushed to the cache and rolled up to the repository before either closes
+		subsystems.MustRegister(lifecycle.Hook{
+			Name:      "usage",
+			DependsOn: []string{"repositories", "cache"},
+			Start:     startFunc(usageTracker.Start),
+			Stop:      usageTracker.Close,
+			Timeout:   UsageStopTimeout,
+		})
+		// Registered before rate limiting so refused requests are counted too
+		router.UseAfterAuth(middleware.UsageTracking(usageTracker))
+		router.AddRouteGroup("Usage Routes", routes.NewUsageRoutes(handler.NewUsageHandler(usageTracker)))
+	}
+	if cfg.Database.MongoDB.ExplainEnabled {
+		// Admins can send X-Explain: true to get the request's query plans in the response meta
+		router.UseAfterAuth(middleware.QueryExplain)
+	}
+	if cfg.RateLimit.Enabled {
+		log.Info("Tiered rate limiting enabled", "window", cfg.RateLimit.Window, "tiers", cfg.RateLimit.Tiers)
+		rateLimitMiddleware := middleware.NewRateLimitMiddleware(limiter, cfg.RateLimit)
+		router.UseAfterAuth(rateLimitMiddleware.Limit)
+	}
+
+	if cfg.Dedup.Enabled {
+		// Registered after rate limiting so every coalesced retry still counts against the caller
+		log.Info("Concurrent GET request deduplication enabled")
+		router.UseAfterAuth(middleware.NewDedupMiddleware().Deduplicate)
+	}
+
+	if cfg.Recovery.Enabled {
+		log.Info("Security-question account recovery enabled")
+		recoveryService := service.NewAccountRecoveryService(
+			userRepo,
+			initializeResetTokenStore(cacheService),
+			limiter,
+			passwordPolicy,
+			passwordHasher,
+			tokenRevocations,
+			cfg.Recovery,
+		)
+		router.AddRouteGroup("Account Recovery Routes", routes.NewRecoveryRoutes(handler.NewRecoveryHandler(recoveryService)))
+	}
+
+	if cfg.WebAuthn.Enabled {
+		log.Info("Passkey login enabled", "rp_id", cfg.WebAuthn.RPID, "origins", cfg.WebAuthn.Origins)
+		webAuthnService := service.NewWebAuthnService(
+			userRepo,
+			initializeWebAuthnSessionStore(cacheService),
+			tokenService,
+			auditService,
+			cfg.WebAuthn,
+		)
+		if loginHistory != nil {
+			webAuthnService = service.NewLoginHistoryWebAuthnService(webAuthnService, loginHistory)
+		}
+		webAuthnHandler := handler.NewWebAuthnHandler(webAuthnService, refreshTokens)
+		if cookieSessions != nil {
+			webAuthnHandler.SetCookieSessions(cookieSessions)
+		}
+		router.AddRouteGroup("Passkey Routes", routes.NewWebAuthnRoutes(webAuthnHandler))
+	}
+
+	if cfg.OIDC.Enabled {
+		if err := oidc.ValidateConfig(cfg.OIDC); err != nil {
+			return fail(fmt.Errorf("invalid OIDC settings: %w", err))
+		}
+		log.Info("Single sign-on enabled", "issuer", cfg.OIDC.IssuerURL, "auto_provision", cfg.OIDC.AutoProvision)
+		oidcService := service.NewOIDCService(
+			oidc.NewProvider(cfg.OIDC, nil),
+			userRepo,
+			initializeOIDCStateStore(cacheService),
+			tokenService,
+			auditService,
+			cacheService,
+			cfg.Roles.Default,
+			cfg.OIDC,
+		)
+		if loginHistory != nil {
+			oidcService = service.NewLoginHistoryOIDCService(oidcService, loginHistory)
+		}
+		oidcHandler := handler.NewOIDCHandler(oidcService, refreshTokens)
+		if cookieSessions != nil {
+			oidcHandler.SetCookieSessions(cookieSessions)
+		}
+		router.AddRouteGroup("Single Sign-On Routes", routes.NewOIDCRoutes(oidcHandler))
+		if !cfg.OIDC.PasswordLogin {
+			log.Info("Password login disabled; users log in through single sign-on")
+			router.DisablePasswordLogin()
+		}
+	}
+
+	if emailOutbox != nil {
+		router.AddRouteGroup("Email Outbox Routes", routes.NewEmailOutboxRoutes(handler.NewEmailOutboxHandler(emailOutbox)))
+	}
+
+	if cfg.PasswordReset.Enabled {
+		log.Info("Emailed password reset enabled")
+		if cfg.Email.SMTP.Host == "" {
+			log.Warn("SMTP_HOST is not set; password reset emails are only logged")
+		}
+		passwordResetService := service.NewPasswordResetService(
+			userRepo,
+			initializeResetTokenStore(cacheService),
+			emailSender,
+			limiter,
+			passwordPolicy,
+			passwordHasher,
+			tokenRevocations,
+			cfg.PasswordReset,
+		)
+		router.SetPasswordResetHandler(handler.NewPasswordResetHandler(passwordResetService))
+	}
+
+	if loginThrottleLimiter != nil {
+		throttleService := service.NewLoginThrottleService(loginThrottleLimiter, cfg.LoginThrottle)
+		router.AddRouteGroup("Login Throttle Routes", routes.NewLoginThrottleRoutes(handler.NewLoginThrottleHandler(throttleService)))
+	}
+
+	if cfg.EmailCheck.Enabled {
+		emailCheckService := service.NewEmailCheckService(userRepo, limiter, cfg.EmailCheck)
+		router.AddRouteGroup("Email Check Routes", routes.NewEmailCheckRoutes(handler.NewEmailCheckHandler(emailCheckService)))
+	}
+
+	// Demo mode seeds known accounts at startup and puts them back, with
+	// everything the demo admin can configure, on a schedule
+	if cfg.Demo.Enabled {
+		log.Warn("Demo mode enabled; every account but the demo fixtures is deleted on reset",
+			"admin_email", cfg.Demo.AdminEmail, "users", cfg.Demo.Users, "reset_interval", cfg.Demo.ResetInterval)
+		seeder := demo.New(cfg.Demo, userRepo, repos.audit, passwordHasher, cacheService, cfg.Roles.Default,
+			demo.WithRoles(roleService),
+			demo.WithRouteOverrides(routeOverrides),
+			demo.WithClients(clientService),
+			demo.WithAPIKeys(apiKeyService),
+			demo.WithInvites(inviteService),
+			demo.WithAnnouncements(announcements),
+		)
+		subsystems.MustRegister(lifecycle.Hook{
+			Name:      "demo",
+			DependsOn: []string{"repositories", "cache"},
+			Start: func(ctx context.Context) error {
+				if err := seeder.Reset(ctx); err != nil {
+					return fmt.Errorf("seed demo state: %w", err)
+				}
+				seeder.Start()
+				return nil
+			},
+			Stop:    seeder.Close,
+			Timeout: DemoResetTimeout,
+		})
+	}
+
 	httpRouter := router.SetupRoutes()
 
 	server := &http.Server{
@@ -119,17 +831,70 @@ func initializeServer(cfg *config.Config, baseLogger *logger.Logger) (*http.Serv
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
+	// Listeners start last and stop first, so requests in flight can still
+	// use every other subsystem
+	subsystems.MustRegister(subsystems.ListenerHook("http_server", server.Addr, server, cfg.Server.ShutdownTimeout, subsystems.Names()...))
+
+	return subsystems, nil
+}
+
+// startFunc adapts the Start method of a background worker, which launches
+// its goroutine and returns, to a lifecycle Start function
+func startFunc(start func()) func(ctx context.Context) error {
+	return func(context.Context) error {
+		start()
+		return nil
+	}
+}
 
-	return server, combinedCleanup, nil
+// repositories groups the data repositories backed by the configured store
+type repositories struct {
+	users       domain.UserRepository
+	audit       domain.AuditRepository
+	preferences domain.PreferencesRepository
+	notes       domain.NoteRepository
+	apiKeys     domain.APIKeyRepository
+	clients     domain.ClientRepository
+	roles       domain.RoleRepository
+	emails      domain.EmailOutboxRepository
+	overrides   domain.RouteOverrideRepository
+	sessions    domain.SessionRepository
+	usage       domain.UsageRepository
+	logins      domain.LoginHistoryRepository
+	invites     domain.InviteRepository
+	// announcements is replaced by a Redis store for in-memory repositories when a cache is available
+	announcements domain.AnnouncementStore
+	// refreshTokens is replaced by a Redis store for in-memory repositories when a cache is available
+	refreshTokens domain.RefreshTokenStore
+	// writeBuffer holds user writes during MongoDB failovers; nil unless enabled
+	writeBuffer *repository.FailoverUserRepository
+	// migration mirrors user writes to the migration target; nil unless enabled
+	migration *repository.MigratingUserRepository
 }
 
-// initializeRepository sets up the data repository based on configuration
-func initializeRepository(cfg *config.Config, log *logger.Logger) (domain.UserRepository, func(), error) {
+// initializeRepositories sets up the data repositories based on configuration
+func initializeRepositories(cfg *config.Config, log *logger.Logger) (*repositories, func(), error) {
 	repositoryType := os.Getenv("REPOSITORY_TYPE")
 
 	if repositoryType == "memory" || repositoryType == "" {
 		log.Info("Using in-memory repository")
-		return repository.NewMemoryUserRepository(), func() {}, nil
+		return &repositories{
+			users:         repository.NewMemoryUserRepository(),
+			audit:         repository.NewMemoryAuditRepository(),
+			preferences:   repository.NewMemoryPreferencesRepository(),
+			notes:         repository.NewMemoryNoteRepository(),
+			apiKeys:       repository.NewMemoryAPIKeyRepository(),
+			clients:       repository.NewMemoryClientRepository(),
+			roles:         repository.NewMemoryRoleRepository(),
+			emails:        repository.NewMemoryEmailOutboxRepository(),
+			overrides:     repository.NewMemoryRouteOverrideRepository(),
+			sessions:      repository.NewMemorySessionRepository(),
+			usage:         repository.NewMemoryUsageRepository(),
+			logins:        repository.NewMemoryLoginHistoryRepository(cfg.LoginHistory.Retention),
+			invites:       repository.NewMemoryInviteRepository(),
+			announcements: repository.NewMemoryAnnouncementStore(),
+			refreshTokens: repository.NewMemoryRefreshTokenStore(),
+		}, func() {}, nil
 	}
 
 	if repositoryType == "mongodb" {
@@ -140,7 +905,28 @@ func initializeRepository(cfg *config.Config, log *logger.Logger) (domain.UserRe
 			return nil, nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 		}
 
-		userRepo := repository.NewMongoUserRepository(mongoClient, cfg)
+		repos := &repositories{
+			users:         repository.NewMongoUserRepository(mongoClient, cfg),
+			audit:         repository.NewMongoAuditRepository(mongoClient, cfg),
+			preferences:   repository.NewMongoPreferencesRepository(mongoClient, cfg),
+			notes:         repository.NewMongoNoteRepository(mongoClient, cfg),
+			apiKeys:       repository.NewMongoAPIKeyRepository(mongoClient, cfg),
+			clients:       repository.NewMongoClientRepository(mongoClient, cfg),
+			roles:         repository.NewMongoRoleRepository(mongoClient, cfg),
+			emails:        repository.NewMongoEmailOutboxRepository(mongoClient, cfg),
+			overrides:     repository.NewMongoRouteOverrideRepository(mongoClient, cfg),
+			sessions:      repository.NewMongoSessionRepository(mongoClient, cfg),
+			usage:         repository.NewMongoUsageRepository(mongoClient, cfg),
+			logins:        repository.NewMongoLoginHistoryRepository(mongoClient, cfg),
+			invites:       repository.NewMongoInviteRepository(mongoClient, cfg),
+			announcements: repository.NewMongoAnnouncementStore(mongoClient, cfg),
+			refreshTokens: repository.NewMongoRefreshTokenStore(mongoClient, cfg),
+		}
+		if bufferCfg := cfg.Database.MongoDB.WriteBuffer; bufferCfg.Enabled {
+			log.Info("MongoDB write buffer enabled", "max_pending", bufferCfg.MaxPending, "timeout", bufferCfg.Timeout)
+			repos.writeBuffer = repository.NewFailoverUserRepository(repos.users, bufferCfg)
+			repos.users = repos.writeBuffer
+		}
 
 		cleanup := func() {
 			log.Info("Disconnecting from MongoDB")
@@ -153,33 +939,289 @@ func initializeRepository(cfg *config.Config, log *logger.Logger) (domain.UserRe
 			}
 		}
 
-		return userRepo, cleanup, nil
+		return repos, cleanup, nil
 	}
 
 	return nil, nil, fmt.Errorf("unsupported repository type: %s", repositoryType)
 }
 
-// initializeServices sets up the business logic services with optional caching
-func initializeServices(cfg *config.Config, userRepo domain.UserRepository, log *logger.Logger) (domain.UserService, func()) {
-	tokenService := service.NewJWTTokenService(cfg)
-	baseUserService := service.NewUserService(userRepo, tokenService)
+// initializeRoles sets up admin-defined roles. The built-in roles are created
+// when missing and the roles are loaded before requests are served.
+func initializeRoles(cfg *config.Config, repos *repositories, auditService domain.AuditService, log *logger.Logger) (domain.RoleService, error) {
+	builtIn := builtInRoles(cfg)
+	roleService, err := service.NewRoleService(repos.roles, repos.users, auditService, cfg.Policy.Permissions, builtIn, cfg.Roles.Custom.RefreshInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid POLICY_PERMISSIONS: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), RoleLoadTimeout)
+	defer cancel()
+	if err := roleService.Reload(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	log.Info("Custom roles enabled", "built_in_roles", builtIn, "refresh_interval", cfg.Roles.Custom.RefreshInterval)
+	return roleService, nil
+}
+
+// builtInRoles lists the roles the configuration refers to, sorted
+func builtInRoles(cfg *config.Config) []string {
+	seen := map[string]bool{"admin": true, cfg.Roles.Default: true}
+	for _, role := range cfg.Roles.SelfAssignable {
+		seen[role] = true
+	}
+	for _, role := range cfg.Roles.NoteRoles {
+		seen[role] = true
+	}
+	for role := range cfg.Roles.Scopes {
+		seen[role] = true
+	}
+	for role := range cfg.Policy.Permissions {
+		seen[role] = true
+	}
+	for _, role := range cfg.OIDC.RoleMapping {
+		seen[role] = true
+	}
+	delete(seen, "")
+
+	roles := make([]string, 0, len(seen))
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// initializeRepositoryMigration wraps the user repository so its writes are
+// mirrored to the REPOSITORY_MIGRATION_TARGET store, which must differ from
+// REPOSITORY_TYPE. The returned cleanup disconnects the target.
+func initializeRepositoryMigration(cfg *config.Config, repos *repositories, log *logger.Logger) (func(), error) {
+	migrationCfg := cfg.Database.Migration
+	if migrationCfg.Target == "" {
+		return func() {}, nil
+	}
+	repositoryType := os.Getenv("REPOSITORY_TYPE")
+	if repositoryType == "" {
+		repositoryType = "memory"
+	}
+	if migrationCfg.Target == repositoryType {
+		return nil, fmt.Errorf("REPOSITORY_MIGRATION_TARGET must differ from REPOSITORY_TYPE (%s)", repositoryType)
+	}
+
+	var target domain.UserRepository
+	cleanup := func() {}
+	switch migrationCfg.Target {
+	case "memory":
+		target = repository.NewMemoryUserRepository()
+	case "mongodb":
+		mongoClient, err := repository.NewMongoClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to the MongoDB migration target: %w", err)
+		}
+		target = repository.NewMongoUserRepository(mongoClient, cfg)
+		cleanup = func() {
+			ctx, cancel := context.WithTimeout(context.Background(), MongoDisconnectTimeout)
+			defer cancel()
+			if err := mongoClient.Disconnect(ctx); err != nil {
+				log.Error("Error disconnecting from the MongoDB migration target", "error", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported REPOSITORY_MIGRATION_TARGET: %s", migrationCfg.Target)
+	}
+
+	log.Info("Repository migration mode enabled", "from", repositoryType, "to", migrationCfg.Target, "shadow_reads", migrationCfg.ShadowReads)
+	repos.migration = repository.NewMigratingUserRepository(repos.users, target, migrationCfg)
+	repos.users = repos.migration
+	return cleanup, nil
+}
+
+// initializeFieldEncryption wraps the repositories of sensitive fields with
+// field encryption when enabled. Keys from a file or URL come with a key
+// provider, which the caller starts.
+func initializeFieldEncryption(cfg *config.Config, repos *repositories, log *logger.Logger) (*keys.Provider, error) {
+	if !cfg.Encryption.Enabled {
+		return nil, nil
+	}
+
+	var keyProvider domain.KeyProvider
+	var reloading *keys.Provider
+	if cfg.Encryption.Keys.Source == config.JWTKeySourceStatic {
+		if cfg.Encryption.Key == "" {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEY is required when field encryption is enabled")
+		}
+		key := domain.SigningKey{ID: cfg.Encryption.KeyID, Secret: []byte(cfg.Encryption.Key)}
+		keyProvider = keys.NewStaticProvider(&domain.KeySet{Current: key, Keys: map[string]domain.SigningKey{key.ID: key}})
+	} else {
+		source, err := keys.NewSource(cfg.Encryption.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("field encryption keys: %w", err)
+		}
+		reloading = keys.NewNamedProvider("field encryption", source, cfg.Encryption.Keys)
+		keyProvider = reloading
+	}
+
+	cipher := fieldcrypt.New(keyProvider)
+	repos.notes = repository.NewEncryptedNoteRepository(repos.notes, cipher)
+	log.Info("Field encryption enabled", "key_source", cfg.Encryption.Keys.Source)
+	return reloading, nil
+}
+
+// registerHooks is the place for integrators to attach custom behavior to
+// lifecycle events without modifying handlers or services, e.g.
+//
+//	registry.OnUserRegistered("crm-sync", func(ctx context.Context, user *domain.UserResponse) error {
+//		return crmClient.CreateContact(ctx, user.Email, user.Name)
+//	})
+func registerHooks(_ *hooks.Registry) {
+	// No hooks are registered by default
+}
+
+// initializeHMACMiddleware sets up request signature verification when enabled.
+// Nonces are stored in Redis when a cache is available so replay protection
+// holds across instances; otherwise they are kept in process memory.
+func initializeHMACMiddleware(cfg *config.Config, cacheService cache.Service, log *logger.Logger) *middleware.HMACMiddleware {
+	if !cfg.HMAC.Enabled {
+		return nil
+	}
 
-	cacheType := os.Getenv("CACHE_TYPE")
-	if cacheType != "redis" {
+	if len(cfg.HMAC.Clients) == 0 {
+		log.Warn("HMAC request signing enabled but no clients configured")
+	}
+
+	var nonces middleware.NonceStore
+	if cacheService != nil {
+		nonces = middleware.NewCacheNonceStore(cacheService)
+	} else {
+		log.Warn("HMAC nonce cache using in-memory store; replay protection is per instance")
+		nonces = middleware.NewMemoryNonceStore()
+	}
+
+	log.Info("HMAC request signing enabled", "clients", len(cfg.HMAC.Clients))
+	return middleware.NewHMACMiddleware(&cfg.HMAC, nonces)
+}
+
+// initializeSIEMShipper creates the security event shipper when SIEM export is enabled
+func initializeSIEMShipper(cfg *config.Config, log *logger.Logger) (*siem.Shipper, error) {
+	if !cfg.SIEM.Enabled {
+		return nil, nil
+	}
+
+	sink, err := siem.NewSink(cfg.SIEM)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("SIEM export enabled", "transport", cfg.SIEM.Transport)
+	return siem.NewShipper(sink, cfg.SIEM), nil
+}
+
+// initializeResetTokenStore picks Redis for reset tokens when a cache is
+// available so tokens remain valid across instances
+func initializeResetTokenStore(cacheService cache.Service) domain.ResetTokenStore {
+	if cacheService != nil {
+		return cache.NewResetTokenStore(cacheService)
+	}
+	return repository.NewMemoryResetTokenStore()
+}
+
+// initializeWebAuthnSessionStore picks Redis for passkey ceremony sessions
+// when a cache is available, so a ceremony may finish on another instance
+func initializeWebAuthnSessionStore(cacheService cache.Service) domain.WebAuthnSessionStore {
+	if cacheService != nil {
+		return cache.NewWebAuthnSessionStore(cacheService)
+	}
+	return repository.NewMemoryWebAuthnSessionStore()
+}
+
+// initializeOIDCStateStore keeps single sign-on states in Redis when a cache
+// is available, so the callback can reach any instance
+func initializeOIDCStateStore(cacheService cache.Service) domain.OIDCStateStore {
+	if cacheService != nil {
+		return cache.NewOIDCStateStore(cacheService)
+	}
+	return repository.NewMemoryOIDCStateStore()
+}
+
+// initializeLimiter picks the store of rate limit and lockout counters.
+// Redis sliding windows hold limits across instances; in memory each
+// instance counts on its own.
+func initializeLimiter(cfg *config.Config, cacheService cache.Service, log *logger.Logger) (ratelimit.Limiter, error) {
+	limiter, err := cache.NewLimiter(cfg.RateLimit.Store, cacheService)
+	if err != nil {
+		return nil, err
+	}
+	if cacheService != nil && cfg.RateLimit.Store != config.RateLimitStoreMemory {
+		log.Info("Rate limit counters stored in Redis")
+	} else {
+		log.Info("Rate limit counters stored in memory; limits apply per instance")
+	}
+	return limiter, nil
+}
+
+// initializePresenceStore picks Redis for last-seen times when a cache is
+// available so activity is debounced and shown across instances
+func initializePresenceStore(cacheService cache.Service) domain.PresenceStore {
+	if cacheService != nil {
+		return cache.NewPresenceStore(cacheService)
+	}
+	return repository.NewMemoryPresenceStore()
+}
+
+// initializeRefreshTokenStore keeps refresh tokens in MongoDB when it is the
+// repository, otherwise in Redis when a cache is available so sessions survive
+// restarts and are shared across instances
+func initializeRefreshTokenStore(repos *repositories, cacheService cache.Service) domain.RefreshTokenStore {
+	if os.Getenv("REPOSITORY_TYPE") != "mongodb" && cacheService != nil {
+		return cache.NewRefreshTokenStore(cacheService)
+	}
+	return repos.refreshTokens
+}
+
+// initializeAnnouncementStore keeps the announcement in MongoDB when it is
+// the repository, otherwise in Redis when a cache is available so every
+// instance shows the same one
+func initializeAnnouncementStore(repos *repositories, cacheService cache.Service) domain.AnnouncementStore {
+	if os.Getenv("REPOSITORY_TYPE") != "mongodb" && cacheService != nil {
+		return cache.NewAnnouncementStore(cacheService)
+	}
+	return repos.announcements
+}
+
+// initializeUsageCounterStore keeps the daily usage counters in Redis when a
+// cache is available, so every instance adds to the same counters
+func initializeUsageCounterStore(cacheService cache.Service) domain.UsageCounterStore {
+	if cacheService != nil {
+		return cache.NewUsageCounterStore(cacheService)
+	}
+	return repository.NewMemoryUsageCounterStore()
+}
+
+// initializeTokenRevocationStore keeps the token denylist in Redis when a
+// cache is available, so a logout is honoured by every instance
+func initializeTokenRevocationStore(cacheService cache.Service) domain.TokenRevocationStore {
+	if cacheService != nil {
+		return cache.NewTokenRevocationStore(cacheService)
+	}
+	return repository.NewMemoryTokenRevocationStore()
+}
+
+// initializeCache connects to Redis when CACHE_TYPE=redis. The returned
+// cache service is nil when caching is disabled or unavailable.
+func initializeCache(cfg *config.Config, log *logger.Logger) (cache.Service, func()) {
+	if cfg.Cache.Type != "redis" {
 		log.Info("Cache disabled or not configured")
-		return baseUserService, func() {}
+		return nil, func() {}
 	}
 
 	log.Info("Initializing Redis cache")
 	cacheService, err := cache.NewRedisCache(cfg)
 	if err != nil {
 		log.Warn("Failed to initialize Redis cache, using service without cache", "error", err)
-		return baseUserService, func() {}
+		return nil, func() {}
 	}
 
 	log.Info("Redis cache initialized successfully")
-	userService := service.NewCachedUserService(baseUserService, cacheService, cfg.Cache.Redis.TTL)
-
+	cacheService = cache.NewDegradingCache(cacheService, cfg.Cache.Degradation)
 	cleanup := func() {
 		log.Info("Closing cache connection")
 		if err := cacheService.Close(); err != nil {
@@ -189,5 +1231,195 @@ func initializeServices(cfg *config.Config, userRepo domain.UserRepository, log
 		}
 	}
 
-	return userService, cleanup
+	return cacheService, cleanup
+}
+
+// initializeTokenService selects JWT or opaque server-side tokens. Opaque
+// tokens live in Redis when a cache is available, otherwise in process memory.
+// JWT keys from a file or URL come with a key provider, which the caller starts.
+func initializeTokenService(
+	cfg *config.Config,
+	cacheService cache.Service,
+	log *logger.Logger,
+) (domain.TokenService, *keys.Provider, error) {
+	switch cfg.JWT.TokenFormat {
+	case config.TokenFormatJWT:
+		if err := service.ValidateTrustedIssuers(cfg.JWT); err != nil {
+			return nil, nil, fmt.Errorf("invalid JWT_TRUSTED_ISSUERS: %w", err)
+		}
+		if len(cfg.JWT.TrustedIssuers) > 0 {
+			log.Info("Accepting tokens of trusted issuers", "tenants", len(cfg.JWT.TrustedIssuers))
+		}
+		if cfg.JWT.Keys.Source == config.JWTKeySourceStatic {
+			return service.NewJWTTokenService(cfg), nil, nil
+		}
+		source, err := keys.NewSource(cfg.JWT.Keys)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyProvider := keys.NewProvider(source, cfg.JWT.Keys)
+		return service.NewJWTTokenServiceWithKeys(cfg, keyProvider), keyProvider, nil
+	case config.TokenFormatOpaque:
+		if len(cfg.JWT.TrustedIssuers) > 0 {
+			log.Warn("JWT_TRUSTED_ISSUERS is ignored with opaque tokens")
+		}
+		var store domain.TokenStore
+		if cacheService != nil {
+			store = cache.NewTokenStore(cacheService)
+		} else {
+			log.Warn("Opaque tokens stored in memory; they are lost on restart and not shared across instances")
+			store = repository.NewMemoryTokenStore()
+		}
+		log.Info("Using opaque access tokens")
+		return service.NewOpaqueTokenService(store, cfg.JWT.Expiration), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported token format: %s", cfg.JWT.TokenFormat)
+	}
+}
+
+// initializeCookieSessions returns the session cookies of cookie auth mode, or
+// nil in bearer mode. The cookie holds an opaque token, so sessions live in the
+// token store and end server-side on logout or revocation.
+func initializeCookieSessions(cfg *config.Config, log *logger.Logger) (*middleware.CookieSessions, error) {
+	switch cfg.Auth.Mode {
+	case config.AuthModeBearer:
+		return nil, nil
+	case config.AuthModeCookie:
+		if cfg.JWT.TokenFormat != config.TokenFormatOpaque {
+			return nil, fmt.Errorf("AUTH_MODE=cookie requires TOKEN_FORMAT=opaque")
+		}
+		cookies, err := middleware.NewCookieSessions(cfg.Auth.Cookie, cfg.JWT.Expiration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session cookie configuration: %w", err)
+		}
+		log.Info("Cookie auth mode enabled", "cookie", cfg.Auth.Cookie.Name, "same_site", cfg.Auth.Cookie.SameSite)
+		return cookies, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth mode: %s", cfg.Auth.Mode)
+	}
+}
+
+// initializeResponseAssembler registers the sources that enrich user responses
+func initializeResponseAssembler(
+	cfg *config.Config,
+	prefsRepo domain.PreferencesRepository,
+	presence domain.PresenceService,
+) *assembler.Assembler {
+	responseAssembler := assembler.New()
+	responseAssembler.Register(assembler.NewPreferencesSource(prefsRepo), cfg.Profile.SourceTimeout)
+	if cfg.Profile.AvatarURLTemplate != "" {
+		responseAssembler.Register(assembler.NewAvatarSource(cfg.Profile.AvatarURLTemplate), cfg.Profile.SourceTimeout)
+	}
+	if presence != nil {
+		responseAssembler.Register(assembler.NewPresenceSource(presence), cfg.Profile.SourceTimeout)
+	}
+	return responseAssembler
+}
+
+// telemetryFeatures lists the optional features enabled in this deployment
+// for telemetry reports. Only feature names are reported, never their settings.
+func telemetryFeatures(cfg *config.Config) []string {
+	repositoryType := "mongodb"
+	if t := os.Getenv("REPOSITORY_TYPE"); t == "memory" || t == "" {
+		repositoryType = "memory"
+	}
+	features := []string{"repository:" + repositoryType, "token:" + cfg.JWT.TokenFormat}
+	if cfg.Cache.Type == "redis" {
+		features = append(features, "redis")
+	}
+	optional := []struct {
+		name    string
+		enabled bool
+	}{
+		{"hmac", cfg.HMAC.Enabled},
+		{"recovery", cfg.Recovery.Enabled},
+		{"password_reset", cfg.PasswordReset.Enabled},
+		{"public_profiles", cfg.PublicProfile.Enabled},
+		{"field_encryption", cfg.Encryption.Enabled},
+		{"mongodb_write_buffer", repositoryType == "mongodb" && cfg.Database.MongoDB.WriteBuffer.Enabled},
+		{"repository_migration", cfg.Database.Migration.Target != ""},
+		{"presence", cfg.Presence.Enabled},
+		{"webauthn", cfg.WebAuthn.Enabled},
+		{"oidc", cfg.OIDC.Enabled},
+		{"api_keys", cfg.APIKeys.Enabled},
+		{"client_credentials", cfg.Clients.Enabled},
+		{"token_exchange", cfg.Clients.Enabled && cfg.Clients.TokenExchange},
+		{"custom_roles", cfg.Roles.Custom.Enabled},
+		{"email_outbox", cfg.Email.Outbox.Enabled},
+		{"role_recheck", cfg.Roles.RecheckInterval > 0},
+		{"route_overrides", cfg.JWT.RouteOverrides.Enabled},
+		{"announcements", cfg.Announcements.Enabled},
+		{"usage", cfg.Usage.Enabled},
+		{"sessions", cfg.JWT.TrackSessions},
+		{"login_history", cfg.LoginHistory.Enabled},
+		{"suspicious_login", cfg.SuspiciousLogin.Enabled},
+		{"invites", cfg.Invites.Enabled},
+		{"invite_only", cfg.Invites.InviteOnly},
+		{"demo", cfg.Demo.Enabled},
+		{"admin_search", cfg.AdminSearch.Enabled},
+		{"cookie_auth", cfg.Auth.Mode == config.AuthModeCookie},
+		{"rate_limit", cfg.RateLimit.Enabled},
+		{"dedup", cfg.Dedup.Enabled},
+		{"content_policy", cfg.ContentPolicy.Enabled},
+		{"email_domain_policy", cfg.EmailDomains.Enabled},
+		{"siem", cfg.SIEM.Enabled},
+		{"account_transfer", cfg.Transfer.Enabled},
+		{"retention", cfg.Retention.Enabled},
+		{"login_throttle", cfg.LoginThrottle.Enabled},
+		{"snapshots", cfg.Snapshot.Enabled},
+		{"jwt_key_rotation", cfg.JWT.Keys.Source != config.JWTKeySourceStatic},
+		{"breached_password_check", cfg.Passwords.BreachCheck},
+	}
+	for _, feature := range optional {
+		if feature.enabled {
+			features = append(features, feature.name)
+		}
+	}
+	return features
+}
+
+// initializeCacheStrategies validates the per-operation cache strategies and
+// starts the write-behind queue. The queue is nil when caching is disabled.
+func initializeCacheStrategies(
+	cfg *config.Config,
+	cacheService cache.Service,
+	log *logger.Logger,
+) ([]service.CachedUserServiceOption, *cache.WriteBehindQueue, error) {
+	strategies, err := service.ParseCacheStrategies(cfg.Cache.Strategies)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CACHE_STRATEGIES: %w", err)
+	}
+	if cacheService == nil {
+		return nil, nil, nil
+	}
+
+	if len(strategies) > 0 {
+		log.Info("Cache strategy overrides configured", "strategies", cfg.Cache.Strategies)
+	}
+	if err := cache.ValidateWriteBehindConfig(cfg.Cache.WriteBehind); err != nil {
+		return nil, nil, fmt.Errorf("invalid write-behind cache configuration: %w", err)
+	}
+	writeBehind := cache.NewWriteBehindQueue(cacheService, cfg.Cache.WriteBehind)
+	return []service.CachedUserServiceOption{
+		service.WithCacheStrategies(strategies),
+		service.WithWriteBehindQueue(writeBehind),
+		service.WithListPageCache(cfg.Cache.ListPages),
+	}, writeBehind, nil
+}
+
+// initializeServices sets up the business logic services, adding caching
+// when a cache service is available
+func initializeServices(
+	cfg *config.Config,
+	userRepo domain.UserRepository,
+	tokenService domain.TokenService,
+	cacheService cache.Service,
+	cacheOpts []service.CachedUserServiceOption,
+	userServiceOpts ...service.UserServiceOption,
+) domain.UserService {
+	userService := service.NewUserService(userRepo, tokenService, userServiceOpts...)
+	if cacheService != nil {
+		userService = service.NewCachedUserService(userService, cacheService, cfg.Cache.Redis.TTL, cacheOpts...)
+	}
+	return userService
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+	"demo-go/internal/repository"
+)
+
+// Re-encryption command settings
+const (
+	reencryptTimeout  = 30 * time.Minute
+	reencryptPageSize = 200
+)
+
+// reencryptReport is the JSON result of a re-encryption run
+type reencryptReport struct {
+	DryRun         bool `json:"dry_run"`
+	UsersScanned   int  `json:"users_scanned"`
+	NotesRewritten int  `json:"notes_rewritten"`
+}
+
+// runReencryptFieldsCommand rewrites encrypted fields that are still
+// plaintext or sealed with a previous key under the current key, writes a
+// JSON report to out and returns the process exit code. Run it after a key
+// rotation, before the previous key is removed from the key set.
+func runReencryptFieldsCommand(cfg *config.Config, args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("reencrypt-fields", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "count the records that would be rewritten without rewriting them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !cfg.Encryption.Enabled {
+		fmt.Fprintln(os.Stderr, "Field encryption is disabled; set FIELD_ENCRYPTION_ENABLED=true")
+		return 2
+	}
+
+	log := logger.GetGlobal().ForComponent("reencrypt-command")
+	repos, cleanup, err := initializeRepositories(cfg, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Repository unavailable: %v\n", err)
+		return 1
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reencryptTimeout)
+	defer cancel()
+
+	encryptionKeys, err := initializeFieldEncryption(cfg, repos, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Field encryption unavailable: %v\n", err)
+		return 1
+	}
+	if encryptionKeys != nil {
+		if err := encryptionKeys.Refresh(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load field encryption keys: %v\n", err)
+			return 1
+		}
+	}
+	notes := repos.notes.(*repository.EncryptedNoteRepository)
+
+	report := &reencryptReport{DryRun: *dryRun}
+	for offset := 0; ; offset += reencryptPageSize {
+		users, err := repos.users.List(ctx, reencryptPageSize, offset, "id")
+		if err == nil && len(users) > 0 {
+			ids := make([]string, 0, len(users))
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			var rewritten int
+			rewritten, err = notes.Reencrypt(ctx, ids, *dryRun)
+			report.UsersScanned += len(users)
+			report.NotesRewritten += rewritten
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Re-encryption failed: %v\n", err)
+			writeReencryptReport(out, report)
+			return 1
+		}
+		if len(users) < reencryptPageSize {
+			break
+		}
+	}
+
+	if !writeReencryptReport(out, report) {
+		return 1
+	}
+	return 0
+}
+
+// writeReencryptReport writes report as JSON and reports whether that worked
+func writeReencryptReport(out io.Writer, report *reencryptReport) bool {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write re-encryption report: %v\n", err)
+		return false
+	}
+	return true
+}
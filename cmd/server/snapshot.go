@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/service"
+	"demo-go/internal/snapshot"
+)
+
+// Snapshot command settings
+const (
+	snapshotCommandTimeout = 10 * time.Minute
+	// snapshotCLIActor is recorded as the actor of snapshots taken and
+	// restored from the command line
+	snapshotCLIActor = "cli"
+)
+
+const snapshotUsage = `usage: server snapshot <command>
+
+commands:
+  create                             snapshot the users collection
+  list                               list stored snapshots
+  show <name>                        describe and verify a snapshot
+  restore [--confirm TOKEN] <name>   preview a restore, or run it with the token from the preview`
+
+// runSnapshotCommand creates, lists or restores users snapshots in the
+// configured snapshot storage, writes the JSON result to out and returns the
+// process exit code. It works whether or not the admin API is enabled.
+func runSnapshotCommand(cfg *config.Config, args []string, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, snapshotUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("snapshot "+args[0], flag.ContinueOnError)
+	confirm := flags.String("confirm", "", "confirmation token printed by a restore preview")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	log := logger.GetGlobal().ForComponent("snapshot-command")
+	store, err := snapshot.NewStore(cfg.Snapshot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Snapshot storage unavailable: %v\n", err)
+		return 1
+	}
+	repos, cleanup, err := initializeRepositories(cfg, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Repository unavailable: %v\n", err)
+		return 1
+	}
+	defer cleanup()
+	// Restores drop cached users so running instances do not serve stale copies
+	cacheService, cacheCleanup := initializeCache(cfg, log)
+	defer cacheCleanup()
+
+	snapshots := service.NewSnapshotService(store, repos.users, service.NewAuditService(repos.audit), cacheService, cfg.Snapshot)
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotCommandTimeout)
+	defer cancel()
+
+	var result interface{}
+	switch command, name := args[0], flags.Arg(0); {
+	case command == "create" && flags.NArg() == 0:
+		result, err = snapshots.Create(ctx, snapshotCLIActor)
+	case command == "list" && flags.NArg() == 0:
+		result, err = snapshots.List(ctx)
+	case command == "show" && flags.NArg() == 1:
+		result, err = snapshots.Get(ctx, name)
+	case command == "restore" && flags.NArg() == 1 && *confirm == "":
+		var preview *domain.SnapshotRestorePreview
+		if preview, err = snapshots.PreviewRestore(ctx, snapshotCLIActor, name); err == nil {
+			fmt.Fprintf(os.Stderr, "Nothing was changed. To replace all %d users with the %d users of %s, run:\n  server snapshot restore --confirm %s %s\n",
+				preview.CurrentUsers, preview.Snapshot.UserCount, name, preview.ConfirmationToken, name)
+		}
+		result = preview
+	case command == "restore" && flags.NArg() == 1:
+		result, err = snapshots.Restore(ctx, snapshotCLIActor, name, *confirm)
+	default:
+		fmt.Fprintln(os.Stderr, snapshotUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Snapshot %s failed: %v\n", args[0], err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write snapshot result: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+// Package adminui serves the embedded admin dashboard, a small single-page
+// application compiled into the binary so deployments need no separate frontend.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// PathPrefix is the URL prefix the dashboard is served under
+const PathPrefix = "/admin-ui/"
+
+//go:embed static
+var staticFiles embed.FS
+
+// Handler returns an http.Handler serving the dashboard assets under PathPrefix
+func Handler() http.Handler {
+	assets, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		// The embedded directory is fixed at build time, so this cannot fail at runtime
+		panic("adminui: missing embedded static directory: " + err.Error())
+	}
+
+	fileServer := http.StripPrefix(PathPrefix, http.FileServer(http.FS(assets)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The shell holds no data; everything sensitive is fetched with the admin's token
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,178 @@
+// Admin dashboard client. Authenticates through the regular /auth/login flow
+// and calls the admin API with the resulting bearer token.
+(function () {
+  "use strict";
+
+  const TOKEN_KEY = "demo-go-admin-token";
+  const PAGE_SIZE = 20;
+
+  let token = sessionStorage.getItem(TOKEN_KEY);
+  let offset = 0;
+
+  const $ = (id) => document.getElementById(id);
+
+  async function api(method, path, body) {
+    const headers = { "Content-Type": "application/json" };
+    if (token) {
+      headers.Authorization = "Bearer " + token;
+    }
+
+    const res = await fetch(path, {
+      method: method,
+      headers: headers,
+      body: body ? JSON.stringify(body) : undefined,
+    });
+
+    if (res.status === 401) {
+      signOut();
+      throw new Error("Session expired, please sign in again");
+    }
+
+    const payload = await res.json().catch(() => ({}));
+    if (!res.ok || payload.success === false) {
+      const err = new Error(payload.message || res.statusText);
+      err.status = res.status;
+      throw err;
+    }
+    return payload.data;
+  }
+
+  function showError(id, err) {
+    $(id).textContent = err ? err.message : "";
+  }
+
+  function signOut() {
+    token = null;
+    sessionStorage.removeItem(TOKEN_KEY);
+    $("app-view").hidden = true;
+    $("session").hidden = true;
+    $("login-view").hidden = false;
+  }
+
+  function showApp(user) {
+    $("login-view").hidden = true;
+    $("app-view").hidden = false;
+    $("session").hidden = false;
+    $("session-user").textContent = user ? user.email : "";
+    loadUsers();
+  }
+
+  async function loadUsers() {
+    showError("app-error", null);
+    try {
+      const data = await api("GET", "/api/v1/admin/users?limit=" + PAGE_SIZE + "&offset=" + offset);
+      const rows = (data.users || []).map(function (u) {
+        const tr = document.createElement("tr");
+        [u.id, u.name, u.email, u.role, new Date(u.created_at).toLocaleString()].forEach(function (v) {
+          const td = document.createElement("td");
+          td.textContent = v;
+          tr.appendChild(td);
+        });
+        const actions = document.createElement("td");
+        const del = document.createElement("button");
+        del.type = "button";
+        del.textContent = "Delete";
+        del.addEventListener("click", function () { deleteUser(u); });
+        actions.appendChild(del);
+        tr.appendChild(actions);
+        return tr;
+      });
+      $("users-body").replaceChildren.apply($("users-body"), rows);
+
+      const page = Math.floor(offset / PAGE_SIZE) + 1;
+      const pages = Math.max(1, Math.ceil(data.total / PAGE_SIZE));
+      $("users-page").textContent = "Page " + page + " of " + pages + " (" + data.total + " users)";
+      $("users-prev").disabled = offset === 0;
+      $("users-next").disabled = offset + PAGE_SIZE >= data.total;
+    } catch (err) {
+      showError("app-error", err);
+    }
+  }
+
+  async function deleteUser(user) {
+    if (!window.confirm("Delete " + user.email + "?")) {
+      return;
+    }
+    try {
+      await api("DELETE", "/api/v1/admin/users/" + encodeURIComponent(user.id));
+      loadUsers();
+    } catch (err) {
+      showError("app-error", err);
+    }
+  }
+
+  // loadJSONPanel renders an admin endpoint into a <pre>, treating 404 as
+  // "subsystem not enabled" so the dashboard works on minimal deployments.
+  async function loadJSONPanel(path, target) {
+    showError("app-error", null);
+    try {
+      const data = await api("GET", path);
+      $(target).textContent = JSON.stringify(data, null, 2);
+    } catch (err) {
+      if (err.status === 404 || err.status === 405) {
+        $(target).textContent = "Not enabled on this server.";
+        return;
+      }
+      showError("app-error", err);
+    }
+  }
+
+  const panels = {
+    users: loadUsers,
+    audit: function () { loadJSONPanel("/api/v1/admin/audit-events", "audit-body"); },
+    cache: function () { loadJSONPanel("/api/v1/admin/cache/stats", "cache-body"); },
+    flags: function () { loadJSONPanel("/api/v1/admin/feature-flags", "flags-body"); },
+  };
+
+  document.querySelectorAll("nav button").forEach(function (btn) {
+    btn.addEventListener("click", function () {
+      document.querySelectorAll("nav button").forEach(function (b) { b.classList.remove("active"); });
+      document.querySelectorAll(".panel").forEach(function (p) { p.hidden = true; });
+      btn.classList.add("active");
+      $("panel-" + btn.dataset.panel).hidden = false;
+      panels[btn.dataset.panel]();
+    });
+  });
+
+  $("users-prev").addEventListener("click", function () {
+    offset = Math.max(0, offset - PAGE_SIZE);
+    loadUsers();
+  });
+  $("users-next").addEventListener("click", function () {
+    offset += PAGE_SIZE;
+    loadUsers();
+  });
+  $("logout").addEventListener("click", signOut);
+
+  $("login-form").addEventListener("submit", async function (event) {
+    event.preventDefault();
+    showError("login-error", null);
+    const form = new FormData(event.target);
+    try {
+      const data = await api("POST", "/auth/login", {
+        email: form.get("email"),
+        password: form.get("password"),
+      });
+      if (!data.user || data.user.role !== "admin") {
+        throw new Error("This account does not have admin access");
+      }
+      token = data.token;
+      sessionStorage.setItem(TOKEN_KEY, token);
+      showApp(data.user);
+    } catch (err) {
+      showError("login-error", err);
+    }
+  });
+
+  if (token) {
+    api("GET", "/api/v1/profile")
+      .then(function (user) {
+        if (user.role !== "admin") {
+          signOut();
+          return;
+        }
+        showApp(user);
+      })
+      .catch(function () { signOut(); });
+  }
+})();
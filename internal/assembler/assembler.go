@@ -0,0 +1,142 @@
+// Package assembler enriches user responses with data held outside the user
+// record, such as avatar URLs and display preferences. Each source is fetched
+// concurrently under its own timeout, and a slow or failing source only leaves
+// its own fields empty.
+package assembler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// DefaultSourceTimeout bounds each source fetch when no timeout is given
+const DefaultSourceTimeout = 200 * time.Millisecond
+
+// ApplyFunc copies fetched data onto a single user response
+type ApplyFunc func(user *domain.UserResponse)
+
+// Source loads one kind of data for a batch of users
+type Source interface {
+	// Field is the UserResponse JSON field the source fills, used to skip
+	// sources that were not requested in a sparse fieldset
+	Field() string
+	// Fetch loads data for the users and returns a function applying it to each one.
+	// It must not modify the users itself; results are applied after all sources finish.
+	Fetch(ctx context.Context, users []*domain.UserResponse) (ApplyFunc, error)
+}
+
+// registeredSource pairs a source with its fetch timeout
+type registeredSource struct {
+	source  Source
+	timeout time.Duration
+}
+
+// Assembler composes user responses from the registered sources
+type Assembler struct {
+	sources []registeredSource
+	logger  *logger.Logger
+}
+
+// New creates an assembler with no sources
+func New() *Assembler {
+	return &Assembler{
+		logger: logger.GetGlobal().ForComponent("response-assembler"),
+	}
+}
+
+// Register adds a source fetched with the given timeout (DefaultSourceTimeout when zero or less).
+// It must be called before the assembler is used.
+func (a *Assembler) Register(source Source, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultSourceTimeout
+	}
+	a.sources = append(a.sources, registeredSource{source: source, timeout: timeout})
+}
+
+// Assemble enriches the users in place. When fields are given, only sources
+// for selected fields are fetched.
+func (a *Assembler) Assemble(ctx context.Context, users []*domain.UserResponse, fields ...string) {
+	if len(users) == 0 {
+		return
+	}
+
+	sources := a.selectSources(fields)
+	if len(sources) == 0 {
+		return
+	}
+
+	applies := make([]ApplyFunc, len(sources))
+	var wg sync.WaitGroup
+	for i, registered := range sources {
+		wg.Add(1)
+		go func(i int, registered registeredSource) {
+			defer wg.Done()
+
+			apply, err := fetch(ctx, registered, users)
+			if err != nil {
+				a.logger.Warn("Response source failed, leaving its fields empty",
+					"field", registered.source.Field(), "error", err)
+				return
+			}
+			applies[i] = apply
+		}(i, registered)
+	}
+	wg.Wait()
+
+	for _, apply := range applies {
+		if apply == nil {
+			continue
+		}
+		for _, user := range users {
+			apply(user)
+		}
+	}
+}
+
+// fetch runs a source under its timeout. A source that ignores cancellation is
+// abandoned when the timeout expires rather than holding up the response.
+func fetch(ctx context.Context, registered registeredSource, users []*domain.UserResponse) (ApplyFunc, error) {
+	ctx, cancel := context.WithTimeout(ctx, registered.timeout)
+	defer cancel()
+
+	type result struct {
+		apply ApplyFunc
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		apply, err := registered.source.Fetch(ctx, users)
+		done <- result{apply: apply, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.apply, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// selectSources returns the sources whose field is in the sparse fieldset
+func (a *Assembler) selectSources(fields []string) []registeredSource {
+	if len(fields) == 0 {
+		return a.sources
+	}
+
+	selected := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		selected[field] = true
+	}
+
+	var sources []registeredSource
+	for _, registered := range a.sources {
+		if selected[registered.source.Field()] {
+			sources = append(sources, registered)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,102 @@
+package assembler
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"demo-go/internal/domain"
+)
+
+// avatarSource builds avatar URLs from a storage URL template
+type avatarSource struct {
+	template string
+}
+
+// NewAvatarSource creates a source that sets avatar_url by substituting the
+// URL-escaped user ID for {id} in the template, e.g.
+// "https://cdn.example.com/avatars/{id}.png"
+func NewAvatarSource(template string) Source {
+	return &avatarSource{template: template}
+}
+
+// Field returns the field filled by the source
+func (s *avatarSource) Field() string {
+	return "avatar_url"
+}
+
+// Fetch computes the avatar URL for each user
+func (s *avatarSource) Fetch(_ context.Context, _ []*domain.UserResponse) (ApplyFunc, error) {
+	return func(user *domain.UserResponse) {
+		user.AvatarURL = AvatarURL(s.template, user.ID)
+	}, nil
+}
+
+// AvatarURL substitutes the URL-escaped user ID for {id} in template
+func AvatarURL(template, userID string) string {
+	return strings.ReplaceAll(template, "{id}", url.PathEscape(userID))
+}
+
+// preferencesSource loads display preferences from the preferences repository
+type preferencesSource struct {
+	repo domain.PreferencesRepository
+}
+
+// NewPreferencesSource creates a source that sets preferences from saved display preferences
+func NewPreferencesSource(repo domain.PreferencesRepository) Source {
+	return &preferencesSource{repo: repo}
+}
+
+// Field returns the field filled by the source
+func (s *preferencesSource) Field() string {
+	return "preferences"
+}
+
+// Fetch loads the preferences of all users in one repository call
+func (s *preferencesSource) Fetch(ctx context.Context, users []*domain.UserResponse) (ApplyFunc, error) {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	prefs, err := s.repo.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(user *domain.UserResponse) {
+		user.Preferences = prefs[user.ID]
+	}, nil
+}
+
+// presenceSource derives online status from last-seen tracking
+type presenceSource struct {
+	presence domain.PresenceService
+}
+
+// NewPresenceSource creates a source that sets presence from last-seen times
+func NewPresenceSource(presence domain.PresenceService) Source {
+	return &presenceSource{presence: presence}
+}
+
+// Field returns the field filled by the source
+func (s *presenceSource) Field() string {
+	return "presence"
+}
+
+// Fetch loads the presence of all users in one call
+func (s *presenceSource) Fetch(ctx context.Context, users []*domain.UserResponse) (ApplyFunc, error) {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	presence, err := s.presence.Presence(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(user *domain.UserResponse) {
+		user.Presence = presence[user.ID]
+	}, nil
+}
@@ -0,0 +1,57 @@
+// Package budget derives per-operation deadlines from the time a request has
+// left. Storage calls take the smaller of their configured timeout and the
+// remaining request budget, and calls that cannot finish in time fail fast
+// with domain.ErrRequestTimeout instead of starting work that will be abandoned.
+package budget
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// MinOperationBudget is the least remaining time an operation is started with
+const MinOperationBudget = 5 * time.Millisecond
+
+// Remaining reports how long ctx has left, and false when it has no deadline
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// WithTimeout returns a context for one operation bounded by limit and by the
+// remaining budget of ctx, whichever is shorter. It returns
+// domain.ErrRequestTimeout without a new context when the budget is spent.
+func WithTimeout(ctx context.Context, limit time.Duration) (context.Context, context.CancelFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return ctx, func() {}, timeoutError(err)
+	}
+
+	timeout := limit
+	if remaining, ok := Remaining(ctx); ok {
+		if remaining < MinOperationBudget {
+			return ctx, func() {}, domain.ErrRequestTimeout
+		}
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+func timeoutError(err error) error {
+	if err == context.DeadlineExceeded {
+		return domain.ErrRequestTimeout
+	}
+	return err
+}
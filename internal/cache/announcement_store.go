@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// announcementKey holds the announcement; it is kept until cleared, even
+// once expired, so admins still see what was shown
+const announcementKey = "announcement:current"
+
+// announcementStore implements domain.AnnouncementStore on top of the cache service
+type announcementStore struct {
+	cache Service
+}
+
+// NewAnnouncementStore creates a Redis-backed announcement store
+func NewAnnouncementStore(cacheService Service) domain.AnnouncementStore {
+	return &announcementStore{cache: cacheService}
+}
+
+// Get returns the announcement
+func (s *announcementStore) Get(ctx context.Context) (*domain.Announcement, error) {
+	var announcement domain.Announcement
+	if err := s.cache.Get(ctx, announcementKey, &announcement); err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrAnnouncementNotFound
+		}
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// Set stores the announcement, replacing any previous one
+func (s *announcementStore) Set(ctx context.Context, announcement *domain.Announcement) error {
+	return s.cache.Set(ctx, announcementKey, announcement, 0)
+}
+
+// Delete removes the announcement
+func (s *announcementStore) Delete(ctx context.Context) error {
+	exists, err := s.cache.Exists(ctx, announcementKey)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrAnnouncementNotFound
+	}
+	return s.cache.Delete(ctx, announcementKey)
+}
@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrCacheDegraded is returned without contacting the cache while it is in degraded mode
+var ErrCacheDegraded = errors.New("cache bypassed: degraded after repeated failures")
+
+// DegradingCache wraps a cache Service and stops calling it after repeated
+// failures. While degraded every call fails fast with ErrCacheDegraded, so
+// callers fall back to the primary store without waiting on timeouts. A
+// background probe pings the cache after each cooldown and leaves degraded
+// mode as soon as it answers.
+type DegradingCache struct {
+	inner  Service
+	cfg    config.CacheDegradationConfig
+	logger *logger.Logger
+
+	mu            sync.Mutex
+	failures      int // consecutive failures
+	degraded      bool
+	degradedSince time.Time
+	lastError     string
+	trips         int64
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewDegradingCache creates a cache decorator with degraded mode
+func NewDegradingCache(inner Service, cfg config.CacheDegradationConfig) *DegradingCache {
+	return &DegradingCache{
+		inner:  inner,
+		cfg:    cfg,
+		logger: logger.GetGlobal().ForComponent("cache-degradation"),
+		stop:   make(chan struct{}),
+	}
+}
+
+// GetUser retrieves a user from cache
+func (c *DegradingCache) GetUser(ctx context.Context, userID string) (*domain.UserResponse, error) {
+	var user *domain.UserResponse
+	err := c.do(func() (err error) {
+		user, err = c.inner.GetUser(ctx, userID)
+		return err
+	})
+	return user, err
+}
+
+// SetUser stores a user in cache
+func (c *DegradingCache) SetUser(ctx context.Context, userID string, user *domain.UserResponse, ttl time.Duration) error {
+	return c.do(func() error { return c.inner.SetUser(ctx, userID, user, ttl) })
+}
+
+// DeleteUser removes a user from cache
+func (c *DegradingCache) DeleteUser(ctx context.Context, userID string) error {
+	return c.do(func() error { return c.inner.DeleteUser(ctx, userID) })
+}
+
+// Get retrieves a value from cache and unmarshals it into result
+func (c *DegradingCache) Get(ctx context.Context, key string, result interface{}) error {
+	return c.do(func() error { return c.inner.Get(ctx, key, result) })
+}
+
+// Set stores a value in cache with TTL
+func (c *DegradingCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.do(func() error { return c.inner.Set(ctx, key, value, ttl) })
+}
+
+// Delete removes a key from cache
+func (c *DegradingCache) Delete(ctx context.Context, key string) error {
+	return c.do(func() error { return c.inner.Delete(ctx, key) })
+}
+
+// Exists checks whether a key exists in cache
+func (c *DegradingCache) Exists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := c.do(func() (err error) {
+		exists, err = c.inner.Exists(ctx, key)
+		return err
+	})
+	return exists, err
+}
+
+// SetNX stores a value only if the key does not exist yet
+func (c *DegradingCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	var stored bool
+	err := c.do(func() (err error) {
+		stored, err = c.inner.SetNX(ctx, key, value, ttl)
+		return err
+	})
+	return stored, err
+}
+
+// GetDel retrieves and deletes a value atomically
+func (c *DegradingCache) GetDel(ctx context.Context, key string, result interface{}) error {
+	return c.do(func() error { return c.inner.GetDel(ctx, key, result) })
+}
+
+// SlidingWindow counts and optionally records an event in a sliding window
+func (c *DegradingCache) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration, record bool) (int, time.Time, error) {
+	var count int
+	var oldest time.Time
+	err := c.do(func() (err error) {
+		count, oldest, err = c.inner.SlidingWindow(ctx, key, limit, window, record)
+		return err
+	})
+	return count, oldest, err
+}
+
+// IncrementFields adds to the integer fields of a hash
+func (c *DegradingCache) IncrementFields(ctx context.Context, key string, fields map[string]int64, ttl time.Duration) error {
+	return c.do(func() error { return c.inner.IncrementFields(ctx, key, fields, ttl) })
+}
+
+// GetFields returns the integer fields of a hash
+func (c *DegradingCache) GetFields(ctx context.Context, key string) (map[string]int64, error) {
+	var fields map[string]int64
+	err := c.do(func() (err error) {
+		fields, err = c.inner.GetFields(ctx, key)
+		return err
+	})
+	return fields, err
+}
+
+// DeleteByPattern removes all keys matching the pattern
+func (c *DegradingCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	return c.do(func() error { return c.inner.DeleteByPattern(ctx, pattern) })
+}
+
+// Ping checks if the cache is reachable
+func (c *DegradingCache) Ping(ctx context.Context) error {
+	return c.do(func() error { return c.inner.Ping(ctx) })
+}
+
+// Close stops the recovery probe and closes the underlying cache
+func (c *DegradingCache) Close() error {
+	c.closeOnce.Do(func() { close(c.stop) })
+	return c.inner.Close()
+}
+
+// CheckHealth reports whether the cache is in degraded mode
+func (c *DegradingCache) CheckHealth(_ context.Context) domain.ComponentHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.degraded {
+		return domain.ComponentHealth{
+			Status:  domain.HealthStatusHealthy,
+			Details: map[string]interface{}{"trips": c.trips},
+		}
+	}
+
+	return domain.ComponentHealth{
+		Status: domain.HealthStatusDegraded,
+		Details: map[string]interface{}{
+			"degraded_since": c.degradedSince,
+			"last_error":     c.lastError,
+			"trips":          c.trips,
+		},
+	}
+}
+
+// do runs a cache call unless degraded, and records its outcome
+func (c *DegradingCache) do(call func() error) error {
+	if c.cfg.FailureThreshold <= 0 {
+		return call()
+	}
+
+	c.mu.Lock()
+	degraded := c.degraded
+	c.mu.Unlock()
+	if degraded {
+		return ErrCacheDegraded
+	}
+
+	err := call()
+	c.record(err)
+	return err
+}
+
+// record counts consecutive failures and trips into degraded mode at the threshold
+func (c *DegradingCache) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !isCacheFailure(err) {
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	c.lastError = err.Error()
+	if c.degraded || c.failures < c.cfg.FailureThreshold {
+		return
+	}
+
+	c.degraded = true
+	c.degradedSince = time.Now()
+	c.trips++
+	c.logger.Warn("Cache degraded, bypassing it until it recovers",
+		"consecutive_failures", c.failures, "cooldown", c.cfg.Cooldown, "error", err)
+
+	go c.probe()
+}
+
+// probe pings the cache after every cooldown until it answers or the cache is closed
+func (c *DegradingCache) probe() {
+	timer := time.NewTimer(c.cfg.Cooldown)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-timer.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.cfg.ProbeTimeout)
+		err := c.inner.Ping(ctx)
+		cancel()
+
+		if err == nil {
+			c.mu.Lock()
+			downtime := time.Since(c.degradedSince)
+			c.degraded = false
+			c.failures = 0
+			c.lastError = ""
+			c.mu.Unlock()
+
+			c.logger.Info("Cache recovered, leaving degraded mode", "downtime", downtime.Round(time.Millisecond))
+			return
+		}
+
+		c.mu.Lock()
+		c.lastError = err.Error()
+		c.mu.Unlock()
+		c.logger.Debug("Cache recovery probe failed", "error", err)
+
+		timer.Reset(c.cfg.Cooldown)
+	}
+}
+
+// isCacheFailure reports whether an error means the cache itself is unhealthy,
+// as opposed to a miss, a domain result or a value that failed to (un)marshal
+func isCacheFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var domainErr *domain.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var unsupportedErr *json.UnsupportedTypeError
+	if errors.As(err, &domainErr) || errors.As(err, &syntaxErr) ||
+		errors.As(err, &typeErr) || errors.As(err, &unsupportedErr) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"demo-go/internal/config"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// KeyFamilies lists the prefixes of every key the application writes. Only
+// these keys are moved by MigrateNamespace, so unrelated keys sharing the
+// Redis database are never touched. New key families must be added here.
+var KeyFamilies = []string{
+	"user:",
+	"users:list:",
+	"stats:",
+	"access_token:",
+	"reset_token:",
+	"refresh_token:",
+	"revoked_token:",
+	"hmac:",
+}
+
+// migrationScanCount is the SCAN batch size hint used while migrating keys
+const migrationScanCount = 500
+
+// KeyPrefix returns the prefix applied to every cache key for the namespace,
+// e.g. "prod:eu-west-1:acme:", or "" when no namespace is configured
+func KeyPrefix(ns config.CacheNamespaceConfig) (string, error) {
+	var parts []string
+	for _, part := range []struct{ name, value string }{
+		{"environment", ns.Environment},
+		{"region", ns.Region},
+		{"tenant", ns.Tenant},
+	} {
+		value := strings.TrimSpace(part.value)
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, ":*?[]\\ ") {
+			return "", fmt.Errorf("cache namespace %s %q must not contain ':', spaces or glob characters", part.name, value)
+		}
+		parts = append(parts, value)
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	prefix := strings.Join(parts, ":") + ":"
+	for _, family := range KeyFamilies {
+		// Patterns such as "user:*" of instances without a namespace would match these keys
+		if strings.HasPrefix(prefix, family) {
+			return "", fmt.Errorf("cache namespace %q must not start like the %s keys", prefix, family)
+		}
+	}
+	return prefix, nil
+}
+
+// NamespaceMigration reports the keys moved between two namespaces
+type NamespaceMigration struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	DryRun  bool   `json:"dry_run"`
+	Moved   int    `json:"moved"`
+	Skipped int    `json:"skipped"` // already present in the target namespace
+}
+
+// MigrateNamespace moves the application's keys from the from prefix into the
+// namespace configured in cfg, keeping their TTLs. A key that already exists
+// in the target namespace is left in place and counted as skipped. With dryRun
+// set, keys are only counted. Run it once after changing the namespace, e.g.
+// with from "" to adopt keys written before a namespace was configured.
+func MigrateNamespace(ctx context.Context, cfg *config.Config, from string, dryRun bool) (*NamespaceMigration, error) {
+	to, err := KeyPrefix(cfg.Cache.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, fmt.Errorf("source and target namespace are both %q", to)
+	}
+	for _, family := range KeyFamilies {
+		// Moved keys would match the scan again and be moved forever
+		if strings.HasPrefix(to, from+family) {
+			return nil, fmt.Errorf("target namespace %q overlaps %s keys of the source namespace", to, family)
+		}
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:        cfg.Cache.Redis.Address,
+		Password:    cfg.Cache.Redis.Password,
+		DB:          cfg.Cache.Redis.DB,
+		DialTimeout: cfg.Cache.Redis.DialTimeout,
+		ReadTimeout: cfg.Cache.Redis.ReadTimeout,
+	})
+	defer client.Close() //nolint:errcheck
+
+	migration := &NamespaceMigration{From: from, To: to, DryRun: dryRun}
+	for _, family := range KeyFamilies {
+		iter := client.Scan(ctx, 0, from+family+"*", migrationScanCount).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			target := to + strings.TrimPrefix(key, from)
+			if dryRun {
+				migration.Moved++
+				continue
+			}
+
+			renamed, err := client.RenameNX(ctx, key, target).Result()
+			if err == redis.Nil || (err != nil && strings.Contains(err.Error(), "no such key")) {
+				// Expired or deleted between SCAN and RENAMENX
+				continue
+			}
+			if err != nil {
+				return migration, fmt.Errorf("move %s: %w", key, err)
+			}
+			if renamed {
+				migration.Moved++
+			} else {
+				migration.Skipped++
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return migration, fmt.Errorf("scan %s keys: %w", family, err)
+		}
+	}
+
+	return migration, nil
+}
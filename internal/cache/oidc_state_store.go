@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// oidcStateStore implements domain.OIDCStateStore on top of the cache service
+type oidcStateStore struct {
+	cache Service
+}
+
+// NewOIDCStateStore creates a Redis-backed single sign-on state store
+func NewOIDCStateStore(cacheService Service) domain.OIDCStateStore {
+	return &oidcStateStore{cache: cacheService}
+}
+
+// Save stores the session until ttl elapses
+func (s *oidcStateStore) Save(ctx context.Context, state string, session *domain.OIDCSession, ttl time.Duration) error {
+	return s.cache.Set(ctx, oidcStateKey(state), session, ttl)
+}
+
+// Consume atomically reads and deletes the session, so a state is redeemed once
+func (s *oidcStateStore) Consume(ctx context.Context, state string) (*domain.OIDCSession, error) {
+	var session domain.OIDCSession
+	if err := s.cache.GetDel(ctx, oidcStateKey(state), &session); err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrOIDCFailed
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// oidcStateKey generates a cache key for a single sign-on state
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
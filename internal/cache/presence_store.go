@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// presenceRetention is how long a last-seen time is kept after the user's
+// latest activity
+const presenceRetention = 30 * 24 * time.Hour
+
+// presenceStore implements domain.PresenceStore on top of the cache service,
+// so activity is debounced across instances
+type presenceStore struct {
+	cache Service
+}
+
+// NewPresenceStore creates a Redis-backed presence store
+func NewPresenceStore(cacheService Service) domain.PresenceStore {
+	return &presenceStore{cache: cacheService}
+}
+
+// Claim sets a marker that expires after debounce; only the request that sets it wins
+func (s *presenceStore) Claim(ctx context.Context, userID string, debounce time.Duration) (bool, error) {
+	return s.cache.SetNX(ctx, "presence:debounce:"+userID, 1, debounce)
+}
+
+// Record stores the last-seen time
+func (s *presenceStore) Record(ctx context.Context, userID string, at time.Time) error {
+	return s.cache.Set(ctx, "presence:last_seen:"+userID, at.UTC(), presenceRetention)
+}
+
+// LastSeen reads the last-seen time of each user
+func (s *presenceStore) LastSeen(ctx context.Context, userIDs []string) (map[string]time.Time, error) {
+	result := make(map[string]time.Time, len(userIDs))
+	for _, userID := range userIDs {
+		var seen time.Time
+		if err := s.cache.Get(ctx, "presence:last_seen:"+userID, &seen); err != nil {
+			if err == redis.Nil {
+				continue
+			}
+			return nil, err
+		}
+		result[userID] = seen
+	}
+	return result, nil
+}
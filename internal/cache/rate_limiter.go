@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/ratelimit"
+)
+
+// slidingWindowLimiter implements ratelimit.Limiter with sliding window logs
+// in Redis, so limits hold across instances and a burst at a window boundary
+// cannot double the limit. Refused events are not recorded, so hammering a
+// limited key does not extend its lockout.
+type slidingWindowLimiter struct {
+	cache Service
+}
+
+// NewRateLimiter creates a Redis-backed sliding window rate limiter
+func NewRateLimiter(cacheService Service) ratelimit.Limiter {
+	return &slidingWindowLimiter{cache: cacheService}
+}
+
+// NewLimiter creates the limiter of a config.RateLimitStore* store.
+// cacheService is nil when Redis is not configured or was unreachable at
+// startup, which the redis store refuses rather than limiting per instance.
+func NewLimiter(store string, cacheService Service) (ratelimit.Limiter, error) {
+	switch store {
+	case config.RateLimitStoreAuto:
+		if cacheService != nil {
+			return NewRateLimiter(cacheService), nil
+		}
+		return ratelimit.NewMemoryLimiter(), nil
+	case config.RateLimitStoreMemory:
+		return ratelimit.NewMemoryLimiter(), nil
+	case config.RateLimitStoreRedis:
+		if cacheService == nil {
+			return nil, fmt.Errorf("RATE_LIMIT_STORE=redis requires CACHE_TYPE=redis and a reachable Redis")
+		}
+		return NewRateLimiter(cacheService), nil
+	}
+	return nil, fmt.Errorf("invalid RATE_LIMIT_STORE %q: must be auto, memory or redis", store)
+}
+
+// Allow records an event unless the window already holds limit events
+func (l *slidingWindowLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	count, oldest, err := l.cache.SlidingWindow(ctx, rateLimitKey(key), limit, window, true)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if count < limit {
+		return ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - count - 1}, nil
+	}
+	return refused(limit, oldest, window), nil
+}
+
+// Peek reports whether one more event would be allowed without recording it
+func (l *slidingWindowLimiter) Peek(ctx context.Context, key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	count, oldest, err := l.cache.SlidingWindow(ctx, rateLimitKey(key), limit, window, false)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if count < limit {
+		return ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - count}, nil
+	}
+	return refused(limit, oldest, window), nil
+}
+
+// Reset clears the window of key
+func (l *slidingWindowLimiter) Reset(ctx context.Context, key string) error {
+	return l.cache.Delete(ctx, rateLimitKey(key))
+}
+
+// refused is the result for a full window, which frees up when its oldest event expires
+func refused(limit int, oldest time.Time, window time.Duration) ratelimit.Result {
+	retryAfter := time.Until(oldest.Add(window))
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return ratelimit.Result{Limit: limit, RetryAfter: retryAfter}
+}
+
+// rateLimitKey generates a cache key for a rate limit window
+func rateLimitKey(key string) string {
+	return "ratelimit:" + key
+}
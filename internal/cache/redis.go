@@ -6,13 +6,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
+	"demo-go/internal/budget"
 	"demo-go/internal/config"
 	"demo-go/internal/domain"
 	"demo-go/internal/logger"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 )
 
 // Service defines the interface for cache operations
@@ -27,6 +30,18 @@ type Service interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	GetDel(ctx context.Context, key string, result interface{}) error
+	// SlidingWindow drops the events of key older than window and returns how
+	// many remain and when the oldest of them happened. With record set, it
+	// then records one event now, but only while fewer than limit remain.
+	SlidingWindow(ctx context.Context, key string, limit int, window time.Duration, record bool) (int, time.Time, error)
+	// IncrementFields adds to the integer fields of the hash at key, creating
+	// it as needed, and keeps the hash for ttl after the last increment
+	IncrementFields(ctx context.Context, key string, fields map[string]int64, ttl time.Duration) error
+	// GetFields returns the integer fields of the hash at key, empty when
+	// there is none
+	GetFields(ctx context.Context, key string) (map[string]int64, error)
 
 	// Batch operations
 	DeleteByPattern(ctx context.Context, pattern string) error
@@ -43,16 +58,23 @@ type redisCache struct {
 	client *redis.Client
 	logger *logger.Logger
 	config *config.RedisConfig
+	prefix string // namespace applied to every key
 }
 
 // NewRedisCache creates a new Redis cache service
 func NewRedisCache(cfg *config.Config) (Service, error) {
 	log := logger.GetGlobal().ForComponent("redis-cache")
 
+	prefix, err := KeyPrefix(cfg.Cache.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Info("Initializing Redis cache",
 		"address", cfg.Cache.Redis.Address,
 		"db", cfg.Cache.Redis.DB,
 		"pool_size", cfg.Cache.Redis.PoolSize,
+		"key_prefix", prefix,
 	)
 
 	// Create Redis client
@@ -84,10 +106,13 @@ func NewRedisCache(cfg *config.Config) (Service, error) {
 		client: client,
 		logger: log,
 		config: &cfg.Cache.Redis,
+		prefix: prefix,
 	}, nil
 }
 
-// GetUser retrieves a user from cache
+// GetUser retrieves a user from cache. Users cached with another schema, by
+// a release where UserResponse looked different, are misses so callers
+// reload and re-cache them.
 func (c *redisCache) GetUser(ctx context.Context, userID string) (*domain.UserResponse, error) {
 	key := c.userCacheKey(userID)
 	log := c.logger.WithField("user_id", userID).WithField("cache_key", key)
@@ -95,12 +120,16 @@ func (c *redisCache) GetUser(ctx context.Context, userID string) (*domain.UserRe
 	log.Debug("Getting user from cache")
 
 	var user domain.UserResponse
-	err := c.Get(ctx, key, &user)
+	err := GetVersioned(ctx, c, key, &user)
 	if err != nil {
 		if err == redis.Nil {
 			log.Debug("User cache miss")
 			return nil, domain.ErrUserNotFound
 		}
+		if err == ErrSchemaMismatch {
+			log.Debug("Cached user has another schema, treating it as a miss")
+			return nil, domain.ErrUserNotFound
+		}
 		log.Error("Failed to get user from cache", "error", err)
 		return nil, err
 	}
@@ -109,14 +138,14 @@ func (c *redisCache) GetUser(ctx context.Context, userID string) (*domain.UserRe
 	return &user, nil
 }
 
-// SetUser stores a user in cache
+// SetUser stores a user in cache, tagged with its schema
 func (c *redisCache) SetUser(ctx context.Context, userID string, user *domain.UserResponse, ttl time.Duration) error {
 	key := c.userCacheKey(userID)
 	log := c.logger.WithField("user_id", userID).WithField("cache_key", key).WithField("ttl", ttl)
 
 	log.Debug("Setting user in cache")
 
-	err := c.Set(ctx, key, user, ttl)
+	err := SetVersioned(ctx, c, key, user, ttl)
 	if err != nil {
 		log.Error("Failed to set user in cache", "error", err)
 		return err
@@ -147,7 +176,13 @@ func (c *redisCache) DeleteUser(ctx context.Context, userID string) error {
 func (c *redisCache) Get(ctx context.Context, key string, result interface{}) error {
 	log := c.logger.WithField("cache_key", key)
 
-	val, err := c.client.Get(ctx, key).Result()
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	val, err := c.client.Get(ctx, c.prefix+key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			log.Debug("Cache miss")
@@ -170,6 +205,12 @@ func (c *redisCache) Get(ctx context.Context, key string, result interface{}) er
 func (c *redisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	log := c.logger.WithField("cache_key", key).WithField("ttl", ttl)
 
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
 	// Use default TTL if not specified
 	if ttl == 0 {
 		ttl = c.config.TTL
@@ -181,7 +222,7 @@ func (c *redisCache) Set(ctx context.Context, key string, value interface{}, ttl
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
 
-	err = c.client.Set(ctx, key, data, ttl).Err()
+	err = c.client.Set(ctx, c.prefix+key, data, ttl).Err()
 	if err != nil {
 		log.Error("Redis SET failed", "error", err)
 		return err
@@ -195,7 +236,13 @@ func (c *redisCache) Set(ctx context.Context, key string, value interface{}, ttl
 func (c *redisCache) Delete(ctx context.Context, key string) error {
 	log := c.logger.WithField("cache_key", key)
 
-	err := c.client.Del(ctx, key).Err()
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = c.client.Del(ctx, c.prefix+key).Err()
 	if err != nil {
 		log.Error("Redis DELETE failed", "error", err)
 		return err
@@ -209,7 +256,13 @@ func (c *redisCache) Delete(ctx context.Context, key string) error {
 func (c *redisCache) Exists(ctx context.Context, key string) (bool, error) {
 	log := c.logger.WithField("cache_key", key)
 
-	count, err := c.client.Exists(ctx, key).Result()
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+
+	count, err := c.client.Exists(ctx, c.prefix+key).Result()
 	if err != nil {
 		log.Error("Redis EXISTS failed", "error", err)
 		return false, err
@@ -220,14 +273,172 @@ func (c *redisCache) Exists(ctx context.Context, key string) (bool, error) {
 	return exists, nil
 }
 
+// SetNX stores a value only if the key does not exist yet and reports whether it was stored
+func (c *redisCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	log := c.logger.WithField("cache_key", key).WithField("ttl", ttl)
+
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+
+	if ttl == 0 {
+		ttl = c.config.TTL
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Error("Failed to marshal value for caching", "error", err)
+		return false, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	stored, err := c.client.SetNX(ctx, c.prefix+key, data, ttl).Result()
+	if err != nil {
+		log.Error("Redis SETNX failed", "error", err)
+		return false, err
+	}
+
+	log.Debug("Conditional set completed", "stored", stored)
+	return stored, nil
+}
+
+// GetDel atomically retrieves a value and removes its key
+func (c *redisCache) GetDel(ctx context.Context, key string, result interface{}) error {
+	log := c.logger.WithField("cache_key", key)
+
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	val, err := c.client.GetDel(ctx, c.prefix+key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			log.Debug("Cache miss")
+			return redis.Nil
+		}
+		log.Error("Redis GETDEL failed", "error", err)
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(val), result); err != nil {
+		log.Error("Failed to unmarshal cached value", "error", err)
+		return fmt.Errorf("failed to unmarshal cached value: %w", err)
+	}
+
+	return nil
+}
+
+// slidingWindowScript keeps one sorted set member per event, scored by its
+// time in milliseconds, so counting and recording happen in one round trip
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if ARGV[4] == "1" and count < tonumber(ARGV[3]) then
+	redis.call("ZADD", KEYS[1], now, ARGV[5])
+	redis.call("PEXPIRE", KEYS[1], window)
+end
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {count, tonumber(oldest[2]) or 0}
+`)
+
+// SlidingWindow counts and optionally records an event in a sliding window log
+func (c *redisCache) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration, record bool) (int, time.Time, error) {
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer cancel()
+
+	recordArg := "0"
+	if record {
+		recordArg = "1"
+	}
+	now := time.Now()
+	values, err := slidingWindowScript.Run(ctx, c.client, []string{c.prefix + key},
+		now.UnixMilli(), window.Milliseconds(), limit, recordArg, uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		c.logger.WithField("cache_key", key).Error("Redis sliding window failed", "error", err)
+		return 0, time.Time{}, err
+	}
+
+	var oldest time.Time
+	if values[1] > 0 {
+		oldest = time.UnixMilli(values[1])
+	}
+	return int(values[0]), oldest, nil
+}
+
+// IncrementFields runs HINCRBY for every field and refreshes the TTL in one
+// transaction
+func (c *redisCache) IncrementFields(ctx context.Context, key string, fields map[string]int64, ttl time.Duration) error {
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if ttl == 0 {
+		ttl = c.config.TTL
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for field, delta := range fields {
+			pipe.HIncrBy(ctx, c.prefix+key, field, delta)
+		}
+		pipe.Expire(ctx, c.prefix+key, ttl)
+		return nil
+	})
+	if err != nil {
+		c.logger.WithField("cache_key", key).Error("Redis HINCRBY failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetFields returns the hash at key with its values parsed as integers
+func (c *redisCache) GetFields(ctx context.Context, key string) (map[string]int64, error) {
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	values, err := c.client.HGetAll(ctx, c.prefix+key).Result()
+	if err != nil {
+		c.logger.WithField("cache_key", key).Error("Redis HGETALL failed", "error", err)
+		return nil, err
+	}
+	fields := make(map[string]int64, len(values))
+	for field, value := range values {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s is not an integer: %w", field, key, err)
+		}
+		fields[field] = n
+	}
+	return fields, nil
+}
+
 // DeleteByPattern deletes all keys matching a pattern
 func (c *redisCache) DeleteByPattern(ctx context.Context, pattern string) error {
 	log := c.logger.WithField("pattern", pattern)
 
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
 	log.Debug("Deleting keys by pattern")
 
 	// Get all keys matching the pattern
-	keys, err := c.client.Keys(ctx, pattern).Result()
+	// Keys come back with the namespace, so they are deleted as returned
+	keys, err := c.client.Keys(ctx, c.prefix+pattern).Result()
 	if err != nil {
 		log.Error("Failed to get keys by pattern", "error", err)
 		return err
@@ -258,7 +469,13 @@ func (c *redisCache) DeleteByPattern(ctx context.Context, pattern string) error
 func (c *redisCache) Ping(ctx context.Context) error {
 	log := c.logger
 
-	err := c.client.Ping(ctx).Err()
+	ctx, cancel, err := c.withBudget(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = c.client.Ping(ctx).Err()
 	if err != nil {
 		log.Error("Redis ping failed", "error", err)
 		return err
@@ -284,6 +501,12 @@ func (c *redisCache) Close() error {
 	return nil
 }
 
+// withBudget bounds one command by its round trip (write then read timeout)
+// and by the time left in the caller's request budget
+func (c *redisCache) withBudget(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	return budget.WithTimeout(ctx, c.config.WriteTimeout+c.config.ReadTimeout)
+}
+
 // userCacheKey generates a cache key for user data
 func (c *redisCache) userCacheKey(userID string) string {
 	return fmt.Sprintf("user:%s", userID)
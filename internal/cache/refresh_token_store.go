@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// refreshTokenStore implements domain.RefreshTokenStore on top of the cache
+// service. Tokens are stored by hash so a cache dump does not reveal usable
+// tokens. A token is marked used with SETNX, so of two concurrent rotations
+// only one succeeds and the other is treated as reuse.
+type refreshTokenStore struct {
+	cache Service
+}
+
+// NewRefreshTokenStore creates a Redis-backed refresh token store
+func NewRefreshTokenStore(cacheService Service) domain.RefreshTokenStore {
+	return &refreshTokenStore{cache: cacheService}
+}
+
+// Save stores the token until its record expires
+func (s *refreshTokenStore) Save(ctx context.Context, token string, record *domain.RefreshTokenRecord) error {
+	ttl := time.Until(record.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.cache.Set(ctx, refreshTokenKey(token), record, ttl)
+}
+
+// Get returns the token's record without marking it used
+func (s *refreshTokenStore) Get(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	record, err := s.lookup(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.cache.Exists(ctx, refreshTokenUsedKey(token))
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return record, domain.ErrRefreshTokenReused
+	}
+	return record, nil
+}
+
+// Use marks the token as used and returns its record
+func (s *refreshTokenStore) Use(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	record, err := s.lookup(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	first, err := s.cache.SetNX(ctx, refreshTokenUsedKey(token), true, time.Until(record.ExpiresAt))
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return record, domain.ErrRefreshTokenReused
+	}
+	return record, nil
+}
+
+// lookup returns the record of a token that is still valid
+func (s *refreshTokenStore) lookup(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	var record domain.RefreshTokenRecord
+	if err := s.cache.Get(ctx, refreshTokenKey(token), &record); err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	revoked, err := s.cache.Exists(ctx, refreshFamilyRevokedKey(record.FamilyID))
+	if err != nil {
+		return nil, err
+	}
+	if revoked || time.Until(record.ExpiresAt) <= 0 {
+		return nil, domain.ErrInvalidToken
+	}
+	return &record, nil
+}
+
+// RevokeFamily invalidates every token of the family
+func (s *refreshTokenStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	return s.cache.Set(ctx, refreshFamilyRevokedKey(familyID), true, ttl)
+}
+
+// refreshTokenKey generates a cache key for a refresh token's record
+func refreshTokenKey(token string) string {
+	return "refresh_token:" + hashRefreshToken(token)
+}
+
+// refreshTokenUsedKey generates the cache key marking a refresh token as rotated
+func refreshTokenUsedKey(token string) string {
+	return "refresh_token:used:" + hashRefreshToken(token)
+}
+
+// refreshFamilyRevokedKey generates the cache key marking a token family as revoked
+func refreshFamilyRevokedKey(familyID string) string {
+	return "refresh_token:revoked:" + familyID
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
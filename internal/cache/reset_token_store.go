@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// resetTokenStore implements domain.ResetTokenStore on top of the cache service.
+// Tokens are stored by hash so a cache dump does not reveal usable tokens.
+type resetTokenStore struct {
+	cache Service
+}
+
+// NewResetTokenStore creates a Redis-backed reset token store
+func NewResetTokenStore(cacheService Service) domain.ResetTokenStore {
+	return &resetTokenStore{cache: cacheService}
+}
+
+// Save stores the token's binding until ttl elapses
+func (s *resetTokenStore) Save(ctx context.Context, token string, binding *domain.ResetToken, ttl time.Duration) error {
+	return s.cache.Set(ctx, resetTokenKey(token), binding, ttl)
+}
+
+// Consume atomically reads and deletes the token
+func (s *resetTokenStore) Consume(ctx context.Context, token string) (*domain.ResetToken, error) {
+	var binding domain.ResetToken
+	if err := s.cache.GetDel(ctx, resetTokenKey(token), &binding); err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// resetTokenKey generates a cache key for a reset token
+func resetTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "reset_token:" + hex.EncodeToString(sum[:])
+}
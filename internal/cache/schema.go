@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaVersion is part of every cached value's schema. Changes to a struct's
+// JSON fields change the schema on their own; bump SchemaVersion for changes
+// they cannot show, such as a field whose meaning or format changed.
+const SchemaVersion = 1
+
+// ErrSchemaMismatch is returned for a cached value written with another
+// schema, typically by an instance running an older or newer release.
+// Callers treat it as a miss and refresh the entry.
+var ErrSchemaMismatch = errors.New("cached value has a different schema")
+
+// versionedEntry wraps a cached value with the schema it was written with
+type versionedEntry struct {
+	Schema string          `json:"schema"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// schemas memoizes the schema of each type
+var schemas sync.Map // reflect.Type -> string
+
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// SchemaOf returns the schema of v's type, e.g. "1:9f2c4e1a": SchemaVersion
+// and a fingerprint of the JSON field names and types reachable from it
+func SchemaOf(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if schema, ok := schemas.Load(t); ok {
+		return schema.(string)
+	}
+
+	var b strings.Builder
+	fingerprint(&b, t, map[reflect.Type]bool{})
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(b.String()))
+	schema := fmt.Sprintf("%d:%08x", SchemaVersion, h.Sum32())
+	schemas.Store(t, schema)
+	return schema
+}
+
+// SetVersioned caches value tagged with its schema
+func SetVersioned(ctx context.Context, c Service, key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return c.Set(ctx, key, versionedEntry{Schema: SchemaOf(value), Value: raw}, ttl)
+}
+
+// GetVersioned reads a value cached by SetVersioned into result. It returns
+// ErrSchemaMismatch, leaving result untouched, when the value was written
+// with another schema than result's or without one.
+func GetVersioned(ctx context.Context, c Service, key string, result interface{}) error {
+	var entry versionedEntry
+	if err := c.Get(ctx, key, &entry); err != nil {
+		return err
+	}
+	if entry.Schema != SchemaOf(result) {
+		return ErrSchemaMismatch
+	}
+	return json.Unmarshal(entry.Value, result)
+}
+
+// fingerprint writes the JSON shape of t: the name and shape of each field.
+// Types that marshal themselves are written by name.
+func fingerprint(b *strings.Builder, t reflect.Type, seen map[reflect.Type]bool) {
+	if t == nil {
+		b.WriteString("nil")
+		return
+	}
+	if t.Implements(jsonMarshaler) || reflect.PtrTo(t).Implements(jsonMarshaler) {
+		b.WriteString(t.String())
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		fingerprint(b, t.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[]")
+		fingerprint(b, t.Elem(), seen)
+	case reflect.Map:
+		b.WriteString("map[" + t.Key().Kind().String() + "]")
+		fingerprint(b, t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			b.WriteString(t.String())
+			return
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		b.WriteString("{")
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				fingerprint(b, field.Type, seen)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			b.WriteString(name + ":")
+			fingerprint(b, field.Type, seen)
+			b.WriteString(";")
+		}
+		b.WriteString("}")
+	default:
+		b.WriteString(t.Kind().String())
+	}
+}
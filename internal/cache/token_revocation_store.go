@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenRevocationStore implements domain.TokenRevocationStore on top of the
+// cache service, so a logout is seen by every instance sharing the cache
+type tokenRevocationStore struct {
+	cache Service
+}
+
+// NewTokenRevocationStore creates a Redis-backed token denylist
+func NewTokenRevocationStore(cacheService Service) domain.TokenRevocationStore {
+	return &tokenRevocationStore{cache: cacheService}
+}
+
+// Revoke denies the token until expiresAt
+func (s *tokenRevocationStore) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil // already expired, nothing to deny
+	}
+	return s.cache.Set(ctx, revokedTokenKey(tokenID), true, ttl)
+}
+
+// IsRevoked reports whether the token was revoked
+func (s *tokenRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return s.cache.Exists(ctx, revokedTokenKey(tokenID))
+}
+
+// RevokeUser denies the user's tokens issued at or before `before`. A later
+// call replaces the cut-off, which only ever moves forward.
+func (s *tokenRevocationStore) RevokeUser(ctx context.Context, userID string, before time.Time, ttl time.Duration) error {
+	return s.cache.Set(ctx, revokedUserKey(userID), before.Unix(), ttl)
+}
+
+// RevokedBefore returns the user's cut-off, or the zero time
+func (s *tokenRevocationStore) RevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	var unix int64
+	if err := s.cache.Get(ctx, revokedUserKey(userID), &unix); err != nil {
+		if err == redis.Nil {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// revokedTokenKey generates the cache key denying a single token
+func revokedTokenKey(tokenID string) string {
+	return "revoked_token:" + tokenID
+}
+
+// revokedUserKey generates the cache key holding a user's revocation cut-off
+func revokedUserKey(userID string) string {
+	return "revoked_token:user:" + userID
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenStore implements domain.TokenStore on top of the cache service.
+// Tokens are stored by hash so a cache dump does not reveal usable tokens.
+type tokenStore struct {
+	cache Service
+}
+
+// NewTokenStore creates a Redis-backed opaque token store
+func NewTokenStore(cacheService Service) domain.TokenStore {
+	return &tokenStore{cache: cacheService}
+}
+
+// Save stores the claims for the token until ttl elapses
+func (s *tokenStore) Save(ctx context.Context, token string, claims *domain.TokenClaims, ttl time.Duration) error {
+	return s.cache.Set(ctx, accessTokenKey(token), claims, ttl)
+}
+
+// Get returns the claims bound to the token
+func (s *tokenStore) Get(ctx context.Context, token string) (*domain.TokenClaims, error) {
+	var claims domain.TokenClaims
+	if err := s.cache.Get(ctx, accessTokenKey(token), &claims); err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// Delete revokes the token
+func (s *tokenStore) Delete(ctx context.Context, token string) error {
+	return s.cache.Delete(ctx, accessTokenKey(token))
+}
+
+// accessTokenKey generates a cache key for an opaque access token
+func accessTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "access_token:" + hex.EncodeToString(sum[:])
+}
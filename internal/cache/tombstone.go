@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// SetTombstone replaces a cached user with a marker recording its deletion,
+// so a stale write racing the delete cannot bring the user back before the
+// marker expires
+func SetTombstone(ctx context.Context, c Service, userID string, ttl time.Duration) error {
+	return c.SetUser(ctx, userID, &domain.UserResponse{ID: userID, Status: domain.UserStatusDeleted}, ttl)
+}
+
+// IsTombstone reports whether a cached user is a deletion marker
+func IsTombstone(user *domain.UserResponse) bool {
+	return user != nil && user.Status == domain.UserStatusDeleted
+}
+
+// SetUserUnlessDeleted caches a user unless a tombstone is in place for it.
+// The check and the write are not atomic, which narrows rather than closes
+// the window for resurrecting a deleted user.
+func SetUserUnlessDeleted(ctx context.Context, c Service, userID string, user *domain.UserResponse, ttl time.Duration) error {
+	if cached, err := c.GetUser(ctx, userID); err == nil && IsTombstone(cached) {
+		return nil
+	}
+	return c.SetUser(ctx, userID, user, ttl)
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// usageCounterTTL keeps a day's counters long enough for the rollup of the
+// day after
+const usageCounterTTL = 72 * time.Hour
+
+// Counter fields of a principal's usage hash
+const (
+	usageFieldRequests     = "requests"
+	usageFieldClientErrors = "client_errors"
+	usageFieldServerErrors = "server_errors"
+	usageFieldLatencyMs    = "latency_ms"
+)
+
+// usageCounterStore implements domain.UsageCounterStore with one hash per
+// principal and day, plus a hash per day indexing the principals seen
+type usageCounterStore struct {
+	cache Service
+}
+
+// NewUsageCounterStore creates a Redis-backed usage counter store
+func NewUsageCounterStore(cacheService Service) domain.UsageCounterStore {
+	return &usageCounterStore{cache: cacheService}
+}
+
+// Add adds counts to the principal's counters of the day
+func (s *usageCounterStore) Add(ctx context.Context, day string, principal domain.UsagePrincipal, counts domain.UsageCounts) error {
+	if err := s.cache.IncrementFields(ctx, usageCounterKey(day, principal), map[string]int64{
+		usageFieldRequests:     counts.Requests,
+		usageFieldClientErrors: counts.ClientErrors,
+		usageFieldServerErrors: counts.ServerErrors,
+		usageFieldLatencyMs:    counts.LatencyMs,
+	}, usageCounterTTL); err != nil {
+		return err
+	}
+	indexField := principal.Type + ":" + principal.ID + ":" + principal.UserID
+	return s.cache.IncrementFields(ctx, usageIndexKey(day), map[string]int64{indexField: counts.Requests}, usageCounterTTL)
+}
+
+// List returns the counters of every principal seen on the day
+func (s *usageCounterStore) List(ctx context.Context, day string) ([]*domain.UsageRecord, error) {
+	index, err := s.cache.GetFields(ctx, usageIndexKey(day))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*domain.UsageRecord, 0, len(index))
+	for field := range index {
+		parts := strings.SplitN(field, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		principal := domain.UsagePrincipal{Type: parts[0], ID: parts[1], UserID: parts[2]}
+		fields, err := s.cache.GetFields(ctx, usageCounterKey(day, principal))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, &domain.UsageRecord{
+			Day:            day,
+			UsagePrincipal: principal,
+			UsageCounts: domain.UsageCounts{
+				Requests:     fields[usageFieldRequests],
+				ClientErrors: fields[usageFieldClientErrors],
+				ServerErrors: fields[usageFieldServerErrors],
+				LatencyMs:    fields[usageFieldLatencyMs],
+			},
+		})
+	}
+	return records, nil
+}
+
+// usageCounterKey generates the cache key of a principal's counters of a day
+func usageCounterKey(day string, principal domain.UsagePrincipal) string {
+	return "usage:" + day + ":" + principal.Type + ":" + principal.ID
+}
+
+// usageIndexKey generates the cache key of the principals seen on a day
+func usageIndexKey(day string) string {
+	return "usage:" + day + ":principals"
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// webAuthnSessionStore implements domain.WebAuthnSessionStore on top of the cache service
+type webAuthnSessionStore struct {
+	cache Service
+}
+
+// NewWebAuthnSessionStore creates a Redis-backed passkey ceremony session store
+func NewWebAuthnSessionStore(cacheService Service) domain.WebAuthnSessionStore {
+	return &webAuthnSessionStore{cache: cacheService}
+}
+
+// Save stores the session until ttl elapses
+func (s *webAuthnSessionStore) Save(ctx context.Context, challenge string, session *domain.WebAuthnSession, ttl time.Duration) error {
+	return s.cache.Set(ctx, webAuthnSessionKey(challenge), session, ttl)
+}
+
+// Consume atomically reads and deletes the session, so a challenge is answered once
+func (s *webAuthnSessionStore) Consume(ctx context.Context, challenge string) (*domain.WebAuthnSession, error) {
+	var session domain.WebAuthnSession
+	if err := s.cache.GetDel(ctx, webAuthnSessionKey(challenge), &session); err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrPasskeyFailed
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// webAuthnSessionKey generates a cache key for a ceremony challenge
+func webAuthnSessionKey(challenge string) string {
+	return "webauthn_session:" + challenge
+}
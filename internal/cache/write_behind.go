@@ -0,0 +1,308 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// writeBehindTimeout bounds each queued cache write
+const writeBehindTimeout = 3 * time.Second
+
+// Drop policies for writes that find their queue full
+const (
+	// WriteBehindDropSync applies the write synchronously, or waits for room
+	// when writes for the same user are still queued
+	WriteBehindDropSync = "sync"
+	// WriteBehindDropFills discards writes that only fill the cache; updates
+	// and invalidations are still applied synchronously
+	WriteBehindDropFills = "drop"
+)
+
+// ValidateWriteBehindConfig checks the drop policy and sizes of the queue
+func ValidateWriteBehindConfig(cfg config.WriteBehindConfig) error {
+	if cfg.DropPolicy != "" && cfg.DropPolicy != WriteBehindDropSync && cfg.DropPolicy != WriteBehindDropFills {
+		return fmt.Errorf("unknown write-behind drop policy %q (allowed: %s, %s)", cfg.DropPolicy, WriteBehindDropSync, WriteBehindDropFills)
+	}
+	if cfg.QueueSize < 0 || cfg.Workers < 0 {
+		return fmt.Errorf("write-behind queue size and workers must not be negative")
+	}
+	return nil
+}
+
+// writeBehindOp is a queued cache write; fill marks writes that only add a
+// user to the cache, which may be dropped without leaving stale data
+type writeBehindOp struct {
+	userID string
+	apply  func(ctx context.Context) error
+	fill   bool
+}
+
+// WriteBehindQueue applies cache writes asynchronously on a fixed number of
+// workers so requests do not wait on the cache. Writes are assigned to a
+// worker by user ID, so writes for one user are applied in order. When a
+// worker's queue is full the write is applied synchronously instead of being
+// dropped, because a lost invalidation would leave stale data in the cache;
+// with the drop policy, writes that only fill the cache are discarded instead.
+// A write for a user whose earlier writes are still queued waits for room
+// rather than being applied ahead of them.
+type WriteBehindQueue struct {
+	cache    Service
+	cfg      config.WriteBehindConfig
+	queues   []chan writeBehindOp
+	capacity int
+	wg       sync.WaitGroup
+	done     chan struct{}
+	// ctx is cancelled when a flush times out; workers then discard what is left
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger *logger.Logger
+
+	mu     sync.RWMutex
+	closed bool
+
+	// queued counts each user's writes that are queued or being applied
+	queuedMu sync.Mutex
+	queued   map[string]int
+
+	statsMu     sync.Mutex
+	peakDepth   int
+	applied     int64
+	failed      int64
+	synchronous int64
+	waited      int64
+	dropped     int64
+}
+
+// NewWriteBehindQueue creates a queue holding up to cfg.QueueSize pending
+// writes and starts its workers
+func NewWriteBehindQueue(cacheService Service, cfg config.WriteBehindConfig) *WriteBehindQueue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize < cfg.Workers {
+		cfg.QueueSize = cfg.Workers
+	}
+	if cfg.DropPolicy == "" {
+		cfg.DropPolicy = WriteBehindDropSync
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &WriteBehindQueue{
+		cache:  cacheService,
+		cfg:    cfg,
+		queues: make([]chan writeBehindOp, cfg.Workers),
+		done:   make(chan struct{}),
+		queued: make(map[string]int),
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logger.GetGlobal().ForComponent("cache-write-behind"),
+	}
+	perWorker := (cfg.QueueSize + cfg.Workers - 1) / cfg.Workers
+	q.capacity = perWorker * cfg.Workers
+	for i := range q.queues {
+		q.queues[i] = make(chan writeBehindOp, perWorker)
+		q.wg.Add(1)
+		go q.run(q.queues[i])
+	}
+	go func() {
+		q.wg.Wait()
+		close(q.done)
+	}()
+	return q
+}
+
+// SetUser queues caching a user; the write is skipped if the user has been tombstoned by then
+func (q *WriteBehindQueue) SetUser(userID string, user *domain.UserResponse, ttl time.Duration) {
+	q.enqueue(userID, writeBehindOp{apply: func(ctx context.Context) error {
+		return SetUserUnlessDeleted(ctx, q.cache, userID, user, ttl)
+	}})
+}
+
+// FillUser queues caching a user that was read, not changed, e.g. one of a
+// listed page. Unlike SetUser it may be dropped when the queue is full.
+func (q *WriteBehindQueue) FillUser(userID string, user *domain.UserResponse, ttl time.Duration) {
+	q.enqueue(userID, writeBehindOp{fill: true, apply: func(ctx context.Context) error {
+		return SetUserUnlessDeleted(ctx, q.cache, userID, user, ttl)
+	}})
+}
+
+// Tombstone queues replacing a cached user with a deletion marker
+func (q *WriteBehindQueue) Tombstone(userID string, ttl time.Duration) {
+	q.enqueue(userID, writeBehindOp{apply: func(ctx context.Context) error {
+		return SetTombstone(ctx, q.cache, userID, ttl)
+	}})
+}
+
+// DeleteUser queues invalidating a cached user
+func (q *WriteBehindQueue) DeleteUser(userID string) {
+	q.enqueue(userID, writeBehindOp{apply: func(ctx context.Context) error {
+		return q.cache.DeleteUser(ctx, userID)
+	}})
+}
+
+// Close stops accepting writes and waits for queued ones to be applied, or
+// for ctx to expire. Writes still queued when ctx expires are discarded and
+// counted as dropped. Writes arriving after Close are applied synchronously.
+func (q *WriteBehindQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		for _, ops := range q.queues {
+			close(ops)
+		}
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		q.logger.Warn("Write-behind flush timed out, discarding queued writes", "pending", q.Pending())
+		q.cancel()
+		return ctx.Err()
+	}
+}
+
+// Pending returns the number of queued writes
+func (q *WriteBehindQueue) Pending() int {
+	pending := 0
+	for _, ops := range q.queues {
+		pending += len(ops)
+	}
+	return pending
+}
+
+// CheckHealth reports the queue depth and what became of queued writes; it
+// is degraded while a worker's queue is full
+func (q *WriteBehindQueue) CheckHealth(_ context.Context) domain.ComponentHealth {
+	full := false
+	for _, ops := range q.queues {
+		full = full || len(ops) == cap(ops)
+	}
+
+	q.statsMu.Lock()
+	details := map[string]interface{}{
+		"queue_depth":        q.Pending(),
+		"queue_capacity":     q.capacity,
+		"peak_queue_depth":   q.peakDepth,
+		"workers":            len(q.queues),
+		"drop_policy":        q.cfg.DropPolicy,
+		"applied_writes":     q.applied,
+		"failed_writes":      q.failed,
+		"synchronous_writes": q.synchronous,
+		"waited_writes":      q.waited,
+		"dropped_writes":     q.dropped,
+	}
+	q.statsMu.Unlock()
+
+	if full {
+		return domain.ComponentHealth{Status: domain.HealthStatusDegraded, Details: details}
+	}
+	return domain.ComponentHealth{Status: domain.HealthStatusHealthy, Details: details}
+}
+
+func (q *WriteBehindQueue) enqueue(userID string, op writeBehindOp) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	op.userID = userID
+	if !q.closed {
+		ops := q.queues[q.worker(userID)]
+		q.queuedMu.Lock()
+		q.queued[userID]++
+		select {
+		case ops <- op:
+			q.queuedMu.Unlock()
+			q.recordDepth()
+			return
+		default:
+		}
+		q.queued[userID]--
+		behind := q.queued[userID] > 0
+		if !behind {
+			delete(q.queued, userID)
+		}
+		q.queuedMu.Unlock()
+
+		if op.fill && q.cfg.DropPolicy == WriteBehindDropFills {
+			q.logger.Debug("Write-behind queue full, dropping cache fill", "user_id", userID)
+			q.count(&q.dropped)
+			return
+		}
+		if behind {
+			// Applying it now would put it ahead of the user's queued writes
+			q.logger.Debug("Write-behind queue full, waiting behind the user's queued writes", "user_id", userID)
+			q.markQueued(userID, 1)
+			ops <- op
+			q.count(&q.waited)
+			q.recordDepth()
+			return
+		}
+		q.logger.Debug("Write-behind queue full, writing synchronously", "user_id", userID)
+	}
+	q.count(&q.synchronous)
+	q.apply(context.Background(), op)
+}
+
+// worker picks the queue of a user's writes
+func (q *WriteBehindQueue) worker(userID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(userID))
+	return int(h.Sum32() % uint32(len(q.queues)))
+}
+
+func (q *WriteBehindQueue) run(ops <-chan writeBehindOp) {
+	defer q.wg.Done()
+	for op := range ops {
+		if q.ctx.Err() != nil {
+			q.count(&q.dropped)
+		} else {
+			q.apply(q.ctx, op)
+		}
+		q.markQueued(op.userID, -1)
+	}
+}
+
+// markQueued adjusts the number of the user's writes that are queued
+func (q *WriteBehindQueue) markQueued(userID string, delta int) {
+	q.queuedMu.Lock()
+	defer q.queuedMu.Unlock()
+	if q.queued[userID] += delta; q.queued[userID] <= 0 {
+		delete(q.queued, userID)
+	}
+}
+
+func (q *WriteBehindQueue) apply(parent context.Context, op writeBehindOp) {
+	ctx, cancel := context.WithTimeout(parent, writeBehindTimeout)
+	defer cancel()
+
+	if err := op.apply(ctx); err != nil {
+		q.logger.Warn("Write-behind cache write failed", "error", err)
+		q.count(&q.failed)
+		return
+	}
+	q.count(&q.applied)
+}
+
+func (q *WriteBehindQueue) count(counter *int64) {
+	q.statsMu.Lock()
+	*counter++
+	q.statsMu.Unlock()
+}
+
+// recordDepth tracks the deepest the queue has been
+func (q *WriteBehindQueue) recordDepth() {
+	depth := q.Pending()
+	q.statsMu.Lock()
+	if depth > q.peakDepth {
+		q.peakDepth = depth
+	}
+	q.statsMu.Unlock()
+}
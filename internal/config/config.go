@@ -5,15 +5,59 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Cache    CacheConfig
-	JWT      JWTConfig
+	Server        ServerConfig
+	Response      ResponseConfig
+	Database      DatabaseConfig
+	Cache         CacheConfig
+	JWT           JWTConfig
+	HMAC          HMACConfig
+	Auth          AuthConfig
+	Hooks         HooksConfig
+	Recovery      RecoveryConfig
+	PasswordReset PasswordResetConfig
+	Email         EmailConfig
+	PublicProfile PublicProfileConfig
+	Encryption    FieldEncryptionConfig
+	Presence      PresenceConfig
+	WebAuthn      WebAuthnConfig
+	OIDC          OIDCConfig
+	APIKeys       APIKeyConfig
+	Clients       ClientCredentialsConfig
+	RateLimit     RateLimitConfig
+	Dedup         DedupConfig
+	Roles         RolesConfig
+	Policy        PolicyConfig
+	Profile       ProfileConfig
+
+	ContentPolicy ContentPolicyConfig
+	Passwords     PasswordPolicyConfig
+	PasswordHash  PasswordHashConfig
+	EmailDomains  EmailDomainConfig
+	EmailCheck    EmailCheckConfig
+	Pagination    PaginationConfig
+	SIEM          SIEMConfig
+	Security      SecurityEventsConfig
+	Transfer      TransferConfig
+	LoginThrottle LoginThrottleConfig
+	Telemetry     TelemetryConfig
+	Retention     RetentionConfig
+	Snapshot      SnapshotConfig
+	Announcements AnnouncementConfig
+	Usage         UsageConfig
+	LoginHistory  LoginHistoryConfig
+	AdminSearch   AdminSearchConfig
+	CORS          CORSConfig
+	Hygiene       SecretsHygieneConfig
+
+	SuspiciousLogin SuspiciousLoginConfig
+	Invites         InviteConfig
+	Demo            DemoConfig
 }
 
 // ServerConfig holds server-specific configuration
@@ -23,11 +67,35 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	RequestTimeout  time.Duration // total budget per request; 0 leaves requests unbounded
+	APIVersion      string
+	AppVersion      string
+	// TrustedProxies are the addresses or CIDR ranges of reverse proxies whose
+	// X-Forwarded-For and X-Real-IP headers name the client
+	TrustedProxies []string
+}
+
+// ResponseConfig holds the default format of JSON response bodies
+type ResponseConfig struct {
+	FieldNaming   string // snake or camel
+	Envelope      bool   // false writes bare bodies without the envelope
+	FormatHeaders bool   // let clients pick the format per request
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	MongoDB MongoDBConfig
+	MongoDB   MongoDBConfig
+	Migration RepositoryMigrationConfig
+}
+
+// RepositoryMigrationConfig mirrors user writes to a second repository type
+// while moving between stores. The configured repository stays the source
+// of truth; with ShadowReads, reads are repeated against the target and
+// compared. Every target operation is bounded by Timeout.
+type RepositoryMigrationConfig struct {
+	Target      string // repository type to migrate to; empty disables the migration mode
+	ShadowReads bool
+	Timeout     time.Duration
 }
 
 // MongoDBConfig holds MongoDB-specific configuration
@@ -36,11 +104,73 @@ type MongoDBConfig struct {
 	Database    string
 	Timeout     time.Duration
 	MaxPoolSize int
+
+	LogQueries     bool // log every query filter
+	ExplainEnabled bool // let admins request query plans with the X-Explain header
+
+	WriteBuffer WriteBufferConfig
+}
+
+// WriteBufferConfig controls holding user writes that fail while MongoDB has
+// no primary, e.g. during an election. Up to MaxPending writes wait and are
+// retried every RetryInterval for at most Timeout; further writes fail at once.
+type WriteBufferConfig struct {
+	Enabled       bool
+	MaxPending    int
+	Timeout       time.Duration
+	RetryInterval time.Duration
 }
 
 // CacheConfig holds cache configuration
 type CacheConfig struct {
-	Redis RedisConfig
+	// Type is "redis" to cache through Redis; anything else disables caching
+	Type        string
+	Redis       RedisConfig
+	Degradation CacheDegradationConfig
+	// Strategies overrides the cache strategy per user service operation
+	Strategies  map[string]string
+	WriteBehind WriteBehindConfig
+	Namespace   CacheNamespaceConfig
+	ListPages   ListPageCacheConfig
+}
+
+// ListPageCacheConfig caches pages of the admin user list. Pages older than
+// SoftTTL, or cached before a user write, are still served while they are
+// refreshed in the background; after HardTTL they expire.
+type ListPageCacheConfig struct {
+	Enabled bool
+	SoftTTL time.Duration
+	HardTTL time.Duration
+}
+
+// WriteBehindConfig sizes the queue of asynchronous cache writes. Writes are
+// spread over Workers by user, so writes for one user keep their order, and
+// up to QueueSize writes wait in total. DropPolicy decides what happens to a
+// write that finds its queue full: "sync" applies it synchronously, or waits
+// behind the same user's queued writes, "drop"
+// discards it when it only fills the cache, such as users cached from a list.
+type WriteBehindConfig struct {
+	QueueSize  int
+	Workers    int
+	DropPolicy string
+}
+
+// CacheNamespaceConfig scopes every Redis key so several environments,
+// regions or tenants can share one Redis without key collisions. Empty parts
+// are left out; with all parts empty keys are not prefixed.
+type CacheNamespaceConfig struct {
+	Environment string
+	Region      string
+	Tenant      string
+}
+
+// CacheDegradationConfig controls when the cache is bypassed after repeated
+// failures and how often recovery is probed. A threshold of zero or less
+// disables degraded mode.
+type CacheDegradationConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+	ProbeTimeout     time.Duration
 }
 
 // RedisConfig holds Redis-specific configuration
@@ -58,23 +188,670 @@ type RedisConfig struct {
 	TTL          time.Duration
 }
 
-// JWTConfig holds JWT-specific configuration
+// Access token formats
+const (
+	TokenFormatJWT    = "jwt"
+	TokenFormatOpaque = "opaque"
+)
+
+// Authentication modes for browser clients
+const (
+	AuthModeBearer = "bearer"
+	AuthModeCookie = "cookie"
+)
+
+// JWTConfig holds JWT-specific configuration. TokenFormat switches to opaque
+// server-side tokens, which reuse Expiration as their lifetime.
 type JWTConfig struct {
-	SecretKey  string
-	Expiration time.Duration
+	SecretKey   string
+	Expiration  time.Duration
+	TokenFormat string
+	// RefreshExpiration is the lifetime of a refresh token. Every refresh
+	// issues a new one, so a session expires after this long without use.
+	RefreshExpiration time.Duration
+	// RememberMeExpiration is the refresh token lifetime of logins made with
+	// remember_me; zero gives them RefreshExpiration
+	RememberMeExpiration time.Duration
+	// TrackSessions records every refresh token family as a session that
+	// its user can list and revoke
+	TrackSessions bool
+	// Issuer and Audience are set on issued tokens and must match on validation,
+	// so tokens minted by other environments or services are rejected
+	Issuer    string
+	Audience  string
+	ClockSkew time.Duration // tolerance for exp, nbf and iat checks
+	// AcceptedAudiences are further audiences accepted on validation, e.g.
+	// the previous Audience while it is renamed. Tokens must carry Audience
+	// or one of these.
+	AcceptedAudiences []string
+	// ClaimNamespace prefixes the user_id, email and role claims of issued
+	// tokens, e.g. "https://api.example.com/", so they cannot collide with
+	// claims of other issuers
+	ClaimNamespace string
+	// TrustedIssuers are other issuers, e.g. tenants or environments, whose
+	// tokens are accepted too. They are validated, never issued.
+	TrustedIssuers []TrustedIssuerConfig
+	// SkipPaths are extra public endpoints as "[METHOD ]PATTERN" rules, where
+	// "*" matches one path segment and a trailing "/**" any depth
+	SkipPaths []string
+	Keys      JWTKeysConfig
+	// RouteOverrides open or close endpoints at runtime through the admin API
+	RouteOverrides RouteOverrideConfig
+}
+
+// RouteOverrideConfig controls runtime route overrides. They are stored in
+// the repository and every instance reloads them at RefreshInterval.
+type RouteOverrideConfig struct {
+	Enabled         bool
+	RefreshInterval time.Duration
+}
+
+// TrustedIssuerConfig describes another issuer of HS256 tokens. Its tokens
+// must carry Issuer and, when set, Audience, and name the user in claims
+// prefixed with ClaimNamespace. Its users are "<Tenant>:<user_id>" here, never
+// users of this deployment.
+type TrustedIssuerConfig struct {
+	Tenant         string
+	Issuer         string
+	Secret         string
+	Audience       string
+	ClaimNamespace string
+	// Roles maps the issuer's roles to roles of this deployment; tokens with
+	// other roles are refused
+	Roles map[string]string
+	// Permissions caps what the issuer's principals may do: their role must
+	// hold a permission and so must this list. Empty grants none.
+	Permissions []string
+}
+
+// JWT signing key sources
+const (
+	JWTKeySourceStatic = "static"
+	JWTKeySourceFile   = "file"
+	JWTKeySourceURL    = "url"
+)
+
+// JWTKeysConfig selects where JWT signing keys come from. The static source
+// signs with JWTConfig.SecretKey; file and url key sets are reloaded in the
+// background, so keys can be rotated without a restart.
+type JWTKeysConfig struct {
+	Source   string
+	File     string
+	URL      string
+	URLToken string // sent as a bearer token to the key set URL
+	// RefreshInterval is the time between reloads; RetryInterval replaces it
+	// after a failed reload
+	RefreshInterval time.Duration
+	RetryInterval   time.Duration
+	// GracePeriod is how long the last key set that loaded is still used
+	// while reloads fail. Zero keeps it until a reload succeeds.
+	GracePeriod time.Duration
+}
+
+// AuthConfig selects how browsers hold their access token. In bearer mode
+// clients send it in the Authorization header. In cookie mode login puts it
+// in an HttpOnly cookie instead, and state-changing requests made with the
+// cookie need a CSRF token; bearer tokens are still accepted.
+type AuthConfig struct {
+	Mode   string
+	Cookie CookieSessionConfig
+}
+
+// CookieSessionConfig describes the session cookie of cookie mode. The CSRF
+// token is derived from the session with CSRFSecret; without one a random
+// secret is used, which does not survive restarts or work across instances.
+type CookieSessionConfig struct {
+	Name           string
+	CSRFCookieName string
+	Domain         string
+	Path           string
+	Secure         bool
+	SameSite       string // lax, strict or none
+	CSRFSecret     string
+}
+
+// HMACConfig holds configuration for HMAC request signing used by machine-to-machine callers
+type HMACConfig struct {
+	Enabled      bool
+	Clients      map[string]string // client ID -> shared secret
+	MaxClockSkew time.Duration
+	NonceTTL     time.Duration
+}
+
+// HooksConfig holds configuration for integrator lifecycle hooks
+type HooksConfig struct {
+	Timeout time.Duration
+}
+
+// RecoveryConfig holds configuration for security-question account recovery
+type RecoveryConfig struct {
+	Enabled         bool
+	Questions       []string // catalog users choose their questions from
+	RequiredAnswers int
+	MaxAttempts     int
+	AttemptWindow   time.Duration
+	TokenTTL        time.Duration
+}
+
+// PasswordResetConfig holds configuration for the emailed password reset flow.
+// ResetURL is the link sent to users, with "{token}" replaced by the token.
+type PasswordResetConfig struct {
+	Enabled       bool
+	ResetURL      string
+	TokenTTL      time.Duration
+	MaxAttempts   int // reset requests per email, and per client IP, in AttemptWindow
+	AttemptWindow time.Duration
+}
+
+// EmailConfig holds configuration for outgoing email. Without an SMTP host
+// emails are only logged.
+type EmailConfig struct {
+	From   string
+	SMTP   SMTPConfig
+	Outbox EmailOutboxConfig
+}
+
+// EmailOutboxConfig controls the outbox emails are recorded in before they
+// are sent. Failed deliveries are retried after RetryBackoff, doubling with
+// each attempt, until MaxAttempts have been made.
+type EmailOutboxConfig struct {
+	Enabled      bool
+	PollInterval time.Duration
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+// SMTPConfig holds the SMTP server emails are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// PublicProfileConfig controls the public profile and WebFinger discovery
+// endpoints. Users still have to opt in through the public_profile preference.
+type PublicProfileConfig struct {
+	Enabled bool
+	// BaseURL is the externally reachable URL of the API, used for profile links
+	BaseURL string
+	// Domain is the host of acct: URIs; empty uses the host of BaseURL
+	Domain string
+}
+
+// FieldEncryptionConfig controls application-layer encryption of sensitive
+// fields at rest. The static source encrypts with Key under KeyID; file and
+// url key sets take the same format as JWT key sets, and are reloaded in the
+// background so the current key can be rotated without a restart.
+type FieldEncryptionConfig struct {
+	Enabled bool
+	KeyID   string
+	Key     string
+	Keys    JWTKeysConfig
+}
+
+// PresenceConfig controls last-seen tracking. A user seen within OnlineWindow
+// is online, within RecentWindow recently active, and offline after that.
+// Activity is written at most once per Debounce per user.
+type PresenceConfig struct {
+	Enabled      bool
+	OnlineWindow time.Duration
+	RecentWindow time.Duration
+	Debounce     time.Duration
+}
+
+// WebAuthnConfig controls passkey registration and login. RPID is the
+// relying party ID, the domain passkeys are bound to, and Origins lists the
+// origins browsers may run the ceremonies from. UserVerification is
+// "required", "preferred" or "discouraged".
+type WebAuthnConfig struct {
+	Enabled          bool
+	RPID             string
+	RPName           string
+	Origins          []string
+	UserVerification string
+	ChallengeTTL     time.Duration
+}
+
+// OIDCConfig controls login through an external OpenID Connect provider,
+// found from the discovery document of IssuerURL. Users are matched by their
+// email and created on first login when AutoProvision is set. RoleMapping maps
+// values of the RoleClaim claim to roles. PasswordLogin keeps local password
+// registration and login available beside the provider.
+type OIDCConfig struct {
+	Enabled              bool
+	IssuerURL            string
+	ClientID             string
+	ClientSecret         string
+	RedirectURL          string
+	Scopes               []string
+	AutoProvision        bool
+	RequireVerifiedEmail bool
+	EmailClaim           string
+	NameClaim            string
+	RoleClaim            string
+	RoleMapping          map[string]string
+	PasswordLogin        bool
+	StateTTL             time.Duration
+	Leeway               time.Duration
+}
+
+// APIKeyConfig controls API keys, long-lived credentials users create for
+// scripts and integrations. MaxPerUser of 0 does not limit them.
+type APIKeyConfig struct {
+	Enabled    bool
+	MaxPerUser int
+}
+
+// ClientCredentialsConfig controls machine clients, backend services that get
+// tokens at /auth/token with their own client ID and secret. With
+// TokenExchange they may also trade a user's token for a narrower one at
+// /auth/token/exchange, valid for at most ExchangeTokenTTL.
+type ClientCredentialsConfig struct {
+	Enabled          bool
+	TokenTTL         time.Duration
+	TokenExchange    bool
+	ExchangeTokenTTL time.Duration
+}
+
+// EmailCheckConfig controls the public email availability check. Attempts
+// are limited per client IP, and every answer takes at least MinResponseTime
+// so timing does not reveal which emails exist.
+type EmailCheckConfig struct {
+	Enabled         bool
+	MaxAttempts     int
+	AttemptWindow   time.Duration
+	MinResponseTime time.Duration
+	// GlobalMaxAttempts bounds the checks of all clients together per
+	// AttemptWindow, so rotating IP addresses does not allow enumeration;
+	// 0 removes the bound
+	GlobalMaxAttempts int
+}
+
+// EmailDomainConfig holds the rules for which email domains accounts may use.
+// Each domain also covers its subdomains.
+type EmailDomainConfig struct {
+	Enabled         bool
+	Allow           []string // when set, only these domains may register
+	Deny            []string // domains that may not register
+	DenyListFile    string   // maintained deny-list, one domain per line; # starts a comment
+	DenyListURL     string   // or fetched from a URL
+	RefreshInterval time.Duration
+}
+
+// ContentPolicyConfig holds configuration for screening user-supplied display names
+type ContentPolicyConfig struct {
+	Enabled           bool
+	Blocklist         []string // terms rejected anywhere in a name
+	BlocklistFile     string   // optional file with one term per line; # starts a comment
+	BlockMixedScripts bool     // reject words mixing Latin with Cyrillic or Greek lookalikes
+}
+
+// PasswordPolicyConfig holds the rules new passwords must follow
+type PasswordPolicyConfig struct {
+	MinLength        int // in characters
+	MaxLength        int // in bytes; bcrypt ignores anything past 72
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// BreachCheck rejects passwords found in data breaches by querying a
+	// k-anonymity range API; only a hash prefix is sent
+	BreachCheck        bool
+	BreachCheckURL     string // the hash prefix is appended
+	BreachCheckTimeout time.Duration
+}
+
+// PasswordHashConfig selects how new password hashes are made. Stored hashes
+// of any supported algorithm keep verifying after a change.
+type PasswordHashConfig struct {
+	Algorithm  string // bcrypt or argon2id
+	BcryptCost int
+	Argon2     Argon2Config
+}
+
+// Argon2Config holds the argon2id parameters; Memory is in KiB
+type Argon2Config struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2 follows the OWASP recommendation of 64 MiB, 3 passes and 2 lanes
+var DefaultArgon2 = Argon2Config{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32}
+
+// SIEM transports
+const (
+	SIEMTransportSyslog = "syslog"
+	SIEMTransportHTTP   = "http"
+)
+
+// SecurityEventsConfig controls the security event stream. Login failures
+// above FailureThreshold per email or client IP within FailureWindow are
+// flagged as repeated failures; a threshold of 0 disables the flag.
+type SecurityEventsConfig struct {
+	BufferSize       int // recent events kept in memory for the admin API
+	FailureThreshold int
+	FailureWindow    time.Duration
+}
+
+// TransferConfig controls admin export and import of user accounts between
+// instances. Bundles carry password hashes, so they are signed with a key
+// shared by the instances and expire after BundleTTL.
+type TransferConfig struct {
+	Enabled    bool
+	SigningKey string
+	Source     string // environment name written into exported bundles
+	BundleTTL  time.Duration
+	MaxUsers   int // users per export or import
+}
+
+// Snapshot storage backends
+const (
+	SnapshotStorageFile = "file"
+	SnapshotStorageS3   = "s3"
+)
+
+// AnnouncementConfig controls the announcement banner admins set for every
+// client. Each instance rereads it every RefreshInterval, so changes made
+// through another instance show up within that interval.
+type AnnouncementConfig struct {
+	Enabled         bool
+	RefreshInterval time.Duration
+}
+
+// UsageConfig controls per-principal API usage analytics. Each instance
+// adds its counts to the shared daily counters every FlushInterval; every
+// RollupInterval the counters of today and yesterday are persisted to the
+// repository, which serves the usage routes.
+type UsageConfig struct {
+	Enabled        bool
+	FlushInterval  time.Duration
+	RollupInterval time.Duration
+	// MaxDays bounds the days a usage request may cover
+	MaxDays int
+}
+
+// LoginHistoryConfig controls recording every login attempt for the
+// login history routes. Attempts are kept for Retention; zero keeps them
+// forever.
+type LoginHistoryConfig struct {
+	Enabled   bool
+	Retention time.Duration
+}
+
+// SuspiciousLoginConfig flags successful logins from a country or device
+// not seen in the user's last Lookback recorded logins. Users need at least
+// MinHistory earlier successful logins before anything is flagged, so first
+// logins are not. Countries are read from CountryHeader, set by a CDN or
+// load balancer such as Cloudflare's CF-IPCountry; empty disables the
+// country check. Notify emails the user about flagged logins.
+type SuspiciousLoginConfig struct {
+	Enabled       bool
+	NewCountry    bool
+	NewDevice     bool
+	Lookback      int
+	MinHistory    int
+	Notify        bool
+	CountryHeader string
+}
+
+// AdminSearchConfig bounds the admin search. Each source is searched under
+// SourceTimeout, and at most MaxConcurrent source searches run at once
+// across all requests; waiting for a slot counts against the timeout.
+type AdminSearchConfig struct {
+	Enabled       bool
+	SourceTimeout time.Duration
+	MaxConcurrent int
+}
+
+// InviteConfig controls invitations: admins invite an email, optionally
+// with a role, and the invite code lets that email register. Invites expire
+// after TTL. InviteOnly refuses registrations without a valid code.
+type InviteConfig struct {
+	Enabled    bool
+	InviteOnly bool
+	TTL        time.Duration
+}
+
+// DemoConfig runs the server as a public showcase. Users are replaced
+// with a known admin and Users sample users, and the audit log with sample
+// events, at startup and every ResetInterval; zero only seeds at startup.
+// Every other account is deleted on reset.
+type DemoConfig struct {
+	Enabled       bool
+	AdminEmail    string
+	AdminPassword string
+	UserPassword  string
+	Users         int
+	ResetInterval time.Duration
+}
+
+// CORSConfig lists the origins browsers may call the API from. "*" allows
+// every origin; other origins are echoed back when they match exactly.
+type CORSConfig struct {
+	AllowedOrigins []string
+}
+
+// SecretsHygieneConfig controls the startup check for insecure defaults: the
+// built-in JWT secret, Redis without a password on another host, and CORS
+// open to every origin. Findings are logged as warnings; in the production
+// environment the server refuses to start unless each one is listed in
+// Acknowledged.
+type SecretsHygieneConfig struct {
+	Environment  string
+	Acknowledged []string
+}
+
+// Production reports whether the check refuses to start on findings
+func (c SecretsHygieneConfig) Production() bool {
+	return strings.EqualFold(c.Environment, "production")
+}
+
+// SnapshotConfig controls admin snapshots of the users collection, meant for
+// quick recovery in demo and staging environments. Snapshots carry password
+// hashes. A restore must be confirmed with a token valid for ConfirmTTL.
+type SnapshotConfig struct {
+	Enabled bool
+	Storage string // file or s3
+	Dir     string // directory for file storage
+	S3      SnapshotS3Config
+	Source  string // environment name written into snapshots
+	// ConfirmationKey signs restore confirmation tokens, so a token from
+	// the HTTP API or the CLI is accepted by every instance sharing the key
+	ConfirmationKey string
+	ConfirmTTL      time.Duration
+}
+
+// SnapshotS3Config locates the bucket for s3 snapshot storage. Endpoint
+// defaults to AWS; set it for S3-compatible stores such as MinIO.
+type SnapshotS3Config struct {
+	Bucket          string
+	Prefix          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// TelemetryConfig controls opt-in anonymous usage reporting. Nothing is sent
+// unless Enabled is set and Endpoint is configured.
+type TelemetryConfig struct {
+	Enabled  bool
+	Endpoint string
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// LoginThrottleConfig locks an email, and a client IP trying many emails, out
+// of logging in after repeated failures. IPMaxFailures of zero disables the
+// per-IP limit. The lockout status endpoint has its own, stricter limit per
+// client IP.
+type LoginThrottleConfig struct {
+	Enabled         bool
+	MaxFailures     int
+	Window          time.Duration
+	IPMaxFailures   int
+	IPWindow        time.Duration
+	StatusMaxChecks int
+	StatusWindow    time.Duration
+}
+
+// RetentionConfig sets how long each class of user data is kept. A zero
+// period keeps that class forever. Purges only run on schedule when Enabled;
+// the dry-run report is always available to admins.
+type RetentionConfig struct {
+	Enabled      bool
+	Interval     time.Duration
+	BatchSize    int
+	AuditEvents  time.Duration
+	DeletedUsers time.Duration
+}
+
+// SIEMConfig holds configuration for forwarding audit and authentication
+// events to a SIEM over syslog or an HTTP event collector
+type SIEMConfig struct {
+	Enabled       bool
+	Transport     string // syslog or http
+	SyslogNetwork string // udp or tcp
+	SyslogAddress string
+	HTTPURL       string // e.g. https://splunk:8088/services/collector/event
+	HTTPToken     string
+	SourceType    string
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+}
+
+// Rate limit tier names, selected from the authenticated principal's role
+const (
+	RateLimitTierAnonymous = "anonymous"
+	RateLimitTierUser      = "user"
+	RateLimitTierAdmin     = "admin"
+	RateLimitTierService   = "service"
+)
+
+// Stores of rate limit and lockout counters
+const (
+	RateLimitStoreAuto   = "auto"   // Redis when the cache is available, memory otherwise
+	RateLimitStoreMemory = "memory" // per instance
+	RateLimitStoreRedis  = "redis"  // shared by every instance; startup fails without Redis
+)
+
+// RateLimitConfig holds per-tier request rate limits. A limit of zero or less
+// disables limiting for that tier. Store picks where the counters of every
+// limiter are kept: these tiers, login throttling, password reset, account
+// recovery, email checks and security event thresholds.
+type RateLimitConfig struct {
+	Enabled bool
+	Window  time.Duration
+	Tiers   map[string]int // tier -> requests per window
+	Store   string
+}
+
+// ProfileConfig controls how user responses are enriched from other sources
+type ProfileConfig struct {
+	// AvatarURLTemplate is the storage URL of a user's avatar with {id} as the
+	// user ID placeholder; empty disables avatar URLs
+	AvatarURLTemplate string
+	// SourceTimeout bounds each enrichment source fetch
+	SourceTimeout time.Duration
+}
+
+// RolesConfig controls which roles users may pick for themselves. Any other
+// role can only be assigned by an admin.
+type RolesConfig struct {
+	Default        string
+	SelfAssignable []string
+	NoteRoles      []string // roles that may read and write internal notes on users
+	// RecheckInterval is how old the role applied to a token may be; 0
+	// trusts the role in tokens until they expire
+	RecheckInterval time.Duration
+	// Scopes are the resource scopes, such as users:write, granted to each role
+	Scopes map[string][]string
+	// Custom keeps the roles in the database, where admins define them
+	Custom CustomRolesConfig
+}
+
+// CustomRolesConfig controls roles defined by admins. Users can then only be
+// assigned roles that exist. Each instance reloads the roles at most every
+// RefreshInterval, so changes made through another instance show up by then.
+type CustomRolesConfig struct {
+	Enabled         bool
+	RefreshInterval time.Duration
+}
+
+// PolicyConfig holds the role → permission matrix of the admin API. A role
+// is granted permissions by name, by "<area>.*" or by "*" for all of them.
+type PolicyConfig struct {
+	Permissions map[string][]string
+}
+
+// PaginationConfig caps list page sizes. Larger requested limits are reduced
+// to MaxLimit.
+type PaginationConfig struct {
+	MaxLimit int
+}
+
+// DedupConfig controls coalescing of concurrent identical GET requests
+type DedupConfig struct {
+	Enabled bool
+}
+
+// DefaultRecoveryQuestions is the built-in security question catalog
+var DefaultRecoveryQuestions = []string{
+	"What was the name of your first pet?",
+	"In what city were you born?",
+	"What was the name of your first school?",
+	"What is your oldest sibling's middle name?",
+	"What was the make of your first car?",
 }
 
 // Default timeout constants
 const (
 	DefaultReadWriteTimeout = 15 * time.Second
 	DefaultShutdownTimeout  = 30 * time.Second
+	DefaultRequestTimeout   = 10 * time.Second
 	DefaultDBTimeout        = 10 * time.Second
 	DefaultMaxPoolSize      = 100
 	DefaultJWTExpiration    = 24 * time.Hour
+	DefaultJWTClockSkew     = 30 * time.Second
+	DefaultRefreshTokenTTL  = 7 * 24 * time.Hour
+	DefaultRememberMeTTL    = 30 * 24 * time.Hour
+	DefaultKeyRefresh       = 5 * time.Minute
+	DefaultKeyRetry         = 10 * time.Second
+	DefaultKeyGracePeriod   = 24 * time.Hour
 	DefaultCacheTTL         = 5 * time.Minute
 	DefaultRedisDataTTL     = 1 * time.Hour
+	DefaultHMACClockSkew    = 5 * time.Minute
+	DefaultHookTimeout      = 2 * time.Second
+	DefaultRecoveryWindow   = 15 * time.Minute
+	DefaultResetTokenTTL    = 15 * time.Minute
+	DefaultPasswordResetTTL = 30 * time.Minute
+	DefaultDenyListRefresh  = time.Hour
 )
 
+// Default password rules
+const (
+	DefaultPasswordMinLength = 6
+	DefaultPasswordMaxLength = 72
+	DefaultBcryptCost        = 10
+	DefaultBreachCheckURL    = "https://api.pwnedpasswords.com/range/"
+)
+
+// Default token claims
+const (
+	DefaultJWTIssuer   = "demo-go-api"
+	DefaultJWTAudience = "demo-go-api"
+)
+
+// DefaultJWTSecret is the development signing key used when JWT_SECRET is unset
+const DefaultJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
 // Load creates and returns a new Config with values from environment variables
 func Load() *Config {
 	return &Config{
@@ -84,6 +861,15 @@ func Load() *Config {
 			ReadTimeout:     getDurationEnv("SERVER_READ_TIMEOUT", DefaultReadWriteTimeout),
 			WriteTimeout:    getDurationEnv("SERVER_WRITE_TIMEOUT", DefaultReadWriteTimeout),
 			ShutdownTimeout: getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", DefaultShutdownTimeout),
+			RequestTimeout:  getDurationEnv("SERVER_REQUEST_TIMEOUT", DefaultRequestTimeout),
+			APIVersion:      getEnv("API_VERSION", "v1"),
+			AppVersion:      getEnv("APP_VERSION", "1.0.0"),
+			TrustedProxies:  getListEnv("TRUSTED_PROXIES", ",", []string{"127.0.0.1", "::1"}),
+		},
+		Response: ResponseConfig{
+			FieldNaming:   getEnv("RESPONSE_FIELD_NAMING", "snake"),
+			Envelope:      getBoolEnv("RESPONSE_ENVELOPE", true),
+			FormatHeaders: getBoolEnv("RESPONSE_FORMAT_HEADERS", true),
 		},
 		Database: DatabaseConfig{
 			MongoDB: MongoDBConfig{
@@ -91,9 +877,25 @@ func Load() *Config {
 				Database:    getEnv("MONGODB_DATABASE", "demo_clean"),
 				Timeout:     getDurationEnv("MONGODB_TIMEOUT", DefaultDBTimeout),
 				MaxPoolSize: getIntEnv("MONGODB_MAX_POOL_SIZE", DefaultMaxPoolSize),
+
+				LogQueries:     getBoolEnv("MONGODB_LOG_QUERIES", false),
+				ExplainEnabled: getBoolEnv("MONGODB_EXPLAIN_ENABLED", false),
+
+				WriteBuffer: WriteBufferConfig{
+					Enabled:       getBoolEnv("MONGODB_WRITE_BUFFER_ENABLED", false),
+					MaxPending:    getIntEnv("MONGODB_WRITE_BUFFER_SIZE", 100),
+					Timeout:       getDurationEnv("MONGODB_WRITE_BUFFER_TIMEOUT", 10*time.Second),
+					RetryInterval: getDurationEnv("MONGODB_WRITE_BUFFER_RETRY_INTERVAL", 250*time.Millisecond),
+				},
+			},
+			Migration: RepositoryMigrationConfig{
+				Target:      getEnv("REPOSITORY_MIGRATION_TARGET", ""),
+				ShadowReads: getBoolEnv("REPOSITORY_MIGRATION_SHADOW_READS", true),
+				Timeout:     getDurationEnv("REPOSITORY_MIGRATION_TIMEOUT", 2*time.Second),
 			},
 		},
 		Cache: CacheConfig{
+			Type: getEnv("CACHE_TYPE", ""),
 			Redis: RedisConfig{
 				Address:      getEnv("REDIS_ADDRESS", "localhost:6379"),
 				Password:     getEnv("REDIS_PASSWORD", ""),
@@ -107,10 +909,361 @@ func Load() *Config {
 				IdleTimeout:  getDurationEnv("REDIS_IDLE_TIMEOUT", DefaultCacheTTL),
 				TTL:          getDurationEnv("REDIS_TTL", DefaultRedisDataTTL),
 			},
+			Degradation: CacheDegradationConfig{
+				FailureThreshold: getIntEnv("CACHE_DEGRADE_THRESHOLD", 5),
+				Cooldown:         getDurationEnv("CACHE_DEGRADE_COOLDOWN", 30*time.Second),
+				ProbeTimeout:     getDurationEnv("CACHE_PROBE_TIMEOUT", time.Second),
+			},
+			Strategies: getMapEnv("CACHE_STRATEGIES"),
+			WriteBehind: WriteBehindConfig{
+				QueueSize:  getIntEnv("CACHE_WRITE_BEHIND_QUEUE_SIZE", 1000),
+				Workers:    getIntEnv("CACHE_WRITE_BEHIND_WORKERS", 4),
+				DropPolicy: getEnv("CACHE_WRITE_BEHIND_DROP_POLICY", "sync"),
+			},
+			ListPages: ListPageCacheConfig{
+				Enabled: getBoolEnv("CACHE_LIST_PAGES_ENABLED", false),
+				SoftTTL: getDurationEnv("CACHE_LIST_PAGES_SOFT_TTL", 30*time.Second),
+				HardTTL: getDurationEnv("CACHE_LIST_PAGES_HARD_TTL", 5*time.Minute),
+			},
+			Namespace: CacheNamespaceConfig{
+				Environment: getEnv("CACHE_NAMESPACE_ENVIRONMENT", ""),
+				Region:      getEnv("CACHE_NAMESPACE_REGION", ""),
+				Tenant:      getEnv("CACHE_NAMESPACE_TENANT", ""),
+			},
 		},
 		JWT: JWTConfig{
-			SecretKey:  getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
-			Expiration: getDurationEnv("JWT_EXPIRATION", DefaultJWTExpiration),
+			SecretKey:            getEnv("JWT_SECRET", DefaultJWTSecret),
+			Expiration:           getDurationEnv("JWT_EXPIRATION", DefaultJWTExpiration),
+			TokenFormat:          getEnv("TOKEN_FORMAT", TokenFormatJWT),
+			RefreshExpiration:    getDurationEnv("JWT_REFRESH_EXPIRATION", DefaultRefreshTokenTTL),
+			RememberMeExpiration: getDurationEnv("JWT_REMEMBER_ME_EXPIRATION", DefaultRememberMeTTL),
+			TrackSessions:        getBoolEnv("SESSIONS_ENABLED", false),
+			Issuer:               getEnv("JWT_ISSUER", DefaultJWTIssuer),
+			Audience:             getEnv("JWT_AUDIENCE", DefaultJWTAudience),
+			ClockSkew:            getDurationEnv("JWT_CLOCK_SKEW", DefaultJWTClockSkew),
+			AcceptedAudiences:    getListEnv("JWT_ACCEPTED_AUDIENCES", ",", nil),
+			ClaimNamespace:       getEnv("JWT_CLAIM_NAMESPACE", ""),
+			TrustedIssuers:       getTrustedIssuers(),
+			SkipPaths:            getListEnv("JWT_SKIP_PATHS", ",", nil),
+			Keys: JWTKeysConfig{
+				Source:          getEnv("JWT_KEYS_SOURCE", JWTKeySourceStatic),
+				File:            getEnv("JWT_KEYS_FILE", ""),
+				URL:             getEnv("JWT_KEYS_URL", ""),
+				URLToken:        getEnv("JWT_KEYS_URL_TOKEN", ""),
+				RefreshInterval: getDurationEnv("JWT_KEYS_REFRESH_INTERVAL", DefaultKeyRefresh),
+				RetryInterval:   getDurationEnv("JWT_KEYS_RETRY_INTERVAL", DefaultKeyRetry),
+				GracePeriod:     getDurationEnv("JWT_KEYS_GRACE_PERIOD", DefaultKeyGracePeriod),
+			},
+			RouteOverrides: RouteOverrideConfig{
+				Enabled:         getBoolEnv("ROUTE_OVERRIDES_ENABLED", false),
+				RefreshInterval: getDurationEnv("ROUTE_OVERRIDES_REFRESH_INTERVAL", 30*time.Second),
+			},
+		},
+		HMAC: HMACConfig{
+			Enabled:      getBoolEnv("HMAC_AUTH_ENABLED", false),
+			Clients:      getMapEnv("HMAC_CLIENTS"),
+			MaxClockSkew: getDurationEnv("HMAC_MAX_CLOCK_SKEW", DefaultHMACClockSkew),
+			// Nonces only need to outlive the window in which a timestamp is accepted
+			NonceTTL: getDurationEnv("HMAC_NONCE_TTL", 2*DefaultHMACClockSkew),
+		},
+		Auth: AuthConfig{
+			Mode: getEnv("AUTH_MODE", AuthModeBearer),
+			Cookie: CookieSessionConfig{
+				Name:           getEnv("SESSION_COOKIE_NAME", "session"),
+				CSRFCookieName: getEnv("CSRF_COOKIE_NAME", "csrf_token"),
+				Domain:         getEnv("SESSION_COOKIE_DOMAIN", ""),
+				Path:           getEnv("SESSION_COOKIE_PATH", "/"),
+				Secure:         getBoolEnv("SESSION_COOKIE_SECURE", true),
+				SameSite:       getEnv("SESSION_COOKIE_SAMESITE", "lax"),
+				CSRFSecret:     getEnv("CSRF_SECRET", ""),
+			},
+		},
+		Hooks: HooksConfig{
+			Timeout: getDurationEnv("HOOK_TIMEOUT", DefaultHookTimeout),
+		},
+		Recovery: RecoveryConfig{
+			Enabled:         getBoolEnv("RECOVERY_QUESTIONS_ENABLED", false),
+			Questions:       getListEnv("RECOVERY_QUESTIONS", "|", DefaultRecoveryQuestions),
+			RequiredAnswers: getIntEnv("RECOVERY_REQUIRED_ANSWERS", 2),
+			MaxAttempts:     getIntEnv("RECOVERY_MAX_ATTEMPTS", 5),
+			AttemptWindow:   getDurationEnv("RECOVERY_ATTEMPT_WINDOW", DefaultRecoveryWindow),
+			TokenTTL:        getDurationEnv("RESET_TOKEN_TTL", DefaultResetTokenTTL),
+		},
+		PasswordReset: PasswordResetConfig{
+			Enabled:       getBoolEnv("PASSWORD_RESET_ENABLED", false),
+			ResetURL:      getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password?token={token}"),
+			TokenTTL:      getDurationEnv("PASSWORD_RESET_TOKEN_TTL", DefaultPasswordResetTTL),
+			MaxAttempts:   getIntEnv("PASSWORD_RESET_MAX_ATTEMPTS", 5),
+			AttemptWindow: getDurationEnv("PASSWORD_RESET_ATTEMPT_WINDOW", time.Hour),
+		},
+		Email: EmailConfig{
+			From: getEnv("EMAIL_FROM", "no-reply@localhost"),
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getIntEnv("SMTP_PORT", 587),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+			},
+			Outbox: EmailOutboxConfig{
+				Enabled:      getBoolEnv("EMAIL_OUTBOX_ENABLED", false),
+				PollInterval: getDurationEnv("EMAIL_OUTBOX_POLL_INTERVAL", 10*time.Second),
+				MaxAttempts:  getIntEnv("EMAIL_OUTBOX_MAX_ATTEMPTS", 5),
+				RetryBackoff: getDurationEnv("EMAIL_OUTBOX_RETRY_BACKOFF", time.Minute),
+			},
+		},
+		PublicProfile: PublicProfileConfig{
+			Enabled: getBoolEnv("PUBLIC_PROFILES_ENABLED", false),
+			BaseURL: strings.TrimSuffix(getEnv("PUBLIC_BASE_URL", "http://localhost:"+getEnv("SERVER_PORT", "8080")), "/"),
+			Domain:  getEnv("WEBFINGER_DOMAIN", ""),
+		},
+		Encryption: FieldEncryptionConfig{
+			Enabled: getBoolEnv("FIELD_ENCRYPTION_ENABLED", false),
+			KeyID:   getEnv("FIELD_ENCRYPTION_KEY_ID", "default"),
+			Key:     getEnv("FIELD_ENCRYPTION_KEY", ""),
+			Keys: JWTKeysConfig{
+				Source:          getEnv("FIELD_ENCRYPTION_KEYS_SOURCE", JWTKeySourceStatic),
+				File:            getEnv("FIELD_ENCRYPTION_KEYS_FILE", ""),
+				URL:             getEnv("FIELD_ENCRYPTION_KEYS_URL", ""),
+				URLToken:        getEnv("FIELD_ENCRYPTION_KEYS_URL_TOKEN", ""),
+				RefreshInterval: getDurationEnv("FIELD_ENCRYPTION_KEYS_REFRESH_INTERVAL", DefaultKeyRefresh),
+				RetryInterval:   getDurationEnv("FIELD_ENCRYPTION_KEYS_RETRY_INTERVAL", DefaultKeyRetry),
+				GracePeriod:     getDurationEnv("FIELD_ENCRYPTION_KEYS_GRACE_PERIOD", 0),
+			},
+		},
+		Presence: PresenceConfig{
+			Enabled:      getBoolEnv("PRESENCE_ENABLED", false),
+			OnlineWindow: getDurationEnv("PRESENCE_ONLINE_WINDOW", 5*time.Minute),
+			RecentWindow: getDurationEnv("PRESENCE_RECENT_WINDOW", 24*time.Hour),
+			Debounce:     getDurationEnv("PRESENCE_DEBOUNCE", time.Minute),
+		},
+		WebAuthn: WebAuthnConfig{
+			Enabled:          getBoolEnv("WEBAUTHN_ENABLED", false),
+			RPID:             getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName:           getEnv("WEBAUTHN_RP_NAME", "demo-go"),
+			Origins:          getListEnv("WEBAUTHN_ORIGINS", ",", []string{"http://localhost:8080"}),
+			UserVerification: getEnv("WEBAUTHN_USER_VERIFICATION", "preferred"),
+			ChallengeTTL:     getDurationEnv("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute),
+		},
+		OIDC: OIDCConfig{
+			Enabled:              getBoolEnv("OIDC_ENABLED", false),
+			IssuerURL:            getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:             getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:         getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:          getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+			Scopes:               getListEnv("OIDC_SCOPES", ",", []string{"openid", "email", "profile"}),
+			AutoProvision:        getBoolEnv("OIDC_AUTO_PROVISION", true),
+			RequireVerifiedEmail: getBoolEnv("OIDC_REQUIRE_VERIFIED_EMAIL", true),
+			EmailClaim:           getEnv("OIDC_EMAIL_CLAIM", "email"),
+			NameClaim:            getEnv("OIDC_NAME_CLAIM", "name"),
+			RoleClaim:            getEnv("OIDC_ROLE_CLAIM", ""),
+			RoleMapping:          getMapEnv("OIDC_ROLE_MAPPING"),
+			PasswordLogin:        getBoolEnv("AUTH_PASSWORD_LOGIN_ENABLED", true),
+			StateTTL:             getDurationEnv("OIDC_STATE_TTL", 10*time.Minute),
+			Leeway:               getDurationEnv("OIDC_LEEWAY", time.Minute),
+		},
+		APIKeys: APIKeyConfig{
+			Enabled:    getBoolEnv("API_KEYS_ENABLED", false),
+			MaxPerUser: getIntEnv("API_KEYS_MAX_PER_USER", 10),
+		},
+		Clients: ClientCredentialsConfig{
+			Enabled:          getBoolEnv("CLIENT_CREDENTIALS_ENABLED", false),
+			TokenTTL:         getDurationEnv("CLIENT_TOKEN_TTL", time.Hour),
+			TokenExchange:    getBoolEnv("CLIENT_TOKEN_EXCHANGE_ENABLED", false),
+			ExchangeTokenTTL: getDurationEnv("CLIENT_EXCHANGE_TOKEN_TTL", 5*time.Minute),
+		},
+		EmailCheck: EmailCheckConfig{
+			Enabled:         getBoolEnv("EMAIL_CHECK_ENABLED", true),
+			MaxAttempts:     getIntEnv("EMAIL_CHECK_MAX_ATTEMPTS", 10),
+			AttemptWindow:   getDurationEnv("EMAIL_CHECK_WINDOW", 10*time.Minute),
+			MinResponseTime: getDurationEnv("EMAIL_CHECK_MIN_RESPONSE_TIME", 250*time.Millisecond),
+
+			GlobalMaxAttempts: getIntEnv("EMAIL_CHECK_GLOBAL_MAX_ATTEMPTS", 1000),
+		},
+		ContentPolicy: ContentPolicyConfig{
+			Enabled:           getBoolEnv("CONTENT_POLICY_ENABLED", false),
+			Blocklist:         getListEnv("CONTENT_POLICY_BLOCKLIST", ",", nil),
+			BlocklistFile:     getEnv("CONTENT_POLICY_BLOCKLIST_FILE", ""),
+			BlockMixedScripts: getBoolEnv("CONTENT_POLICY_BLOCK_MIXED_SCRIPTS", true),
+		},
+		Passwords: PasswordPolicyConfig{
+			MinLength:          getIntEnv("PASSWORD_MIN_LENGTH", DefaultPasswordMinLength),
+			MaxLength:          getIntEnv("PASSWORD_MAX_LENGTH", DefaultPasswordMaxLength),
+			RequireUppercase:   getBoolEnv("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase:   getBoolEnv("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:       getBoolEnv("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:      getBoolEnv("PASSWORD_REQUIRE_SYMBOL", false),
+			BreachCheck:        getBoolEnv("PASSWORD_BREACH_CHECK_ENABLED", false),
+			BreachCheckURL:     getEnv("PASSWORD_BREACH_CHECK_URL", DefaultBreachCheckURL),
+			BreachCheckTimeout: getDurationEnv("PASSWORD_BREACH_CHECK_TIMEOUT", 2*time.Second),
+		},
+		PasswordHash: PasswordHashConfig{
+			Algorithm:  getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt"),
+			BcryptCost: getIntEnv("BCRYPT_COST", DefaultBcryptCost),
+			Argon2: Argon2Config{
+				Memory:      uint32(getIntEnv("ARGON2_MEMORY_KIB", int(DefaultArgon2.Memory))),
+				Iterations:  uint32(getIntEnv("ARGON2_ITERATIONS", int(DefaultArgon2.Iterations))),
+				Parallelism: uint8(getIntEnv("ARGON2_PARALLELISM", int(DefaultArgon2.Parallelism))),
+				SaltLength:  DefaultArgon2.SaltLength,
+				KeyLength:   DefaultArgon2.KeyLength,
+			},
+		},
+		EmailDomains: EmailDomainConfig{
+			Enabled:         getBoolEnv("EMAIL_DOMAIN_POLICY_ENABLED", false),
+			Allow:           getListEnv("EMAIL_DOMAIN_ALLOWLIST", ",", nil),
+			Deny:            getListEnv("EMAIL_DOMAIN_DENYLIST", ",", nil),
+			DenyListFile:    getEnv("EMAIL_DOMAIN_DENYLIST_FILE", ""),
+			DenyListURL:     getEnv("EMAIL_DOMAIN_DENYLIST_URL", ""),
+			RefreshInterval: getDurationEnv("EMAIL_DOMAIN_DENYLIST_REFRESH_INTERVAL", DefaultDenyListRefresh),
+		},
+		Transfer: TransferConfig{
+			Enabled:    getBoolEnv("ACCOUNT_TRANSFER_ENABLED", false),
+			SigningKey: getEnv("ACCOUNT_TRANSFER_SIGNING_KEY", ""),
+			Source:     getEnv("APP_ENVIRONMENT", "development"),
+			BundleTTL:  getDurationEnv("ACCOUNT_TRANSFER_BUNDLE_TTL", 24*time.Hour),
+			MaxUsers:   getIntEnv("ACCOUNT_TRANSFER_MAX_USERS", 1000),
+		},
+		Announcements: AnnouncementConfig{
+			Enabled:         getBoolEnv("ANNOUNCEMENTS_ENABLED", false),
+			RefreshInterval: getDurationEnv("ANNOUNCEMENTS_REFRESH_INTERVAL", 30*time.Second),
+		},
+		Usage: UsageConfig{
+			Enabled:        getBoolEnv("USAGE_ENABLED", false),
+			FlushInterval:  getDurationEnv("USAGE_FLUSH_INTERVAL", 10*time.Second),
+			RollupInterval: getDurationEnv("USAGE_ROLLUP_INTERVAL", 5*time.Minute),
+			MaxDays:        getIntEnv("USAGE_MAX_DAYS", 90),
+		},
+		LoginHistory: LoginHistoryConfig{
+			Enabled:   getBoolEnv("LOGIN_HISTORY_ENABLED", false),
+			Retention: getDurationEnv("LOGIN_HISTORY_RETENTION", 90*24*time.Hour),
+		},
+		SuspiciousLogin: SuspiciousLoginConfig{
+			Enabled:       getBoolEnv("SUSPICIOUS_LOGIN_ENABLED", false),
+			NewCountry:    getBoolEnv("SUSPICIOUS_LOGIN_NEW_COUNTRY", true),
+			NewDevice:     getBoolEnv("SUSPICIOUS_LOGIN_NEW_DEVICE", true),
+			Lookback:      getIntEnv("SUSPICIOUS_LOGIN_LOOKBACK", 20),
+			MinHistory:    getIntEnv("SUSPICIOUS_LOGIN_MIN_HISTORY", 1),
+			Notify:        getBoolEnv("SUSPICIOUS_LOGIN_NOTIFY", false),
+			CountryHeader: getEnv("SUSPICIOUS_LOGIN_COUNTRY_HEADER", ""),
+		},
+		AdminSearch: AdminSearchConfig{
+			Enabled:       getBoolEnv("ADMIN_SEARCH_ENABLED", false),
+			SourceTimeout: getDurationEnv("ADMIN_SEARCH_SOURCE_TIMEOUT", 2*time.Second),
+			MaxConcurrent: getIntEnv("ADMIN_SEARCH_MAX_CONCURRENT", 8),
+		},
+		Invites: InviteConfig{
+			Enabled:    getBoolEnv("INVITES_ENABLED", false),
+			InviteOnly: getBoolEnv("INVITE_ONLY", false),
+			TTL:        getDurationEnv("INVITE_TTL", 7*24*time.Hour),
+		},
+		Demo: DemoConfig{
+			Enabled:       getBoolEnv("DEMO_MODE", false),
+			AdminEmail:    getEnv("DEMO_ADMIN_EMAIL", "admin@demo.example.com"),
+			AdminPassword: getEnv("DEMO_ADMIN_PASSWORD", "demo-admin-password"),
+			UserPassword:  getEnv("DEMO_USER_PASSWORD", "demo-user-password"),
+			Users:         getIntEnv("DEMO_USERS", 5),
+			ResetInterval: getDurationEnv("DEMO_RESET_INTERVAL", time.Hour),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getListEnv("CORS_ALLOWED_ORIGINS", ",", []string{"*"}),
+		},
+		Hygiene: SecretsHygieneConfig{
+			Environment:  getEnv("APP_ENVIRONMENT", "development"),
+			Acknowledged: getListEnv("SECRETS_HYGIENE_ACKNOWLEDGE", ",", nil),
+		},
+		Snapshot: SnapshotConfig{
+			Enabled: getBoolEnv("SNAPSHOT_ENABLED", false),
+			Storage: getEnv("SNAPSHOT_STORAGE", SnapshotStorageFile),
+			Dir:     getEnv("SNAPSHOT_DIR", "./snapshots"),
+			S3: SnapshotS3Config{
+				Bucket:          getEnv("SNAPSHOT_S3_BUCKET", ""),
+				Prefix:          getEnv("SNAPSHOT_S3_PREFIX", "snapshots/"),
+				Region:          getEnv("SNAPSHOT_S3_REGION", "us-east-1"),
+				Endpoint:        getEnv("SNAPSHOT_S3_ENDPOINT", ""),
+				AccessKeyID:     getEnv("SNAPSHOT_S3_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+				SecretAccessKey: getEnv("SNAPSHOT_S3_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			},
+			Source:          getEnv("APP_ENVIRONMENT", "development"),
+			ConfirmationKey: getEnv("SNAPSHOT_CONFIRMATION_KEY", getEnv("JWT_SECRET", DefaultJWTSecret)),
+			ConfirmTTL:      getDurationEnv("SNAPSHOT_CONFIRM_TTL", 5*time.Minute),
+		},
+		Telemetry: TelemetryConfig{
+			Enabled:  getBoolEnv("TELEMETRY_ENABLED", false),
+			Endpoint: getEnv("TELEMETRY_ENDPOINT", ""),
+			Interval: getDurationEnv("TELEMETRY_INTERVAL", 24*time.Hour),
+			Timeout:  getDurationEnv("TELEMETRY_TIMEOUT", 10*time.Second),
+		},
+		LoginThrottle: LoginThrottleConfig{
+			Enabled:         getBoolEnv("LOGIN_THROTTLE_ENABLED", false),
+			MaxFailures:     getIntEnv("LOGIN_THROTTLE_MAX_FAILURES", 5),
+			Window:          getDurationEnv("LOGIN_THROTTLE_WINDOW", 15*time.Minute),
+			IPMaxFailures:   getIntEnv("LOGIN_THROTTLE_IP_MAX_FAILURES", 20),
+			IPWindow:        getDurationEnv("LOGIN_THROTTLE_IP_WINDOW", 15*time.Minute),
+			StatusMaxChecks: getIntEnv("LOGIN_LOCKOUT_STATUS_MAX_CHECKS", 5),
+			StatusWindow:    getDurationEnv("LOGIN_LOCKOUT_STATUS_WINDOW", 15*time.Minute),
+		},
+		Retention: RetentionConfig{
+			Enabled:      getBoolEnv("RETENTION_ENABLED", false),
+			Interval:     getDurationEnv("RETENTION_PURGE_INTERVAL", 24*time.Hour),
+			BatchSize:    getIntEnv("RETENTION_BATCH_SIZE", 500),
+			AuditEvents:  getDurationEnv("RETENTION_AUDIT_EVENTS", 0),
+			DeletedUsers: getDurationEnv("RETENTION_DELETED_USERS", 30*24*time.Hour),
+		},
+		Security: SecurityEventsConfig{
+			BufferSize:       getIntEnv("SECURITY_EVENTS_BUFFER_SIZE", 1000),
+			FailureThreshold: getIntEnv("SECURITY_FAILED_LOGIN_THRESHOLD", 5),
+			FailureWindow:    getDurationEnv("SECURITY_FAILED_LOGIN_WINDOW", 15*time.Minute),
+		},
+		SIEM: SIEMConfig{
+			Enabled:       getBoolEnv("SIEM_ENABLED", false),
+			Transport:     getEnv("SIEM_TRANSPORT", SIEMTransportSyslog),
+			SyslogNetwork: getEnv("SIEM_SYSLOG_NETWORK", "udp"),
+			SyslogAddress: getEnv("SIEM_SYSLOG_ADDRESS", "localhost:514"),
+			HTTPURL:       getEnv("SIEM_HTTP_URL", ""),
+			HTTPToken:     getEnv("SIEM_HTTP_TOKEN", ""),
+			SourceType:    getEnv("SIEM_SOURCETYPE", "demo-go:security"),
+			BufferSize:    getIntEnv("SIEM_BUFFER_SIZE", 1000),
+			BatchSize:     getIntEnv("SIEM_BATCH_SIZE", 50),
+			FlushInterval: getDurationEnv("SIEM_FLUSH_INTERVAL", 2*time.Second),
+			MaxRetries:    getIntEnv("SIEM_MAX_RETRIES", 3),
+			RetryBackoff:  getDurationEnv("SIEM_RETRY_BACKOFF", 500*time.Millisecond),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getBoolEnv("RATE_LIMIT_ENABLED", false),
+			Window:  getDurationEnv("RATE_LIMIT_WINDOW", time.Minute),
+			Tiers: map[string]int{
+				RateLimitTierAnonymous: getIntEnv("RATE_LIMIT_ANONYMOUS", 60),
+				RateLimitTierUser:      getIntEnv("RATE_LIMIT_USER", 300),
+				RateLimitTierAdmin:     getIntEnv("RATE_LIMIT_ADMIN", 1000),
+				RateLimitTierService:   getIntEnv("RATE_LIMIT_SERVICE", 5000),
+			},
+			Store: getEnv("RATE_LIMIT_STORE", RateLimitStoreAuto),
+		},
+		Profile: ProfileConfig{
+			AvatarURLTemplate: getEnv("AVATAR_URL_TEMPLATE", ""),
+			SourceTimeout:     getDurationEnv("PROFILE_SOURCE_TIMEOUT", 200*time.Millisecond),
+		},
+		Roles: RolesConfig{
+			Default:         getEnv("ROLE_DEFAULT", "user"),
+			SelfAssignable:  getListEnv("ROLE_SELF_ASSIGNABLE", ",", []string{"user"}),
+			NoteRoles:       getListEnv("ROLE_NOTES", ",", []string{"admin", "moderator"}),
+			RecheckInterval: getDurationEnv("ROLE_RECHECK_INTERVAL", 0),
+			Scopes: getRoleGrantsEnv("ROLE_SCOPES", map[string][]string{
+				"admin":     {"users:read", "users:write"},
+				"moderator": {"users:read"},
+			}),
+			Custom: CustomRolesConfig{
+				Enabled:         getBoolEnv("CUSTOM_ROLES_ENABLED", false),
+				RefreshInterval: getDurationEnv("CUSTOM_ROLES_REFRESH_INTERVAL", 30*time.Second),
+			},
+		},
+		Policy: PolicyConfig{
+			Permissions: getRoleGrantsEnv("POLICY_PERMISSIONS", map[string][]string{"admin": {"*"}}),
+		},
+		Pagination: PaginationConfig{
+			MaxLimit: getIntEnv("PAGINATION_MAX_LIMIT", 100),
+		},
+		Dedup: DedupConfig{
+			Enabled: getBoolEnv("REQUEST_DEDUP_ENABLED", false),
 		},
 	}
 }
@@ -142,3 +1295,114 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getBoolEnv gets an environment variable as bool or returns a default value
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getMapEnv parses an environment variable of the form "key1:value1,key2:value2"
+func getMapEnv(key string) map[string]string {
+	result := make(map[string]string)
+	value := os.Getenv(key)
+	if value == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || k == "" || v == "" {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// getRoleGrantsEnv parses an environment variable of the form
+// "role1=grant1 grant2;role2=grant3" or returns a default value. Scopes
+// contain colons, so roles are separated by semicolons.
+func getRoleGrantsEnv(key string, defaultValue map[string][]string) map[string][]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string][]string)
+	for _, grant := range strings.Split(value, ";") {
+		role, scopes, found := strings.Cut(strings.TrimSpace(grant), "=")
+		if !found || role == "" {
+			continue
+		}
+		result[strings.TrimSpace(role)] = strings.Fields(scopes)
+	}
+	return result
+}
+
+// getTrustedIssuers reads the tenants listed in JWT_TRUSTED_ISSUERS, each
+// configured by JWT_TENANT_<NAME>_* variables
+func getTrustedIssuers() []TrustedIssuerConfig {
+	var issuers []TrustedIssuerConfig
+	for _, tenant := range getListEnv("JWT_TRUSTED_ISSUERS", ",", nil) {
+		prefix := "JWT_TENANT_" + envName(tenant) + "_"
+		issuers = append(issuers, TrustedIssuerConfig{
+			Tenant:         tenant,
+			Issuer:         getEnv(prefix+"ISSUER", ""),
+			Secret:         getEnv(prefix+"SECRET", ""),
+			Audience:       getEnv(prefix+"AUDIENCE", getEnv("JWT_AUDIENCE", DefaultJWTAudience)),
+			ClaimNamespace: getEnv(prefix+"CLAIM_NAMESPACE", ""),
+			Roles:          getTrustedIssuerRoles(prefix + "ROLES"),
+			Permissions:    getListEnv(prefix+"PERMISSIONS", ",", nil),
+		})
+	}
+	return issuers
+}
+
+// getTrustedIssuerRoles reads a trusted issuer's role mapping, by default
+// accepting its "user" role as this deployment's
+func getTrustedIssuerRoles(key string) map[string]string {
+	roles := getMapEnv(key)
+	if len(roles) == 0 {
+		roles["user"] = "user"
+	}
+	return roles
+}
+
+// envName turns a name into the form used in environment variable names,
+// e.g. "eu-west" into "EU_WEST"
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// getListEnv splits an environment variable on sep or returns a default value
+
+func getListEnv(key, sep string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
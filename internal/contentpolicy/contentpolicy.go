@@ -0,0 +1,165 @@
+// Package contentpolicy screens user-supplied display names against
+// configurable blocklists and Unicode lookalike tricks so public-facing
+// handles stay clean.
+package contentpolicy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ViolationCode is the domain error code returned for rejected content
+const ViolationCode = "CONTENT_POLICY_VIOLATION"
+
+// confusables folds common Cyrillic, Greek and leetspeak lookalikes onto the
+// Latin letters they imitate
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ї': 'i', 'ј': 'j',
+	'ѕ': 's', 'ԁ': 'd', 'һ': 'h', 'ӏ': 'l', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Leetspeak
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's', '!': 'i',
+}
+
+// Chain combines several policies; the first violation wins
+type Chain []domain.ContentPolicy
+
+// Check runs every policy in order
+func (c Chain) Check(field, value string) error {
+	for _, policy := range c {
+		if err := policy.Check(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blocklistPolicy rejects names containing blocklisted terms or Unicode spoofing
+type blocklistPolicy struct {
+	terms             []string // folded
+	blockMixedScripts bool
+}
+
+// New creates the built-in blocklist policy from configuration, loading the
+// optional blocklist file
+func New(cfg config.ContentPolicyConfig) (domain.ContentPolicy, error) {
+	terms := append([]string(nil), cfg.Blocklist...)
+
+	if cfg.BlocklistFile != "" {
+		fileTerms, err := loadBlocklist(cfg.BlocklistFile)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, fileTerms...)
+	}
+
+	policy := &blocklistPolicy{blockMixedScripts: cfg.BlockMixedScripts}
+	for _, term := range terms {
+		if folded := fold(term); folded != "" {
+			policy.terms = append(policy.terms, folded)
+		}
+	}
+	return policy, nil
+}
+
+// Check screens a value. Terms match anywhere in the folded value, so spacing,
+// punctuation, accents and lookalike characters do not evade the blocklist.
+func (p *blocklistPolicy) Check(field, value string) error {
+	normalized := norm.NFKC.String(value)
+
+	for _, r := range normalized {
+		// Zero-width and bidi control characters are used to disguise names
+		if unicode.Is(unicode.Cf, r) || unicode.IsControl(r) {
+			return violation(field, "contains invisible or control characters")
+		}
+	}
+
+	if p.blockMixedScripts {
+		for _, word := range strings.Fields(normalized) {
+			if mixesScripts(word) {
+				return violation(field, "mixes lookalike characters from different alphabets")
+			}
+		}
+	}
+
+	folded := fold(normalized)
+	for _, term := range p.terms {
+		if strings.Contains(folded, term) {
+			return violation(field, "contains disallowed content")
+		}
+	}
+
+	return nil
+}
+
+// fold lowercases, strips accents, maps lookalikes to Latin and drops
+// everything that is not a letter
+func fold(value string) string {
+	decomposed := norm.NFKD.String(strings.ToLower(value))
+
+	var b strings.Builder
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if mapped, ok := confusables[r]; ok {
+			r = mapped
+		}
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// mixesScripts reports whether a word combines Latin letters with Cyrillic or Greek ones
+func mixesScripts(word string) bool {
+	var latin, other bool
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		case unicode.Is(unicode.Cyrillic, r), unicode.Is(unicode.Greek, r):
+			other = true
+		}
+	}
+	return latin && other
+}
+
+func loadBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec G304 -- path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open content policy blocklist: %w", err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	var terms []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read content policy blocklist: %w", err)
+	}
+	return terms, nil
+}
+
+func violation(field, reason string) error {
+	return &domain.Error{Code: ViolationCode, Message: field + " " + reason}
+}
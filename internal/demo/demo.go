@@ -0,0 +1,509 @@
+// Package demo runs the server as an instantly usable showcase. A Seeder
+// replaces the users with fixtures, a known admin and sample users, and the
+// audit log with sample events, at startup and on a schedule. Since anyone
+// can sign in as the admin, what the admin can configure (roles, route
+// overrides, clients, API keys, invites and the announcement) is put back
+// too, so visitors always find the same state however they changed it.
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// AdminID is the ID of the demo admin, which stays the same across resets
+// so its tokens keep working
+const AdminID = "demo-admin"
+
+// resetTimeout bounds one scheduled reset
+const resetTimeout = time.Minute
+
+// resetBatchSize bounds the users deleted at once on reset
+const resetBatchSize = 500
+
+// sampleNames name the sample users; users beyond them are numbered
+var sampleNames = []string{
+	"Ada Lovelace", "Grace Hopper", "Alan Turing", "Katherine Johnson",
+	"Edsger Dijkstra", "Barbara Liskov", "Donald Knuth", "Margaret Hamilton",
+}
+
+// Seeder resets the demo state
+type Seeder struct {
+	cfg         config.DemoConfig
+	users       domain.UserRepository
+	audit       domain.AuditRepository
+	hasher      domain.PasswordHasher
+	userCache   cache.Service
+	defaultRole string
+	logger      *logger.Logger
+	now         func() time.Time
+
+	// Admin-managed state put back on reset; nil when the feature is off
+	roles         domain.RoleService
+	overrides     domain.RouteOverrideService
+	clients       domain.ClientService
+	apiKeys       domain.APIKeyService
+	invites       domain.InviteService
+	announcements domain.AnnouncementService
+
+	mu        sync.Mutex
+	started   bool
+	adminHash string
+	userHash  string
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Option configures a Seeder
+type Option func(*Seeder)
+
+// WithRoles deletes custom roles and clears the stored permissions of the
+// built-in ones on reset
+func WithRoles(roles domain.RoleService) Option {
+	return func(s *Seeder) { s.roles = roles }
+}
+
+// WithRouteOverrides deletes every route override on reset
+func WithRouteOverrides(overrides domain.RouteOverrideService) Option {
+	return func(s *Seeder) { s.overrides = overrides }
+}
+
+// WithClients deletes every machine client on reset
+func WithClients(clients domain.ClientService) Option {
+	return func(s *Seeder) { s.clients = clients }
+}
+
+// WithAPIKeys revokes the API keys of the fixtures on reset; the keys of
+// deleted users stop working with them
+func WithAPIKeys(apiKeys domain.APIKeyService) Option {
+	return func(s *Seeder) { s.apiKeys = apiKeys }
+}
+
+// WithInvites deletes every invite on reset
+func WithInvites(invites domain.InviteService) Option {
+	return func(s *Seeder) { s.invites = invites }
+}
+
+// WithAnnouncements clears the announcement on reset
+func WithAnnouncements(announcements domain.AnnouncementService) Option {
+	return func(s *Seeder) { s.announcements = announcements }
+}
+
+// New creates a seeder. Sample users get defaultRole. userCache, when not
+// nil, is cleared of users after each reset. Nothing is reset until Reset
+// or Start is called.
+func New(
+	cfg config.DemoConfig,
+	users domain.UserRepository,
+	audit domain.AuditRepository,
+	hasher domain.PasswordHasher,
+	userCache cache.Service,
+	defaultRole string,
+	opts ...Option,
+) *Seeder {
+	if cfg.Users < 0 {
+		cfg.Users = 0
+	}
+
+	s := &Seeder{
+		cfg:         cfg,
+		users:       users,
+		audit:       audit,
+		hasher:      hasher,
+		userCache:   userCache,
+		defaultRole: defaultRole,
+		logger:      logger.GetGlobal().ForComponent("demo"),
+		now:         time.Now,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Fixtures returns the demo users: the admin first, then the sample users
+// with sample-user-<n> IDs. Passwords are left empty.
+func (s *Seeder) Fixtures() []*domain.User {
+	now := s.now().UTC()
+	fixtures := []*domain.User{{
+		ID:        AdminID,
+		Name:      "Demo Admin",
+		Email:     strings.ToLower(s.cfg.AdminEmail),
+		Role:      "admin",
+		Status:    domain.UserStatusActive,
+		CreatedAt: now.AddDate(0, 0, -30),
+	}}
+	for i := 1; i <= s.cfg.Users; i++ {
+		name := fmt.Sprintf("Sample User %d", i)
+		if i <= len(sampleNames) {
+			name = sampleNames[i-1]
+		}
+		fixtures = append(fixtures, &domain.User{
+			ID:        fmt.Sprintf("sample-user-%d", i),
+			Name:      name,
+			Email:     strings.ReplaceAll(strings.ToLower(name), " ", ".") + "@demo.example.com",
+			Role:      s.defaultRole,
+			Status:    domain.UserStatusActive,
+			CreatedAt: now.AddDate(0, 0, i-30),
+		})
+	}
+	// One account shows what a suspension looks like
+	if len(fixtures) > 2 {
+		suspended := fixtures[len(fixtures)-1]
+		suspended.Status = domain.UserStatusSuspended
+		suspended.StatusReason = "Suspended to demonstrate account statuses"
+		suspended.StatusChangedAt = &now
+	}
+	for _, user := range fixtures {
+		user.UpdatedAt = user.CreatedAt
+	}
+	return fixtures
+}
+
+// Reset deletes every user other than the fixtures, stores the fixtures as
+// they were first seeded, puts back the admin-managed state and replaces the
+// audit log with sample events
+func (s *Seeder) Reset(ctx context.Context) error {
+	adminHash, userHash, err := s.passwordHashes()
+	if err != nil {
+		return err
+	}
+
+	fixtures := s.Fixtures()
+	keep := make(map[string]bool, len(fixtures))
+	for _, user := range fixtures {
+		keep[user.ID] = true
+		user.Password = userHash
+		if user.ID == AdminID {
+			user.Password = adminHash
+		}
+	}
+
+	deleted, err := s.deleteOthers(ctx, keep)
+	if err != nil {
+		return fmt.Errorf("delete users: %w", err)
+	}
+	for _, user := range fixtures {
+		if err := s.store(ctx, user); err != nil {
+			return fmt.Errorf("seed user %s: %w", user.ID, err)
+		}
+	}
+	// Before the audit log is replaced, so the deletions are not left in it
+	if err := s.resetManaged(ctx, fixtures); err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	if _, err := s.audit.DeleteBefore(ctx, now.Add(time.Second)); err != nil {
+		return fmt.Errorf("clear audit log: %w", err)
+	}
+	if err := s.audit.CreateMany(ctx, sampleEvents(fixtures, now)); err != nil {
+		return fmt.Errorf("seed audit log: %w", err)
+	}
+
+	s.invalidateCache(ctx)
+	s.logger.Info("Demo state reset", "users", len(fixtures), "deleted_users", deleted)
+	return nil
+}
+
+// Start begins resetting every ResetInterval in the background; a zero
+// interval never resets again
+func (s *Seeder) Start() {
+	if s.cfg.ResetInterval <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	s.logger.Info("Demo resets scheduled", "interval", s.cfg.ResetInterval)
+	go s.run()
+}
+
+// Close stops scheduled resets and waits for an in-flight reset, or for ctx to expire
+func (s *Seeder) Close(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+
+	s.closeOnce.Do(func() { close(s.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Seeder) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.ResetInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.scheduledReset()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// scheduledReset runs one scheduled reset; failures are logged and retried at the next interval
+func (s *Seeder) scheduledReset() {
+	ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := s.Reset(ctx); err != nil {
+		s.logger.Warn("Demo reset failed", "error", err)
+	}
+}
+
+// passwordHashes hashes the demo passwords once; hashing is slow by design
+func (s *Seeder) passwordHashes() (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.adminHash != "" {
+		return s.adminHash, s.userHash, nil
+	}
+
+	adminHash, err := s.hasher.Hash(s.cfg.AdminPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("hash demo admin password: %w", err)
+	}
+	userHash, err := s.hasher.Hash(s.cfg.UserPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("hash demo user password: %w", err)
+	}
+	s.adminHash, s.userHash = adminHash, userHash
+	return adminHash, userHash, nil
+}
+
+// deleteOthers deletes the users not kept, in batches
+func (s *Seeder) deleteOthers(ctx context.Context, keep map[string]bool) (int64, error) {
+	query := &domain.UserQuery{
+		Sort:      []domain.SortField{{Field: "created_at"}, {Field: "email"}},
+		Limit:     resetBatchSize,
+		Fields:    []string{"id"},
+		SkipTotal: true,
+	}
+
+	var others []string
+	for {
+		page, _, err := s.users.Search(ctx, query)
+		if err != nil {
+			return 0, err
+		}
+		for _, user := range page {
+			if !keep[user.ID] {
+				others = append(others, user.ID)
+			}
+		}
+		if len(page) < query.Limit {
+			break
+		}
+		query.Offset += len(page)
+	}
+
+	var deleted int64
+	for start := 0; start < len(others); start += resetBatchSize {
+		end := start + resetBatchSize
+		if end > len(others) {
+			end = len(others)
+		}
+		n, err := s.users.DeleteMany(ctx, others[start:end])
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// resetManaged puts back what admins can configure, through the services so
+// their cached state follows
+func (s *Seeder) resetManaged(ctx context.Context, fixtures []*domain.User) error {
+	if s.roles != nil {
+		if err := s.resetRoles(ctx); err != nil {
+			return fmt.Errorf("reset roles: %w", err)
+		}
+	}
+	if s.overrides != nil {
+		overrides, err := s.overrides.ListOverrides(ctx)
+		if err != nil {
+			return fmt.Errorf("reset route overrides: %w", err)
+		}
+		for _, override := range overrides {
+			if err := s.overrides.DeleteOverride(ctx, AdminID, override.ID); err != nil {
+				return fmt.Errorf("reset route overrides: %w", err)
+			}
+		}
+	}
+	if s.clients != nil {
+		clients, err := s.clients.ListClients(ctx)
+		if err != nil {
+			return fmt.Errorf("reset clients: %w", err)
+		}
+		for _, client := range clients {
+			if err := s.clients.DeleteClient(ctx, AdminID, client.ID); err != nil {
+				return fmt.Errorf("reset clients: %w", err)
+			}
+		}
+	}
+	if s.apiKeys != nil {
+		for _, user := range fixtures {
+			keys, err := s.apiKeys.ListKeys(ctx, user.ID)
+			if err != nil {
+				return fmt.Errorf("reset API keys: %w", err)
+			}
+			for _, key := range keys {
+				if err := s.apiKeys.RevokeKey(ctx, AdminID, user.ID, key.ID); err != nil {
+					return fmt.Errorf("reset API keys: %w", err)
+				}
+			}
+		}
+	}
+	if s.invites != nil {
+		if err := s.resetInvites(ctx); err != nil {
+			return fmt.Errorf("reset invites: %w", err)
+		}
+	}
+	if s.announcements != nil {
+		if err := s.announcements.Clear(ctx, AdminID); err != nil && !errors.Is(err, domain.ErrAnnouncementNotFound) {
+			return fmt.Errorf("reset announcement: %w", err)
+		}
+	}
+	return nil
+}
+
+// resetRoles deletes the custom roles, which no fixture holds, and clears
+// what was stored for the built-in ones, leaving their configured permissions
+func (s *Seeder) resetRoles(ctx context.Context) error {
+	roles, err := s.roles.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if !role.BuiltIn {
+			if err := s.roles.DeleteRole(ctx, AdminID, role.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(role.Permissions) == 0 && role.Description == "" {
+			continue
+		}
+		description, permissions := "", []string{}
+		// Clearing grants nothing, so no actor role is needed
+		if _, err := s.roles.UpdateRole(ctx, AdminID, "", role.Name, &domain.UpdateRoleRequest{Description: &description, Permissions: &permissions}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetInvites deletes every invite, a page at a time
+func (s *Seeder) resetInvites(ctx context.Context) error {
+	for {
+		invites, _, err := s.invites.ListInvites(ctx, resetBatchSize, 0)
+		if err != nil {
+			return err
+		}
+		for _, invite := range invites {
+			if err := s.invites.RevokeInvite(ctx, AdminID, invite.ID); err != nil {
+				return err
+			}
+		}
+		if len(invites) < resetBatchSize {
+			return nil
+		}
+	}
+}
+
+// store creates the fixture or puts it back as seeded
+func (s *Seeder) store(ctx context.Context, user *domain.User) error {
+	_, err := s.users.GetByID(ctx, user.ID)
+	switch err {
+	case nil:
+		return s.users.Update(ctx, user.ID, user)
+	case domain.ErrUserNotFound:
+		return s.users.Create(ctx, user)
+	}
+	return err
+}
+
+func (s *Seeder) invalidateCache(ctx context.Context) {
+	if s.userCache == nil {
+		return
+	}
+	for _, pattern := range []string{"user:*", "users:list:*", "stats:*"} {
+		if err := s.userCache.DeleteByPattern(ctx, pattern); err != nil {
+			s.logger.Warn("Failed to invalidate cache after demo reset", "pattern", pattern, "error", err)
+		}
+	}
+}
+
+// sampleEvents is a short admin history over the fixtures, spread over the
+// last days before now
+func sampleEvents(fixtures []*domain.User, now time.Time) []*domain.AuditEvent {
+	var events []*domain.AuditEvent
+	at := func(hoursAgo int) time.Time { return now.Add(-time.Duration(hoursAgo) * time.Hour) }
+
+	for i, user := range fixtures[1:] {
+		events = append(events, &domain.AuditEvent{
+			Action:    domain.AuditActionUserImported,
+			ActorID:   AdminID,
+			TargetID:  user.ID,
+			Details:   map[string]interface{}{"email": user.Email, "source": "demo fixtures"},
+			CreatedAt: at(72 - i),
+		})
+	}
+	if len(fixtures) > 1 {
+		events = append(events, &domain.AuditEvent{
+			Action:    domain.AuditActionUserUpdated,
+			ActorID:   fixtures[1].ID,
+			TargetID:  fixtures[1].ID,
+			Details:   map[string]interface{}{"changes": []domain.FieldChange{{Field: "name", From: "Ada", To: fixtures[1].Name}}},
+			CreatedAt: at(30),
+		})
+	}
+	if last := fixtures[len(fixtures)-1]; last.Status == domain.UserStatusSuspended {
+		events = append(events, &domain.AuditEvent{
+			Action:    domain.AuditActionUserSuspended,
+			ActorID:   AdminID,
+			TargetID:  last.ID,
+			Details:   map[string]interface{}{"from": domain.UserStatusActive, "reason": last.StatusReason},
+			CreatedAt: at(2),
+		})
+	}
+	return events
+}
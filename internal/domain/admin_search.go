@@ -0,0 +1,44 @@
+package domain
+
+import "context"
+
+// Sources searched by the admin search
+const (
+	AdminSearchSourceUsers    = "users"
+	AdminSearchSourceAudit    = "audit_events"
+	AdminSearchSourceSessions = "sessions"
+)
+
+// Bounds of admin search queries, in characters
+const (
+	MinAdminSearchQuery = 3
+	MaxAdminSearchQuery = 200
+)
+
+// AdminSearchResults groups what an admin search found in each source.
+// Errors maps the sources that failed or timed out to the reason; their
+// results are empty.
+type AdminSearchResults struct {
+	Query       string            `json:"query"`
+	Users       []*UserResponse   `json:"users"`
+	AuditEvents []*AuditEvent     `json:"audit_events"`
+	Sessions    []*Session        `json:"sessions"`
+	Errors      map[string]string `json:"errors,omitempty"`
+}
+
+// AdminSearchService searches users, audit events and sessions at once, so
+// support staff can investigate an email, user ID or IP address in one call.
+// Users match by name or email, audit events by actor, target or email, and
+// sessions by user ID or IP address. Audit events and sessions also match
+// the users found, so searching an email finds the user's events and sessions.
+type AdminSearchService interface {
+	// Search returns up to limit results per source
+	Search(ctx context.Context, query string, limit int) (*AdminSearchResults, error)
+}
+
+// ErrAdminSearchDenied keeps principals who may read users but not the
+// audit log from searching both
+var ErrAdminSearchDenied = &Error{Code: "FORBIDDEN", Message: "The admin search requires the audit.read permission"}
+
+// ErrInvalidSearchQuery indicates an admin search query that is too short or too long
+var ErrInvalidSearchQuery = &Error{Code: "INVALID_SEARCH_QUERY", Message: "Search queries must be 3 to 200 characters"}
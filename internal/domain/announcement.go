@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Severities of an announcement, which clients style the banner by
+const (
+	AnnouncementSeverityInfo     = "info"
+	AnnouncementSeverityWarning  = "warning"
+	AnnouncementSeverityCritical = "critical"
+)
+
+// Audit actions of announcement management
+const (
+	AuditActionAnnouncementUpdated = "announcement.updated"
+	AuditActionAnnouncementCleared = "announcement.cleared"
+)
+
+// Announcement is the banner shown to every client, such as a maintenance
+// notice. There is at most one; setting it again replaces it. Announcements
+// without ExpiresAt are shown until they are cleared.
+type Announcement struct {
+	Message   string     `json:"message" bson:"message"`
+	Severity  string     `json:"severity" bson:"severity"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty" bson:"updated_by"`
+	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+// Expired reports whether the announcement has expired at the given time
+func (a *Announcement) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+// Public returns a copy of the announcement for clients, without the admin
+// who set it
+func (a *Announcement) Public() *Announcement {
+	public := *a
+	public.UpdatedBy = ""
+	return &public
+}
+
+// SetAnnouncementRequest creates or replaces the announcement
+type SetAnnouncementRequest struct {
+	Message   string     `json:"message"`
+	Severity  string     `json:"severity"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AnnouncementStore persists the announcement
+type AnnouncementStore interface {
+	// Get returns the announcement, or ErrAnnouncementNotFound when none is set
+	Get(ctx context.Context) (*Announcement, error)
+	Set(ctx context.Context, announcement *Announcement) error
+	// Delete returns ErrAnnouncementNotFound when none is set
+	Delete(ctx context.Context) error
+}
+
+// AnnouncementService manages the announcement and serves it to clients
+// from a snapshot, so reading it never waits on storage
+type AnnouncementService interface {
+	// Current returns the announcement in effect, if any
+	Current() (*Announcement, bool)
+	// Get returns the stored announcement, expired or not
+	Get(ctx context.Context) (*Announcement, error)
+	Set(ctx context.Context, actorID string, req *SetAnnouncementRequest) (*Announcement, error)
+	Clear(ctx context.Context, actorID string) error
+	// Reload rereads the announcement from the store
+	Reload(ctx context.Context) error
+}
+
+// ErrAnnouncementNotFound indicates that no announcement is set
+var ErrAnnouncementNotFound = &Error{Code: "ANNOUNCEMENT_NOT_FOUND", Message: "No announcement is set"}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// API key scopes. A key may read with read or write, change data with write,
+// and reach role-restricted routes with its owner's role only with admin;
+// without admin a key acts with the default role.
+const (
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+	APIKeyScopeAdmin = "admin"
+)
+
+// APIKeyScopes are the scopes a key can be given
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeWrite, APIKeyScopeAdmin}
+
+// Audit actions of API key management
+const (
+	AuditActionAPIKeyCreated = "api_key.created"
+	AuditActionAPIKeyRevoked = "api_key.revoked"
+)
+
+// APIKey is a long-lived credential for scripts and integrations, sent in the
+// X-API-Key header. Only the SHA-256 hash of the key is stored; Prefix, the
+// start of the key, lets its owner tell keys apart.
+type APIKey struct {
+	ID         string     `json:"id" bson:"_id"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	Name       string     `json:"name" bson:"name"`
+	Prefix     string     `json:"prefix" bson:"prefix"`
+	Hash       string     `json:"-" bson:"hash"`
+	Scopes     []string   `json:"scopes" bson:"scopes"`
+	CreatedBy  string     `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+}
+
+// HasScope reports whether the key was given the scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether the key has expired at the given time
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// CreateAPIKeyRequest names a new key and what it may do. Keys without
+// ExpiresAt do not expire.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey is a new key together with its secret, which is shown only
+// in the response that creates it
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyRepository defines the interface for API key persistence
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	// GetByHash returns ErrAPIKeyNotFound when no key has the hash
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+	// ListByUser returns the user's keys, newest first
+	ListByUser(ctx context.Context, userID string) ([]*APIKey, error)
+	// Delete removes a key of the user; keys of other users are not found
+	Delete(ctx context.Context, userID, id string) error
+	// Touch records when the key was last used
+	Touch(ctx context.Context, id string, at time.Time) error
+}
+
+// APIKeyService defines the interface for API key business logic. The actor
+// is the user creating or revoking a key, the key's owner or an admin.
+type APIKeyService interface {
+	CreateKey(ctx context.Context, actorID, userID string, req *CreateAPIKeyRequest) (*CreatedAPIKey, error)
+	ListKeys(ctx context.Context, userID string) ([]*APIKey, error)
+	RevokeKey(ctx context.Context, actorID, userID, keyID string) error
+	// Authenticate returns the key and its owner. Unknown and expired keys,
+	// and keys of suspended or deleted users, return ErrInvalidAPIKey.
+	Authenticate(ctx context.Context, rawKey string) (*APIKey, *User, error)
+}
+
+// API key errors
+var (
+	ErrAPIKeyNotFound = &Error{Code: "API_KEY_NOT_FOUND", Message: "API key not found"}
+	ErrInvalidAPIKey  = &Error{Code: "UNAUTHORIZED", Message: "Invalid or expired API key"}
+)
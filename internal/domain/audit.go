@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Audit actions recorded by the service layer
+const (
+	AuditActionUserDeleted     = "user.deleted"
+	AuditActionUserSuspended   = "user.suspended"
+	AuditActionUserUnsuspended = "user.unsuspended"
+	AuditActionUserBanned      = "user.banned"
+	AuditActionUserRoleChanged = "user.role_changed"
+	AuditActionUserExported    = "user.exported"
+	AuditActionUserImported    = "user.imported"
+)
+
+// AuditEvent records an administrative or security-relevant action
+type AuditEvent struct {
+	ID        string                 `json:"id" bson:"_id,omitempty"`
+	Action    string                 `json:"action" bson:"action"`
+	ActorID   string                 `json:"actor_id" bson:"actor_id"`
+	TargetID  string                 `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// AuditFilter narrows audit event listings; empty fields match everything
+type AuditFilter struct {
+	ActorID  string
+	TargetID string
+	Action   string
+	// CreatedBefore matches only events recorded before this time
+	CreatedBefore time.Time
+	// Subjects matches events whose actor or target is one of Subjects, or
+	// whose email detail is, for searches that do not know which one it is
+	Subjects []string
+}
+
+// AuditRepository defines the interface for audit event persistence
+type AuditRepository interface {
+	Create(ctx context.Context, event *AuditEvent) error
+	CreateMany(ctx context.Context, events []*AuditEvent) error
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditEvent, error)
+	Count(ctx context.Context, filter AuditFilter) (int64, error)
+	// DeleteBefore removes events recorded before cutoff, for retention purges
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// AuditService defines the interface for recording and querying audit events
+type AuditService interface {
+	Record(ctx context.Context, events ...*AuditEvent) error
+	ListEvents(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditEvent, int64, error)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// AuditActionUserUpdated is recorded with the field changes of every user update
+const AuditActionUserUpdated = "user.updated"
+
+// FieldChange is one changed field of an update. Sensitive values are masked.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// DiffUsers returns the fields that differ between two versions of a user,
+// with email addresses masked
+func DiffUsers(before, after *User) []FieldChange {
+	var changes []FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("name", before.Name, after.Name)
+	if before.Email != after.Email {
+		changes = append(changes, FieldChange{Field: "email", From: MaskEmail(before.Email), To: MaskEmail(after.Email)})
+	}
+	add("role", before.Role, after.Role)
+	add("status", before.Status, after.Status)
+	return changes
+}
+
+// MaskEmail keeps the first and last character of the local part and the
+// domain, e.g. "j**e@example.com", so changes can be told apart without
+// exposing addresses
+func MaskEmail(email string) string {
+	local, host, ok := strings.Cut(email, "@")
+	runes := []rune(local)
+	if !ok || len(runes) == 0 {
+		return "***"
+	}
+	if len(runes) <= 2 {
+		return string(runes[:1]) + "*@" + host
+	}
+	return string(runes[:1]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1:]) + "@" + host
+}
+
+// ChangeSet collects the field changes made while serving one request, so the
+// handler can report them alongside the updated resource
+type ChangeSet struct {
+	mu      sync.Mutex
+	changes []FieldChange
+}
+
+type changeSetKey struct{}
+
+// WithChangeSet returns a context that collects changes into the returned set
+func WithChangeSet(ctx context.Context) (context.Context, *ChangeSet) {
+	changes := &ChangeSet{}
+	return context.WithValue(ctx, changeSetKey{}, changes), changes
+}
+
+// ChangeSetFromContext returns the request's change set, or nil when changes are not collected
+func ChangeSetFromContext(ctx context.Context) *ChangeSet {
+	changes, _ := ctx.Value(changeSetKey{}).(*ChangeSet)
+	return changes
+}
+
+// RecordChanges adds changes to the request's change set, if it has one
+func RecordChanges(ctx context.Context, changes []FieldChange) {
+	set := ChangeSetFromContext(ctx)
+	if set == nil {
+		return
+	}
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.changes = append(set.changes, changes...)
+}
+
+// Changes returns the collected changes; nil when nothing changed
+func (c *ChangeSet) Changes() []FieldChange {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]FieldChange(nil), c.changes...)
+}
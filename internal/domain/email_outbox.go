@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Statuses of an email in the outbox
+const (
+	// EmailStatusQueued emails wait for their next delivery attempt
+	EmailStatusQueued = "queued"
+	// EmailStatusSent emails were accepted by the mail server
+	EmailStatusSent = "sent"
+	// EmailStatusFailed emails ran out of delivery attempts
+	EmailStatusFailed = "failed"
+	// EmailStatusBounced emails were refused for good, by the mail server or
+	// in a bounce reported later
+	EmailStatusBounced = "bounced"
+)
+
+// Audit actions of the email outbox
+const (
+	AuditActionEmailResent  = "email.resent"
+	AuditActionEmailBounced = "email.bounced"
+)
+
+// OutboxEmail is an email recorded before it is sent, with its delivery
+// state. The body is only kept until the email is sent, as it may carry
+// links with tokens, and it is never returned by the API.
+type OutboxEmail struct {
+	ID       string `json:"id" bson:"_id"`
+	Kind     string `json:"kind" bson:"kind"`
+	To       string `json:"to" bson:"to"`
+	Subject  string `json:"subject" bson:"subject"`
+	Body     string `json:"-" bson:"body,omitempty"`
+	Status   string `json:"status" bson:"status"`
+	Attempts int    `json:"attempts" bson:"attempts"`
+	// LastError is why the last attempt failed or the email bounced
+	LastError string `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	// NextAttemptAt is when a queued email is next tried
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" bson:"updated_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+}
+
+// OutboxEmailFilter selects emails of the outbox; empty fields match all
+type OutboxEmailFilter struct {
+	Status string
+	To     string
+	Limit  int
+}
+
+// MarkBouncedRequest reports that a sent email bounced
+type MarkBouncedRequest struct {
+	Reason string `json:"reason"`
+}
+
+// EmailOutboxRepository defines the interface for email outbox persistence
+type EmailOutboxRepository interface {
+	Create(ctx context.Context, email *OutboxEmail) error
+	// GetByID returns ErrEmailNotFound for unknown emails
+	GetByID(ctx context.Context, id string) (*OutboxEmail, error)
+	Update(ctx context.Context, email *OutboxEmail) error
+	// List returns the matching emails, newest first
+	List(ctx context.Context, filter OutboxEmailFilter) ([]*OutboxEmail, error)
+	// ClaimDue returns a queued email due at now and moves its next attempt
+	// to now + lease, so no other instance claims it meanwhile; nil when no
+	// email is due
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*OutboxEmail, error)
+	// CountByStatus counts the emails in each status
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// Email outbox errors
+var (
+	ErrEmailNotFound      = &Error{Code: "EMAIL_NOT_FOUND", Message: "Email not found"}
+	ErrEmailNotResendable = &Error{Code: "EMAIL_NOT_RESENDABLE", Message: "Only failed or bounced emails can be resent"}
+	ErrEmailNotSent       = &Error{Code: "EMAIL_NOT_SENT", Message: "Only sent emails can bounce"}
+)
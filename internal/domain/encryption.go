@@ -0,0 +1,5 @@
+package domain
+
+// ErrDecryptionFailed indicates that a stored encrypted value could not be
+// decrypted: it was tampered with, or its key is no longer in the key set
+var ErrDecryptionFailed = &Error{Code: "DECRYPTION_FAILED", Message: "Stored data could not be decrypted"}
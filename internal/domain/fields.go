@@ -0,0 +1,52 @@
+package domain
+
+import "strings"
+
+// UserFieldNames lists the user fields clients may select with the fields= query parameter
+var UserFieldNames = []string{
+	"id", "name", "email", "role", "status", "created_at", "updated_at",
+	"avatar_url", "preferences", "presence",
+}
+
+// PrivateUserFields are the user fields shown on other users' responses only
+// to viewers whose role holds the given permission; the other fields are
+// public. Users always see all of their own fields.
+var PrivateUserFields = map[string]string{
+	"email":       PermissionUserReadPrivate,
+	"role":        PermissionUserReadPrivate,
+	"status":      PermissionUserReadPrivate,
+	"preferences": PermissionUserReadPrivate,
+}
+
+// ErrFieldNotVisible is returned when a list query filters or sorts on a
+// field the viewer may not see
+var ErrFieldNotVisible = &Error{Code: "FIELD_NOT_VISIBLE", Message: "Filtering or sorting on this field is not allowed"}
+
+// ParseUserFields parses a comma-separated sparse fieldset such as "id,name,email".
+// An empty value selects all fields and returns nil.
+func ParseUserFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	allowed := make(map[string]bool, len(UserFieldNames))
+	for _, name := range UserFieldNames {
+		allowed[name] = true
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !allowed[field] {
+			return nil, &Error{Code: "VALIDATION_FAILED", Message: "Unknown field: " + field}
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
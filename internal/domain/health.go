@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+// Component health states reported by /health
+const (
+	HealthStatusHealthy  = "healthy"
+	HealthStatusDegraded = "degraded"
+)
+
+// ComponentHealth is the health of one dependency
+type ComponentHealth struct {
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthChecker reports the health of a dependency. It should answer from
+// state it already tracks and return quickly, since /health is polled often.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) ComponentHealth
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Audit actions of invitations
+const (
+	AuditActionInviteCreated  = "invite.created"
+	AuditActionInviteRevoked  = "invite.revoked"
+	AuditActionInviteAccepted = "invite.accepted"
+	// AuditActionInviteRoleFailed records an invited user who registered
+	// without the invite's role
+	AuditActionInviteRoleFailed = "invite.role_failed"
+)
+
+// Invite lets one email address register, with Role when set. Only the
+// SHA-256 hash of the invite code is stored; the code itself is returned
+// once, to the admin creating the invite.
+type Invite struct {
+	ID         string     `json:"id" bson:"_id"`
+	Email      string     `json:"email" bson:"email"`
+	Role       string     `json:"role,omitempty" bson:"role,omitempty"`
+	Hash       string     `json:"-" bson:"hash"`
+	CreatedBy  string     `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	AcceptedBy string     `json:"accepted_by,omitempty" bson:"accepted_by,omitempty"`
+}
+
+// Usable reports whether the invite can still be accepted at the given time
+func (i *Invite) Usable(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// CreateInviteRequest names the email to invite. Without Role, the invited
+// user registers like anyone else.
+type CreateInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role,omitempty"`
+}
+
+// CreatedInvite is a new invite together with its code, which is shown only
+// in the response that creates it
+type CreatedInvite struct {
+	*Invite
+	Code string `json:"code"`
+}
+
+// InviteRepository defines the interface for invite persistence
+type InviteRepository interface {
+	Create(ctx context.Context, invite *Invite) error
+	// GetByHash returns ErrInviteNotFound when no invite has the hash
+	GetByHash(ctx context.Context, hash string) (*Invite, error)
+	// List returns a page of invites, newest first, and the total count
+	List(ctx context.Context, limit, offset int) ([]*Invite, int64, error)
+	// Accept marks the invite used by the user; it returns ErrInvalidInvite
+	// when the invite was already accepted
+	Accept(ctx context.Context, id, userID string, at time.Time) error
+	// Delete removes an invite; it returns ErrInviteNotFound when none has the ID
+	Delete(ctx context.Context, id string) error
+}
+
+// InviteService defines the interface for invite business logic. The actor
+// is the admin creating or revoking an invite.
+type InviteService interface {
+	CreateInvite(ctx context.Context, actorID string, req *CreateInviteRequest) (*CreatedInvite, error)
+	ListInvites(ctx context.Context, limit, offset int) ([]*Invite, int64, error)
+	// RevokeInvite deletes an invite. Accounts registered with it remain.
+	RevokeInvite(ctx context.Context, actorID, id string) error
+	// CheckInvite returns the invite of the code when it can still be
+	// accepted by the email, and ErrInvalidInvite otherwise
+	CheckInvite(ctx context.Context, code, email string) (*Invite, error)
+	// AcceptInvite records that the user registered with the invite.
+	// roleErr, when not nil, is why the invite's role could not be assigned;
+	// it is recorded with the acceptance.
+	AcceptInvite(ctx context.Context, invite *Invite, userID string, roleErr error) error
+}
+
+// Invite errors
+var (
+	ErrInviteNotFound = &Error{Code: "INVITE_NOT_FOUND", Message: "Invite not found"}
+	ErrInviteRequired = &Error{Code: "INVITE_REQUIRED", Message: "Registration requires an invite code"}
+	ErrInvalidInvite  = &Error{Code: "INVALID_INVITE", Message: "Invite code is invalid, expired, already used or for another email"}
+)
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"crypto"
+)
+
+// Signing algorithms of access token keys, as named in a token's alg header
+const (
+	KeyAlgorithmHS256 = "HS256"
+	KeyAlgorithmRS256 = "RS256"
+	KeyAlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is a key for access tokens, named in a token's kid header. HMAC
+// keys have a Secret. RS256 and EdDSA keys have a PublicKey and, unless they
+// are only kept to verify tokens signed before a rotation, a PrivateKey.
+type SigningKey struct {
+	ID         string
+	Algorithm  string // empty for HMAC keys, which sign with HS256
+	Secret     []byte
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// Alg returns the signing algorithm of the key
+func (k SigningKey) Alg() string {
+	if k.Algorithm == "" {
+		return KeyAlgorithmHS256
+	}
+	return k.Algorithm
+}
+
+// KeySet is the set of keys access tokens are verified with. Current signs
+// new tokens; the others are previous keys kept while their tokens are still
+// in circulation after a rotation, or next keys published ahead of one.
+type KeySet struct {
+	Current SigningKey
+	Keys    map[string]SigningKey // by ID, including Current
+}
+
+// Key returns the key with the given ID
+func (s *KeySet) Key(id string) (SigningKey, bool) {
+	key, ok := s.Keys[id]
+	return key, ok
+}
+
+// KeyProvider supplies the current key set. KeySet returns
+// ErrKeysUnavailable while no usable key set is known.
+type KeyProvider interface {
+	KeySet(ctx context.Context) (*KeySet, error)
+}
+
+// JSONWebKey is the public part of an asymmetric signing key (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// PublicKeyPublisher is implemented by token services whose tokens other
+// services can verify with published public keys
+type PublicKeyPublisher interface {
+	// PublicKeys returns the public keys of the current key set; HMAC keys
+	// are never included
+	PublicKeys(ctx context.Context) ([]JSONWebKey, error)
+}
+
+// ErrKeysUnavailable indicates that tokens can be neither signed nor verified
+// because the signing keys could not be loaded. Clients should retry rather
+// than treat their tokens as invalid.
+var ErrKeysUnavailable = &Error{Code: "KEYS_UNAVAILABLE", Message: "Token signing keys are temporarily unavailable"}
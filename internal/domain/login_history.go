@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Outcomes of a login attempt
+const (
+	LoginOutcomeSuccess = "success"
+	LoginOutcomeFailure = "failure"
+)
+
+// Ways of logging in recorded in the login history
+const (
+	LoginMethodPassword = "password"
+	LoginMethodPasskey  = "passkey"
+	LoginMethodOIDC     = "oidc"
+)
+
+// LoginAttempt is one login of a user, successful or not. Reason is the
+// error code of failed attempts, such as INVALID_CREDENTIALS or LOGIN_LOCKED.
+// Device is read from the user agent; Country is only known behind a CDN or
+// load balancer that reports it.
+type LoginAttempt struct {
+	ID        string    `json:"id" bson:"_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Method    string    `json:"method" bson:"method"`
+	Outcome   string    `json:"outcome" bson:"outcome"`
+	Reason    string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	IPAddress string    `json:"ip_address" bson:"ip_address"`
+	UserAgent string    `json:"user_agent" bson:"user_agent"`
+	Device    string    `json:"device" bson:"device"`
+	Country   string    `json:"country,omitempty" bson:"country,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// LoginHistoryRepository persists login attempts. Attempts older than the
+// configured retention are dropped.
+type LoginHistoryRepository interface {
+	Create(ctx context.Context, attempt *LoginAttempt) error
+	// ListByUser returns a page of the user's attempts, newest first
+	ListByUser(ctx context.Context, userID string, limit, offset int) ([]*LoginAttempt, error)
+	CountByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// LoginHistoryService records login attempts and lists them for their user
+// and for admins
+type LoginHistoryService interface {
+	// Record stores an attempt, filling in its ID, time and the client of
+	// the request context
+	Record(ctx context.Context, attempt *LoginAttempt) error
+	// ListLoginHistory returns a page of the user's attempts, newest first,
+	// and their total
+	ListLoginHistory(ctx context.Context, userID string, limit, offset int) ([]*LoginAttempt, int64, error)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "context"
+
+// ErrLoginLocked indicates that an email has too many recent failed logins
+var ErrLoginLocked = &Error{Code: "LOGIN_LOCKED", Message: "Too many failed login attempts, please try again later"}
+
+// LoginLockoutStatus describes the login throttle state of an email
+type LoginLockoutStatus struct {
+	Locked            bool `json:"locked"`
+	RemainingAttempts int  `json:"remaining_attempts"`
+	// RetryAfterSeconds is how long until logins are accepted again; 0 when not locked
+	RetryAfterSeconds int `json:"retry_after_seconds,omitempty"`
+}
+
+// LoginAttemptError is a failed or refused login together with the throttle
+// state of its email, so clients can tell users how many attempts remain
+type LoginAttemptError struct {
+	Err    error
+	Status LoginLockoutStatus
+}
+
+// Error returns the message of the underlying error
+func (e *LoginAttemptError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *LoginAttemptError) Unwrap() error {
+	return e.Err
+}
+
+// LoginThrottleService reports the login throttle state of an email. Checks
+// are rate limited per client IP and never count as login attempts.
+type LoginThrottleService interface {
+	LockoutStatus(ctx context.Context, email, clientIP string) (*LoginLockoutStatus, error)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MaxNoteLength bounds the length of a user note in characters
+const MaxNoteLength = 2000
+
+// ErrNoteNotFound indicates that a note does not exist for the given user
+var ErrNoteNotFound = &Error{Code: "NOTE_NOT_FOUND", Message: "Note not found"}
+
+// UserNote is an internal note staff attach to a user account. Notes are
+// never shown to the user they are about.
+type UserNote struct {
+	ID        string         `json:"id" bson:"_id"`
+	UserID    string         `json:"user_id" bson:"user_id"`
+	Body      string         `json:"body" bson:"body"`
+	AuthorID  string         `json:"author_id" bson:"author_id"`
+	CreatedAt time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" bson:"updated_at"`
+	EditedBy  string         `json:"edited_by,omitempty" bson:"edited_by,omitempty"`
+	History   []NoteRevision `json:"history,omitempty" bson:"history,omitempty"`
+}
+
+// NoteRevision is an earlier version of a note, kept when the note is edited
+type NoteRevision struct {
+	Body string `json:"body" bson:"body"`
+	// EditedBy and EditedAt identify who wrote this version and when
+	EditedBy string    `json:"edited_by" bson:"edited_by"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
+}
+
+// NoteRequest carries the text of a new or edited note
+type NoteRequest struct {
+	Body string `json:"body"`
+}
+
+// NoteRepository defines the interface for user note persistence
+type NoteRepository interface {
+	Create(ctx context.Context, note *UserNote) error
+	// Get returns ErrNoteNotFound when the note does not exist
+	Get(ctx context.Context, id string) (*UserNote, error)
+	Update(ctx context.Context, note *UserNote) error
+	// ListByUser returns the user's notes, newest first
+	ListByUser(ctx context.Context, userID string) ([]*UserNote, error)
+	// ListByUsers returns notes keyed by user ID; users without any are absent
+	ListByUsers(ctx context.Context, userIDs []string) (map[string][]*UserNote, error)
+	// ReplaceForUser replaces all of the user's notes, used by account imports
+	ReplaceForUser(ctx context.Context, userID string, notes []*UserNote) error
+}
+
+// NoteService defines the interface for user note business logic. The
+// actor is the staff member adding or editing the note.
+type NoteService interface {
+	AddNote(ctx context.Context, actorID, userID string, req *NoteRequest) (*UserNote, error)
+	ListNotes(ctx context.Context, userID string) ([]*UserNote, error)
+	UpdateNote(ctx context.Context, actorID, userID, noteID string, req *NoteRequest) (*UserNote, error)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Audit action of users created by their first single sign-on login
+const AuditActionUserProvisioned = "user.provisioned"
+
+// ExternalIdentity links a user to their account at an OpenID Connect provider
+type ExternalIdentity struct {
+	Issuer   string    `json:"issuer" bson:"issuer"`
+	Subject  string    `json:"subject" bson:"subject"`
+	LinkedAt time.Time `json:"linked_at" bson:"linked_at"`
+}
+
+// OIDCSession is what the server remembers about a single sign-on login
+// between the redirect to the provider and the provider's callback
+type OIDCSession struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// OIDCStateStore keeps login sessions by their state parameter until the
+// callback arrives or they expire
+type OIDCStateStore interface {
+	Save(ctx context.Context, state string, session *OIDCSession, ttl time.Duration) error
+	// Consume returns the session of state and removes it.
+	// It returns ErrOIDCFailed when the state is unknown or expired.
+	Consume(ctx context.Context, state string) (*OIDCSession, error)
+}
+
+// OIDCService logs users in through an external OpenID Connect provider
+type OIDCService interface {
+	// BeginLogin returns the provider's authorization URL to send the user to
+	BeginLogin(ctx context.Context) (string, error)
+	// FinishLogin redeems the code of the provider's callback, verifies the ID
+	// token and returns an access token like Login
+	FinishLogin(ctx context.Context, code, state string) (string, *UserResponse, error)
+}
+
+var (
+	// ErrOIDCFailed indicates that a single sign-on login could not be verified
+	ErrOIDCFailed = &Error{Code: "INVALID_CREDENTIALS", Message: "Single sign-on failed"}
+	// ErrOIDCNotProvisioned indicates that no account exists for the identity
+	// and accounts are not created on first login
+	ErrOIDCNotProvisioned = &Error{Code: "FORBIDDEN", Message: "No account exists for this identity"}
+	// ErrOIDCProviderUnavailable indicates that the identity provider could not be reached
+	ErrOIDCProviderUnavailable = &Error{Code: "IDP_UNAVAILABLE", Message: "Identity provider is unavailable"}
+)
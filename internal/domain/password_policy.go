@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// PasswordPolicy decides which passwords accounts may use. Check returns an
+// *InvalidFieldsError listing every rule the password breaks.
+type PasswordPolicy interface {
+	Check(ctx context.Context, field, password string) error
+}
+
+// FieldError is a request field that failed validation. Rule names the check
+// that failed, such as "min_length" or "breached", for clients to act on.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// InvalidFieldsError lists every invalid field of a request. It unwraps to
+// ErrValidationFailed.
+type InvalidFieldsError struct {
+	Fields []FieldError
+}
+
+// Error lists the invalid fields and why they were rejected
+func (e *InvalidFieldsError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		reasons = append(reasons, field.Field+": "+field.Message)
+	}
+	return "Validation failed: " + strings.Join(reasons, "; ")
+}
+
+// Unwrap returns ErrValidationFailed
+func (e *InvalidFieldsError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// PasswordHasher hashes passwords for storage. Verify returns an error when
+// the password does not match the hash. NeedsRehash reports whether a stored
+// hash was made with another algorithm or parameters than new hashes, so it
+// can be replaced once the password is known.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) error
+	NeedsRehash(hash string) bool
+}
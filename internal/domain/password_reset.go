@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// ForgotPasswordRequest asks for a password reset link to be emailed
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// PasswordResetService defines the emailed password reset flow
+type PasswordResetService interface {
+	// RequestReset emails a single-use reset link to the account's address.
+	// It succeeds whether or not the email is registered.
+	RequestReset(ctx context.Context, email, clientIP string) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+}
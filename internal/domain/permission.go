@@ -0,0 +1,118 @@
+package domain
+
+import "context"
+
+// Permissions of the admin API checked by JWTMiddleware.RequirePermission.
+// Policies grant them to roles by name, by "<area>.*" for a whole area, or
+// by "*" for every permission.
+const (
+	PermissionUserList    = "user.list"
+	PermissionUserRead    = "user.read"
+	PermissionUserUpdate  = "user.update"
+	PermissionUserDelete  = "user.delete"
+	PermissionUserBulk    = "user.bulk"
+	PermissionUserStatus  = "user.status"
+	PermissionStatsRead   = "stats.read"
+	PermissionCacheRead   = "cache.read"
+	PermissionPolicyRead  = "policy.read"
+	PermissionRoleRead    = "role.read"
+	PermissionRoleManage  = "role.manage"
+	PermissionRoleAssign  = "role.assign"
+	PermissionEmailRead   = "email.read"
+	PermissionEmailManage = "email.manage"
+
+	PermissionAnnouncementManage = "announcement.manage"
+	PermissionUsageRead          = "usage.read"
+	PermissionInviteManage       = "invite.manage"
+	PermissionAuditRead          = "audit.read"
+	PermissionSecurityRead       = "security.read"
+	PermissionMetricsRead        = "metrics.read"
+	PermissionTelemetryRead      = "telemetry.read"
+	PermissionRetentionRead      = "retention.read"
+	PermissionRouteRead          = "route.read"
+	PermissionRouteManage        = "route.manage"
+	PermissionAPIKeyManage       = "api_key.manage"
+	PermissionClientManage       = "client.manage"
+	PermissionTokenRevoke        = "token.revoke"
+	PermissionSnapshotManage     = "snapshot.manage"
+	PermissionTransferManage     = "transfer.manage"
+)
+
+// PermissionUserReadPrivate shows the private fields of other users (see
+// PrivateUserFields) wherever users are returned
+const PermissionUserReadPrivate = "user.read_private"
+
+// Permissions lists the permissions checked by the built-in routes and responses
+var Permissions = []string{
+	PermissionUserList,
+	PermissionUserRead,
+	PermissionUserReadPrivate,
+	PermissionUserUpdate,
+	PermissionUserDelete,
+	PermissionUserBulk,
+	PermissionUserStatus,
+	PermissionStatsRead,
+	PermissionCacheRead,
+	PermissionPolicyRead,
+	PermissionRoleRead,
+	PermissionRoleManage,
+	PermissionRoleAssign,
+	PermissionEmailRead,
+	PermissionEmailManage,
+	PermissionAnnouncementManage,
+	PermissionUsageRead,
+	PermissionInviteManage,
+	PermissionAuditRead,
+	PermissionSecurityRead,
+	PermissionMetricsRead,
+	PermissionTelemetryRead,
+	PermissionRetentionRead,
+	PermissionRouteRead,
+	PermissionRouteManage,
+	PermissionAPIKeyManage,
+	PermissionClientManage,
+	PermissionTokenRevoke,
+	PermissionSnapshotManage,
+	PermissionTransferManage,
+}
+
+// PolicyEngine decides which roles hold which permissions. Implementations
+// may load their rules from configuration, a database or an external
+// service; decisions must be fast, as they are made on every request.
+type PolicyEngine interface {
+	// Allows reports whether the role holds the permission
+	Allows(role, permission string) bool
+	// Permissions returns the known permissions the role holds, wildcards
+	// expanded, followed by any other permissions granted to it by name
+	Permissions(role string) []string
+	// Grants returns the rules as configured: the permissions or wildcards
+	// granted to each role
+	Grants() map[string][]string
+}
+
+// Authorizer checks what the principal of a request may do, for handlers
+// whose decision depends on the request body or on the resource they load
+type Authorizer interface {
+	// Can reports whether the principal's role holds the permission
+	Can(ctx context.Context, permission string) bool
+	// AuthorizeOwner returns ErrForbidden unless the principal is the user
+	// owning the resource or its role holds the permission
+	AuthorizeOwner(ctx context.Context, ownerID, permission string) error
+}
+
+// EffectivePermissions are the permissions a user holds through their role
+type EffectivePermissions struct {
+	UserID      string   `json:"user_id,omitempty"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// PolicyOverview describes the permission rules in effect
+type PolicyOverview struct {
+	// Permissions are those checked by the built-in routes
+	Permissions []string `json:"permissions"`
+	// Grants are the rules as configured
+	Grants map[string][]string `json:"grants"`
+	// Roles are the permissions each role holds, wildcards expanded
+	Roles map[string][]string `json:"roles"`
+}
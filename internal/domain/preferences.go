@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Display themes a user can choose
+const (
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+	ThemeSystem = "system"
+)
+
+// UserPreferences holds a user's display and privacy preferences
+type UserPreferences struct {
+	UserID   string `json:"-" bson:"_id"`
+	Theme    string `json:"theme,omitempty" bson:"theme,omitempty"`
+	Language string `json:"language,omitempty" bson:"language,omitempty"`
+	Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	// PublicProfile opts the user in to the public profile and WebFinger
+	// discovery; profiles are private by default
+	PublicProfile bool `json:"public_profile,omitempty" bson:"public_profile,omitempty"`
+	// HidePresence opts the user out of last-seen tracking
+	HidePresence bool      `json:"hide_presence,omitempty" bson:"hide_presence,omitempty"`
+	UpdatedAt    time.Time `json:"-" bson:"updated_at"`
+}
+
+// UpdatePreferencesRequest represents the request to change display preferences.
+// Omitted fields are left unchanged; an empty string clears a preference.
+type UpdatePreferencesRequest struct {
+	Theme    *string `json:"theme,omitempty"`
+	Language *string `json:"language,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
+	// PublicProfile turns the public profile on or off
+	PublicProfile *bool `json:"public_profile,omitempty"`
+	// HidePresence turns last-seen tracking off or back on
+	HidePresence *bool `json:"hide_presence,omitempty"`
+}
+
+// PreferencesRepository defines the interface for display preference storage
+type PreferencesRepository interface {
+	// Get returns the user's preferences, or nil when none have been saved
+	Get(ctx context.Context, userID string) (*UserPreferences, error)
+	// GetMany returns saved preferences keyed by user ID; users without any are absent
+	GetMany(ctx context.Context, userIDs []string) (map[string]*UserPreferences, error)
+	Upsert(ctx context.Context, prefs *UserPreferences) error
+}
+
+// PreferencesService defines the interface for display preference business logic
+type PreferencesService interface {
+	GetPreferences(ctx context.Context, userID string) (*UserPreferences, error)
+	UpdatePreferences(ctx context.Context, userID string, req *UpdatePreferencesRequest) (*UserPreferences, error)
+}
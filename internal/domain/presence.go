@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Presence statuses, derived from how long ago a user was last seen
+const (
+	PresenceOnline         = "online"
+	PresenceRecentlyActive = "recently_active"
+	PresenceOffline        = "offline"
+)
+
+// Presence tells staff whether a user is currently active
+type Presence struct {
+	Status     string    `json:"status"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// PresenceStore keeps the time each user was last seen
+type PresenceStore interface {
+	// Claim reports whether this is the user's first activity within
+	// `debounce`, across instances, so only that one has to be recorded
+	Claim(ctx context.Context, userID string, debounce time.Duration) (bool, error)
+	// Record stores the time the user was last seen
+	Record(ctx context.Context, userID string, at time.Time) error
+	// LastSeen returns last-seen times keyed by user ID; users never seen are absent
+	LastSeen(ctx context.Context, userIDs []string) (map[string]time.Time, error)
+}
+
+// PresenceService tracks when users were last active
+type PresenceService interface {
+	// RecordActivity notes an authenticated request by the user. It never
+	// fails the request; store errors are logged.
+	RecordActivity(ctx context.Context, userID string)
+	// Presence returns the presence of the users keyed by user ID. Users who
+	// were never seen, or opted out, are absent.
+	Presence(ctx context.Context, userIDs []string) (map[string]*Presence, error)
+}
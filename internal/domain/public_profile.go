@@ -0,0 +1,42 @@
+package domain
+
+import "context"
+
+// WebFinger link relations published for public profiles
+const (
+	WebFingerRelProfilePage = "http://webfinger.net/rel/profile-page"
+	WebFingerRelAvatar      = "http://webfinger.net/rel/avatar"
+)
+
+// PublicProfile is the part of a user's profile anyone may read, once the
+// user opted in with the public_profile preference
+type PublicProfile struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Handle     string `json:"handle"` // <id>@<domain>, the user's acct: URI without the scheme
+	AvatarURL  string `json:"avatar_url,omitempty"`
+	ProfileURL string `json:"profile_url"`
+}
+
+// WebFingerLink is a link of a WebFinger resource (RFC 7033)
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// WebFingerResource is the JSON Resource Descriptor returned by WebFinger
+type WebFingerResource struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+// PublicProfileService serves public profiles without authentication. Users
+// who did not opt in, and inactive accounts, are reported as not found.
+type PublicProfileService interface {
+	GetProfile(ctx context.Context, userID string) (*PublicProfile, error)
+	// WebFinger resolves an acct: URI, either the user's handle or their
+	// email, keeping only links whose relation is in rels when any are given
+	WebFinger(ctx context.Context, resource string, rels []string) (*WebFingerResource, error)
+}
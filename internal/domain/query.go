@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FilterOp is a comparison operator in the list query DSL
+type FilterOp string
+
+// Supported filter operators
+const (
+	OpEq       FilterOp = "eq"
+	OpNe       FilterOp = "ne"
+	OpGt       FilterOp = "gt"
+	OpGte      FilterOp = "gte"
+	OpLt       FilterOp = "lt"
+	OpLte      FilterOp = "lte"
+	OpIn       FilterOp = "in"
+	OpContains FilterOp = "contains"
+)
+
+// MaxQueryFilters bounds how many filter clauses a single query may contain
+const MaxQueryFilters = 10
+
+// Filter is a single validated filter clause. Value is a string, a time.Time
+// for date fields, or a []string for the in operator.
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value interface{}
+}
+
+// SortField orders results by a field, descending when Desc is set
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// UserQuery is the typed form of the admin list query DSL, e.g.
+//
+//	?q=smith&filter[role]=admin&filter[created_at][gte]=2025-01-01&sort=-created_at,name
+type UserQuery struct {
+	Search  string
+	Filters []Filter
+	Sort    []SortField
+	Limit   int
+	Offset  int
+	Fields  []string
+
+	// SkipTotal avoids counting every match (?include_total=false). The
+	// total returned by a search is then only offset + page size, plus one
+	// when more results follow, which is enough to tell if there is a next page.
+	SkipTotal bool
+}
+
+// HasCriteria reports whether the query searches, filters or sorts, as
+// opposed to plain pagination
+func (q *UserQuery) HasCriteria() bool {
+	return q.Search != "" || len(q.Filters) > 0 || len(q.Sort) > 0
+}
+
+type fieldKind int
+
+const (
+	stringField fieldKind = iota
+	timeField
+)
+
+// userQueryFields lists the fields that can be filtered and sorted on
+var userQueryFields = map[string]fieldKind{
+	"name":       stringField,
+	"email":      stringField,
+	"role":       stringField,
+	"status":     stringField,
+	"created_at": timeField,
+	"updated_at": timeField,
+}
+
+var allowedOps = map[fieldKind]map[FilterOp]bool{
+	stringField: {OpEq: true, OpNe: true, OpIn: true, OpContains: true},
+	timeField:   {OpEq: true, OpGt: true, OpGte: true, OpLt: true, OpLte: true},
+}
+
+var filterParamPattern = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// ParseUserQuery parses and validates list query parameters. Every invalid
+// parameter is reported in the returned *InvalidParamsError.
+func ParseUserQuery(values url.Values) (*UserQuery, error) {
+	params := NewQueryBinder(values)
+	q := &UserQuery{
+		Limit:     params.Limit(),
+		Offset:    params.Offset(),
+		Search:    strings.TrimSpace(values.Get("q")),
+		SkipTotal: !params.Bool("include_total", true),
+	}
+
+	fields, err := ParseUserFields(values.Get("fields"))
+	if err != nil {
+		params.Invalid("fields", err.Error())
+	}
+	q.Fields = fields
+
+	for key, vals := range values {
+		match := filterParamPattern.FindStringSubmatch(key)
+		if match == nil {
+			if strings.HasPrefix(key, "filter[") {
+				params.Invalid(key, "Malformed filter parameter")
+			}
+			continue
+		}
+
+		for _, raw := range vals {
+			filter, err := parseFilter(match[1], FilterOp(match[2]), raw)
+			if err != nil {
+				params.Invalid(key, err.Error())
+				continue
+			}
+			q.Filters = append(q.Filters, filter)
+		}
+	}
+	if len(q.Filters) > MaxQueryFilters {
+		params.Invalid("filter", "Too many filters")
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			sortField := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+			if _, ok := userQueryFields[sortField.Field]; !ok {
+				params.Invalid("sort", "Unknown sort field: "+sortField.Field)
+				continue
+			}
+			q.Sort = append(q.Sort, sortField)
+		}
+	}
+
+	if err := params.Err(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func parseFilter(field string, op FilterOp, raw string) (Filter, error) {
+	kind, ok := userQueryFields[field]
+	if !ok {
+		return Filter{}, queryError("Unknown filter field: " + field)
+	}
+	if op == "" {
+		op = OpEq
+	}
+	if !allowedOps[kind][op] {
+		return Filter{}, queryError("Operator " + string(op) + " is not supported for " + field)
+	}
+
+	filter := Filter{Field: field, Op: op}
+	switch {
+	case kind == timeField:
+		t, err := parseQueryTime(raw)
+		if err != nil {
+			return Filter{}, queryError("Invalid date for " + field + ": " + raw)
+		}
+		filter.Value = t
+	case op == OpIn:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		filter.Value = items
+	default:
+		filter.Value = raw
+	}
+	return filter, nil
+}
+
+// parseQueryTime accepts RFC 3339 timestamps or plain YYYY-MM-DD dates (UTC midnight)
+func parseQueryTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+func queryError(message string) error {
+	return &Error{Code: "VALIDATION_FAILED", Message: message}
+}
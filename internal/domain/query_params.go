@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxQueryLimit is the largest page size a list request may ask for. Services
+// may cap pages further; see PaginationConfig.
+const MaxQueryLimit = 1000
+
+// InvalidParam is a query parameter that failed validation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// InvalidParamsError lists every invalid query parameter of a request. It
+// unwraps to ErrValidationFailed.
+type InvalidParamsError struct {
+	Params []InvalidParam
+}
+
+// Error lists the invalid parameters and why they were rejected
+func (e *InvalidParamsError) Error() string {
+	reasons := make([]string, 0, len(e.Params))
+	for _, param := range e.Params {
+		reasons = append(reasons, param.Name+": "+param.Reason)
+	}
+	return "Invalid query parameters: " + strings.Join(reasons, "; ")
+}
+
+// Unwrap returns ErrValidationFailed
+func (e *InvalidParamsError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// QueryBinder reads typed query parameters, falling back to defaults for
+// absent ones. Invalid values are collected rather than returned one at a
+// time, so a single response can report all of them:
+//
+//	params := domain.NewQueryBinder(r.URL.Query())
+//	limit := params.Limit()
+//	offset := params.Offset()
+//	if err := params.Err(); err != nil { ... }
+type QueryBinder struct {
+	values  url.Values
+	invalid []InvalidParam
+}
+
+// NewQueryBinder creates a binder for values
+func NewQueryBinder(values url.Values) *QueryBinder {
+	return &QueryBinder{values: values}
+}
+
+// Int returns the named integer parameter, or def when it is absent. Values
+// outside [min, max] are invalid.
+func (b *QueryBinder) Int(name string, def, min, max int) int {
+	raw := strings.TrimSpace(b.values.Get(name))
+	if raw == "" {
+		return def
+	}
+
+	value, err := strconv.Atoi(raw)
+	switch {
+	case err != nil:
+		b.Invalid(name, "must be an integer")
+	case value < min:
+		b.Invalid(name, "must be at least "+strconv.Itoa(min))
+	case value > max:
+		b.Invalid(name, "must be at most "+strconv.Itoa(max))
+	default:
+		return value
+	}
+	return def
+}
+
+// Bool returns the named boolean parameter, or def when it is absent
+func (b *QueryBinder) Bool(name string, def bool) bool {
+	raw := strings.TrimSpace(b.values.Get(name))
+	if raw == "" {
+		return def
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		b.Invalid(name, "must be true or false")
+		return def
+	}
+	return value
+}
+
+// Limit returns the page size, 10 unless the limit parameter says otherwise
+func (b *QueryBinder) Limit() int {
+	return b.Int("limit", 10, 1, MaxQueryLimit)
+}
+
+// Offset returns the number of results to skip, 0 unless the offset parameter says otherwise
+func (b *QueryBinder) Offset() int {
+	return b.Int("offset", 0, 0, math.MaxInt32)
+}
+
+// Invalid records a parameter rejected by the caller's own validation
+func (b *QueryBinder) Invalid(name, reason string) {
+	b.invalid = append(b.invalid, InvalidParam{Name: name, Reason: reason})
+}
+
+// Err returns an *InvalidParamsError listing the invalid parameters, or nil
+func (b *QueryBinder) Err() error {
+	if len(b.invalid) == 0 {
+		return nil
+	}
+	return &InvalidParamsError{Params: b.invalid}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SecurityQuestion is a knowledge-based recovery question with a hashed answer
+type SecurityQuestion struct {
+	Question   string `json:"question" bson:"question"`
+	AnswerHash string `json:"-" bson:"answer_hash"`
+}
+
+// SecurityAnswer pairs a question with the user's plain-text answer
+type SecurityAnswer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// SetSecurityQuestionsRequest represents a request to configure recovery questions
+type SetSecurityQuestionsRequest struct {
+	Answers []SecurityAnswer `json:"answers"`
+}
+
+// RecoveryQuestionsRequest represents a request for an account's recovery questions
+type RecoveryQuestionsRequest struct {
+	Email string `json:"email"`
+}
+
+// VerifyRecoveryRequest represents an attempt to answer recovery questions
+type VerifyRecoveryRequest struct {
+	Email   string           `json:"email"`
+	Answers []SecurityAnswer `json:"answers"`
+}
+
+// ResetPasswordRequest represents a password reset using a one-time token
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// AccountRecoveryService defines the knowledge-based account recovery flow
+type AccountRecoveryService interface {
+	// SecurityQuestionCatalog returns the questions users may choose from
+	SecurityQuestionCatalog() []string
+	SetSecurityQuestions(ctx context.Context, userID string, req *SetSecurityQuestionsRequest) error
+	GetRecoveryQuestions(ctx context.Context, email string) ([]string, error)
+	// VerifyAnswers checks answers and returns a single-use password reset token
+	VerifyAnswers(ctx context.Context, req *VerifyRecoveryRequest, clientIP string) (string, error)
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+}
+
+// ResetToken is what a password reset token is bound to
+type ResetToken struct {
+	UserID string `json:"user_id"`
+	// PasswordStamp fingerprints the user's password hash when the token was
+	// issued. A token whose stamp no longer matches is void, so changing the
+	// password invalidates every outstanding reset token.
+	PasswordStamp string `json:"password_stamp"`
+}
+
+// ResetTokenStore persists single-use password reset tokens
+type ResetTokenStore interface {
+	Save(ctx context.Context, token string, binding *ResetToken, ttl time.Duration) error
+	// Consume returns the binding of token and invalidates it.
+	// It returns ErrInvalidToken when the token is unknown or expired.
+	Consume(ctx context.Context, token string) (*ResetToken, error)
+}
+
+var (
+	// ErrRateLimited indicates that too many attempts were made in a short period
+	ErrRateLimited = &Error{Code: "RATE_LIMITED", Message: "Too many attempts, please try again later"}
+	// ErrRecoveryFailed indicates that recovery answers did not match
+	ErrRecoveryFailed = &Error{Code: "INVALID_CREDENTIALS", Message: "Recovery answers are incorrect"}
+)
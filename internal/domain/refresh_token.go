@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RefreshRequest exchanges a refresh token for a new token pair
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPair is an access token together with the refresh token that renews it
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshTokenRecord is the server-side state of a refresh token. Every token
+// belongs to a family started at login; rotating a token issues the next
+// token of the same family.
+type RefreshTokenRecord struct {
+	UserID    string    `json:"user_id" bson:"user_id"`
+	FamilyID  string    `json:"family_id" bson:"family_id"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	// IssuedAt is when the family started, checked against per-user revocations
+	IssuedAt time.Time `json:"issued_at" bson:"issued_at"`
+	// RememberMe families were started with remember-me and keep its
+	// longer lifetime on every rotation
+	RememberMe bool `json:"remember_me,omitempty" bson:"remember_me,omitempty"`
+}
+
+// RefreshTokenStore persists refresh tokens, keyed by token
+type RefreshTokenStore interface {
+	// Save stores the token until record.ExpiresAt
+	Save(ctx context.Context, token string, record *RefreshTokenRecord) error
+	// Get returns the token's record like Use does, without marking it used
+	Get(ctx context.Context, token string) (*RefreshTokenRecord, error)
+	// Use marks the token as used and returns its record. A token that was
+	// used before returns its record with ErrRefreshTokenReused. Unknown,
+	// expired and revoked tokens return ErrInvalidToken.
+	Use(ctx context.Context, token string) (*RefreshTokenRecord, error)
+	// RevokeFamily invalidates every token of the family. ttl bounds how long
+	// the revocation has to be remembered, the lifetime of a refresh token.
+	RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error
+}
+
+// RefreshTokenService issues refresh tokens and rotates them on every use
+type RefreshTokenService interface {
+	// Issue starts a new token family for a user who has just authenticated
+	// and been given accessToken. rememberMe starts a long-lived family.
+	// client is where the user signed in from.
+	Issue(ctx context.Context, userID, accessToken string, rememberMe bool, client SessionClient) (string, time.Time, error)
+	// Rotate exchanges a refresh token for a new access token and the next
+	// refresh token of its family. Replaying a used token revokes the family.
+	Rotate(ctx context.Context, refreshToken string, client SessionClient) (*TokenPair, error)
+	// Revoke ends the family of a refresh token held by the user, as part of
+	// a logout. Unknown tokens and tokens of other users are ignored.
+	Revoke(ctx context.Context, userID, refreshToken string) error
+}
+
+// ErrRefreshTokenReused indicates that an already rotated refresh token was
+// presented again, a sign that it was stolen. It is reported to clients as
+// ErrInvalidToken.
+var ErrRefreshTokenReused = &Error{Code: "REFRESH_TOKEN_REUSED", Message: "Refresh token has already been used"}
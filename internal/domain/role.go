@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Audit actions of role management
+const (
+	AuditActionRoleCreated = "role.created"
+	AuditActionRoleUpdated = "role.updated"
+	AuditActionRoleDeleted = "role.deleted"
+)
+
+// Role is a named set of permissions. Users hold a role by name through
+// User.Role. Built-in roles are the ones the configuration refers to; they
+// are created at startup and cannot be deleted, but admins may grant them
+// more permissions.
+type Role struct {
+	Name        string   `json:"name" bson:"_id"`
+	Description string   `json:"description,omitempty" bson:"description,omitempty"`
+	Permissions []string `json:"permissions" bson:"permissions"`
+	// EffectivePermissions are those the role holds, configured and stored
+	// ones together, with wildcards expanded
+	EffectivePermissions []string `json:"effective_permissions" bson:"-"`
+	// BuiltIn follows from the configuration and is not stored
+	BuiltIn   bool      `json:"built_in" bson:"-"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// CreateRoleRequest defines a new role
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest changes a role; omitted fields are left as they are
+type UpdateRoleRequest struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// AssignRoleRequest names the role to give a user
+type AssignRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// RoleRepository defines the interface for role persistence
+type RoleRepository interface {
+	// Create returns ErrRoleExists when the name is taken
+	Create(ctx context.Context, role *Role) error
+	// GetByName returns ErrRoleNotFound for unknown roles
+	GetByName(ctx context.Context, name string) (*Role, error)
+	// List returns every role, sorted by name
+	List(ctx context.Context) ([]*Role, error)
+	Update(ctx context.Context, role *Role) error
+	Delete(ctx context.Context, name string) error
+}
+
+// RoleCatalog tells which roles exist, so users are only assigned those
+type RoleCatalog interface {
+	HasRole(name string) bool
+}
+
+// RoleService manages roles. It is also the policy engine deciding what
+// each role may do: the permissions configured for a role together with
+// those stored with it. The actor is the admin changing a role, holding
+// actorRole: roles are only granted permissions the actor holds itself
+// (ErrRoleGrantDenied), and the actor cannot change its own role
+// (ErrRoleSelfEdit).
+type RoleService interface {
+	PolicyEngine
+	RoleCatalog
+	CreateRole(ctx context.Context, actorID, actorRole string, req *CreateRoleRequest) (*Role, error)
+	ListRoles(ctx context.Context) ([]*Role, error)
+	GetRole(ctx context.Context, name string) (*Role, error)
+	UpdateRole(ctx context.Context, actorID, actorRole, name string, req *UpdateRoleRequest) (*Role, error)
+	// DeleteRole refuses built-in roles and roles users still hold
+	DeleteRole(ctx context.Context, actorID, name string) error
+	// Reload creates the missing built-in roles and reloads the roles from
+	// the repository
+	Reload(ctx context.Context) error
+}
+
+// Role errors
+var (
+	ErrRoleNotFound = &Error{Code: "ROLE_NOT_FOUND", Message: "Role not found"}
+	ErrRoleExists   = &Error{Code: "ROLE_EXISTS", Message: "A role with this name already exists"}
+	ErrRoleInUse    = &Error{Code: "ROLE_IN_USE", Message: "The role is still assigned to users"}
+	ErrRoleBuiltIn  = &Error{Code: "ROLE_BUILT_IN", Message: "Built-in roles cannot be deleted"}
+	ErrUnknownRole  = &Error{Code: "UNKNOWN_ROLE", Message: "Role does not exist"}
+	// ErrRoleAssignDenied keeps principals holding user.update or user.bulk
+	// from changing roles without role.assign
+	ErrRoleAssignDenied = &Error{Code: "FORBIDDEN", Message: "Changing roles requires the role.assign permission"}
+	ErrRoleGrantDenied  = &Error{Code: "FORBIDDEN", Message: "Roles can only be granted permissions you hold"}
+	ErrRoleSelfEdit     = &Error{Code: "FORBIDDEN", Message: "You cannot change your own role"}
+)
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// RoleCheckService looks up users' current roles, so tokens issued before a
+// role change stop granting the old role before they expire
+type RoleCheckService interface {
+	// CurrentRole returns the user's role, at most as old as the service's
+	// recheck interval. Deleted users return ErrUserNotFound, suspended
+	// ones ErrAccountSuspended and banned ones ErrAccountBanned.
+	CurrentRole(ctx context.Context, userID string) (string, error)
+}
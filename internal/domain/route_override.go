@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Route override actions
+const (
+	// RouteOverrideOpen lets matching requests through without authentication
+	RouteOverrideOpen = "open"
+	// RouteOverrideClose refuses matching requests, public routes included
+	RouteOverrideClose = "close"
+)
+
+// Audit actions of route override management
+const (
+	AuditActionRouteOverrideCreated = "route_override.created"
+	AuditActionRouteOverrideDeleted = "route_override.deleted"
+)
+
+// RouteOverride opens or closes endpoints at runtime, on top of the skip
+// paths of the configuration and the route table. Rule has the form of a JWT
+// skip path, "[METHOD ]PATTERN". Overrides without ExpiresAt stay in effect
+// until they are deleted.
+type RouteOverride struct {
+	ID        string     `json:"id" bson:"_id"`
+	Rule      string     `json:"rule" bson:"rule"`
+	Action    string     `json:"action" bson:"action"`
+	Reason    string     `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedBy string     `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// Expired reports whether the override has expired at the given time
+func (o *RouteOverride) Expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
+
+// CreateRouteOverrideRequest describes a new override
+type CreateRouteOverrideRequest struct {
+	Rule      string     `json:"rule"`
+	Action    string     `json:"action"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RouteOverrideRepository defines the interface for route override persistence
+type RouteOverrideRepository interface {
+	Create(ctx context.Context, override *RouteOverride) error
+	// List returns every override, oldest first
+	List(ctx context.Context) ([]*RouteOverride, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// RouteOverrideService manages route overrides and answers which override,
+// if any, applies to a request. Overrides are shared through the repository
+// and picked up by every instance within the refresh interval.
+type RouteOverrideService interface {
+	// ListOverrides returns the overrides in effect, oldest first
+	ListOverrides(ctx context.Context) ([]*RouteOverride, error)
+	CreateOverride(ctx context.Context, actorID string, req *CreateRouteOverrideRequest) (*RouteOverride, error)
+	DeleteOverride(ctx context.Context, actorID, id string) error
+	// MatchRoute returns the override matching the request, if any;
+	// a close override wins over an open one. It never blocks on storage.
+	MatchRoute(method, path string) (*RouteOverride, bool)
+}
+
+// ErrRouteOverrideNotFound indicates that a route override does not exist
+var ErrRouteOverrideNotFound = &Error{Code: "ROUTE_OVERRIDE_NOT_FOUND", Message: "Route override not found"}
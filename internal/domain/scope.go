@@ -0,0 +1,17 @@
+package domain
+
+import "strings"
+
+// Resource scopes routes can demand with JWTMiddleware.RequireScope. They
+// have the form "<resource>:<action>"; principals get them from their token
+// or from the scopes granted to their role.
+const (
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+)
+
+// IsResourceScope reports whether scope has the "<resource>:<action>" form
+func IsResourceScope(scope string) bool {
+	resource, action, found := strings.Cut(scope, ":")
+	return found && resource != "" && action != "" && !strings.ContainsAny(scope, " \t")
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Security event types
+const (
+	SecurityEventLoginFailed        = "login.failed"
+	SecurityEventRoleChanged        = "user.role_changed"
+	SecurityEventUserSuspended      = "user.suspended"
+	SecurityEventUserBanned         = "user.banned"
+	SecurityEventUserDeleted        = "user.deleted"
+	SecurityEventRefreshTokenReused = "refresh_token.reused"
+	SecurityEventSuspiciousLogin    = "login.suspicious"
+)
+
+// Security event severities, from lowest to highest
+const (
+	SecuritySeverityLow      = "low"
+	SecuritySeverityMedium   = "medium"
+	SecuritySeverityHigh     = "high"
+	SecuritySeverityCritical = "critical"
+)
+
+// Anomaly flags attached to security events
+const (
+	// SecurityFlagRepeatedFailuresEmail marks a login failure for an email that failed repeatedly
+	SecurityFlagRepeatedFailuresEmail = "repeated_failures_email"
+	// SecurityFlagRepeatedFailuresIP marks a login failure from a client that failed repeatedly
+	SecurityFlagRepeatedFailuresIP = "repeated_failures_ip"
+	// SecurityFlagPrivilegeEscalation marks a change to the admin role
+	SecurityFlagPrivilegeEscalation = "privilege_escalation"
+	// SecurityFlagNewCountry marks a login from a country the user has not recently logged in from
+	SecurityFlagNewCountry = "new_country"
+	// SecurityFlagNewDevice marks a login from a device the user has not recently logged in with
+	SecurityFlagNewDevice = "new_device"
+)
+
+var securitySeverityRank = map[string]int{
+	SecuritySeverityLow:      1,
+	SecuritySeverityMedium:   2,
+	SecuritySeverityHigh:     3,
+	SecuritySeverityCritical: 4,
+}
+
+// IsSecuritySeverity reports whether severity is a known severity level
+func IsSecuritySeverity(severity string) bool {
+	_, ok := securitySeverityRank[severity]
+	return ok
+}
+
+// SeverityAtLeast reports whether severity is at or above min
+func SeverityAtLeast(severity, min string) bool {
+	return securitySeverityRank[severity] >= securitySeverityRank[min]
+}
+
+// SecurityEvent is a structured record of a security-relevant occurrence
+type SecurityEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Severity  string                 `json:"severity"`
+	Flags     []string               `json:"flags,omitempty"`
+	ActorID   string                 `json:"actor_id,omitempty"`
+	TargetID  string                 `json:"target_id,omitempty"`
+	Email     string                 `json:"email,omitempty"`
+	ClientIP  string                 `json:"client_ip,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// SecurityEventFilter narrows security event listings; empty fields match everything
+type SecurityEventFilter struct {
+	Type string
+	// MinSeverity keeps events at or above this severity
+	MinSeverity string
+	TargetID    string
+}
+
+// SecurityEventService records security events and lists recent ones
+type SecurityEventService interface {
+	// Record stores the event and notifies subscribers. It never fails the caller.
+	Record(ctx context.Context, event *SecurityEvent)
+	ListEvents(ctx context.Context, filter SecurityEventFilter, limit, offset int) ([]*SecurityEvent, int64, error)
+}
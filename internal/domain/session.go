@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Session is one sign-in of a user on one device. It shares its ID with the
+// refresh token family started at the sign-in and lives as long as that
+// family: until the user signs out, revokes it or stops refreshing.
+type Session struct {
+	ID         string    `json:"id" bson:"_id"`
+	UserID     string    `json:"user_id" bson:"user_id"`
+	Device     string    `json:"device" bson:"device"`
+	IPAddress  string    `json:"ip_address" bson:"ip_address"`
+	UserAgent  string    `json:"user_agent" bson:"user_agent"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" bson:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+	RememberMe bool      `json:"remember_me" bson:"remember_me"`
+	// AccessTokens are the unexpired access tokens issued in the session,
+	// which are revoked together with it
+	AccessTokens []SessionToken `json:"-" bson:"access_tokens"`
+	// Current marks the session the listing request was made from
+	Current bool `json:"current" bson:"-"`
+}
+
+// HasAccessToken reports whether the access token with the ID was issued in the session
+func (s *Session) HasAccessToken(tokenID string) bool {
+	for _, token := range s.AccessTokens {
+		if token.ID == tokenID {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionToken identifies an access token issued in a session
+type SessionToken struct {
+	ID        string    `bson:"id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// SessionClient describes the client a session signed in or refreshed from
+type SessionClient struct {
+	IPAddress string
+	UserAgent string
+}
+
+// SessionRepository defines the interface for session persistence
+type SessionRepository interface {
+	// Save creates the session or replaces a stored one with the same ID
+	Save(ctx context.Context, session *Session) error
+	// GetByID returns ErrSessionNotFound for unknown sessions and sessions of other users
+	GetByID(ctx context.Context, userID, id string) (*Session, error)
+	// ListByUser returns the user's sessions, most recently seen first
+	ListByUser(ctx context.Context, userID string) ([]*Session, error)
+	// Delete removes a session of the user; sessions of other users are not found
+	Delete(ctx context.Context, userID, id string) error
+	// Search returns up to limit unexpired sessions of any of the user IDs,
+	// or last seen from any of the IP addresses, in queries, most recently
+	// seen first
+	Search(ctx context.Context, queries []string, limit int) ([]*Session, error)
+}
+
+// SessionRecorder keeps sessions in step with their refresh token families
+type SessionRecorder interface {
+	// Track records that the family of record issued accessToken to client,
+	// starting its session on the first call
+	Track(ctx context.Context, record *RefreshTokenRecord, accessToken string, client SessionClient) error
+	// End revokes the access tokens of a family's session and forgets it,
+	// once the family itself has been revoked
+	End(ctx context.Context, userID, familyID string) error
+}
+
+// SessionService lets users see and end the sessions they are signed in
+// with. currentTokenID is the ID of the access token of the request, which
+// identifies the session it was made from.
+type SessionService interface {
+	// ListSessions returns the user's active sessions, most recently seen first
+	ListSessions(ctx context.Context, userID, currentTokenID string) ([]*Session, error)
+	// RevokeSession ends a session: its refresh token and access tokens stop working
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	// RevokeOtherSessions ends every session of the user but the current one
+	// and returns how many were ended
+	RevokeOtherSessions(ctx context.Context, userID, currentTokenID string) (int, error)
+}
+
+// ErrSessionNotFound indicates that a session does not exist or belongs to another user
+var ErrSessionNotFound = &Error{Code: "SESSION_NOT_FOUND", Message: "Session not found"}
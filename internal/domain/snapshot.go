@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserSnapshotVersion is the format version written into user snapshots
+const UserSnapshotVersion = 1
+
+// Audit actions recorded for snapshots
+const (
+	AuditActionSnapshotCreated  = "snapshot.created"
+	AuditActionSnapshotRestored = "snapshot.restored"
+)
+
+var (
+	// ErrSnapshotNotFound indicates that no snapshot has the requested name
+	ErrSnapshotNotFound = &Error{Code: "SNAPSHOT_NOT_FOUND", Message: "Snapshot not found"}
+	// ErrInvalidSnapshot indicates a snapshot that is malformed or fails its checksum
+	ErrInvalidSnapshot = &Error{Code: "INVALID_SNAPSHOT", Message: "Snapshot is corrupt or unsupported"}
+	// ErrInvalidConfirmation indicates a restore confirmation token that is
+	// wrong, expired or was issued for another snapshot or admin
+	ErrInvalidConfirmation = &Error{Code: "INVALID_CONFIRMATION", Message: "Confirmation token is invalid or expired"}
+)
+
+// SnapshotInfo describes a snapshot without its users
+type SnapshotInfo struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	Source    string    `json:"source"` // environment the snapshot was taken in
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UserCount int       `json:"user_count"`
+	// Checksum is the hex SHA-256 of the JSON encoded users
+	Checksum string `json:"checksum"`
+}
+
+// UserSnapshot is a full dump of the users collection. Users are carried as
+// ExportedUser records, including password hashes, so restored accounts keep
+// working.
+type UserSnapshot struct {
+	SnapshotInfo
+	Users []*ExportedUser `json:"users"`
+}
+
+// RestoreSnapshotRequest confirms a restore with the token from its preview
+type RestoreSnapshotRequest struct {
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+// SnapshotRestorePreview describes what restoring a snapshot would do. The
+// restore only runs when it is repeated with ConfirmationToken.
+type SnapshotRestorePreview struct {
+	Snapshot          SnapshotInfo `json:"snapshot"`
+	CurrentUsers      int          `json:"current_users"`
+	ConfirmationToken string       `json:"confirmation_token"`
+	ExpiresAt         time.Time    `json:"expires_at"`
+}
+
+// SnapshotRestoreResult reports how the users collection was changed
+type SnapshotRestoreResult struct {
+	Snapshot SnapshotInfo `json:"snapshot"`
+	Created  int          `json:"created"`
+	Updated  int          `json:"updated"`
+	Deleted  int64        `json:"deleted"`
+}
+
+// SnapshotStore keeps encoded snapshots by name
+type SnapshotStore interface {
+	Put(ctx context.Context, name string, data []byte) error
+	// Get returns ErrSnapshotNotFound when no snapshot has the name
+	Get(ctx context.Context, name string) ([]byte, error)
+	// List returns the names of all snapshots, oldest first
+	List(ctx context.Context) ([]string, error)
+}
+
+// SnapshotService dumps the users collection to snapshots and restores it
+// from them. A restore replaces every user, so it is confirmed in two steps.
+type SnapshotService interface {
+	Create(ctx context.Context, actorID string) (*SnapshotInfo, error)
+	List(ctx context.Context) ([]string, error)
+	Get(ctx context.Context, name string) (*SnapshotInfo, error)
+	// PreviewRestore verifies the snapshot and issues a confirmation token
+	// bound to the snapshot, its checksum and the actor
+	PreviewRestore(ctx context.Context, actorID, name string) (*SnapshotRestorePreview, error)
+	// Restore replaces the users collection with the snapshot's users
+	Restore(ctx context.Context, actorID, name, confirmationToken string) (*SnapshotRestoreResult, error)
+}
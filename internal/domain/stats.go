@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// UserStats summarizes the user base for lightweight reporting
+type UserStats struct {
+	Total            int64            `json:"total"`
+	NewUsers         NewUserCounts    `json:"new_users"`
+	DailySignups     []DailyCount     `json:"daily_signups"`
+	RoleDistribution map[string]int64 `json:"role_distribution"`
+	ComputedAt       time.Time        `json:"computed_at"`
+}
+
+// NewUserCounts holds sign-up counts over rolling windows ending now
+type NewUserCounts struct {
+	LastDay   int64 `json:"last_day"`
+	LastWeek  int64 `json:"last_week"`
+	LastMonth int64 `json:"last_month"`
+}
+
+// DailyCount is the number of sign-ups on a UTC calendar day (YYYY-MM-DD)
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// StatsWindowDays is how far back daily sign-ups and the monthly count reach
+const StatsWindowDays = 30
+
+// DailyCountLayout is the date format used by DailyCount
+const DailyCountLayout = "2006-01-02"
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditActionUserTokensRevoked is recorded when an admin revokes every token of a user
+const AuditActionUserTokensRevoked = "user.tokens_revoked"
+
+// LogoutRequest optionally names the refresh token to end along with the access token
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// TokenRevocationStore is the denylist of revoked access tokens. Entries only
+// have to outlive the tokens they deny, so every entry expires.
+type TokenRevocationStore interface {
+	// Revoke denies the token with the given ID (its jti) until expiresAt
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	// IsRevoked reports whether the token with the given ID was revoked
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	// RevokeUser denies every token of the user issued at or before
+	// `before`. ttl bounds how long the cut-off has to be remembered.
+	RevokeUser(ctx context.Context, userID string, before time.Time, ttl time.Duration) error
+	// RevokedBefore returns the user's latest cut-off, or the zero time
+	RevokedBefore(ctx context.Context, userID string) (time.Time, error)
+}
+
+// TokenRevocationService logs users out by revoking their access tokens
+type TokenRevocationService interface {
+	// RevokeToken revokes the single token described by claims
+	RevokeToken(ctx context.Context, claims *TokenClaims) error
+	// RevokeUserTokens revokes every token and refresh token issued to the user so far
+	RevokeUserTokens(ctx context.Context, actorID, userID string) error
+	// IsRevoked reports whether the token described by claims was revoked,
+	// individually or together with all tokens of its user. When the denylist
+	// cannot be read it returns ErrStorageUnavailable, so revoked tokens are
+	// not accepted during an outage.
+	IsRevoked(ctx context.Context, claims *TokenClaims) (bool, error)
+}
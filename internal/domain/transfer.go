@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserBundleVersion is the format version written into exported bundles
+const UserBundleVersion = 1
+
+// Conflict modes for importing a user whose ID or email already exists
+const (
+	ImportConflictSkip      = "skip"      // keep the existing user
+	ImportConflictOverwrite = "overwrite" // replace the user with the same ID
+	ImportConflictFail      = "fail"      // import nothing if any user conflicts
+)
+
+// Import outcomes reported per user
+const (
+	ImportOutcomeCreated     = "created"
+	ImportOutcomeOverwritten = "overwritten"
+	ImportOutcomeSkipped     = "skipped"
+	ImportOutcomeConflict    = "conflict"
+)
+
+var (
+	// ErrInvalidBundle indicates a bundle that is malformed, expired or not signed by a trusted instance
+	ErrInvalidBundle = &Error{Code: "INVALID_BUNDLE", Message: "Bundle signature is invalid"}
+	// ErrImportConflict indicates that a fail-mode import found existing users
+	ErrImportConflict = &Error{Code: "IMPORT_CONFLICT", Message: "Some users already exist, nothing was imported"}
+)
+
+// ExportedUser is a user record as carried in a bundle, including the
+// password hash and recovery answer hashes so the account keeps working on
+// the target instance
+type ExportedUser struct {
+	ID                string                     `json:"id"`
+	Name              string                     `json:"name"`
+	Email             string                     `json:"email"`
+	PasswordHash      string                     `json:"password_hash"`
+	Role              string                     `json:"role"`
+	Status            string                     `json:"status,omitempty"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+	SecurityQuestions []ExportedSecurityQuestion `json:"security_questions,omitempty"`
+	Preferences       *UserPreferences           `json:"preferences,omitempty"`
+	Notes             []*UserNote                `json:"notes,omitempty"`
+}
+
+// ExportedSecurityQuestion is a recovery question with its answer hash
+type ExportedSecurityQuestion struct {
+	Question   string `json:"question"`
+	AnswerHash string `json:"answer_hash"`
+}
+
+// UserBundle is a signed set of exported users. The signature is an
+// HMAC-SHA256 over the bundle without its signature, keyed with the signing
+// key shared by the exporting and importing instances.
+type UserBundle struct {
+	Version    int             `json:"version"`
+	Source     string          `json:"source"` // environment the bundle was exported from
+	ExportedBy string          `json:"exported_by"`
+	ExportedAt time.Time       `json:"exported_at"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+	Users      []*ExportedUser `json:"users"`
+	Signature  string          `json:"signature"`
+}
+
+// ExportUsersRequest selects the users to export
+type ExportUsersRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// ImportUsersRequest carries a bundle to import and how to handle users that already exist
+type ImportUsersRequest struct {
+	Bundle   *UserBundle `json:"bundle"`
+	Conflict string      `json:"conflict,omitempty"` // defaults to skip
+	DryRun   bool        `json:"dry_run,omitempty"`  // report outcomes without writing
+}
+
+// ImportItemResult reports what happened to one user from a bundle
+type ImportItemResult struct {
+	ID      string `json:"id"`
+	Email   string `json:"email"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AccountTransferService moves user accounts between instances through signed bundles
+type AccountTransferService interface {
+	Export(ctx context.Context, actorID string, req *ExportUsersRequest) (*UserBundle, error)
+	Import(ctx context.Context, actorID string, req *ImportUsersRequest) ([]ImportItemResult, error)
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Kinds of principals whose API usage is counted
+const (
+	UsagePrincipalUser   = "user"
+	UsagePrincipalAPIKey = "api_key"
+	UsagePrincipalClient = "client"
+)
+
+// UsageDayFormat is the layout of usage days, which are UTC dates
+const UsageDayFormat = "2006-01-02"
+
+// UsagePrincipal identifies who made requests: a user with a token, an API
+// key or a machine client. UserID is the user the usage belongs to, the
+// owner of an API key and the user itself otherwise.
+type UsagePrincipal struct {
+	Type   string `json:"principal_type" bson:"principal_type"`
+	ID     string `json:"principal_id" bson:"principal_id"`
+	UserID string `json:"user_id" bson:"user_id"`
+}
+
+// UsageCounts are the counters kept for a principal and day
+type UsageCounts struct {
+	Requests     int64 `json:"requests" bson:"requests"`
+	ClientErrors int64 `json:"client_errors" bson:"client_errors"` // 4xx responses
+	ServerErrors int64 `json:"server_errors" bson:"server_errors"` // 5xx responses
+	LatencyMs    int64 `json:"total_latency_ms" bson:"latency_ms"`
+}
+
+// Add adds other to the counts
+func (c *UsageCounts) Add(other UsageCounts) {
+	c.Requests += other.Requests
+	c.ClientErrors += other.ClientErrors
+	c.ServerErrors += other.ServerErrors
+	c.LatencyMs += other.LatencyMs
+}
+
+// Rates returns the share of requests that failed and their average latency
+func (c UsageCounts) Rates() (errorRate, avgLatencyMs float64) {
+	if c.Requests == 0 {
+		return 0, 0
+	}
+	return float64(c.ClientErrors+c.ServerErrors) / float64(c.Requests), float64(c.LatencyMs) / float64(c.Requests)
+}
+
+// UsageRecord is a principal's usage on one day. ErrorRate and AvgLatencyMs
+// are derived from the counts when the record is returned.
+type UsageRecord struct {
+	Day            string `json:"day" bson:"day"`
+	UsagePrincipal `bson:",inline"`
+	UsageCounts    `bson:",inline"`
+	ErrorRate      float64   `json:"error_rate" bson:"-"`
+	AvgLatencyMs   float64   `json:"avg_latency_ms" bson:"-"`
+	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// WithRates sets the derived fields from the counts and returns the record
+func (r *UsageRecord) WithRates() *UsageRecord {
+	r.ErrorRate, r.AvgLatencyMs = r.UsageCounts.Rates()
+	return r
+}
+
+// UsageTotals are the counts of many records added up, with their rates
+type UsageTotals struct {
+	UsageCounts
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// SumUsage adds up the counts of records
+func SumUsage(records []*UsageRecord) UsageTotals {
+	var totals UsageTotals
+	for _, record := range records {
+		totals.Add(record.UsageCounts)
+	}
+	totals.ErrorRate, totals.AvgLatencyMs = totals.UsageCounts.Rates()
+	return totals
+}
+
+// UsageFilter selects usage records. From and To are inclusive days; empty
+// fields match everything.
+type UsageFilter struct {
+	UserID        string
+	PrincipalType string
+	PrincipalID   string
+	From          string
+	To            string
+}
+
+// UsageCounterStore keeps the running counters of recent days, shared by
+// every instance
+type UsageCounterStore interface {
+	// Add adds counts to the principal's counters of the day
+	Add(ctx context.Context, day string, principal UsagePrincipal, counts UsageCounts) error
+	// List returns the counters of every principal seen on the day
+	List(ctx context.Context, day string) ([]*UsageRecord, error)
+}
+
+// UsageRepository persists the daily usage rollups
+type UsageRepository interface {
+	// Save creates the record or replaces the stored one of the same day
+	// and principal
+	Save(ctx context.Context, record *UsageRecord) error
+	// List returns the matching records, newest day first
+	List(ctx context.Context, filter UsageFilter) ([]*UsageRecord, error)
+}
+
+// ErrInvalidUsageRange indicates usage days that are malformed, reversed or
+// span more days than allowed
+var ErrInvalidUsageRange = &Error{Code: "INVALID_USAGE_RANGE", Message: "Usage days must be YYYY-MM-DD, from before to, within the allowed range"}
+
+// UsageRecorder counts a request made by an authenticated principal
+type UsageRecorder interface {
+	Record(principal UsagePrincipal, status int, latency time.Duration)
+}
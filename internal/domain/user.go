@@ -14,8 +14,48 @@ type User struct {
 	Email     string    `json:"email" bson:"email"`
 	Password  string    `json:"-" bson:"password"` // Hidden from JSON
 	Role      string    `json:"role" bson:"role"`
+	Status    string    `json:"status" bson:"status,omitempty"`
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	// StatusReason explains why an admin suspended or banned the account
+	StatusReason    string     `json:"status_reason,omitempty" bson:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" bson:"status_changed_at,omitempty"`
+
+	SecurityQuestions   []SecurityQuestion   `json:"-" bson:"security_questions,omitempty"`
+	WebAuthnCredentials []WebAuthnCredential `json:"-" bson:"webauthn_credentials,omitempty"`
+	ExternalIdentities  []ExternalIdentity   `json:"-" bson:"external_identities,omitempty"`
+}
+
+// User account statuses. An empty status is treated as active so existing
+// records remain valid. Suspended and banned accounts cannot sign in or use
+// their tokens; suspensions can be lifted, bans cannot. Deleted accounts are
+// reported as not found; the status also marks cache tombstones of removed
+// users.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+	UserStatusDeleted   = "deleted"
+)
+
+// IsActive reports whether the account may be used
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
+}
+
+// IsSuspended reports whether the account has been suspended
+func (u *User) IsSuspended() bool {
+	return u.Status == UserStatusSuspended
+}
+
+// IsBanned reports whether the account has been banned
+func (u *User) IsBanned() bool {
+	return u.Status == UserStatusBanned
+}
+
+// IsDeleted reports whether the account has been deleted
+func (u *User) IsDeleted() bool {
+	return u.Status == UserStatusDeleted
 }
 
 // CreateUserRequest represents the request to create a new user
@@ -24,6 +64,9 @@ type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 	Role     string `json:"role,omitempty"`
+	// InviteCode is the code of an invite to the email, required when
+	// registration is invite only
+	InviteCode string `json:"invite_code,omitempty"`
 }
 
 // UpdateUserRequest represents the request to update a user
@@ -33,6 +76,12 @@ type UpdateUserRequest struct {
 	Role  *string `json:"role,omitempty"`
 }
 
+// ChangePasswordRequest represents the request to change the caller's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
 // UpdateUserInput represents input for GraphQL user updates
 type UpdateUserInput struct {
 	Name  *string `json:"name,omitempty"`
@@ -44,16 +93,63 @@ type UpdateUserInput struct {
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// RememberMe asks for a long-lived refresh token; the access token
+	// lifetime is unchanged
+	RememberMe bool `json:"remember_me,omitempty"`
+}
+
+// Bulk user actions accepted by BulkUserAction
+const (
+	BulkActionDelete  = "delete"
+	BulkActionSuspend = "suspend"
+	BulkActionSetRole = "set-role"
+)
+
+// BulkUserActionRequest represents an admin action applied to many users at once
+type BulkUserActionRequest struct {
+	IDs    []string `json:"ids"`
+	Action string   `json:"action"`
+	Role   string   `json:"role,omitempty"`   // required for set-role
+	Reason string   `json:"reason,omitempty"` // required for suspend
+}
+
+// BulkItemResult reports the outcome of a bulk action for one user
+type BulkItemResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SetUserStatusRequest is an admin changing an account's status. Reason is
+// required to suspend or ban.
+type SetUserStatusRequest struct {
+	Status string `json:"-"`
+	Reason string `json:"reason"`
+}
+
+// UserFieldUpdate lists fields changed by a batch update; nil fields are left untouched
+type UserFieldUpdate struct {
+	Role   *string
+	Status *string
 }
 
 // UserResponse represents user data returned to clients (without sensitive data)
 type UserResponse struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	Status    string    `json:"status,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Only set for suspended and banned accounts
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+
+	// Fields filled in from other sources by the response assembler
+	AvatarURL   string           `json:"avatar_url,omitempty"`
+	Preferences *UserPreferences `json:"preferences,omitempty"`
+	Presence    *Presence        `json:"presence,omitempty"`
 }
 
 // ToResponse converts User entity to UserResponse
@@ -63,8 +159,12 @@ func (u *User) ToResponse() *UserResponse {
 		Name:      u.Name,
 		Email:     u.Email,
 		Role:      u.Role,
+		Status:    u.Status,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
+
+		StatusReason:    u.StatusReason,
+		StatusChangedAt: u.StatusChangedAt,
 	}
 }
 
@@ -75,36 +175,102 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Update(ctx context.Context, id string, user *User) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, limit, offset int) ([]*User, error)
+	// List returns a page of users; when fields are given only those fields
+	// need to be loaded (see UserFieldNames)
+	List(ctx context.Context, limit, offset int, fields ...string) ([]*User, error)
+	// Search returns the page of users matching the query and the total match
+	// count; the total is not computed (0) when query.SkipTotal is set
+	Search(ctx context.Context, query *UserQuery) ([]*User, int64, error)
 	Count(ctx context.Context) (int64, error)
+
+	// Batch operations
+	GetByIDs(ctx context.Context, ids []string) ([]*User, error)
+	DeleteMany(ctx context.Context, ids []string) (int64, error)
+	UpdateMany(ctx context.Context, ids []string, update UserFieldUpdate) (int64, error)
+
+	// Reporting
+	Stats(ctx context.Context, now time.Time) (*UserStats, error)
 }
 
-// UserService defines the interface for user business logic
-type UserService interface {
+// UserQueryService is the read side of user business logic. Its methods
+// do not change state, so decorators such as caches can wrap them alone.
+type UserQueryService interface {
+	GetProfile(ctx context.Context, userID string) (*UserResponse, error)
+	GetUsers(ctx context.Context, limit, offset int, fields ...string) ([]*UserResponse, int64, error)
+	SearchUsers(ctx context.Context, query *UserQuery) ([]*UserResponse, int64, error)
+	GetUserByID(ctx context.Context, id string) (*UserResponse, error)
+	GetUserStats(ctx context.Context) (*UserStats, error)
+}
+
+// UserCommandService is the write side of user business logic: operations
+// that create, change or remove users or issue tokens for them
+type UserCommandService interface {
 	Register(ctx context.Context, req *CreateUserRequest) (*UserResponse, error)
 	Login(ctx context.Context, req *LoginRequest) (string, *UserResponse, error) // returns token and user
-	GetProfile(ctx context.Context, userID string) (*UserResponse, error)
 	UpdateProfile(ctx context.Context, userID string, req *UpdateUserRequest) (*UserResponse, error)
-	GetUsers(ctx context.Context, limit, offset int) ([]*UserResponse, int64, error)
-	GetUserByID(ctx context.Context, id string) (*UserResponse, error)
+	// ChangePassword sets a new password after checking the current one
+	ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) error
+	// UpdateUser is the admin update, the only path that may assign privileged roles
+	UpdateUser(ctx context.Context, actorID, id string, req *UpdateUserRequest) (*UserResponse, error)
 	DeleteUser(ctx context.Context, id string) error
+	// RefreshToken issues a new access token for the user; clients renew
+	// tokens through RefreshTokenService, which calls it on rotation
 	RefreshToken(ctx context.Context, userID string) (string, error)
+	BulkUserAction(ctx context.Context, actorID string, req *BulkUserActionRequest) ([]BulkItemResult, error)
+	// SetUserStatus suspends, bans or reinstates an account (admin only).
+	// Banned accounts cannot be reinstated.
+	SetUserStatus(ctx context.Context, actorID, id string, req *SetUserStatusRequest) (*UserResponse, error)
+}
+
+// UserService combines the query and command sides of user business logic
+type UserService interface {
+	UserQueryService
+	UserCommandService
+}
+
+// CheckEmailRequest asks whether an email address is already registered
+type CheckEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// EmailCheckService tells signup forms whether an email can still be used.
+// It is rate limited per client and answers in constant time to make
+// enumerating registered addresses slow.
+type EmailCheckService interface {
+	IsEmailAvailable(ctx context.Context, email, clientIP string) (bool, error)
 }
 
 // TokenService defines the interface for JWT token operations
 type TokenService interface {
 	GenerateToken(user *User) (string, error)
+	// GenerateClientToken issues a token to a machine client, without a user
+	GenerateClientToken(grant *ClientGrant) (string, error)
+	// GenerateDelegatedToken issues a token acting for a user on behalf of
+	// the grant's actor
+	GenerateDelegatedToken(grant *DelegationGrant) (string, error)
 	ValidateToken(tokenString string) (*TokenClaims, error)
 	ExtractUserIDFromToken(tokenString string) (string, error)
 }
 
 // TokenClaims represents JWT token claims
 type TokenClaims struct {
+	ID     string `json:"jti,omitempty"` // unique per token, the key for revoking it
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
 	Exp    int64  `json:"exp"`
 	Iat    int64  `json:"iat"`
+	// Tenant names the trusted issuer of the token; empty for tokens of this deployment
+	Tenant string `json:"tenant,omitempty"`
+	// ClientID is set on tokens of machine clients, whose UserID is the
+	// client ID too
+	ClientID string `json:"client_id,omitempty"`
+	// Scopes are the scopes granted by the token itself; principals also
+	// have the resource scopes of their role (see JWTMiddleware.RequireScope)
+	Scopes []string `json:"scopes,omitempty"`
+	// Actor is set on delegated tokens, issued by a token exchange to the
+	// client acting for the user; they only have the scopes of the token
+	Actor *Actor `json:"act,omitempty"`
 }
 
 // Error represents a domain-specific error with a code and message.
@@ -126,4 +292,36 @@ var (
 	ErrUnauthorized       = &Error{Code: "UNAUTHORIZED", Message: "Unauthorized access"}
 	ErrForbidden          = &Error{Code: "FORBIDDEN", Message: "Access forbidden"}
 	ErrValidationFailed   = &Error{Code: "VALIDATION_FAILED", Message: "Validation failed"}
+	ErrAccountSuspended   = &Error{Code: "ACCOUNT_SUSPENDED", Message: "Account is suspended"}
+	ErrAccountBanned      = &Error{Code: "ACCOUNT_BANNED", Message: "Account is banned"}
+	ErrUserBanned         = &Error{Code: "USER_BANNED", Message: "Banned accounts cannot be reinstated"}
+	ErrRoleNotAllowed     = &Error{Code: "ROLE_NOT_ALLOWED", Message: "Role cannot be self-assigned"}
+	ErrWrongPassword      = &Error{Code: "WRONG_PASSWORD", Message: "Current password is incorrect"}
+	ErrRequestTimeout     = &Error{Code: "REQUEST_TIMEOUT", Message: "Request timed out"}
+	// ErrStorageUnavailable indicates that a write could not reach the
+	// database in time, e.g. during a primary election; clients should retry
+	ErrStorageUnavailable = &Error{Code: "STORAGE_UNAVAILABLE", Message: "Storage is temporarily unavailable, please retry"}
 )
+
+// ContentPolicy screens user-supplied display text such as names. Check
+// returns a *Error with code CONTENT_POLICY_VIOLATION when the value is rejected.
+type ContentPolicy interface {
+	Check(field, value string) error
+}
+
+// EmailDomainPolicy decides which email domains accounts may use. CheckEmail
+// returns a *Error with code EMAIL_DOMAIN_NOT_ALLOWED when the domain is rejected.
+type EmailDomainPolicy interface {
+	CheckEmail(email string) error
+}
+
+// ErrEmailDomainNotAllowed indicates that an email domain may not be used;
+// policies name the domain in their message
+var ErrEmailDomainNotAllowed = &Error{Code: "EMAIL_DOMAIN_NOT_ALLOWED", Message: "Email domain is not allowed"}
+
+// TokenStore persists opaque access tokens server-side, keyed by token
+type TokenStore interface {
+	Save(ctx context.Context, token string, claims *TokenClaims, ttl time.Duration) error
+	Get(ctx context.Context, token string) (*TokenClaims, error) // ErrInvalidToken if unknown or expired
+	Delete(ctx context.Context, token string) error
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Audit actions of passkey management
+const (
+	AuditActionPasskeyRegistered = "user.passkey_registered"
+	AuditActionPasskeyRemoved    = "user.passkey_removed"
+)
+
+// WebAuthnCredential is a passkey registered to a user. ID and PublicKey
+// (a COSE key) are base64url-encoded as browsers report them.
+type WebAuthnCredential struct {
+	ID         string    `json:"id" bson:"id"`
+	Name       string    `json:"name" bson:"name"`
+	PublicKey  string    `json:"-" bson:"public_key"`
+	Algorithm  int64     `json:"algorithm" bson:"algorithm"`
+	SignCount  uint32    `json:"-" bson:"sign_count"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+}
+
+// The option and credential types below follow the WebAuthn JSON encoding,
+// so options can be passed to navigator.credentials as they are, and the
+// credential it returns posted back, with binary fields base64url-encoded.
+
+// WebAuthnRelyingParty identifies this service to authenticators
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// WebAuthnUserEntity identifies the user a passkey is created for
+type WebAuthnUserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// WebAuthnCredentialParameter is a key type the service accepts
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// WebAuthnCredentialDescriptor names an existing credential
+type WebAuthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// WebAuthnAuthenticatorSelection states what authenticators may be used
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// WebAuthnCreationOptions are the publicKey options of navigator.credentials.create
+type WebAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions are the publicKey options of navigator.credentials.get
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RPID             string                         `json:"rpId"`
+	Timeout          int64                          `json:"timeout"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+// WebAuthnCredentialResponse is the credential navigator.credentials returns.
+// Registrations carry AttestationObject; logins carry AuthenticatorData,
+// Signature and, for discoverable credentials, UserHandle.
+type WebAuthnCredentialResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject,omitempty"`
+		AuthenticatorData string `json:"authenticatorData,omitempty"`
+		Signature         string `json:"signature,omitempty"`
+		UserHandle        string `json:"userHandle,omitempty"`
+	} `json:"response"`
+}
+
+// FinishPasskeyRegistrationRequest completes a passkey registration
+type FinishPasskeyRegistrationRequest struct {
+	Name       string                     `json:"name"`
+	Credential WebAuthnCredentialResponse `json:"credential"`
+}
+
+// BeginPasskeyLoginRequest starts a passkey login. Without an email any
+// discoverable passkey of this service may be used.
+type BeginPasskeyLoginRequest struct {
+	Email string `json:"email,omitempty"`
+}
+
+// FinishPasskeyLoginRequest completes a passkey login
+type FinishPasskeyLoginRequest struct {
+	Credential WebAuthnCredentialResponse `json:"credential"`
+}
+
+// WebAuthnSession is what the server remembers about a ceremony between its
+// begin and finish requests
+type WebAuthnSession struct {
+	Ceremony string `json:"ceremony"`
+	// UserID is the user registering a passkey, or logging in when the login
+	// named an account; empty for discoverable logins
+	UserID string `json:"user_id,omitempty"`
+}
+
+// WebAuthnSessionStore keeps ceremony sessions by challenge until they are
+// finished or expire
+type WebAuthnSessionStore interface {
+	Save(ctx context.Context, challenge string, session *WebAuthnSession, ttl time.Duration) error
+	// Consume returns the session of challenge and removes it.
+	// It returns ErrPasskeyFailed when the challenge is unknown or expired.
+	Consume(ctx context.Context, challenge string) (*WebAuthnSession, error)
+}
+
+// WebAuthnService registers passkeys and logs users in with them
+type WebAuthnService interface {
+	BeginRegistration(ctx context.Context, userID string) (*WebAuthnCreationOptions, error)
+	FinishRegistration(ctx context.Context, userID string, req *FinishPasskeyRegistrationRequest) (*WebAuthnCredential, error)
+	ListCredentials(ctx context.Context, userID string) ([]WebAuthnCredential, error)
+	DeleteCredential(ctx context.Context, userID, credentialID string) error
+	BeginLogin(ctx context.Context, req *BeginPasskeyLoginRequest) (*WebAuthnRequestOptions, error)
+	// FinishLogin verifies the assertion and returns an access token like Login
+	FinishLogin(ctx context.Context, req *FinishPasskeyLoginRequest) (string, *UserResponse, error)
+}
+
+var (
+	// ErrPasskeyFailed indicates that a passkey ceremony could not be verified
+	ErrPasskeyFailed = &Error{Code: "INVALID_CREDENTIALS", Message: "Passkey verification failed"}
+	// ErrPasskeyNotFound indicates that the user has no passkey with the given ID
+	ErrPasskeyNotFound = &Error{Code: "PASSKEY_NOT_FOUND", Message: "Passkey not found"}
+)
@@ -0,0 +1,246 @@
+// Package emailpolicy decides which email domains may register, from
+// configured allow and deny lists and a maintained deny-list, such as a list
+// of disposable email providers, reloaded from a file or URL in the background.
+package emailpolicy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/httpclient"
+	"demo-go/internal/logger"
+)
+
+const (
+	// loadTimeout bounds one deny-list load
+	loadTimeout = 10 * time.Second
+	// maxListSize bounds how much of a deny-list file or response is read
+	maxListSize = 8 << 20
+)
+
+// Policy implements domain.EmailDomainPolicy. A domain rule also covers the
+// domain's subdomains, so "example.com" matches "mail.example.com".
+type Policy struct {
+	cfg        config.EmailDomainConfig
+	allow      map[string]bool
+	deny       map[string]bool
+	httpClient *http.Client
+	logger     *logger.Logger
+
+	mu     sync.RWMutex
+	listed map[string]bool // the last deny-list that loaded
+
+	started   bool
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates the email domain policy from configuration; call Start to load
+// the deny-list file or URL, when one is configured
+func New(cfg config.EmailDomainConfig) (*Policy, error) {
+	if cfg.DenyListFile != "" && cfg.DenyListURL != "" {
+		return nil, fmt.Errorf("set either a deny-list file or a deny-list URL, not both")
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = config.DefaultDenyListRefresh
+	}
+	return &Policy{
+		cfg:        cfg,
+		allow:      domainSet(cfg.Allow),
+		deny:       domainSet(cfg.Deny),
+		httpClient: &httpclient.New("email-deny-list", httpclient.Config{Timeout: loadTimeout}).Client,
+		logger:     logger.GetGlobal().ForComponent("email-domain-policy"),
+		listed:     map[string]bool{},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// CheckEmail returns a *domain.Error with code EMAIL_DOMAIN_NOT_ALLOWED when
+// the email's domain is denied, or missing from a configured allow list
+func (p *Policy) CheckEmail(email string) error {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil // malformed addresses are left to email validation
+	}
+	emailDomain := normalizeDomain(email[at+1:])
+
+	if len(p.allow) > 0 && !matches(p.allow, emailDomain) {
+		return notAllowed(emailDomain, "is not open for registration")
+	}
+	if matches(p.deny, emailDomain) {
+		return notAllowed(emailDomain, "is not accepted")
+	}
+	p.mu.RLock()
+	listed := matches(p.listed, emailDomain)
+	p.mu.RUnlock()
+	if listed {
+		return notAllowed(emailDomain, "is not accepted")
+	}
+	return nil
+}
+
+// Start loads the deny-list, then keeps reloading it in the background.
+// Until a load succeeds only the configured allow and deny lists apply; after
+// a failed reload the last list that loaded stays in use.
+func (p *Policy) Start() {
+	if p.cfg.DenyListFile == "" && p.cfg.DenyListURL == "" {
+		return
+	}
+	p.mu.Lock()
+	if p.started {
+		p.mu.Unlock()
+		return
+	}
+	p.started = true
+	p.mu.Unlock()
+
+	p.logger.Info("Email domain deny-list enabled", "refresh_interval", p.cfg.RefreshInterval)
+	if err := p.Refresh(context.Background()); err != nil {
+		p.logger.Warn("Failed to load email domain deny-list; retrying in the background", "error", err)
+	}
+	go p.run()
+}
+
+// Close stops background reloads and waits for an in-flight load, or for ctx to expire
+func (p *Policy) Close(ctx context.Context) error {
+	p.mu.RLock()
+	started := p.started
+	p.mu.RUnlock()
+
+	p.closeOnce.Do(func() { close(p.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Refresh loads the deny-list now and replaces the one in use
+func (p *Policy) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	listed, err := p.load(ctx)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.listed = listed
+	p.mu.Unlock()
+	p.logger.Debug("Email domain deny-list loaded", "domains", len(listed))
+	return nil
+}
+
+// run reloads the deny-list at the refresh interval until Close
+func (p *Policy) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			if err := p.Refresh(context.Background()); err != nil {
+				p.logger.Warn("Failed to reload email domain deny-list; keeping the last one", "error", err)
+			}
+		}
+	}
+}
+
+// load reads the deny-list from its file or URL
+func (p *Policy) load(ctx context.Context) (map[string]bool, error) {
+	if p.cfg.DenyListFile != "" {
+		file, err := os.Open(p.cfg.DenyListFile) // #nosec G304 -- path comes from operator configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to open email domain deny-list: %w", err)
+		}
+		defer file.Close() //nolint:errcheck
+		return parseList(file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.DenyListURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch email domain deny-list: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch email domain deny-list: %s", resp.Status)
+	}
+	return parseList(resp.Body)
+}
+
+// parseList reads one domain per line; blank lines and lines starting with #
+// are skipped
+func parseList(r io.Reader) (map[string]bool, error) {
+	listed := make(map[string]bool)
+	scanner := bufio.NewScanner(io.LimitReader(r, maxListSize))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		listed[normalizeDomain(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read email domain deny-list: %w", err)
+	}
+	return listed, nil
+}
+
+// matches reports whether the domain, or a domain it is a subdomain of, is in set
+func matches(set map[string]bool, emailDomain string) bool {
+	for d := emailDomain; d != ""; {
+		if set[d] {
+			return true
+		}
+		i := strings.IndexByte(d, '.')
+		if i < 0 {
+			break
+		}
+		d = d[i+1:]
+	}
+	return false
+}
+
+func domainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		if d = normalizeDomain(d); d != "" {
+			set[d] = true
+		}
+	}
+	return set
+}
+
+// normalizeDomain lowercases a domain and drops a leading "@" or "*." and a
+// trailing dot, so list entries can be written either way
+func normalizeDomain(d string) string {
+	d = strings.ToLower(strings.TrimSpace(d))
+	d = strings.TrimPrefix(strings.TrimPrefix(d, "@"), "*.")
+	return strings.TrimSuffix(d, ".")
+}
+
+func notAllowed(emailDomain, reason string) error {
+	return &domain.Error{Code: domain.ErrEmailDomainNotAllowed.Code, Message: "Email domain " + emailDomain + " " + reason}
+}
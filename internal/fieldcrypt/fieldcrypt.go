@@ -0,0 +1,147 @@
+// Package fieldcrypt encrypts sensitive fields before repositories store
+// them. Values are sealed with AES-256-GCM under the current key of a key
+// provider and tagged with the key's ID, so older values still decrypt after
+// a rotation until they are re-encrypted.
+//
+// An encrypted value has the form "enc:v1:<key id>:<base64url nonce+ciphertext>".
+// Values without the prefix are plaintext written before encryption was
+// enabled; they are returned as they are and re-encrypted on the next write.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"demo-go/internal/domain"
+)
+
+// prefix marks encrypted values and their format version
+const prefix = "enc:v1:"
+
+// Cipher encrypts and decrypts field values with the keys of a provider
+type Cipher struct {
+	keys domain.KeyProvider
+}
+
+// New creates a cipher using the key set of keys
+func New(keys domain.KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// KeyID returns the ID of the key value was encrypted with, or "" for plaintext
+func KeyID(value string) string {
+	id, _, _ := split(value)
+	return id
+}
+
+// Encrypt seals plaintext with the current key. aad binds the value to where
+// it is stored, e.g. a record ID, so it cannot be copied to another record.
+// Empty values stay empty.
+func (c *Cipher) Encrypt(ctx context.Context, plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	set, err := c.keys.KeySet(ctx)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(set.Current)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+	return prefix + set.Current.ID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with the same aad. Plaintext
+// values are returned unchanged.
+func (c *Cipher) Decrypt(ctx context.Context, value, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, encoded, ok := split(value)
+	if !ok {
+		return "", domain.ErrDecryptionFailed
+	}
+
+	set, err := c.keys.KeySet(ctx)
+	if err != nil {
+		return "", err
+	}
+	key, ok := set.Key(id)
+	if !ok {
+		return "", domain.ErrDecryptionFailed
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", domain.ErrDecryptionFailed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(aad))
+	if err != nil {
+		return "", domain.ErrDecryptionFailed
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether value is plaintext or encrypted with a key
+// other than the current one
+func (c *Cipher) NeedsRotation(ctx context.Context, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	set, err := c.keys.KeySet(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !IsEncrypted(value) || KeyID(value) != set.Current.ID, nil
+}
+
+// split separates an encrypted value into its key ID and payload. The payload
+// is base64url and never holds a colon, so key IDs may.
+func split(value string) (id, payload string, ok bool) {
+	if !IsEncrypted(value) {
+		return "", "", false
+	}
+	rest := strings.TrimPrefix(value, prefix)
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// newAEAD derives a 256-bit AES key from the key's secret, so secrets of any
+// length can be used. Asymmetric keys have no secret and cannot encrypt.
+func newAEAD(key domain.SigningKey) (cipher.AEAD, error) {
+	if len(key.Secret) == 0 {
+		return nil, fmt.Errorf("key %q has no secret", key.ID)
+	}
+	sum := sha256.Sum256(key.Secret)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
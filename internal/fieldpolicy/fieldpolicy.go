@@ -0,0 +1,112 @@
+// Package fieldpolicy hides the user fields a viewer may not see. Users see
+// all of their own fields; on other users' responses they see the public
+// fields and the private ones (see domain.PrivateUserFields) their role
+// holds the permission for. REST handlers and GraphQL resolvers filter every
+// user they return through the same Filter.
+package fieldpolicy
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+)
+
+// Filter decides which user fields the viewer of a request may see. The
+// viewer is the authenticated user of the request context; requests without
+// one only see public fields.
+type Filter struct {
+	policy domain.PolicyEngine
+}
+
+// New creates a filter checking viewers' roles against policy
+func New(policy domain.PolicyEngine) *Filter {
+	return &Filter{policy: policy}
+}
+
+// User returns the user as the viewer may see it. The user is not modified;
+// a copy is returned when fields are hidden. A nil filter hides nothing.
+func (f *Filter) User(ctx context.Context, user *domain.UserResponse) *domain.UserResponse {
+	if f == nil || user == nil {
+		return user
+	}
+	if viewerID, ok := middleware.GetUserIDFromContext(ctx); ok && viewerID == user.ID {
+		return user
+	}
+	hidden := f.Hidden(ctx)
+	if len(hidden) == 0 {
+		return user
+	}
+
+	visible := *user
+	for _, field := range hidden {
+		hide(&visible, field)
+	}
+	return &visible
+}
+
+// Users returns the users as the viewer may see them, in a new slice
+func (f *Filter) Users(ctx context.Context, users []*domain.UserResponse) []*domain.UserResponse {
+	if f == nil {
+		return users
+	}
+	visible := make([]*domain.UserResponse, len(users))
+	for i, user := range users {
+		visible[i] = f.User(ctx, user)
+	}
+	return visible
+}
+
+// Hidden returns the private fields the viewer may not see on other users
+func (f *Filter) Hidden(ctx context.Context) []string {
+	if f == nil {
+		return nil
+	}
+	role, _ := middleware.GetUserRoleFromContext(ctx)
+	var hidden []string
+	for _, field := range domain.UserFieldNames {
+		permission, private := domain.PrivateUserFields[field]
+		if private && (role == "" || !f.policy.Allows(role, permission)) {
+			hidden = append(hidden, field)
+		}
+	}
+	return hidden
+}
+
+// CheckQuery rejects list queries filtering or sorting on fields the viewer
+// may not see, which would otherwise reveal their values
+func (f *Filter) CheckQuery(ctx context.Context, query *domain.UserQuery) error {
+	hidden := f.Hidden(ctx)
+	if len(hidden) == 0 {
+		return nil
+	}
+	isHidden := make(map[string]bool, len(hidden))
+	for _, field := range hidden {
+		isHidden[field] = true
+	}
+	for _, filter := range query.Filters {
+		if isHidden[filter.Field] {
+			return domain.ErrFieldNotVisible
+		}
+	}
+	for _, sort := range query.Sort {
+		if isHidden[sort.Field] {
+			return domain.ErrFieldNotVisible
+		}
+	}
+	return nil
+}
+
+// hide clears a private field; cleared fields are omitted from JSON
+func hide(user *domain.UserResponse, field string) {
+	switch field {
+	case "email":
+		user.Email = ""
+	case "role":
+		user.Role = ""
+	case "status":
+		user.Status = ""
+	case "preferences":
+		user.Preferences = nil
+	}
+}
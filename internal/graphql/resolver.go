@@ -8,23 +8,34 @@ import (
 	"strings"
 
 	"demo-go/internal/domain"
+	"demo-go/internal/fieldpolicy"
 	"demo-go/internal/logger"
 )
 
 // Resolver is the root resolver for GraphQL operations
 type Resolver struct {
-	userService domain.UserService
-	logger      *logger.Logger
+	queries  domain.UserQueryService
+	commands domain.UserCommandService
+	fields   *fieldpolicy.Filter
+	logger   *logger.Logger
 }
 
 // NewResolver creates a new GraphQL resolver
 func NewResolver(userService domain.UserService) *Resolver {
 	return &Resolver{
-		userService: userService,
-		logger:      logger.GetGlobal().ForComponent("graphql-resolver"),
+		queries:  userService,
+		commands: userService,
+		logger:   logger.GetGlobal().ForComponent("graphql-resolver"),
 	}
 }
 
+// SetFieldFilter hides the private fields of the users returned to viewers
+// whose role lacks the permission for them, as the REST handlers do. It must
+// be called before the resolver starts serving requests.
+func (r *Resolver) SetFieldFilter(fields *fieldpolicy.Filter) {
+	r.fields = fields
+}
+
 // Query resolver
 func (r *Resolver) Query() QueryResolver {
 	return &queryResolver{r}
@@ -49,14 +60,14 @@ func (r *queryResolver) GetUser(ctx context.Context, id string) (*domain.UserRes
 
 	log.Debug("Resolving getUser query")
 
-	user, err := r.userService.GetUserByID(ctx, id)
+	user, err := r.queries.GetUserByID(ctx, id)
 	if err != nil {
 		log.Error("Failed to get user", "error", err)
 		return nil, err
 	}
 
 	log.Debug("Successfully resolved getUser query", "user_email", user.Email)
-	return user, nil
+	return r.fields.User(ctx, user), nil
 }
 
 // GetUsers resolves the getUsers query
@@ -75,7 +86,7 @@ func (r *queryResolver) GetUsers(ctx context.Context, limit, offset *int) ([]*do
 
 	log.Debug("Resolving getUsers query", "limit", *limit, "offset", *offset)
 
-	users, _, err := r.userService.GetUsers(ctx, *limit, *offset)
+	users, _, err := r.queries.GetUsers(ctx, *limit, *offset)
 	if err != nil {
 		log.Error("Failed to get users", "error", err)
 		return nil, err
@@ -96,7 +107,7 @@ func (r *queryResolver) GetUsers(ctx context.Context, limit, offset *int) ([]*do
 	paginatedUsers := users[start:end]
 	log.Debug("Successfully resolved getUsers query", "total_users", len(users), "returned_users", len(paginatedUsers))
 
-	return paginatedUsers, nil
+	return r.fields.Users(ctx, paginatedUsers), nil
 }
 
 // SearchUsers resolves the searchUsers query
@@ -106,14 +117,15 @@ func (r *queryResolver) SearchUsers(ctx context.Context, query string) ([]*domai
 	log.Debug("Resolving searchUsers query")
 
 	// Get all users and filter by name or email
-	users, _, err := r.userService.GetUsers(ctx, 1000, 0) // Get up to 1000 users for search
+	users, _, err := r.queries.GetUsers(ctx, 1000, 0) // Get up to 1000 users for search
 	if err != nil {
 		log.Error("Failed to get users for search", "error", err)
 		return nil, err
 	}
 
+	// Only visible fields are matched, so hidden emails cannot be probed
 	var filteredUsers []*domain.UserResponse
-	for _, user := range users {
+	for _, user := range r.fields.Users(ctx, users) {
 		if containsIgnoreCase(user.Name, query) || containsIgnoreCase(user.Email, query) {
 			filteredUsers = append(filteredUsers, user)
 		}
@@ -136,7 +148,7 @@ func (r *queryResolver) Me(ctx context.Context) (*domain.UserResponse, error) {
 		return nil, domain.ErrUnauthorized
 	}
 
-	user, err := r.userService.GetUserByID(ctx, userID)
+	user, err := r.queries.GetUserByID(ctx, userID)
 	if err != nil {
 		log.Error("Failed to get current user", "user_id", userID, "error", err)
 		return nil, err
@@ -162,14 +174,14 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input CreateUserInput
 		Role:     "user",
 	}
 
-	user, err := r.userService.Register(ctx, createReq)
+	user, err := r.commands.Register(ctx, createReq)
 	if err != nil {
 		log.Error("Failed to create user", "error", err)
 		return nil, err
 	}
 
 	log.Info("Successfully created user", "user_id", user.ID, "user_email", user.Email)
-	return user, nil
+	return r.fields.User(ctx, user), nil
 }
 
 // UpdateUser resolves the updateUser mutation
@@ -191,14 +203,14 @@ func (r *mutationResolver) UpdateUser(
 		updateReq.Email = input.Email
 	}
 
-	user, err := r.userService.UpdateProfile(ctx, id, updateReq)
+	user, err := r.commands.UpdateProfile(ctx, id, updateReq)
 	if err != nil {
 		log.Error("Failed to update user", "error", err)
 		return nil, err
 	}
 
 	log.Info("Successfully updated user", "user_id", user.ID, "user_email", user.Email)
-	return user, nil
+	return r.fields.User(ctx, user), nil
 }
 
 // DeleteUser resolves the deleteUser mutation
@@ -207,7 +219,7 @@ func (r *mutationResolver) DeleteUser(ctx context.Context, id string) (bool, err
 
 	log.Debug("Resolving deleteUser mutation")
 
-	err := r.userService.DeleteUser(ctx, id)
+	err := r.commands.DeleteUser(ctx, id)
 	if err != nil {
 		log.Error("Failed to delete user", "error", err)
 		return false, err
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// maxAdminSearchLimit bounds the results returned per source
+const maxAdminSearchLimit = 50
+
+// AdminSearchHandler handles HTTP requests for the admin search
+type AdminSearchHandler struct {
+	search domain.AdminSearchService
+	// authz checks the audit.read permission the search takes on top of the
+	// route's user.read; nil skips the check
+	authz domain.Authorizer
+}
+
+// NewAdminSearchHandler creates a new admin search handler
+func NewAdminSearchHandler(search domain.AdminSearchService, authz domain.Authorizer) *AdminSearchHandler {
+	return &AdminSearchHandler{
+		search: search,
+		authz:  authz,
+	}
+}
+
+// Search handles searching users, audit events and sessions for q
+func (h *AdminSearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if h.authz != nil && !h.authz.Can(r.Context(), domain.PermissionAuditRead) {
+		handleServiceError(w, r, domain.ErrAdminSearchDenied)
+		return
+	}
+	params := domain.NewQueryBinder(r.URL.Query())
+	limit := params.Int("limit", 10, 1, maxAdminSearchLimit)
+	if err := params.Err(); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	results, err := h.search.Search(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Search completed", results)
+}
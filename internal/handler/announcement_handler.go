@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// AnnouncementHandler handles HTTP requests for the announcement banner
+type AnnouncementHandler struct {
+	announcements domain.AnnouncementService
+}
+
+// NewAnnouncementHandler creates a new announcement handler
+func NewAnnouncementHandler(announcements domain.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		announcements: announcements,
+	}
+}
+
+// ListAnnouncements handles returning the announcements in effect to
+// clients. It is served from memory, so clients may poll it.
+func (h *AnnouncementHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	announcements := []*domain.Announcement{}
+	if announcement, ok := h.announcements.Current(); ok {
+		announcements = append(announcements, announcement.Public())
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Announcements retrieved successfully", map[string]interface{}{
+		"announcements": announcements,
+	})
+}
+
+// GetAnnouncement handles returning the stored announcement, expired or not
+func (h *AnnouncementHandler) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcement, err := h.announcements.Get(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Announcement retrieved successfully", announcement)
+}
+
+// SetAnnouncement handles creating or replacing the announcement
+func (h *AnnouncementHandler) SetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var req domain.SetAnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	announcement, err := h.announcements.Set(r.Context(), getUserIDFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Announcement set successfully", announcement)
+}
+
+// ClearAnnouncement handles removing the announcement
+func (h *AnnouncementHandler) ClearAnnouncement(w http.ResponseWriter, r *http.Request) {
+	if err := h.announcements.Clear(r.Context(), getUserIDFromContext(r)); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Announcement cleared successfully", nil)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHandler handles HTTP requests for API key management
+type APIKeyHandler struct {
+	apiKeyService domain.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService domain.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// ListKeys handles listing the caller's API keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	h.listKeys(w, r, getUserIDFromContext(r))
+}
+
+// CreateKey handles creating an API key for the caller
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	h.createKey(w, r, getUserIDFromContext(r))
+}
+
+// RevokeKey handles revoking one of the caller's API keys
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	h.revokeKey(w, r, getUserIDFromContext(r))
+}
+
+// ListUserKeys handles listing the API keys of a user
+func (h *APIKeyHandler) ListUserKeys(w http.ResponseWriter, r *http.Request) {
+	h.listKeys(w, r, mux.Vars(r)["id"])
+}
+
+// CreateUserKey handles creating an API key for a user
+func (h *APIKeyHandler) CreateUserKey(w http.ResponseWriter, r *http.Request) {
+	h.createKey(w, r, mux.Vars(r)["id"])
+}
+
+// RevokeUserKey handles revoking an API key of a user
+func (h *APIKeyHandler) RevokeUserKey(w http.ResponseWriter, r *http.Request) {
+	h.revokeKey(w, r, mux.Vars(r)["id"])
+}
+
+// Helper methods
+
+func (h *APIKeyHandler) listKeys(w http.ResponseWriter, r *http.Request, userID string) {
+	if !h.checkCaller(w, r) {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "API keys retrieved successfully", map[string]interface{}{
+		"api_keys": keys,
+	})
+}
+
+func (h *APIKeyHandler) createKey(w http.ResponseWriter, r *http.Request, userID string) {
+	if !h.checkCaller(w, r) {
+		return
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(r.Context(), getUserIDFromContext(r), userID, &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "API key created; store the key now, it is not shown again", key)
+}
+
+func (h *APIKeyHandler) revokeKey(w http.ResponseWriter, r *http.Request, userID string) {
+	if !h.checkCaller(w, r) {
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(r.Context(), getUserIDFromContext(r), userID, mux.Vars(r)["keyId"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "API key revoked successfully", nil)
+}
+
+// checkCaller refuses requests without a user, and requests authenticated by
+// an API key, so a leaked key cannot be used to mint or hide other keys
+func (h *APIKeyHandler) checkCaller(w http.ResponseWriter, r *http.Request) bool {
+	if getUserIDFromContext(r) == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return false
+	}
+	if method, _ := middleware.GetAuthMethodFromContext(r.Context()); method == "api_key" {
+		writeErrorResponse(w, r, http.StatusForbidden, "API keys cannot manage API keys", "FORBIDDEN")
+		return false
+	}
+	return true
+}
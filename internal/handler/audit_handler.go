@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	auditService domain.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService domain.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListEvents handles listing audit events, optionally filtered by actor, target or action
+func (h *AuditHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	params := domain.NewQueryBinder(query)
+	limit := params.Limit()
+	offset := params.Offset()
+	if err := params.Err(); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	filter := domain.AuditFilter{
+		ActorID:  query.Get("actor_id"),
+		TargetID: query.Get("target_id"),
+		Action:   query.Get("action"),
+	}
+
+	events, total, err := h.auditService.ListEvents(r.Context(), filter, limit, offset)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"events": events,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Audit events retrieved successfully", response)
+}
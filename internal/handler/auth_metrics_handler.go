@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/middleware"
+)
+
+// AuthMetricsHandler handles HTTP requests for authentication metrics
+type AuthMetricsHandler struct {
+	metrics *middleware.AuthMetrics
+}
+
+// NewAuthMetricsHandler creates a new authentication metrics handler
+func NewAuthMetricsHandler(metrics *middleware.AuthMetrics) *AuthMetricsHandler {
+	return &AuthMetricsHandler{
+		metrics: metrics,
+	}
+}
+
+// GetMetrics handles reporting authentication outcomes and recent failures (admin only)
+func (h *AuthMetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	writeSuccessResponse(w, r, http.StatusOK, "Authentication metrics retrieved successfully", h.metrics.Snapshot())
+}
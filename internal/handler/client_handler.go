@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// ClientHandler handles machine client management and the token endpoint
+// of the client credentials grant
+type ClientHandler struct {
+	clientService domain.ClientService
+}
+
+// NewClientHandler creates a new machine client handler
+func NewClientHandler(clientService domain.ClientService) *ClientHandler {
+	return &ClientHandler{
+		clientService: clientService,
+	}
+}
+
+// tokenRequest is a client credentials grant or token exchange, sent as a
+// form as RFC 6749 prescribes, or as JSON
+type tokenRequest struct {
+	GrantType        string `json:"grant_type"`
+	ClientID         string `json:"client_id"`
+	ClientSecret     string `json:"client_secret"`
+	Scope            string `json:"scope"`
+	SubjectToken     string `json:"subject_token"`
+	SubjectTokenType string `json:"subject_token_type"`
+}
+
+// parseTokenRequest reads a token request and the client's credentials,
+// from HTTP Basic authentication when present. It writes the error response
+// and returns false when the body cannot be read.
+func parseTokenRequest(w http.ResponseWriter, r *http.Request) (tokenRequest, bool) {
+	var req tokenRequest
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+			return req, false
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid form body")
+			return req, false
+		}
+		req = tokenRequest{
+			GrantType:        r.PostForm.Get("grant_type"),
+			ClientID:         r.PostForm.Get("client_id"),
+			ClientSecret:     r.PostForm.Get("client_secret"),
+			Scope:            r.PostForm.Get("scope"),
+			SubjectToken:     r.PostForm.Get("subject_token"),
+			SubjectTokenType: r.PostForm.Get("subject_token_type"),
+		}
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = id, secret
+	}
+	return req, true
+}
+
+// Token handles POST /auth/token. Clients authenticate with HTTP Basic
+// credentials or client_id and client_secret parameters. Answers and errors
+// follow RFC 6749 instead of the response envelope, so OAuth libraries can
+// use the endpoint.
+func (h *ClientHandler) Token(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseTokenRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if req.GrantType != domain.GrantTypeClientCredentials {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", domain.ErrUnsupportedGrantType.Message)
+		return
+	}
+	if req.ClientID == "" || req.ClientSecret == "" {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client credentials are required")
+		return
+	}
+
+	token, err := h.clientService.IssueToken(r.Context(), req.ClientID, req.ClientSecret, domain.ParseScope(req.Scope))
+	writeTokenResponse(w, token, err)
+}
+
+// ExchangeToken handles POST /auth/token/exchange, the token exchange of
+// RFC 8693. A client authenticated like at /auth/token trades a user's
+// access token, the subject_token, for a shorter-lived one with a subset of
+// its scopes that names the client in its act claim.
+func (h *ClientHandler) ExchangeToken(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseTokenRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if req.GrantType != domain.GrantTypeTokenExchange {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Only the "+domain.GrantTypeTokenExchange+" grant is supported")
+		return
+	}
+	if req.ClientID == "" || req.ClientSecret == "" {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client credentials are required")
+		return
+	}
+	if req.SubjectToken == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The subject_token is required")
+		return
+	}
+	if req.SubjectTokenType != "" && req.SubjectTokenType != domain.TokenTypeAccessToken {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Only access tokens can be exchanged")
+		return
+	}
+
+	token, err := h.clientService.ExchangeToken(r.Context(), req.ClientID, req.ClientSecret, &domain.TokenExchangeRequest{
+		SubjectToken: req.SubjectToken,
+		Scopes:       domain.ParseScope(req.Scope),
+	})
+	writeTokenResponse(w, token, err)
+}
+
+// writeTokenResponse writes an issued token, or the RFC 6749 error of err
+func writeTokenResponse(w http.ResponseWriter, token *domain.ClientToken, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidClient):
+		w.Header().Set("WWW-Authenticate", `Basic realm="token"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", domain.ErrInvalidClient.Message)
+	case errors.Is(err, domain.ErrInvalidScope):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_scope", domain.ErrInvalidScope.Message)
+	case errors.Is(err, domain.ErrScopeRequired):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", domain.ErrScopeRequired.Message)
+	case errors.Is(err, domain.ErrInvalidSubjectToken):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", domain.ErrInvalidSubjectToken.Message)
+	case errors.Is(err, domain.ErrKeysUnavailable):
+		w.Header().Set("Retry-After", "5")
+		writeOAuthError(w, http.StatusServiceUnavailable, "temporarily_unavailable", domain.ErrKeysUnavailable.Message)
+	case err != nil:
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "The token could not be issued")
+	default:
+		writeOAuthJSON(w, http.StatusOK, token)
+	}
+}
+
+// CreateClient handles registering a machine client (admin only)
+func (h *ClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	client, err := h.clientService.CreateClient(r.Context(), getUserIDFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Client created; store the secret now, it is not shown again", client)
+}
+
+// ListClients handles listing machine clients (admin only)
+func (h *ClientHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := h.clientService.ListClients(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Clients retrieved successfully", map[string]interface{}{
+		"clients": clients,
+	})
+}
+
+// DeleteClient handles deleting a machine client (admin only)
+func (h *ClientHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	if err := h.clientService.DeleteClient(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Client deleted successfully", nil)
+}
+
+// writeOAuthError writes an RFC 6749 error response
+func writeOAuthError(w http.ResponseWriter, statusCode int, code, description string) {
+	writeOAuthJSON(w, statusCode, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// writeOAuthJSON writes a token endpoint response, which must not be cached
+func writeOAuthJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+)
+
+// EmailCheckHandler handles the public email availability check
+type EmailCheckHandler struct {
+	emailCheckService domain.EmailCheckService
+}
+
+// NewEmailCheckHandler creates a new email check handler
+func NewEmailCheckHandler(emailCheckService domain.EmailCheckService) *EmailCheckHandler {
+	return &EmailCheckHandler{
+		emailCheckService: emailCheckService,
+	}
+}
+
+// CheckEmail handles telling signup forms whether an email is still available
+func (h *EmailCheckHandler) CheckEmail(w http.ResponseWriter, r *http.Request) {
+	var req domain.CheckEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	available, err := h.emailCheckService.IsEmailAvailable(r.Context(), req.Email, middleware.GetClientIP(r))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Email checked successfully", map[string]interface{}{
+		"available": available,
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/outbox"
+
+	"github.com/gorilla/mux"
+)
+
+// EmailOutboxHandler handles HTTP requests inspecting the email outbox
+type EmailOutboxHandler struct {
+	outbox *outbox.Outbox
+}
+
+// NewEmailOutboxHandler creates a new email outbox handler
+func NewEmailOutboxHandler(outbox *outbox.Outbox) *EmailOutboxHandler {
+	return &EmailOutboxHandler{
+		outbox: outbox,
+	}
+}
+
+// ListEmails handles listing outbox emails, optionally by status or recipient
+func (h *EmailOutboxHandler) ListEmails(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	params := domain.NewQueryBinder(query)
+	limit := params.Int("limit", 50, 1, outbox.MaxListLimit)
+	status := query.Get("status")
+	switch status {
+	case "", domain.EmailStatusQueued, domain.EmailStatusSent, domain.EmailStatusFailed, domain.EmailStatusBounced:
+	default:
+		params.Invalid("status", "must be one of queued, sent, failed or bounced")
+	}
+	if err := params.Err(); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	emails, counts, err := h.outbox.List(r.Context(), domain.OutboxEmailFilter{Status: status, To: query.Get("to"), Limit: limit})
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Emails retrieved successfully", map[string]interface{}{
+		"emails": emails,
+		"counts": counts,
+	})
+}
+
+// GetEmail handles retrieving the delivery state of an email
+func (h *EmailOutboxHandler) GetEmail(w http.ResponseWriter, r *http.Request) {
+	email, err := h.outbox.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Email retrieved successfully", email)
+}
+
+// ResendEmail handles queueing a failed or bounced email again
+func (h *EmailOutboxHandler) ResendEmail(w http.ResponseWriter, r *http.Request) {
+	email, err := h.outbox.Resend(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusAccepted, "Email queued for delivery", email)
+}
+
+// MarkBounced handles recording that a sent email bounced, such as from a
+// mail provider's bounce notifications
+func (h *EmailOutboxHandler) MarkBounced(w http.ResponseWriter, r *http.Request) {
+	var req domain.MarkBouncedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	email, err := h.outbox.MarkBounced(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"], req.Reason)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Email marked as bounced", email)
+}
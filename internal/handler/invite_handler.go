@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// InviteHandler handles HTTP requests for invite management
+type InviteHandler struct {
+	inviteService domain.InviteService
+	// authz checks that invites carrying a role come from a principal
+	// allowed to assign roles; nil skips the check
+	authz domain.Authorizer
+}
+
+// NewInviteHandler creates a new invite handler
+func NewInviteHandler(inviteService domain.InviteService, authz domain.Authorizer) *InviteHandler {
+	return &InviteHandler{
+		inviteService: inviteService,
+		authz:         authz,
+	}
+}
+
+// CreateInvite handles inviting an email to register
+func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	// The invite's role is assigned on registration, so granting it takes
+	// the same permission as assigning it directly
+	if strings.TrimSpace(req.Role) != "" && h.authz != nil && !h.authz.Can(r.Context(), domain.PermissionRoleAssign) {
+		handleServiceError(w, r, domain.ErrRoleAssignDenied)
+		return
+	}
+
+	invite, err := h.inviteService.CreateInvite(r.Context(), getUserIDFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Invite created; send the code now, it is not shown again", invite)
+}
+
+// ListInvites handles listing invites, newest first
+func (h *InviteHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	params := domain.NewQueryBinder(r.URL.Query())
+	limit := params.Limit()
+	offset := params.Offset()
+	if err := params.Err(); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	invites, total, err := h.inviteService.ListInvites(r.Context(), limit, offset)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Invites retrieved successfully", map[string]interface{}{
+		"invites": invites,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// RevokeInvite handles deleting an invite
+func (h *InviteHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	if err := h.inviteService.RevokeInvite(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Invite revoked successfully", nil)
+}
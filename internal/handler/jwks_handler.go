@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// jwksMaxAge is how long verifiers may cache the key set. It is short so
+// a key published ahead of a rotation is picked up well before it signs.
+const jwksMaxAge = "public, max-age=300"
+
+// JWKSHandler publishes the public keys access tokens are signed with
+type JWKSHandler struct {
+	publisher domain.PublicKeyPublisher
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(publisher domain.PublicKeyPublisher) *JWKSHandler {
+	return &JWKSHandler{
+		publisher: publisher,
+	}
+}
+
+// GetKeys handles GET /.well-known/jwks.json. The answer is a bare JSON Web
+// Key Set, as RFC 7517 verifiers expect, not the usual envelope.
+func (h *JWKSHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.publisher.PublicKeys(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", jwksMaxAge)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string][]domain.JSONWebKey{"keys": keys})
+}
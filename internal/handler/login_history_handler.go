@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// LoginHistoryHandler handles HTTP requests for the login history
+type LoginHistoryHandler struct {
+	loginHistory domain.LoginHistoryService
+}
+
+// NewLoginHistoryHandler creates a new login history handler
+func NewLoginHistoryHandler(loginHistory domain.LoginHistoryService) *LoginHistoryHandler {
+	return &LoginHistoryHandler{
+		loginHistory: loginHistory,
+	}
+}
+
+// MyLoginHistory handles listing the caller's recent login attempts
+func (h *LoginHistoryHandler) MyLoginHistory(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+	h.writeHistory(w, r, userID)
+}
+
+// UserLoginHistory handles listing the login attempts of any user
+func (h *LoginHistoryHandler) UserLoginHistory(w http.ResponseWriter, r *http.Request) {
+	h.writeHistory(w, r, mux.Vars(r)["id"])
+}
+
+// writeHistory answers with a page of the user's login attempts
+func (h *LoginHistoryHandler) writeHistory(w http.ResponseWriter, r *http.Request, userID string) {
+	params := domain.NewQueryBinder(r.URL.Query())
+	limit := params.Limit()
+	offset := params.Offset()
+	if err := params.Err(); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	attempts, total, err := h.loginHistory.ListLoginHistory(r.Context(), userID, limit, offset)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Login history retrieved successfully", map[string]interface{}{
+		"logins": attempts,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+)
+
+// writeLoginResponse answers a successful password, single sign-on or passkey
+// login. In cookie auth mode the token goes into the session cookie and the
+// body carries the CSRF token instead; refresh tokens are not issued, so the
+// browser signs in again once the cookie expires. Otherwise the body carries
+// the token, and a refresh token when refreshTokens is set; rememberMe makes
+// it long-lived.
+func writeLoginResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	log *logger.Logger,
+	refreshTokens domain.RefreshTokenService,
+	cookies *middleware.CookieSessions,
+	token string,
+	user *domain.UserResponse,
+	rememberMe bool,
+) {
+	if cookies != nil {
+		writeSuccessResponse(w, r, http.StatusOK, "Login successful", map[string]interface{}{
+			"user":       user,
+			"csrf_token": cookies.SetSession(w, token),
+		})
+		return
+	}
+
+	response := map[string]interface{}{
+		"token": token,
+		"user":  user,
+	}
+	if refreshTokens != nil {
+		refreshToken, expiresAt, err := refreshTokens.Issue(r.Context(), user.ID, token, rememberMe, sessionClientOf(r))
+		if err != nil {
+			log.Error("Failed to issue refresh token", "user_id", user.ID, "error", err)
+			handleServiceError(w, r, err)
+			return
+		}
+		response["refresh_token"] = refreshToken
+		response["refresh_expires_at"] = expiresAt
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Login successful", response)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+)
+
+// LoginThrottleHandler handles the public login lockout status check
+type LoginThrottleHandler struct {
+	throttleService domain.LoginThrottleService
+}
+
+// NewLoginThrottleHandler creates a new login throttle handler
+func NewLoginThrottleHandler(throttleService domain.LoginThrottleService) *LoginThrottleHandler {
+	return &LoginThrottleHandler{
+		throttleService: throttleService,
+	}
+}
+
+// GetLockoutStatus handles telling a user whether their email is locked out
+// of logging in, how many attempts remain and when to retry
+func (h *LoginThrottleHandler) GetLockoutStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.throttleService.LockoutStatus(r.Context(), r.URL.Query().Get("email"), middleware.GetClientIP(r))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Lockout status retrieved successfully", status)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// NoteHandler handles HTTP requests for internal notes on user accounts
+type NoteHandler struct {
+	noteService domain.NoteService
+}
+
+// NewNoteHandler creates a new note handler
+func NewNoteHandler(noteService domain.NoteService) *NoteHandler {
+	return &NoteHandler{
+		noteService: noteService,
+	}
+}
+
+// ListNotes handles listing the notes on a user, newest first
+func (h *NoteHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
+	notes, err := h.noteService.ListNotes(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Notes retrieved successfully", notes)
+}
+
+// AddNote handles attaching a note to a user, authored by the caller
+func (h *NoteHandler) AddNote(w http.ResponseWriter, r *http.Request) {
+	var req domain.NoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	note, err := h.noteService.AddNote(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"], &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Note added successfully", note)
+}
+
+// UpdateNote handles editing a note; the previous text is kept in its history
+func (h *NoteHandler) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	var req domain.NoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	note, err := h.noteService.UpdateNote(r.Context(), getUserIDFromContext(r), vars["id"], vars["noteId"], &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Note updated successfully", note)
+}
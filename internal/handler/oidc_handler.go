@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+)
+
+// OIDCHandler handles HTTP requests for single sign-on through an OpenID Connect provider
+type OIDCHandler struct {
+	oidcService   domain.OIDCService
+	refreshTokens domain.RefreshTokenService
+	cookies       *middleware.CookieSessions
+	logger        *logger.Logger
+}
+
+// NewOIDCHandler creates a new single sign-on handler. refreshTokens may be
+// nil when refresh tokens are not enabled.
+func NewOIDCHandler(oidcService domain.OIDCService, refreshTokens domain.RefreshTokenService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:   oidcService,
+		refreshTokens: refreshTokens,
+		logger:        logger.GetGlobal().ForComponent("oidc-handler"),
+	}
+}
+
+// SetCookieSessions sets the token of logins in a session cookie in cookie
+// auth mode. It must be called before the handler starts serving requests.
+func (h *OIDCHandler) SetCookieSessions(cookies *middleware.CookieSessions) {
+	h.cookies = cookies
+}
+
+// Login handles starting a single sign-on. Browsers are redirected to the
+// provider; clients that accept JSON get the provider URL instead.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.oidcService.BeginLogin(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		writeSuccessResponse(w, r, http.StatusOK, "Single sign-on started", map[string]interface{}{
+			"authorization_url": authURL,
+		})
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles the provider's redirect back with an authorization code,
+// answering like a password login
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, getRequestID(r))
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		log.Warn("Identity provider refused the login", "error", providerErr, "description", query.Get("error_description"))
+		handleServiceError(w, r, domain.ErrOIDCFailed)
+		return
+	}
+
+	token, user, err := h.oidcService.FinishLogin(r.Context(), query.Get("code"), query.Get("state"))
+	if err != nil {
+		log.Warn("Single sign-on failed", "error", err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeLoginResponse(w, r, log, h.refreshTokens, h.cookies, token, user, false)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+)
+
+// PasswordResetHandler handles HTTP requests for the emailed password reset flow
+type PasswordResetHandler struct {
+	resetService domain.PasswordResetService
+	logger       *logger.Logger
+}
+
+// NewPasswordResetHandler creates a new password reset handler
+func NewPasswordResetHandler(resetService domain.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		resetService: resetService,
+		logger:       logger.GetGlobal().ForComponent("password-reset-handler"),
+	}
+}
+
+// ForgotPassword handles requesting a reset link. The response is the same
+// whether or not the email is registered.
+func (h *PasswordResetHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, getRequestID(r))
+
+	var req domain.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.resetService.RequestReset(r.Context(), req.Email, middleware.GetClientIP(r)); err != nil {
+		log.Warn("Password reset request failed", "error", err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusAccepted, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// ResetPassword handles setting a new password with an emailed reset token
+func (h *PasswordResetHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.resetService.ResetPassword(r.Context(), &req); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Password reset successfully", nil)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// PolicyHandler handles HTTP requests inspecting the permission policy
+type PolicyHandler struct {
+	engine domain.PolicyEngine
+	users  domain.UserQueryService
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(engine domain.PolicyEngine, users domain.UserQueryService) *PolicyHandler {
+	return &PolicyHandler{
+		engine: engine,
+		users:  users,
+	}
+}
+
+// GetPolicy handles reporting the permission rules and what each role holds
+func (h *PolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	grants := h.engine.Grants()
+	roles := make([]string, 0, len(grants))
+	for role := range grants {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	overview := &domain.PolicyOverview{
+		Permissions: domain.Permissions,
+		Grants:      grants,
+		Roles:       make(map[string][]string, len(roles)),
+	}
+	for _, role := range roles {
+		overview.Roles[role] = h.engine.Permissions(role)
+	}
+	writeSuccessResponse(w, r, http.StatusOK, "Policy retrieved successfully", overview)
+}
+
+// GetUserPermissions handles reporting the permissions a user holds through their role
+func (h *PolicyHandler) GetUserPermissions(w http.ResponseWriter, r *http.Request) {
+	user, err := h.users.GetUserByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Permissions retrieved successfully", &domain.EffectivePermissions{
+		UserID:      user.ID,
+		Role:        user.Role,
+		Permissions: h.engine.Permissions(user.Role),
+	})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// PreferencesHandler handles HTTP requests for the caller's display preferences
+type PreferencesHandler struct {
+	prefsService domain.PreferencesService
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(prefsService domain.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{
+		prefsService: prefsService,
+	}
+}
+
+// GetPreferences handles getting the caller's display preferences
+func (h *PreferencesHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	prefs, err := h.prefsService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Preferences retrieved successfully", prefs)
+}
+
+// UpdatePreferences handles changing the caller's display preferences
+func (h *PreferencesHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	var req domain.UpdatePreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	prefs, err := h.prefsService.UpdatePreferences(r.Context(), userID, &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Preferences updated successfully", prefs)
+}
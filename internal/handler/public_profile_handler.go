@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// PublicProfileHandler handles unauthenticated profile discovery requests
+type PublicProfileHandler struct {
+	profileService domain.PublicProfileService
+}
+
+// NewPublicProfileHandler creates a new public profile handler
+func NewPublicProfileHandler(profileService domain.PublicProfileService) *PublicProfileHandler {
+	return &PublicProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// GetProfile handles getting a user's public profile
+func (h *PublicProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.profileService.GetProfile(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Profile retrieved successfully", profile)
+}
+
+// WebFinger handles resolving an acct: URI. The answer is a bare JSON
+// Resource Descriptor, as RFC 7033 requires, not the usual envelope.
+func (h *PublicProfileHandler) WebFinger(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	resource := query.Get("resource")
+	if resource == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "resource query parameter is required", domain.ErrValidationFailed.Code)
+		return
+	}
+
+	jrd, err := h.profileService.WebFinger(r.Context(), resource, query["rel"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jrd+json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(jrd)
+}
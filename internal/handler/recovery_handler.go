@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+)
+
+// RecoveryHandler handles HTTP requests for security-question account recovery
+type RecoveryHandler struct {
+	recoveryService domain.AccountRecoveryService
+	logger          *logger.Logger
+}
+
+// NewRecoveryHandler creates a new account recovery handler
+func NewRecoveryHandler(recoveryService domain.AccountRecoveryService) *RecoveryHandler {
+	return &RecoveryHandler{
+		recoveryService: recoveryService,
+		logger:          logger.GetGlobal().ForComponent("recovery-handler"),
+	}
+}
+
+// GetQuestionCatalog handles listing the questions users may choose from
+func (h *RecoveryHandler) GetQuestionCatalog(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"questions": h.recoveryService.SecurityQuestionCatalog(),
+	}
+	writeSuccessResponse(w, r, http.StatusOK, "Security questions retrieved successfully", response)
+}
+
+// SetSecurityQuestions handles configuring the caller's recovery questions
+func (h *RecoveryHandler) SetSecurityQuestions(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, getRequestID(r))
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	var req domain.SetSecurityQuestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn("Invalid request body for security questions", "error", err)
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.recoveryService.SetSecurityQuestions(r.Context(), userID, &req); err != nil {
+		log.Warn("Failed to set security questions", "user_id", userID, "error", err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Security questions updated successfully", nil)
+}
+
+// GetRecoveryQuestions handles fetching the questions for an account
+func (h *RecoveryHandler) GetRecoveryQuestions(w http.ResponseWriter, r *http.Request) {
+	var req domain.RecoveryQuestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	questions, err := h.recoveryService.GetRecoveryQuestions(r.Context(), req.Email)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Recovery questions retrieved successfully", map[string]interface{}{
+		"questions": questions,
+	})
+}
+
+// VerifyAnswers handles answering recovery questions to obtain a reset token
+func (h *RecoveryHandler) VerifyAnswers(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, getRequestID(r))
+
+	var req domain.VerifyRecoveryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	token, err := h.recoveryService.VerifyAnswers(r.Context(), &req, middleware.GetClientIP(r))
+	if err != nil {
+		log.Warn("Account recovery verification failed", "email", req.Email, "error", err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Recovery answers verified", map[string]string{
+		"reset_token": token,
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+func (h *RecoveryHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.recoveryService.ResetPassword(r.Context(), &req); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Password reset successfully", nil)
+}
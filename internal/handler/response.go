@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+	"demo-go/internal/response"
+)
+
+// Shared response helpers used by all handlers
+
+func getUserIDFromContext(r *http.Request) string {
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok && userID != "" {
+		return userID
+	}
+	if userID := r.Context().Value("user_id"); userID != nil {
+		if id, ok := userID.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+func getUserRoleFromContext(r *http.Request) string {
+	role, _ := middleware.GetUserRoleFromContext(r.Context())
+	return role
+}
+
+func handleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var paramsErr *domain.InvalidParamsError
+	if errors.As(err, &paramsErr) {
+		response.ErrorWithDetails(w, r, http.StatusBadRequest, "Invalid query parameters", domain.ErrValidationFailed.Code, paramsErr.Params)
+		return
+	}
+
+	var fieldsErr *domain.InvalidFieldsError
+	if errors.As(err, &fieldsErr) {
+		response.ErrorWithDetails(w, r, http.StatusBadRequest, fieldsErr.Error(), domain.ErrValidationFailed.Code, fieldsErr.Fields)
+		return
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code {
+		case "USER_NOT_FOUND", "NOTE_NOT_FOUND", "SNAPSHOT_NOT_FOUND", "PASSKEY_NOT_FOUND", "API_KEY_NOT_FOUND", "ROUTE_OVERRIDE_NOT_FOUND",
+			"SESSION_NOT_FOUND", "CLIENT_NOT_FOUND", "ROLE_NOT_FOUND", "EMAIL_NOT_FOUND", "ANNOUNCEMENT_NOT_FOUND", "INVITE_NOT_FOUND":
+			writeErrorResponse(w, r, http.StatusNotFound, domainErr.Message, domainErr.Code)
+		case "USER_ALREADY_EXISTS", "IMPORT_CONFLICT", "ROLE_EXISTS", "ROLE_IN_USE", "ROLE_BUILT_IN",
+			"EMAIL_NOT_RESENDABLE", "EMAIL_NOT_SENT", "USER_BANNED":
+			writeErrorResponse(w, r, http.StatusConflict, domainErr.Message, domainErr.Code)
+		case "INVALID_CREDENTIALS":
+			writeErrorResponse(w, r, http.StatusUnauthorized, domainErr.Message, domainErr.Code)
+		case "INVALID_TOKEN":
+			writeErrorResponse(w, r, http.StatusUnauthorized, domainErr.Message, domainErr.Code)
+		case "UNAUTHORIZED":
+			writeErrorResponse(w, r, http.StatusUnauthorized, domainErr.Message, domainErr.Code)
+		case "FORBIDDEN", "ACCOUNT_SUSPENDED", "ACCOUNT_BANNED", "ROLE_NOT_ALLOWED", "WRONG_PASSWORD", "FIELD_NOT_VISIBLE",
+			"INVITE_REQUIRED", "INVALID_INVITE":
+			writeErrorResponse(w, r, http.StatusForbidden, domainErr.Message, domainErr.Code)
+		case "VALIDATION_FAILED", "CONTENT_POLICY_VIOLATION", "EMAIL_DOMAIN_NOT_ALLOWED", "INVALID_BUNDLE", "INVALID_SNAPSHOT", "INVALID_CONFIRMATION",
+			"UNKNOWN_ROLE", "INVALID_USAGE_RANGE", "INVALID_SEARCH_QUERY":
+			writeErrorResponse(w, r, http.StatusBadRequest, domainErr.Message, domainErr.Code)
+		case "RATE_LIMITED", "LOGIN_LOCKED":
+			writeErrorResponse(w, r, http.StatusTooManyRequests, domainErr.Message, domainErr.Code)
+		case "KEYS_UNAVAILABLE", "STORAGE_UNAVAILABLE", "IDP_UNAVAILABLE":
+			writeErrorResponse(w, r, http.StatusServiceUnavailable, domainErr.Message, domainErr.Code)
+		case "REQUEST_TIMEOUT":
+			writeErrorResponse(w, r, http.StatusGatewayTimeout, domainErr.Message, domainErr.Code)
+		default:
+			writeErrorResponse(w, r, http.StatusInternalServerError, "Internal server error", "INTERNAL_ERROR")
+		}
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		// A storage call ran out of the request's time budget
+		writeErrorResponse(w, r, http.StatusGatewayTimeout, domain.ErrRequestTimeout.Message, domain.ErrRequestTimeout.Code)
+	} else {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "Internal server error", "INTERNAL_ERROR")
+	}
+}
+
+func writeSuccessResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, data interface{}) {
+	response.Success(w, r, statusCode, message, data)
+}
+
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message, code string) {
+	response.Error(w, r, statusCode, message, code)
+}
+
+func getRequestID(r *http.Request) string {
+	if requestID := r.Header.Get("X-Request-ID"); requestID != "" {
+		return requestID
+	}
+	if requestID, ok := response.RequestIDFromContext(r.Context()); ok {
+		return requestID
+	}
+	return "unknown"
+}
+
+// writeLoginThrottleHeaders tells clients how many login attempts remain and,
+// once locked out, when to retry
+func writeLoginThrottleHeaders(w http.ResponseWriter, err error) {
+	var attemptErr *domain.LoginAttemptError
+	if !errors.As(err, &attemptErr) {
+		return
+	}
+
+	w.Header().Set("X-Auth-Remaining-Attempts", strconv.Itoa(attemptErr.Status.RemainingAttempts))
+	if attemptErr.Status.Locked {
+		retryAfter := strconv.Itoa(attemptErr.Status.RetryAfterSeconds)
+		w.Header().Set("X-Auth-Retry-After", retryAfter)
+		w.Header().Set("Retry-After", retryAfter)
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/retention"
+)
+
+// RetentionHandler exposes the data retention schedule and dry-run report
+type RetentionHandler struct {
+	engine *retention.Engine
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(engine *retention.Engine) *RetentionHandler {
+	return &RetentionHandler{
+		engine: engine,
+	}
+}
+
+// GetRetentionReport handles showing the retention schedule, the outcome of
+// the last purge and what a purge would delete now. Nothing is deleted.
+func (h *RetentionHandler) GetRetentionReport(w http.ResponseWriter, r *http.Request) {
+	status, err := h.engine.Status(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Retention report generated successfully", status)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/fieldpolicy"
+
+	"github.com/gorilla/mux"
+)
+
+// RoleHandler handles HTTP requests managing roles and assigning them
+type RoleHandler struct {
+	roleService domain.RoleService
+	users       domain.UserCommandService
+	fields      *fieldpolicy.Filter
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService domain.RoleService, users domain.UserCommandService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		users:       users,
+		fields:      fieldpolicy.New(roleService),
+	}
+}
+
+// ListRoles handles listing the roles with their permissions
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleService.ListRoles(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Roles retrieved successfully", map[string]interface{}{
+		"roles": roles,
+	})
+}
+
+// GetRole handles retrieving a role
+func (h *RoleHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	role, err := h.roleService.GetRole(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Role retrieved successfully", role)
+}
+
+// CreateRole handles defining a role
+func (h *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	role, err := h.roleService.CreateRole(r.Context(), getUserIDFromContext(r), getUserRoleFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Role created successfully", role)
+}
+
+// UpdateRole handles changing a role's description or permissions
+func (h *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(r.Context(), getUserIDFromContext(r), getUserRoleFromContext(r), mux.Vars(r)["name"], &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Role updated successfully", role)
+}
+
+// DeleteRole handles deleting a role no user holds
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	if err := h.roleService.DeleteRole(r.Context(), getUserIDFromContext(r), mux.Vars(r)["name"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Role deleted successfully", nil)
+}
+
+// AssignRole handles giving a user a role. It goes through the admin user
+// update, so the change is audited and admins cannot change their own role.
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	var req domain.AssignRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if !h.roleService.HasRole(req.Role) {
+		handleServiceError(w, r, domain.ErrUnknownRole)
+		return
+	}
+
+	user, err := h.users.UpdateUser(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"], &domain.UpdateUserRequest{Role: &req.Role})
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Role assigned successfully", h.fields.User(r.Context(), user))
+}
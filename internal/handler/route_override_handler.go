@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteOverrideHandler handles HTTP requests for runtime route overrides
+type RouteOverrideHandler struct {
+	overrideService domain.RouteOverrideService
+	skipRules       func() []string
+}
+
+// NewRouteOverrideHandler creates a new route override handler. skipRules
+// returns the skip rules of the configuration and the route table, which are
+// listed next to the overrides.
+func NewRouteOverrideHandler(overrideService domain.RouteOverrideService, skipRules func() []string) *RouteOverrideHandler {
+	return &RouteOverrideHandler{
+		overrideService: overrideService,
+		skipRules:       skipRules,
+	}
+}
+
+// ListOverrides handles listing the skip rules and the overrides in effect
+func (h *RouteOverrideHandler) ListOverrides(w http.ResponseWriter, r *http.Request) {
+	overrides, err := h.overrideService.ListOverrides(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Route overrides retrieved successfully", map[string]interface{}{
+		"skip_rules": h.skipRules(),
+		"overrides":  overrides,
+	})
+}
+
+// CreateOverride handles opening or closing endpoints
+func (h *RouteOverrideHandler) CreateOverride(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateRouteOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	override, err := h.overrideService.CreateOverride(r.Context(), getUserIDFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Route override created successfully", override)
+}
+
+// DeleteOverride handles removing an override
+func (h *RouteOverrideHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	if err := h.overrideService.DeleteOverride(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Route override deleted successfully", nil)
+}
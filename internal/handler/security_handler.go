@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// SecurityHandler handles HTTP requests for the security event stream
+type SecurityHandler struct {
+	securityService domain.SecurityEventService
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(securityService domain.SecurityEventService) *SecurityHandler {
+	return &SecurityHandler{
+		securityService: securityService,
+	}
+}
+
+// ListEvents handles listing security events, optionally filtered by type, minimum severity or target
+func (h *SecurityHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	params := domain.NewQueryBinder(query)
+	limit := params.Limit()
+	offset := params.Offset()
+	if err := params.Err(); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	filter := domain.SecurityEventFilter{
+		Type:        query.Get("type"),
+		MinSeverity: query.Get("severity"),
+		TargetID:    query.Get("target_id"),
+	}
+
+	events, total, err := h.securityService.ListEvents(r.Context(), filter, limit, offset)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"events": events,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Security events retrieved successfully", response)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// SessionHandler handles HTTP requests for the caller's sessions
+type SessionHandler struct {
+	sessionService domain.SessionService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionService domain.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions handles listing where the caller is signed in
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(r.Context(), userID, currentTokenID(r))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Sessions retrieved successfully", map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// RevokeSession handles signing one of the caller's sessions out
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	if !h.checkCaller(w, r) {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(r.Context(), getUserIDFromContext(r), mux.Vars(r)["id"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// RevokeOtherSessions handles signing out every session of the caller but
+// the one of the request
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	if !h.checkCaller(w, r) {
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeOtherSessions(r.Context(), getUserIDFromContext(r), currentTokenID(r))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Other sessions revoked successfully", map[string]interface{}{
+		"revoked": revoked,
+	})
+}
+
+// checkCaller refuses requests that were not made with an access token;
+// without one the current session is unknown, and API keys are not sessions
+func (h *SessionHandler) checkCaller(w http.ResponseWriter, r *http.Request) bool {
+	if getUserIDFromContext(r) == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return false
+	}
+	if currentTokenID(r) == "" {
+		writeErrorResponse(w, r, http.StatusForbidden, "Managing sessions requires an access token", "FORBIDDEN")
+		return false
+	}
+	return true
+}
+
+// currentTokenID returns the ID of the access token of the request, if any
+func currentTokenID(r *http.Request) string {
+	if claims, ok := middleware.GetTokenClaimsFromContext(r.Context()); ok {
+		return claims.ID
+	}
+	return ""
+}
+
+// sessionClientOf describes the client of a sign-in or refresh request
+func sessionClientOf(r *http.Request) domain.SessionClient {
+	return domain.SessionClient{
+		IPAddress: middleware.GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"demo-go/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// SnapshotHandler handles HTTP requests for snapshots of the users collection
+type SnapshotHandler struct {
+	snapshotService domain.SnapshotService
+}
+
+// NewSnapshotHandler creates a new snapshot handler
+func NewSnapshotHandler(snapshotService domain.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+	}
+}
+
+// ListSnapshots handles listing the stored snapshot names, oldest first
+func (h *SnapshotHandler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	names, err := h.snapshotService.List(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Snapshots retrieved successfully", map[string]interface{}{"snapshots": names})
+}
+
+// CreateSnapshot handles dumping every user into a new snapshot
+func (h *SnapshotHandler) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	info, err := h.snapshotService.Create(r.Context(), getUserIDFromContext(r))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Snapshot created successfully", info)
+}
+
+// GetSnapshot handles describing a snapshot after verifying its checksum
+func (h *SnapshotHandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	info, err := h.snapshotService.Get(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Snapshot retrieved successfully", info)
+}
+
+// RestoreSnapshot handles restoring the users collection from a snapshot.
+// Without a confirmation token nothing is changed: the response previews the
+// restore and carries the token that confirms it.
+func (h *SnapshotHandler) RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req domain.RestoreSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	actorID := getUserIDFromContext(r)
+	name := mux.Vars(r)["name"]
+	if req.ConfirmationToken == "" {
+		preview, err := h.snapshotService.PreviewRestore(r.Context(), actorID, name)
+		if err != nil {
+			handleServiceError(w, r, err)
+			return
+		}
+		writeSuccessResponse(w, r, http.StatusAccepted, "Repeat the request with the confirmation token to restore", preview)
+		return
+	}
+
+	result, err := h.snapshotService.Restore(r.Context(), actorID, name, req.ConfirmationToken)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Snapshot restored successfully", result)
+}
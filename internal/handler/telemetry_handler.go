@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/telemetry"
+)
+
+// TelemetryHandler exposes the telemetry payload for inspection
+type TelemetryHandler struct {
+	collector *telemetry.Collector
+}
+
+// NewTelemetryHandler creates a new telemetry handler
+func NewTelemetryHandler(collector *telemetry.Collector) *TelemetryHandler {
+	return &TelemetryHandler{
+		collector: collector,
+	}
+}
+
+// GetTelemetry handles showing whether telemetry is enabled and the exact
+// payload it would send, whether or not it is enabled
+func (h *TelemetryHandler) GetTelemetry(w http.ResponseWriter, r *http.Request) {
+	status, err := h.collector.Status(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Telemetry status retrieved successfully", status)
+}
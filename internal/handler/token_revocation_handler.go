@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// TokenRevocationHandler handles logging out and revoking access tokens
+type TokenRevocationHandler struct {
+	revocations   domain.TokenRevocationService
+	refreshTokens domain.RefreshTokenService
+	cookies       *middleware.CookieSessions
+}
+
+// NewTokenRevocationHandler creates a new token revocation handler.
+// refreshTokens may be nil when refresh tokens are not enabled.
+func NewTokenRevocationHandler(revocations domain.TokenRevocationService, refreshTokens domain.RefreshTokenService) *TokenRevocationHandler {
+	return &TokenRevocationHandler{
+		revocations:   revocations,
+		refreshTokens: refreshTokens,
+	}
+}
+
+// SetCookieSessions makes logout clear the session cookie of cookie auth
+// mode. It must be called before the handler starts serving requests.
+func (h *TokenRevocationHandler) SetCookieSessions(cookies *middleware.CookieSessions) {
+	h.cookies = cookies
+}
+
+// Logout handles revoking the bearer token of the request, and the refresh
+// token in the body when one is given
+func (h *TokenRevocationHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetTokenClaimsFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Logout requires a bearer token", "The request was not authenticated with an access token")
+		return
+	}
+
+	var req domain.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.revocations.RevokeToken(r.Context(), claims); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+	if req.RefreshToken != "" && h.refreshTokens != nil {
+		if err := h.refreshTokens.Revoke(r.Context(), claims.UserID, req.RefreshToken); err != nil {
+			handleServiceError(w, r, err)
+			return
+		}
+	}
+	if h.cookies != nil {
+		h.cookies.ClearSession(w)
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Logged out successfully", nil)
+}
+
+// RevokeUserTokens handles revoking every access and refresh token issued to a user so far (admin only)
+func (h *TokenRevocationHandler) RevokeUserTokens(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+	if err := h.revocations.RevokeUserTokens(r.Context(), getUserIDFromContext(r), userID); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "User tokens revoked successfully", nil)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"demo-go/internal/domain"
+)
+
+// TransferHandler handles HTTP requests for exporting and importing user accounts
+type TransferHandler struct {
+	transferService domain.AccountTransferService
+}
+
+// NewTransferHandler creates a new account transfer handler
+func NewTransferHandler(transferService domain.AccountTransferService) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+	}
+}
+
+// ExportUsers handles exporting users into a signed bundle (admin only)
+func (h *TransferHandler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	var req domain.ExportUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	bundle, err := h.transferService.Export(r.Context(), getUserIDFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Users exported successfully", bundle)
+}
+
+// ImportUsers handles importing users from a signed bundle (admin only)
+func (h *TransferHandler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	var req domain.ImportUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	results, err := h.transferService.Import(r.Context(), getUserIDFromContext(r), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Outcome]++
+	}
+
+	response := map[string]interface{}{
+		"results": results,
+		"counts":  counts,
+		"dry_run": req.DryRun,
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Import processed", response)
+}
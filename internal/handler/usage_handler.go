@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/usage"
+)
+
+// UsageHandler handles HTTP requests reporting API usage
+type UsageHandler struct {
+	usage *usage.Tracker
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(tracker *usage.Tracker) *UsageHandler {
+	return &UsageHandler{
+		usage: tracker,
+	}
+}
+
+// ListUsage handles reporting the usage of every principal, optionally of
+// one user, principal type or principal
+func (h *UsageHandler) ListUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	h.writeUsage(w, r, domain.UsageFilter{
+		UserID:        query.Get("user_id"),
+		PrincipalType: query.Get("principal_type"),
+		PrincipalID:   query.Get("principal_id"),
+		From:          query.Get("from"),
+		To:            query.Get("to"),
+	})
+}
+
+// MyUsage handles reporting the usage of the current user, made with their
+// tokens and API keys
+func (h *UsageHandler) MyUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	h.writeUsage(w, r, domain.UsageFilter{
+		UserID:        getUserIDFromContext(r),
+		PrincipalType: query.Get("principal_type"),
+		PrincipalID:   query.Get("principal_id"),
+		From:          query.Get("from"),
+		To:            query.Get("to"),
+	})
+}
+
+// writeUsage answers with the matching daily usage and its totals
+func (h *UsageHandler) writeUsage(w http.ResponseWriter, r *http.Request, filter domain.UsageFilter) {
+	switch filter.PrincipalType {
+	case "", domain.UsagePrincipalUser, domain.UsagePrincipalAPIKey, domain.UsagePrincipalClient:
+	default:
+		params := domain.NewQueryBinder(r.URL.Query())
+		params.Invalid("principal_type", "must be one of user, api_key or client")
+		handleServiceError(w, r, params.Err())
+		return
+	}
+
+	records, err := h.usage.List(r.Context(), filter)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Usage retrieved successfully", map[string]interface{}{
+		"usage":  records,
+		"totals": domain.SumUsage(records),
+	})
+}
@@ -3,30 +3,102 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-	"strconv"
+	"time"
 
 	"demo-go/internal/domain"
+	"demo-go/internal/fieldpolicy"
 	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/response"
 
 	"github.com/gorilla/mux"
 )
 
 // UserHandler handles HTTP requests for user operations
 type UserHandler struct {
-	userService domain.UserService
-	logger      *logger.Logger
+	queries  domain.UserQueryService
+	commands domain.UserCommandService
+	logger   *logger.Logger
+
+	// refreshTokens issues refresh tokens at login and rotates them on /auth/refresh
+	refreshTokens domain.RefreshTokenService
+	// revocations signs the user out everywhere after a password change,
+	// suspension or ban
+	revocations domain.TokenRevocationService
+	// cookies holds the token of logins in a session cookie in cookie auth mode
+	cookies *middleware.CookieSessions
+	// fields hides the private fields of other users from viewers not allowed to see them
+	fields *fieldpolicy.Filter
+	// authz checks the permissions the admin routes need beyond their own,
+	// such as role.assign to change roles
+	authz domain.Authorizer
+
+	// Dependencies whose state is reported by /health
+	healthChecks map[string]domain.HealthChecker
+	// announcements adds the announcement in effect to /health
+	announcements domain.AnnouncementService
 }
 
 // NewUserHandler creates a new user handler
 func NewUserHandler(userService domain.UserService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		logger:      logger.GetGlobal().ForComponent("handler"),
+		queries:      userService,
+		commands:     userService,
+		logger:       logger.GetGlobal().ForComponent("handler"),
+		healthChecks: make(map[string]domain.HealthChecker),
 	}
 }
 
+// AddHealthCheck registers a dependency to report in /health under the given
+// name. It must be called before the handler starts serving requests.
+func (h *UserHandler) AddHealthCheck(name string, checker domain.HealthChecker) {
+	h.healthChecks[name] = checker
+}
+
+// SetAnnouncementService includes the announcement in effect in /health, so
+// status pages show it. It must be called before the handler starts serving
+// requests.
+func (h *UserHandler) SetAnnouncementService(announcements domain.AnnouncementService) {
+	h.announcements = announcements
+}
+
+// SetRefreshTokenService enables refresh tokens. Logins then also return a
+// refresh token, and /auth/refresh exchanges it for a new token pair. It must
+// be called before the handler starts serving requests.
+func (h *UserHandler) SetRefreshTokenService(refreshTokens domain.RefreshTokenService) {
+	h.refreshTokens = refreshTokens
+}
+
+// SetTokenRevocationService makes password changes revoke every token the
+// user holds. It must be called before the handler starts serving requests.
+func (h *UserHandler) SetTokenRevocationService(revocations domain.TokenRevocationService) {
+	h.revocations = revocations
+}
+
+// SetCookieSessions switches logins to cookie auth mode: the token is set in
+// a session cookie instead of being returned. It must be called before the
+// handler starts serving requests.
+func (h *UserHandler) SetCookieSessions(cookies *middleware.CookieSessions) {
+	h.cookies = cookies
+}
+
+// SetFieldFilter hides the private fields of the users returned to viewers
+// whose role lacks the permission for them. It must be called before the
+// handler starts serving requests.
+func (h *UserHandler) SetFieldFilter(fields *fieldpolicy.Filter) {
+	h.fields = fields
+}
+
+// SetAuthorizer makes role changes through the admin update and bulk routes
+// require role.assign, on top of the routes' own permissions. It must be
+// called before the handler starts serving requests.
+func (h *UserHandler) SetAuthorizer(authz domain.Authorizer) {
+	h.authz = authz
+}
+
 // Register handles user registration
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	log := h.logger.ForRequest(r.Method, r.URL.Path, h.getRequestID(r))
@@ -34,21 +106,21 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req domain.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Warn("Invalid request body for registration", "error", err)
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	log.Info("User registration attempt", "email", req.Email)
 
-	user, err := h.userService.Register(r.Context(), &req)
+	user, err := h.commands.Register(r.Context(), &req)
 	if err != nil {
 		log.Error("User registration failed", "email", req.Email, "error", err)
-		h.handleServiceError(w, err)
+		h.handleServiceError(w, r, err)
 		return
 	}
 
 	log.Info("User registered successfully", "user_id", user.ID, "email", user.Email)
-	h.writeSuccessResponse(w, http.StatusCreated, "User registered successfully", user)
+	h.writeSuccessResponse(w, r, http.StatusCreated, "User registered successfully", user)
 }
 
 // Login handles user authentication
@@ -58,27 +130,23 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req domain.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Warn("Invalid request body for login", "error", err)
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	log.Info("User login attempt", "email", req.Email)
 
-	token, user, err := h.userService.Login(r.Context(), &req)
+	token, user, err := h.commands.Login(r.Context(), &req)
 	if err != nil {
 		log.Error("User login failed", "email", req.Email, "error", err)
-		h.handleServiceError(w, err)
+		writeLoginThrottleHeaders(w, err)
+		h.handleServiceError(w, r, err)
 		return
 	}
 
 	log.Info("User logged in successfully", "user_id", user.ID, "email", user.Email)
 
-	response := map[string]interface{}{
-		"token": token,
-		"user":  user,
-	}
-
-	h.writeSuccessResponse(w, http.StatusOK, "Login successful", response)
+	writeLoginResponse(w, r, log, h.refreshTokens, h.cookies, token, user, req.RememberMe)
 }
 
 // GetProfile handles getting user profile
@@ -88,21 +156,21 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 	if userID == "" {
 		log.Warn("Unauthorized profile access attempt")
-		h.writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		h.writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
 		return
 	}
 
 	log.Debug("Getting user profile", "user_id", userID)
 
-	user, err := h.userService.GetProfile(r.Context(), userID)
+	user, err := h.queries.GetProfile(r.Context(), userID)
 	if err != nil {
 		log.Error("Failed to get user profile", "user_id", userID, "error", err)
-		h.handleServiceError(w, err)
+		h.handleServiceError(w, r, err)
 		return
 	}
 
 	log.Info("User profile retrieved successfully", "user_id", userID)
-	h.writeSuccessResponse(w, http.StatusOK, "Profile retrieved successfully", user)
+	h.writeSuccessResponse(w, r, http.StatusOK, "Profile retrieved successfully", user)
 }
 
 // UpdateProfile handles updating user profile
@@ -112,63 +180,115 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 	if userID == "" {
 		log.Warn("Unauthorized profile update attempt")
-		h.writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		h.writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
 		return
 	}
 
 	var req domain.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Warn("Invalid request body for profile update", "user_id", userID, "error", err)
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
 	log.Info("Profile update attempt", "user_id", userID)
 
-	user, err := h.userService.UpdateProfile(r.Context(), userID, &req)
+	// The changed fields are reported in the response meta
+	ctx, _ := domain.WithChangeSet(r.Context())
+	r = r.WithContext(ctx)
+
+	user, err := h.commands.UpdateProfile(ctx, userID, &req)
 	if err != nil {
 		log.Error("Profile update failed", "user_id", userID, "error", err)
-		h.handleServiceError(w, err)
+		h.handleServiceError(w, r, err)
 		return
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "Profile updated successfully", user)
+	h.writeSuccessResponse(w, r, http.StatusOK, "Profile updated successfully", user)
 }
 
-// GetUsers handles getting all users (admin only)
-func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10 // default
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+// ChangePassword handles changing the caller's password. Every session of the
+// user, including the one making the request, ends, so clients log in again.
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, h.getRequestID(r))
+
+	userID := h.getUserIDFromContext(r)
+	if userID == "" {
+		log.Warn("Unauthorized password change attempt")
+		h.writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	var req domain.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn("Invalid request body for password change", "user_id", userID, "error", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.commands.ChangePassword(r.Context(), userID, &req); err != nil {
+		log.Warn("Password change failed", "user_id", userID, "error", err)
+		h.handleServiceError(w, r, err)
+		return
 	}
 
-	offset := 0 // default
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
+	if h.revocations != nil {
+		if err := h.revocations.RevokeUserTokens(r.Context(), userID, userID); err != nil {
+			log.Error("Password changed but existing tokens were not revoked", "user_id", userID, "error", err)
+			h.writeErrorResponse(w, r, http.StatusInternalServerError, "Password changed, but existing sessions could not be signed out", "INTERNAL_ERROR")
+			return
 		}
 	}
 
-	users, total, err := h.userService.GetUsers(r.Context(), limit, offset)
+	h.writeSuccessResponse(w, r, http.StatusOK, "Password changed successfully; please log in again", nil)
+}
+
+// GetUsers handles getting all users (admin only). Besides limit, offset and
+// fields it accepts the list query DSL: q (name/email search), filter[field]
+// or filter[field][op], and sort (comma-separated, "-" for descending).
+// include_total=false skips counting and reports has_more instead of total.
+func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	query, err := domain.ParseUserQuery(r.URL.Query())
 	if err != nil {
-		h.handleServiceError(w, err)
+		h.handleServiceError(w, r, err)
+		return
+	}
+	if err := h.fields.CheckQuery(r.Context(), query); err != nil {
+		h.handleServiceError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"users":  users,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
+	var users []*domain.UserResponse
+	var total int64
+	if query.HasCriteria() || query.SkipTotal {
+		users, total, err = h.queries.SearchUsers(r.Context(), query)
+	} else {
+		// Plain pagination keeps using the list path, which warms the user cache
+		users, total, err = h.queries.GetUsers(r.Context(), query.Limit, query.Offset, query.Fields...)
+	}
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	projected, err := response.Project(h.fields.Users(r.Context(), users), query.Fields)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	result := map[string]interface{}{
+		"users":  projected,
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	}
+	if query.SkipTotal {
+		result["has_more"] = total > int64(query.Offset+len(users))
+	} else {
+		result["total"] = total
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "Users retrieved successfully", response)
+	h.writeSuccessResponse(w, r, http.StatusOK, "Users retrieved successfully", result)
 }
 
 // GetUserByID handles getting a specific user by ID (admin only)
@@ -177,17 +297,29 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	userID := vars["id"]
 
 	if userID == "" {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Missing user ID", "User ID is required")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Missing user ID", "User ID is required")
 		return
 	}
 
-	user, err := h.userService.GetUserByID(r.Context(), userID)
+	fields, err := domain.ParseUserFields(r.URL.Query().Get("fields"))
 	if err != nil {
-		h.handleServiceError(w, err)
+		h.handleServiceError(w, r, err)
 		return
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "User retrieved successfully", user)
+	user, err := h.queries.GetUserByID(r.Context(), userID)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	projected, err := response.Project(h.fields.User(r.Context(), user), fields)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, r, http.StatusOK, "User retrieved successfully", projected)
 }
 
 // DeleteUser handles deleting a user (admin only)
@@ -196,130 +328,290 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID := vars["id"]
 
 	if userID == "" {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Missing user ID", "User ID is required")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Missing user ID", "User ID is required")
 		return
 	}
 
-	err := h.userService.DeleteUser(r.Context(), userID)
+	err := h.commands.DeleteUser(r.Context(), userID)
 	if err != nil {
-		h.handleServiceError(w, err)
+		h.handleServiceError(w, r, err)
 		return
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "User deleted successfully", nil)
+	h.writeSuccessResponse(w, r, http.StatusOK, "User deleted successfully", nil)
 }
 
-// RefreshToken handles token refresh
-func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	userID := h.getUserIDFromContext(r)
+// UpdateUser handles updating any user, including their role (admin only)
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
 	if userID == "" {
-		h.writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Missing user ID", "User ID is required")
 		return
 	}
 
-	token, err := h.userService.RefreshToken(r.Context(), userID)
-	if err != nil {
-		h.handleServiceError(w, err)
+	var req domain.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
-	response := map[string]string{
-		"token": token,
+	if req.Role != nil && h.authz != nil && !h.authz.Can(r.Context(), domain.PermissionRoleAssign) {
+		current, err := h.queries.GetUserByID(r.Context(), userID)
+		if err != nil {
+			h.handleServiceError(w, r, err)
+			return
+		}
+		// Sending the role a user already holds is not a change
+		if current.Role != *req.Role {
+			h.handleServiceError(w, r, domain.ErrRoleAssignDenied)
+			return
+		}
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "Token refreshed successfully", response)
-}
+	ctx, _ := domain.WithChangeSet(r.Context())
+	r = r.WithContext(ctx)
 
-// Health check endpoint
-func (h *UserHandler) Health(w http.ResponseWriter, _ *http.Request) {
-	response := map[string]interface{}{
-		"status":    "healthy",
-		"service":   "clean-architecture-api",
-		"timestamp": "2025-09-18T00:00:00Z",
+	user, err := h.commands.UpdateUser(ctx, h.getUserIDFromContext(r), userID, &req)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "Service is healthy", response)
+	h.writeSuccessResponse(w, r, http.StatusOK, "User updated successfully", h.fields.User(r.Context(), user))
 }
 
-// Helper methods
+// SuspendUser handles suspending an account with a reason (admin only)
+func (h *UserHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserStatus(w, r, domain.UserStatusSuspended, "User suspended successfully")
+}
 
-func (h *UserHandler) getUserIDFromContext(r *http.Request) string {
-	if userID := r.Context().Value("user_id"); userID != nil {
-		if id, ok := userID.(string); ok {
-			return id
+// UnsuspendUser handles lifting an account's suspension (admin only)
+func (h *UserHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserStatus(w, r, domain.UserStatusActive, "User reinstated successfully")
+}
+
+// BanUser handles banning an account for good, with a reason (admin only)
+func (h *UserHandler) BanUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserStatus(w, r, domain.UserStatusBanned, "User banned successfully")
+}
+
+// setUserStatus applies a status change. Suspended and banned users are
+// signed out everywhere, so tokens they already hold stop working.
+func (h *UserHandler) setUserStatus(w http.ResponseWriter, r *http.Request, status, message string) {
+	userID := mux.Vars(r)["id"]
+	if userID == "" {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Missing user ID", "User ID is required")
+		return
+	}
+
+	var req domain.SetUserStatusRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
 		}
 	}
-	return ""
-}
-
-func (h *UserHandler) handleServiceError(w http.ResponseWriter, err error) {
-	if domainErr, ok := err.(*domain.Error); ok {
-		switch domainErr.Code {
-		case "USER_NOT_FOUND":
-			h.writeErrorResponse(w, http.StatusNotFound, domainErr.Message, domainErr.Code)
-		case "USER_ALREADY_EXISTS":
-			h.writeErrorResponse(w, http.StatusConflict, domainErr.Message, domainErr.Code)
-		case "INVALID_CREDENTIALS":
-			h.writeErrorResponse(w, http.StatusUnauthorized, domainErr.Message, domainErr.Code)
-		case "INVALID_TOKEN":
-			h.writeErrorResponse(w, http.StatusUnauthorized, domainErr.Message, domainErr.Code)
-		case "UNAUTHORIZED":
-			h.writeErrorResponse(w, http.StatusUnauthorized, domainErr.Message, domainErr.Code)
-		case "FORBIDDEN":
-			h.writeErrorResponse(w, http.StatusForbidden, domainErr.Message, domainErr.Code)
-		case "VALIDATION_FAILED":
-			h.writeErrorResponse(w, http.StatusBadRequest, domainErr.Message, domainErr.Code)
-		default:
-			h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", "INTERNAL_ERROR")
+	req.Status = status
+
+	actorID := h.getUserIDFromContext(r)
+	user, err := h.commands.SetUserStatus(r.Context(), actorID, userID, &req)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	if status != domain.UserStatusActive && h.revocations != nil {
+		if err := h.revocations.RevokeUserTokens(r.Context(), actorID, userID); err != nil {
+			log := h.logger.ForRequest(r.Method, r.URL.Path, h.getRequestID(r))
+			log.Error("User status changed but existing tokens were not revoked", "user_id", userID, "status", status, "error", err)
+			h.writeErrorResponse(w, r, http.StatusInternalServerError, "Status changed, but existing sessions could not be signed out", "INTERNAL_ERROR")
+			return
 		}
-	} else {
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", "INTERNAL_ERROR")
 	}
+
+	h.writeSuccessResponse(w, r, http.StatusOK, message, h.fields.User(r.Context(), user))
 }
 
-func (h *UserHandler) writeSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+// BulkUserAction handles applying an admin action to many users at once
+func (h *UserHandler) BulkUserAction(w http.ResponseWriter, r *http.Request) {
+	var req domain.BulkUserActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Action == domain.BulkActionSetRole && h.authz != nil && !h.authz.Can(r.Context(), domain.PermissionRoleAssign) {
+		h.handleServiceError(w, r, domain.ErrRoleAssignDenied)
+		return
+	}
+
+	actorID := h.getUserIDFromContext(r)
+	results, err := h.commands.BulkUserAction(r.Context(), actorID, &req)
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+	if req.Action == domain.BulkActionSuspend {
+		h.revokeSuspendedTokens(r, actorID, results)
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
 	response := map[string]interface{}{
-		"success": true,
-		"message": message,
-		"data":    data,
+		"action":    req.Action,
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		// If we can't encode the response, there's not much we can do
-		// The status code has already been set
+	h.writeSuccessResponse(w, r, http.StatusOK, "Bulk action processed", response)
+}
+
+// revokeSuspendedTokens signs out every user a bulk suspend applied to, as
+// suspending one user does. Users whose tokens could not be revoked are
+// reported as failed.
+func (h *UserHandler) revokeSuspendedTokens(r *http.Request, actorID string, results []domain.BulkItemResult) {
+	if h.revocations == nil {
 		return
 	}
+	for i := range results {
+		if !results[i].Success {
+			continue
+		}
+		if err := h.revocations.RevokeUserTokens(r.Context(), actorID, results[i].ID); err != nil {
+			log := h.logger.ForRequest(r.Method, r.URL.Path, h.getRequestID(r))
+			log.Error("User suspended but existing tokens were not revoked", "user_id", results[i].ID, "error", err)
+			results[i].Success = false
+			results[i].Error = "TOKENS_NOT_REVOKED"
+		}
+	}
 }
 
-func (h *UserHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message, code string) {
-	response := map[string]interface{}{
-		"success": false,
-		"message": message,
-		"error": map[string]string{
-			"code": code,
-		},
+// GetUserStats handles reporting user totals, sign-ups and role distribution (admin only)
+func (h *UserHandler) GetUserStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.queries.GetUserStats(r.Context())
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		// If we can't encode the response, there's not much we can do
-		// The status code has already been set
+	h.writeSuccessResponse(w, r, http.StatusOK, "User statistics retrieved successfully", stats)
+}
+
+// RefreshToken exchanges the refresh token in the request body for a new
+// access token and refresh token. The presented refresh token is spent.
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	if h.refreshTokens == nil {
+		h.writeErrorResponse(w, r, http.StatusNotImplemented, "Refresh tokens are not enabled", "No refresh token service is configured")
+		return
+	}
+
+	var req domain.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
+	if req.RefreshToken == "" {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Refresh token is required", "refresh_token must not be empty")
+		return
+	}
+
+	pair, err := h.refreshTokens.Rotate(r.Context(), req.RefreshToken, sessionClientOf(r))
+	if err != nil {
+		h.handleServiceError(w, r, err)
+		return
+	}
+
+	h.writeSuccessResponse(w, r, http.StatusOK, "Token refreshed successfully", pair)
 }
 
-// Helper methods
-func (h *UserHandler) getRequestID(r *http.Request) string {
-	if requestID := r.Header.Get("X-Request-ID"); requestID != "" {
-		return requestID
+// cacheStatsProvider is implemented by user services that sit in front of a cache
+type cacheStatsProvider interface {
+	GetCacheStats(ctx context.Context) (map[string]interface{}, error)
+}
+
+// GetCacheStats handles reporting cache statistics (admin only)
+func (h *UserHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.queries.(cacheStatsProvider)
+	if !ok {
+		h.writeSuccessResponse(w, r, http.StatusOK, "Cache is not enabled", map[string]interface{}{
+			"enabled": false,
+		})
+		return
+	}
+
+	stats, err := provider.GetCacheStats(r.Context())
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Cache is unavailable", "CACHE_UNAVAILABLE")
+		return
 	}
-	if requestID := r.Context().Value("request_id"); requestID != nil {
-		if id, ok := requestID.(string); ok {
-			return id
+
+	stats["enabled"] = true
+	h.writeSuccessResponse(w, r, http.StatusOK, "Cache statistics retrieved successfully", stats)
+}
+
+// Health check endpoint. The service reports "degraded" when any registered
+// dependency is degraded; it still answers 200 since it keeps serving requests.
+func (h *UserHandler) Health(w http.ResponseWriter, r *http.Request) {
+	status := domain.HealthStatusHealthy
+	components := make(map[string]domain.ComponentHealth, len(h.healthChecks))
+	for name, checker := range h.healthChecks {
+		health := checker.CheckHealth(r.Context())
+		if health.Status != domain.HealthStatusHealthy {
+			status = domain.HealthStatusDegraded
 		}
+		components[name] = health
+	}
+
+	response := map[string]interface{}{
+		"status":    status,
+		"service":   "clean-architecture-api",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
 	}
-	return "unknown"
+	if len(components) > 0 {
+		response["components"] = components
+	}
+	if h.announcements != nil {
+		if announcement, ok := h.announcements.Current(); ok {
+			response["announcement"] = announcement.Public()
+		}
+	}
+
+	message := "Service is healthy"
+	if status != domain.HealthStatusHealthy {
+		message = "Service is degraded"
+	}
+	h.writeSuccessResponse(w, r, http.StatusOK, message, response)
+}
+
+// Helper methods
+
+func (h *UserHandler) getUserIDFromContext(r *http.Request) string {
+	return getUserIDFromContext(r)
+}
+
+func (h *UserHandler) handleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	handleServiceError(w, r, err)
+}
+
+func (h *UserHandler) writeSuccessResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	statusCode int,
+	message string,
+	data interface{},
+) {
+	writeSuccessResponse(w, r, statusCode, message, data)
+}
+
+func (h *UserHandler) writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message, code string) {
+	writeErrorResponse(w, r, statusCode, message, code)
+}
+
+func (h *UserHandler) getRequestID(r *http.Request) string {
+	return getRequestID(r)
 }
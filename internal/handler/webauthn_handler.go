@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// WebAuthnHandler handles HTTP requests for passkey registration and login
+type WebAuthnHandler struct {
+	webAuthnService domain.WebAuthnService
+	refreshTokens   domain.RefreshTokenService
+	cookies         *middleware.CookieSessions
+	logger          *logger.Logger
+}
+
+// NewWebAuthnHandler creates a new passkey handler. refreshTokens may be nil
+// when refresh tokens are not enabled.
+func NewWebAuthnHandler(webAuthnService domain.WebAuthnService, refreshTokens domain.RefreshTokenService) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		webAuthnService: webAuthnService,
+		refreshTokens:   refreshTokens,
+		logger:          logger.GetGlobal().ForComponent("webauthn-handler"),
+	}
+}
+
+// SetCookieSessions sets the token of logins in a session cookie in cookie
+// auth mode. It must be called before the handler starts serving requests.
+func (h *WebAuthnHandler) SetCookieSessions(cookies *middleware.CookieSessions) {
+	h.cookies = cookies
+}
+
+// BeginRegistration handles starting the registration of a passkey for the caller
+func (h *WebAuthnHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	options, err := h.webAuthnService.BeginRegistration(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Passkey registration started", map[string]interface{}{
+		"publicKey": options,
+	})
+}
+
+// FinishRegistration handles storing the passkey the browser created
+func (h *WebAuthnHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, getRequestID(r))
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	var req domain.FinishPasskeyRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	credential, err := h.webAuthnService.FinishRegistration(r.Context(), userID, &req)
+	if err != nil {
+		log.Warn("Passkey registration failed", "user_id", userID, "error", err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusCreated, "Passkey registered successfully", credential)
+}
+
+// ListCredentials handles listing the caller's passkeys
+func (h *WebAuthnHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	credentials, err := h.webAuthnService.ListCredentials(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Passkeys retrieved successfully", map[string]interface{}{
+		"passkeys": credentials,
+	})
+}
+
+// DeleteCredential handles removing one of the caller's passkeys
+func (h *WebAuthnHandler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		writeErrorResponse(w, r, http.StatusUnauthorized, "Unauthorized", "User ID not found in context")
+		return
+	}
+
+	if err := h.webAuthnService.DeleteCredential(r.Context(), userID, mux.Vars(r)["credentialId"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Passkey removed successfully", nil)
+}
+
+// BeginLogin handles starting a passkey login
+func (h *WebAuthnHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	var req domain.BeginPasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	options, err := h.webAuthnService.BeginLogin(r.Context(), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, r, http.StatusOK, "Passkey login started", map[string]interface{}{
+		"publicKey": options,
+	})
+}
+
+// FinishLogin handles verifying a passkey assertion, answering like a password login
+func (h *WebAuthnHandler) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.ForRequest(r.Method, r.URL.Path, getRequestID(r))
+
+	var req domain.FinishPasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	token, user, err := h.webAuthnService.FinishLogin(r.Context(), &req)
+	if err != nil {
+		log.Warn("Passkey login failed", "error", err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeLoginResponse(w, r, log, h.refreshTokens, h.cookies, token, user, false)
+}
@@ -0,0 +1,235 @@
+// Package hooks provides an extension point for integrators to run custom Go
+// code on request and domain lifecycle events. Hooks are registered at
+// startup and executed with a timeout and panic isolation so a misbehaving
+// hook cannot take down or stall the request that triggered it.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// DefaultTimeout bounds how long a single hook may run
+const DefaultTimeout = 2 * time.Second
+
+// UserHook is invoked for user lifecycle events such as registration and login
+type UserHook func(ctx context.Context, user *domain.UserResponse) error
+
+// LoginFailureHook is invoked when a login attempt is rejected. The email is
+// as supplied by the caller and may not belong to an existing account.
+type LoginFailureHook func(ctx context.Context, email string, reason error) error
+
+// SecurityEventHook is invoked after a security event is recorded, e.g. to raise alerts
+type SecurityEventHook func(ctx context.Context, event *domain.SecurityEvent) error
+
+// ResponseHook is invoked just before the response status line is written.
+// Hooks may add or change headers but must not write the body.
+type ResponseHook func(ctx context.Context, r *http.Request, statusCode int, header http.Header) error
+
+type namedUserHook struct {
+	name string
+	fn   UserHook
+}
+
+type namedLoginFailureHook struct {
+	name string
+	fn   LoginFailureHook
+}
+
+type namedSecurityEventHook struct {
+	name string
+	fn   SecurityEventHook
+}
+
+type namedResponseHook struct {
+	name string
+	fn   ResponseHook
+}
+
+// Registry holds registered hooks. It is safe for concurrent use, although
+// hooks are expected to be registered during startup.
+type Registry struct {
+	mu             sync.RWMutex
+	timeout        time.Duration
+	userRegistered []namedUserHook
+	login          []namedUserHook
+	loginFailed    []namedLoginFailureHook
+	securityEvent  []namedSecurityEventHook
+	beforeResponse []namedResponseHook
+	logger         *logger.Logger
+}
+
+// NewRegistry creates an empty hook registry
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Registry{
+		timeout: timeout,
+		logger:  logger.GetGlobal().ForComponent("hooks"),
+	}
+}
+
+// OnUserRegistered registers a hook called after a user account is created
+func (r *Registry) OnUserRegistered(name string, fn UserHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.userRegistered = append(r.userRegistered, namedUserHook{name: name, fn: fn})
+}
+
+// OnLogin registers a hook called after a user logs in successfully
+func (r *Registry) OnLogin(name string, fn UserHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.login = append(r.login, namedUserHook{name: name, fn: fn})
+}
+
+// OnLoginFailed registers a hook called after a login attempt is rejected
+func (r *Registry) OnLoginFailed(name string, fn LoginFailureHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loginFailed = append(r.loginFailed, namedLoginFailureHook{name: name, fn: fn})
+}
+
+// OnSecurityEvent registers a hook called after every recorded security event
+func (r *Registry) OnSecurityEvent(name string, fn SecurityEventHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.securityEvent = append(r.securityEvent, namedSecurityEventHook{name: name, fn: fn})
+}
+
+// BeforeResponse registers a hook called before every HTTP response is sent
+func (r *Registry) BeforeResponse(name string, fn ResponseHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.beforeResponse = append(r.beforeResponse, namedResponseHook{name: name, fn: fn})
+}
+
+// HasResponseHooks reports whether any BeforeResponse hooks are registered
+func (r *Registry) HasResponseHooks() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.beforeResponse) > 0
+}
+
+// UserRegistered runs all OnUserRegistered hooks
+func (r *Registry) UserRegistered(ctx context.Context, user *domain.UserResponse) {
+	r.mu.RLock()
+	hooks := r.userRegistered
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		fn := h.fn
+		r.run(ctx, "user-registered", h.name, func(ctx context.Context) error {
+			return fn(ctx, user)
+		})
+	}
+}
+
+// LoggedIn runs all OnLogin hooks
+func (r *Registry) LoggedIn(ctx context.Context, user *domain.UserResponse) {
+	r.mu.RLock()
+	hooks := r.login
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		fn := h.fn
+		r.run(ctx, "login", h.name, func(ctx context.Context) error {
+			return fn(ctx, user)
+		})
+	}
+}
+
+// LoginFailed runs all OnLoginFailed hooks
+func (r *Registry) LoginFailed(ctx context.Context, email string, reason error) {
+	r.mu.RLock()
+	hooks := r.loginFailed
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		fn := h.fn
+		r.run(ctx, "login-failed", h.name, func(ctx context.Context) error {
+			return fn(ctx, email, reason)
+		})
+	}
+}
+
+// SecurityEventRecorded runs all OnSecurityEvent hooks
+func (r *Registry) SecurityEventRecorded(ctx context.Context, event *domain.SecurityEvent) {
+	r.mu.RLock()
+	hooks := r.securityEvent
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		fn := h.fn
+		r.run(ctx, "security-event", h.name, func(ctx context.Context) error {
+			return fn(ctx, event)
+		})
+	}
+}
+
+// ResponseStarting runs all BeforeResponse hooks
+func (r *Registry) ResponseStarting(ctx context.Context, req *http.Request, statusCode int, header http.Header) {
+	r.mu.RLock()
+	hooks := r.beforeResponse
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		fn := h.fn
+		// Hooks work on a copy so one that times out cannot keep mutating
+		// headers that are already being written
+		draft := header.Clone()
+		if r.run(ctx, "before-response", h.name, func(ctx context.Context) error {
+			return fn(ctx, req, statusCode, draft)
+		}) {
+			for key := range header {
+				delete(header, key)
+			}
+			for key, values := range draft {
+				header[key] = values
+			}
+		}
+	}
+}
+
+// run executes a hook with a timeout, recovering from panics. Errors are
+// logged and never propagated to the caller; the result reports whether the
+// hook completed successfully within its timeout.
+func (r *Registry) run(ctx context.Context, event, name string, fn func(context.Context) error) bool {
+	log := r.logger.WithFields(map[string]interface{}{
+		"event": event,
+		"hook":  name,
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("hook panicked: %v", rec)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Warn("Hook failed", "error", err)
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		log.Warn("Hook timed out", "timeout", r.timeout)
+		return false
+	}
+}
@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+
+	"demo-go/internal/logger"
+)
+
+// Circuit states reported in Metrics
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// breaker opens the circuit after threshold consecutive failed requests.
+// Once cooldown has passed one trial request is let through: its success
+// closes the circuit, its failure opens it for another cooldown.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	logger    *logger.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	trial    bool // a trial request is in flight
+}
+
+func newBreaker(threshold int, cooldown time.Duration, log *logger.Logger) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, logger: log, now: time.Now}
+}
+
+// allow reports whether a request may be sent
+func (b *breaker) allow() bool {
+	if b.threshold < 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return true
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// success closes the circuit
+func (b *breaker) success() {
+	if b.threshold < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		b.logger.Info("Circuit closed")
+	}
+	b.failures = 0
+	b.open = false
+	b.trial = false
+}
+
+// failure counts a failed request, opening the circuit at the threshold or
+// when the trial request failed
+func (b *breaker) failure() {
+	if b.threshold < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.trial || (!b.open && b.failures >= b.threshold) {
+		b.logger.Warn("Circuit opened", "consecutive_failures", b.failures, "cooldown", b.cooldown)
+		b.open = true
+		b.openedAt = b.now()
+	}
+	b.trial = false
+}
+
+// release ends a request that neither succeeded nor failed, such as one the
+// caller cancelled, letting another trial through
+func (b *breaker) release() {
+	if b.threshold < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *breaker) state() string {
+	if b.threshold < 0 {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case !b.open:
+		return CircuitClosed
+	case b.trial || b.now().Sub(b.openedAt) >= b.cooldown:
+		return CircuitHalfOpen
+	default:
+		return CircuitOpen
+	}
+}
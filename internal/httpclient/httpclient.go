@@ -0,0 +1,310 @@
+// Package httpclient is the HTTP client for calls to other services, such as
+// identity providers, key stores and deny-lists. Each attempt is bounded by a
+// timeout, idempotent requests are retried with a growing delay, a circuit
+// breaker stops calling a service that keeps failing, trace headers are
+// propagated and outcomes are counted for each integration.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"demo-go/internal/logger"
+	"demo-go/internal/tracing"
+)
+
+// Defaults applied to zero Config fields
+const (
+	DefaultTimeout          = 10 * time.Second
+	DefaultMaxAttempts      = 3
+	DefaultRetryBackoff     = 200 * time.Millisecond
+	DefaultMaxRetryBackoff  = 5 * time.Second
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling the service while its circuit
+// is open
+var ErrCircuitOpen = errors.New("circuit open: the service is failing")
+
+// Config tunes a client. Zero fields take the defaults.
+type Config struct {
+	// Timeout bounds each attempt, including reading the response body
+	Timeout time.Duration
+	// MaxAttempts bounds the attempts of an idempotent request; 1 disables retries
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubling for each
+	// further one up to MaxRetryBackoff. A Retry-After header in seconds is
+	// honoured instead; responses asking for more than MaxRetryBackoff are
+	// returned without a retry.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// BreakerThreshold is the number of consecutive failed requests that
+	// opens the circuit; negative disables the breaker
+	BreakerThreshold int
+	// BreakerCooldown is how long the circuit stays open before one trial
+	// request is let through
+	BreakerCooldown time.Duration
+	// Transport sends the requests; http.DefaultTransport when nil
+	Transport http.RoundTripper
+}
+
+// Client is an http.Client for one integration. It can be passed wherever
+// an *http.Client is expected through its embedded Client.
+type Client struct {
+	http.Client
+	transport *transport
+}
+
+// New creates a client for the integration called name, which labels its
+// logs and metrics
+func New(name string, cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = DefaultMaxRetryBackoff
+	}
+	if cfg.BreakerThreshold == 0 {
+		cfg.BreakerThreshold = DefaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = DefaultBreakerCooldown
+	}
+
+	log := logger.GetGlobal().ForComponent("httpclient").WithField("client", name)
+	t := &transport{
+		name:    name,
+		cfg:     cfg,
+		base:    tracing.NewTransport(cfg.Transport),
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, log),
+		logger:  log,
+		sleep:   sleepContext,
+	}
+	return &Client{Client: http.Client{Transport: t}, transport: t}
+}
+
+// Metrics returns the client's counters since it was created
+func (c *Client) Metrics() Metrics {
+	return c.transport.snapshot()
+}
+
+// Metrics counts the outcomes of a client's requests
+type Metrics struct {
+	Name     string `json:"name"`
+	Requests int64  `json:"requests"`
+	// Attempts includes retries; Retries counts them alone
+	Attempts int64 `json:"attempts"`
+	Retries  int64 `json:"retries"`
+	// Failures are requests that failed after their last attempt
+	Failures int64 `json:"failures"`
+	// Rejected are requests refused because the circuit was open
+	Rejected     int64         `json:"rejected"`
+	CircuitState string        `json:"circuit_state"`
+	LastError    string        `json:"last_error,omitempty"`
+	LastFailure  *time.Time    `json:"last_failure,omitempty"`
+	TotalLatency time.Duration `json:"total_latency_ns"`
+}
+
+// transport retries, breaks the circuit and counts on top of the base transport
+type transport struct {
+	name    string
+	cfg     Config
+	base    http.RoundTripper
+	breaker *breaker
+	logger  *logger.Logger
+	sleep   func(ctx context.Context, d time.Duration) error
+
+	mu      sync.Mutex
+	metrics Metrics
+}
+
+// RoundTrip sends the request, retrying idempotent requests on network
+// errors and on 429, 502, 503 and 504 responses
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	t.count(func(m *Metrics) { m.Requests++ })
+
+	if !t.breaker.allow() {
+		t.count(func(m *Metrics) { m.Rejected++ })
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), ErrCircuitOpen)
+	}
+
+	attempts := 1
+	if retryable(req) {
+		attempts = t.cfg.MaxAttempts
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		t.count(func(m *Metrics) { m.Attempts++ })
+		resp, err = t.attempt(req)
+		if attempt >= attempts || !shouldRetry(req.Context(), resp, err) {
+			break
+		}
+
+		delay, ok := t.backoff(attempt, resp)
+		if !ok {
+			break
+		}
+		if resp != nil {
+			drain(resp.Body)
+		}
+		t.logger.Debug("Retrying request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt, "delay", delay, "error", err)
+		t.count(func(m *Metrics) { m.Retries++ })
+		if sleepErr := t.sleep(req.Context(), delay); sleepErr != nil {
+			t.breaker.release()
+			return nil, sleepErr
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				t.breaker.release()
+				return nil, bodyErr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// The caller gave up; that says nothing about the service
+		t.breaker.release()
+	case failed:
+		t.breaker.failure()
+	default:
+		t.breaker.success()
+	}
+
+	t.count(func(m *Metrics) {
+		m.TotalLatency += time.Since(start)
+		if failed {
+			now := time.Now().UTC()
+			m.Failures++
+			m.LastFailure = &now
+			if err != nil {
+				m.LastError = err.Error()
+			} else {
+				m.LastError = resp.Status
+			}
+		}
+	})
+	return resp, err
+}
+
+// attempt sends the request once within the attempt timeout. The timeout
+// keeps running while the caller reads the body and ends when it is closed.
+func (t *transport) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.cfg.Timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff is the delay after the given failed attempt. It is false when the
+// service asks to wait longer than MaxRetryBackoff, which is not retried.
+func (t *transport) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay := time.Duration(seconds) * time.Second
+			return delay, delay <= t.cfg.MaxRetryBackoff
+		}
+	}
+	delay := t.cfg.RetryBackoff
+	for i := 1; i < attempt && delay < t.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > t.cfg.MaxRetryBackoff {
+		delay = t.cfg.MaxRetryBackoff
+	}
+	return delay, true
+}
+
+func (t *transport) count(update func(m *Metrics)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update(&t.metrics)
+}
+
+func (t *transport) snapshot() Metrics {
+	t.mu.Lock()
+	metrics := t.metrics
+	t.mu.Unlock()
+	metrics.Name = t.name
+	metrics.CircuitState = t.breaker.state()
+	return metrics
+}
+
+// retryable reports whether the request may be sent again: it is idempotent
+// and its body, if any, can be replayed
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry reports whether the outcome of an attempt is worth retrying
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// drain reads a little of a discarded body so the connection can be reused
+func drain(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, 4<<10)
+	_ = body.Close()
+}
+
+// cancelOnClose ends an attempt's timeout once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,111 @@
+// Package hygiene finds insecure settings that are convenient on a laptop
+// and dangerous in production: the built-in JWT secret, Redis without a
+// password on another host, CORS open to every origin, and demo mode with its
+// published passwords and periodic wipes. Findings are logged as warnings;
+// in the production environment the server refuses to start until each one
+// is fixed or acknowledged.
+package hygiene
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+)
+
+// Finding IDs, listed in SECRETS_HYGIENE_ACKNOWLEDGE to accept a finding
+const (
+	FindingDefaultJWTSecret = "default_jwt_secret"
+	FindingRedisNoPassword  = "redis_no_password"
+	FindingCORSWildcard     = "cors_wildcard"
+	FindingDemoMode         = "demo_mode"
+)
+
+// Finding is an insecure setting
+type Finding struct {
+	ID           string
+	Message      string
+	Acknowledged bool
+}
+
+// Check returns the insecure settings of cfg
+func Check(cfg *config.Config) []Finding {
+	var findings []Finding
+	add := func(id, message string) {
+		findings = append(findings, Finding{ID: id, Message: message, Acknowledged: acknowledged(cfg, id)})
+	}
+
+	if cfg.JWT.SecretKey == config.DefaultJWTSecret {
+		add(FindingDefaultJWTSecret, "JWT_SECRET is the built-in development key; anyone can sign tokens")
+	}
+	if cfg.Cache.Type == "redis" && cfg.Cache.Redis.Password == "" && !isLoopback(cfg.Cache.Redis.Address) {
+		add(FindingRedisNoPassword, fmt.Sprintf("Redis at %s is used without a password", cfg.Cache.Redis.Address))
+	}
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		if origin == "*" {
+			add(FindingCORSWildcard, "CORS allows every origin; set CORS_ALLOWED_ORIGINS")
+			break
+		}
+	}
+	if cfg.Demo.Enabled {
+		add(FindingDemoMode, "DEMO_MODE seeds accounts with known passwords and deletes every other account on reset")
+	}
+	return findings
+}
+
+// Enforce logs every finding of cfg. In the production environment it fails
+// when a finding is not acknowledged, and it always fails on acknowledged IDs
+// it does not know, so that typos do not go unnoticed.
+func Enforce(cfg *config.Config, log *logger.Logger) error {
+	for _, id := range cfg.Hygiene.Acknowledged {
+		switch id {
+		case FindingDefaultJWTSecret, FindingRedisNoPassword, FindingCORSWildcard, FindingDemoMode:
+		default:
+			return fmt.Errorf("SECRETS_HYGIENE_ACKNOWLEDGE: unknown finding %q", id)
+		}
+	}
+
+	var refused []string
+	for _, finding := range Check(cfg) {
+		if finding.Acknowledged {
+			log.Warn("Insecure setting acknowledged", "finding", finding.ID, "detail", finding.Message)
+			continue
+		}
+		log.Warn("INSECURE SETTING: "+finding.Message, "finding", finding.ID, "environment", cfg.Hygiene.Environment)
+		refused = append(refused, finding.ID)
+	}
+
+	if len(refused) > 0 && cfg.Hygiene.Production() {
+		return fmt.Errorf("refusing to start in production with insecure settings: %s (fix them or list them in SECRETS_HYGIENE_ACKNOWLEDGE)",
+			strings.Join(refused, ", "))
+	}
+	return nil
+}
+
+func acknowledged(cfg *config.Config, id string) bool {
+	for _, ack := range cfg.Hygiene.Acknowledged {
+		if ack == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopback reports whether a Redis address only reaches this host. Unix
+// sockets are local too.
+func isLoopback(address string) bool {
+	if strings.HasPrefix(address, "/") {
+		return true
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
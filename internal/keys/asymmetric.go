@@ -0,0 +1,96 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"demo-go/internal/domain"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for signing keys
+const minRSAKeyBits = 2048
+
+// isPEM reports whether a key set value is a PEM-encoded key rather than an
+// HMAC secret
+func isPEM(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN ")
+}
+
+// ParsePEMKey decodes an RSA or Ed25519 key. Private keys (PKCS #8, or PKCS #1
+// for RSA) can sign and verify; public keys (PKIX) only verify, for keys kept
+// to check tokens signed before a rotation.
+func ParsePEMKey(id, data string) (domain.SigningKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(data)))
+	if block == nil {
+		return domain.SigningKey{}, fmt.Errorf("key %q is not valid PEM", id)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return domain.SigningKey{}, fmt.Errorf("key %q has unsupported PEM type %q", id, block.Type)
+	}
+	if err != nil {
+		return domain.SigningKey{}, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	key := domain.SigningKey{ID: id}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.PrivateKey = signer
+		parsed = signer.Public()
+	}
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return domain.SigningKey{}, fmt.Errorf("key %q: RSA keys must have at least %d bits", id, minRSAKeyBits)
+		}
+		key.Algorithm = domain.KeyAlgorithmRS256
+	case ed25519.PublicKey:
+		key.Algorithm = domain.KeyAlgorithmEdDSA
+	default:
+		return domain.SigningKey{}, fmt.Errorf("key %q: only RSA and Ed25519 keys are supported", id)
+	}
+	key.PublicKey = parsed
+	return key, nil
+}
+
+// PublicJWK returns the JSON Web Key of an asymmetric key; HMAC keys have none
+func PublicJWK(key domain.SigningKey) (domain.JSONWebKey, bool) {
+	jwk := domain.JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Alg()}
+	switch public := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return domain.JSONWebKey{}, false
+	}
+	return jwk, true
+}
+
+// samePublicKey reports whether two keys have the same public key
+func samePublicKey(a, b crypto.PublicKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	equaler, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && equaler.Equal(b)
+}
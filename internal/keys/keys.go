@@ -0,0 +1,247 @@
+// Package keys supplies rotating keys, such as the HMAC, RSA or Ed25519 keys
+// JWT access tokens are signed and verified with, reloading key sets in the
+// background
+package keys
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// loadTimeout bounds one key set load
+const loadTimeout = 5 * time.Second
+
+// SecretKeySet is the key set of the static source: a single key without an
+// ID, so tokens carry no kid header
+func SecretKeySet(secret string) *domain.KeySet {
+	key := domain.SigningKey{Secret: []byte(secret)}
+	return &domain.KeySet{Current: key, Keys: map[string]domain.SigningKey{"": key}}
+}
+
+// staticProvider implements domain.KeyProvider with a fixed key set
+type staticProvider struct {
+	set *domain.KeySet
+}
+
+// NewStaticProvider creates a key provider that always returns set
+func NewStaticProvider(set *domain.KeySet) domain.KeyProvider {
+	return &staticProvider{set: set}
+}
+
+// KeySet returns the fixed key set
+func (p *staticProvider) KeySet(ctx context.Context) (*domain.KeySet, error) {
+	return p.set, nil
+}
+
+// Provider implements domain.KeyProvider by caching the key set of a Source
+// and reloading it periodically. When reloads fail, the last key set that
+// loaded keeps being served in grace mode, and reported as degraded, until
+// the grace period runs out. Until the first load succeeds, and after the
+// grace period, KeySet returns domain.ErrKeysUnavailable.
+type Provider struct {
+	name   string // what the keys are for, as used in log messages
+	source Source
+	cfg    config.JWTKeysConfig
+	logger *logger.Logger
+	now    func() time.Time
+
+	mu           sync.RWMutex
+	keys         *domain.KeySet
+	loadedAt     time.Time // last successful load
+	failingSince time.Time // first failure of the current run of failures
+	lastError    string
+	rotations    int64
+
+	started   bool
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewProvider creates the JWT key provider for source; call Start to load keys
+func NewProvider(source Source, cfg config.JWTKeysConfig) *Provider {
+	return NewNamedProvider("JWT", source, cfg)
+}
+
+// NewNamedProvider creates a key provider for keys used for name, e.g.
+// "field encryption"; call Start to load keys
+func NewNamedProvider(name string, source Source, cfg config.JWTKeysConfig) *Provider {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = config.DefaultKeyRefresh
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = config.DefaultKeyRetry
+	}
+
+	component := strings.ToLower(strings.ReplaceAll(name, " ", "-")) + "-keys"
+	return &Provider{
+		name:   name,
+		source: source,
+		cfg:    cfg,
+		logger: logger.GetGlobal().ForComponent(component),
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start loads the key set, then keeps reloading it in the background. A
+// failed first load is retried in the background too; the provider is not
+// ready until one succeeds.
+func (p *Provider) Start() {
+	p.mu.Lock()
+	if p.started {
+		p.mu.Unlock()
+		return
+	}
+	p.started = true
+	p.mu.Unlock()
+
+	p.logger.Info(p.name+" key rotation enabled", "source", p.cfg.Source, "refresh_interval", p.cfg.RefreshInterval)
+	err := p.Refresh(context.Background())
+	go p.run(err)
+}
+
+// Close stops background reloads and waits for an in-flight load, or for ctx to expire
+func (p *Provider) Close(ctx context.Context) error {
+	p.mu.RLock()
+	started := p.started
+	p.mu.RUnlock()
+
+	p.closeOnce.Do(func() { close(p.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Refresh loads the key set once and records the outcome
+func (p *Provider) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	set, err := p.source.Load(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if err != nil {
+		if p.failingSince.IsZero() {
+			p.failingSince = now
+		}
+		p.lastError = err.Error()
+		if p.keys == nil {
+			p.logger.Error("Failed to load "+p.name+" keys; they are unavailable", "error", err)
+		} else {
+			p.logger.Warn("Failed to reload "+p.name+" keys; serving the last key set", "error", err, "loaded_at", p.loadedAt)
+		}
+		return err
+	}
+
+	if p.keys != nil && !sameKeys(p.keys, set) {
+		p.rotations++
+		p.logger.Info(p.name+" keys rotated", "current_key_id", set.Current.ID, "keys", len(set.Keys))
+	} else if p.keys == nil {
+		p.logger.Info(p.name+" keys loaded", "current_key_id", set.Current.ID, "keys", len(set.Keys))
+	}
+	if !p.failingSince.IsZero() {
+		p.logger.Info(p.name+" key reloads recovered", "failing_since", p.failingSince)
+	}
+	p.keys = set
+	p.loadedAt = now
+	p.failingSince = time.Time{}
+	p.lastError = ""
+	return nil
+}
+
+// KeySet returns the cached key set
+func (p *Provider) KeySet(ctx context.Context) (*domain.KeySet, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.keys == nil || p.graceExpired() {
+		return nil, domain.ErrKeysUnavailable
+	}
+	return p.keys, nil
+}
+
+// CheckHealth reports whether keys are loaded, served in grace mode or unavailable
+func (p *Provider) CheckHealth(_ context.Context) domain.ComponentHealth {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	details := map[string]interface{}{
+		"source":    p.cfg.Source,
+		"rotations": p.rotations,
+	}
+	if p.keys != nil {
+		details["current_key_id"] = p.keys.Current.ID
+		details["loaded_at"] = p.loadedAt
+	}
+	if p.failingSince.IsZero() && p.keys != nil {
+		details["mode"] = "live"
+		return domain.ComponentHealth{Status: domain.HealthStatusHealthy, Details: details}
+	}
+
+	details["failing_since"] = p.failingSince
+	details["last_error"] = p.lastError
+	details["mode"] = "grace"
+	if p.keys == nil || p.graceExpired() {
+		details["mode"] = "unavailable"
+	}
+	return domain.ComponentHealth{Status: domain.HealthStatusDegraded, Details: details}
+}
+
+// graceExpired reports whether the cached key set is too old to serve; callers hold mu
+func (p *Provider) graceExpired() bool {
+	return !p.failingSince.IsZero() && p.cfg.GracePeriod > 0 && p.now().Sub(p.loadedAt) > p.cfg.GracePeriod
+}
+
+// run reloads the key set until Close, sooner after a failed load
+func (p *Provider) run(lastErr error) {
+	defer close(p.done)
+
+	for {
+		interval := p.cfg.RefreshInterval
+		if lastErr != nil {
+			interval = p.cfg.RetryInterval
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-p.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			lastErr = p.Refresh(context.Background())
+		}
+	}
+}
+
+// sameKeys reports whether two key sets hold the same keys and current key
+func sameKeys(a, b *domain.KeySet) bool {
+	if a.Current.ID != b.Current.ID || len(a.Keys) != len(b.Keys) {
+		return false
+	}
+	for id, key := range a.Keys {
+		other, ok := b.Keys[id]
+		if !ok || !bytes.Equal(key.Secret, other.Secret) || !samePublicKey(key.PublicKey, other.PublicKey) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/httpclient"
+)
+
+// maxKeySetSize bounds how much of a key set file or response is read
+const maxKeySetSize = 1 << 20
+
+// Source loads a key set from where rotated keys are published
+type Source interface {
+	Load(ctx context.Context) (*domain.KeySet, error)
+}
+
+// NewSource creates the key set source selected by cfg.Source. The static
+// source has no Source; use SecretKeySet instead.
+func NewSource(cfg config.JWTKeysConfig) (Source, error) {
+	switch cfg.Source {
+	case config.JWTKeySourceFile:
+		if cfg.File == "" {
+			return nil, errors.New("a key file is required for the file key source")
+		}
+		return &fileSource{path: cfg.File}, nil
+	case config.JWTKeySourceURL:
+		if cfg.URL == "" {
+			return nil, errors.New("a URL is required for the url key source")
+		}
+		return &urlSource{
+			url:        cfg.URL,
+			token:      cfg.URLToken,
+			httpClient: &httpclient.New("jwt-keys", httpclient.Config{Timeout: loadTimeout}).Client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key source: %s", cfg.Source)
+	}
+}
+
+// fileSource reads the key set from a file, e.g. one mounted by a secret store agent
+type fileSource struct {
+	path string
+}
+
+// Load reads and parses the key set file
+func (s *fileSource) Load(ctx context.Context) (*domain.KeySet, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(io.LimitReader(file, maxKeySetSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
+
+// urlSource fetches the key set from a secret store over HTTP
+type urlSource struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// Load fetches and parses the key set
+func (s *urlSource) Load(ctx context.Context) (*domain.KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch key set: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch key set: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySetSize))
+	if err != nil {
+		return nil, fmt.Errorf("fetch key set: %w", err)
+	}
+	return ParseKeySet(data)
+}
+
+// keySetDocument is the published key set format:
+//
+//	{"current": "2025-10", "keys": {"2025-10": "new-secret", "2025-09": "old-secret"}}
+//
+// Values may instead be PEM-encoded RSA or Ed25519 keys. The current key must
+// then be a private key; older keys may be public keys, which only verify.
+type keySetDocument struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// ParseKeySet decodes a published key set and checks that the current key is in it
+func ParseKeySet(data []byte) (*domain.KeySet, error) {
+	var doc keySetDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+	if doc.Current == "" {
+		return nil, errors.New("invalid key set: current key ID is empty")
+	}
+
+	set := &domain.KeySet{Keys: make(map[string]domain.SigningKey, len(doc.Keys))}
+	for id, secret := range doc.Keys {
+		if id == "" || secret == "" {
+			return nil, errors.New("invalid key set: key IDs and secrets must not be empty")
+		}
+		if !isPEM(secret) {
+			set.Keys[id] = domain.SigningKey{ID: id, Secret: []byte(secret)}
+			continue
+		}
+		key, err := ParsePEMKey(id, secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key set: %w", err)
+		}
+		set.Keys[id] = key
+	}
+
+	current, ok := set.Keys[doc.Current]
+	if !ok {
+		return nil, fmt.Errorf("invalid key set: current key %q is not in keys", doc.Current)
+	}
+	if current.PublicKey != nil && current.PrivateKey == nil {
+		return nil, fmt.Errorf("invalid key set: current key %q is a public key and cannot sign", doc.Current)
+	}
+	set.Current = current
+	return set, nil
+}
@@ -0,0 +1,259 @@
+// Package lifecycle starts and stops the server's subsystems in dependency
+// order. Each subsystem registers a Hook; startup runs the hooks' Start
+// functions with dependencies first, and shutdown runs their Stop functions
+// in reverse, each bounded by its timeout and logged. Listeners register
+// through ListenerHook and report a failure to serve with Fail, so the
+// process can shut down every subsystem instead of exiting.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"demo-go/internal/logger"
+)
+
+// DefaultTimeout bounds the Start and Stop of hooks without their own Timeout
+const DefaultTimeout = 5 * time.Second
+
+// Hook is a subsystem's part in startup and shutdown
+type Hook struct {
+	Name string
+	// DependsOn names registered hooks that start before this one and stop
+	// after it
+	DependsOn []string
+	// Start launches the subsystem; nil for subsystems that are running once
+	// constructed, such as open connections
+	Start func(ctx context.Context) error
+	// Stop releases the subsystem; nil when there is nothing to release
+	Stop func(ctx context.Context) error
+	// Timeout bounds Start and Stop each; 0 uses DefaultTimeout
+	Timeout time.Duration
+}
+
+// Manager runs the hooks of the registered subsystems
+type Manager struct {
+	logger *logger.Logger
+
+	mu      sync.Mutex
+	hooks   []Hook
+	index   map[string]int
+	running map[string]bool
+	stopped bool
+	failed  bool
+	failure chan error
+}
+
+// NewManager creates a manager without hooks
+func NewManager() *Manager {
+	return &Manager{
+		logger:  logger.GetGlobal().ForComponent("lifecycle"),
+		index:   make(map[string]int),
+		running: make(map[string]bool),
+		failure: make(chan error, 1),
+	}
+}
+
+// Register adds a hook. Its dependencies must already be registered, so
+// registration order is a valid start order and cycles cannot occur. A hook
+// without Start counts as running from registration, so Stop releases it
+// even if Start is never called.
+func (m *Manager) Register(hook Hook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hook.Name == "" {
+		return errors.New("lifecycle hook without a name")
+	}
+	if _, exists := m.index[hook.Name]; exists {
+		return fmt.Errorf("lifecycle hook %q is already registered", hook.Name)
+	}
+	for _, dependency := range hook.DependsOn {
+		if _, exists := m.index[dependency]; !exists {
+			return fmt.Errorf("lifecycle hook %q depends on unregistered hook %q", hook.Name, dependency)
+		}
+	}
+	if hook.Timeout <= 0 {
+		hook.Timeout = DefaultTimeout
+	}
+
+	m.index[hook.Name] = len(m.hooks)
+	m.hooks = append(m.hooks, hook)
+	if hook.Start == nil {
+		m.running[hook.Name] = true
+	}
+	return nil
+}
+
+// MustRegister adds a hook and panics if it cannot be registered, for hooks
+// whose names and dependencies are constant
+func (m *Manager) MustRegister(hook Hook) {
+	if err := m.Register(hook); err != nil {
+		panic(err)
+	}
+}
+
+// Names returns the registered hooks in registration order
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, len(m.hooks))
+	for i, hook := range m.hooks {
+		names[i] = hook.Name
+	}
+	return names
+}
+
+// Start starts the hooks in registration order, which puts dependencies
+// first. When a hook fails to start, the hooks already running are stopped
+// and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	for _, hook := range m.snapshot() {
+		if hook.Start == nil {
+			continue
+		}
+		log := m.logger.WithField("hook", hook.Name)
+		began := time.Now()
+		err := run(ctx, hook.Timeout, hook.Start)
+		if err != nil {
+			log.Error("Subsystem failed to start", "error", err)
+			if stopErr := m.Stop(context.WithoutCancel(ctx)); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+			return fmt.Errorf("start %s: %w", hook.Name, err)
+		}
+		m.mu.Lock()
+		m.running[hook.Name] = true
+		m.mu.Unlock()
+		log.Debug("Subsystem started", "duration", time.Since(began))
+	}
+	return nil
+}
+
+// Stop stops the running hooks in reverse registration order, so each hook
+// stops before the hooks it depends on. A failed or timed-out Stop is logged
+// and the remaining hooks are still stopped; the errors are returned
+// together. Stop only runs once; later calls return nil.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	m.mu.Unlock()
+
+	hooks := m.snapshot()
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		m.mu.Lock()
+		running := m.running[hook.Name]
+		delete(m.running, hook.Name)
+		m.mu.Unlock()
+		if !running || hook.Stop == nil {
+			continue
+		}
+
+		log := m.logger.WithField("hook", hook.Name)
+		began := time.Now()
+		if err := run(ctx, hook.Timeout, hook.Stop); err != nil {
+			log.Warn("Subsystem did not stop cleanly", "error", err, "duration", time.Since(began))
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+			continue
+		}
+		log.Info("Subsystem stopped", "duration", time.Since(began))
+	}
+	return errors.Join(errs...)
+}
+
+// Fail reports that the running subsystem name failed on its own, such as a
+// listener that stopped serving. The first failure is delivered on Failed;
+// later ones, and those reported once Stop began, are only logged.
+func (m *Manager) Fail(name string, err error) {
+	m.logger.WithField("hook", name).Error("Subsystem failed", "error", err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failed || m.stopped {
+		return
+	}
+	m.failed = true
+	m.failure <- fmt.Errorf("%s: %w", name, err)
+}
+
+// Failed returns a channel receiving the first failure reported with Fail
+func (m *Manager) Failed() <-chan error {
+	return m.failure
+}
+
+// Server is a listener's server; *http.Server implements it
+type Server interface {
+	Serve(listener net.Listener) error
+	Shutdown(ctx context.Context) error
+}
+
+// ListenerHook returns the hook of a listener named name. Start binds
+// address, so an address in use fails startup, then serves in the
+// background; Stop shuts the server down gracefully within timeout. Serve
+// returning before Stop, other than with http.ErrServerClosed, is reported
+// with Fail.
+func (m *Manager) ListenerHook(name, address string, server Server, timeout time.Duration, dependsOn ...string) Hook {
+	return Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", address)
+			if err != nil {
+				return err
+			}
+			m.logger.WithField("hook", name).Info("Listener serving", "address", listener.Addr().String())
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					m.Fail(name, err)
+				}
+			}()
+			return nil
+		},
+		Stop:    server.Shutdown,
+		Timeout: timeout,
+	}
+}
+
+func (m *Manager) snapshot() []Hook {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Hook(nil), m.hooks...)
+}
+
+// run calls fn with a context bounded by timeout and returns once fn does or
+// the context is done, whichever comes first; fn keeps running in the latter
+// case, but shutdown no longer waits for it
+func run(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseFunc adapts a cleanup function without a context or error, such as
+// one closing a connection, to a Stop function
+func CloseFunc(fn func()) func(ctx context.Context) error {
+	return func(context.Context) error {
+		fn()
+		return nil
+	}
+}
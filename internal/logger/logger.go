@@ -179,6 +179,14 @@ func (l *Logger) WithUserID(userID string) *Logger {
 	return l.WithField("user_id", userID)
 }
 
+// WithTrace adds W3C trace and span ID fields to the logger
+func (l *Logger) WithTrace(traceID, spanID string) *Logger {
+	return l.WithFields(map[string]interface{}{
+		"trace_id": traceID,
+		"span_id":  spanID,
+	})
+}
+
 // ForComponent creates a logger for a specific component
 func (l *Logger) ForComponent(component string) *Logger {
 	return l.WithField("component", component)
@@ -0,0 +1,60 @@
+// Package memo provides a request-scoped memo carried in the context. The
+// service layer stores lookups in it so repeated fetches of the same data
+// within one request are served from memory; it is discarded with the request.
+package memo
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey struct{}
+
+// Memo holds values computed during one request. A nil *Memo is valid and
+// memoizes nothing, so callers need not check whether a request has one.
+type Memo struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// NewContext returns a copy of ctx carrying a new, empty memo
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &Memo{values: make(map[string]interface{})})
+}
+
+// FromContext returns the request's memo, or nil when there is none
+func FromContext(ctx context.Context) *Memo {
+	m, _ := ctx.Value(contextKey{}).(*Memo)
+	return m
+}
+
+// Get returns the value stored under key
+func (m *Memo) Get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set stores value under key
+func (m *Memo) Set(key string, value interface{}) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+}
+
+// Reset drops all stored values, e.g. after a write made them stale
+func (m *Memo) Reset() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values = make(map[string]interface{})
+}
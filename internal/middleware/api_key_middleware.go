@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// HeaderAPIKey carries the API key of requests authenticated without a JWT
+const HeaderAPIKey = "X-API-Key"
+
+const apiKeyKey contextKey = "api_key"
+
+// GetAPIKeyFromContext returns the API key that authenticated the request
+func GetAPIKeyFromContext(ctx context.Context) (*domain.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyKey).(*domain.APIKey)
+	return key, ok
+}
+
+// APIKeyMiddleware authenticates requests that carry an API key
+type APIKeyMiddleware struct {
+	apiKeys     domain.APIKeyService
+	defaultRole string
+	logger      *logger.Logger
+}
+
+// NewAPIKeyMiddleware creates a new API key authentication middleware. Keys
+// without the admin scope act with defaultRole instead of their owner's role.
+func NewAPIKeyMiddleware(apiKeys domain.APIKeyService, defaultRole string) *APIKeyMiddleware {
+	return &APIKeyMiddleware{
+		apiKeys:     apiKeys,
+		defaultRole: defaultRole,
+		logger:      logger.GetGlobal().ForComponent("api-key-middleware"),
+	}
+}
+
+// Authenticate verifies API keys and marks their requests as authenticated
+// by the key's owner. Reads need the read or write scope and other methods
+// the write scope. Requests without an API key, or already authenticated by
+// another scheme, are passed through untouched so the JWT middleware can
+// handle them.
+func (m *APIKeyMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawKey := r.Header.Get(HeaderAPIKey)
+		if rawKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := GetAuthMethodFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, user, err := m.apiKeys.Authenticate(r.Context(), rawKey)
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			writeJSONError(w, r, http.StatusUnauthorized, domain.ErrInvalidAPIKey.Message, domain.ErrInvalidAPIKey.Code)
+			return
+		}
+		if err != nil {
+			m.logger.Error("Failed to verify API key", "error", err)
+			writeJSONError(w, r, http.StatusServiceUnavailable, "Unable to verify API key", "SERVICE_UNAVAILABLE")
+			return
+		}
+
+		log := m.logger.WithField("key_id", key.ID)
+		if scope, ok := apiKeyAllows(key, r.Method); !ok {
+			log.Warn("API key used without the scope of the request", "scope", scope, "method", r.Method, "path", r.URL.Path)
+			writeJSONError(w, r, http.StatusForbidden, "API key lacks the "+scope+" scope", "FORBIDDEN")
+			return
+		}
+
+		role := m.defaultRole
+		if key.HasScope(domain.APIKeyScopeAdmin) {
+			role = user.Role
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, user.ID)
+		ctx = context.WithValue(ctx, userEmailKey, user.Email)
+		ctx = context.WithValue(ctx, userRoleKey, role)
+		ctx = context.WithValue(ctx, apiKeyKey, key)
+		ctx = context.WithValue(ctx, authMethodKey, "api_key")
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiKeyAllows returns the scope requests of the method need and whether the
+// key has it; the write scope includes read
+func apiKeyAllows(key *domain.APIKey, method string) (string, bool) {
+	return scopesAllow(key.HasScope, method)
+}
+
+// scopesAllow returns the scope requests of the method need and whether a
+// holder with the given scopes has it
+func scopesAllow(hasScope func(string) bool, method string) (string, bool) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return domain.APIKeyScopeRead, hasScope(domain.APIKeyScopeRead) || hasScope(domain.APIKeyScopeWrite)
+	}
+	return domain.APIKeyScopeWrite, hasScope(domain.APIKeyScopeWrite)
+}
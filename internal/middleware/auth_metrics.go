@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Outcomes of bearer token authentication counted by AuthMetrics
+const (
+	AuthOutcomeSuccess         = "success"
+	AuthOutcomeMissingHeader   = "missing_header"
+	AuthOutcomeInvalidToken    = "invalid_token"
+	AuthOutcomeExpiredToken    = "expired_token"
+	AuthOutcomeRevokedToken    = "revoked_token"
+	AuthOutcomeUserNotFound    = "user_not_found"
+	AuthOutcomeInactiveUser    = "inactive_user"
+	AuthOutcomeKeysUnavailable = "keys_unavailable"
+	AuthOutcomeStorageError    = "storage_unavailable"
+	AuthOutcomeEndpointClosed  = "endpoint_closed"
+	AuthOutcomeMissingScope    = "missing_scope"
+)
+
+// maxRecentAuthFailures bounds the failures kept for the admin summary
+const maxRecentAuthFailures = 100
+
+// AuthFailure is one refused request, without the token it carried
+type AuthFailure struct {
+	Time     time.Time `json:"time"`
+	Reason   string    `json:"reason"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	ClientIP string    `json:"client_ip"`
+}
+
+// AuthMetricsSnapshot summarizes authentication since the process started
+type AuthMetricsSnapshot struct {
+	Since    time.Time        `json:"since"`
+	Outcomes map[string]int64 `json:"outcomes"`
+	// SkipHits counts requests let through by each JWT skip rule
+	SkipHits map[string]int64 `json:"skip_hits"`
+	// PassedThrough counts requests already authenticated by another scheme,
+	// such as an API key or a signature
+	PassedThrough int64 `json:"passed_through"`
+	// RecentReasons counts the reasons of the RecentFailures
+	RecentReasons  map[string]int `json:"recent_reasons"`
+	RecentFailures []AuthFailure  `json:"recent_failures"` // newest first
+}
+
+// AuthMetrics counts the outcomes of the JWT middleware and keeps its most
+// recent failures, so authentication issues can be diagnosed without debug
+// logs. Counters live in process memory and restart from zero.
+type AuthMetrics struct {
+	mu            sync.Mutex
+	since         time.Time
+	outcomes      map[string]int64
+	skipHits      map[string]int64
+	passedThrough int64
+	recent        []AuthFailure // ring buffer, next points at the oldest entry once full
+	next          int
+	now           func() time.Time
+}
+
+// NewAuthMetrics creates empty authentication metrics
+func NewAuthMetrics() *AuthMetrics {
+	return &AuthMetrics{
+		since:    time.Now().UTC(),
+		outcomes: make(map[string]int64),
+		skipHits: make(map[string]int64),
+		recent:   make([]AuthFailure, 0, maxRecentAuthFailures),
+		now:      time.Now,
+	}
+}
+
+// Snapshot returns the current counters and recent failures
+func (m *AuthMetrics) Snapshot() *AuthMetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := &AuthMetricsSnapshot{
+		Since:          m.since,
+		Outcomes:       make(map[string]int64, len(m.outcomes)),
+		SkipHits:       make(map[string]int64, len(m.skipHits)),
+		PassedThrough:  m.passedThrough,
+		RecentReasons:  make(map[string]int),
+		RecentFailures: make([]AuthFailure, 0, len(m.recent)),
+	}
+	for outcome, count := range m.outcomes {
+		snapshot.Outcomes[outcome] = count
+	}
+	for rule, count := range m.skipHits {
+		snapshot.SkipHits[rule] = count
+	}
+	for i := len(m.recent) - 1; i >= 0; i-- {
+		failure := m.recent[(m.next+i)%len(m.recent)]
+		snapshot.RecentFailures = append(snapshot.RecentFailures, failure)
+		snapshot.RecentReasons[failure.Reason]++
+	}
+	return snapshot
+}
+
+// Helper methods
+
+func (m *AuthMetrics) recordSuccess() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[AuthOutcomeSuccess]++
+}
+
+func (m *AuthMetrics) recordFailure(r *http.Request, reason string) {
+	failure := AuthFailure{
+		Time:     m.now().UTC(),
+		Reason:   reason,
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		ClientIP: GetClientIP(r),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[reason]++
+	if len(m.recent) < maxRecentAuthFailures {
+		m.recent = append(m.recent, failure)
+		return
+	}
+	m.recent[m.next] = failure
+	m.next = (m.next + 1) % maxRecentAuthFailures
+}
+
+func (m *AuthMetrics) recordSkip(rule string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skipHits[rule]++
+}
+
+func (m *AuthMetrics) recordPassThrough() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.passedThrough++
+}
+
+// invalidTokenReason tells expired JWTs apart from other invalid tokens. The
+// token has already failed validation, so its claims are only read, not
+// trusted; opaque tokens carry no claims and count as invalid.
+func invalidTokenReason(token string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return AuthOutcomeInvalidToken
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil && exp.Before(time.Now()) {
+		return AuthOutcomeExpiredToken
+	}
+	return AuthOutcomeInvalidToken
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// clientCountryKey is the context key of the client's country
+const clientCountryKey loggingContextKey = "client_country"
+
+// ClientCountry records the client's ISO 3166 country code from a header set
+// by a CDN or load balancer, such as Cloudflare's CF-IPCountry. Clients can
+// send the header themselves, so it must only be used behind a proxy that
+// overwrites it. Unknown countries ("XX") and malformed values are ignored.
+func ClientCountry(header string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			country := strings.ToUpper(strings.TrimSpace(r.Header.Get(header)))
+			if len(country) == 2 && country != "XX" && isLetters(country) {
+				r = r.WithContext(context.WithValue(r.Context(), clientCountryKey, country))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetClientCountryFromContext returns the country recorded by ClientCountry
+func GetClientCountryFromContext(ctx context.Context) (string, bool) {
+	country, ok := ctx.Value(clientCountryKey).(string)
+	return country, ok
+}
+
+func isLetters(s string) bool {
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+)
+
+// HeaderCSRFToken carries the CSRF token of state-changing requests made with
+// the session cookie
+const HeaderCSRFToken = "X-CSRF-Token"
+
+// CookieSessions keeps access tokens in an HttpOnly session cookie for
+// browsers. Next to it a readable cookie holds the session's CSRF token, which
+// scripts of the site echo in the X-CSRF-Token header; other sites can make
+// the browser send the cookies but cannot read them.
+type CookieSessions struct {
+	cfg      config.CookieSessionConfig
+	sameSite http.SameSite
+	maxAge   time.Duration
+	csrfKey  []byte
+	logger   *logger.Logger
+}
+
+// NewCookieSessions creates the cookie sessions of cookie auth mode. Cookies
+// last maxAge, the lifetime of an access token.
+func NewCookieSessions(cfg config.CookieSessionConfig, maxAge time.Duration) (*CookieSessions, error) {
+	var sameSite http.SameSite
+	switch strings.ToLower(cfg.SameSite) {
+	case "", "lax":
+		sameSite = http.SameSiteLaxMode
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		if !cfg.Secure {
+			return nil, fmt.Errorf("SameSite=None cookies must be secure")
+		}
+		sameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("unsupported SameSite mode: %s", cfg.SameSite)
+	}
+	if cfg.Name == "" || cfg.CSRFCookieName == "" || cfg.Name == cfg.CSRFCookieName {
+		return nil, fmt.Errorf("the session and CSRF cookies need distinct names")
+	}
+
+	log := logger.GetGlobal().ForComponent("cookie-sessions")
+	csrfKey := []byte(cfg.CSRFSecret)
+	if len(csrfKey) == 0 {
+		log.Warn("No CSRF secret configured; CSRF tokens are lost on restart and not valid across instances")
+		csrfKey = make([]byte, 32)
+		if _, err := rand.Read(csrfKey); err != nil {
+			return nil, fmt.Errorf("failed to generate CSRF secret: %w", err)
+		}
+	}
+
+	return &CookieSessions{
+		cfg:      cfg,
+		sameSite: sameSite,
+		maxAge:   maxAge,
+		csrfKey:  csrfKey,
+		logger:   log,
+	}, nil
+}
+
+// SetSession stores the access token in the session cookie and returns the
+// CSRF token of the session, which is also set in the CSRF cookie
+func (c *CookieSessions) SetSession(w http.ResponseWriter, token string) string {
+	csrfToken := c.csrfToken(token)
+	http.SetCookie(w, c.cookie(c.cfg.Name, token, true, int(c.maxAge.Seconds())))
+	http.SetCookie(w, c.cookie(c.cfg.CSRFCookieName, csrfToken, false, int(c.maxAge.Seconds())))
+	return csrfToken
+}
+
+// ClearSession makes the browser drop the session and CSRF cookies
+func (c *CookieSessions) ClearSession(w http.ResponseWriter) {
+	http.SetCookie(w, c.cookie(c.cfg.Name, "", true, -1))
+	http.SetCookie(w, c.cookie(c.cfg.CSRFCookieName, "", false, -1))
+}
+
+// Authenticate turns the session cookie into the bearer token of the request
+// so the JWT middleware validates it like any other. State-changing requests
+// with the cookie need the session's CSRF token in the X-CSRF-Token header.
+// Requests with an Authorization header, or already authenticated by another
+// scheme, are passed through untouched and are not subject to CSRF checks.
+func (c *CookieSessions) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := GetAuthMethodFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cookie, err := r.Cookie(c.cfg.Name)
+		if err != nil || cookie.Value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !isSafeMethod(r.Method) {
+			given := r.Header.Get(HeaderCSRFToken)
+			if given == "" || !hmac.Equal([]byte(given), []byte(c.csrfToken(cookie.Value))) {
+				c.logger.Warn("Request with the session cookie lacks a valid CSRF token",
+					"method", r.Method, "path", r.URL.Path, "ip", GetClientIP(r))
+				writeJSONError(w, r, http.StatusForbidden, "Missing or invalid CSRF token", "CSRF_TOKEN_INVALID")
+				return
+			}
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Set("Authorization", "Bearer "+cookie.Value)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Helper methods
+
+// csrfToken derives the CSRF token of a session from its access token
+func (c *CookieSessions) csrfToken(token string) string {
+	mac := hmac.New(sha256.New, c.csrfKey)
+	mac.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *CookieSessions) cookie(name, value string, httpOnly bool, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     c.cfg.Path,
+		Domain:   c.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   c.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite,
+	}
+}
+
+// isSafeMethod reports whether requests of the method do not change state
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"demo-go/internal/response"
+	"demo-go/internal/tracing"
+
+	"github.com/gorilla/mux"
+)
+
+// corsAllowedHeaders are the request headers the API recognizes, allowed on
+// cross-origin requests
+var corsAllowedHeaders = strings.Join([]string{
+	"Content-Type",
+	"Authorization",
+	response.NamingHeader,
+	response.EnvelopeHeader,
+	ExplainHeader,
+	HeaderAPIKey,
+	HeaderCSRFToken,
+	tracing.TraceParentHeader,
+	tracing.TraceStateHeader,
+}, ", ")
+
+// CORSMiddleware provides CORS headers allowing every origin
+func CORSMiddleware(next http.Handler) http.Handler {
+	return CORS([]string{"*"})(next)
+}
+
+// CORS provides CORS headers for the allowed origins. "*" allows every
+// origin; otherwise the request's Origin is echoed back when it is listed,
+// and requests from other origins get no CORS headers.
+func CORS(allowedOrigins []string) mux.MiddlewareFunc {
+	wildcard := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			wildcard = true
+		}
+		allowed[origin] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch origin := r.Header.Get("Origin"); {
+			case wildcard:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			case allowed[origin]:
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			default:
+				w.Header().Add("Vary", "Origin")
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+
+			// Handle preflight requests
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
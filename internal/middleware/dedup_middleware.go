@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"demo-go/internal/logger"
+	"demo-go/internal/response"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// HeaderDeduplicated is set on responses that were shared between concurrent identical requests
+const HeaderDeduplicated = "X-Request-Deduplicated"
+
+// DedupMiddleware coalesces concurrent identical GET requests onto a single
+// handler execution, so a storm of client retries costs the backend one call.
+// Requests are identical when they have the same principal, credential and
+// request URI, and the same headers that shape the response (see dedupKey).
+// It must run after authentication so the principal is known.
+type DedupMiddleware struct {
+	group  singleflight.Group
+	logger *logger.Logger
+}
+
+// NewDedupMiddleware creates a new request deduplication middleware
+func NewDedupMiddleware() *DedupMiddleware {
+	return &DedupMiddleware{
+		logger: logger.GetGlobal().ForComponent("dedup-middleware"),
+	}
+}
+
+// sharedResponse is a handler response captured so it can be replayed to every waiting caller
+type sharedResponse struct {
+	header     http.Header
+	statusCode int
+	body       []byte
+}
+
+// Deduplicate is a middleware that shares one response between concurrent identical GETs.
+// The shared response, including its envelope metadata, is the one produced for
+// the first caller.
+func (m *DedupMiddleware) Deduplicate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, _ := GetUserIDFromContext(r.Context())
+		key := dedupKey(r)
+
+		result, _, shared := m.group.Do(key, func() (interface{}, error) {
+			recorder := &recordingResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+			// Detach from the first caller's cancellation so its disconnect does not fail the others
+			next.ServeHTTP(recorder, r.WithContext(context.WithoutCancel(r.Context())))
+			return &sharedResponse{
+				header:     recorder.header,
+				statusCode: recorder.statusCode,
+				body:       recorder.body.Bytes(),
+			}, nil
+		})
+
+		resp := result.(*sharedResponse)
+		for name, values := range resp.header {
+			w.Header()[name] = values
+		}
+		if shared {
+			m.logger.Debug("Served deduplicated response", "path", r.URL.Path, "user_id", userID)
+			w.Header().Set(HeaderDeduplicated, "true")
+		}
+		w.WriteHeader(resp.statusCode)
+		_, _ = w.Write(resp.body)
+	})
+}
+
+// dedupVaryHeaders are the request headers that change the response of a
+// route, so requests differing in them are never shared
+var dedupVaryHeaders = []string{response.NamingHeader, response.EnvelopeHeader, ExplainHeader, "Accept"}
+
+// dedupKey identifies requests whose responses are interchangeable: the same
+// user through the same credential, with the same role and scopes (an API
+// key or a delegated token may hold less than the user), the same response
+// headers and request URI
+func dedupKey(r *http.Request) string {
+	ctx := r.Context()
+	userID, _ := GetUserIDFromContext(ctx)
+	method, _ := GetAuthMethodFromContext(ctx)
+	role, _ := GetUserRoleFromContext(ctx)
+
+	var credential string
+	var scopes []string
+	if key, ok := GetAPIKeyFromContext(ctx); ok {
+		credential = "api_key:" + key.ID
+		scopes = key.Scopes
+	} else if claims, ok := GetTokenClaimsFromContext(ctx); ok {
+		credential = "client:" + claims.ClientID
+		for actor := claims.Actor; actor != nil; actor = actor.Actor {
+			credential += ">" + actor.Subject
+		}
+		scopes = claims.Scopes
+	}
+	scopes = append([]string(nil), scopes...)
+	sort.Strings(scopes)
+
+	parts := []string{userID, method, credential, role, strings.Join(scopes, ",")}
+	for _, name := range dedupVaryHeaders {
+		parts = append(parts, strings.Join(r.Header.Values(name), ","))
+	}
+	parts = append(parts, r.URL.RequestURI())
+	// The unit separator cannot appear in any of the parts
+	return strings.Join(parts, "\x1f")
+}
+
+// recordingResponseWriter buffers a response instead of sending it
+type recordingResponseWriter struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *recordingResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.statusCode = statusCode
+	w.wroteHeader = true
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(data)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"demo-go/internal/queryplan"
+)
+
+// ExplainHeader is the request header admins set to receive query plans in the response meta
+const ExplainHeader = "X-Explain"
+
+// QueryExplain collects database query plans for admin requests that set
+// ExplainHeader. It must run after authentication; the header is ignored for
+// everyone else.
+func QueryExplain(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested, _ := strconv.ParseBool(r.Header.Get(ExplainHeader))
+		if role, _ := GetUserRoleFromContext(r.Context()); !requested || role != "admin" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(queryplan.NewContext(r.Context())))
+	})
+}
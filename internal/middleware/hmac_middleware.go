@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+)
+
+// HMAC signing headers sent by machine-to-machine callers
+const (
+	HeaderClientID  = "X-Client-ID"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// ServiceRole is the role assigned to principals authenticated by request signature
+const ServiceRole = "service"
+
+// maxSignedBodySize limits the size of signed request bodies; larger ones are
+// refused rather than verified against a truncated body
+const maxSignedBodySize = 1 << 20 // 1MB
+
+// errSignedBodyTooLarge is returned by readBody for bodies over maxSignedBodySize
+var errSignedBodyTooLarge = errors.New("signed request body too large")
+
+const authMethodKey contextKey = "auth_method"
+
+// GetAuthMethodFromContext returns how the current request was authenticated
+// ("jwt", "hmac" or "api_key")
+func GetAuthMethodFromContext(ctx context.Context) (string, bool) {
+	method, ok := ctx.Value(authMethodKey).(string)
+	return method, ok
+}
+
+// NonceStore remembers nonces that have already been used so signed requests cannot be replayed
+type NonceStore interface {
+	// Remember records the nonce and returns false if it has been seen before
+	Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// cacheNonceStore implements NonceStore on top of the shared cache service
+type cacheNonceStore struct {
+	cache cache.Service
+}
+
+// NewCacheNonceStore creates a nonce store backed by the cache service (Redis)
+func NewCacheNonceStore(cacheService cache.Service) NonceStore {
+	return &cacheNonceStore{cache: cacheService}
+}
+
+// Remember stores the nonce with SETNX semantics
+func (s *cacheNonceStore) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return s.cache.SetNX(ctx, "hmac:nonce:"+nonce, true, ttl)
+}
+
+// memoryNonceStore implements NonceStore in process memory for single-instance deployments
+type memoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time // nonce -> expiry
+}
+
+// NewMemoryNonceStore creates an in-memory nonce store
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Remember records the nonce, evicting expired entries along the way
+func (s *memoryNonceStore) Remember(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for n, expiry := range s.nonces {
+		if now.After(expiry) {
+			delete(s.nonces, n)
+		}
+	}
+
+	if _, seen := s.nonces[nonce]; seen {
+		return false, nil
+	}
+
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// HMACMiddleware verifies HMAC-signed requests from machine clients
+type HMACMiddleware struct {
+	clients      map[string][]byte
+	maxClockSkew time.Duration
+	nonceTTL     time.Duration
+	nonces       NonceStore
+	logger       *logger.Logger
+	now          func() time.Time
+}
+
+// NewHMACMiddleware creates a new HMAC signature verification middleware
+func NewHMACMiddleware(cfg *config.HMACConfig, nonces NonceStore) *HMACMiddleware {
+	clients := make(map[string][]byte, len(cfg.Clients))
+	for clientID, secret := range cfg.Clients {
+		clients[clientID] = []byte(secret)
+	}
+
+	// A nonce must be remembered for as long as its timestamp could still be accepted
+	nonceTTL := cfg.NonceTTL
+	if nonceTTL < 2*cfg.MaxClockSkew {
+		nonceTTL = 2 * cfg.MaxClockSkew
+	}
+
+	return &HMACMiddleware{
+		clients:      clients,
+		maxClockSkew: cfg.MaxClockSkew,
+		nonceTTL:     nonceTTL,
+		nonces:       nonces,
+		logger:       logger.GetGlobal().ForComponent("hmac-middleware"),
+		now:          time.Now,
+	}
+}
+
+// Authenticate verifies signed requests and marks them as authenticated.
+// Requests without a signature header are passed through untouched so the
+// JWT middleware can handle them.
+func (m *HMACMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature := r.Header.Get(HeaderSignature)
+		if signature == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clientID := r.Header.Get(HeaderClientID)
+		log := m.logger.WithField("client_id", clientID)
+
+		secret, ok := m.clients[clientID]
+		if !ok {
+			log.Warn("Signed request from unknown client")
+			m.writeUnauthorizedResponse(w, r, "Unknown client")
+			return
+		}
+
+		timestamp := r.Header.Get(HeaderTimestamp)
+		if !m.isFreshTimestamp(timestamp) {
+			log.Warn("Signed request timestamp outside allowed window", "timestamp", timestamp)
+			m.writeUnauthorizedResponse(w, r, "Request timestamp is missing or outside the allowed window")
+			return
+		}
+
+		nonce := r.Header.Get(HeaderNonce)
+		if nonce == "" {
+			m.writeUnauthorizedResponse(w, r, "Missing request nonce")
+			return
+		}
+
+		body, err := m.readBody(r)
+		if errors.Is(err, errSignedBodyTooLarge) {
+			log.Warn("Signed request body too large", "limit", maxSignedBodySize)
+			writeJSONError(w, r, http.StatusRequestEntityTooLarge, "Signed request body exceeds 1MB", "PAYLOAD_TOO_LARGE")
+			return
+		}
+		if err != nil {
+			m.writeUnauthorizedResponse(w, r, "Unable to read request body")
+			return
+		}
+
+		expected := ComputeSignature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+		provided, err := hex.DecodeString(signature)
+		if err != nil || !hmac.Equal(provided, expected) {
+			log.Warn("Invalid request signature")
+			m.writeUnauthorizedResponse(w, r, "Invalid request signature")
+			return
+		}
+
+		// Only burn the nonce once the signature is known to be valid, so
+		// unauthenticated callers cannot exhaust a legitimate client's nonces
+		fresh, err := m.nonces.Remember(r.Context(), clientID+":"+nonce, m.nonceTTL)
+		if err != nil {
+			log.Error("Failed to record request nonce", "error", err)
+			writeJSONError(w, r, http.StatusServiceUnavailable, "Unable to verify request", "SERVICE_UNAVAILABLE")
+			return
+		}
+		if !fresh {
+			log.Warn("Replayed signed request", "nonce", nonce)
+			m.writeUnauthorizedResponse(w, r, "Request has already been processed")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, "client:"+clientID)
+		ctx = context.WithValue(ctx, userRoleKey, ServiceRole)
+		ctx = context.WithValue(ctx, authMethodKey, "hmac")
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ComputeSignature returns the HMAC-SHA256 signature of a request. The signed
+// payload is method, request URI, timestamp, nonce and the hex SHA-256 of the
+// body, separated by newlines.
+func ComputeSignature(secret []byte, method, requestURI, timestamp, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + requestURI + "\n" + timestamp + "\n" + nonce + "\n"))
+	mac.Write([]byte(hex.EncodeToString(bodyHash[:])))
+	return mac.Sum(nil)
+}
+
+// Helper methods
+
+func (m *HMACMiddleware) isFreshTimestamp(timestamp string) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	skew := m.now().Sub(time.Unix(seconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew <= m.maxClockSkew
+}
+
+func (m *HMACMiddleware) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSignedBodySize {
+		return nil, errSignedBodyTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (m *HMACMiddleware) writeUnauthorizedResponse(w http.ResponseWriter, r *http.Request, message string) {
+	writeJSONError(w, r, http.StatusUnauthorized, message, "UNAUTHORIZED")
+}
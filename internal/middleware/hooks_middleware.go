@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"demo-go/internal/hooks"
+)
+
+// HooksMiddleware runs the registry's BeforeResponse hooks right before the
+// response status is written
+func HooksMiddleware(registry *hooks.Registry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !registry.HasResponseHooks() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&hookResponseWriter{ResponseWriter: w, request: r, registry: registry}, r)
+		})
+	}
+}
+
+// hookResponseWriter intercepts the first WriteHeader/Write to run hooks
+type hookResponseWriter struct {
+	http.ResponseWriter
+	request     *http.Request
+	registry    *hooks.Registry
+	wroteHeader bool
+}
+
+func (w *hookResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.registry.ResponseStarting(w.request.Context(), w.request, statusCode, w.Header())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *hookResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
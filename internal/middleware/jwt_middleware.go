@@ -4,10 +4,17 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
 
 	"demo-go/internal/domain"
+	"demo-go/internal/pathrule"
+	"demo-go/internal/policy"
+	"demo-go/internal/response"
+
+	"github.com/gorilla/mux"
 )
 
 // Context key types to avoid collisions
@@ -17,6 +24,7 @@ const (
 	userIDKey    contextKey = "user_id"
 	userEmailKey contextKey = "user_email"
 	userRoleKey  contextKey = "user_role"
+	claimsKey    contextKey = "token_claims"
 )
 
 // Helper functions to safely retrieve context values
@@ -39,32 +47,196 @@ func GetUserRoleFromContext(ctx context.Context) (string, bool) {
 	return role, ok
 }
 
+// GetTokenClaimsFromContext returns the claims of the bearer token that
+// authenticated the request
+func GetTokenClaimsFromContext(ctx context.Context) (*domain.TokenClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*domain.TokenClaims)
+	return claims, ok
+}
+
+// unavailableRetryAfter is the Retry-After, in seconds, sent while token
+// signing keys or revocations cannot be loaded
+const unavailableRetryAfter = "5"
+
+// defaultSkipRules are the public endpoints that never require authentication
+var defaultSkipRules = []string{
+	"/health",
+	"/auth/register",
+	"/auth/login",
+	"POST /auth/refresh", // authenticated by the refresh token in the body
+	"/admin-ui",
+	"/admin-ui/**", // public static assets
+}
+
 // JWTMiddleware provides JWT authentication middleware
 type JWTMiddleware struct {
 	tokenService domain.TokenService
-	skipPaths    map[string]bool
+	revocations  domain.TokenRevocationService
+	presence     domain.PresenceService
+	roles        domain.RoleCheckService
+	overrides    domain.RouteOverrideService
+	roleScopes   map[string][]string
+	policy       domain.PolicyEngine
+	tenantPolicy domain.PolicyEngine // by tenant; nil grants tenants nothing
+	metrics      *AuthMetrics
+
+	mu        sync.RWMutex
+	skipRules []pathrule.Rule
 }
 
+// defaultPolicy grants admins every permission until a policy engine is set
+var defaultPolicy, _ = policy.NewMatrix(map[string][]string{"admin": {policy.Wildcard}})
+
 // NewJWTMiddleware creates a new JWT middleware
 func NewJWTMiddleware(tokenService domain.TokenService) *JWTMiddleware {
-	// Define paths that should skip authentication
-	skipPaths := map[string]bool{
-		"/health":        true,
-		"/auth/register": true,
-		"/auth/login":    true,
+	m := &JWTMiddleware{
+		tokenService: tokenService,
+		policy:       defaultPolicy,
+		metrics:      NewAuthMetrics(),
 	}
+	if err := m.AddSkipRules(defaultSkipRules...); err != nil {
+		panic(err) // the built-in rules are constant
+	}
+	return m
+}
 
-	return &JWTMiddleware{
-		tokenService: tokenService,
-		skipPaths:    skipPaths,
+// SetRevocationService makes Authenticate reject revoked tokens. It must be
+// called before the middleware starts serving requests.
+func (m *JWTMiddleware) SetRevocationService(revocations domain.TokenRevocationService) {
+	m.revocations = revocations
+}
+
+// SetPresenceService makes Authenticate record each authenticated user's
+// activity. It must be called before the middleware starts serving requests.
+func (m *JWTMiddleware) SetPresenceService(presence domain.PresenceService) {
+	m.presence = presence
+}
+
+// SetRoleCheckService makes Authenticate apply users' current roles instead
+// of the role in their token, so role changes take effect before tokens
+// expire. Tokens of trusted issuers are not checked. It must be called before
+// the middleware starts serving requests.
+func (m *JWTMiddleware) SetRoleCheckService(roles domain.RoleCheckService) {
+	m.roles = roles
+}
+
+// SetRouteOverrideService makes Authenticate apply runtime route overrides
+// before the skip rules: closed endpoints are refused for everyone and opened
+// ones skip authentication. It must be called before the middleware starts
+// serving requests.
+func (m *JWTMiddleware) SetRouteOverrideService(overrides domain.RouteOverrideService) {
+	m.overrides = overrides
+}
+
+// SetRoleScopes sets the resource scopes granted to each role, which
+// RequireScope accepts in addition to the scopes in a user's token. Machine
+// clients and delegated tokens only have the scopes of their token. It must be called before the
+// middleware starts serving requests.
+func (m *JWTMiddleware) SetRoleScopes(grants map[string][]string) {
+	m.roleScopes = grants
+}
+
+// SetPolicyEngine sets the policy RequirePermission consults. Without one,
+// only admins hold permissions, all of them. It must be called before the
+// middleware starts serving requests.
+func (m *JWTMiddleware) SetPolicyEngine(engine domain.PolicyEngine) {
+	m.policy = engine
+}
+
+// SetTenantPolicy sets the permissions principals of each trusted issuer may
+// use, with tenant names in place of roles. Their role must also hold the
+// permission. Without one, they hold no permissions. It must be called before
+// the middleware starts serving requests.
+func (m *JWTMiddleware) SetTenantPolicy(engine domain.PolicyEngine) {
+	m.tenantPolicy = engine
+}
+
+// PolicyEngine returns the policy RequirePermission consults
+func (m *JWTMiddleware) PolicyEngine() domain.PolicyEngine {
+	return m.policy
+}
+
+// Metrics returns the counters of authentication outcomes and skip rule hits
+func (m *JWTMiddleware) Metrics() *AuthMetrics {
+	return m.metrics
+}
+
+// SkipRules returns the skip rules in effect, built-in ones first, in the
+// "[METHOD ]PATTERN" form they are added in
+func (m *JWTMiddleware) SkipRules() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rules := make([]string, len(m.skipRules))
+	for i, rule := range m.skipRules {
+		rules[i] = rule.String()
+	}
+	return rules
+}
+
+// AddSkipPaths registers additional exact paths that bypass authentication
+// for any method
+func (m *JWTMiddleware) AddSkipPaths(paths ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range paths {
+		m.skipRules = append(m.skipRules, pathrule.Exact(p))
 	}
 }
 
+// AddSkipRules registers skip rules of the form "[METHOD ]PATTERN", such as
+// "GET /.well-known/*" or "/public/**". It is safe to call while the
+// middleware is serving requests; no rule is added if any is invalid.
+func (m *JWTMiddleware) AddSkipRules(rules ...string) error {
+	parsed := make([]pathrule.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		skip, err := pathrule.Parse(rule)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, skip)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skipRules = append(m.skipRules, parsed...)
+	return nil
+}
+
 // Authenticate is a middleware that validates JWT tokens
 func (m *JWTMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.overrides != nil {
+			if override, ok := m.overrides.MatchRoute(r.Method, r.URL.Path); ok {
+				if override.Action == domain.RouteOverrideClose {
+					message := "Endpoint is temporarily closed"
+					if override.Reason != "" {
+						message += ": " + override.Reason
+					}
+					m.metrics.recordFailure(r, AuthOutcomeEndpointClosed)
+					m.writeJSONError(w, r, http.StatusForbidden, message, "ENDPOINT_CLOSED")
+					return
+				}
+				m.metrics.recordSkip("override " + override.Rule)
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
 		// Skip authentication for certain paths
-		if m.shouldSkipPath(r.URL.Path) {
+		if rule, ok := m.matchSkipRule(r.Method, r.URL.Path); ok {
+			m.metrics.recordSkip(rule)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Requests already authenticated by another scheme (e.g. HMAC signing) pass through
+		if _, ok := GetAuthMethodFromContext(r.Context()); ok {
+			m.metrics.recordPassThrough()
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -72,21 +244,99 @@ func (m *JWTMiddleware) Authenticate(next http.Handler) http.Handler {
 		// Extract token from Authorization header
 		tokenString := m.extractTokenFromHeader(r)
 		if tokenString == "" {
-			m.writeUnauthorizedResponse(w, "Missing or invalid Authorization header")
+			m.metrics.recordFailure(r, AuthOutcomeMissingHeader)
+			m.writeUnauthorizedResponse(w, r, "Missing or invalid Authorization header")
 			return
 		}
 
 		// Validate token
 		claims, err := m.tokenService.ValidateToken(tokenString)
+		if errors.Is(err, domain.ErrKeysUnavailable) {
+			// The token may well be valid; tell the client to retry instead of logging in again
+			m.metrics.recordFailure(r, AuthOutcomeKeysUnavailable)
+			w.Header().Set("Retry-After", unavailableRetryAfter)
+			m.writeJSONError(w, r, http.StatusServiceUnavailable, domain.ErrKeysUnavailable.Message, domain.ErrKeysUnavailable.Code)
+			return
+		}
 		if err != nil {
-			m.writeUnauthorizedResponse(w, "Invalid or expired token")
+			m.metrics.recordFailure(r, invalidTokenReason(tokenString))
+			m.writeUnauthorizedResponse(w, r, "Invalid or expired token")
 			return
 		}
+		if m.revocations != nil {
+			revoked, err := m.revocations.IsRevoked(r.Context(), claims)
+			if err != nil {
+				// Revoked tokens must not work during an outage; ask the client to retry
+				m.metrics.recordFailure(r, AuthOutcomeStorageError)
+				w.Header().Set("Retry-After", unavailableRetryAfter)
+				m.writeJSONError(w, r, http.StatusServiceUnavailable, domain.ErrStorageUnavailable.Message, domain.ErrStorageUnavailable.Code)
+				return
+			}
+			if revoked {
+				m.metrics.recordFailure(r, AuthOutcomeRevokedToken)
+				m.writeUnauthorizedResponse(w, r, "Token has been revoked")
+				return
+			}
+		}
+		if claims.ClientID != "" || claims.Actor != nil {
+			hasScope := func(scope string) bool { return containsString(claims.Scopes, scope) }
+			if scope, ok := scopesAllow(hasScope, r.Method); !ok {
+				m.metrics.recordFailure(r, AuthOutcomeMissingScope)
+				kind := "Client"
+				if claims.ClientID == "" {
+					kind = "Delegated"
+				}
+				m.writeForbiddenResponse(w, r, kind+" token lacks the "+scope+" scope")
+				return
+			}
+		}
+		// Machine clients have no user whose role or activity could be tracked
+		if m.roles != nil && claims.Tenant == "" && claims.ClientID == "" {
+			role, err := m.roles.CurrentRole(r.Context(), claims.UserID)
+			if errors.Is(err, domain.ErrUserNotFound) {
+				m.metrics.recordFailure(r, AuthOutcomeUserNotFound)
+				m.writeUnauthorizedResponse(w, r, "User no longer exists")
+				return
+			}
+			// Tokens of suspended and banned users are refused even if they were not revoked
+			var statusErr *domain.Error
+			if errors.As(err, &statusErr) && (statusErr == domain.ErrAccountSuspended || statusErr == domain.ErrAccountBanned) {
+				m.metrics.recordFailure(r, AuthOutcomeInactiveUser)
+				m.writeJSONError(w, r, http.StatusForbidden, statusErr.Message, statusErr.Code)
+				return
+			}
+			if err != nil {
+				m.metrics.recordFailure(r, AuthOutcomeStorageError)
+				m.writeJSONError(w, r, http.StatusServiceUnavailable, domain.ErrStorageUnavailable.Message, domain.ErrStorageUnavailable.Code)
+				return
+			}
+			// Delegated tokens without the admin scope keep the default role
+			// they were issued with
+			delegatedRole := claims.Actor != nil && !containsString(claims.Scopes, domain.APIKeyScopeAdmin)
+			if role != claims.Role && !delegatedRole {
+				current := *claims
+				current.Role = role
+				claims = &current
+			}
+		}
+		if m.presence != nil && claims.ClientID == "" {
+			m.presence.RecordActivity(r.Context(), claims.UserID)
+		}
+		m.metrics.recordSuccess()
 
 		// Add user information to request context
 		ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, userEmailKey, claims.Email)
 		ctx = context.WithValue(ctx, userRoleKey, claims.Role)
+		ctx = context.WithValue(ctx, claimsKey, claims)
+		authMethod := "jwt"
+		switch {
+		case claims.ClientID != "":
+			authMethod = "client_credentials"
+		case claims.Actor != nil:
+			authMethod = "token_exchange"
+		}
+		ctx = context.WithValue(ctx, authMethodKey, authMethod)
 
 		// Call next handler with updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -95,17 +345,27 @@ func (m *JWTMiddleware) Authenticate(next http.Handler) http.Handler {
 
 // RequireRole is a middleware that checks if user has required role
 func (m *JWTMiddleware) RequireRole(role string) func(http.Handler) http.Handler {
+	return m.RequireAnyRole(role)
+}
+
+// RequireAnyRole is a middleware that checks if user has one of the given roles
+func (m *JWTMiddleware) RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userRole := r.Context().Value(userRoleKey)
 			if userRole == nil {
-				m.writeForbiddenResponse(w, "User role not found in context")
+				m.writeForbiddenResponse(w, r, "User role not found in context")
 				return
 			}
 
 			roleStr, ok := userRole.(string)
-			if !ok || roleStr != role {
-				m.writeForbiddenResponse(w, "Insufficient permissions")
+			if !ok || !allowed[roleStr] {
+				m.writeForbiddenResponse(w, r, "Insufficient permissions")
 				return
 			}
 
@@ -114,15 +374,133 @@ func (m *JWTMiddleware) RequireRole(role string) func(http.Handler) http.Handler
 	}
 }
 
-// RequireAdmin is a middleware that checks if user is admin
+// RequireScope is a middleware that checks if the principal has the scope,
+// either from its token or granted to its role. Principals authenticated
+// without a token, such as API keys, have the scopes of their role.
+func (m *JWTMiddleware) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !containsString(m.scopesOf(r.Context()), scope) {
+				m.metrics.recordFailure(r, AuthOutcomeMissingScope)
+				m.writeForbiddenResponse(w, r, "Missing the "+scope+" scope")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission is a middleware that checks if the principal's role
+// holds the permission under the policy engine
+func (m *JWTMiddleware) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := GetUserRoleFromContext(r.Context())
+			if !ok {
+				m.writeForbiddenResponse(w, r, "User role not found in context")
+				return
+			}
+			if !m.policy.Allows(role, permission) || !m.tenantAllows(r.Context(), permission) {
+				m.writeForbiddenResponse(w, r, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOwnerOrPermission is a middleware that lets the user named by the
+// path variable param act on their own resources; other principals need the
+// permission. Without a permission, only principals holding every permission,
+// as admins do, may act on other users' resources.
+func (m *JWTMiddleware) RequireOwnerOrPermission(param, permission string) func(http.Handler) http.Handler {
+	if permission == "" {
+		permission = policy.Wildcard
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := m.AuthorizeOwner(r.Context(), mux.Vars(r)[param], permission); err != nil {
+				m.writeForbiddenResponse(w, r, "Insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Can reports whether the principal's role holds the permission under the
+// policy engine, and its tenant, if any, under the tenant policy
+func (m *JWTMiddleware) Can(ctx context.Context, permission string) bool {
+	role, ok := GetUserRoleFromContext(ctx)
+	return ok && m.policy.Allows(role, permission) && m.tenantAllows(ctx, permission)
+}
+
+// tenantAllows reports whether a principal of a trusted issuer may use the
+// permission; principals of this deployment are only limited by their role
+func (m *JWTMiddleware) tenantAllows(ctx context.Context, permission string) bool {
+	claims, ok := GetTokenClaimsFromContext(ctx)
+	if !ok || claims.Tenant == "" {
+		return true
+	}
+	return m.tenantPolicy != nil && m.tenantPolicy.Allows(claims.Tenant, permission)
+}
+
+// AuthorizeOwner returns domain.ErrForbidden unless the principal is the
+// user owning the resource or its role holds the permission
+func (m *JWTMiddleware) AuthorizeOwner(ctx context.Context, ownerID, permission string) error {
+	if userID, ok := GetUserIDFromContext(ctx); ok && userID != "" && userID == ownerID {
+		return nil
+	}
+	if m.Can(ctx, permission) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
+
+// RequireAdmin is a middleware that checks if the principal holds every
+// permission, as admins do under the default policy
 func (m *JWTMiddleware) RequireAdmin(next http.Handler) http.Handler {
-	return m.RequireRole("admin")(next)
+	return m.RequirePermission(policy.Wildcard)(next)
 }
 
 // Helper methods
 
-func (m *JWTMiddleware) shouldSkipPath(path string) bool {
-	return m.skipPaths[path]
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// scopesOf returns the scopes of the request's principal
+func (m *JWTMiddleware) scopesOf(ctx context.Context) []string {
+	claims, hasClaims := GetTokenClaimsFromContext(ctx)
+	if hasClaims && (claims.ClientID != "" || claims.Actor != nil) {
+		return claims.Scopes
+	}
+	role, _ := GetUserRoleFromContext(ctx)
+	if !hasClaims {
+		return m.roleScopes[role]
+	}
+	return append(append([]string(nil), claims.Scopes...), m.roleScopes[role]...)
+}
+
+// matchSkipRule returns the first skip rule matching the request, if any
+func (m *JWTMiddleware) matchSkipRule(method, requestPath string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rule := range m.skipRules {
+		if rule.Matches(method, requestPath) {
+			return rule.String(), true
+		}
+	}
+	return "", false
 }
 
 func (m *JWTMiddleware) extractTokenFromHeader(r *http.Request) string {
@@ -141,28 +519,19 @@ func (m *JWTMiddleware) extractTokenFromHeader(r *http.Request) string {
 	return strings.TrimSpace(authHeader[len(bearerPrefix):])
 }
 
-func (m *JWTMiddleware) writeUnauthorizedResponse(w http.ResponseWriter, message string) {
-	m.writeJSONError(w, http.StatusUnauthorized, message, "UNAUTHORIZED")
+func (m *JWTMiddleware) writeUnauthorizedResponse(w http.ResponseWriter, r *http.Request, message string) {
+	m.writeJSONError(w, r, http.StatusUnauthorized, message, "UNAUTHORIZED")
 }
 
-func (m *JWTMiddleware) writeForbiddenResponse(w http.ResponseWriter, message string) {
-	m.writeJSONError(w, http.StatusForbidden, message, "FORBIDDEN")
+func (m *JWTMiddleware) writeForbiddenResponse(w http.ResponseWriter, r *http.Request, message string) {
+	m.writeJSONError(w, r, http.StatusForbidden, message, "FORBIDDEN")
 }
 
-func (m *JWTMiddleware) writeJSONError(w http.ResponseWriter, statusCode int, message, code string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	response := `{
-		"success": false,
-		"message": "` + message + `",
-		"error": {
-			"code": "` + code + `"
-		}
-	}`
+func (m *JWTMiddleware) writeJSONError(w http.ResponseWriter, r *http.Request, statusCode int, message, code string) {
+	writeJSONError(w, r, statusCode, message, code)
+}
 
-	if _, err := w.Write([]byte(response)); err != nil {
-		// Log the error but there's not much we can do at this point
-		// since we're already in an error handling path
-	}
+// writeJSONError writes the standard error envelope used across middleware
+func writeJSONError(w http.ResponseWriter, r *http.Request, statusCode int, message, code string) {
+	response.Error(w, r, statusCode, message, code)
 }
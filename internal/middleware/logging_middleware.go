@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	"demo-go/internal/logger"
+	"demo-go/internal/response"
+	"demo-go/internal/tracing"
 
 	"github.com/google/uuid"
 )
@@ -21,8 +22,24 @@ type loggingContextKey string
 
 const (
 	requestIDKey loggingContextKey = "request_id"
+	clientIPKey  loggingContextKey = "client_ip"
+	userAgentKey loggingContextKey = "user_agent"
 )
 
+// GetClientIPFromContext returns the client IP recorded by LoggingMiddleware,
+// for code that only has the request context
+func GetClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey).(string)
+	return ip, ok
+}
+
+// GetUserAgentFromContext returns the User-Agent recorded by LoggingMiddleware,
+// for code that only has the request context
+func GetUserAgentFromContext(ctx context.Context) (string, bool) {
+	userAgent, ok := ctx.Value(userAgentKey).(string)
+	return userAgent, ok
+}
+
 // LoggingMiddleware provides request logging with structured output
 func LoggingMiddleware(baseLogger *logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -32,10 +49,16 @@ func LoggingMiddleware(baseLogger *logger.Logger) func(http.Handler) http.Handle
 
 			// Create logger for this request
 			log := baseLogger.ForRequest(r.Method, r.URL.Path, requestID)
+			if span, ok := tracing.SpanFromContext(r.Context()); ok {
+				log = log.WithTrace(span.TraceIDString(), span.SpanIDString())
+			}
 
 			// Add request ID to context for downstream use
 			ctx := r.Context()
 			ctx = requestIDContext(ctx, requestID)
+			ctx = context.WithValue(ctx, clientIPKey, GetClientIP(r))
+			ctx = context.WithValue(ctx, userAgentKey, r.UserAgent())
+			ctx = response.ContextWithRequestInfo(ctx, requestID, start)
 			r = r.WithContext(ctx)
 
 			// Add request ID header to response
@@ -59,7 +82,7 @@ func LoggingMiddleware(baseLogger *logger.Logger) func(http.Handler) http.Handle
 			// Log incoming request (only for non-health checks to reduce noise)
 			if r.URL.Path != "/health" {
 				logMessage := fmt.Sprintf("→ Request started\nMethod: %s\nPath: %s\nUser-Agent: %s\nClient-IP: %s",
-					r.Method, r.URL.Path, r.UserAgent(), getClientIP(r))
+					r.Method, r.URL.Path, r.UserAgent(), GetClientIP(r))
 				
 				// Add pretty JSON request body if present
 				if len(requestBody) > 0 {
@@ -137,46 +160,6 @@ func (w *responseWriterWrapper) Write(data []byte) (int, error) {
 	return size, err
 }
 
-// CORSMiddleware provides CORS headers
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
-}
-
-// getClientIP extracts the real client IP from various headers
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if ips := strings.Split(xff, ","); len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-	
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-	
-	// Fall back to RemoteAddr
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return ip
-}
-
 // getStatusEmoji returns an emoji based on HTTP status code
 func getStatusEmoji(statusCode int) string {
 	switch {
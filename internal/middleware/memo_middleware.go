@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	"demo-go/internal/memo"
+)
+
+// RequestMemo gives every request its own memo so the service layer can
+// reuse lookups made earlier in the same request
+func RequestMemo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(memo.NewContext(r.Context())))
+	})
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+	"demo-go/internal/ratelimit"
+)
+
+// RateLimitMiddleware throttles requests using a limit chosen by the caller's
+// role, so trusted tooling is not throttled like anonymous traffic. It must
+// run after authentication so the principal is known.
+type RateLimitMiddleware struct {
+	limiter ratelimit.Limiter
+	config  config.RateLimitConfig
+	logger  *logger.Logger
+}
+
+// NewRateLimitMiddleware creates a new tiered rate limit middleware
+func NewRateLimitMiddleware(limiter ratelimit.Limiter, cfg config.RateLimitConfig) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter: limiter,
+		config:  cfg,
+		logger:  logger.GetGlobal().ForComponent("rate-limit-middleware"),
+	}
+}
+
+// Limit is a middleware that enforces the caller's tier limit
+func (m *RateLimitMiddleware) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tier, key := m.classify(r)
+
+		limit := m.config.Tiers[tier]
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result, err := m.limiter.Allow(r.Context(), "ratelimit:"+tier+":"+key, limit, m.config.Window)
+		if err != nil {
+			// Fail open: a broken limiter store must not take the API down
+			m.logger.Warn("Rate limiter unavailable", "tier", tier, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Tier", tier)
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			m.logger.Debug("Rate limit exceeded", "tier", tier, "key", key)
+			writeJSONError(w, r, http.StatusTooManyRequests, "Rate limit exceeded", "RATE_LIMITED")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// classify returns the rate limit tier and bucket key for a request
+func (m *RateLimitMiddleware) classify(r *http.Request) (tier, key string) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		return config.RateLimitTierAnonymous, GetClientIP(r)
+	}
+
+	role, _ := GetUserRoleFromContext(r.Context())
+	switch role {
+	case "admin":
+		return config.RateLimitTierAdmin, userID
+	case ServiceRole:
+		return config.RateLimitTierService, userID
+	default:
+		return config.RateLimitTierUser, userID
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"demo-go/internal/tracing"
+)
+
+// TraceIDHeader exposes the trace ID to clients for correlating with server logs
+const TraceIDHeader = "X-Trace-ID"
+
+// TracingMiddleware continues the caller's W3C trace (or starts a new one),
+// stores the server span in the request context and echoes it in the response
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var span tracing.SpanContext
+		if parent, ok := tracing.FromRequest(r); ok {
+			span = parent.NewChild()
+		} else {
+			span = tracing.NewRoot()
+		}
+
+		w.Header().Set(tracing.TraceParentHeader, span.TraceParent())
+		if span.TraceState != "" {
+			w.Header().Set(tracing.TraceStateHeader, span.TraceState)
+		}
+		w.Header().Set(TraceIDHeader, span.TraceIDString())
+
+		next.ServeHTTP(w, r.WithContext(tracing.ContextWithSpan(r.Context(), span)))
+	})
+}
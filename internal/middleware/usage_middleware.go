@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// UsageTracking counts the requests of authenticated principals, with their
+// status and latency, in recorder. It must run after authentication;
+// anonymous requests are not counted.
+func UsageTracking(recorder domain.UsageRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := usagePrincipal(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			wrapped := &responseWriterWrapper{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+			recorder.Record(principal, wrapped.statusCode, time.Since(start))
+		})
+	}
+}
+
+// usagePrincipal identifies the principal of a request: the API key that
+// authenticated it, the machine client of its token, or its user
+func usagePrincipal(ctx context.Context) (domain.UsagePrincipal, bool) {
+	if key, ok := GetAPIKeyFromContext(ctx); ok {
+		return domain.UsagePrincipal{Type: domain.UsagePrincipalAPIKey, ID: key.ID, UserID: key.UserID}, true
+	}
+	if claims, ok := GetTokenClaimsFromContext(ctx); ok && claims.ClientID != "" {
+		return domain.UsagePrincipal{Type: domain.UsagePrincipalClient, ID: claims.ClientID, UserID: claims.ClientID}, true
+	}
+	if userID, ok := GetUserIDFromContext(ctx); ok && userID != "" {
+		return domain.UsagePrincipal{Type: domain.UsagePrincipalUser, ID: userID, UserID: userID}, true
+	}
+	return domain.UsagePrincipal{}, false
+}
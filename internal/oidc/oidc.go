@@ -0,0 +1,318 @@
+// Package oidc is an OpenID Connect relying party for the authorization code
+// flow with PKCE: it discovers a provider's endpoints, redeems authorization
+// codes and verifies the ID tokens the provider returns.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/httpclient"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// httpTimeout bounds each request to the provider
+	httpTimeout = 10 * time.Second
+	// maxResponseSize bounds how much of a provider response is read
+	maxResponseSize = 1 << 20
+	// minKeyRefresh bounds how often an unknown key ID reloads the key set
+	minKeyRefresh = time.Minute
+)
+
+// ErrUnavailable is wrapped by errors of requests the provider did not answer
+var ErrUnavailable = errors.New("identity provider is unavailable")
+
+// signingMethods are the ID token algorithms accepted. "none" and HMAC are
+// not: the client secret must not be usable to forge ID tokens.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Metadata is the part of the provider's discovery document the client uses
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is an OpenID Connect provider found through its discovery
+// document. Metadata is fetched on first use and the signing keys again when
+// an ID token names a key that is not known.
+type Provider struct {
+	cfg        config.OIDCConfig
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu           sync.Mutex
+	metadata     *Metadata
+	keys         map[string]crypto.PublicKey
+	keysLoadedAt time.Time
+}
+
+// NewProvider creates a provider client. httpClient may be nil.
+func NewProvider(cfg config.OIDCConfig, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &httpclient.New("oidc", httpclient.Config{Timeout: httpTimeout}).Client
+	}
+	if cfg.Leeway < 0 {
+		cfg.Leeway = 0
+	}
+	return &Provider{cfg: cfg, httpClient: httpClient, now: time.Now}
+}
+
+// Metadata returns the provider's discovery document
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.discover(ctx)
+}
+
+// discover fetches the discovery document unless it is known; p.mu must be held
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.IssuerURL, "/")
+	data, err := p.get(ctx, issuer+"/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	var metadata Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid discovery document: %w", err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %q, want %q", metadata.Issuer, p.cfg.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("discovery document lacks the authorization, token or JWKS endpoint")
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// AuthCodeURL returns the URL that starts a login at the provider. The
+// challenge is the S256 PKCE challenge of the verifier passed to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the raw ID token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		// Public clients identify themselves in the body
+		form.Set("client_id", p.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: token request: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("%w: token response: %v", ErrUnavailable, err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("%w: token endpoint answered %s", ErrUnavailable, resp.Status)
+	}
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint refused the code: %s %s", resp.Status, body.Error)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token response has no ID token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, lifetime and nonce of
+// an ID token and returns its claims
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithLeeway(p.cfg.Leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(p.now),
+	)
+	claims := jwt.MapClaims{}
+	var keyErr error
+	_, err = parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(ctx, kid)
+		keyErr = err
+		return key, err
+	})
+	if err != nil {
+		if errors.Is(keyErr, ErrUnavailable) {
+			return nil, keyErr
+		}
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return nil, errors.New("invalid ID token: no expiry")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, errors.New("invalid ID token: nonce does not match")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.cfg.ClientID {
+		return nil, fmt.Errorf("invalid ID token: issued to %q", azp)
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the given ID, reloading the key set when
+// the ID is not known and it was not reloaded recently
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && p.now().Sub(p.keysLoadedAt) < minKeyRefresh {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.get(ctx, metadata.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysLoadedAt = keys, p.now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a loaded key. Tokens without a key ID may only be used with
+// a key set of a single key.
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// get fetches a provider document
+func (p *Provider) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s answered %s", ErrUnavailable, url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return data, nil
+}
+
+// ValidateConfig checks the settings an enabled provider needs
+func ValidateConfig(cfg config.OIDCConfig) error {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return errors.New("OIDC_ISSUER_URL, OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required")
+	}
+	for _, raw := range []string{cfg.IssuerURL, cfg.RedirectURL} {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", raw)
+		}
+	}
+	for _, scope := range cfg.Scopes {
+		if scope == "openid" {
+			return nil
+		}
+	}
+	return errors.New("OIDC_SCOPES must include openid")
+}
@@ -0,0 +1,338 @@
+// Package outbox records emails before they are sent and delivers them in
+// the background. Each email's delivery state is persisted, failed
+// deliveries are retried with a growing delay, and admins can inspect the
+// outbox and resend what failed.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/service"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sendTimeout bounds one delivery attempt
+	sendTimeout = 30 * time.Second
+	// claimLease keeps a claimed email from being claimed again while it is
+	// being delivered; it outlasts an attempt
+	claimLease = 2 * sendTimeout
+	// maxRetryBackoff caps the delay between attempts
+	maxRetryBackoff = time.Hour
+	// maxBatch bounds the emails delivered in one round
+	maxBatch = 50
+	// MaxListLimit bounds the emails listed at once
+	MaxListLimit = 200
+)
+
+// Outbox is an EmailSender that records each email and delivers it in the
+// background, so callers never wait on the mail server and failures are
+// retried instead of lost
+type Outbox struct {
+	cfg          config.EmailOutboxConfig
+	repo         domain.EmailOutboxRepository
+	sender       service.EmailSender
+	auditService domain.AuditService
+	logger       *logger.Logger
+	now          func() time.Time
+
+	mu      sync.Mutex
+	started bool
+
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates an outbox delivering through sender. Nothing is delivered
+// until Start is called.
+func New(cfg config.EmailOutboxConfig, repo domain.EmailOutboxRepository, sender service.EmailSender, auditService domain.AuditService) *Outbox {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Minute
+	}
+
+	return &Outbox{
+		cfg:          cfg,
+		repo:         repo,
+		sender:       sender,
+		auditService: auditService,
+		logger:       logger.GetGlobal().ForComponent("email-outbox"),
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Send records the email as queued; it is delivered in the background
+func (o *Outbox) Send(ctx context.Context, message *service.EmailMessage) error {
+	now := o.now().UTC()
+	email := &domain.OutboxEmail{
+		ID:            uuid.New().String(),
+		Kind:          message.Kind,
+		To:            message.To,
+		Subject:       message.Subject,
+		Body:          message.Body,
+		Status:        domain.EmailStatusQueued,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := o.repo.Create(ctx, email); err != nil {
+		return err
+	}
+
+	o.logger.Info("Email queued", "email_id", email.ID, "kind", email.Kind)
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start begins delivering queued emails in the background
+func (o *Outbox) Start() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.started {
+		return
+	}
+	o.started = true
+	o.logger.Info("Email outbox started",
+		"poll_interval", o.cfg.PollInterval,
+		"max_attempts", o.cfg.MaxAttempts,
+		"retry_backoff", o.cfg.RetryBackoff,
+	)
+	go o.run()
+}
+
+// Close stops deliveries and waits for an in-flight one, or for ctx to
+// expire. Emails still queued are delivered after the next start.
+func (o *Outbox) Close(ctx context.Context) error {
+	o.mu.Lock()
+	started := o.started
+	o.mu.Unlock()
+
+	o.closeOnce.Do(func() { close(o.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-o.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// List returns the matching emails, newest first, and how many emails are
+// in each status
+func (o *Outbox) List(ctx context.Context, filter domain.OutboxEmailFilter) ([]*domain.OutboxEmail, map[string]int64, error) {
+	if filter.Limit <= 0 || filter.Limit > MaxListLimit {
+		filter.Limit = MaxListLimit
+	}
+	emails, err := o.repo.List(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := o.repo.CountByStatus(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return emails, counts, nil
+}
+
+// Get returns an email
+func (o *Outbox) Get(ctx context.Context, id string) (*domain.OutboxEmail, error) {
+	return o.repo.GetByID(ctx, id)
+}
+
+// Resend queues a failed or bounced email again with a fresh set of attempts
+func (o *Outbox) Resend(ctx context.Context, actorID, id string) (*domain.OutboxEmail, error) {
+	email, err := o.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if email.Status != domain.EmailStatusFailed && email.Status != domain.EmailStatusBounced {
+		return nil, domain.ErrEmailNotResendable
+	}
+
+	now := o.now().UTC()
+	previous := email.Status
+	email.Status = domain.EmailStatusQueued
+	email.Attempts = 0
+	email.NextAttemptAt = &now
+	email.UpdatedAt = now
+	if err := o.repo.Update(ctx, email); err != nil {
+		return nil, err
+	}
+
+	o.record(ctx, domain.AuditActionEmailResent, actorID, email, map[string]interface{}{"previous_status": previous})
+	o.logger.Info("Email queued again", "email_id", email.ID, "actor_id", actorID, "previous_status", previous)
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return email, nil
+}
+
+// MarkBounced records that a sent email bounced, as reported by the mail
+// provider after delivery
+func (o *Outbox) MarkBounced(ctx context.Context, actorID, id, reason string) (*domain.OutboxEmail, error) {
+	email, err := o.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if email.Status != domain.EmailStatusSent {
+		return nil, domain.ErrEmailNotSent
+	}
+
+	email.Status = domain.EmailStatusBounced
+	email.LastError = reason
+	email.UpdatedAt = o.now().UTC()
+	if err := o.repo.Update(ctx, email); err != nil {
+		return nil, err
+	}
+
+	o.record(ctx, domain.AuditActionEmailBounced, actorID, email, map[string]interface{}{"reason": reason})
+	o.logger.Warn("Email bounced", "email_id", email.ID, "kind", email.Kind, "reason", reason)
+	return email, nil
+}
+
+// DeliverDue delivers the emails that are due; the background loop calls it
+// on every poll and whenever an email is queued
+func (o *Outbox) DeliverDue(ctx context.Context) {
+	for i := 0; i < maxBatch; i++ {
+		email, err := o.repo.ClaimDue(ctx, o.now().UTC(), claimLease)
+		if err != nil {
+			o.logger.Warn("Failed to claim due emails", "error", err)
+			return
+		}
+		if email == nil {
+			return
+		}
+		o.deliver(ctx, email)
+	}
+}
+
+func (o *Outbox) run() {
+	defer close(o.done)
+
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-o.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	o.DeliverDue(ctx)
+	for {
+		select {
+		case <-ticker.C:
+		case <-o.wake:
+		case <-o.stop:
+			return
+		}
+		o.DeliverDue(ctx)
+	}
+}
+
+// deliver makes one attempt and records its outcome
+func (o *Outbox) deliver(ctx context.Context, email *domain.OutboxEmail) {
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	err := o.sender.Send(sendCtx, &service.EmailMessage{Kind: email.Kind, To: email.To, Subject: email.Subject, Body: email.Body})
+	cancel()
+
+	now := o.now().UTC()
+	email.Attempts++
+	email.UpdatedAt = now
+	log := o.logger.WithField("email_id", email.ID)
+	switch {
+	case err == nil:
+		email.Status = domain.EmailStatusSent
+		email.SentAt = &now
+		email.NextAttemptAt = nil
+		email.LastError = ""
+		// The body may carry tokens; it is not needed once sent
+		email.Body = ""
+		log.Info("Email sent", "kind", email.Kind, "attempts", email.Attempts)
+	case isPermanent(err):
+		email.Status = domain.EmailStatusBounced
+		email.NextAttemptAt = nil
+		email.LastError = err.Error()
+		log.Warn("Email refused by the mail server", "kind", email.Kind, "error", err)
+	case email.Attempts >= o.cfg.MaxAttempts:
+		email.Status = domain.EmailStatusFailed
+		email.NextAttemptAt = nil
+		email.LastError = err.Error()
+		log.Error("Email delivery failed, giving up", "kind", email.Kind, "attempts", email.Attempts, "error", err)
+	default:
+		next := now.Add(o.backoff(email.Attempts))
+		email.NextAttemptAt = &next
+		email.LastError = err.Error()
+		log.Warn("Email delivery failed, will retry", "kind", email.Kind, "attempts", email.Attempts, "next_attempt_at", next, "error", err)
+	}
+
+	// The outcome is recorded even when shutdown cancelled the attempt
+	updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+	defer cancel()
+	if err := o.repo.Update(updateCtx, email); err != nil {
+		log.Error("Failed to record email delivery", "status", email.Status, "error", err)
+	}
+}
+
+// backoff is the delay after the given number of failed attempts
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// record writes an audit event for an email; a failed write is logged by
+// the audit service, not returned
+func (o *Outbox) record(ctx context.Context, action, actorID string, email *domain.OutboxEmail, details map[string]interface{}) {
+	if o.auditService == nil {
+		return
+	}
+	details["kind"] = email.Kind
+	_ = o.auditService.Record(ctx, &domain.AuditEvent{
+		Action:   action,
+		ActorID:  actorID,
+		TargetID: email.ID,
+		Details:  details,
+	})
+}
+
+// isPermanent reports whether the mail server refused the email for good,
+// such as for an unknown mailbox (SMTP replies 550 to 554)
+func isPermanent(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply) && reply.Code >= 550 && reply.Code <= 554
+}
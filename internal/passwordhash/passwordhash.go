@@ -0,0 +1,201 @@
+// Package passwordhash hashes passwords with bcrypt or argon2id. Hashes are
+// self-describing, so whichever algorithm is configured for new hashes,
+// stored hashes of either algorithm keep verifying, and hashes made with
+// other settings can be recognized and upgraded on login.
+package passwordhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithms new hashes can be made with
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrMismatch is returned by Verify when the password does not match
+var ErrMismatch = errors.New("password does not match")
+
+// ErrUnknownFormat is returned by Verify for hashes of no supported algorithm
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+// argon2idPrefix starts hashes in the PHC string format,
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+const argon2idPrefix = "$argon2id$"
+
+// New creates the hasher configured by cfg
+func New(cfg config.PasswordHashConfig) (domain.PasswordHasher, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Algorithm)) {
+	case "", AlgorithmBcrypt:
+		return NewBcrypt(cfg.BcryptCost)
+	case AlgorithmArgon2id:
+		return NewArgon2id(cfg.Argon2)
+	}
+	return nil, fmt.Errorf("password hash algorithm must be %q or %q, got %q", AlgorithmBcrypt, AlgorithmArgon2id, cfg.Algorithm)
+}
+
+// Verify checks password against a hash of any supported algorithm
+func Verify(hash, password string) error {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return verifyArgon2id(hash, password)
+	case strings.HasPrefix(hash, "$2"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatch
+		}
+		return err
+	}
+	return ErrUnknownFormat
+}
+
+// bcryptHasher makes bcrypt hashes
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcrypt creates a bcrypt hasher; a zero cost takes bcrypt.DefaultCost
+func NewBcrypt(cost int) (domain.PasswordHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+	return &bcryptHasher{cost: cost}, nil
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h *bcryptHasher) Verify(hash, password string) error {
+	return Verify(hash, password)
+}
+
+// NeedsRehash is true for hashes of other algorithms and bcrypt hashes of
+// another cost
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// argon2idHasher makes argon2id hashes
+type argon2idHasher struct {
+	params config.Argon2Config
+}
+
+// NewArgon2id creates an argon2id hasher. Zero parameters take the
+// defaults, which follow the OWASP recommendation of 64 MiB, 3 passes and 2
+// lanes; memory is in KiB.
+func NewArgon2id(params config.Argon2Config) (domain.PasswordHasher, error) {
+	defaults := config.DefaultArgon2
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = defaults.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = defaults.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+	if params.Memory < 8*uint32(params.Parallelism) {
+		return nil, fmt.Errorf("argon2id memory must be at least 8 KiB per lane, got %d KiB for %d lanes", params.Memory, params.Parallelism)
+	}
+	if params.SaltLength < 8 || params.KeyLength < 16 {
+		return nil, fmt.Errorf("argon2id needs a salt of at least 8 bytes and a key of at least 16 bytes")
+	}
+	return &argon2idHasher{params: params}, nil
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *argon2idHasher) Verify(hash, password string) error {
+	return Verify(hash, password)
+}
+
+// NeedsRehash is true for hashes of other algorithms and argon2id hashes of
+// other parameters
+func (h *argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return parsed.params.Memory != h.params.Memory || parsed.params.Iterations != h.params.Iterations ||
+		parsed.params.Parallelism != h.params.Parallelism || parsed.params.SaltLength != h.params.SaltLength ||
+		parsed.params.KeyLength != h.params.KeyLength
+}
+
+// argon2idHash is a decoded argon2id hash
+type argon2idHash struct {
+	params    config.Argon2Config
+	salt, key []byte
+}
+
+// parseArgon2id decodes a hash in the PHC string format
+func parseArgon2id(hash string) (*argon2idHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || !strings.HasPrefix(hash, argon2idPrefix) {
+		return nil, ErrUnknownFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrUnknownFormat
+	}
+	parsed := &argon2idHash{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parsed.params.Memory, &parsed.params.Iterations, &parsed.params.Parallelism); err != nil || parsed.params.Parallelism == 0 {
+		return nil, ErrUnknownFormat
+	}
+	var err error
+	if parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownFormat
+	}
+	if parsed.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(parsed.key) == 0 {
+		return nil, ErrUnknownFormat
+	}
+	parsed.params.SaltLength = uint32(len(parsed.salt))
+	parsed.params.KeyLength = uint32(len(parsed.key))
+	return parsed, nil
+}
+
+// verifyArgon2id recomputes the key with the parameters stored in the hash
+func verifyArgon2id(hash, password string) error {
+	parsed, err := parseArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	p := parsed.params
+	computed := argon2.IDKey([]byte(password), parsed.salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(computed, parsed.key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package pathrule matches requests against rules of the form
+// "[METHOD ]PATTERN", as used for JWT skip paths and runtime route overrides
+package pathrule
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Rule matches requests by method and path
+type Rule struct {
+	method  string // empty matches any method
+	pattern string // exact path, or a path.Match glob when glob is set
+	glob    bool
+	prefix  string // set for "/**" patterns, which match everything below the prefix
+}
+
+// Exact returns a rule matching one path for any method
+func Exact(requestPath string) Rule {
+	return Rule{pattern: requestPath}
+}
+
+// Parse parses "[METHOD ]PATTERN", e.g. "/health", "GET /.well-known/*"
+// or "/admin-ui/**". A "*" matches within one path segment; a trailing "/**"
+// matches any depth below the prefix.
+func Parse(rule string) (Rule, error) {
+	rule = strings.TrimSpace(rule)
+
+	var parsed Rule
+	if method, pattern, found := strings.Cut(rule, " "); found {
+		parsed.method = strings.ToUpper(method)
+		rule = strings.TrimSpace(pattern)
+	}
+
+	if !strings.HasPrefix(rule, "/") {
+		return Rule{}, fmt.Errorf("path rule %q must start with /", rule)
+	}
+
+	parsed.pattern = rule
+	switch {
+	case strings.HasSuffix(rule, "/**"):
+		parsed.prefix = strings.TrimSuffix(rule, "**")
+	case strings.ContainsAny(rule, `*?[\`):
+		if _, err := path.Match(rule, ""); err != nil {
+			return Rule{}, fmt.Errorf("invalid path pattern %q: %w", rule, err)
+		}
+		parsed.glob = true
+	}
+
+	return parsed, nil
+}
+
+// Matches reports whether the rule applies to the request method and path
+func (r Rule) Matches(method, requestPath string) bool {
+	if r.method != "" && r.method != method {
+		return false
+	}
+	if r.prefix != "" {
+		return strings.HasPrefix(requestPath, r.prefix)
+	}
+	if !r.glob {
+		return r.pattern == requestPath
+	}
+	matched, _ := path.Match(r.pattern, requestPath)
+	return matched
+}
+
+// String returns the rule in the form it is parsed from
+func (r Rule) String() string {
+	if r.method == "" {
+		return r.pattern
+	}
+	return r.method + " " + r.pattern
+}
@@ -0,0 +1,95 @@
+// Package policy decides which roles hold which permissions from a
+// role → permission matrix, such as the one in POLICY_PERMISSIONS.
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"demo-go/internal/domain"
+)
+
+// Wildcard grants every permission
+const Wildcard = "*"
+
+// Matrix is a PolicyEngine backed by a fixed role → permission matrix
+type Matrix struct {
+	grants map[string][]string
+	// allowed holds the exact permissions and the "<area>." prefixes of
+	// the "<area>.*" wildcards granted to each role
+	allowed  map[string]map[string]bool
+	prefixes map[string][]string
+}
+
+// NewMatrix creates a policy from the permissions granted to each role. A
+// grant is a permission name, "<area>.*" for every permission of an area,
+// or "*" for every permission.
+func NewMatrix(grants map[string][]string) (*Matrix, error) {
+	m := &Matrix{
+		grants:   make(map[string][]string, len(grants)),
+		allowed:  make(map[string]map[string]bool, len(grants)),
+		prefixes: make(map[string][]string),
+	}
+	for role, permissions := range grants {
+		m.grants[role] = append([]string(nil), permissions...)
+		m.allowed[role] = make(map[string]bool, len(permissions))
+		for _, permission := range permissions {
+			switch area, isArea := strings.CutSuffix(permission, ".*"); {
+			case permission == Wildcard:
+				m.allowed[role][Wildcard] = true
+			case area == "" || strings.ContainsAny(area, " \t*"):
+				return nil, fmt.Errorf("invalid permission %q granted to role %q", permission, role)
+			case isArea:
+				m.prefixes[role] = append(m.prefixes[role], area+".")
+			default:
+				m.allowed[role][permission] = true
+			}
+		}
+	}
+	return m, nil
+}
+
+// Allows reports whether the role holds the permission
+func (m *Matrix) Allows(role, permission string) bool {
+	allowed := m.allowed[role]
+	if allowed[Wildcard] || allowed[permission] {
+		return true
+	}
+	for _, prefix := range m.prefixes[role] {
+		if strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the known permissions the role holds, followed by the
+// other permissions granted to it by name, sorted
+func (m *Matrix) Permissions(role string) []string {
+	permissions := []string{}
+	known := make(map[string]bool, len(domain.Permissions))
+	for _, permission := range domain.Permissions {
+		known[permission] = true
+		if m.Allows(role, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	var custom []string
+	for permission := range m.allowed[role] {
+		if !known[permission] && permission != Wildcard {
+			custom = append(custom, permission)
+		}
+	}
+	sort.Strings(custom)
+	return append(permissions, custom...)
+}
+
+// Grants returns a copy of the matrix as configured
+func (m *Matrix) Grants() map[string][]string {
+	grants := make(map[string][]string, len(m.grants))
+	for role, permissions := range m.grants {
+		grants[role] = append([]string(nil), permissions...)
+	}
+	return grants
+}
@@ -0,0 +1,60 @@
+// Package queryplan collects database query plans for a single request. When
+// an admin asks for explain output, the request context carries a Collector,
+// repositories add a plan summary for each query they run, and the plans are
+// returned in the response meta.
+package queryplan
+
+import (
+	"context"
+	"sync"
+)
+
+// Plan summarizes how the database executed one query
+type Plan struct {
+	Collection      string `json:"collection"`
+	Operation       string `json:"operation"`
+	Filter          string `json:"filter"`
+	Stages          string `json:"stages,omitempty"` // winning plan, outermost stage first, e.g. "LIMIT > FETCH > IXSCAN"
+	Index           string `json:"index,omitempty"`
+	KeysExamined    int64  `json:"keys_examined"`
+	DocsExamined    int64  `json:"docs_examined"`
+	Returned        int64  `json:"returned"`
+	ExecutionMillis int64  `json:"execution_ms"`
+	Error           string `json:"error,omitempty"`
+}
+
+// Collector gathers the plans of one request
+type Collector struct {
+	mu    sync.Mutex
+	plans []Plan
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that asks repositories to explain their queries
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &Collector{})
+}
+
+// FromContext returns the request's collector, or nil when explain was not requested
+func FromContext(ctx context.Context) *Collector {
+	c, _ := ctx.Value(contextKey{}).(*Collector)
+	return c
+}
+
+// Add records a plan
+func (c *Collector) Add(plan Plan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plans = append(c.plans, plan)
+}
+
+// Plans returns the recorded plans in query order
+func (c *Collector) Plans() []Plan {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Plan(nil), c.plans...)
+}
@@ -0,0 +1,131 @@
+// Package ratelimit provides request rate limiting primitives shared by the
+// HTTP middleware and services that need to throttle sensitive operations.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result describes the outcome of a rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter counts events per key within a time window
+type Limiter interface {
+	// Allow records one event for key and reports whether it is within limit
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	// Peek reports whether one more event for key would be within limit
+	// without recording it. RetryAfter is set when it would not be.
+	Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	// Reset clears the counter for key
+	Reset(ctx context.Context, key string) error
+}
+
+// window tracks the event count for one key in the current fixed window
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// memoryLimiter implements Limiter with fixed windows held in process memory
+type memoryLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval controls how often expired windows are evicted
+const sweepInterval = time.Minute
+
+// NewMemoryLimiter creates an in-memory fixed-window rate limiter
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{
+		windows:   make(map[string]*window),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Allow records an event and checks it against the limit
+func (l *memoryLimiter) Allow(_ context.Context, key string, limit int, period time.Duration) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &window{resetAt: now.Add(period)}
+		l.windows[key] = w
+	}
+
+	w.count++
+
+	result := Result{
+		Allowed:   w.count <= limit,
+		Limit:     limit,
+		Remaining: limit - w.count,
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !result.Allowed {
+		result.RetryAfter = w.resetAt.Sub(now)
+	}
+
+	return result, nil
+}
+
+// Peek checks the counter for key against the limit without recording an event
+func (l *memoryLimiter) Peek(_ context.Context, key string, limit int, _ time.Duration) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	result := Result{Allowed: true, Limit: limit, Remaining: limit}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		return result, nil
+	}
+
+	result.Remaining = limit - w.count
+	if result.Remaining <= 0 {
+		result.Remaining = 0
+		result.Allowed = false
+		result.RetryAfter = w.resetAt.Sub(now)
+	}
+
+	return result, nil
+}
+
+// Reset clears the counter for key
+func (l *memoryLimiter) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.windows, key)
+	return nil
+}
+
+// sweep evicts expired windows; callers must hold the lock
+func (l *memoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, w := range l.windows {
+		if !now.Before(w.resetAt) {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/fieldcrypt"
+)
+
+// EncryptedNoteRepository decorates a note repository so note bodies, current
+// and earlier revisions, are encrypted before they are stored
+type EncryptedNoteRepository struct {
+	domain.NoteRepository
+	cipher *fieldcrypt.Cipher
+}
+
+// NewEncryptedNoteRepository wraps inner with field encryption
+func NewEncryptedNoteRepository(inner domain.NoteRepository, cipher *fieldcrypt.Cipher) *EncryptedNoteRepository {
+	return &EncryptedNoteRepository{NoteRepository: inner, cipher: cipher}
+}
+
+// Create encrypts and stores a new note
+func (r *EncryptedNoteRepository) Create(ctx context.Context, note *domain.UserNote) error {
+	sealed, err := r.encrypt(ctx, note)
+	if err != nil {
+		return err
+	}
+	return r.NoteRepository.Create(ctx, sealed)
+}
+
+// Get returns a decrypted note by ID
+func (r *EncryptedNoteRepository) Get(ctx context.Context, id string) (*domain.UserNote, error) {
+	note, err := r.NoteRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.decrypt(ctx, note)
+}
+
+// Update encrypts and replaces an existing note
+func (r *EncryptedNoteRepository) Update(ctx context.Context, note *domain.UserNote) error {
+	sealed, err := r.encrypt(ctx, note)
+	if err != nil {
+		return err
+	}
+	return r.NoteRepository.Update(ctx, sealed)
+}
+
+// ListByUser returns the user's decrypted notes, newest first
+func (r *EncryptedNoteRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserNote, error) {
+	notes, err := r.NoteRepository.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.decryptAll(ctx, notes)
+}
+
+// ListByUsers returns decrypted notes keyed by user ID
+func (r *EncryptedNoteRepository) ListByUsers(ctx context.Context, userIDs []string) (map[string][]*domain.UserNote, error) {
+	byUser, err := r.NoteRepository.ListByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for userID, notes := range byUser {
+		if byUser[userID], err = r.decryptAll(ctx, notes); err != nil {
+			return nil, err
+		}
+	}
+	return byUser, nil
+}
+
+// ReplaceForUser encrypts and replaces all of the user's notes
+func (r *EncryptedNoteRepository) ReplaceForUser(ctx context.Context, userID string, notes []*domain.UserNote) error {
+	sealed := make([]*domain.UserNote, 0, len(notes))
+	for _, note := range notes {
+		noteCopy, err := r.encrypt(ctx, note)
+		if err != nil {
+			return err
+		}
+		sealed = append(sealed, noteCopy)
+	}
+	return r.NoteRepository.ReplaceForUser(ctx, userID, sealed)
+}
+
+// Reencrypt rewrites the users' notes that are still plaintext or encrypted
+// with a previous key under the current key, and returns how many needed it.
+// A dry run only counts them. It is run after a key rotation, before the old
+// key is retired.
+func (r *EncryptedNoteRepository) Reencrypt(ctx context.Context, userIDs []string, dryRun bool) (int, error) {
+	byUser, err := r.NoteRepository.ListByUsers(ctx, userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for _, notes := range byUser {
+		for _, note := range notes {
+			stale, err := r.needsRotation(ctx, note)
+			if err != nil {
+				return rewritten, err
+			}
+			if !stale {
+				continue
+			}
+			if !dryRun {
+				plain, err := r.decrypt(ctx, note)
+				if err != nil {
+					return rewritten, err
+				}
+				if err := r.Update(ctx, plain); err != nil {
+					return rewritten, err
+				}
+			}
+			rewritten++
+		}
+	}
+	return rewritten, nil
+}
+
+// needsRotation reports whether any encrypted field of note needs rewriting
+func (r *EncryptedNoteRepository) needsRotation(ctx context.Context, note *domain.UserNote) (bool, error) {
+	values := []string{note.Body}
+	for _, revision := range note.History {
+		values = append(values, revision.Body)
+	}
+	for _, value := range values {
+		stale, err := r.cipher.NeedsRotation(ctx, value)
+		if err != nil || stale {
+			return stale, err
+		}
+	}
+	return false, nil
+}
+
+// encrypt returns a copy of note with its bodies encrypted
+func (r *EncryptedNoteRepository) encrypt(ctx context.Context, note *domain.UserNote) (*domain.UserNote, error) {
+	return r.transform(ctx, note, r.cipher.Encrypt)
+}
+
+// decrypt returns a copy of note with its bodies decrypted
+func (r *EncryptedNoteRepository) decrypt(ctx context.Context, note *domain.UserNote) (*domain.UserNote, error) {
+	return r.transform(ctx, note, r.cipher.Decrypt)
+}
+
+func (r *EncryptedNoteRepository) decryptAll(ctx context.Context, notes []*domain.UserNote) ([]*domain.UserNote, error) {
+	plain := make([]*domain.UserNote, 0, len(notes))
+	for _, note := range notes {
+		noteCopy, err := r.decrypt(ctx, note)
+		if err != nil {
+			return nil, err
+		}
+		plain = append(plain, noteCopy)
+	}
+	return plain, nil
+}
+
+// transform applies fn to the bodies of a copy of note. Bodies are bound to
+// the note's ID, so an encrypted body cannot be moved to another note.
+func (r *EncryptedNoteRepository) transform(
+	ctx context.Context,
+	note *domain.UserNote,
+	fn func(ctx context.Context, value, aad string) (string, error),
+) (*domain.UserNote, error) {
+	aad := "user_note:" + note.ID
+	noteCopy := copyNote(note)
+
+	var err error
+	if noteCopy.Body, err = fn(ctx, note.Body, aad); err != nil {
+		return nil, err
+	}
+	for i := range noteCopy.History {
+		if noteCopy.History[i].Body, err = fn(ctx, noteCopy.History[i].Body, aad); err != nil {
+			return nil, err
+		}
+	}
+	return noteCopy, nil
+}
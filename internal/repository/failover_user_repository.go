@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// Server error codes MongoDB returns while a replica set has no writable primary
+var noPrimaryCodes = []int{
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// FailoverUserRepository decorates a user repository so writes that fail
+// because MongoDB has no primary wait and are retried instead of failing at
+// once. A bounded number of writes wait at a time; writes beyond that, and
+// writes still failing after the timeout, return domain.ErrStorageUnavailable
+// and are counted as dropped. Reads are passed through.
+type FailoverUserRepository struct {
+	domain.UserRepository
+	cfg    config.WriteBufferConfig
+	slots  chan struct{}
+	logger *logger.Logger
+
+	mu           sync.Mutex
+	queued       int
+	peakQueued   int
+	retriedOK    int64
+	dropped      int64
+	lastFailover time.Time
+}
+
+// NewFailoverUserRepository wraps inner with a write buffer
+func NewFailoverUserRepository(inner domain.UserRepository, cfg config.WriteBufferConfig) *FailoverUserRepository {
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = 250 * time.Millisecond
+	}
+	return &FailoverUserRepository{
+		UserRepository: inner,
+		cfg:            cfg,
+		slots:          make(chan struct{}, cfg.MaxPending),
+		logger:         logger.GetGlobal().ForComponent("mongo-write-buffer"),
+	}
+}
+
+// Create stores a new user. A retry of an insert that reached the primary
+// before the failover reports a duplicate; that counts as success when the
+// stored user is the one being created.
+func (r *FailoverUserRepository) Create(ctx context.Context, user *domain.User) error {
+	attempts := 0
+	return r.write(ctx, "create", func(ctx context.Context) error {
+		attempts++
+		err := r.UserRepository.Create(ctx, user)
+		if attempts > 1 && errors.Is(err, domain.ErrUserAlreadyExists) && user.ID != "" {
+			if stored, getErr := r.UserRepository.GetByID(ctx, user.ID); getErr == nil && stored.Email == user.Email {
+				return nil
+			}
+		}
+		return err
+	})
+}
+
+// Update replaces a user
+func (r *FailoverUserRepository) Update(ctx context.Context, id string, user *domain.User) error {
+	return r.write(ctx, "update", func(ctx context.Context) error {
+		return r.UserRepository.Update(ctx, id, user)
+	})
+}
+
+// Delete removes a user
+func (r *FailoverUserRepository) Delete(ctx context.Context, id string) error {
+	return r.write(ctx, "delete", func(ctx context.Context) error {
+		return r.UserRepository.Delete(ctx, id)
+	})
+}
+
+// DeleteMany removes users
+func (r *FailoverUserRepository) DeleteMany(ctx context.Context, ids []string) (int64, error) {
+	var deleted int64
+	err := r.write(ctx, "delete-many", func(ctx context.Context) (err error) {
+		deleted, err = r.UserRepository.DeleteMany(ctx, ids)
+		return err
+	})
+	return deleted, err
+}
+
+// UpdateMany changes a field of many users
+func (r *FailoverUserRepository) UpdateMany(ctx context.Context, ids []string, update domain.UserFieldUpdate) (int64, error) {
+	var updated int64
+	err := r.write(ctx, "update-many", func(ctx context.Context) (err error) {
+		updated, err = r.UserRepository.UpdateMany(ctx, ids, update)
+		return err
+	})
+	return updated, err
+}
+
+// CheckHealth reports the write buffer; it is degraded while writes are waiting
+func (r *FailoverUserRepository) CheckHealth(_ context.Context) domain.ComponentHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	details := map[string]interface{}{
+		"queue_depth":      r.queued,
+		"queue_capacity":   r.cfg.MaxPending,
+		"peak_queue_depth": r.peakQueued,
+		"retried_writes":   r.retriedOK,
+		"dropped_writes":   r.dropped,
+	}
+	if !r.lastFailover.IsZero() {
+		details["last_failover_at"] = r.lastFailover
+	}
+	if r.queued > 0 {
+		return domain.ComponentHealth{Status: domain.HealthStatusDegraded, Details: details}
+	}
+	return domain.ComponentHealth{Status: domain.HealthStatusHealthy, Details: details}
+}
+
+// write runs op, and when it fails for lack of a primary, holds it in the
+// buffer and retries it every RetryInterval until it succeeds, fails otherwise, or
+// the timeout or ctx expires
+func (r *FailoverUserRepository) write(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	if !isNoPrimaryError(err) {
+		return err
+	}
+	log := r.logger.ForRepository("user", op)
+
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		r.drop()
+		log.Warn("Write buffer full, dropping write", "error", err)
+		return domain.ErrStorageUnavailable
+	}
+	r.enter()
+	defer func() {
+		<-r.slots
+		r.leave()
+	}()
+
+	deadline := time.NewTimer(r.cfg.Timeout)
+	defer deadline.Stop()
+	for {
+		wait := time.NewTimer(r.cfg.RetryInterval)
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			r.drop()
+			log.Warn("Buffered write abandoned by caller", "error", ctx.Err())
+			return ctx.Err()
+		case <-deadline.C:
+			wait.Stop()
+			r.drop()
+			log.Error("No primary before the write buffer timeout, dropping write", "timeout", r.cfg.Timeout, "error", err)
+			return domain.ErrStorageUnavailable
+		case <-wait.C:
+		}
+
+		if err = fn(ctx); !isNoPrimaryError(err) {
+			if err == nil {
+				r.mu.Lock()
+				r.retriedOK++
+				r.mu.Unlock()
+				log.Info("Buffered write succeeded after failover")
+			}
+			return err
+		}
+	}
+}
+
+func (r *FailoverUserRepository) enter() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queued++
+	if r.queued > r.peakQueued {
+		r.peakQueued = r.queued
+	}
+	r.lastFailover = time.Now()
+}
+
+func (r *FailoverUserRepository) leave() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queued--
+}
+
+func (r *FailoverUserRepository) drop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped++
+}
+
+// isNoPrimaryError reports whether err means the write may succeed once a
+// new primary is elected
+func isNoPrimaryError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) || mongo.IsNetworkError(err) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		if serverErr.HasErrorLabel("RetryableWriteError") {
+			return true
+		}
+		for _, code := range noPrimaryCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
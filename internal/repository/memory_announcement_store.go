@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"demo-go/internal/domain"
+)
+
+// memoryAnnouncementStore implements domain.AnnouncementStore using in-memory storage
+type memoryAnnouncementStore struct {
+	announcement *domain.Announcement
+	mu           sync.RWMutex
+}
+
+// NewMemoryAnnouncementStore creates a new in-memory announcement store
+func NewMemoryAnnouncementStore() domain.AnnouncementStore {
+	return &memoryAnnouncementStore{}
+}
+
+// Get returns the announcement
+func (s *memoryAnnouncementStore) Get(ctx context.Context) (*domain.Announcement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.announcement == nil {
+		return nil, domain.ErrAnnouncementNotFound
+	}
+	announcementCopy := *s.announcement
+	return &announcementCopy, nil
+}
+
+// Set stores the announcement, replacing any previous one
+func (s *memoryAnnouncementStore) Set(ctx context.Context, announcement *domain.Announcement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	announcementCopy := *announcement
+	s.announcement = &announcementCopy
+	return nil
+}
+
+// Delete removes the announcement
+func (s *memoryAnnouncementStore) Delete(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.announcement == nil {
+		return domain.ErrAnnouncementNotFound
+	}
+	s.announcement = nil
+	return nil
+}
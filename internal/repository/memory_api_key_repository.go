@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryAPIKeyRepository implements domain.APIKeyRepository using in-memory storage
+type memoryAPIKeyRepository struct {
+	keys map[string]*domain.APIKey
+	mu   sync.RWMutex
+}
+
+// NewMemoryAPIKeyRepository creates a new in-memory API key repository
+func NewMemoryAPIKeyRepository() domain.APIKeyRepository {
+	return &memoryAPIKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+// Create stores a new key
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys[key.ID] = copyAPIKey(key)
+	return nil
+}
+
+// GetByHash returns the key with the given hash
+func (r *memoryAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Hash == hash {
+			return copyAPIKey(key), nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+// ListByUser returns the user's keys, newest first
+func (r *memoryAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []*domain.APIKey{}
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, copyAPIKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Delete removes a key of the user
+func (r *memoryAPIKeyRepository) Delete(ctx context.Context, userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists || key.UserID != userID {
+		return domain.ErrAPIKeyNotFound
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// Touch records when the key was last used
+func (r *memoryAPIKeyRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &at
+	return nil
+}
+
+func copyAPIKey(key *domain.APIKey) *domain.APIKey {
+	keyCopy := *key
+	keyCopy.Scopes = append([]string(nil), key.Scopes...)
+	return &keyCopy
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// memoryAuditRepository implements domain.AuditRepository using in-memory storage
+type memoryAuditRepository struct {
+	events []*domain.AuditEvent // append-only, oldest first
+	mu     sync.RWMutex
+}
+
+// NewMemoryAuditRepository creates a new in-memory audit repository
+func NewMemoryAuditRepository() domain.AuditRepository {
+	return &memoryAuditRepository{}
+}
+
+// Create records a single audit event
+func (r *memoryAuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	return r.CreateMany(ctx, []*domain.AuditEvent{event})
+}
+
+// CreateMany records several audit events at once
+func (r *memoryAuditRepository) CreateMany(ctx context.Context, events []*domain.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		prepareAuditEvent(event)
+		eventCopy := *event
+		r.events = append(r.events, &eventCopy)
+	}
+
+	return nil
+}
+
+// List returns matching audit events, newest first
+func (r *memoryAuditRepository) List(
+	ctx context.Context,
+	filter domain.AuditFilter,
+	limit, offset int,
+) ([]*domain.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []*domain.AuditEvent{}
+	skipped := 0
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if !matchesAuditFilter(r.events[i], filter) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		eventCopy := *r.events[i]
+		events = append(events, &eventCopy)
+	}
+
+	return events, nil
+}
+
+// Count returns the number of matching audit events
+func (r *memoryAuditRepository) Count(ctx context.Context, filter domain.AuditFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, event := range r.events {
+		if matchesAuditFilter(event, filter) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// DeleteBefore removes events recorded before cutoff
+func (r *memoryAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := make([]*domain.AuditEvent, 0, len(r.events))
+	for _, event := range r.events {
+		if event.CreatedAt.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+	deleted := int64(len(r.events) - len(kept))
+	r.events = kept
+
+	return deleted, nil
+}
+
+// prepareAuditEvent assigns an ID and timestamp when the caller has not
+func prepareAuditEvent(event *domain.AuditEvent) {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+}
+
+func matchesAuditFilter(event *domain.AuditEvent, filter domain.AuditFilter) bool {
+	if filter.ActorID != "" && event.ActorID != filter.ActorID {
+		return false
+	}
+	if filter.TargetID != "" && event.TargetID != filter.TargetID {
+		return false
+	}
+	if filter.Action != "" && event.Action != filter.Action {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !event.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	if len(filter.Subjects) > 0 {
+		email, _ := event.Details["email"].(string)
+		for _, subject := range filter.Subjects {
+			if event.ActorID == subject || event.TargetID == subject || strings.EqualFold(email, subject) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryClientRepository implements domain.ClientRepository using in-memory storage
+type memoryClientRepository struct {
+	clients map[string]*domain.MachineClient
+	mu      sync.RWMutex
+}
+
+// NewMemoryClientRepository creates a new in-memory machine client repository
+func NewMemoryClientRepository() domain.ClientRepository {
+	return &memoryClientRepository{
+		clients: make(map[string]*domain.MachineClient),
+	}
+}
+
+// Create stores a new client
+func (r *memoryClientRepository) Create(ctx context.Context, client *domain.MachineClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clients[client.ID] = copyClient(client)
+	return nil
+}
+
+// GetByID returns the client with the given ID
+func (r *memoryClientRepository) GetByID(ctx context.Context, id string) (*domain.MachineClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, exists := r.clients[id]
+	if !exists {
+		return nil, domain.ErrClientNotFound
+	}
+	return copyClient(client), nil
+}
+
+// List returns every client, newest first
+func (r *memoryClientRepository) List(ctx context.Context) ([]*domain.MachineClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]*domain.MachineClient, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, copyClient(client))
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].CreatedAt.After(clients[j].CreatedAt)
+	})
+	return clients, nil
+}
+
+// Delete removes a client
+func (r *memoryClientRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[id]; !exists {
+		return domain.ErrClientNotFound
+	}
+	delete(r.clients, id)
+	return nil
+}
+
+// Touch records when the client last obtained a token
+func (r *memoryClientRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, exists := r.clients[id]
+	if !exists {
+		return domain.ErrClientNotFound
+	}
+	client.LastUsedAt = &at
+	return nil
+}
+
+func copyClient(client *domain.MachineClient) *domain.MachineClient {
+	clientCopy := *client
+	clientCopy.Scopes = append([]string(nil), client.Scopes...)
+	return &clientCopy
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryEmailOutboxRepository implements domain.EmailOutboxRepository using in-memory storage
+type memoryEmailOutboxRepository struct {
+	emails map[string]*domain.OutboxEmail
+	mu     sync.Mutex
+}
+
+// NewMemoryEmailOutboxRepository creates a new in-memory email outbox repository
+func NewMemoryEmailOutboxRepository() domain.EmailOutboxRepository {
+	return &memoryEmailOutboxRepository{
+		emails: make(map[string]*domain.OutboxEmail),
+	}
+}
+
+// Create stores a new email
+func (r *memoryEmailOutboxRepository) Create(ctx context.Context, email *domain.OutboxEmail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.emails[email.ID] = copyOutboxEmail(email)
+	return nil
+}
+
+// GetByID returns the email with the given ID
+func (r *memoryEmailOutboxRepository) GetByID(ctx context.Context, id string) (*domain.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email, exists := r.emails[id]
+	if !exists {
+		return nil, domain.ErrEmailNotFound
+	}
+	return copyOutboxEmail(email), nil
+}
+
+// Update replaces an email
+func (r *memoryEmailOutboxRepository) Update(ctx context.Context, email *domain.OutboxEmail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.emails[email.ID]; !exists {
+		return domain.ErrEmailNotFound
+	}
+	r.emails[email.ID] = copyOutboxEmail(email)
+	return nil
+}
+
+// List returns the matching emails, newest first
+func (r *memoryEmailOutboxRepository) List(ctx context.Context, filter domain.OutboxEmailFilter) ([]*domain.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	emails := make([]*domain.OutboxEmail, 0)
+	for _, email := range r.emails {
+		if (filter.Status == "" || email.Status == filter.Status) && (filter.To == "" || email.To == filter.To) {
+			emails = append(emails, copyOutboxEmail(email))
+		}
+	}
+	sort.Slice(emails, func(i, j int) bool {
+		return emails[i].CreatedAt.After(emails[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(emails) > filter.Limit {
+		emails = emails[:filter.Limit]
+	}
+	return emails, nil
+}
+
+// ClaimDue returns the queued email due the longest and leases it
+func (r *memoryEmailOutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due *domain.OutboxEmail
+	for _, email := range r.emails {
+		if email.Status != domain.EmailStatusQueued || email.NextAttemptAt == nil || email.NextAttemptAt.After(now) {
+			continue
+		}
+		if due == nil || email.NextAttemptAt.Before(*due.NextAttemptAt) {
+			due = email
+		}
+	}
+	if due == nil {
+		return nil, nil
+	}
+	leasedUntil := now.Add(lease)
+	due.NextAttemptAt = &leasedUntil
+	return copyOutboxEmail(due), nil
+}
+
+// CountByStatus counts the emails in each status
+func (r *memoryEmailOutboxRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int64)
+	for _, email := range r.emails {
+		counts[email.Status]++
+	}
+	return counts, nil
+}
+
+func copyOutboxEmail(email *domain.OutboxEmail) *domain.OutboxEmail {
+	emailCopy := *email
+	if email.NextAttemptAt != nil {
+		next := *email.NextAttemptAt
+		emailCopy.NextAttemptAt = &next
+	}
+	if email.SentAt != nil {
+		sent := *email.SentAt
+		emailCopy.SentAt = &sent
+	}
+	return &emailCopy
+}
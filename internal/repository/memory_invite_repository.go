@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryInviteRepository implements domain.InviteRepository using in-memory storage
+type memoryInviteRepository struct {
+	invites map[string]*domain.Invite
+	mu      sync.RWMutex
+}
+
+// NewMemoryInviteRepository creates a new in-memory invite repository
+func NewMemoryInviteRepository() domain.InviteRepository {
+	return &memoryInviteRepository{
+		invites: make(map[string]*domain.Invite),
+	}
+}
+
+// Create stores a new invite
+func (r *memoryInviteRepository) Create(ctx context.Context, invite *domain.Invite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invites[invite.ID] = copyInvite(invite)
+	return nil
+}
+
+// GetByHash returns the invite with the given code hash
+func (r *memoryInviteRepository) GetByHash(ctx context.Context, hash string) (*domain.Invite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invite := range r.invites {
+		if invite.Hash == hash {
+			return copyInvite(invite), nil
+		}
+	}
+	return nil, domain.ErrInviteNotFound
+}
+
+// List returns a page of invites, newest first, and the total count
+func (r *memoryInviteRepository) List(ctx context.Context, limit, offset int) ([]*domain.Invite, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invites := make([]*domain.Invite, 0, len(r.invites))
+	for _, invite := range r.invites {
+		invites = append(invites, copyInvite(invite))
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+
+	total := int64(len(invites))
+	if offset >= len(invites) {
+		return []*domain.Invite{}, total, nil
+	}
+	end := offset + limit
+	if limit <= 0 || end > len(invites) {
+		end = len(invites)
+	}
+	return invites[offset:end], total, nil
+}
+
+// Accept marks the invite used by the user unless it already was
+func (r *memoryInviteRepository) Accept(ctx context.Context, id, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, exists := r.invites[id]
+	if !exists {
+		return domain.ErrInviteNotFound
+	}
+	if invite.AcceptedAt != nil {
+		return domain.ErrInvalidInvite
+	}
+	invite.AcceptedAt = &at
+	invite.AcceptedBy = userID
+	return nil
+}
+
+// Delete removes an invite
+func (r *memoryInviteRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.invites[id]; !exists {
+		return domain.ErrInviteNotFound
+	}
+	delete(r.invites, id)
+	return nil
+}
+
+func copyInvite(invite *domain.Invite) *domain.Invite {
+	inviteCopy := *invite
+	if invite.AcceptedAt != nil {
+		acceptedAt := *invite.AcceptedAt
+		inviteCopy.AcceptedAt = &acceptedAt
+	}
+	return &inviteCopy
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryLoginHistoryRepository implements domain.LoginHistoryRepository using in-memory storage
+type memoryLoginHistoryRepository struct {
+	attempts  []*domain.LoginAttempt // oldest first
+	retention time.Duration
+	mu        sync.RWMutex
+}
+
+// NewMemoryLoginHistoryRepository creates a new in-memory login history
+// repository keeping attempts for retention; zero keeps them forever
+func NewMemoryLoginHistoryRepository(retention time.Duration) domain.LoginHistoryRepository {
+	return &memoryLoginHistoryRepository{retention: retention}
+}
+
+// Create records an attempt, dropping attempts past the retention on the way
+func (r *memoryLoginHistoryRepository) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.retention > 0 {
+		cutoff := time.Now().Add(-r.retention)
+		kept := 0
+		for kept < len(r.attempts) && r.attempts[kept].CreatedAt.Before(cutoff) {
+			kept++
+		}
+		r.attempts = r.attempts[kept:]
+	}
+
+	attemptCopy := *attempt
+	r.attempts = append(r.attempts, &attemptCopy)
+	return nil
+}
+
+// ListByUser returns a page of the user's attempts, newest first
+func (r *memoryLoginHistoryRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*domain.LoginAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attempts := []*domain.LoginAttempt{}
+	skipped := 0
+	for i := len(r.attempts) - 1; i >= 0 && len(attempts) < limit; i-- {
+		if r.attempts[i].UserID != userID {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		attemptCopy := *r.attempts[i]
+		attempts = append(attempts, &attemptCopy)
+	}
+	return attempts, nil
+}
+
+// CountByUser returns the number of the user's attempts
+func (r *memoryLoginHistoryRepository) CountByUser(ctx context.Context, userID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, attempt := range r.attempts {
+		if attempt.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
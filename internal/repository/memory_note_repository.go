@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"demo-go/internal/domain"
+)
+
+// memoryNoteRepository implements domain.NoteRepository using in-memory storage
+type memoryNoteRepository struct {
+	notes map[string]*domain.UserNote
+	mu    sync.RWMutex
+}
+
+// NewMemoryNoteRepository creates a new in-memory note repository
+func NewMemoryNoteRepository() domain.NoteRepository {
+	return &memoryNoteRepository{
+		notes: make(map[string]*domain.UserNote),
+	}
+}
+
+// Create stores a new note
+func (r *memoryNoteRepository) Create(ctx context.Context, note *domain.UserNote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notes[note.ID] = copyNote(note)
+	return nil
+}
+
+// Get returns a note by ID
+func (r *memoryNoteRepository) Get(ctx context.Context, id string) (*domain.UserNote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	note, exists := r.notes[id]
+	if !exists {
+		return nil, domain.ErrNoteNotFound
+	}
+	return copyNote(note), nil
+}
+
+// Update replaces an existing note
+func (r *memoryNoteRepository) Update(ctx context.Context, note *domain.UserNote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.notes[note.ID]; !exists {
+		return domain.ErrNoteNotFound
+	}
+	r.notes[note.ID] = copyNote(note)
+	return nil
+}
+
+// ListByUser returns the user's notes, newest first
+func (r *memoryNoteRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserNote, error) {
+	notes, err := r.ListByUsers(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if notes[userID] == nil {
+		return []*domain.UserNote{}, nil
+	}
+	return notes[userID], nil
+}
+
+// ListByUsers returns notes keyed by user ID, each newest first
+func (r *memoryNoteRepository) ListByUsers(ctx context.Context, userIDs []string) (map[string][]*domain.UserNote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	result := make(map[string][]*domain.UserNote)
+	for _, note := range r.notes {
+		if wanted[note.UserID] {
+			result[note.UserID] = append(result[note.UserID], copyNote(note))
+		}
+	}
+	for _, notes := range result {
+		sort.Slice(notes, func(i, j int) bool {
+			return notes[i].CreatedAt.After(notes[j].CreatedAt)
+		})
+	}
+
+	return result, nil
+}
+
+// ReplaceForUser replaces all of the user's notes
+func (r *memoryNoteRepository) ReplaceForUser(ctx context.Context, userID string, notes []*domain.UserNote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, note := range r.notes {
+		if note.UserID == userID {
+			delete(r.notes, id)
+		}
+	}
+	for _, note := range notes {
+		noteCopy := copyNote(note)
+		noteCopy.UserID = userID
+		r.notes[noteCopy.ID] = noteCopy
+	}
+
+	return nil
+}
+
+func copyNote(note *domain.UserNote) *domain.UserNote {
+	noteCopy := *note
+	noteCopy.History = append([]domain.NoteRevision(nil), note.History...)
+	return &noteCopy
+}
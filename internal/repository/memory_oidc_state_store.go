@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// oidcStateEntry is a stored single sign-on session
+type oidcStateEntry struct {
+	session   domain.OIDCSession
+	expiresAt time.Time
+}
+
+// memoryOIDCStateStore implements domain.OIDCStateStore using in-memory storage
+type memoryOIDCStateStore struct {
+	sessions map[string]oidcStateEntry // state -> entry
+	mu       sync.Mutex
+}
+
+// NewMemoryOIDCStateStore creates a new in-memory single sign-on state store
+func NewMemoryOIDCStateStore() domain.OIDCStateStore {
+	return &memoryOIDCStateStore{
+		sessions: make(map[string]oidcStateEntry),
+	}
+}
+
+// Save stores the session until ttl elapses
+func (s *memoryOIDCStateStore) Save(ctx context.Context, state string, session *domain.OIDCSession, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.sessions {
+		if now.After(entry.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+
+	s.sessions[state] = oidcStateEntry{session: *session, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Consume returns the session and removes it
+func (s *memoryOIDCStateStore) Consume(ctx context.Context, state string) (*domain.OIDCSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.sessions[state]
+	if !exists {
+		return nil, domain.ErrOIDCFailed
+	}
+	delete(s.sessions, state)
+
+	if time.Now().After(entry.expiresAt) {
+		return nil, domain.ErrOIDCFailed
+	}
+	session := entry.session
+	return &session, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"demo-go/internal/domain"
+)
+
+// memoryPreferencesRepository implements domain.PreferencesRepository using in-memory storage
+type memoryPreferencesRepository struct {
+	prefs map[string]*domain.UserPreferences
+	mu    sync.RWMutex
+}
+
+// NewMemoryPreferencesRepository creates a new in-memory preferences repository
+func NewMemoryPreferencesRepository() domain.PreferencesRepository {
+	return &memoryPreferencesRepository{
+		prefs: make(map[string]*domain.UserPreferences),
+	}
+}
+
+// Get returns the user's preferences, or nil when none have been saved
+func (r *memoryPreferencesRepository) Get(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, exists := r.prefs[userID]
+	if !exists {
+		return nil, nil
+	}
+
+	prefsCopy := *prefs
+	return &prefsCopy, nil
+}
+
+// GetMany returns saved preferences keyed by user ID
+func (r *memoryPreferencesRepository) GetMany(ctx context.Context, userIDs []string) (map[string]*domain.UserPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]*domain.UserPreferences, len(userIDs))
+	for _, id := range userIDs {
+		if prefs, exists := r.prefs[id]; exists {
+			prefsCopy := *prefs
+			result[id] = &prefsCopy
+		}
+	}
+
+	return result, nil
+}
+
+// Upsert saves the user's preferences, replacing any previous ones
+func (r *memoryPreferencesRepository) Upsert(ctx context.Context, prefs *domain.UserPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefsCopy := *prefs
+	r.prefs[prefs.UserID] = &prefsCopy
+	return nil
+}
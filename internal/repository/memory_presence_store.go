@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryPresenceStore implements domain.PresenceStore using in-memory
+// storage. Activity is only debounced within this instance.
+type memoryPresenceStore struct {
+	lastSeen map[string]time.Time
+	claims   map[string]time.Time // user ID -> end of the debounce interval
+	mu       sync.Mutex
+}
+
+// NewMemoryPresenceStore creates a new in-memory presence store
+func NewMemoryPresenceStore() domain.PresenceStore {
+	return &memoryPresenceStore{
+		lastSeen: make(map[string]time.Time),
+		claims:   make(map[string]time.Time),
+	}
+}
+
+// Claim succeeds once per debounce interval and user
+func (s *memoryPresenceStore) Claim(ctx context.Context, userID string, debounce time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if until, ok := s.claims[userID]; ok && now.Before(until) {
+		return false, nil
+	}
+	s.claims[userID] = now.Add(debounce)
+	return true, nil
+}
+
+// Record stores the last-seen time
+func (s *memoryPresenceStore) Record(ctx context.Context, userID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSeen[userID] = at
+	return nil
+}
+
+// LastSeen returns the last-seen time of each user
+func (s *memoryPresenceStore) LastSeen(ctx context.Context, userIDs []string) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]time.Time, len(userIDs))
+	for _, userID := range userIDs {
+		if seen, ok := s.lastSeen[userID]; ok {
+			result[userID] = seen
+		}
+	}
+	return result, nil
+}
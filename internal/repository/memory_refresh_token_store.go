@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// refreshTokenEntry is a stored refresh token and whether it was rotated
+type refreshTokenEntry struct {
+	record domain.RefreshTokenRecord
+	used   bool
+}
+
+// memoryRefreshTokenStore implements domain.RefreshTokenStore using in-memory storage
+type memoryRefreshTokenStore struct {
+	tokens  map[string]refreshTokenEntry // token hash -> entry
+	revoked map[string]time.Time         // family ID -> revocation expiry
+	mu      sync.Mutex
+}
+
+// NewMemoryRefreshTokenStore creates a new in-memory refresh token store
+func NewMemoryRefreshTokenStore() domain.RefreshTokenStore {
+	return &memoryRefreshTokenStore{
+		tokens:  make(map[string]refreshTokenEntry),
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Save stores the token until its record expires
+func (s *memoryRefreshTokenStore) Save(ctx context.Context, token string, record *domain.RefreshTokenRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, entry := range s.tokens {
+		if !now.Before(entry.record.ExpiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+	for family, expiresAt := range s.revoked {
+		if !now.Before(expiresAt) {
+			delete(s.revoked, family)
+		}
+	}
+
+	s.tokens[hashToken(token)] = refreshTokenEntry{record: *record}
+	return nil
+}
+
+// Get returns the token's record without marking it used
+func (s *memoryRefreshTokenStore) Get(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.lookup(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	record := entry.record
+	if entry.used {
+		return &record, domain.ErrRefreshTokenReused
+	}
+	return &record, nil
+}
+
+// Use marks the token as used and returns its record
+func (s *memoryRefreshTokenStore) Use(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := hashToken(token)
+	entry, err := s.lookup(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	record := entry.record
+	if entry.used {
+		return &record, domain.ErrRefreshTokenReused
+	}
+	entry.used = true
+	s.tokens[hash] = entry
+	return &record, nil
+}
+
+// lookup returns the entry of a token that is still valid; s.mu must be held
+func (s *memoryRefreshTokenStore) lookup(hash string) (refreshTokenEntry, error) {
+	entry, exists := s.tokens[hash]
+	if !exists {
+		return refreshTokenEntry{}, domain.ErrInvalidToken
+	}
+
+	now := time.Now()
+	if !now.Before(entry.record.ExpiresAt) {
+		delete(s.tokens, hash)
+		return refreshTokenEntry{}, domain.ErrInvalidToken
+	}
+	if expiresAt, revoked := s.revoked[entry.record.FamilyID]; revoked && now.Before(expiresAt) {
+		return refreshTokenEntry{}, domain.ErrInvalidToken
+	}
+	return entry, nil
+}
+
+// RevokeFamily invalidates every token of the family
+func (s *memoryRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked[familyID] = time.Now().Add(ttl)
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// resetTokenEntry is a stored reset token binding
+type resetTokenEntry struct {
+	binding   domain.ResetToken
+	expiresAt time.Time
+}
+
+// memoryResetTokenStore implements domain.ResetTokenStore using in-memory storage
+type memoryResetTokenStore struct {
+	tokens map[string]resetTokenEntry // token hash -> entry
+	mu     sync.Mutex
+}
+
+// NewMemoryResetTokenStore creates a new in-memory reset token store
+func NewMemoryResetTokenStore() domain.ResetTokenStore {
+	return &memoryResetTokenStore{
+		tokens: make(map[string]resetTokenEntry),
+	}
+}
+
+// Save stores the token's binding until ttl elapses
+func (s *memoryResetTokenStore) Save(ctx context.Context, token string, binding *domain.ResetToken, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, entry := range s.tokens {
+		if now.After(entry.expiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+
+	s.tokens[hashToken(token)] = resetTokenEntry{binding: *binding, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Consume returns the token's binding and removes the token
+func (s *memoryResetTokenStore) Consume(ctx context.Context, token string) (*domain.ResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := hashToken(token)
+	entry, exists := s.tokens[hash]
+	if !exists {
+		return nil, domain.ErrInvalidToken
+	}
+	delete(s.tokens, hash)
+
+	if time.Now().After(entry.expiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+	binding := entry.binding
+	return &binding, nil
+}
+
+// hashToken returns the hex SHA-256 of a token so raw tokens are never stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"demo-go/internal/domain"
+)
+
+// memoryRoleRepository implements domain.RoleRepository using in-memory storage
+type memoryRoleRepository struct {
+	roles map[string]*domain.Role
+	mu    sync.RWMutex
+}
+
+// NewMemoryRoleRepository creates a new in-memory role repository
+func NewMemoryRoleRepository() domain.RoleRepository {
+	return &memoryRoleRepository{
+		roles: make(map[string]*domain.Role),
+	}
+}
+
+// Create stores a new role
+func (r *memoryRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.Name]; exists {
+		return domain.ErrRoleExists
+	}
+	r.roles[role.Name] = copyRole(role)
+	return nil
+}
+
+// GetByName returns the role with the given name
+func (r *memoryRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, exists := r.roles[name]
+	if !exists {
+		return nil, domain.ErrRoleNotFound
+	}
+	return copyRole(role), nil
+}
+
+// List returns every role, sorted by name
+func (r *memoryRoleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]*domain.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, copyRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles, nil
+}
+
+// Update replaces a role
+func (r *memoryRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.Name]; !exists {
+		return domain.ErrRoleNotFound
+	}
+	r.roles[role.Name] = copyRole(role)
+	return nil
+}
+
+// Delete removes a role
+func (r *memoryRoleRepository) Delete(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[name]; !exists {
+		return domain.ErrRoleNotFound
+	}
+	delete(r.roles, name)
+	return nil
+}
+
+func copyRole(role *domain.Role) *domain.Role {
+	roleCopy := *role
+	roleCopy.Permissions = append([]string(nil), role.Permissions...)
+	return &roleCopy
+}
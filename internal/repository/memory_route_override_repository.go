@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"demo-go/internal/domain"
+)
+
+// memoryRouteOverrideRepository implements domain.RouteOverrideRepository using in-memory storage
+type memoryRouteOverrideRepository struct {
+	overrides map[string]*domain.RouteOverride
+	mu        sync.RWMutex
+}
+
+// NewMemoryRouteOverrideRepository creates a new in-memory route override repository
+func NewMemoryRouteOverrideRepository() domain.RouteOverrideRepository {
+	return &memoryRouteOverrideRepository{
+		overrides: make(map[string]*domain.RouteOverride),
+	}
+}
+
+// Create stores a new override
+func (r *memoryRouteOverrideRepository) Create(ctx context.Context, override *domain.RouteOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	overrideCopy := *override
+	r.overrides[override.ID] = &overrideCopy
+	return nil
+}
+
+// List returns every override, oldest first
+func (r *memoryRouteOverrideRepository) List(ctx context.Context) ([]*domain.RouteOverride, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	overrides := make([]*domain.RouteOverride, 0, len(r.overrides))
+	for _, override := range r.overrides {
+		overrideCopy := *override
+		overrides = append(overrides, &overrideCopy)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].CreatedAt.Before(overrides[j].CreatedAt)
+	})
+	return overrides, nil
+}
+
+// Delete removes an override
+func (r *memoryRouteOverrideRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.overrides[id]; !exists {
+		return domain.ErrRouteOverrideNotFound
+	}
+	delete(r.overrides, id)
+	return nil
+}
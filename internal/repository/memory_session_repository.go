@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memorySessionRepository implements domain.SessionRepository using in-memory storage
+type memorySessionRepository struct {
+	sessions map[string]*domain.Session
+	mu       sync.RWMutex
+}
+
+// NewMemorySessionRepository creates a new in-memory session repository
+func NewMemorySessionRepository() domain.SessionRepository {
+	return &memorySessionRepository{
+		sessions: make(map[string]*domain.Session),
+	}
+}
+
+// Save stores the session, dropping expired sessions on the way
+func (r *memorySessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, stored := range r.sessions {
+		if !now.Before(stored.ExpiresAt) {
+			delete(r.sessions, id)
+		}
+	}
+
+	r.sessions[session.ID] = copySession(session)
+	return nil
+}
+
+// GetByID returns a session of the user
+func (r *memorySessionRepository) GetByID(ctx context.Context, userID, id string) (*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, exists := r.sessions[id]
+	if !exists || session.UserID != userID {
+		return nil, domain.ErrSessionNotFound
+	}
+	return copySession(session), nil
+}
+
+// ListByUser returns the user's sessions, most recently seen first
+func (r *memorySessionRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := []*domain.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, copySession(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// Delete removes a session of the user
+func (r *memorySessionRepository) Delete(ctx context.Context, userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[id]
+	if !exists || session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+	delete(r.sessions, id)
+	return nil
+}
+
+// Search returns unexpired sessions of the user IDs or from the IP addresses
+func (r *memorySessionRepository) Search(ctx context.Context, queries []string, limit int) ([]*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(queries))
+	for _, query := range queries {
+		wanted[query] = true
+	}
+	now := time.Now()
+	sessions := []*domain.Session{}
+	for _, session := range r.sessions {
+		if (wanted[session.UserID] || wanted[session.IPAddress]) && now.Before(session.ExpiresAt) {
+			sessions = append(sessions, copySession(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func copySession(session *domain.Session) *domain.Session {
+	sessionCopy := *session
+	sessionCopy.AccessTokens = append([]domain.SessionToken(nil), session.AccessTokens...)
+	return &sessionCopy
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// userCutoff is a per-user revocation and how long it must be remembered
+type userCutoff struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+// memoryTokenRevocationStore implements domain.TokenRevocationStore using in-memory storage
+type memoryTokenRevocationStore struct {
+	tokens map[string]time.Time  // token ID -> revocation expiry
+	users  map[string]userCutoff // user ID -> cut-off
+	mu     sync.Mutex
+}
+
+// NewMemoryTokenRevocationStore creates a new in-memory token denylist
+func NewMemoryTokenRevocationStore() domain.TokenRevocationStore {
+	return &memoryTokenRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]userCutoff),
+	}
+}
+
+// Revoke denies the token until expiresAt
+func (s *memoryTokenRevocationStore) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired(time.Now())
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked
+func (s *memoryTokenRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, exists := s.tokens[tokenID]
+	return exists && time.Now().Before(expiresAt), nil
+}
+
+// RevokeUser denies the user's tokens issued at or before `before`
+func (s *memoryTokenRevocationStore) RevokeUser(ctx context.Context, userID string, before time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.purgeExpired(now)
+	s.users[userID] = userCutoff{before: before, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// RevokedBefore returns the user's cut-off, or the zero time
+func (s *memoryTokenRevocationStore) RevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff, exists := s.users[userID]
+	if !exists || !time.Now().Before(cutoff.expiresAt) {
+		return time.Time{}, nil
+	}
+	return cutoff.before, nil
+}
+
+// purgeExpired drops entries that no longer deny any valid token; callers hold mu
+func (s *memoryTokenRevocationStore) purgeExpired(now time.Time) {
+	for id, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, id)
+		}
+	}
+	for id, cutoff := range s.users {
+		if !now.Before(cutoff.expiresAt) {
+			delete(s.users, id)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryTokenStore implements domain.TokenStore using in-memory storage
+type memoryTokenStore struct {
+	tokens map[string]domain.TokenClaims // token hash -> claims
+	mu     sync.Mutex
+}
+
+// NewMemoryTokenStore creates a new in-memory opaque token store
+func NewMemoryTokenStore() domain.TokenStore {
+	return &memoryTokenStore{
+		tokens: make(map[string]domain.TokenClaims),
+	}
+}
+
+// Save stores the claims for the token; expiry is taken from ttl
+func (s *memoryTokenStore) Save(ctx context.Context, token string, claims *domain.TokenClaims, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, entry := range s.tokens {
+		if now.Unix() >= entry.Exp {
+			delete(s.tokens, hash)
+		}
+	}
+
+	entry := *claims
+	entry.Exp = now.Add(ttl).Unix()
+	s.tokens[hashToken(token)] = entry
+	return nil
+}
+
+// Get returns the claims bound to the token
+func (s *memoryTokenStore) Get(ctx context.Context, token string) (*domain.TokenClaims, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := hashToken(token)
+	entry, exists := s.tokens[hash]
+	if !exists {
+		return nil, domain.ErrInvalidToken
+	}
+	if time.Now().Unix() >= entry.Exp {
+		delete(s.tokens, hash)
+		return nil, domain.ErrInvalidToken
+	}
+	return &entry, nil
+}
+
+// Delete revokes the token
+func (s *memoryTokenStore) Delete(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, hashToken(token))
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// memoryUsageDays bounds the days of counters kept in memory
+const memoryUsageDays = 3
+
+// memoryUsageCounterStore implements domain.UsageCounterStore using
+// in-memory storage; each instance counts only its own requests
+type memoryUsageCounterStore struct {
+	days map[string]map[domain.UsagePrincipal]*domain.UsageCounts
+	mu   sync.Mutex
+}
+
+// NewMemoryUsageCounterStore creates a new in-memory usage counter store
+func NewMemoryUsageCounterStore() domain.UsageCounterStore {
+	return &memoryUsageCounterStore{days: make(map[string]map[domain.UsagePrincipal]*domain.UsageCounts)}
+}
+
+// Add adds counts to the principal's counters of the day
+func (s *memoryUsageCounterStore) Add(ctx context.Context, day string, principal domain.UsagePrincipal, counts domain.UsageCounts) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	principals, ok := s.days[day]
+	if !ok {
+		principals = make(map[domain.UsagePrincipal]*domain.UsageCounts)
+		s.days[day] = principals
+		s.dropOldDays()
+	}
+	total, ok := principals[principal]
+	if !ok {
+		total = &domain.UsageCounts{}
+		principals[principal] = total
+	}
+	total.Add(counts)
+	return nil
+}
+
+// List returns the counters of every principal seen on the day
+func (s *memoryUsageCounterStore) List(ctx context.Context, day string) ([]*domain.UsageRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]*domain.UsageRecord, 0, len(s.days[day]))
+	for principal, counts := range s.days[day] {
+		records = append(records, &domain.UsageRecord{Day: day, UsagePrincipal: principal, UsageCounts: *counts})
+	}
+	return records, nil
+}
+
+// dropOldDays keeps the most recent memoryUsageDays days
+func (s *memoryUsageCounterStore) dropOldDays() {
+	if len(s.days) <= memoryUsageDays {
+		return
+	}
+	days := make([]string, 0, len(s.days))
+	for day := range s.days {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	for _, day := range days[:len(days)-memoryUsageDays] {
+		delete(s.days, day)
+	}
+}
+
+// memoryUsageRepository implements domain.UsageRepository using in-memory storage
+type memoryUsageRepository struct {
+	records map[string]*domain.UsageRecord // keyed by usageRecordID
+	mu      sync.RWMutex
+}
+
+// NewMemoryUsageRepository creates a new in-memory usage repository
+func NewMemoryUsageRepository() domain.UsageRepository {
+	return &memoryUsageRepository{records: make(map[string]*domain.UsageRecord)}
+}
+
+// Save creates or replaces the record of the day and principal
+func (r *memoryUsageRepository) Save(ctx context.Context, record *domain.UsageRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recordCopy := *record
+	if recordCopy.UpdatedAt.IsZero() {
+		recordCopy.UpdatedAt = time.Now().UTC()
+	}
+	r.records[usageRecordID(record)] = &recordCopy
+	return nil
+}
+
+// List returns the matching records, newest day first
+func (r *memoryUsageRepository) List(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := []*domain.UsageRecord{}
+	for _, record := range r.records {
+		switch {
+		case filter.UserID != "" && record.UserID != filter.UserID,
+			filter.PrincipalType != "" && record.Type != filter.PrincipalType,
+			filter.PrincipalID != "" && record.ID != filter.PrincipalID,
+			filter.From != "" && record.Day < filter.From,
+			filter.To != "" && record.Day > filter.To:
+			continue
+		}
+		recordCopy := *record
+		records = append(records, &recordCopy)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Day != records[j].Day {
+			return records[i].Day > records[j].Day
+		}
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records, nil
+}
+
+// usageRecordID identifies the record of a day and principal
+func usageRecordID(record *domain.UsageRecord) string {
+	return record.Day + ":" + record.Type + ":" + record.ID
+}
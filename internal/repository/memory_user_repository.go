@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,11 +42,21 @@ func (r *memoryUserRepository) Create(ctx context.Context, user *domain.User) er
 	if user.ID == "" {
 		user.ID = strconv.Itoa(r.nextID)
 		r.nextID++
+	} else if _, exists := r.users[user.ID]; exists {
+		return domain.ErrUserAlreadyExists
+	} else if n, err := strconv.Atoi(user.ID); err == nil && n >= r.nextID {
+		// Keep generated IDs clear of explicitly assigned ones
+		r.nextID = n + 1
 	}
 
-	// Set creation time
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
+	// Set creation time unless the record carries its own (imported users)
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
 
 	// Store user
 	r.users[user.ID] = user
@@ -133,8 +145,9 @@ func (r *memoryUserRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List retrieves users with pagination from memory
-func (r *memoryUserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+// List retrieves users with pagination from memory. Fields are ignored since
+// there is no transfer cost; callers project the response themselves.
+func (r *memoryUserRepository) List(ctx context.Context, limit, offset int, fields ...string) ([]*domain.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -175,3 +188,235 @@ func (r *memoryUserRepository) Count(ctx context.Context) (int64, error) {
 
 	return int64(len(r.users)), nil
 }
+
+// GetByIDs retrieves the users matching the given IDs; unknown IDs are skipped
+func (r *memoryUserRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		if user, exists := r.users[id]; exists {
+			userCopy := *user
+			users = append(users, &userCopy)
+		}
+	}
+
+	return users, nil
+}
+
+// DeleteMany deletes the users matching the given IDs and returns how many were removed
+func (r *memoryUserRepository) DeleteMany(ctx context.Context, ids []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for _, id := range ids {
+		user, exists := r.users[id]
+		if !exists {
+			continue
+		}
+		delete(r.users, id)
+		delete(r.emails, user.Email)
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// UpdateMany applies the field update to the users matching the given IDs
+func (r *memoryUserRepository) UpdateMany(ctx context.Context, ids []string, update domain.UserFieldUpdate) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var updated int64
+	now := time.Now()
+	for _, id := range ids {
+		user, exists := r.users[id]
+		if !exists {
+			continue
+		}
+
+		userCopy := *user
+		if update.Role != nil {
+			userCopy.Role = *update.Role
+		}
+		if update.Status != nil {
+			userCopy.Status = *update.Status
+		}
+		userCopy.UpdatedAt = now
+		r.users[id] = &userCopy
+		updated++
+	}
+
+	return updated, nil
+}
+
+// Stats computes user totals, recent sign-ups and role distribution from memory
+func (r *memoryUserRepository) Stats(ctx context.Context, now time.Time) (*domain.UserStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now = now.UTC()
+	monthStart := now.AddDate(0, 0, -domain.StatsWindowDays)
+	stats := &domain.UserStats{
+		Total:            int64(len(r.users)),
+		RoleDistribution: make(map[string]int64),
+	}
+
+	daily := make(map[string]int64)
+	for _, user := range r.users {
+		stats.RoleDistribution[user.Role]++
+
+		created := user.CreatedAt.UTC()
+		if created.Before(monthStart) {
+			continue
+		}
+		stats.NewUsers.LastMonth++
+		if created.After(now.AddDate(0, 0, -7)) {
+			stats.NewUsers.LastWeek++
+		}
+		if created.After(now.AddDate(0, 0, -1)) {
+			stats.NewUsers.LastDay++
+		}
+		daily[created.Format(domain.DailyCountLayout)]++
+	}
+
+	for date, count := range daily {
+		stats.DailySignups = append(stats.DailySignups, domain.DailyCount{Date: date, Count: count})
+	}
+	sort.Slice(stats.DailySignups, func(i, j int) bool {
+		return stats.DailySignups[i].Date < stats.DailySignups[j].Date
+	})
+
+	return stats, nil
+}
+
+// Search returns the page of users matching the query from memory
+func (r *memoryUserRepository) Search(ctx context.Context, query *domain.UserQuery) ([]*domain.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.User
+	for _, user := range r.users {
+		if matchesUserQuery(user, query) {
+			userCopy := *user
+			matched = append(matched, &userCopy)
+		}
+	}
+
+	sortFields := query.Sort
+	if len(sortFields) == 0 {
+		sortFields = []domain.SortField{{Field: "created_at", Desc: true}}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, sf := range sortFields {
+			cmp := compareUserField(matched[i], matched[j], sf.Field)
+			if cmp == 0 {
+				continue
+			}
+			if sf.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	var total int64
+	if !query.SkipTotal {
+		total = int64(len(matched))
+	}
+	start := query.Offset
+	if start > len(matched) {
+		return []*domain.User{}, total, nil
+	}
+	end := start + query.Limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return matched[start:end], total, nil
+}
+
+func matchesUserQuery(user *domain.User, query *domain.UserQuery) bool {
+	if query.Search != "" {
+		search := strings.ToLower(query.Search)
+		if !strings.Contains(strings.ToLower(user.Name), search) && !strings.Contains(strings.ToLower(user.Email), search) {
+			return false
+		}
+	}
+
+	for _, filter := range query.Filters {
+		if !matchesFilter(user, filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesFilter(user *domain.User, filter domain.Filter) bool {
+	if t, ok := filter.Value.(time.Time); ok {
+		value := userTimeField(user, filter.Field)
+		switch filter.Op {
+		case domain.OpGt:
+			return value.After(t)
+		case domain.OpGte:
+			return !value.Before(t)
+		case domain.OpLt:
+			return value.Before(t)
+		case domain.OpLte:
+			return !value.After(t)
+		default:
+			return value.Equal(t)
+		}
+	}
+
+	value := userStringField(user, filter.Field)
+	switch filter.Op {
+	case domain.OpNe:
+		return value != filter.Value
+	case domain.OpIn:
+		for _, item := range filter.Value.([]string) {
+			if value == item {
+				return true
+			}
+		}
+		return false
+	case domain.OpContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(filter.Value.(string)))
+	default:
+		return value == filter.Value
+	}
+}
+
+func compareUserField(a, b *domain.User, field string) int {
+	if field == "created_at" || field == "updated_at" {
+		return userTimeField(a, field).Compare(userTimeField(b, field))
+	}
+	return strings.Compare(userStringField(a, field), userStringField(b, field))
+}
+
+func userStringField(user *domain.User, field string) string {
+	switch field {
+	case "name":
+		return user.Name
+	case "email":
+		return user.Email
+	case "role":
+		return user.Role
+	case "status":
+		if user.Status == "" {
+			return domain.UserStatusActive
+		}
+		return user.Status
+	}
+	return ""
+}
+
+func userTimeField(user *domain.User, field string) time.Time {
+	if field == "updated_at" {
+		return user.UpdatedAt
+	}
+	return user.CreatedAt
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// webAuthnSessionEntry is a stored ceremony session
+type webAuthnSessionEntry struct {
+	session   domain.WebAuthnSession
+	expiresAt time.Time
+}
+
+// memoryWebAuthnSessionStore implements domain.WebAuthnSessionStore using in-memory storage
+type memoryWebAuthnSessionStore struct {
+	sessions map[string]webAuthnSessionEntry // challenge -> entry
+	mu       sync.Mutex
+}
+
+// NewMemoryWebAuthnSessionStore creates a new in-memory passkey ceremony session store
+func NewMemoryWebAuthnSessionStore() domain.WebAuthnSessionStore {
+	return &memoryWebAuthnSessionStore{
+		sessions: make(map[string]webAuthnSessionEntry),
+	}
+}
+
+// Save stores the session until ttl elapses
+func (s *memoryWebAuthnSessionStore) Save(ctx context.Context, challenge string, session *domain.WebAuthnSession, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.sessions {
+		if now.After(entry.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+
+	s.sessions[challenge] = webAuthnSessionEntry{session: *session, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Consume returns the session and removes it
+func (s *memoryWebAuthnSessionStore) Consume(ctx context.Context, challenge string) (*domain.WebAuthnSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.sessions[challenge]
+	if !exists {
+		return nil, domain.ErrPasskeyFailed
+	}
+	delete(s.sessions, challenge)
+
+	if time.Now().After(entry.expiresAt) {
+		return nil, domain.ErrPasskeyFailed
+	}
+	session := entry.session
+	return &session, nil
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+const (
+	// maxPendingShadowReads bounds the comparisons running in the background;
+	// reads beyond that are not compared
+	maxPendingShadowReads = 32
+	// migrationDegradedWindow is how long a divergence or failed mirror write
+	// keeps the migration health check degraded
+	migrationDegradedWindow = 5 * time.Minute
+)
+
+// MigratingUserRepository decorates the user repository being migrated from
+// so every successful write is repeated on the target repository. The
+// primary stays the source of truth: its results are returned, and target
+// failures are logged and counted, never returned. With shadow reads, reads
+// by ID, email and the user count are repeated against the target in the
+// background and divergences are logged and counted. Updates of users the
+// target does not have yet copy them over, so the target fills up with the
+// users in use while the migration runs.
+type MigratingUserRepository struct {
+	domain.UserRepository
+	target  domain.UserRepository
+	cfg     config.RepositoryMigrationConfig
+	pending chan struct{}
+	logger  *logger.Logger
+
+	mu             sync.Mutex
+	mirrored       int64
+	failedWrites   int64
+	backfilled     int64
+	compared       int64
+	mismatches     int64
+	missing        int64
+	shadowErrors   int64
+	skipped        int64
+	lastDivergence time.Time
+}
+
+// NewMigratingUserRepository mirrors the writes of primary to target
+func NewMigratingUserRepository(primary, target domain.UserRepository, cfg config.RepositoryMigrationConfig) *MigratingUserRepository {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	return &MigratingUserRepository{
+		UserRepository: primary,
+		target:         target,
+		cfg:            cfg,
+		pending:        make(chan struct{}, maxPendingShadowReads),
+		logger:         logger.GetGlobal().ForComponent("repository-migration"),
+	}
+}
+
+// Create stores a new user in both repositories; the target gets the ID the
+// primary assigned
+func (r *MigratingUserRepository) Create(ctx context.Context, user *domain.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+	r.mirror(ctx, "create", func(ctx context.Context) error {
+		clone := *user
+		return r.target.Create(ctx, &clone)
+	})
+	return nil
+}
+
+// Update replaces a user in both repositories, copying it to the target when
+// it is not there yet
+func (r *MigratingUserRepository) Update(ctx context.Context, id string, user *domain.User) error {
+	if err := r.UserRepository.Update(ctx, id, user); err != nil {
+		return err
+	}
+	r.mirror(ctx, "update", func(ctx context.Context) error {
+		clone := *user
+		err := r.target.Update(ctx, id, &clone)
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return err
+		}
+		clone.ID = id
+		if err := r.target.Create(ctx, &clone); err != nil {
+			return err
+		}
+		r.count(&r.backfilled)
+		return nil
+	})
+	return nil
+}
+
+// Delete removes a user from both repositories
+func (r *MigratingUserRepository) Delete(ctx context.Context, id string) error {
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.mirror(ctx, "delete", func(ctx context.Context) error {
+		if err := r.target.Delete(ctx, id); !errors.Is(err, domain.ErrUserNotFound) {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+// DeleteMany removes users from both repositories
+func (r *MigratingUserRepository) DeleteMany(ctx context.Context, ids []string) (int64, error) {
+	deleted, err := r.UserRepository.DeleteMany(ctx, ids)
+	if err != nil {
+		return deleted, err
+	}
+	r.mirror(ctx, "delete-many", func(ctx context.Context) error {
+		_, err := r.target.DeleteMany(ctx, ids)
+		return err
+	})
+	return deleted, nil
+}
+
+// UpdateMany changes a field of many users in both repositories
+func (r *MigratingUserRepository) UpdateMany(ctx context.Context, ids []string, update domain.UserFieldUpdate) (int64, error) {
+	updated, err := r.UserRepository.UpdateMany(ctx, ids, update)
+	if err != nil {
+		return updated, err
+	}
+	r.mirror(ctx, "update-many", func(ctx context.Context) error {
+		_, err := r.target.UpdateMany(ctx, ids, update)
+		return err
+	})
+	return updated, nil
+}
+
+// GetByID reads a user from the primary, comparing it with the target
+func (r *MigratingUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	user, err := r.UserRepository.GetByID(ctx, id)
+	r.shadowUser(ctx, "get-by-id", user, err, func(ctx context.Context) (*domain.User, error) {
+		return r.target.GetByID(ctx, id)
+	})
+	return user, err
+}
+
+// GetByEmail reads a user from the primary, comparing it with the target
+func (r *MigratingUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := r.UserRepository.GetByEmail(ctx, email)
+	r.shadowUser(ctx, "get-by-email", user, err, func(ctx context.Context) (*domain.User, error) {
+		return r.target.GetByEmail(ctx, email)
+	})
+	return user, err
+}
+
+// Count counts the primary's users, comparing the count with the target's
+func (r *MigratingUserRepository) Count(ctx context.Context) (int64, error) {
+	count, err := r.UserRepository.Count(ctx)
+	if err != nil {
+		return count, err
+	}
+	r.shadow(ctx, "count", func(ctx context.Context) ([]string, error) {
+		targetCount, err := r.target.Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if targetCount != count {
+			return []string{"count"}, nil
+		}
+		return nil, nil
+	}, "primary_count", count)
+	return count, nil
+}
+
+// CheckHealth reports the mirror writes and comparisons; it is degraded
+// for a while after a divergence or a failed mirror write
+func (r *MigratingUserRepository) CheckHealth(_ context.Context) domain.ComponentHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	details := map[string]interface{}{
+		"mirrored_writes":   r.mirrored,
+		"failed_writes":     r.failedWrites,
+		"backfilled_users":  r.backfilled,
+		"shadow_reads":      r.cfg.ShadowReads,
+		"compared_reads":    r.compared,
+		"mismatched_reads":  r.mismatches,
+		"missing_in_target": r.missing,
+		"target_errors":     r.shadowErrors,
+		"skipped_reads":     r.skipped,
+	}
+	if !r.lastDivergence.IsZero() {
+		details["last_divergence_at"] = r.lastDivergence
+		if time.Since(r.lastDivergence) < migrationDegradedWindow {
+			return domain.ComponentHealth{Status: domain.HealthStatusDegraded, Details: details}
+		}
+	}
+	return domain.ComponentHealth{Status: domain.HealthStatusHealthy, Details: details}
+}
+
+// mirror repeats a write that succeeded on the primary on the target. It
+// runs even when the caller's context is done, because the primary already
+// holds the change.
+func (r *MigratingUserRepository) mirror(ctx context.Context, op string, fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.cfg.Timeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		r.mu.Lock()
+		r.failedWrites++
+		r.lastDivergence = time.Now()
+		r.mu.Unlock()
+		r.logger.ForRepository("user", op).Warn("Mirror write to the migration target failed", "error", err)
+		return
+	}
+	r.count(&r.mirrored)
+}
+
+// shadowUser compares a user read from the primary with the same read on the target
+func (r *MigratingUserRepository) shadowUser(ctx context.Context, op string, user *domain.User, err error,
+	read func(ctx context.Context) (*domain.User, error)) {
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return
+	}
+	userID := ""
+	if user != nil {
+		userID = user.ID
+	}
+	r.shadow(ctx, op, func(ctx context.Context) ([]string, error) {
+		targetUser, targetErr := read(ctx)
+		switch {
+		case errors.Is(targetErr, domain.ErrUserNotFound):
+			if user == nil {
+				return nil, nil
+			}
+			r.count(&r.missing)
+			return []string{"missing"}, nil
+		case targetErr != nil:
+			return nil, targetErr
+		case user == nil:
+			return []string{"unexpected"}, nil
+		}
+		return userDiff(user, targetUser), nil
+	}, "user_id", userID)
+}
+
+// shadow runs compare in the background when shadow reads are enabled;
+// compare returns the names of the fields that differ. Only field names
+// are logged, never their values.
+func (r *MigratingUserRepository) shadow(ctx context.Context, op string, compare func(ctx context.Context) ([]string, error), key string, value interface{}) {
+	if !r.cfg.ShadowReads {
+		return
+	}
+	select {
+	case r.pending <- struct{}{}:
+	default:
+		r.count(&r.skipped)
+		return
+	}
+
+	go func() {
+		defer func() { <-r.pending }()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.cfg.Timeout)
+		defer cancel()
+
+		log := r.logger.ForRepository("user", op).WithField(key, value)
+		diff, err := compare(ctx)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		switch {
+		case err != nil:
+			r.shadowErrors++
+			log.Warn("Shadow read on the migration target failed", "error", err)
+		case len(diff) > 0:
+			r.compared++
+			r.mismatches++
+			r.lastDivergence = time.Now()
+			log.Warn("Migration target diverges from the primary", "fields", diff)
+		default:
+			r.compared++
+		}
+	}()
+}
+
+func (r *MigratingUserRepository) count(counter *int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*counter++
+}
+
+// userDiff names the stored fields that differ between two copies of a
+// user. Times are compared to the millisecond, the precision MongoDB keeps.
+func userDiff(a, b *domain.User) []string {
+	var diff []string
+	check := func(name string, equal bool) {
+		if !equal {
+			diff = append(diff, name)
+		}
+	}
+	check("id", a.ID == b.ID)
+	check("name", a.Name == b.Name)
+	check("email", a.Email == b.Email)
+	check("password", a.Password == b.Password)
+	check("role", a.Role == b.Role)
+	check("status", a.Status == b.Status)
+	check("created_at", a.CreatedAt.Truncate(time.Millisecond).Equal(b.CreatedAt.Truncate(time.Millisecond)))
+	check("updated_at", a.UpdatedAt.Truncate(time.Millisecond).Equal(b.UpdatedAt.Truncate(time.Millisecond)))
+	check("security_questions", len(a.SecurityQuestions) == len(b.SecurityQuestions))
+	check("webauthn_credentials", len(a.WebAuthnCredentials) == len(b.WebAuthnCredentials))
+	check("external_identities", len(a.ExternalIdentities) == len(b.ExternalIdentities))
+	return diff
+}
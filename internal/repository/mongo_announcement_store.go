@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// announcementID is the _id of the single announcement document
+const announcementID = "current"
+
+// mongoAnnouncementStore implements domain.AnnouncementStore using MongoDB
+type mongoAnnouncementStore struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoAnnouncementStore creates a new MongoDB announcement store. The
+// collection holds one document, so it has no indexes beyond _id.
+func NewMongoAnnouncementStore(client *mongo.Client, cfg *config.Config) domain.AnnouncementStore {
+	log := logger.GetGlobal().ForComponent("mongo-announcement-store")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("announcements")
+
+	return &mongoAnnouncementStore{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Get returns the announcement
+func (s *mongoAnnouncementStore) Get(ctx context.Context) (*domain.Announcement, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var announcement domain.Announcement
+	filter := bson.M{"_id": announcementID}
+	s.debug.find(ctx, "get", filter, nil, 0, 1)
+	err = s.collection.FindOne(ctx, filter).Decode(&announcement)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrAnnouncementNotFound
+	}
+	if err != nil {
+		s.logger.ForRepository("announcement", "get").Error("Failed to get announcement", "error", err)
+		return nil, err
+	}
+
+	return &announcement, nil
+}
+
+// Set stores the announcement, replacing any previous one
+func (s *mongoAnnouncementStore) Set(ctx context.Context, announcement *domain.Announcement) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": announcementID}
+	s.debug.filter("set", filter)
+	if _, err := s.collection.ReplaceOne(ctx, filter, announcement, options.Replace().SetUpsert(true)); err != nil {
+		s.logger.ForRepository("announcement", "set").Error("Failed to store announcement", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the announcement
+func (s *mongoAnnouncementStore) Delete(ctx context.Context) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": announcementID}
+	s.debug.filter("delete", filter)
+	result, err := s.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		s.logger.ForRepository("announcement", "delete").Error("Failed to delete announcement", "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrAnnouncementNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoAPIKeyRepository implements domain.APIKeyRepository using MongoDB
+type mongoAPIKeyRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoAPIKeyRepository creates a new MongoDB API key repository
+func NewMongoAPIKeyRepository(client *mongo.Client, cfg *config.Config) domain.APIKeyRepository {
+	log := logger.GetGlobal().ForComponent("mongo-api-key-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("api_keys")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating API key indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create API key indexes", "error", err)
+	}
+
+	return &mongoAPIKeyRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new key
+func (r *mongoAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, key); err != nil {
+		r.logger.ForRepository("api_key", "create").Error("Failed to insert API key", "user_id", key.UserID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByHash returns the key with the given hash
+func (r *mongoAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var key domain.APIKey
+	filter := bson.M{"hash": hash}
+	r.debug.find(ctx, "get_by_hash", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("api_key", "get-by-hash").Error("Failed to get API key", "error", err)
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// ListByUser returns the user's keys, newest first
+func (r *mongoAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	r.debug.find(ctx, "list_by_user", filter, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("api_key", "list-by-user").Error("Failed to find API keys", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	keys := []*domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Delete removes a key of the user
+func (r *mongoAPIKeyRepository) Delete(ctx context.Context, userID, id string) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id, "user_id": userID}
+	r.debug.filter("delete", filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("api_key", "delete").Error("Failed to delete API key", "key_id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Touch records when the key was last used
+func (r *mongoAPIKeyRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	r.debug.filter("touch", filter)
+	if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_used_at": at}}); err != nil {
+		r.logger.ForRepository("api_key", "touch").Warn("Failed to record API key use", "key_id", id, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoAuditRepository implements domain.AuditRepository using MongoDB
+type mongoAuditRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoAuditRepository creates a new MongoDB audit repository
+func NewMongoAuditRepository(client *mongo.Client, cfg *config.Config) domain.AuditRepository {
+	log := logger.GetGlobal().ForComponent("mongo-audit-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("audit_events")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating audit event indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create audit event indexes", "error", err)
+	}
+
+	return &mongoAuditRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create records a single audit event
+func (r *mongoAuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	return r.CreateMany(ctx, []*domain.AuditEvent{event})
+}
+
+// CreateMany records several audit events in a single insert
+func (r *mongoAuditRepository) CreateMany(ctx context.Context, events []*domain.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	docs := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		prepareAuditEvent(event)
+		docs = append(docs, event)
+	}
+
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		r.logger.ForRepository("audit", "create-many").Error("Failed to insert audit events", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns matching audit events, newest first
+func (r *mongoAuditRepository) List(
+	ctx context.Context,
+	filter domain.AuditFilter,
+	limit, offset int,
+) ([]*domain.AuditEvent, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(sortDoc)
+
+	document := auditFilterDocument(filter)
+	r.debug.find(ctx, "list", document, sortDoc, int64(offset), int64(limit))
+	cursor, err := r.collection.Find(ctx, document, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	events := []*domain.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Count returns the number of matching audit events
+func (r *mongoAuditRepository) Count(ctx context.Context, filter domain.AuditFilter) (int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	document := auditFilterDocument(filter)
+	r.debug.count(ctx, "count", document)
+	return r.collection.CountDocuments(ctx, document)
+}
+
+// DeleteBefore removes events recorded before cutoff
+func (r *mongoAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	document := bson.M{"created_at": bson.M{"$lt": cutoff}}
+	r.debug.filter("delete-before", document)
+	result, err := r.collection.DeleteMany(ctx, document)
+	if err != nil {
+		r.logger.ForRepository("audit", "delete-before").Error("Failed to delete audit events", "error", err)
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+func auditFilterDocument(filter domain.AuditFilter) bson.M {
+	doc := bson.M{}
+	if filter.ActorID != "" {
+		doc["actor_id"] = filter.ActorID
+	}
+	if filter.TargetID != "" {
+		doc["target_id"] = filter.TargetID
+	}
+	if filter.Action != "" {
+		doc["action"] = filter.Action
+	}
+	if !filter.CreatedBefore.IsZero() {
+		doc["created_at"] = bson.M{"$lt": filter.CreatedBefore}
+	}
+	if len(filter.Subjects) > 0 {
+		subjects := bson.M{"$in": filter.Subjects}
+		doc["$or"] = bson.A{
+			bson.M{"actor_id": subjects},
+			bson.M{"target_id": subjects},
+			bson.M{"details.email": subjects},
+		}
+	}
+	return doc
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoClientRepository implements domain.ClientRepository using MongoDB
+type mongoClientRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoClientRepository creates a new MongoDB machine client repository
+func NewMongoClientRepository(client *mongo.Client, cfg *config.Config) domain.ClientRepository {
+	log := logger.GetGlobal().ForComponent("mongo-client-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("machine_clients")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating machine client indexes")
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}}); err != nil {
+		log.Warn("Failed to create machine client indexes", "error", err)
+	}
+
+	return &mongoClientRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new client
+func (r *mongoClientRepository) Create(ctx context.Context, client *domain.MachineClient) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, client); err != nil {
+		r.logger.ForRepository("client", "create").Error("Failed to insert machine client", "client_id", client.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns the client with the given ID
+func (r *mongoClientRepository) GetByID(ctx context.Context, id string) (*domain.MachineClient, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var client domain.MachineClient
+	filter := bson.M{"_id": id}
+	r.debug.find(ctx, "get_by_id", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&client)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrClientNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("client", "get-by-id").Error("Failed to get machine client", "client_id", id, "error", err)
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// List returns every client, newest first
+func (r *mongoClientRepository) List(ctx context.Context) ([]*domain.MachineClient, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{}
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	r.debug.find(ctx, "list", filter, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("client", "list").Error("Failed to find machine clients", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	clients := []*domain.MachineClient{}
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// Delete removes a client
+func (r *mongoClientRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	r.debug.filter("delete", filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("client", "delete").Error("Failed to delete machine client", "client_id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrClientNotFound
+	}
+
+	return nil
+}
+
+// Touch records when the client last obtained a token
+func (r *mongoClientRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	r.debug.filter("touch", filter)
+	if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_used_at": at}}); err != nil {
+		r.logger.ForRepository("client", "touch").Warn("Failed to record machine client use", "client_id", id, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoEmailOutboxRepository implements domain.EmailOutboxRepository using MongoDB
+type mongoEmailOutboxRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoEmailOutboxRepository creates a new MongoDB email outbox repository
+func NewMongoEmailOutboxRepository(client *mongo.Client, cfg *config.Config) domain.EmailOutboxRepository {
+	log := logger.GetGlobal().ForComponent("mongo-email-outbox-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("email_outbox")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating email outbox indexes")
+	indexes := []mongo.IndexModel{
+		// Claiming the next due email
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "to", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create email outbox indexes", "error", err)
+	}
+
+	return &mongoEmailOutboxRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new email
+func (r *mongoEmailOutboxRepository) Create(ctx context.Context, email *domain.OutboxEmail) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, email); err != nil {
+		r.logger.ForRepository("email_outbox", "create").Error("Failed to insert email", "email_id", email.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns the email with the given ID
+func (r *mongoEmailOutboxRepository) GetByID(ctx context.Context, id string) (*domain.OutboxEmail, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var email domain.OutboxEmail
+	filter := bson.M{"_id": id}
+	r.debug.find(ctx, "get_by_id", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&email)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrEmailNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("email_outbox", "get-by-id").Error("Failed to get email", "email_id", id, "error", err)
+		return nil, err
+	}
+
+	return &email, nil
+}
+
+// Update replaces an email
+func (r *mongoEmailOutboxRepository) Update(ctx context.Context, email *domain.OutboxEmail) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": email.ID}
+	r.debug.filter("update", filter)
+	result, err := r.collection.ReplaceOne(ctx, filter, email)
+	if err != nil {
+		r.logger.ForRepository("email_outbox", "update").Error("Failed to update email", "email_id", email.ID, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrEmailNotFound
+	}
+
+	return nil
+}
+
+// List returns the matching emails, newest first
+func (r *mongoEmailOutboxRepository) List(ctx context.Context, filter domain.OutboxEmailFilter) ([]*domain.OutboxEmail, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.To != "" {
+		query["to"] = filter.To
+	}
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	opts := options.Find().SetSort(sortDoc)
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	r.debug.find(ctx, "list", query, sortDoc, 0, int64(filter.Limit))
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		r.logger.ForRepository("email_outbox", "list").Error("Failed to find emails", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	emails := []*domain.OutboxEmail{}
+	if err := cursor.All(ctx, &emails); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// ClaimDue leases the queued email due the longest in a single update, so
+// concurrent instances never claim the same email
+func (r *mongoEmailOutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxEmail, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"status": domain.EmailStatusQueued, "next_attempt_at": bson.M{"$lte": now}}
+	r.debug.filter("claim_due", filter)
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+	var email domain.OutboxEmail
+	err = r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}, opts).Decode(&email)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.ForRepository("email_outbox", "claim-due").Error("Failed to claim due email", "error", err)
+		return nil, err
+	}
+
+	return &email, nil
+}
+
+// CountByStatus counts the emails in each status
+func (r *mongoEmailOutboxRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$status", "n": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		r.logger.ForRepository("email_outbox", "count-by-status").Error("Failed to count emails", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	var buckets []struct {
+		Status string `bson:"_id"`
+		N      int64  `bson:"n"`
+	}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Status] = bucket.N
+	}
+	return counts, nil
+}
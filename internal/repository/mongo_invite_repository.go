@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoInviteRepository implements domain.InviteRepository using MongoDB
+type mongoInviteRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoInviteRepository creates a new MongoDB invite repository
+func NewMongoInviteRepository(client *mongo.Client, cfg *config.Config) domain.InviteRepository {
+	log := logger.GetGlobal().ForComponent("mongo-invite-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("invites")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating invite indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create invite indexes", "error", err)
+	}
+
+	return &mongoInviteRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new invite
+func (r *mongoInviteRepository) Create(ctx context.Context, invite *domain.Invite) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, invite); err != nil {
+		r.logger.ForRepository("invite", "create").Error("Failed to insert invite", "email", invite.Email, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByHash returns the invite with the given code hash
+func (r *mongoInviteRepository) GetByHash(ctx context.Context, hash string) (*domain.Invite, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var invite domain.Invite
+	filter := bson.M{"hash": hash}
+	r.debug.find(ctx, "get_by_hash", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&invite)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrInviteNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("invite", "get-by-hash").Error("Failed to get invite", "error", err)
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// List returns a page of invites, newest first, and the total count
+func (r *mongoInviteRepository) List(ctx context.Context, limit, offset int) ([]*domain.Invite, int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cancel()
+
+	filter := bson.M{}
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(sortDoc)
+
+	r.debug.find(ctx, "list", filter, sortDoc, int64(offset), int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.ForRepository("invite", "list").Error("Failed to find invites", "error", err)
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	invites := []*domain.Invite{}
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, 0, err
+	}
+
+	r.debug.count(ctx, "count", filter)
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return invites, total, nil
+}
+
+// Accept marks the invite used by the user unless it already was
+func (r *mongoInviteRepository) Accept(ctx context.Context, id, userID string, at time.Time) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id, "accepted_at": bson.M{"$exists": false}}
+	r.debug.filter("accept", filter)
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"accepted_at": at, "accepted_by": userID}})
+	if err != nil {
+		r.logger.ForRepository("invite", "accept").Error("Failed to accept invite", "invite_id", id, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrInvalidInvite
+	}
+
+	return nil
+}
+
+// Delete removes an invite
+func (r *mongoInviteRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	r.debug.filter("delete", filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("invite", "delete").Error("Failed to delete invite", "invite_id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrInviteNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoLoginHistoryRepository implements domain.LoginHistoryRepository using
+// MongoDB. Attempts past the retention are removed by a TTL index on created_at.
+type mongoLoginHistoryRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoLoginHistoryRepository creates a new MongoDB login history repository
+func NewMongoLoginHistoryRepository(client *mongo.Client, cfg *config.Config) domain.LoginHistoryRepository {
+	log := logger.GetGlobal().ForComponent("mongo-login-history-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("login_history")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating login history indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if retention := cfg.LoginHistory.Retention; retention > 0 {
+		indexes = append(indexes, mongo.IndexModel{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(retention / time.Second)),
+		})
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create login history indexes", "error", err)
+	}
+
+	return &mongoLoginHistoryRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create records an attempt
+func (r *mongoLoginHistoryRepository) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, attempt); err != nil {
+		r.logger.ForRepository("login-history", "create").Error("Failed to record login attempt", "user_id", attempt.UserID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ListByUser returns a page of the user's attempts, newest first
+func (r *mongoLoginHistoryRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*domain.LoginAttempt, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset)).
+		SetSort(sortDoc)
+
+	r.debug.find(ctx, "list_by_user", filter, sortDoc, int64(offset), int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.ForRepository("login-history", "list-by-user").Error("Failed to find login attempts", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	attempts := []*domain.LoginAttempt{}
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// CountByUser returns the number of the user's attempts
+func (r *mongoLoginHistoryRepository) CountByUser(ctx context.Context, userID string) (int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	r.debug.count(ctx, "count_by_user", filter)
+	return r.collection.CountDocuments(ctx, filter)
+}
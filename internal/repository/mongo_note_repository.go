@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoNoteRepository implements domain.NoteRepository using MongoDB
+type mongoNoteRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoNoteRepository creates a new MongoDB note repository
+func NewMongoNoteRepository(client *mongo.Client, cfg *config.Config) domain.NoteRepository {
+	log := logger.GetGlobal().ForComponent("mongo-note-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("user_notes")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating user note indexes")
+	index := mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}}
+	if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+		log.Warn("Failed to create user note indexes", "error", err)
+	}
+
+	return &mongoNoteRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new note
+func (r *mongoNoteRepository) Create(ctx context.Context, note *domain.UserNote) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, note); err != nil {
+		r.logger.ForRepository("note", "create").Error("Failed to insert note", "user_id", note.UserID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Get returns a note by ID
+func (r *mongoNoteRepository) Get(ctx context.Context, id string) (*domain.UserNote, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var note domain.UserNote
+	filter := bson.M{"_id": id}
+	r.debug.find(ctx, "get", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&note)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNoteNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("note", "get").Error("Failed to get note", "note_id", id, "error", err)
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// Update replaces an existing note
+func (r *mongoNoteRepository) Update(ctx context.Context, note *domain.UserNote) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": note.ID}
+	r.debug.filter("update", filter)
+	result, err := r.collection.ReplaceOne(ctx, filter, note)
+	if err != nil {
+		r.logger.ForRepository("note", "update").Error("Failed to update note", "note_id", note.ID, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNoteNotFound
+	}
+
+	return nil
+}
+
+// ListByUser returns the user's notes, newest first
+func (r *mongoNoteRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserNote, error) {
+	notes, err := r.ListByUsers(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if notes[userID] == nil {
+		return []*domain.UserNote{}, nil
+	}
+	return notes[userID], nil
+}
+
+// ListByUsers returns notes keyed by user ID, each newest first
+func (r *mongoNoteRepository) ListByUsers(ctx context.Context, userIDs []string) (map[string][]*domain.UserNote, error) {
+	result := make(map[string][]*domain.UserNote)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"user_id": bson.M{"$in": userIDs}}
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
+	r.debug.find(ctx, "list_by_users", filter, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("note", "list-by-users").Error("Failed to find notes", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	for cursor.Next(ctx) {
+		var note domain.UserNote
+		if err := cursor.Decode(&note); err != nil {
+			return nil, err
+		}
+		result[note.UserID] = append(result[note.UserID], &note)
+	}
+
+	return result, cursor.Err()
+}
+
+// ReplaceForUser replaces all of the user's notes. The delete and insert are
+// separate writes, so a failed insert leaves the user without notes.
+func (r *mongoNoteRepository) ReplaceForUser(ctx context.Context, userID string, notes []*domain.UserNote) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	log := r.logger.ForRepository("note", "replace-for-user")
+	filter := bson.M{"user_id": userID}
+	r.debug.filter("replace_for_user", filter)
+	if _, err := r.collection.DeleteMany(ctx, filter); err != nil {
+		log.Error("Failed to delete notes", "user_id", userID, "error", err)
+		return err
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(notes))
+	for _, note := range notes {
+		noteCopy := *note
+		noteCopy.UserID = userID
+		docs = append(docs, &noteCopy)
+	}
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		log.Error("Failed to insert notes", "user_id", userID, "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoPreferencesRepository implements domain.PreferencesRepository using MongoDB
+type mongoPreferencesRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoPreferencesRepository creates a new MongoDB preferences repository.
+// Documents are keyed by user ID, so no secondary indexes are needed.
+func NewMongoPreferencesRepository(client *mongo.Client, cfg *config.Config) domain.PreferencesRepository {
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("user_preferences")
+	log := logger.GetGlobal().ForComponent("mongo-preferences-repository")
+	return &mongoPreferencesRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Get returns the user's preferences, or nil when none have been saved
+func (r *mongoPreferencesRepository) Get(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var prefs domain.UserPreferences
+	filter := bson.M{"_id": userID}
+	r.debug.find(ctx, "get", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&prefs)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.ForRepository("preferences", "get").Error("Failed to get preferences", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	return &prefs, nil
+}
+
+// GetMany returns saved preferences keyed by user ID
+func (r *mongoPreferencesRepository) GetMany(ctx context.Context, userIDs []string) (map[string]*domain.UserPreferences, error) {
+	result := make(map[string]*domain.UserPreferences, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": userIDs}}
+	r.debug.find(ctx, "get_many", filter, nil, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("preferences", "get-many").Error("Failed to find preferences", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var prefs domain.UserPreferences
+		if err := cursor.Decode(&prefs); err != nil {
+			return nil, err
+		}
+		result[prefs.UserID] = &prefs
+	}
+
+	return result, cursor.Err()
+}
+
+// Upsert saves the user's preferences, replacing any previous ones
+func (r *mongoPreferencesRepository) Upsert(ctx context.Context, prefs *domain.UserPreferences) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	r.debug.filter("upsert", bson.M{"_id": prefs.UserID})
+	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": prefs.UserID}, prefs, options.Replace().SetUpsert(true))
+	if err != nil {
+		r.logger.ForRepository("preferences", "upsert").Error("Failed to save preferences", "user_id", prefs.UserID, "error", err)
+		return err
+	}
+
+	return nil
+}
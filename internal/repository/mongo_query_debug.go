@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+	"demo-go/internal/queryplan"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// queryDebugger logs Mongo filters when query logging is enabled and explains
+// queries for requests that carry a queryplan.Collector
+type queryDebugger struct {
+	collection *mongo.Collection
+	logQueries bool
+	logger     *logger.Logger
+}
+
+func newQueryDebugger(collection *mongo.Collection, cfg config.MongoDBConfig, log *logger.Logger) queryDebugger {
+	return queryDebugger{collection: collection, logQueries: cfg.LogQueries, logger: log}
+}
+
+// filter logs a query filter
+func (d queryDebugger) filter(operation string, filter interface{}) {
+	if d.logQueries {
+		d.logger.Info("Mongo query", "collection", d.collection.Name(), "operation", operation, "filter", filterString(filter))
+	}
+}
+
+// find logs a find query and explains it when the request asked for it
+func (d queryDebugger) find(ctx context.Context, operation string, filter interface{}, sort bson.D, skip, limit int64) {
+	d.filter(operation, filter)
+	if queryplan.FromContext(ctx) == nil {
+		return
+	}
+
+	find := bson.D{{Key: "find", Value: d.collection.Name()}, {Key: "filter", Value: filter}}
+	if len(sort) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: sort})
+	}
+	if skip > 0 {
+		find = append(find, bson.E{Key: "skip", Value: skip})
+	}
+	if limit > 0 {
+		find = append(find, bson.E{Key: "limit", Value: limit})
+	}
+	d.explain(ctx, operation, filter, find)
+}
+
+// count logs a count query and explains it when the request asked for it
+func (d queryDebugger) count(ctx context.Context, operation string, filter interface{}) {
+	d.filter(operation, filter)
+	if queryplan.FromContext(ctx) == nil {
+		return
+	}
+
+	d.explain(ctx, operation, filter, bson.D{{Key: "count", Value: d.collection.Name()}, {Key: "query", Value: filter}})
+}
+
+// explain runs the explain command and adds its summary to the request's collector
+func (d queryDebugger) explain(ctx context.Context, operation string, filter interface{}, command bson.D) {
+	plan := queryplan.Plan{
+		Collection: d.collection.Name(),
+		Operation:  operation,
+		Filter:     filterString(filter),
+	}
+
+	var result struct {
+		QueryPlanner struct {
+			WinningPlan bson.M `bson:"winningPlan"`
+		} `bson:"queryPlanner"`
+		ExecutionStats struct {
+			NReturned           int64 `bson:"nReturned"`
+			ExecutionTimeMillis int64 `bson:"executionTimeMillis"`
+			TotalKeysExamined   int64 `bson:"totalKeysExamined"`
+			TotalDocsExamined   int64 `bson:"totalDocsExamined"`
+		} `bson:"executionStats"`
+	}
+	err := d.collection.Database().RunCommand(ctx, bson.D{
+		{Key: "explain", Value: command},
+		{Key: "verbosity", Value: "executionStats"},
+	}).Decode(&result)
+	if err != nil {
+		d.logger.Warn("Mongo explain failed", "collection", plan.Collection, "operation", operation, "error", err)
+		plan.Error = err.Error()
+	} else {
+		plan.Stages, plan.Index = planStages(result.QueryPlanner.WinningPlan)
+		plan.KeysExamined = result.ExecutionStats.TotalKeysExamined
+		plan.DocsExamined = result.ExecutionStats.TotalDocsExamined
+		plan.Returned = result.ExecutionStats.NReturned
+		plan.ExecutionMillis = result.ExecutionStats.ExecutionTimeMillis
+	}
+
+	queryplan.FromContext(ctx).Add(plan)
+}
+
+// planStages walks a winning plan from its root stage and returns the stage
+// chain and the first index used
+func planStages(stage bson.M) (string, string) {
+	var stages []string
+	index := ""
+	for stage != nil {
+		if name, ok := stage["stage"].(string); ok {
+			stages = append(stages, name)
+		}
+		if name, ok := stage["indexName"].(string); ok && index == "" {
+			index = name
+		}
+		next, _ := stage["inputStage"].(bson.M)
+		stage = next
+	}
+	return strings.Join(stages, " > "), index
+}
+
+// filterString renders a filter as relaxed extended JSON for logs and plans
+func filterString(filter interface{}) string {
+	data, err := bson.MarshalExtJSON(filter, false, false)
+	if err != nil {
+		return "<unprintable filter>"
+	}
+	return string(data)
+}
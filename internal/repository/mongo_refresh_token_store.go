@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// refreshTokenDocument is a refresh token as stored in MongoDB, by hash
+type refreshTokenDocument struct {
+	ID                        string `bson:"_id"`
+	domain.RefreshTokenRecord `bson:",inline"`
+	Uses                      int  `bson:"uses"`
+	Revoked                   bool `bson:"revoked"`
+}
+
+// mongoRefreshTokenStore implements domain.RefreshTokenStore using MongoDB.
+// Expired tokens are removed by a TTL index on expires_at.
+type mongoRefreshTokenStore struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+}
+
+// NewMongoRefreshTokenStore creates a new MongoDB refresh token store
+func NewMongoRefreshTokenStore(client *mongo.Client, cfg *config.Config) domain.RefreshTokenStore {
+	log := logger.GetGlobal().ForComponent("mongo-refresh-token-store")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("refresh_tokens")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating refresh token indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create refresh token indexes", "error", err)
+	}
+
+	return &mongoRefreshTokenStore{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+	}
+}
+
+// Save stores the token until its record expires
+func (s *mongoRefreshTokenStore) Save(ctx context.Context, token string, record *domain.RefreshTokenRecord) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	doc := &refreshTokenDocument{ID: hashToken(token), RefreshTokenRecord: *record}
+	if _, err := s.collection.InsertOne(ctx, doc); err != nil {
+		s.logger.ForRepository("refresh_token", "save").Error("Failed to insert refresh token", "user_id", record.UserID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the token's record without marking it used
+func (s *mongoRefreshTokenStore) Get(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var doc refreshTokenDocument
+	err = s.collection.FindOne(ctx, bson.M{"_id": hashToken(token)}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		s.logger.ForRepository("refresh_token", "get").Error("Failed to get refresh token", "error", err)
+		return nil, err
+	}
+	return doc.record()
+}
+
+// Use marks the token as used and returns its record. The use counter is
+// incremented atomically, so of two concurrent rotations only one succeeds.
+func (s *mongoRefreshTokenStore) Use(ctx context.Context, token string) (*domain.RefreshTokenRecord, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var doc refreshTokenDocument
+	err = s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": hashToken(token)},
+		bson.M{"$inc": bson.M{"uses": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		s.logger.ForRepository("refresh_token", "use").Error("Failed to use refresh token", "error", err)
+		return nil, err
+	}
+
+	return doc.record()
+}
+
+// record returns the document's record, or why the token cannot be used
+func (doc *refreshTokenDocument) record() (*domain.RefreshTokenRecord, error) {
+	// The TTL index removes expired tokens with a delay
+	if doc.Revoked || !time.Now().Before(doc.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+	if doc.Uses > 0 {
+		return &doc.RefreshTokenRecord, domain.ErrRefreshTokenReused
+	}
+	return &doc.RefreshTokenRecord, nil
+}
+
+// RevokeFamily invalidates every token of the family. Revoked tokens are
+// kept until they expire.
+func (s *mongoRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := s.collection.UpdateMany(ctx, bson.M{"family_id": familyID}, bson.M{"$set": bson.M{"revoked": true}}); err != nil {
+		s.logger.ForRepository("refresh_token", "revoke-family").Error("Failed to revoke refresh token family", "family_id", familyID, "error", err)
+		return err
+	}
+
+	return nil
+}
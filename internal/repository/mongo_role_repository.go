@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoRoleRepository implements domain.RoleRepository using MongoDB. Roles
+// are keyed by name, so no index beyond _id is needed.
+type mongoRoleRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoRoleRepository creates a new MongoDB role repository
+func NewMongoRoleRepository(client *mongo.Client, cfg *config.Config) domain.RoleRepository {
+	log := logger.GetGlobal().ForComponent("mongo-role-repository")
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("roles")
+
+	return &mongoRoleRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new role
+func (r *mongoRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, role); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrRoleExists
+		}
+		r.logger.ForRepository("role", "create").Error("Failed to insert role", "role", role.Name, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByName returns the role with the given name
+func (r *mongoRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var role domain.Role
+	filter := bson.M{"_id": name}
+	r.debug.find(ctx, "get_by_name", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrRoleNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("role", "get-by-name").Error("Failed to get role", "role", name, "error", err)
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// List returns every role, sorted by name
+func (r *mongoRoleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{}
+	sortDoc := bson.D{{Key: "_id", Value: 1}}
+	r.debug.find(ctx, "list", filter, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("role", "list").Error("Failed to find roles", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	roles := []*domain.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Update replaces a role
+func (r *mongoRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": role.Name}
+	r.debug.filter("update", filter)
+	result, err := r.collection.ReplaceOne(ctx, filter, role)
+	if err != nil {
+		r.logger.ForRepository("role", "update").Error("Failed to update role", "role", role.Name, "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a role
+func (r *mongoRoleRepository) Delete(ctx context.Context, name string) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": name}
+	r.debug.filter("delete", filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("role", "delete").Error("Failed to delete role", "role", name, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoRouteOverrideRepository implements domain.RouteOverrideRepository using MongoDB
+type mongoRouteOverrideRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoRouteOverrideRepository creates a new MongoDB route override
+// repository. The collection holds a handful of documents, so it has no
+// indexes beyond _id.
+func NewMongoRouteOverrideRepository(client *mongo.Client, cfg *config.Config) domain.RouteOverrideRepository {
+	log := logger.GetGlobal().ForComponent("mongo-route-override-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("route_overrides")
+
+	return &mongoRouteOverrideRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Create stores a new override
+func (r *mongoRouteOverrideRepository) Create(ctx context.Context, override *domain.RouteOverride) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, override); err != nil {
+		r.logger.ForRepository("route_override", "create").Error("Failed to insert route override", "rule", override.Rule, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns every override, oldest first
+func (r *mongoRouteOverrideRepository) List(ctx context.Context) ([]*domain.RouteOverride, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{}
+	sortDoc := bson.D{{Key: "created_at", Value: 1}}
+	r.debug.find(ctx, "list", filter, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("route_override", "list").Error("Failed to find route overrides", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	overrides := []*domain.RouteOverride{}
+	if err := cursor.All(ctx, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// Delete removes an override
+func (r *mongoRouteOverrideRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	r.debug.filter("delete", filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("route_override", "delete").Error("Failed to delete route override", "override_id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrRouteOverrideNotFound
+	}
+
+	return nil
+}
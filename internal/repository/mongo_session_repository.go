@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoSessionRepository implements domain.SessionRepository using MongoDB.
+// Expired sessions are removed by a TTL index on expires_at.
+type mongoSessionRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// NewMongoSessionRepository creates a new MongoDB session repository
+func NewMongoSessionRepository(client *mongo.Client, cfg *config.Config) domain.SessionRepository {
+	log := logger.GetGlobal().ForComponent("mongo-session-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("sessions")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating session indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+		{Keys: bson.D{{Key: "ip_address", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create session indexes", "error", err)
+	}
+
+	return &mongoSessionRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Save creates or replaces the session
+func (r *mongoSessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": session.ID}
+	r.debug.filter("save", filter)
+	if _, err := r.collection.ReplaceOne(ctx, filter, session, options.Replace().SetUpsert(true)); err != nil {
+		r.logger.ForRepository("session", "save").Error("Failed to save session", "user_id", session.UserID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns a session of the user
+func (r *mongoSessionRepository) GetByID(ctx context.Context, userID, id string) (*domain.Session, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var session domain.Session
+	filter := bson.M{"_id": id, "user_id": userID}
+	r.debug.find(ctx, "get_by_id", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		r.logger.ForRepository("session", "get-by-id").Error("Failed to get session", "session_id", id, "error", err)
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListByUser returns the user's sessions, most recently seen first
+func (r *mongoSessionRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	sortDoc := bson.D{{Key: "last_seen_at", Value: -1}}
+	r.debug.find(ctx, "list_by_user", filter, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("session", "list-by-user").Error("Failed to find sessions", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	sessions := []*domain.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Search returns unexpired sessions of the user IDs or from the IP addresses
+func (r *mongoSessionRepository) Search(ctx context.Context, queries []string, limit int) ([]*domain.Session, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	// The TTL index removes expired sessions only periodically
+	filter := bson.M{
+		"$or":        bson.A{bson.M{"user_id": bson.M{"$in": queries}}, bson.M{"ip_address": bson.M{"$in": queries}}},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	sortDoc := bson.D{{Key: "last_seen_at", Value: -1}}
+	r.debug.find(ctx, "search", filter, sortDoc, 0, int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sortDoc).SetLimit(int64(limit)))
+	if err != nil {
+		r.logger.ForRepository("session", "search").Error("Failed to search sessions", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	sessions := []*domain.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Delete removes a session of the user
+func (r *mongoSessionRepository) Delete(ctx context.Context, userID, id string) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": id, "user_id": userID}
+	r.debug.filter("delete", filter)
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		r.logger.ForRepository("session", "delete").Error("Failed to delete session", "session_id", id, "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
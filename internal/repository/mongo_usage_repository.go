@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoUsageRepository implements domain.UsageRepository using MongoDB
+type mongoUsageRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+	logger     *logger.Logger
+	debug      queryDebugger
+}
+
+// usageDocument is a usage record keyed by day and principal
+type usageDocument struct {
+	ID                 string `bson:"_id"`
+	domain.UsageRecord `bson:",inline"`
+}
+
+// NewMongoUsageRepository creates a new MongoDB usage repository
+func NewMongoUsageRepository(client *mongo.Client, cfg *config.Config) domain.UsageRepository {
+	log := logger.GetGlobal().ForComponent("mongo-usage-repository")
+
+	collection := client.Database(cfg.Database.MongoDB.Database).Collection("usage")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
+	defer cancel()
+
+	log.Debug("Creating usage indexes")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "day", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "day", Value: -1}}},
+		{Keys: bson.D{{Key: "principal_type", Value: 1}, {Key: "principal_id", Value: 1}, {Key: "day", Value: -1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		log.Warn("Failed to create usage indexes", "error", err)
+	}
+
+	return &mongoUsageRepository{
+		collection: collection,
+		timeout:    cfg.Database.MongoDB.Timeout,
+		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
+	}
+}
+
+// Save creates or replaces the record of the day and principal
+func (r *mongoUsageRepository) Save(ctx context.Context, record *domain.UsageRecord) error {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	doc := &usageDocument{ID: usageRecordID(record), UsageRecord: *record}
+	if doc.UpdatedAt.IsZero() {
+		doc.UpdatedAt = time.Now().UTC()
+	}
+	filter := bson.M{"_id": doc.ID}
+	r.debug.filter("save", filter)
+	if _, err := r.collection.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true)); err != nil {
+		r.logger.ForRepository("usage", "save").Error("Failed to store usage", "day", record.Day, "principal_id", record.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns the matching records, newest day first
+func (r *mongoUsageRepository) List(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageRecord, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	if filter.PrincipalType != "" {
+		query["principal_type"] = filter.PrincipalType
+	}
+	if filter.PrincipalID != "" {
+		query["principal_id"] = filter.PrincipalID
+	}
+	days := bson.M{}
+	if filter.From != "" {
+		days["$gte"] = filter.From
+	}
+	if filter.To != "" {
+		days["$lte"] = filter.To
+	}
+	if len(days) > 0 {
+		query["day"] = days
+	}
+	sortDoc := bson.D{{Key: "day", Value: -1}, {Key: "principal_type", Value: 1}, {Key: "principal_id", Value: 1}}
+	r.debug.find(ctx, "list", query, sortDoc, 0, 0)
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(sortDoc))
+	if err != nil {
+		r.logger.ForRepository("usage", "list").Error("Failed to find usage", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	var docs []usageDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	records := make([]*domain.UsageRecord, 0, len(docs))
+	for i := range docs {
+		records = append(records, &docs[i].UsageRecord)
+	}
+	return records, nil
+}
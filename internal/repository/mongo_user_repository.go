@@ -4,8 +4,10 @@ package repository
 
 import (
 	"context"
+	"regexp"
 	"time"
 
+	"demo-go/internal/budget"
 	"demo-go/internal/config"
 	"demo-go/internal/domain"
 	"demo-go/internal/logger"
@@ -21,6 +23,7 @@ type mongoUserRepository struct {
 	collection *mongo.Collection
 	timeout    time.Duration
 	logger     *logger.Logger
+	debug      queryDebugger
 }
 
 // NewMongoUserRepository creates a new MongoDB user repository
@@ -49,6 +52,7 @@ func NewMongoUserRepository(client *mongo.Client, cfg *config.Config) domain.Use
 		collection: collection,
 		timeout:    cfg.Database.MongoDB.Timeout,
 		logger:     log,
+		debug:      newQueryDebugger(collection, cfg.Database.MongoDB, log),
 	}
 }
 
@@ -56,14 +60,22 @@ func NewMongoUserRepository(client *mongo.Client, cfg *config.Config) domain.Use
 func (r *mongoUserRepository) Create(ctx context.Context, user *domain.User) error {
 	log := r.logger.ForRepository("user", "create").WithField("email", user.Email)
 
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
 	log.Debug("Creating user in MongoDB")
 
-	// Set creation time
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
+	// Set creation time unless the record carries its own (imported users)
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
 
 	// If ID is empty, MongoDB will generate one
 	if user.ID == "" {
@@ -72,7 +84,7 @@ func (r *mongoUserRepository) Create(ctx context.Context, user *domain.User) err
 
 	log.Debug("Inserting user document", "user_id", user.ID)
 
-	_, err := r.collection.InsertOne(ctx, user)
+	_, err = r.collection.InsertOne(ctx, user)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			log.Warn("Duplicate email detected", "error", err)
@@ -88,11 +100,16 @@ func (r *mongoUserRepository) Create(ctx context.Context, user *domain.User) err
 
 // GetByID retrieves a user by ID from MongoDB
 func (r *mongoUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
 	defer cancel()
 
 	var user domain.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	filter := bson.M{"_id": id}
+	r.debug.find(ctx, "get_by_id", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, domain.ErrUserNotFound
@@ -105,11 +122,16 @@ func (r *mongoUserRepository) GetByID(ctx context.Context, id string) (*domain.U
 
 // GetByEmail retrieves a user by email from MongoDB
 func (r *mongoUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
 	defer cancel()
 
 	var user domain.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	filter := bson.M{"email": email}
+	r.debug.find(ctx, "get_by_email", filter, nil, 0, 1)
+	err = r.collection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, domain.ErrUserNotFound
@@ -122,7 +144,10 @@ func (r *mongoUserRepository) GetByEmail(ctx context.Context, email string) (*do
 
 // Update updates a user in MongoDB
 func (r *mongoUserRepository) Update(ctx context.Context, id string, user *domain.User) error {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
 	// Set update time
@@ -133,7 +158,11 @@ func (r *mongoUserRepository) Update(ctx context.Context, id string, user *domai
 			"name":       user.Name,
 			"email":      user.Email,
 			"role":       user.Role,
+			"status":     user.Status,
 			"updated_at": user.UpdatedAt,
+
+			"status_reason":     user.StatusReason,
+			"status_changed_at": user.StatusChangedAt,
 		},
 	}
 
@@ -144,6 +173,24 @@ func (r *mongoUserRepository) Update(ctx context.Context, id string, user *domai
 		}
 	}
 
+	if user.SecurityQuestions != nil {
+		if setMap, ok := update["$set"].(bson.M); ok {
+			setMap["security_questions"] = user.SecurityQuestions
+		}
+	}
+
+	if user.WebAuthnCredentials != nil {
+		if setMap, ok := update["$set"].(bson.M); ok {
+			setMap["webauthn_credentials"] = user.WebAuthnCredentials
+		}
+	}
+	if user.ExternalIdentities != nil {
+		if setMap, ok := update["$set"].(bson.M); ok {
+			setMap["external_identities"] = user.ExternalIdentities
+		}
+	}
+
+	r.debug.filter("update", bson.M{"_id": id})
 	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
@@ -161,9 +208,13 @@ func (r *mongoUserRepository) Update(ctx context.Context, id string, user *domai
 
 // Delete deletes a user from MongoDB
 func (r *mongoUserRepository) Delete(ctx context.Context, id string) error {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
+	r.debug.filter("delete", bson.M{"_id": id})
 	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil {
 		return err
@@ -176,16 +227,26 @@ func (r *mongoUserRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List retrieves users with pagination from MongoDB
-func (r *mongoUserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+// List retrieves users with pagination from MongoDB, loading only the
+// requested fields when a projection is given
+func (r *mongoUserRepository) List(ctx context.Context, limit, offset int, fields ...string) ([]*domain.User, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
 	defer cancel()
 
+	sortDoc := bson.D{{Key: "created_at", Value: -1}}
 	opts := options.Find().
 		SetLimit(int64(limit)).
 		SetSkip(int64(offset)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
+		SetSort(sortDoc)
 
+	if len(fields) > 0 {
+		opts = opts.SetProjection(userProjection(fields))
+	}
+
+	r.debug.find(ctx, "list", bson.M{}, sortDoc, int64(offset), int64(limit))
 	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
 	if err != nil {
 		return nil, err
@@ -215,9 +276,13 @@ func (r *mongoUserRepository) List(ctx context.Context, limit, offset int) ([]*d
 
 // Count returns the total number of users in MongoDB
 func (r *mongoUserRepository) Count(ctx context.Context) (int64, error) {
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return 0, err
+	}
 	defer cancel()
 
+	r.debug.count(ctx, "count", bson.M{})
 	count, err := r.collection.CountDocuments(ctx, bson.M{})
 	if err != nil {
 		return 0, err
@@ -226,6 +291,267 @@ func (r *mongoUserRepository) Count(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
+// GetByIDs retrieves the users matching the given IDs from MongoDB
+func (r *mongoUserRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	r.debug.find(ctx, "get_by_ids", filter, nil, 0, 0)
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	var users []*domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// DeleteMany deletes the users matching the given IDs in a single operation
+func (r *mongoUserRepository) DeleteMany(ctx context.Context, ids []string) (int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	r.debug.filter("delete_many", filter)
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// UpdateMany applies the field update to the users matching the given IDs in a single operation
+func (r *mongoUserRepository) UpdateMany(ctx context.Context, ids []string, update domain.UserFieldUpdate) (int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	set := bson.M{"updated_at": time.Now()}
+	if update.Role != nil {
+		set["role"] = *update.Role
+	}
+	if update.Status != nil {
+		set["status"] = *update.Status
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	r.debug.filter("update_many", filter)
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.MatchedCount, nil
+}
+
+// Stats computes user totals, recent sign-ups and role distribution with a
+// single $facet aggregation
+func (r *mongoUserRepository) Stats(ctx context.Context, now time.Time) (*domain.UserStats, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	now = now.UTC()
+	countSince := func(since time.Time) bson.A {
+		return bson.A{
+			bson.M{"$match": bson.M{"created_at": bson.M{"$gt": since}}},
+			bson.M{"$count": "n"},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$facet", Value: bson.M{
+			"total":      bson.A{bson.M{"$count": "n"}},
+			"last_day":   countSince(now.AddDate(0, 0, -1)),
+			"last_week":  countSince(now.AddDate(0, 0, -7)),
+			"last_month": countSince(now.AddDate(0, 0, -domain.StatsWindowDays)),
+			"roles": bson.A{
+				bson.M{"$group": bson.M{"_id": "$role", "n": bson.M{"$sum": 1}}},
+			},
+			"daily": bson.A{
+				bson.M{"$match": bson.M{"created_at": bson.M{"$gte": now.AddDate(0, 0, -domain.StatsWindowDays)}}},
+				bson.M{"$group": bson.M{
+					"_id": bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at", "timezone": "UTC"}},
+					"n":   bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	type bucket struct {
+		ID string `bson:"_id"`
+		N  int64  `bson:"n"`
+	}
+	var facets []struct {
+		Total     []bucket `bson:"total"`
+		LastDay   []bucket `bson:"last_day"`
+		LastWeek  []bucket `bson:"last_week"`
+		LastMonth []bucket `bson:"last_month"`
+		Roles     []bucket `bson:"roles"`
+		Daily     []bucket `bson:"daily"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	stats := &domain.UserStats{RoleDistribution: make(map[string]int64)}
+	if len(facets) == 0 {
+		return stats, nil
+	}
+
+	first := func(buckets []bucket) int64 {
+		if len(buckets) == 0 {
+			return 0
+		}
+		return buckets[0].N
+	}
+	f := facets[0]
+	stats.Total = first(f.Total)
+	stats.NewUsers = domain.NewUserCounts{
+		LastDay:   first(f.LastDay),
+		LastWeek:  first(f.LastWeek),
+		LastMonth: first(f.LastMonth),
+	}
+	for _, role := range f.Roles {
+		stats.RoleDistribution[role.ID] = role.N
+	}
+	for _, day := range f.Daily {
+		stats.DailySignups = append(stats.DailySignups, domain.DailyCount{Date: day.ID, Count: day.N})
+	}
+
+	return stats, nil
+}
+
+// Search returns the page of users matching the query from MongoDB
+func (r *mongoUserRepository) Search(ctx context.Context, query *domain.UserQuery) ([]*domain.User, int64, error) {
+	ctx, cancel, err := budget.WithTimeout(ctx, r.timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cancel()
+
+	filter := userQueryFilter(query)
+
+	sortDoc := bson.D{}
+	for _, sf := range query.Sort {
+		direction := 1
+		if sf.Desc {
+			direction = -1
+		}
+		sortDoc = append(sortDoc, bson.E{Key: sf.Field, Value: direction})
+	}
+	if len(sortDoc) == 0 {
+		sortDoc = bson.D{{Key: "created_at", Value: -1}}
+	}
+
+	opts := options.Find().
+		SetLimit(int64(query.Limit)).
+		SetSkip(int64(query.Offset)).
+		SetSort(sortDoc)
+	if len(query.Fields) > 0 {
+		opts = opts.SetProjection(userProjection(query.Fields))
+	}
+
+	r.debug.find(ctx, "search", filter, sortDoc, int64(query.Offset), int64(query.Limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx) //nolint:errcheck
+
+	users := []*domain.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+
+	if query.SkipTotal {
+		return users, 0, nil
+	}
+
+	r.debug.count(ctx, "search_count", filter)
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// userQueryFilter translates a validated user query into a Mongo filter document
+func userQueryFilter(query *domain.UserQuery) bson.M {
+	clauses := bson.A{}
+
+	if query.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query.Search), Options: "i"}
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"name": pattern},
+			bson.M{"email": pattern},
+		}})
+	}
+
+	for _, filter := range query.Filters {
+		var condition interface{}
+		switch filter.Op {
+		case domain.OpNe, domain.OpGt, domain.OpGte, domain.OpLt, domain.OpLte:
+			condition = bson.M{"$" + string(filter.Op): filter.Value}
+		case domain.OpIn:
+			condition = bson.M{"$in": filter.Value}
+		case domain.OpContains:
+			condition = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Value.(string)), Options: "i"}
+		default:
+			condition = filter.Value
+		}
+
+		// Accounts created before statuses existed have no status and count as active
+		if filter.Field == "status" && filter.Op == domain.OpEq && filter.Value == domain.UserStatusActive {
+			condition = bson.M{"$in": bson.A{domain.UserStatusActive, "", nil}}
+		}
+
+		clauses = append(clauses, bson.M{filter.Field: condition})
+	}
+
+	if len(clauses) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": clauses}
+}
+
+// userProjection maps API field names to a Mongo projection document
+func userProjection(fields []string) bson.M {
+	projection := bson.M{"_id": 1}
+	for _, field := range fields {
+		if field == "id" {
+			continue
+		}
+		projection[field] = 1
+	}
+	return projection
+}
+
 // NewMongoClient creates a new MongoDB client
 func NewMongoClient(cfg *config.Config) (*mongo.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MongoDB.Timeout)
@@ -0,0 +1,121 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Field naming styles of response bodies
+const (
+	NamingSnake = "snake" // request_id, the field names the API is defined with
+	NamingCamel = "camel" // requestId
+)
+
+// Headers clients send to pick a format for one request, when FormatHeaders is set
+const (
+	NamingHeader   = "X-Response-Naming"   // snake or camel
+	EnvelopeHeader = "X-Response-Envelope" // bare drops the envelope, wrapped keeps it
+)
+
+// Format controls how response bodies are written
+type Format struct {
+	Naming string
+	// Envelope wraps bodies in success, message, data or error, and meta.
+	// Bare success bodies are the data alone and bare error bodies the error
+	// section with its message.
+	Envelope bool
+}
+
+// DefaultFormat is used for requests that do not ask for another one
+var DefaultFormat = Format{Naming: NamingSnake, Envelope: true}
+
+// FormatHeaders lets clients pick the format of a request with NamingHeader
+// and EnvelopeHeader
+var FormatHeaders = true
+
+// ParseNaming validates a field naming style; empty means NamingSnake
+func ParseNaming(naming string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(naming)) {
+	case "", NamingSnake:
+		return NamingSnake, nil
+	case NamingCamel:
+		return NamingCamel, nil
+	}
+	return "", fmt.Errorf("field naming must be %q or %q, got %q", NamingSnake, NamingCamel, naming)
+}
+
+// FormatFor returns the format of the response to r: DefaultFormat, with the
+// request's headers applied when FormatHeaders is set. Unknown header values
+// are ignored.
+func FormatFor(r *http.Request) Format {
+	format := DefaultFormat
+	if !FormatHeaders || r == nil {
+		return format
+	}
+	if naming, err := ParseNaming(r.Header.Get(NamingHeader)); err == nil && r.Header.Get(NamingHeader) != "" {
+		format.Naming = naming
+	}
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get(EnvelopeHeader))) {
+	case "bare":
+		format.Envelope = false
+	case "wrapped":
+		format.Envelope = true
+	}
+	return format
+}
+
+// encode marshals body with the format's field naming. camelCase renames
+// every object key, including the keys of maps in the data.
+func (f Format) encode(body interface{}) ([]byte, error) {
+	raw, err := json.Marshal(body)
+	if err != nil || f.Naming != NamingCamel {
+		return raw, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(camelKeys(value))
+}
+
+// camelKeys renames the keys of every object in a decoded JSON value
+func camelKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renamed[camelCase(key)] = camelKeys(item)
+		}
+		return renamed
+	case []interface{}:
+		for i, item := range v {
+			v[i] = camelKeys(item)
+		}
+		return v
+	}
+	return value
+}
+
+// camelCase turns "request_id" into "requestId". Keys starting with an
+// underscore, such as "_id", are kept.
+func camelCase(key string) string {
+	if !strings.Contains(key, "_") || strings.HasPrefix(key, "_") {
+		return key
+	}
+	parts := strings.Split(key, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package response
+
+import "encoding/json"
+
+// Project reduces a JSON-serializable value to the given top-level fields. Slices
+// are projected element by element. With no fields the value is returned unchanged.
+func Project(data interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 || data == nil {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []map[string]interface{}
+	if err := json.Unmarshal(raw, &list); err == nil {
+		projected := make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			projected = append(projected, pick(item, fields))
+		}
+		return projected, nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, err
+	}
+	return pick(object, fields), nil
+}
+
+func pick(object map[string]interface{}, fields []string) map[string]interface{} {
+	picked := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := object[field]; ok {
+			picked[field] = value
+		}
+	}
+	return picked
+}
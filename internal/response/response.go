@@ -0,0 +1,147 @@
+// Package response implements the standard JSON envelope returned by every
+// HTTP endpoint, including the meta section clients use to correlate
+// responses with server logs.
+package response
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/queryplan"
+)
+
+// APIVersion is reported in the meta section of every response
+var APIVersion = "v1"
+
+// DemoMode flags every response as coming from a demo server, in the meta
+// section and in DemoHeader, so clients can show a banner
+var DemoMode bool
+
+// DemoHeader is set to "true" on the responses of a demo server
+const DemoHeader = "X-Demo-Mode"
+
+// Meta carries request correlation data included in every envelope
+type Meta struct {
+	RequestID  string   `json:"request_id,omitempty"`
+	DurationMs *float64 `json:"duration_ms,omitempty"`
+	APIVersion string   `json:"api_version"`
+	Demo       bool     `json:"demo,omitempty"`
+
+	// Query plans, only when an admin requested them (see middleware.QueryExplain)
+	QueryPlans []queryplan.Plan `json:"query_plans,omitempty"`
+	// Changes summarizes the fields an update changed, when the handler collects them
+	Changes []domain.FieldChange `json:"changes,omitempty"`
+}
+
+type requestInfo struct {
+	requestID string
+	start     time.Time
+}
+
+type contextKey struct{}
+
+// ContextWithRequestInfo records the request ID and start time used to fill
+// the meta section of responses written for this request
+func ContextWithRequestInfo(ctx context.Context, requestID string, start time.Time) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestInfo{requestID: requestID, start: start})
+}
+
+// RequestIDFromContext returns the request ID recorded for the request
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	info, ok := ctx.Value(contextKey{}).(requestInfo)
+	if !ok || info.requestID == "" {
+		return "", false
+	}
+	return info.requestID, true
+}
+
+// MetaFor builds the meta section for a request
+func MetaFor(r *http.Request) Meta {
+	meta := Meta{APIVersion: APIVersion, Demo: DemoMode}
+	if r == nil {
+		return meta
+	}
+
+	if info, ok := r.Context().Value(contextKey{}).(requestInfo); ok {
+		meta.RequestID = info.requestID
+		if !info.start.IsZero() {
+			ms := float64(time.Since(info.start).Microseconds()) / 1000
+			meta.DurationMs = &ms
+		}
+	}
+	meta.QueryPlans = queryplan.FromContext(r.Context()).Plans()
+	meta.Changes = domain.ChangeSetFromContext(r.Context()).Changes()
+	return meta
+}
+
+// Success writes a success envelope, or the data alone in the bare format
+func Success(w http.ResponseWriter, r *http.Request, statusCode int, message string, data interface{}) {
+	format := FormatFor(r)
+	if !format.Envelope {
+		writeJSON(w, format, statusCode, data)
+		return
+	}
+
+	body := map[string]interface{}{
+		"success": true,
+		"message": message,
+		"data":    data,
+		"meta":    MetaFor(r),
+	}
+
+	writeJSON(w, format, statusCode, body)
+}
+
+// Error writes an error envelope
+func Error(w http.ResponseWriter, r *http.Request, statusCode int, message, code string) {
+	ErrorWithDetails(w, r, statusCode, message, code, nil)
+}
+
+// ErrorWithDetails writes an error envelope whose error section also carries
+// details, such as the parameters that failed validation. In the bare format
+// the body is the error section with the message.
+func ErrorWithDetails(w http.ResponseWriter, r *http.Request, statusCode int, message, code string, details interface{}) {
+	errorBody := map[string]interface{}{
+		"code": code,
+	}
+	if details != nil {
+		errorBody["details"] = details
+	}
+
+	format := FormatFor(r)
+	if !format.Envelope {
+		errorBody["message"] = message
+		writeJSON(w, format, statusCode, errorBody)
+		return
+	}
+
+	body := map[string]interface{}{
+		"success": false,
+		"message": message,
+		"error":   errorBody,
+		"meta":    MetaFor(r),
+	}
+
+	writeJSON(w, format, statusCode, body)
+}
+
+func writeJSON(w http.ResponseWriter, format Format, statusCode int, body interface{}) {
+	encoded, err := format.encode(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if DemoMode {
+		w.Header().Set(DemoHeader, "true")
+	}
+	if FormatHeaders {
+		// Shared caches must keep the formats apart
+		w.Header().Add("Vary", NamingHeader+", "+EnvelopeHeader)
+	}
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(append(encoded, '\n'))
+}
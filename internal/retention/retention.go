@@ -0,0 +1,322 @@
+// Package retention enforces how long user data is kept. Each data class has
+// its own retention period; records older than that are purged on a schedule,
+// and a dry-run report shows what the next purge would delete.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// Data classes with a retention period
+const (
+	ClassAuditEvents  = "audit_events"
+	ClassDeletedUsers = "deleted_users"
+)
+
+// MaxReportSample bounds how many record IDs a report lists per class
+const MaxReportSample = 100
+
+// ClassReport describes the records of one data class past their retention period
+type ClassReport struct {
+	Class  string `json:"class"`
+	Period string `json:"period"`
+	// Enabled is false when the class is kept forever
+	Enabled bool       `json:"enabled"`
+	Cutoff  *time.Time `json:"cutoff,omitempty"`
+	// Eligible counts the records older than the cutoff when the report was made
+	Eligible int64 `json:"eligible"`
+	// Deleted counts the records removed; always 0 in a dry run
+	Deleted int64 `json:"deleted"`
+	// SampleIDs lists some of the eligible records, where they have IDs worth showing
+	SampleIDs []string `json:"sample_ids,omitempty"`
+}
+
+// Report is the outcome of a purge, or of a dry run when DryRun is set
+type Report struct {
+	DryRun      bool          `json:"dry_run"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Classes     []ClassReport `json:"classes"`
+}
+
+// Status describes the purge schedule together with a fresh dry-run report
+type Status struct {
+	Enabled   bool    `json:"enabled"`
+	Interval  string  `json:"interval"`
+	LastPurge *Report `json:"last_purge,omitempty"`
+	LastError string  `json:"last_error,omitempty"`
+	DryRun    *Report `json:"dry_run"`
+}
+
+// Engine applies the retention policies
+type Engine struct {
+	cfg    config.RetentionConfig
+	users  domain.UserRepository
+	audit  domain.AuditRepository
+	logger *logger.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	started   bool
+	lastPurge *Report
+	lastError string
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEngine creates a retention engine. Nothing is purged until Start is
+// called, and only if retention is enabled.
+func NewEngine(cfg config.RetentionConfig, users domain.UserRepository, audit domain.AuditRepository) *Engine {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+
+	return &Engine{
+		cfg:    cfg,
+		users:  users,
+		audit:  audit,
+		logger: logger.GetGlobal().ForComponent("retention"),
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start begins scheduled purges in the background when retention is enabled
+func (e *Engine) Start() {
+	if !e.cfg.Enabled {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.started {
+		return
+	}
+	e.started = true
+	e.logger.Info("Data retention enabled",
+		"interval", e.cfg.Interval,
+		"audit_events", e.cfg.AuditEvents,
+		"deleted_users", e.cfg.DeletedUsers,
+	)
+	go e.run()
+}
+
+// Close stops scheduled purges and waits for an in-flight purge, or for ctx to expire
+func (e *Engine) Close(ctx context.Context) error {
+	e.mu.Lock()
+	started := e.started
+	e.mu.Unlock()
+
+	e.closeOnce.Do(func() { close(e.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DryRun reports what a purge would delete now without deleting anything
+func (e *Engine) DryRun(ctx context.Context) (*Report, error) {
+	return e.apply(ctx, true)
+}
+
+// Purge deletes every record past its retention period
+func (e *Engine) Purge(ctx context.Context) (*Report, error) {
+	return e.apply(ctx, false)
+}
+
+// Status returns the schedule state together with a dry-run report
+func (e *Engine) Status(ctx context.Context) (*Status, error) {
+	report, err := e.DryRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Enabled:  e.cfg.Enabled,
+		Interval: e.cfg.Interval.String(),
+		DryRun:   report,
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	status.LastPurge = e.lastPurge
+	status.LastError = e.lastError
+	return status, nil
+}
+
+func (e *Engine) apply(ctx context.Context, dryRun bool) (*Report, error) {
+	now := e.now().UTC()
+	report := &Report{DryRun: dryRun, GeneratedAt: now}
+
+	audit, err := e.applyAuditEvents(ctx, now, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ClassAuditEvents, err)
+	}
+	users, err := e.applyDeletedUsers(ctx, now, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ClassDeletedUsers, err)
+	}
+
+	report.Classes = []ClassReport{audit, users}
+	return report, nil
+}
+
+func newClassReport(class string, period time.Duration, now time.Time) (ClassReport, time.Time) {
+	report := ClassReport{Class: class, Period: "forever"}
+	if period <= 0 {
+		return report, time.Time{}
+	}
+
+	cutoff := now.Add(-period)
+	report.Period = period.String()
+	report.Enabled = true
+	report.Cutoff = &cutoff
+	return report, cutoff
+}
+
+// applyAuditEvents counts, and unless dryRun deletes, audit events older than the period
+func (e *Engine) applyAuditEvents(ctx context.Context, now time.Time, dryRun bool) (ClassReport, error) {
+	report, cutoff := newClassReport(ClassAuditEvents, e.cfg.AuditEvents, now)
+	if !report.Enabled {
+		return report, nil
+	}
+
+	eligible, err := e.audit.Count(ctx, domain.AuditFilter{CreatedBefore: cutoff})
+	if err != nil {
+		return report, err
+	}
+	report.Eligible = eligible
+	if dryRun || eligible == 0 {
+		return report, nil
+	}
+
+	deleted, err := e.audit.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		return report, err
+	}
+	report.Deleted = deleted
+	return report, nil
+}
+
+// applyDeletedUsers counts, and unless dryRun removes, accounts marked deleted
+// whose last update is older than the period. Purged accounts are removed in batches.
+func (e *Engine) applyDeletedUsers(ctx context.Context, now time.Time, dryRun bool) (ClassReport, error) {
+	report, cutoff := newClassReport(ClassDeletedUsers, e.cfg.DeletedUsers, now)
+	if !report.Enabled {
+		return report, nil
+	}
+
+	query := &domain.UserQuery{
+		Filters: []domain.Filter{
+			{Field: "status", Op: domain.OpEq, Value: domain.UserStatusDeleted},
+			{Field: "updated_at", Op: domain.OpLt, Value: cutoff},
+		},
+		Sort:   []domain.SortField{{Field: "updated_at"}},
+		Fields: []string{"id"},
+	}
+
+	if dryRun {
+		query.Limit = MaxReportSample
+		users, total, err := e.users.Search(ctx, query)
+		if err != nil {
+			return report, err
+		}
+		report.Eligible = total
+		for _, user := range users {
+			report.SampleIDs = append(report.SampleIDs, user.ID)
+		}
+		return report, nil
+	}
+
+	// Each batch is taken from the front of the matches, which shrink as they are deleted
+	query.Limit = e.cfg.BatchSize
+	query.SkipTotal = true
+	for {
+		users, _, err := e.users.Search(ctx, query)
+		if err != nil {
+			return report, err
+		}
+		if len(users) == 0 {
+			return report, nil
+		}
+
+		ids := make([]string, 0, len(users))
+		for _, user := range users {
+			ids = append(ids, user.ID)
+		}
+		deleted, err := e.users.DeleteMany(ctx, ids)
+		if err != nil {
+			return report, err
+		}
+		report.Eligible += int64(len(ids))
+		report.Deleted += deleted
+		if len(users) < query.Limit || deleted == 0 {
+			return report, nil
+		}
+	}
+}
+
+func (e *Engine) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	e.purge()
+	for {
+		select {
+		case <-ticker.C:
+			e.purge()
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// purge runs one scheduled purge; failures are recorded and retried at the next interval
+func (e *Engine) purge() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-e.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	report, err := e.Purge(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.lastError = err.Error()
+		e.logger.Warn("Retention purge failed", "error", err)
+		return
+	}
+	e.lastPurge = report
+	e.lastError = ""
+	for _, class := range report.Classes {
+		if class.Deleted > 0 {
+			e.logger.Info("Purged expired records", "class", class.Class, "deleted", class.Deleted, "cutoff", class.Cutoff)
+		}
+	}
+}
@@ -1,42 +1,34 @@
 package routes
 
 import (
+	"demo-go/internal/domain"
 	"demo-go/internal/handler"
-	"demo-go/internal/middleware"
-
-	"github.com/gorilla/mux"
 )
 
-// AdminRoutes handles admin-only routes
+// AdminRoutes handles the admin user management routes
 type AdminRoutes struct {
-	userHandler   *handler.UserHandler
-	jwtMiddleware *middleware.JWTMiddleware
+	userHandler *handler.UserHandler
 }
 
 // NewAdminRoutes creates a new admin routes instance
-func NewAdminRoutes(userHandler *handler.UserHandler, jwtMiddleware *middleware.JWTMiddleware) *AdminRoutes {
+func NewAdminRoutes(userHandler *handler.UserHandler) *AdminRoutes {
 	return &AdminRoutes{
-		userHandler:   userHandler,
-		jwtMiddleware: jwtMiddleware,
+		userHandler: userHandler,
 	}
 }
 
-// SetupRoutes configures admin routes (admin only)
-func (ar *AdminRoutes) SetupRoutes(router *mux.Router) {
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
-	adminRouter.Use(ar.jwtMiddleware.RequireAdmin)
-
-	adminRouter.HandleFunc("/users", ar.userHandler.GetUsers).Methods("GET")
-	adminRouter.HandleFunc("/users/{id}", ar.userHandler.GetUserByID).Methods("GET")
-	adminRouter.HandleFunc("/users/{id}", ar.userHandler.DeleteUser).Methods("DELETE")
-}
-
-// GetRoutes returns a list of admin routes
-func (ar *AdminRoutes) GetRoutes() []string {
-	return []string{
-		"GET /api/v1/admin/users - List all users",
-		"GET /api/v1/admin/users/{id} - Get user by ID",
-		"DELETE /api/v1/admin/users/{id} - Delete user",
+// Routes returns the admin routes, each requiring a permission of the policy
+func (ar *AdminRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/users", Handler: ar.userHandler.GetUsers, Description: "List all users", Permission: domain.PermissionUserList},
+		{Method: "POST", Path: "/api/v1/admin/users/bulk", Handler: ar.userHandler.BulkUserAction, Description: "Bulk delete, suspend or set role", Permission: domain.PermissionUserBulk},
+		{Method: "GET", Path: "/api/v1/admin/users/{id}", Handler: ar.userHandler.GetUserByID, Description: "Get user by ID", Permission: domain.PermissionUserRead},
+		{Method: "PUT", Path: "/api/v1/admin/users/{id}", Handler: ar.userHandler.UpdateUser, Description: "Update user, including role", Permission: domain.PermissionUserUpdate},
+		{Method: "DELETE", Path: "/api/v1/admin/users/{id}", Handler: ar.userHandler.DeleteUser, Description: "Delete user", Permission: domain.PermissionUserDelete},
+		{Method: "POST", Path: "/api/v1/admin/users/{id}/suspend", Handler: ar.userHandler.SuspendUser, Description: "Suspend a user with a reason", Permission: domain.PermissionUserStatus},
+		{Method: "POST", Path: "/api/v1/admin/users/{id}/unsuspend", Handler: ar.userHandler.UnsuspendUser, Description: "Lift a user's suspension", Permission: domain.PermissionUserStatus},
+		{Method: "POST", Path: "/api/v1/admin/users/{id}/ban", Handler: ar.userHandler.BanUser, Description: "Ban a user with a reason", Permission: domain.PermissionUserStatus},
+		{Method: "GET", Path: "/api/v1/admin/stats/users", Handler: ar.userHandler.GetUserStats, Description: "User count and growth statistics", Permission: domain.PermissionStatsRead},
+		{Method: "GET", Path: "/api/v1/admin/cache/stats", Handler: ar.userHandler.GetCacheStats, Description: "Cache statistics", Permission: domain.PermissionCacheRead},
 	}
 }
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// AdminSearchRoutes handles the admin search route (user.read, and
+// audit.read checked by the handler)
+type AdminSearchRoutes struct {
+	adminSearchHandler *handler.AdminSearchHandler
+}
+
+// NewAdminSearchRoutes creates a new admin search routes instance
+func NewAdminSearchRoutes(adminSearchHandler *handler.AdminSearchHandler) *AdminSearchRoutes {
+	return &AdminSearchRoutes{
+		adminSearchHandler: adminSearchHandler,
+	}
+}
+
+// Routes returns the admin search route
+func (ar *AdminSearchRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/search", Handler: ar.adminSearchHandler.Search, Description: "Search users, audit events and sessions at once", Permission: domain.PermissionUserRead},
+	}
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"net/http"
+
+	"demo-go/internal/adminui"
+)
+
+// AdminUIRoutes serves the embedded admin dashboard
+type AdminUIRoutes struct{}
+
+// NewAdminUIRoutes creates a new admin UI routes instance
+func NewAdminUIRoutes() *AdminUIRoutes {
+	return &AdminUIRoutes{}
+}
+
+// Routes returns the admin dashboard routes (public static assets; the
+// dashboard itself authenticates against the admin API)
+func (ar *AdminUIRoutes) Routes() []Route {
+	return []Route{
+		{
+			Method: "GET", Path: "/admin-ui", Name: "adminui.Redirect",
+			Handler:     http.RedirectHandler(adminui.PathPrefix, http.StatusMovedPermanently).ServeHTTP,
+			Description: "Redirect to the admin dashboard", Public: true,
+		},
+		{
+			Method: "GET", Path: adminui.PathPrefix, Prefix: true, Name: "adminui.Handler",
+			Handler:     adminui.Handler().ServeHTTP,
+			Description: "Embedded admin dashboard", Public: true,
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// AnnouncementRoutes handles the announcement banner routes
+type AnnouncementRoutes struct {
+	announcementHandler *handler.AnnouncementHandler
+}
+
+// NewAnnouncementRoutes creates a new announcement routes instance
+func NewAnnouncementRoutes(announcementHandler *handler.AnnouncementHandler) *AnnouncementRoutes {
+	return &AnnouncementRoutes{
+		announcementHandler: announcementHandler,
+	}
+}
+
+// Routes returns the announcement routes
+func (ar *AnnouncementRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/announcements", Handler: ar.announcementHandler.ListAnnouncements, Description: "Announcements to show clients", Public: true},
+		{Method: "GET", Path: "/api/v1/admin/announcement", Handler: ar.announcementHandler.GetAnnouncement, Description: "Get the announcement, expired or not", Permission: domain.PermissionAnnouncementManage},
+		{Method: "PUT", Path: "/api/v1/admin/announcement", Handler: ar.announcementHandler.SetAnnouncement, Description: "Create or replace the announcement", Permission: domain.PermissionAnnouncementManage},
+		{Method: "DELETE", Path: "/api/v1/admin/announcement", Handler: ar.announcementHandler.ClearAnnouncement, Description: "Clear the announcement", Permission: domain.PermissionAnnouncementManage},
+	}
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// APIKeyRoutes handles API key management routes, for the caller's own keys
+// and, for admins, the keys of any user
+type APIKeyRoutes struct {
+	apiKeyHandler *handler.APIKeyHandler
+}
+
+// NewAPIKeyRoutes creates a new API key routes instance
+func NewAPIKeyRoutes(apiKeyHandler *handler.APIKeyHandler) *APIKeyRoutes {
+	return &APIKeyRoutes{
+		apiKeyHandler: apiKeyHandler,
+	}
+}
+
+// Routes returns the API key routes
+func (kr *APIKeyRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/profile/api-keys", Handler: kr.apiKeyHandler.ListKeys, Description: "List your API keys"},
+		{Method: "POST", Path: "/api/v1/profile/api-keys", Handler: kr.apiKeyHandler.CreateKey, Description: "Create an API key"},
+		{Method: "DELETE", Path: "/api/v1/profile/api-keys/{keyId}", Handler: kr.apiKeyHandler.RevokeKey, Description: "Revoke one of your API keys"},
+		{Method: "GET", Path: "/api/v1/admin/users/{id}/api-keys", Handler: kr.apiKeyHandler.ListUserKeys, Description: "List the API keys of a user", Permission: domain.PermissionAPIKeyManage},
+		{Method: "POST", Path: "/api/v1/admin/users/{id}/api-keys", Handler: kr.apiKeyHandler.CreateUserKey, Description: "Create an API key for a user", Permission: domain.PermissionAPIKeyManage},
+		{Method: "DELETE", Path: "/api/v1/admin/users/{id}/api-keys/{keyId}", Handler: kr.apiKeyHandler.RevokeUserKey, Description: "Revoke an API key of a user", Permission: domain.PermissionAPIKeyManage},
+	}
+}
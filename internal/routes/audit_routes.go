@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// AuditRoutes handles audit log routes
+type AuditRoutes struct {
+	auditHandler *handler.AuditHandler
+}
+
+// NewAuditRoutes creates a new audit routes instance
+func NewAuditRoutes(auditHandler *handler.AuditHandler) *AuditRoutes {
+	return &AuditRoutes{
+		auditHandler: auditHandler,
+	}
+}
+
+// Routes returns the audit log routes
+func (ar *AuditRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/audit-events", Handler: ar.auditHandler.ListEvents, Description: "List audit events", Permission: domain.PermissionAuditRead},
+	}
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// AuthMetricsRoutes handles authentication metrics routes
+type AuthMetricsRoutes struct {
+	metricsHandler *handler.AuthMetricsHandler
+}
+
+// NewAuthMetricsRoutes creates a new authentication metrics routes instance
+func NewAuthMetricsRoutes(metricsHandler *handler.AuthMetricsHandler) *AuthMetricsRoutes {
+	return &AuthMetricsRoutes{
+		metricsHandler: metricsHandler,
+	}
+}
+
+// Routes returns the authentication metrics routes
+func (ar *AuthMetricsRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/auth/metrics", Handler: ar.metricsHandler.GetMetrics, Description: "Authentication outcomes and recent failure reasons", Permission: domain.PermissionMetricsRead},
+	}
+}
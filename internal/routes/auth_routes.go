@@ -2,35 +2,43 @@ package routes
 
 import (
 	"demo-go/internal/handler"
-
-	"github.com/gorilla/mux"
 )
 
 // AuthRoutes handles authentication routes
 type AuthRoutes struct {
 	userHandler *handler.UserHandler
+	// passwordLogin serves password registration and login; cleared when
+	// users only log in through single sign-on
+	passwordLogin bool
+
+	// Optional, set when the emailed password reset flow is enabled
+	passwordResetHandler *handler.PasswordResetHandler
 }
 
 // NewAuthRoutes creates a new auth routes instance
 func NewAuthRoutes(userHandler *handler.UserHandler) *AuthRoutes {
 	return &AuthRoutes{
-		userHandler: userHandler,
+		userHandler:   userHandler,
+		passwordLogin: true,
 	}
 }
 
-// SetupRoutes configures authentication routes (public)
-func (ar *AuthRoutes) SetupRoutes(router *mux.Router) {
-	authRouter := router.PathPrefix("/auth").Subrouter()
-	authRouter.HandleFunc("/register", ar.userHandler.Register).Methods("POST")
-	authRouter.HandleFunc("/login", ar.userHandler.Login).Methods("POST")
-	authRouter.HandleFunc("/refresh", ar.userHandler.RefreshToken).Methods("POST")
-}
-
-// GetRoutes returns a list of auth routes
-func (ar *AuthRoutes) GetRoutes() []string {
-	return []string{
-		"POST /auth/register - User registration",
-		"POST /auth/login - User login",
-		"POST /auth/refresh - Refresh JWT token",
+// Routes returns the authentication routes (public)
+func (ar *AuthRoutes) Routes() []Route {
+	var routes []Route
+	if ar.passwordLogin {
+		routes = append(routes,
+			Route{Method: "POST", Path: "/auth/register", Handler: ar.userHandler.Register, Description: "User registration", Public: true},
+			Route{Method: "POST", Path: "/auth/login", Handler: ar.userHandler.Login, Description: "User login", Public: true},
+		)
+	}
+	// Authenticated by the refresh token in the body
+	routes = append(routes, Route{Method: "POST", Path: "/auth/refresh", Handler: ar.userHandler.RefreshToken, Description: "Refresh JWT token", Public: true})
+	if ar.passwordLogin && ar.passwordResetHandler != nil {
+		routes = append(routes,
+			Route{Method: "POST", Path: "/auth/forgot-password", Handler: ar.passwordResetHandler.ForgotPassword, Description: "Email a password reset link", Public: true},
+			Route{Method: "POST", Path: "/auth/reset-password", Handler: ar.passwordResetHandler.ResetPassword, Description: "Reset password with an emailed token", Public: true},
+		)
 	}
+	return routes
 }
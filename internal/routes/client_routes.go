@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// ClientRoutes handles the token endpoint and machine client management routes
+type ClientRoutes struct {
+	clientHandler *handler.ClientHandler
+	tokenExchange bool
+}
+
+// NewClientRoutes creates a new machine client routes instance. The token
+// exchange endpoint is only served with tokenExchange.
+func NewClientRoutes(clientHandler *handler.ClientHandler, tokenExchange bool) *ClientRoutes {
+	return &ClientRoutes{
+		clientHandler: clientHandler,
+		tokenExchange: tokenExchange,
+	}
+}
+
+// Routes returns the machine client routes
+func (cr *ClientRoutes) Routes() []Route {
+	routes := []Route{
+		{Method: "POST", Path: "/auth/token", Handler: cr.clientHandler.Token, Description: "Issue a token to a machine client (client credentials grant)", Public: true},
+	}
+	if cr.tokenExchange {
+		routes = append(routes, Route{Method: "POST", Path: "/auth/token/exchange", Handler: cr.clientHandler.ExchangeToken, Description: "Exchange a user's token for a narrower delegated one (RFC 8693)", Public: true})
+	}
+	return append(routes,
+		Route{Method: "GET", Path: "/api/v1/admin/clients", Handler: cr.clientHandler.ListClients, Description: "List machine clients", Permission: domain.PermissionClientManage},
+		Route{Method: "POST", Path: "/api/v1/admin/clients", Handler: cr.clientHandler.CreateClient, Description: "Create a machine client", Permission: domain.PermissionClientManage},
+		Route{Method: "DELETE", Path: "/api/v1/admin/clients/{id}", Handler: cr.clientHandler.DeleteClient, Description: "Delete a machine client", Permission: domain.PermissionClientManage},
+	)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// EmailCheckRoutes handles the public email availability route
+type EmailCheckRoutes struct {
+	emailCheckHandler *handler.EmailCheckHandler
+}
+
+// NewEmailCheckRoutes creates a new email check routes instance
+func NewEmailCheckRoutes(emailCheckHandler *handler.EmailCheckHandler) *EmailCheckRoutes {
+	return &EmailCheckRoutes{
+		emailCheckHandler: emailCheckHandler,
+	}
+}
+
+// Routes returns the email availability route
+func (er *EmailCheckRoutes) Routes() []Route {
+	return []Route{
+		{Method: "POST", Path: "/auth/check-email", Handler: er.emailCheckHandler.CheckEmail, Description: "Check whether an email is available for signup", Public: true},
+	}
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// EmailOutboxRoutes handles email outbox inspection routes
+type EmailOutboxRoutes struct {
+	outboxHandler *handler.EmailOutboxHandler
+}
+
+// NewEmailOutboxRoutes creates a new email outbox routes instance
+func NewEmailOutboxRoutes(outboxHandler *handler.EmailOutboxHandler) *EmailOutboxRoutes {
+	return &EmailOutboxRoutes{
+		outboxHandler: outboxHandler,
+	}
+}
+
+// Routes returns the email outbox routes
+func (er *EmailOutboxRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/emails", Handler: er.outboxHandler.ListEmails, Description: "List outbox emails and counts by status", Permission: domain.PermissionEmailRead},
+		{Method: "GET", Path: "/api/v1/admin/emails/{id}", Handler: er.outboxHandler.GetEmail, Description: "Get the delivery state of an email", Permission: domain.PermissionEmailRead},
+		{Method: "POST", Path: "/api/v1/admin/emails/{id}/resend", Handler: er.outboxHandler.ResendEmail, Description: "Queue a failed or bounced email again", Permission: domain.PermissionEmailManage},
+		{Method: "POST", Path: "/api/v1/admin/emails/{id}/bounce", Handler: er.outboxHandler.MarkBounced, Description: "Record that a sent email bounced", Permission: domain.PermissionEmailManage},
+	}
+}
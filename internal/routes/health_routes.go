@@ -2,8 +2,6 @@ package routes
 
 import (
 	"demo-go/internal/handler"
-
-	"github.com/gorilla/mux"
 )
 
 // HealthRoutes handles health check routes
@@ -18,14 +16,9 @@ func NewHealthRoutes(userHandler *handler.UserHandler) *HealthRoutes {
 	}
 }
 
-// SetupRoutes configures health check routes (public)
-func (hr *HealthRoutes) SetupRoutes(router *mux.Router) {
-	router.HandleFunc("/health", hr.userHandler.Health).Methods("GET")
-}
-
-// GetRoutes returns a list of health routes
-func (hr *HealthRoutes) GetRoutes() []string {
-	return []string{
-		"GET /health - Health check",
+// Routes returns the health check routes (public)
+func (hr *HealthRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/health", Handler: hr.userHandler.Health, Description: "Health check", Public: true},
 	}
 }
@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"demo-go/internal/adminui"
+	"demo-go/internal/response"
+)
+
+// APIIndex is the machine-readable answer at the root path
+type APIIndex struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Links   map[string]string `json:"links"`
+	// Auth lists the endpoints under /auth that are registered
+	Auth []IndexEndpoint `json:"auth"`
+}
+
+// IndexEndpoint describes a route listed by the index and the docs page
+type IndexEndpoint struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Protected   bool   `json:"protected"`
+}
+
+// Index handles GET /. Browsers get a landing page, other clients the API index.
+func (ir *introspectionRoutes) Index(w http.ResponseWriter, r *http.Request) {
+	index := ir.router.APIIndex()
+	if !prefersHTML(r) {
+		response.Success(w, r, http.StatusOK, "Welcome to the "+index.Name, index)
+		return
+	}
+	renderPage(w, landingPage, index)
+}
+
+// Docs handles GET /docs, a readable reference of every route
+func (ir *introspectionRoutes) Docs(w http.ResponseWriter, r *http.Request) {
+	groups := make(map[string][]IndexEndpoint)
+	var names []string
+	for _, group := range ir.router.groups() {
+		for _, rt := range group.group.Routes() {
+			if groups[group.name] == nil {
+				names = append(names, group.name)
+			}
+			groups[group.name] = append(groups[group.name], indexEndpoint(rt))
+		}
+	}
+	renderPage(w, docsPage, map[string]interface{}{
+		"Index":  ir.router.APIIndex(),
+		"Names":  names,
+		"Groups": groups,
+	})
+}
+
+// APIIndex returns the name, version and entry points of the API
+func (r *Router) APIIndex() *APIIndex {
+	index := &APIIndex{
+		Name:    "demo-go API",
+		Version: r.version,
+		Links: map[string]string{
+			"self":     "/",
+			"health":   "/health",
+			"openapi":  "/openapi.json",
+			"docs":     "/docs",
+			"admin_ui": adminui.PathPrefix,
+		},
+		Auth: []IndexEndpoint{},
+	}
+	for _, group := range r.groups() {
+		for _, rt := range group.group.Routes() {
+			if strings.HasPrefix(rt.Path, "/auth/") {
+				index.Auth = append(index.Auth, indexEndpoint(rt))
+			}
+		}
+	}
+	sort.SliceStable(index.Auth, func(i, j int) bool { return index.Auth[i].Path < index.Auth[j].Path })
+	return index
+}
+
+// Helper functions
+
+func indexEndpoint(rt Route) IndexEndpoint {
+	return IndexEndpoint{Method: rt.Method, Path: rt.displayPath(), Description: rt.Description, Protected: !rt.Public}
+}
+
+// prefersHTML reports whether the client asked for HTML, as browsers do
+func prefersHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+func renderPage(w http.ResponseWriter, page *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = page.Execute(w, data)
+}
+
+const pageStyle = `<style>
+body { font-family: system-ui, sans-serif; max-width: 52rem; margin: 3rem auto; padding: 0 1rem; color: #222; }
+code, .method { font-family: ui-monospace, monospace; }
+.method { display: inline-block; min-width: 4.5rem; font-weight: bold; }
+li { margin: .3rem 0; }
+.muted { color: #777; }
+</style>`
+
+var landingPage = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Name}}</title>` + pageStyle + `</head>
+<body>
+<h1>{{.Name}}</h1>
+<p class="muted">Version {{.Version}}</p>
+<p>This is a JSON API. Start with the <a href="{{.Links.docs}}">route reference</a>
+or the <a href="{{.Links.openapi}}">OpenAPI document</a>.</p>
+<ul>
+<li><a href="{{.Links.health}}">Health</a></li>
+<li><a href="{{.Links.docs}}">Route reference</a></li>
+<li><a href="{{.Links.openapi}}">OpenAPI document</a></li>
+<li><a href="{{.Links.admin_ui}}">Admin dashboard</a></li>
+</ul>
+<h2>Authentication</h2>
+<ul>
+{{range .Auth}}<li><span class="method">{{.Method}}</span> <code>{{.Path}}</code> <span class="muted">{{.Description}}</span></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Index.Name}} routes</title>` + pageStyle + `</head>
+<body>
+<h1>{{.Index.Name}} routes</h1>
+<p class="muted">Version {{.Index.Version}}. Routes marked 🔒 need a bearer token.
+The <a href="{{.Index.Links.openapi}}">OpenAPI document</a> describes them for tools.</p>
+{{range .Names}}<h2>{{.}}</h2>
+<ul>
+{{range index $.Groups .}}<li><span class="method">{{.Method}}</span> <code>{{.Path}}</code>{{if .Protected}} 🔒{{end}} <span class="muted">{{.Description}}</span></li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/response"
+)
+
+// introspectionRoutes describes the API from the route table of its router
+type introspectionRoutes struct {
+	router *Router
+}
+
+// Routes returns the API index, route listing and OpenAPI document routes
+func (ir *introspectionRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/", Handler: ir.Index, Description: "API index, or a landing page for browsers", Public: true},
+		{Method: "GET", Path: "/docs", Handler: ir.Docs, Description: "Route reference page", Public: true},
+		{Method: "GET", Path: "/api/v1/admin/routes", Handler: ir.ListRoutes, Description: "List every registered route", Permission: domain.PermissionRouteRead},
+		{Method: "GET", Path: "/openapi.json", Handler: ir.OpenAPI, Description: "OpenAPI document of the API", Public: true},
+	}
+}
+
+// ListRoutes handles GET /api/v1/admin/routes
+func (ir *introspectionRoutes) ListRoutes(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, r, http.StatusOK, "Routes retrieved successfully", map[string]interface{}{
+		"routes": ir.router.GetAllRouteInfo(),
+	})
+}
+
+// OpenAPI handles GET /openapi.json
+func (ir *introspectionRoutes) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ir.router.OpenAPIDocument())
+}
+
+// OpenAPIDocument is an OpenAPI 3.0 description of the registered routes
+type OpenAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       OpenAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                      `json:"components"`
+}
+
+// OpenAPIInfo describes the API
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIOperation describes one method of a path
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+	Roles       []string                   `json:"x-roles,omitempty"`
+	Permission  string                     `json:"x-permission,omitempty"`
+	Owner       string                     `json:"x-owner,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a path parameter
+type OpenAPIParameter struct {
+	Name     string            `json:"name"`
+	In       string            `json:"in"`
+	Required bool              `json:"required"`
+	Schema   map[string]string `json:"schema"`
+}
+
+// OpenAPIResponse describes a response
+type OpenAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// OpenAPIComponents holds the security schemes operations refer to
+type OpenAPIComponents struct {
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes how requests authenticate
+type OpenAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat"`
+}
+
+// OpenAPIDocument returns the OpenAPI document of every route, except prefix
+// routes serving static files. Protected operations require a bearer token.
+func (r *Router) OpenAPIDocument() *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: "demo-go API", Version: r.version},
+		Paths:   make(map[string]map[string]OpenAPIOperation),
+		Components: OpenAPIComponents{SecuritySchemes: map[string]OpenAPISecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}},
+	}
+
+	for _, group := range r.groups() {
+		for _, rt := range group.group.Routes() {
+			if rt.Prefix {
+				continue
+			}
+			path, params := openAPIPath(rt.Path)
+			operation := OpenAPIOperation{
+				OperationID: rt.handlerName(),
+				Summary:     rt.Description,
+				Tags:        []string{group.name},
+				Parameters:  params,
+				Roles:       r.allowedRoles(rt),
+				Permission:  rt.Permission,
+				Owner:       rt.Owner,
+				Responses:   map[string]OpenAPIResponse{"default": {Description: "Standard response envelope"}},
+			}
+			if !rt.Public {
+				operation.Security = []map[string][]string{{"bearerAuth": {}}}
+			}
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = make(map[string]OpenAPIOperation)
+			}
+			doc.Paths[path][strings.ToLower(rt.Method)] = operation
+		}
+	}
+	return doc
+}
+
+// openAPIPath converts a mux path template to an OpenAPI path, dropping
+// variable patterns, and returns its path parameters
+func openAPIPath(template string) (string, []OpenAPIParameter) {
+	segments := strings.Split(template, "/")
+	var params []OpenAPIParameter
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.Trim(segment, "{}"), ":")
+		segments[i] = "{" + name + "}"
+		params = append(params, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: map[string]string{"type": "string"}})
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// InviteRoutes handles the admin routes managing registration invites
+type InviteRoutes struct {
+	inviteHandler *handler.InviteHandler
+}
+
+// NewInviteRoutes creates a new invite routes instance
+func NewInviteRoutes(inviteHandler *handler.InviteHandler) *InviteRoutes {
+	return &InviteRoutes{
+		inviteHandler: inviteHandler,
+	}
+}
+
+// Routes returns the invite routes
+func (ir *InviteRoutes) Routes() []Route {
+	return []Route{
+		{Method: "POST", Path: "/api/v1/admin/invites", Handler: ir.inviteHandler.CreateInvite, Description: "Invite an email to register", Permission: domain.PermissionInviteManage},
+		{Method: "GET", Path: "/api/v1/admin/invites", Handler: ir.inviteHandler.ListInvites, Description: "List invites", Permission: domain.PermissionInviteManage},
+		{Method: "DELETE", Path: "/api/v1/admin/invites/{id}", Handler: ir.inviteHandler.RevokeInvite, Description: "Revoke an invite", Permission: domain.PermissionInviteManage},
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// JWKSRoutes handles the public key discovery route
+type JWKSRoutes struct {
+	jwksHandler *handler.JWKSHandler
+}
+
+// NewJWKSRoutes creates a new JWKS routes instance
+func NewJWKSRoutes(jwksHandler *handler.JWKSHandler) *JWKSRoutes {
+	return &JWKSRoutes{
+		jwksHandler: jwksHandler,
+	}
+}
+
+// Routes returns the JWKS route
+func (jr *JWKSRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/.well-known/jwks.json", Handler: jr.jwksHandler.GetKeys, Description: "Public keys access tokens are signed with", Public: true},
+	}
+}
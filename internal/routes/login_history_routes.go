@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// LoginHistoryRoutes handles the login history routes
+type LoginHistoryRoutes struct {
+	loginHistoryHandler *handler.LoginHistoryHandler
+}
+
+// NewLoginHistoryRoutes creates a new login history routes instance
+func NewLoginHistoryRoutes(loginHistoryHandler *handler.LoginHistoryHandler) *LoginHistoryRoutes {
+	return &LoginHistoryRoutes{
+		loginHistoryHandler: loginHistoryHandler,
+	}
+}
+
+// Routes returns the login history routes
+func (lr *LoginHistoryRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/profile/login-history", Handler: lr.loginHistoryHandler.MyLoginHistory, Description: "List your recent logins"},
+		{Method: "GET", Path: "/api/v1/admin/users/{id}/login-history", Handler: lr.loginHistoryHandler.UserLoginHistory, Description: "List a user's recent logins", Permission: domain.PermissionUserRead},
+	}
+}
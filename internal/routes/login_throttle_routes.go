@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// LoginThrottleRoutes handles the public login lockout status route
+type LoginThrottleRoutes struct {
+	loginThrottleHandler *handler.LoginThrottleHandler
+}
+
+// NewLoginThrottleRoutes creates a new login throttle routes instance
+func NewLoginThrottleRoutes(loginThrottleHandler *handler.LoginThrottleHandler) *LoginThrottleRoutes {
+	return &LoginThrottleRoutes{
+		loginThrottleHandler: loginThrottleHandler,
+	}
+}
+
+// Routes returns the login lockout status route
+func (lr *LoginThrottleRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/auth/lockout-status", Handler: lr.loginThrottleHandler.GetLockoutStatus, Description: "Check whether an email is locked out of logging in", Public: true},
+	}
+}
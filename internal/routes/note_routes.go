@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// NoteRoutes handles internal user note routes, limited to the configured note roles
+type NoteRoutes struct {
+	noteHandler *handler.NoteHandler
+	roles       []string
+}
+
+// NewNoteRoutes creates a new note routes instance
+func NewNoteRoutes(noteHandler *handler.NoteHandler, roles []string) *NoteRoutes {
+	return &NoteRoutes{
+		noteHandler: noteHandler,
+		roles:       roles,
+	}
+}
+
+// Routes returns the internal user note routes
+func (nr *NoteRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/users/{id}/notes", Handler: nr.noteHandler.ListNotes, Description: "List internal notes on a user", Roles: nr.roles},
+		{Method: "POST", Path: "/api/v1/admin/users/{id}/notes", Handler: nr.noteHandler.AddNote, Description: "Add an internal note to a user", Roles: nr.roles},
+		{Method: "PUT", Path: "/api/v1/admin/users/{id}/notes/{noteId}", Handler: nr.noteHandler.UpdateNote, Description: "Edit a note, keeping its history", Roles: nr.roles},
+	}
+}
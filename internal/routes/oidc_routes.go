@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// OIDCRoutes handles single sign-on routes
+type OIDCRoutes struct {
+	oidcHandler *handler.OIDCHandler
+}
+
+// NewOIDCRoutes creates a new single sign-on routes instance
+func NewOIDCRoutes(oidcHandler *handler.OIDCHandler) *OIDCRoutes {
+	return &OIDCRoutes{
+		oidcHandler: oidcHandler,
+	}
+}
+
+// Routes returns the single sign-on routes (public)
+func (or *OIDCRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/auth/oidc/login", Handler: or.oidcHandler.Login, Description: "Start a single sign-on login at the identity provider", Public: true},
+		{Method: "GET", Path: "/auth/oidc/callback", Handler: or.oidcHandler.Callback, Description: "Finish a single sign-on login", Public: true},
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// PolicyRoutes handles permission policy inspection routes
+type PolicyRoutes struct {
+	policyHandler *handler.PolicyHandler
+}
+
+// NewPolicyRoutes creates a new policy routes instance
+func NewPolicyRoutes(policyHandler *handler.PolicyHandler) *PolicyRoutes {
+	return &PolicyRoutes{
+		policyHandler: policyHandler,
+	}
+}
+
+// Routes returns the policy routes
+func (pr *PolicyRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/permissions", Handler: pr.policyHandler.GetPolicy, Description: "Permission rules and the permissions of each role", Permission: domain.PermissionPolicyRead},
+		{Method: "GET", Path: "/api/v1/admin/users/{id}/permissions", Handler: pr.policyHandler.GetUserPermissions, Description: "Effective permissions of a user", Permission: domain.PermissionPolicyRead, Owner: "id"},
+	}
+}
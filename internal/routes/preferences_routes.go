@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// PreferencesRoutes handles the caller's display preference routes (authenticated)
+type PreferencesRoutes struct {
+	prefsHandler *handler.PreferencesHandler
+}
+
+// NewPreferencesRoutes creates a new preferences routes instance
+func NewPreferencesRoutes(prefsHandler *handler.PreferencesHandler) *PreferencesRoutes {
+	return &PreferencesRoutes{
+		prefsHandler: prefsHandler,
+	}
+}
+
+// Routes returns the display preference routes
+func (pr *PreferencesRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/profile/preferences", Handler: pr.prefsHandler.GetPreferences, Description: "Get display preferences"},
+		{Method: "PUT", Path: "/api/v1/profile/preferences", Handler: pr.prefsHandler.UpdatePreferences, Description: "Update display preferences"},
+	}
+}
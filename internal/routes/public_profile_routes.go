@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// PublicProfileRoutes handles the public profile and WebFinger routes
+type PublicProfileRoutes struct {
+	profileHandler *handler.PublicProfileHandler
+}
+
+// NewPublicProfileRoutes creates a new public profile routes instance
+func NewPublicProfileRoutes(profileHandler *handler.PublicProfileHandler) *PublicProfileRoutes {
+	return &PublicProfileRoutes{
+		profileHandler: profileHandler,
+	}
+}
+
+// Routes returns the discovery routes
+func (pr *PublicProfileRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/.well-known/webfinger", Handler: pr.profileHandler.WebFinger, Description: "Resolve an acct: URI to a public profile", Public: true},
+		{Method: "GET", Path: "/api/v1/public/profiles/{id}", Handler: pr.profileHandler.GetProfile, Description: "Get a public profile", Public: true},
+	}
+}
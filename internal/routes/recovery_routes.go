@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// RecoveryRoutes handles security-question account recovery routes
+type RecoveryRoutes struct {
+	recoveryHandler *handler.RecoveryHandler
+}
+
+// NewRecoveryRoutes creates a new recovery routes instance
+func NewRecoveryRoutes(recoveryHandler *handler.RecoveryHandler) *RecoveryRoutes {
+	return &RecoveryRoutes{
+		recoveryHandler: recoveryHandler,
+	}
+}
+
+// Routes returns the account recovery routes
+func (rr *RecoveryRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/auth/recovery/catalog", Handler: rr.recoveryHandler.GetQuestionCatalog, Description: "List available security questions", Public: true},
+		{Method: "POST", Path: "/auth/recovery/questions", Handler: rr.recoveryHandler.GetRecoveryQuestions, Description: "Get recovery questions for an account", Public: true},
+		{Method: "POST", Path: "/auth/recovery/verify", Handler: rr.recoveryHandler.VerifyAnswers, Description: "Answer recovery questions for a reset token", Public: true},
+		{Method: "POST", Path: "/auth/recovery/reset", Handler: rr.recoveryHandler.ResetPassword, Description: "Reset password with a reset token", Public: true},
+		{Method: "PUT", Path: "/api/v1/profile/security-questions", Handler: rr.recoveryHandler.SetSecurityQuestions, Description: "Set security questions"},
+	}
+}
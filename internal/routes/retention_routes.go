@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// RetentionRoutes handles data retention report routes
+type RetentionRoutes struct {
+	retentionHandler *handler.RetentionHandler
+}
+
+// NewRetentionRoutes creates a new retention routes instance
+func NewRetentionRoutes(retentionHandler *handler.RetentionHandler) *RetentionRoutes {
+	return &RetentionRoutes{
+		retentionHandler: retentionHandler,
+	}
+}
+
+// Routes returns the data retention report routes
+func (rr *RetentionRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/retention/report", Handler: rr.retentionHandler.GetRetentionReport, Description: "Retention schedule and dry-run purge report", Permission: domain.PermissionRetentionRead},
+	}
+}
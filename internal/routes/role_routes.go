@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// RoleRoutes handles role management and assignment routes
+type RoleRoutes struct {
+	roleHandler *handler.RoleHandler
+}
+
+// NewRoleRoutes creates a new role routes instance
+func NewRoleRoutes(roleHandler *handler.RoleHandler) *RoleRoutes {
+	return &RoleRoutes{
+		roleHandler: roleHandler,
+	}
+}
+
+// Routes returns the role routes
+func (rr *RoleRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/roles", Handler: rr.roleHandler.ListRoles, Description: "List roles and their permissions", Permission: domain.PermissionRoleRead},
+		{Method: "POST", Path: "/api/v1/admin/roles", Handler: rr.roleHandler.CreateRole, Description: "Create a role", Permission: domain.PermissionRoleManage},
+		{Method: "GET", Path: "/api/v1/admin/roles/{name}", Handler: rr.roleHandler.GetRole, Description: "Get a role", Permission: domain.PermissionRoleRead},
+		{Method: "PUT", Path: "/api/v1/admin/roles/{name}", Handler: rr.roleHandler.UpdateRole, Description: "Update a role's description or permissions", Permission: domain.PermissionRoleManage},
+		{Method: "DELETE", Path: "/api/v1/admin/roles/{name}", Handler: rr.roleHandler.DeleteRole, Description: "Delete a role no user holds", Permission: domain.PermissionRoleManage},
+		{Method: "PUT", Path: "/api/v1/admin/users/{id}/role", Handler: rr.roleHandler.AssignRole, Description: "Assign a role to a user", Permission: domain.PermissionRoleAssign},
+	}
+}
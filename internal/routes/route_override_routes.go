@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// RouteOverrideRoutes handles the admin routes that open or close endpoints at runtime
+type RouteOverrideRoutes struct {
+	overrideHandler *handler.RouteOverrideHandler
+}
+
+// NewRouteOverrideRoutes creates a new route override routes instance
+func NewRouteOverrideRoutes(overrideHandler *handler.RouteOverrideHandler) *RouteOverrideRoutes {
+	return &RouteOverrideRoutes{
+		overrideHandler: overrideHandler,
+	}
+}
+
+// Routes returns the route override routes
+func (ro *RouteOverrideRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/route-overrides", Handler: ro.overrideHandler.ListOverrides, Description: "List skip rules and route overrides", Permission: domain.PermissionRouteRead},
+		{Method: "POST", Path: "/api/v1/admin/route-overrides", Handler: ro.overrideHandler.CreateOverride, Description: "Open or close endpoints", Permission: domain.PermissionRouteManage},
+		{Method: "DELETE", Path: "/api/v1/admin/route-overrides/{id}", Handler: ro.overrideHandler.DeleteOverride, Description: "Remove a route override", Permission: domain.PermissionRouteManage},
+	}
+}
@@ -1,134 +1,164 @@
 package routes
 
-import "github.com/gorilla/mux"
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
 
-// RouteGroup defines the interface that all route groups must implement
+	"demo-go/internal/policy"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteGroup defines the interface that all route groups must implement.
+// A group only declares its routes; the Router registers them, applies their
+// role checks and makes public routes skip JWT authentication.
 type RouteGroup interface {
-	SetupRoutes(router *mux.Router)
-	GetRoutes() []string
+	Routes() []Route
 }
 
-// RouteConfig holds configuration for route setup
-type RouteConfig struct {
-	Prefix      string
-	Middleware  []mux.MiddlewareFunc
+// Route declares a single endpoint
+type Route struct {
+	Method string
+	// Path is a mux path template, such as /api/v1/admin/users/{id}
+	Path string
+	// Prefix matches every path below Path, for handlers serving a subtree
+	Prefix  bool
+	Handler http.HandlerFunc
+	// Name identifies the handler in route listings; defaults to the name of
+	// the Handler method, such as UserHandler.Login
+	Name        string
 	Description string
+	// Public routes skip JWT authentication
+	Public bool
+	// Roles limits the route to principals with one of the roles
+	Roles []string
+	// Permission limits the route to principals whose role holds the
+	// permission under the policy engine
+	Permission string
+	// Owner names the path variable holding the ID of the user owning the
+	// resource. That user may always call the route; other principals need
+	// Permission, or every permission, as admins hold, when none is set.
+	Owner string
+	// Scopes limits the route to principals with every one of the scopes
+	Scopes []string
+	// Middleware wraps the handler, inside the role, permission and scope checks
+	Middleware []mux.MiddlewareFunc
 }
 
 // RouteInfo contains information about a single route
 type RouteInfo struct {
-	Method      string
-	Path        string
-	Handler     string
-	Description string
-	Protected   bool
-	AdminOnly   bool
+	Group       string   `json:"group"`
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Description string   `json:"description"`
+	Protected   bool     `json:"protected"`
+	AdminOnly   bool     `json:"admin_only"`
+	Roles       []string `json:"roles,omitempty"`
+	Permission  string   `json:"permission,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
 }
 
-// GetAllRouteInfo returns detailed information about all routes
-func (r *Router) GetAllRouteInfo() []RouteInfo {
-	var routes []RouteInfo
+// String formats the route as listed in route summaries
+func (rt Route) String() string {
+	return rt.Method + " " + rt.displayPath() + " - " + rt.Description
+}
 
-	routes = append(routes, r.getHealthRoutes()...)
-	routes = append(routes, r.getAuthRoutes()...)
-	routes = append(routes, r.getUserRoutes()...)
-	routes = append(routes, r.getAdminRoutes()...)
+// displayPath is Path, with a trailing slash for prefix routes
+func (rt Route) displayPath() string {
+	if rt.Prefix && !strings.HasSuffix(rt.Path, "/") {
+		return rt.Path + "/"
+	}
+	return rt.Path
+}
 
-	return routes
+// skipRule is the JWT skip rule matching the route: path variables match one
+// segment and prefix routes everything below them
+func (rt Route) skipRule() string {
+	segments := strings.Split(strings.TrimSuffix(rt.Path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			segments[i] = "*"
+		}
+	}
+	rule := strings.Join(segments, "/")
+	if rule == "" {
+		rule = "/"
+	}
+	if rt.Prefix {
+		rule += "/**"
+	}
+	return rt.Method + " " + rule
 }
 
-// getHealthRoutes returns health check route information
-func (r *Router) getHealthRoutes() []RouteInfo {
-	return []RouteInfo{
-		{
-			Method:      "GET",
-			Path:        "/health",
-			Handler:     "userHandler.Health",
-			Description: "Health check endpoint",
-			Protected:   false,
-			AdminOnly:   false,
-		},
+// handlerName is Name, or the name of the Handler method
+func (rt Route) handlerName() string {
+	if rt.Name != "" {
+		return rt.Name
+	}
+	fn := runtime.FuncForPC(reflect.ValueOf(rt.Handler).Pointer())
+	if fn == nil {
+		return ""
 	}
+	// demo-go/internal/handler.(*UserHandler).Login-fm -> UserHandler.Login
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	name = name[strings.LastIndex(name, "/")+1:]
+	if _, method, found := strings.Cut(name, "."); found {
+		name = method
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
 }
 
-// getAuthRoutes returns authentication route information
-func (r *Router) getAuthRoutes() []RouteInfo {
-	return []RouteInfo{
-		{
-			Method:      "POST",
-			Path:        "/auth/register",
-			Handler:     "userHandler.Register",
-			Description: "User registration",
-			Protected:   false,
-			AdminOnly:   false,
-		},
-		{
-			Method:      "POST",
-			Path:        "/auth/login",
-			Handler:     "userHandler.Login",
-			Description: "User login",
-			Protected:   false,
-			AdminOnly:   false,
-		},
-		{
-			Method:      "POST",
-			Path:        "/auth/refresh",
-			Handler:     "userHandler.RefreshToken",
-			Description: "Refresh JWT token",
-			Protected:   false,
-			AdminOnly:   false,
-		},
+// info describes the route for listings; roles are those allowed to call it
+func (rt Route) info(group string, roles []string) RouteInfo {
+	return RouteInfo{
+		Group:       group,
+		Method:      rt.Method,
+		Path:        rt.displayPath(),
+		Handler:     rt.handlerName(),
+		Description: rt.Description,
+		Protected:   !rt.Public,
+		AdminOnly:   len(roles) == 1 && roles[0] == "admin",
+		Roles:       roles,
+		Permission:  rt.Permission,
+		Owner:       rt.Owner,
+		Scopes:      rt.Scopes,
 	}
 }
 
-// getUserRoutes returns user API route information
-func (r *Router) getUserRoutes() []RouteInfo {
-	return []RouteInfo{
-		{
-			Method:      "GET",
-			Path:        "/api/v1/profile",
-			Handler:     "userHandler.GetProfile",
-			Description: "Get user profile",
-			Protected:   true,
-			AdminOnly:   false,
-		},
-		{
-			Method:      "PUT",
-			Path:        "/api/v1/profile",
-			Handler:     "userHandler.UpdateProfile",
-			Description: "Update user profile",
-			Protected:   true,
-			AdminOnly:   false,
-		},
+// GetAllRouteInfo returns detailed information about all routes
+func (r *Router) GetAllRouteInfo() []RouteInfo {
+	var routes []RouteInfo
+	for _, group := range r.groups() {
+		for _, rt := range group.group.Routes() {
+			routes = append(routes, rt.info(group.name, r.allowedRoles(rt)))
+		}
 	}
+	return routes
 }
 
-// getAdminRoutes returns admin API route information
-func (r *Router) getAdminRoutes() []RouteInfo {
-	return []RouteInfo{
-		{
-			Method:      "GET",
-			Path:        "/api/v1/admin/users",
-			Handler:     "userHandler.GetUsers",
-			Description: "List all users",
-			Protected:   true,
-			AdminOnly:   true,
-		},
-		{
-			Method:      "GET",
-			Path:        "/api/v1/admin/users/{id}",
-			Handler:     "userHandler.GetUserByID",
-			Description: "Get user by ID",
-			Protected:   true,
-			AdminOnly:   true,
-		},
-		{
-			Method:      "DELETE",
-			Path:        "/api/v1/admin/users/{id}",
-			Handler:     "userHandler.DeleteUser",
-			Description: "Delete user",
-			Protected:   true,
-			AdminOnly:   true,
-		},
+// allowedRoles returns the roles that may call the route: its Roles, or the
+// roles holding its Permission under the current policy. For owner routes,
+// these are the roles that may also act on other users' resources.
+func (r *Router) allowedRoles(rt Route) []string {
+	permission := rt.Permission
+	if rt.Owner != "" && permission == "" {
+		permission = policy.Wildcard
+	}
+	if permission == "" {
+		return rt.Roles
+	}
+	engine := r.jwtMiddleware.PolicyEngine()
+	var roles []string
+	for role := range engine.Grants() {
+		if engine.Allows(role, permission) {
+			roles = append(roles, role)
+		}
 	}
+	sort.Strings(roles)
+	return roles
 }
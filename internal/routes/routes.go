@@ -4,6 +4,8 @@
 package routes
 
 import (
+	"net/http"
+
 	"demo-go/internal/handler"
 	"demo-go/internal/logger"
 	"demo-go/internal/middleware"
@@ -17,11 +19,32 @@ type Router struct {
 	jwtMiddleware *middleware.JWTMiddleware
 	logger        *logger.Logger
 
+	// Global middleware applied after CORS and before JWT authentication
+	preAuthMiddleware []mux.MiddlewareFunc
+	// Global middleware applied after JWT authentication
+	postAuthMiddleware []mux.MiddlewareFunc
+
 	// Route groups
 	healthRoutes *HealthRoutes
 	authRoutes   *AuthRoutes
 	userRoutes   *UserRoutes
 	adminRoutes  *AdminRoutes
+	adminUI      *AdminUIRoutes
+
+	// Optional route groups enabled by configuration
+	optionalGroups []namedRouteGroup
+
+	// Version reported by the OpenAPI document
+	version string
+
+	// Origins allowed by CORS
+	corsOrigins []string
+}
+
+// namedRouteGroup is an optional route group with its summary heading
+type namedRouteGroup struct {
+	name  string
+	group RouteGroup
 }
 
 // NewRouter creates a new router instance with dependencies
@@ -29,44 +52,138 @@ func NewRouter(
 	userHandler *handler.UserHandler,
 	jwtMiddleware *middleware.JWTMiddleware,
 	logger *logger.Logger,
+	preAuthMiddleware ...mux.MiddlewareFunc,
 ) *Router {
 	return &Router{
-		userHandler:   userHandler,
-		jwtMiddleware: jwtMiddleware,
-		logger:        logger,
+		userHandler:       userHandler,
+		jwtMiddleware:     jwtMiddleware,
+		logger:            logger,
+		preAuthMiddleware: preAuthMiddleware,
 
 		// Initialize route groups
 		healthRoutes: NewHealthRoutes(userHandler),
 		authRoutes:   NewAuthRoutes(userHandler),
 		userRoutes:   NewUserRoutes(userHandler),
-		adminRoutes:  NewAdminRoutes(userHandler, jwtMiddleware),
+		adminRoutes:  NewAdminRoutes(userHandler),
+		adminUI:      NewAdminUIRoutes(),
+
+		version:     "1.0.0",
+		corsOrigins: []string{"*"},
 	}
 }
 
-// SetupRoutes configures all HTTP routes and returns the configured router
+// UseAfterAuth registers global middleware that needs the authenticated
+// principal. It must be called before SetupRoutes.
+func (r *Router) UseAfterAuth(mw ...mux.MiddlewareFunc) {
+	r.postAuthMiddleware = append(r.postAuthMiddleware, mw...)
+}
+
+// AddRouteGroup registers an optional route group, such as a feature that is
+// disabled by default. It must be called before SetupRoutes.
+func (r *Router) AddRouteGroup(name string, group RouteGroup) {
+	r.optionalGroups = append(r.optionalGroups, namedRouteGroup{name: name, group: group})
+}
+
+// SetPasswordResetHandler enables the emailed password reset routes under
+// /auth. It must be called before SetupRoutes.
+func (r *Router) SetPasswordResetHandler(h *handler.PasswordResetHandler) {
+	r.authRoutes.passwordResetHandler = h
+}
+
+// DisablePasswordLogin removes password registration, login and reset, for
+// deployments where users only log in through single sign-on. It must be
+// called before SetupRoutes.
+func (r *Router) DisablePasswordLogin() {
+	r.authRoutes.passwordLogin = false
+}
+
+// SetCORSOrigins sets the origins browsers may call the API from; "*", the
+// default, allows every origin. It must be called before SetupRoutes.
+func (r *Router) SetCORSOrigins(origins []string) {
+	r.corsOrigins = origins
+}
+
+// SetVersion sets the API version reported by the OpenAPI document
+func (r *Router) SetVersion(version string) {
+	r.version = version
+}
+
+// groups returns every route group in registration order
+func (r *Router) groups() []namedRouteGroup {
+	groups := []namedRouteGroup{
+		{name: "Health Routes", group: r.healthRoutes},
+		{name: "Authentication Routes", group: r.authRoutes},
+		{name: "User API Routes", group: r.userRoutes},
+		{name: "Admin Routes", group: r.adminRoutes},
+		{name: "Admin UI Routes", group: r.adminUI},
+	}
+	groups = append(groups, r.optionalGroups...)
+	return append(groups, namedRouteGroup{name: "Introspection Routes", group: &introspectionRoutes{router: r}})
+}
+
+// SetupRoutes configures all HTTP routes and returns the configured router.
+// Public routes are added to the JWT middleware's skip rules.
 func (r *Router) SetupRoutes() *mux.Router {
 	router := mux.NewRouter()
 
 	// Add global middleware
+	router.Use(middleware.TracingMiddleware)
 	router.Use(middleware.LoggingMiddleware(r.logger))
-	router.Use(middleware.CORSMiddleware)
+	router.Use(middleware.CORS(r.corsOrigins))
+	router.Use(r.preAuthMiddleware...)
 	router.Use(r.jwtMiddleware.Authenticate)
+	router.Use(r.postAuthMiddleware...)
 
-	// Setup all route groups
-	r.healthRoutes.SetupRoutes(router)
-	r.authRoutes.SetupRoutes(router)
-	r.userRoutes.SetupRoutes(router)
-	r.adminRoutes.SetupRoutes(router)
+	// Register every route group
+	for _, group := range r.groups() {
+		for _, rt := range group.group.Routes() {
+			r.register(router, rt)
+		}
+	}
 
 	return router
 }
 
+// register adds a declared route to router
+func (r *Router) register(router *mux.Router, rt Route) {
+	var h http.Handler = rt.Handler
+	for i := len(rt.Middleware) - 1; i >= 0; i-- {
+		h = rt.Middleware[i](h)
+	}
+	for i := len(rt.Scopes) - 1; i >= 0; i-- {
+		h = r.jwtMiddleware.RequireScope(rt.Scopes[i])(h)
+	}
+	switch {
+	case rt.Owner != "":
+		h = r.jwtMiddleware.RequireOwnerOrPermission(rt.Owner, rt.Permission)(h)
+	case rt.Permission != "":
+		h = r.jwtMiddleware.RequirePermission(rt.Permission)(h)
+	}
+	if len(rt.Roles) > 0 {
+		h = r.jwtMiddleware.RequireAnyRole(rt.Roles...)(h)
+	}
+	if rt.Public {
+		if err := r.jwtMiddleware.AddSkipRules(rt.skipRule()); err != nil {
+			panic(err) // route paths are constant
+		}
+	}
+
+	route := router.NewRoute()
+	if rt.Prefix {
+		route.PathPrefix(rt.Path)
+	} else {
+		route.Path(rt.Path)
+	}
+	route.Methods(rt.Method).Handler(h)
+}
+
 // GetRoutesSummary returns a summary of all available routes
 func (r *Router) GetRoutesSummary() map[string][]string {
-	return map[string][]string{
-		"Health Routes":         r.healthRoutes.GetRoutes(),
-		"Authentication Routes": r.authRoutes.GetRoutes(),
-		"User API Routes":       r.userRoutes.GetRoutes(),
-		"Admin Routes":          r.adminRoutes.GetRoutes(),
+	summary := make(map[string][]string)
+	for _, group := range r.groups() {
+		for _, rt := range group.group.Routes() {
+			summary[group.name] = append(summary[group.name], rt.String())
+		}
 	}
+	return summary
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// SecurityRoutes handles security event routes
+type SecurityRoutes struct {
+	securityHandler *handler.SecurityHandler
+}
+
+// NewSecurityRoutes creates a new security routes instance
+func NewSecurityRoutes(securityHandler *handler.SecurityHandler) *SecurityRoutes {
+	return &SecurityRoutes{
+		securityHandler: securityHandler,
+	}
+}
+
+// Routes returns the security event routes
+func (sr *SecurityRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/security-events", Handler: sr.securityHandler.ListEvents, Description: "List security events", Permission: domain.PermissionSecurityRead},
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// SessionRoutes handles the routes users manage where they are signed in with
+type SessionRoutes struct {
+	sessionHandler *handler.SessionHandler
+}
+
+// NewSessionRoutes creates a new session routes instance
+func NewSessionRoutes(sessionHandler *handler.SessionHandler) *SessionRoutes {
+	return &SessionRoutes{
+		sessionHandler: sessionHandler,
+	}
+}
+
+// Routes returns the session routes
+func (sr *SessionRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/sessions", Handler: sr.sessionHandler.ListSessions, Description: "List your sessions"},
+		{Method: "DELETE", Path: "/api/v1/sessions", Handler: sr.sessionHandler.RevokeOtherSessions, Description: "Sign out all your other sessions"},
+		{Method: "DELETE", Path: "/api/v1/sessions/{id}", Handler: sr.sessionHandler.RevokeSession, Description: "Sign out one of your sessions"},
+	}
+}
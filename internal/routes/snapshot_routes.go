@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// SnapshotRoutes handles users snapshot and restore routes
+type SnapshotRoutes struct {
+	snapshotHandler *handler.SnapshotHandler
+}
+
+// NewSnapshotRoutes creates a new snapshot routes instance
+func NewSnapshotRoutes(snapshotHandler *handler.SnapshotHandler) *SnapshotRoutes {
+	return &SnapshotRoutes{
+		snapshotHandler: snapshotHandler,
+	}
+}
+
+// Routes returns the snapshot routes
+func (sr *SnapshotRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/snapshots", Handler: sr.snapshotHandler.ListSnapshots, Description: "List users snapshots", Permission: domain.PermissionSnapshotManage},
+		{Method: "POST", Path: "/api/v1/admin/snapshots", Handler: sr.snapshotHandler.CreateSnapshot, Description: "Snapshot the users collection", Permission: domain.PermissionSnapshotManage},
+		{Method: "GET", Path: "/api/v1/admin/snapshots/{name}", Handler: sr.snapshotHandler.GetSnapshot, Description: "Describe and verify a snapshot", Permission: domain.PermissionSnapshotManage},
+		{Method: "POST", Path: "/api/v1/admin/snapshots/{name}/restore", Handler: sr.snapshotHandler.RestoreSnapshot, Description: "Preview, then confirm, a restore", Permission: domain.PermissionSnapshotManage},
+	}
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// TelemetryRoutes handles telemetry inspection routes
+type TelemetryRoutes struct {
+	telemetryHandler *handler.TelemetryHandler
+}
+
+// NewTelemetryRoutes creates a new telemetry routes instance
+func NewTelemetryRoutes(telemetryHandler *handler.TelemetryHandler) *TelemetryRoutes {
+	return &TelemetryRoutes{
+		telemetryHandler: telemetryHandler,
+	}
+}
+
+// Routes returns the telemetry inspection routes
+func (tr *TelemetryRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/admin/telemetry", Handler: tr.telemetryHandler.GetTelemetry, Description: "Telemetry status and payload", Permission: domain.PermissionTelemetryRead},
+	}
+}
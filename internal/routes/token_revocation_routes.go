@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// TokenRevocationRoutes handles the logout and admin token revocation routes
+type TokenRevocationRoutes struct {
+	tokenRevocationHandler *handler.TokenRevocationHandler
+}
+
+// NewTokenRevocationRoutes creates a new token revocation routes instance
+func NewTokenRevocationRoutes(tokenRevocationHandler *handler.TokenRevocationHandler) *TokenRevocationRoutes {
+	return &TokenRevocationRoutes{
+		tokenRevocationHandler: tokenRevocationHandler,
+	}
+}
+
+// Routes returns the token revocation routes
+func (tr *TokenRevocationRoutes) Routes() []Route {
+	return []Route{
+		{Method: "POST", Path: "/auth/logout", Handler: tr.tokenRevocationHandler.Logout, Description: "Revoke the current access token and, optionally, a refresh token"},
+		{Method: "POST", Path: "/api/v1/admin/users/{id}/revoke-tokens", Handler: tr.tokenRevocationHandler.RevokeUserTokens, Description: "Revoke every token issued to a user", Permission: domain.PermissionTokenRevoke},
+	}
+}
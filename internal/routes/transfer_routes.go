@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// TransferRoutes handles account export and import routes
+type TransferRoutes struct {
+	transferHandler *handler.TransferHandler
+}
+
+// NewTransferRoutes creates a new account transfer routes instance
+func NewTransferRoutes(transferHandler *handler.TransferHandler) *TransferRoutes {
+	return &TransferRoutes{
+		transferHandler: transferHandler,
+	}
+}
+
+// Routes returns the account transfer routes
+func (tr *TransferRoutes) Routes() []Route {
+	return []Route{
+		{Method: "POST", Path: "/api/v1/admin/users/export", Handler: tr.transferHandler.ExportUsers, Description: "Export users into a signed bundle", Permission: domain.PermissionTransferManage},
+		{Method: "POST", Path: "/api/v1/admin/users/import", Handler: tr.transferHandler.ImportUsers, Description: "Import users from a signed bundle", Permission: domain.PermissionTransferManage},
+	}
+}
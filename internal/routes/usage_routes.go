@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+)
+
+// UsageRoutes handles the API usage routes
+type UsageRoutes struct {
+	usageHandler *handler.UsageHandler
+}
+
+// NewUsageRoutes creates a new usage routes instance
+func NewUsageRoutes(usageHandler *handler.UsageHandler) *UsageRoutes {
+	return &UsageRoutes{
+		usageHandler: usageHandler,
+	}
+}
+
+// Routes returns the usage routes
+func (ur *UsageRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/usage", Handler: ur.usageHandler.MyUsage, Description: "Daily API usage of the current user"},
+		{Method: "GET", Path: "/api/v1/admin/usage", Handler: ur.usageHandler.ListUsage, Description: "Daily API usage of every principal", Permission: domain.PermissionUsageRead},
+	}
+}
@@ -2,8 +2,6 @@ package routes
 
 import (
 	"demo-go/internal/handler"
-
-	"github.com/gorilla/mux"
 )
 
 // UserRoutes handles user-related API routes
@@ -18,19 +16,11 @@ func NewUserRoutes(userHandler *handler.UserHandler) *UserRoutes {
 	}
 }
 
-// SetupRoutes configures user API routes (authenticated)
-func (ur *UserRoutes) SetupRoutes(router *mux.Router) {
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-
-	// User profile routes
-	apiRouter.HandleFunc("/profile", ur.userHandler.GetProfile).Methods("GET")
-	apiRouter.HandleFunc("/profile", ur.userHandler.UpdateProfile).Methods("PUT")
-}
-
-// GetRoutes returns a list of user routes
-func (ur *UserRoutes) GetRoutes() []string {
-	return []string{
-		"GET /api/v1/profile - Get user profile",
-		"PUT /api/v1/profile - Update user profile",
+// Routes returns the user API routes (authenticated)
+func (ur *UserRoutes) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/api/v1/profile", Handler: ur.userHandler.GetProfile, Description: "Get user profile"},
+		{Method: "PUT", Path: "/api/v1/profile", Handler: ur.userHandler.UpdateProfile, Description: "Update user profile"},
+		{Method: "PUT", Path: "/api/v1/profile/password", Handler: ur.userHandler.ChangePassword, Description: "Change password and sign out every session"},
 	}
 }
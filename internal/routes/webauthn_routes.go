@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"demo-go/internal/handler"
+)
+
+// WebAuthnRoutes handles passkey registration and login routes
+type WebAuthnRoutes struct {
+	webAuthnHandler *handler.WebAuthnHandler
+}
+
+// NewWebAuthnRoutes creates a new passkey routes instance
+func NewWebAuthnRoutes(webAuthnHandler *handler.WebAuthnHandler) *WebAuthnRoutes {
+	return &WebAuthnRoutes{
+		webAuthnHandler: webAuthnHandler,
+	}
+}
+
+// Routes returns the passkey routes
+func (wr *WebAuthnRoutes) Routes() []Route {
+	return []Route{
+		{Method: "POST", Path: "/auth/passkeys/login/begin", Handler: wr.webAuthnHandler.BeginLogin, Description: "Start a passkey login", Public: true},
+		{Method: "POST", Path: "/auth/passkeys/login/finish", Handler: wr.webAuthnHandler.FinishLogin, Description: "Log in with a passkey", Public: true},
+		{Method: "GET", Path: "/api/v1/profile/passkeys", Handler: wr.webAuthnHandler.ListCredentials, Description: "List your passkeys"},
+		{Method: "POST", Path: "/api/v1/profile/passkeys/register/begin", Handler: wr.webAuthnHandler.BeginRegistration, Description: "Start registering a passkey"},
+		{Method: "POST", Path: "/api/v1/profile/passkeys/register/finish", Handler: wr.webAuthnHandler.FinishRegistration, Description: "Register a passkey"},
+		{Method: "DELETE", Path: "/api/v1/profile/passkeys/{credentialId}", Handler: wr.webAuthnHandler.DeleteCredential, Description: "Remove a passkey"},
+	}
+}
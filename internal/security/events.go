@@ -0,0 +1,173 @@
+// Package security records structured security events such as failed logins
+// and privilege changes, flags anomalies like repeated failures, and fans
+// events out through lifecycle hooks to the SIEM exporter and alerting.
+// Recent events are kept in a bounded in-memory buffer per instance for the
+// admin API; the SIEM is the durable record.
+package security
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/hooks"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/response"
+
+	"github.com/google/uuid"
+)
+
+// Listing defaults
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+// eventService implements domain.SecurityEventService
+type eventService struct {
+	limiter  ratelimit.Limiter
+	registry *hooks.Registry
+	config   config.SecurityEventsConfig
+	logger   *logger.Logger
+
+	mu     sync.RWMutex
+	events []*domain.SecurityEvent // oldest first
+}
+
+// NewEventService creates a security event service. Recorded events are
+// passed to the registry's OnSecurityEvent hooks.
+func NewEventService(
+	limiter ratelimit.Limiter,
+	registry *hooks.Registry,
+	cfg config.SecurityEventsConfig,
+) domain.SecurityEventService {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+
+	return &eventService{
+		limiter:  limiter,
+		registry: registry,
+		config:   cfg,
+		logger:   logger.GetGlobal().ForComponent("security-events"),
+	}
+}
+
+// Record fills in event metadata, applies anomaly flags, stores the event and runs hooks
+func (s *eventService) Record(ctx context.Context, event *domain.SecurityEvent) {
+	event.ID = uuid.New().String()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	if event.ClientIP == "" {
+		event.ClientIP, _ = middleware.GetClientIPFromContext(ctx)
+	}
+	if event.RequestID == "" {
+		event.RequestID, _ = response.RequestIDFromContext(ctx)
+	}
+	if event.Severity == "" {
+		event.Severity = domain.SecuritySeverityLow
+	}
+	if event.Type == domain.SecurityEventLoginFailed {
+		s.flagRepeatedFailures(ctx, event)
+	}
+
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	if overflow := len(s.events) - s.config.BufferSize; overflow > 0 {
+		s.events = append([]*domain.SecurityEvent(nil), s.events[overflow:]...)
+	}
+	s.mu.Unlock()
+
+	if domain.SeverityAtLeast(event.Severity, domain.SecuritySeverityHigh) {
+		s.logger.Warn("Security event", "type", event.Type, "severity", event.Severity,
+			"flags", event.Flags, "target_id", event.TargetID, "client_ip", event.ClientIP)
+	}
+
+	s.registry.SecurityEventRecorded(ctx, event)
+}
+
+// flagRepeatedFailures marks login failures that exceed the threshold for
+// the same email or client within the window, raising their severity
+func (s *eventService) flagRepeatedFailures(ctx context.Context, event *domain.SecurityEvent) {
+	if s.config.FailureThreshold <= 0 {
+		return
+	}
+
+	check := func(key, flag string) {
+		result, err := s.limiter.Allow(ctx, key, s.config.FailureThreshold, s.config.FailureWindow)
+		if err == nil && !result.Allowed {
+			event.Flags = append(event.Flags, flag)
+		}
+	}
+	if event.Email != "" {
+		check("security:login-failed:email:"+event.Email, domain.SecurityFlagRepeatedFailuresEmail)
+	}
+	if event.ClientIP != "" {
+		check("security:login-failed:ip:"+event.ClientIP, domain.SecurityFlagRepeatedFailuresIP)
+	}
+	if len(event.Flags) > 0 {
+		event.Severity = domain.SecuritySeverityHigh
+	}
+}
+
+// ListEvents returns matching events with pagination, newest first
+func (s *eventService) ListEvents(
+	_ context.Context,
+	filter domain.SecurityEventFilter,
+	limit, offset int,
+) ([]*domain.SecurityEvent, int64, error) {
+	if filter.MinSeverity != "" && !domain.IsSecuritySeverity(filter.MinSeverity) {
+		return nil, 0, &domain.Error{Code: "VALIDATION_FAILED", Message: "Unknown severity: " + filter.MinSeverity}
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	s.mu.RLock()
+	matched := make([]*domain.SecurityEvent, 0, len(s.events))
+	for _, event := range s.events {
+		if matchesFilter(event, filter) {
+			matched = append(matched, event)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []*domain.SecurityEvent{}, total, nil
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[offset:end], total, nil
+}
+
+func matchesFilter(event *domain.SecurityEvent, filter domain.SecurityEventFilter) bool {
+	if filter.Type != "" && event.Type != filter.Type {
+		return false
+	}
+	if filter.TargetID != "" && event.TargetID != filter.TargetID {
+		return false
+	}
+	if filter.MinSeverity != "" && !domain.SeverityAtLeast(event.Severity, filter.MinSeverity) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package security
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/hooks"
+)
+
+// RegisterLoginHooks records a security event for every rejected login
+func RegisterLoginHooks(registry *hooks.Registry, events domain.SecurityEventService) {
+	registry.OnLoginFailed("security-events", func(ctx context.Context, email string, reason error) error {
+		event := &domain.SecurityEvent{
+			Type:     domain.SecurityEventLoginFailed,
+			Severity: domain.SecuritySeverityLow,
+			Email:    email,
+		}
+		if domainErr, ok := reason.(*domain.Error); ok {
+			event.Reason = domainErr.Code
+		}
+		events.Record(ctx, event)
+		return nil
+	})
+}
+
+// auditService derives security events from recorded audit events
+type auditService struct {
+	domain.AuditService
+	events domain.SecurityEventService
+}
+
+// NewAuditService wraps an audit service so privilege changes, suspensions
+// and deletions are also recorded as security events
+func NewAuditService(inner domain.AuditService, events domain.SecurityEventService) domain.AuditService {
+	return &auditService{AuditService: inner, events: events}
+}
+
+// Record persists the audit events and records matching security events
+func (s *auditService) Record(ctx context.Context, events ...*domain.AuditEvent) error {
+	err := s.AuditService.Record(ctx, events...)
+
+	for _, audit := range events {
+		if event := securityEventFromAudit(audit); event != nil {
+			s.events.Record(ctx, event)
+		}
+	}
+	return err
+}
+
+func securityEventFromAudit(audit *domain.AuditEvent) *domain.SecurityEvent {
+	event := &domain.SecurityEvent{
+		ActorID:   audit.ActorID,
+		TargetID:  audit.TargetID,
+		Details:   audit.Details,
+		CreatedAt: audit.CreatedAt,
+		Severity:  domain.SecuritySeverityMedium,
+	}
+
+	switch audit.Action {
+	case domain.AuditActionUserRoleChanged:
+		event.Type = domain.SecurityEventRoleChanged
+		if to, _ := audit.Details["to"].(string); to == "admin" {
+			event.Severity = domain.SecuritySeverityHigh
+			event.Flags = []string{domain.SecurityFlagPrivilegeEscalation}
+		}
+	case domain.AuditActionUserSuspended:
+		event.Type = domain.SecurityEventUserSuspended
+	case domain.AuditActionUserBanned:
+		event.Type = domain.SecurityEventUserBanned
+		event.Severity = domain.SecuritySeverityHigh
+	case domain.AuditActionUserDeleted:
+		event.Type = domain.SecurityEventUserDeleted
+	default:
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/ratelimit"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyAnswerHash is compared against in place of questions an account does
+// not have, so that response timing does not reveal whether the email exists
+var dummyAnswerHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-recovery-answer"), bcrypt.DefaultCost)
+
+// accountRecoveryService implements domain.AccountRecoveryService
+type accountRecoveryService struct {
+	userRepo    domain.UserRepository
+	tokenStore  domain.ResetTokenStore
+	limiter     ratelimit.Limiter
+	passwords   domain.PasswordPolicy
+	hasher      domain.PasswordHasher
+	revocations domain.TokenRevocationService
+	config      config.RecoveryConfig
+	catalog     map[string]bool
+	logger      *logger.Logger
+}
+
+// NewAccountRecoveryService creates a new security-question recovery service.
+// New passwords are checked against passwords and hashed with hasher; nil
+// ones only require MinPasswordLen characters and hash with bcrypt at
+// BCryptCost. Once a password is reset, revocations signs out every token
+// issued to the user before; nil skips it.
+func NewAccountRecoveryService(
+	userRepo domain.UserRepository,
+	tokenStore domain.ResetTokenStore,
+	limiter ratelimit.Limiter,
+	passwords domain.PasswordPolicy,
+	hasher domain.PasswordHasher,
+	revocations domain.TokenRevocationService,
+	cfg config.RecoveryConfig,
+) domain.AccountRecoveryService {
+	if passwords == nil {
+		passwords = defaultPasswordPolicy()
+	}
+	if hasher == nil {
+		hasher = defaultPasswordHasher()
+	}
+	catalog := make(map[string]bool, len(cfg.Questions))
+	for _, q := range cfg.Questions {
+		catalog[q] = true
+	}
+
+	return &accountRecoveryService{
+		userRepo:    userRepo,
+		tokenStore:  tokenStore,
+		limiter:     limiter,
+		passwords:   passwords,
+		hasher:      hasher,
+		revocations: revocations,
+		config:      cfg,
+		catalog:     catalog,
+		logger:      logger.GetGlobal().ForComponent("account-recovery-service"),
+	}
+}
+
+// SecurityQuestionCatalog returns the questions users may choose from
+func (s *accountRecoveryService) SecurityQuestionCatalog() []string {
+	return append([]string(nil), s.config.Questions...)
+}
+
+// SetSecurityQuestions stores hashed answers for the user's chosen questions
+func (s *accountRecoveryService) SetSecurityQuestions(
+	ctx context.Context,
+	userID string,
+	req *domain.SetSecurityQuestionsRequest,
+) error {
+	log := s.logger.ForService("recovery", "set-questions").WithField("user_id", userID)
+
+	if err := s.validateAnswers(req.Answers); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	questions := make([]domain.SecurityQuestion, 0, len(req.Answers))
+	for _, a := range req.Answers {
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeAnswer(a.Answer)), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash answer: %w", err)
+		}
+		questions = append(questions, domain.SecurityQuestion{Question: a.Question, AnswerHash: string(hash)})
+	}
+
+	user.SecurityQuestions = questions
+	if err := s.userRepo.Update(ctx, userID, user); err != nil {
+		return err
+	}
+
+	log.Info("Security questions updated", "count", len(questions))
+	return nil
+}
+
+// GetRecoveryQuestions returns the questions to ask for an email address.
+// Unknown and inactive accounts receive a stable decoy set so the endpoint
+// cannot be used to discover which emails are registered, or their status.
+func (s *accountRecoveryService) GetRecoveryQuestions(ctx context.Context, email string) ([]string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Email is required"}
+	}
+
+	if err := s.checkRateLimit(ctx, "recovery:questions:"+email); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && err != domain.ErrUserNotFound {
+		return nil, err
+	}
+	if user == nil || !user.IsActive() || len(user.SecurityQuestions) == 0 {
+		return s.decoyQuestions(email), nil
+	}
+
+	questions := make([]string, 0, len(user.SecurityQuestions))
+	for _, q := range user.SecurityQuestions {
+		questions = append(questions, q.Question)
+	}
+	return questions, nil
+}
+
+// VerifyAnswers checks all answers and issues a reset token on success.
+// Suspended, banned and deleted accounts cannot be recovered; they fail like
+// unknown ones.
+func (s *accountRecoveryService) VerifyAnswers(
+	ctx context.Context,
+	req *domain.VerifyRecoveryRequest,
+	clientIP string,
+) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	log := s.logger.ForService("recovery", "verify").WithField("email", email)
+
+	if email == "" || len(req.Answers) == 0 {
+		return "", &domain.Error{Code: "VALIDATION_FAILED", Message: "Email and answers are required"}
+	}
+
+	// Both the target account and the caller are throttled
+	if err := s.checkRateLimit(ctx, "recovery:verify:email:"+email); err != nil {
+		log.Warn("Recovery attempts rate limited for email")
+		return "", err
+	}
+	if err := s.checkRateLimit(ctx, "recovery:verify:ip:"+clientIP); err != nil {
+		log.Warn("Recovery attempts rate limited for client", "client_ip", clientIP)
+		return "", err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && err != domain.ErrUserNotFound {
+		return "", err
+	}
+	var questions []domain.SecurityQuestion
+	if user != nil && user.IsActive() {
+		questions = user.SecurityQuestions
+	}
+
+	provided := make(map[string]string, len(req.Answers))
+	for _, a := range req.Answers {
+		provided[a.Question] = a.Answer
+	}
+
+	// Check every question even after a mismatch, and compare against the
+	// dummy hash up to the number of questions unknown accounts are shown,
+	// so timing is the same for unknown, inactive and question-less accounts
+	correct := len(questions) > 0
+	for i := 0; i < max(s.config.RequiredAnswers, len(questions)); i++ {
+		if i >= len(questions) {
+			_ = bcrypt.CompareHashAndPassword(dummyAnswerHash, []byte("x"))
+			continue
+		}
+		answer, ok := provided[questions[i].Question]
+		if bcrypt.CompareHashAndPassword([]byte(questions[i].AnswerHash), []byte(normalizeAnswer(answer))) != nil || !ok {
+			correct = false
+		}
+	}
+	if user == nil || len(user.SecurityQuestions) == 0 {
+		log.Warn("Recovery attempt for account without security questions")
+		return "", domain.ErrRecoveryFailed
+	}
+	if !user.IsActive() {
+		log.Warn("Recovery attempt for inactive account", "user_id", user.ID, "status", user.Status)
+		return "", domain.ErrRecoveryFailed
+	}
+	if !correct {
+		log.Warn("Incorrect recovery answers")
+		return "", domain.ErrRecoveryFailed
+	}
+
+	token, err := issueResetToken(ctx, s.tokenStore, user, s.config.TokenTTL)
+	if err != nil {
+		return "", err
+	}
+
+	_ = s.limiter.Reset(ctx, "recovery:verify:email:"+email)
+	log.Info("Recovery answers verified, reset token issued", "user_id", user.ID)
+	return token, nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs out
+// the tokens issued with the old one
+func (s *accountRecoveryService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	user, err := redeemResetToken(ctx, s.userRepo, s.tokenStore, s.passwords, s.hasher, req)
+	if err != nil {
+		return err
+	}
+
+	log := s.logger.ForService("recovery", "reset-password")
+	if s.revocations != nil {
+		if err := s.revocations.RevokeUserTokens(ctx, user.ID, user.ID); err != nil {
+			log.Error("Password reset but existing tokens were not revoked", "user_id", user.ID, "error", err)
+			return fmt.Errorf("password reset, but existing tokens were not revoked: %w", err)
+		}
+	}
+	log.Info("Password reset via account recovery", "user_id", user.ID)
+	return nil
+}
+
+// Helper methods
+
+func (s *accountRecoveryService) validateAnswers(answers []domain.SecurityAnswer) error {
+	if len(answers) < s.config.RequiredAnswers {
+		return &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("At least %d security questions are required", s.config.RequiredAnswers),
+		}
+	}
+
+	seen := make(map[string]bool, len(answers))
+	for _, a := range answers {
+		if !s.catalog[a.Question] {
+			return &domain.Error{Code: "VALIDATION_FAILED", Message: "Unknown security question: " + a.Question}
+		}
+		if seen[a.Question] {
+			return &domain.Error{Code: "VALIDATION_FAILED", Message: "Security questions must be distinct"}
+		}
+		if normalizeAnswer(a.Answer) == "" {
+			return &domain.Error{Code: "VALIDATION_FAILED", Message: "Security answers must not be empty"}
+		}
+		seen[a.Question] = true
+	}
+	return nil
+}
+
+func (s *accountRecoveryService) checkRateLimit(ctx context.Context, key string) error {
+	result, err := s.limiter.Allow(ctx, key, s.config.MaxAttempts, s.config.AttemptWindow)
+	if err != nil {
+		return err
+	}
+	if !result.Allowed {
+		return domain.ErrRateLimited
+	}
+	return nil
+}
+
+// decoyQuestions deterministically picks catalog questions for an email
+func (s *accountRecoveryService) decoyQuestions(email string) []string {
+	catalog := s.config.Questions
+	count := s.config.RequiredAnswers
+	if count > len(catalog) {
+		count = len(catalog)
+	}
+	if count == 0 {
+		return []string{}
+	}
+
+	sum := sha256.Sum256([]byte(email))
+	start := int(binary.BigEndian.Uint32(sum[:4]) % uint32(len(catalog))) // #nosec G115
+
+	questions := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		questions = append(questions, catalog[(start+i)%len(catalog)])
+	}
+	return questions
+}
+
+// normalizeAnswer makes answer comparison insensitive to case and spacing
+func normalizeAnswer(answer string) string {
+	return strings.ToLower(strings.Join(strings.Fields(answer), " "))
+}
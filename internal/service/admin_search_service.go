@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// adminSearchService implements domain.AdminSearchService
+type adminSearchService struct {
+	users    domain.UserQueryService
+	audit    domain.AuditService
+	sessions domain.SessionRepository
+	timeout  time.Duration
+	slots    chan struct{}
+	logger   *logger.Logger
+}
+
+// NewAdminSearchService creates the admin search. sessions is nil when
+// sessions are not tracked, which leaves the sessions results empty.
+func NewAdminSearchService(
+	users domain.UserQueryService,
+	audit domain.AuditService,
+	sessions domain.SessionRepository,
+	cfg config.AdminSearchConfig,
+) domain.AdminSearchService {
+	if cfg.SourceTimeout <= 0 {
+		cfg.SourceTimeout = 2 * time.Second
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 8
+	}
+
+	return &adminSearchService{
+		users:    users,
+		audit:    audit,
+		sessions: sessions,
+		timeout:  cfg.SourceTimeout,
+		slots:    make(chan struct{}, cfg.MaxConcurrent),
+		logger:   logger.GetGlobal().ForComponent("admin-search"),
+	}
+}
+
+// Search searches the users, then the other sources concurrently for the
+// query and the IDs of the users found. A failing or slow source is reported
+// in the results' Errors instead of failing the search.
+func (s *adminSearchService) Search(ctx context.Context, query string, limit int) (*domain.AdminSearchResults, error) {
+	query = strings.TrimSpace(query)
+	if n := utf8.RuneCountInString(query); n < domain.MinAdminSearchQuery || n > domain.MaxAdminSearchQuery {
+		return nil, domain.ErrInvalidSearchQuery
+	}
+
+	results := &domain.AdminSearchResults{
+		Query:       query,
+		Users:       []*domain.UserResponse{},
+		AuditEvents: []*domain.AuditEvent{},
+		Sessions:    []*domain.Session{},
+	}
+	var mu sync.Mutex
+	failed := func(name string, err error) {
+		reason := "failed"
+		if errors.Is(err, context.DeadlineExceeded) {
+			reason = "timed out"
+		}
+		s.logger.Warn("Admin search source failed", "source", name, "reason", reason, "error", err)
+		mu.Lock()
+		defer mu.Unlock()
+		if results.Errors == nil {
+			results.Errors = make(map[string]string)
+		}
+		results.Errors[name] = reason
+	}
+
+	// The users found widen the other searches: an email matches the
+	// events and sessions of its user, which are keyed by user ID
+	subjects := []string{query}
+	err := s.searchSource(ctx, func(ctx context.Context) error {
+		users, _, err := s.users.SearchUsers(ctx, &domain.UserQuery{Search: query, Limit: limit, SkipTotal: true})
+		if err == nil {
+			results.Users = users
+		}
+		return err
+	})
+	if err != nil {
+		failed(domain.AdminSearchSourceUsers, err)
+	}
+	for _, user := range results.Users {
+		if user.ID != query {
+			subjects = append(subjects, user.ID)
+		}
+	}
+
+	sources := map[string]func(ctx context.Context) error{
+		domain.AdminSearchSourceAudit: func(ctx context.Context) error {
+			events, _, err := s.audit.ListEvents(ctx, domain.AuditFilter{Subjects: subjects}, limit, 0)
+			if err == nil {
+				results.AuditEvents = events
+			}
+			return err
+		},
+	}
+	if s.sessions != nil {
+		sources[domain.AdminSearchSourceSessions] = func(ctx context.Context) error {
+			sessions, err := s.sessions.Search(ctx, subjects, limit)
+			if err == nil {
+				results.Sessions = sessions
+			}
+			return err
+		}
+	}
+
+	// Each source only sets its own field; failures are collected under the lock
+	var wg sync.WaitGroup
+	for name, search := range sources {
+		wg.Add(1)
+		go func(name string, search func(context.Context) error) {
+			defer wg.Done()
+			if err := s.searchSource(ctx, search); err != nil {
+				failed(name, err)
+			}
+		}(name, search)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// searchSource runs one source search under the source timeout, once a
+// concurrency slot is free
+func (s *adminSearchService) searchSource(ctx context.Context, search func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	// Drivers wrap expired contexts in their own errors
+	if err := search(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
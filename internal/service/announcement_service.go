@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+const (
+	// maxAnnouncementLength bounds announcement messages
+	maxAnnouncementLength = 500
+	// announcementLoadTimeout bounds one background reload of the announcement
+	announcementLoadTimeout = 5 * time.Second
+)
+
+// announcementService implements domain.AnnouncementService. Clients are
+// served from a snapshot of the store that is reloaded in the background once
+// it is older than the refresh interval, and right away after a change on
+// this instance.
+type announcementService struct {
+	store        domain.AnnouncementStore
+	auditService domain.AuditService
+	interval     time.Duration
+	logger       *logger.Logger
+	now          func() time.Time
+
+	mu           sync.Mutex
+	announcement *domain.Announcement
+	loadedAt     time.Time
+	reloading    bool
+}
+
+// NewAnnouncementService creates an announcement service whose changes
+// reach every instance within interval
+func NewAnnouncementService(
+	store domain.AnnouncementStore,
+	auditService domain.AuditService,
+	interval time.Duration,
+) domain.AnnouncementService {
+	return &announcementService{
+		store:        store,
+		auditService: auditService,
+		interval:     interval,
+		logger:       logger.GetGlobal().ForComponent("announcement-service"),
+		now:          time.Now,
+	}
+}
+
+// Current returns the announcement in effect from the snapshot, starting a
+// background reload when the snapshot is stale
+func (s *announcementService) Current() (*domain.Announcement, bool) {
+	now := s.now()
+
+	s.mu.Lock()
+	if !s.reloading && now.Sub(s.loadedAt) >= s.interval {
+		s.reloading = true
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), announcementLoadTimeout)
+			defer cancel()
+			_ = s.Reload(ctx)
+		}()
+	}
+	announcement := s.announcement
+	s.mu.Unlock()
+
+	if announcement == nil || announcement.Expired(now) {
+		return nil, false
+	}
+	return announcement, true
+}
+
+// Get returns the stored announcement, expired or not
+func (s *announcementService) Get(ctx context.Context) (*domain.Announcement, error) {
+	return s.store.Get(ctx)
+}
+
+// Set validates and stores the announcement, replacing any previous one
+func (s *announcementService) Set(ctx context.Context, actorID string, req *domain.SetAnnouncementRequest) (*domain.Announcement, error) {
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Announcement message is required"}
+	}
+	if len([]rune(message)) > maxAnnouncementLength {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Announcements are at most %d characters", maxAnnouncementLength),
+		}
+	}
+	severity := strings.ToLower(strings.TrimSpace(req.Severity))
+	switch severity {
+	case "":
+		severity = domain.AnnouncementSeverityInfo
+	case domain.AnnouncementSeverityInfo, domain.AnnouncementSeverityWarning, domain.AnnouncementSeverityCritical:
+	default:
+		return nil, &domain.Error{
+			Code: "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Severity must be %s, %s or %s",
+				domain.AnnouncementSeverityInfo, domain.AnnouncementSeverityWarning, domain.AnnouncementSeverityCritical),
+		}
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Announcement expiry must be in the future"}
+	}
+
+	announcement := &domain.Announcement{
+		Message:   message,
+		Severity:  severity,
+		UpdatedBy: actorID,
+		UpdatedAt: now,
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		announcement.ExpiresAt = &expiresAt
+	}
+	if err := s.store.Set(ctx, announcement); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionAnnouncementUpdated, actorID, map[string]interface{}{
+		"message": message, "severity": severity, "expires_at": announcement.ExpiresAt,
+	})
+	s.logger.ForService("announcement", "set").Info("Announcement set", "actor_id", actorID, "severity", severity)
+	s.apply(announcement)
+	return announcement, nil
+}
+
+// Clear removes the announcement
+func (s *announcementService) Clear(ctx context.Context, actorID string) error {
+	if err := s.store.Delete(ctx); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionAnnouncementCleared, actorID, nil)
+	s.logger.ForService("announcement", "clear").Info("Announcement cleared", "actor_id", actorID)
+	s.apply(nil)
+	return nil
+}
+
+// Reload replaces the snapshot with the stored announcement. When the store
+// cannot be read the last snapshot stays in use until the next refresh.
+func (s *announcementService) Reload(ctx context.Context) error {
+	announcement, err := s.store.Get(ctx)
+	if errors.Is(err, domain.ErrAnnouncementNotFound) {
+		announcement, err = nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloading = false
+	s.loadedAt = s.now()
+	if err != nil {
+		s.logger.Warn("Failed to reload the announcement; keeping the last one", "error", err)
+		return err
+	}
+	s.announcement = announcement
+	return nil
+}
+
+// Helper methods
+
+// apply puts a change made on this instance in effect right away
+func (s *announcementService) apply(announcement *domain.Announcement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.announcement = announcement
+	s.loadedAt = s.now()
+}
+
+// record writes an audit event for an announcement change; a failed write is
+// logged by the audit service, not returned
+func (s *announcementService) record(ctx context.Context, action, actorID string, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:  action,
+		ActorID: actorID,
+		Details: details,
+	})
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyPrefix starts every key so leaked keys are easy to recognize
+	apiKeyPrefix = "dgk_"
+	// apiKeyDisplayLength is how much of a key is kept as its prefix
+	apiKeyDisplayLength = 12
+	// maxAPIKeyNameLength bounds key names
+	maxAPIKeyNameLength = 100
+	// apiKeyTouchInterval bounds how often a key's last use is written
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyService implements domain.APIKeyService
+type apiKeyService struct {
+	keyRepo      domain.APIKeyRepository
+	userRepo     domain.UserRepository
+	auditService domain.AuditService
+	defaultRole  string
+	config       config.APIKeyConfig
+	logger       *logger.Logger
+	now          func() time.Time
+}
+
+// NewAPIKeyService creates a new API key service. defaultRole is the role
+// keys without the admin scope act with, so that scope is refused for users
+// who have no other role.
+func NewAPIKeyService(
+	keyRepo domain.APIKeyRepository,
+	userRepo domain.UserRepository,
+	auditService domain.AuditService,
+	defaultRole string,
+	cfg config.APIKeyConfig,
+) domain.APIKeyService {
+	return &apiKeyService{
+		keyRepo:      keyRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+		defaultRole:  defaultRole,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("api-key-service"),
+		now:          time.Now,
+	}
+}
+
+// CreateKey issues a new key for the user and returns it with its secret
+func (s *apiKeyService) CreateKey(
+	ctx context.Context,
+	actorID, userID string,
+	req *domain.CreateAPIKeyRequest,
+) (*domain.CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > maxAPIKeyNameLength {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("API key names are 1 to %d characters", maxAPIKeyNameLength),
+		}
+	}
+	scopes, err := normalizeScopes("API keys", req.Scopes, false)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "API key expiry must be in the future"}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := selfAccessError(user.Status); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.keyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.MaxPerUser > 0 && len(existing) >= s.config.MaxPerUser {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Users can have at most %d API keys", s.config.MaxPerUser),
+		}
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	rawKey := apiKeyPrefix + secret
+	key := &domain.APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		Hash:      hashAPIKey(rawKey),
+		Scopes:    scopes,
+		CreatedBy: actorID,
+		CreatedAt: now,
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+	if key.HasScope(domain.APIKeyScopeAdmin) && user.Role == s.defaultRole {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "The admin scope needs a user with a privileged role"}
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionAPIKeyCreated, actorID, key)
+	s.logger.ForService("api_key", "create").Info("API key created",
+		"actor_id", actorID, "user_id", userID, "key_id", key.ID, "scopes", scopes)
+	return &domain.CreatedAPIKey{APIKey: key, Key: rawKey}, nil
+}
+
+// ListKeys returns the user's keys, newest first, without their secrets
+func (s *apiKeyService) ListKeys(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := adminAccessError(user.Status); err != nil {
+		return nil, err
+	}
+	return s.keyRepo.ListByUser(ctx, userID)
+}
+
+// RevokeKey deletes a key of the user; it stops working immediately
+func (s *apiKeyService) RevokeKey(ctx context.Context, actorID, userID, keyID string) error {
+	keys, err := s.keyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var key *domain.APIKey
+	for _, k := range keys {
+		if k.ID == keyID {
+			key = k
+		}
+	}
+	if key == nil {
+		return domain.ErrAPIKeyNotFound
+	}
+	if err := s.keyRepo.Delete(ctx, userID, keyID); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionAPIKeyRevoked, actorID, key)
+	s.logger.ForService("api_key", "revoke").Info("API key revoked", "actor_id", actorID, "user_id", userID, "key_id", keyID)
+	return nil
+}
+
+// Authenticate looks a key up by its hash and checks it and its owner
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*domain.APIKey, *domain.User, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+	key, err := s.keyRepo.GetByHash(ctx, hashAPIKey(rawKey))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	now := s.now().UTC()
+	if key.Expired(now) {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if selfAccessError(user.Status) != nil {
+		return nil, nil, domain.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// A missed last-use write is not worth failing the request for
+		if err := s.keyRepo.Touch(ctx, key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, user, nil
+}
+
+// Helper methods
+
+// record writes an audit event for a key; a failed write is logged by the
+// audit service, not returned
+func (s *apiKeyService) record(ctx context.Context, action, actorID string, key *domain.APIKey) {
+	if s.auditService == nil {
+		return
+	}
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:   action,
+		ActorID:  actorID,
+		TargetID: key.UserID,
+		Details:  map[string]interface{}{"key_id": key.ID, "name": key.Name, "scopes": key.Scopes},
+	})
+}
+
+// normalizeScopes checks the scopes requested for holders, such as "API
+// keys", and returns them without duplicates. With resourceScopes, scopes of
+// the "<resource>:<action>" form are accepted too.
+func normalizeScopes(holders string, requested []string, resourceScopes bool) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: holders + " need at least one scope"}
+	}
+	known := make(map[string]bool, len(domain.APIKeyScopes))
+	for _, scope := range domain.APIKeyScopes {
+		known[scope] = true
+	}
+
+	scopes := make([]string, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !known[scope] && !(resourceScopes && domain.IsResourceScope(scope)) {
+			return nil, &domain.Error{
+				Code:    "VALIDATION_FAILED",
+				Message: fmt.Sprintf("Unknown scope %q; scopes are %s", scope, strings.Join(domain.APIKeyScopes, ", ")),
+			}
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// hashAPIKey is the stored form of a key. Keys are random, so a fast hash is
+// enough to keep a database dump from revealing usable keys.
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
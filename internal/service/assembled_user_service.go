@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+
+	"demo-go/internal/assembler"
+	"demo-go/internal/domain"
+)
+
+// assembledUserService wraps a UserService and enriches every returned user
+// through the response assembler. It sits outside the cache so cached entries
+// hold only the user record and enriched data is always current.
+type assembledUserService struct {
+	domain.UserService
+	assembler *assembler.Assembler
+}
+
+// NewAssembledUserService creates a user service decorator that composes responses from extra sources
+func NewAssembledUserService(userService domain.UserService, responseAssembler *assembler.Assembler) domain.UserService {
+	return &assembledUserService{
+		UserService: userService,
+		assembler:   responseAssembler,
+	}
+}
+
+// Register creates a new user account and returns the assembled response
+func (s *assembledUserService) Register(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error) {
+	user, err := s.UserService.Register(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.assembleOne(ctx, user), nil
+}
+
+// Login authenticates a user and returns the assembled response
+func (s *assembledUserService) Login(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error) {
+	token, user, err := s.UserService.Login(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, s.assembleOne(ctx, user), nil
+}
+
+// GetProfile retrieves the user's assembled profile
+func (s *assembledUserService) GetProfile(ctx context.Context, userID string) (*domain.UserResponse, error) {
+	user, err := s.UserService.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.assembleOne(ctx, user), nil
+}
+
+// UpdateProfile updates the user's profile and returns the assembled response
+func (s *assembledUserService) UpdateProfile(
+	ctx context.Context,
+	userID string,
+	req *domain.UpdateUserRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.UserService.UpdateProfile(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.assembleOne(ctx, user), nil
+}
+
+// UpdateUser applies an admin update and returns the assembled response
+func (s *assembledUserService) UpdateUser(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.UpdateUserRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.UserService.UpdateUser(ctx, actorID, id, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.assembleOne(ctx, user), nil
+}
+
+// GetUsers retrieves a page of users, fetching only the sources for the selected fields
+func (s *assembledUserService) GetUsers(ctx context.Context, limit, offset int, fields ...string) ([]*domain.UserResponse, int64, error) {
+	users, total, err := s.UserService.GetUsers(ctx, limit, offset, fields...)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.assembler.Assemble(ctx, users, fields...)
+	return users, total, nil
+}
+
+// SearchUsers runs a list query, fetching only the sources for the selected fields
+func (s *assembledUserService) SearchUsers(ctx context.Context, query *domain.UserQuery) ([]*domain.UserResponse, int64, error) {
+	users, total, err := s.UserService.SearchUsers(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.assembler.Assemble(ctx, users, query.Fields...)
+	return users, total, nil
+}
+
+// GetUserByID retrieves an assembled user by ID
+func (s *assembledUserService) GetUserByID(ctx context.Context, id string) (*domain.UserResponse, error) {
+	user, err := s.UserService.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.assembleOne(ctx, user), nil
+}
+
+func (s *assembledUserService) assembleOne(ctx context.Context, user *domain.UserResponse) *domain.UserResponse {
+	s.assembler.Assemble(ctx, []*domain.UserResponse{user})
+	return user
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// auditService implements domain.AuditService
+type auditService struct {
+	auditRepo domain.AuditRepository
+	logger    *logger.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo domain.AuditRepository) domain.AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		logger:    logger.GetGlobal().ForComponent("audit-service"),
+	}
+}
+
+// Record persists one or more audit events
+func (s *auditService) Record(ctx context.Context, events ...*domain.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := s.auditRepo.CreateMany(ctx, events); err != nil {
+		s.logger.ForService("audit", "record").Error("Failed to record audit events", "count", len(events), "error", err)
+		return err
+	}
+	return nil
+}
+
+// ListEvents returns matching audit events with pagination, newest first
+func (s *auditService) ListEvents(
+	ctx context.Context,
+	filter domain.AuditFilter,
+	limit, offset int,
+) ([]*domain.AuditEvent, int64, error) {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, err := s.auditRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.auditRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// listStaleKey records when user writes last made the cached list pages stale
+const listStaleKey = "users:list:stale_since"
+
+// listRefreshTimeout bounds one background refresh of a list page
+const listRefreshTimeout = 10 * time.Second
+
+// cachedListPage is a page of the user list as cached
+type cachedListPage struct {
+	Users    []*domain.UserResponse `json:"users"`
+	Total    int64                  `json:"total"`
+	CachedAt time.Time              `json:"cached_at"`
+}
+
+// listPageCache serves user list pages stale-while-revalidate: a page is
+// served from cache right away, and once it is older than the soft TTL, or
+// a write has happened since it was cached, it is refreshed in the
+// background. Pages expire after the hard TTL and are then loaded
+// synchronously again.
+type listPageCache struct {
+	cache   cache.Service
+	softTTL time.Duration
+	hardTTL time.Duration
+	logger  *logger.Logger
+
+	mu         sync.Mutex
+	refreshing map[string]bool // pages being refreshed by this instance
+}
+
+// WithListPageCache caches pages of the user list with stale-while-revalidate.
+// Projected pages are never cached.
+func WithListPageCache(cfg config.ListPageCacheConfig) CachedUserServiceOption {
+	return func(s *cachedUserService) {
+		if !cfg.Enabled {
+			return
+		}
+		if cfg.SoftTTL <= 0 {
+			cfg.SoftTTL = 30 * time.Second
+		}
+		if cfg.HardTTL < cfg.SoftTTL {
+			cfg.HardTTL = cfg.SoftTTL
+		}
+		s.listPages = &listPageCache{
+			cache:      s.cache,
+			softTTL:    cfg.SoftTTL,
+			hardTTL:    cfg.HardTTL,
+			logger:     s.logger.WithField("cache", "user-list"),
+			refreshing: make(map[string]bool),
+		}
+	}
+}
+
+// get returns the page, from cache when one is there. load reads the page
+// from the underlying service; onLoad is called with every page it loads.
+func (c *listPageCache) get(
+	ctx context.Context,
+	limit, offset int,
+	load func(context.Context) ([]*domain.UserResponse, int64, error),
+	onLoad func(context.Context, []*domain.UserResponse),
+) ([]*domain.UserResponse, int64, error) {
+	key := fmt.Sprintf("users:list:%d:%d", limit, offset)
+
+	var page cachedListPage
+	if err := cache.GetVersioned(ctx, c.cache, key, &page); err == nil {
+		if c.stale(ctx, &page) {
+			c.refresh(key, load, onLoad)
+		}
+		return page.Users, page.Total, nil
+	}
+
+	users, total, err := c.store(ctx, key, load)
+	if err != nil {
+		return nil, 0, err
+	}
+	onLoad(ctx, users)
+	return users, total, nil
+}
+
+// stale reports whether a cached page is past the soft TTL or older than
+// the last write
+func (c *listPageCache) stale(ctx context.Context, page *cachedListPage) bool {
+	if time.Since(page.CachedAt) >= c.softTTL {
+		return true
+	}
+	var staleSince time.Time
+	if err := c.cache.Get(ctx, listStaleKey, &staleSince); err != nil {
+		return false
+	}
+	return !page.CachedAt.After(staleSince)
+}
+
+// refresh reloads the page in the background unless this instance is already
+// refreshing it
+func (c *listPageCache) refresh(
+	key string,
+	load func(context.Context) ([]*domain.UserResponse, int64, error),
+	onLoad func(context.Context, []*domain.UserResponse),
+) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), listRefreshTimeout)
+		defer cancel()
+		users, _, err := c.store(ctx, key, load)
+		if err != nil {
+			// The stale page keeps being served until it expires
+			c.logger.Warn("Failed to refresh cached user list page", "cache_key", key, "error", err)
+			return
+		}
+		onLoad(ctx, users)
+		c.logger.Debug("Refreshed cached user list page", "cache_key", key)
+	}()
+}
+
+// store loads the page and caches it for the hard TTL
+func (c *listPageCache) store(
+	ctx context.Context,
+	key string,
+	load func(context.Context) ([]*domain.UserResponse, int64, error),
+) ([]*domain.UserResponse, int64, error) {
+	cachedAt := time.Now().UTC()
+	users, total, err := load(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	page := &cachedListPage{Users: users, Total: total, CachedAt: cachedAt}
+	if err := cache.SetVersioned(ctx, c.cache, key, page, c.hardTTL); err != nil {
+		c.logger.Warn("Failed to cache user list page", "cache_key", key, "error", err)
+	}
+	return users, total, nil
+}
+
+// markStale makes every cached page stale without dropping it, so pages keep
+// being served while they are refreshed
+func (c *listPageCache) markStale(ctx context.Context, log *logger.Logger) {
+	if err := c.cache.Set(ctx, listStaleKey, time.Now().UTC(), c.hardTTL); err != nil {
+		log.Warn("Failed to mark cached user list pages stale", "error", err)
+	}
+}
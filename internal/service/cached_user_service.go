@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"demo-go/internal/cache"
@@ -9,12 +12,132 @@ import (
 	"demo-go/internal/logger"
 )
 
+// userStatsCacheKey stores the most recently computed user statistics
+const userStatsCacheKey = "stats:users"
+
+// userStatsFreshness is how long cached statistics are served without recomputing
+const userStatsFreshness = time.Minute
+
+// CacheStrategy decides how an operation uses the user cache
+type CacheStrategy string
+
+// Cache strategies. Reads are read-through or none; writes keep the cache in
+// step synchronously (write-through), asynchronously (write-behind), by
+// dropping the entry (invalidate), or not at all (none). The primary store is
+// always written synchronously.
+const (
+	CacheStrategyReadThrough  CacheStrategy = "read-through"
+	CacheStrategyWriteThrough CacheStrategy = "write-through"
+	CacheStrategyWriteBehind  CacheStrategy = "write-behind"
+	CacheStrategyInvalidate   CacheStrategy = "invalidate"
+	CacheStrategyNone         CacheStrategy = "none"
+)
+
+// Cached operations, as named in CACHE_STRATEGIES
+const (
+	CacheOpRegister      = "register"
+	CacheOpLogin         = "login"
+	CacheOpGetProfile    = "get_profile"
+	CacheOpGetUserByID   = "get_user_by_id"
+	CacheOpUpdateProfile = "update_profile"
+	CacheOpUpdateUser    = "update_user"
+	CacheOpDeleteUser    = "delete_user"
+	CacheOpBulkAction    = "bulk_action"
+	CacheOpGetUsers      = "get_users"
+)
+
+// defaultCacheStrategies is the behavior when no strategy is configured for an operation
+var defaultCacheStrategies = map[string]CacheStrategy{
+	CacheOpRegister:      CacheStrategyWriteThrough,
+	CacheOpLogin:         CacheStrategyWriteThrough,
+	CacheOpGetProfile:    CacheStrategyReadThrough,
+	CacheOpGetUserByID:   CacheStrategyReadThrough,
+	CacheOpUpdateProfile: CacheStrategyWriteThrough,
+	CacheOpUpdateUser:    CacheStrategyInvalidate,
+	CacheOpDeleteUser:    CacheStrategyInvalidate,
+	CacheOpBulkAction:    CacheStrategyInvalidate,
+	CacheOpGetUsers:      CacheStrategyWriteBehind,
+}
+
+// allowedCacheStrategies lists the strategies that make sense for each operation.
+// Deletes and bulk actions return no user to write, so they can only drop entries.
+var allowedCacheStrategies = map[string][]CacheStrategy{
+	CacheOpRegister:      {CacheStrategyWriteThrough, CacheStrategyWriteBehind, CacheStrategyNone},
+	CacheOpLogin:         {CacheStrategyWriteThrough, CacheStrategyWriteBehind, CacheStrategyNone},
+	CacheOpGetProfile:    {CacheStrategyReadThrough, CacheStrategyNone},
+	CacheOpGetUserByID:   {CacheStrategyReadThrough, CacheStrategyNone},
+	CacheOpUpdateProfile: {CacheStrategyWriteThrough, CacheStrategyWriteBehind, CacheStrategyInvalidate, CacheStrategyNone},
+	CacheOpUpdateUser:    {CacheStrategyWriteThrough, CacheStrategyWriteBehind, CacheStrategyInvalidate, CacheStrategyNone},
+	CacheOpDeleteUser:    {CacheStrategyInvalidate, CacheStrategyWriteBehind, CacheStrategyNone},
+	CacheOpBulkAction:    {CacheStrategyInvalidate, CacheStrategyWriteBehind, CacheStrategyNone},
+	CacheOpGetUsers:      {CacheStrategyWriteThrough, CacheStrategyWriteBehind, CacheStrategyNone},
+}
+
+// ParseCacheStrategies validates per-operation strategy overrides from
+// configuration. Operations left out keep their default strategy.
+func ParseCacheStrategies(raw map[string]string) (map[string]CacheStrategy, error) {
+	strategies := make(map[string]CacheStrategy, len(raw))
+	for op, value := range raw {
+		allowed, ok := allowedCacheStrategies[op]
+		if !ok {
+			return nil, fmt.Errorf("unknown cache operation %q", op)
+		}
+		strategy := CacheStrategy(strings.ToLower(strings.TrimSpace(value)))
+		if !containsStrategy(allowed, strategy) {
+			return nil, fmt.Errorf("cache strategy %q not supported for %s (allowed: %s)", value, op, joinStrategies(allowed))
+		}
+		strategies[op] = strategy
+	}
+	return strategies, nil
+}
+
+func containsStrategy(strategies []CacheStrategy, strategy CacheStrategy) bool {
+	for _, s := range strategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+func joinStrategies(strategies []CacheStrategy) string {
+	names := make([]string, len(strategies))
+	for i, s := range strategies {
+		names[i] = string(s)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
 // cachedUserService wraps a UserService with caching capabilities
 type cachedUserService struct {
 	userService domain.UserService
 	cache       cache.Service
 	logger      *logger.Logger
 	cacheTTL    time.Duration
+	strategies  map[string]CacheStrategy
+	writeBehind *cache.WriteBehindQueue
+	listPages   *listPageCache // nil unless list pages are cached
+}
+
+// CachedUserServiceOption configures the cached user service
+type CachedUserServiceOption func(*cachedUserService)
+
+// WithCacheStrategies overrides the cache strategy of individual operations
+func WithCacheStrategies(strategies map[string]CacheStrategy) CachedUserServiceOption {
+	return func(s *cachedUserService) {
+		for op, strategy := range strategies {
+			s.strategies[op] = strategy
+		}
+	}
+}
+
+// WithWriteBehindQueue sends write-behind cache updates through the given
+// queue. Without it write-behind operations update the cache synchronously.
+func WithWriteBehindQueue(queue *cache.WriteBehindQueue) CachedUserServiceOption {
+	return func(s *cachedUserService) {
+		s.writeBehind = queue
+	}
 }
 
 // NewCachedUserService creates a new cached user service wrapper
@@ -22,34 +145,110 @@ func NewCachedUserService(
 	userService domain.UserService,
 	cacheService cache.Service,
 	cacheTTL time.Duration,
+	opts ...CachedUserServiceOption,
 ) domain.UserService {
-	return &cachedUserService{
+	s := &cachedUserService{
 		userService: userService,
 		cache:       cacheService,
 		logger:      logger.GetGlobal().ForComponent("cached-user-service"),
 		cacheTTL:    cacheTTL,
+		strategies:  make(map[string]CacheStrategy, len(defaultCacheStrategies)),
+	}
+	for op, strategy := range defaultCacheStrategies {
+		s.strategies[op] = strategy
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// markListsStale has cached list pages refreshed after a write
+func (s *cachedUserService) markListsStale(ctx context.Context, log *logger.Logger) {
+	if s.listPages != nil {
+		s.listPages.markStale(ctx, log)
 	}
 }
 
-// Register creates a new user account (no caching needed for write operations)
+// storeUser brings the cached copy of user in line with a write, following
+// the operation's strategy. Write-behind without a queue is applied synchronously.
+func (s *cachedUserService) storeUser(ctx context.Context, op string, user *domain.UserResponse, log *logger.Logger) {
+	switch s.strategies[op] {
+	case CacheStrategyWriteBehind:
+		if s.writeBehind != nil && op == CacheOpGetUsers {
+			// Listed users were only read, so the write may be dropped under load
+			s.writeBehind.FillUser(user.ID, user, s.cacheTTL)
+			return
+		}
+		if s.writeBehind != nil {
+			s.writeBehind.SetUser(user.ID, user, s.cacheTTL)
+			return
+		}
+		fallthrough
+	case CacheStrategyWriteThrough:
+		if cacheErr := cache.SetUserUnlessDeleted(ctx, s.cache, user.ID, user, s.cacheTTL); cacheErr != nil {
+			// Don't fail the operation if caching fails
+			log.Warn("Failed to cache user", "user_id", user.ID, "error", cacheErr)
+		} else {
+			log.Debug("Cached user", "user_id", user.ID)
+		}
+	case CacheStrategyInvalidate:
+		s.evictUser(ctx, op, user.ID, log)
+	}
+}
+
+// evictUser drops a user from the cache after a write, following the operation's strategy
+func (s *cachedUserService) evictUser(ctx context.Context, op, userID string, log *logger.Logger) {
+	switch s.strategies[op] {
+	case CacheStrategyWriteBehind:
+		if s.writeBehind != nil {
+			s.writeBehind.DeleteUser(userID)
+			return
+		}
+		fallthrough
+	case CacheStrategyWriteThrough, CacheStrategyInvalidate:
+		if cacheErr := s.cache.DeleteUser(ctx, userID); cacheErr != nil {
+			// Don't fail the operation if cache invalidation fails
+			log.Warn("Failed to invalidate user cache", "user_id", userID, "error", cacheErr)
+		} else {
+			log.Debug("Invalidated user cache", "user_id", userID)
+		}
+	}
+}
+
+// tombstoneUser marks a deleted user in the cache, following the operation's
+// strategy, so stale cache writes cannot serve it again
+func (s *cachedUserService) tombstoneUser(ctx context.Context, op, userID string, log *logger.Logger) {
+	switch s.strategies[op] {
+	case CacheStrategyWriteBehind:
+		if s.writeBehind != nil {
+			s.writeBehind.Tombstone(userID, s.cacheTTL)
+			return
+		}
+		fallthrough
+	case CacheStrategyWriteThrough, CacheStrategyInvalidate:
+		if cacheErr := cache.SetTombstone(ctx, s.cache, userID, s.cacheTTL); cacheErr != nil {
+			// Don't fail the operation if caching fails
+			log.Warn("Failed to tombstone deleted user", "user_id", userID, "error", cacheErr)
+		} else {
+			log.Debug("Tombstoned deleted user", "user_id", userID)
+		}
+	}
+}
+
+// Register creates a new user account and caches it
 func (s *cachedUserService) Register(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error) {
 	log := s.logger.ForService("user", "register").WithField("email", req.Email)
 
-	log.Debug("Registering new user (bypassing cache)")
+	log.Debug("Registering new user")
 
 	user, err := s.userService.Register(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache the newly created user
-	if cacheErr := s.cache.SetUser(ctx, user.ID, user, s.cacheTTL); cacheErr != nil {
-		log.Warn("Failed to cache newly registered user", "user_id", user.ID, "error", cacheErr)
-		// Don't fail the operation if caching fails
-	} else {
-		log.Debug("Cached newly registered user", "user_id", user.ID)
-	}
-
+	s.storeUser(ctx, CacheOpRegister, user, log)
+	s.markListsStale(ctx, log)
 	return user, nil
 }
 
@@ -65,21 +264,19 @@ func (s *cachedUserService) Login(ctx context.Context, req *domain.LoginRequest)
 	}
 
 	// Cache the user data after successful login
-	if cacheErr := s.cache.SetUser(ctx, user.ID, user, s.cacheTTL); cacheErr != nil {
-		log.Warn("Failed to cache user after login", "user_id", user.ID, "error", cacheErr)
-		// Don't fail the operation if caching fails
-	} else {
-		log.Debug("Cached user after login", "user_id", user.ID)
-	}
-
+	s.storeUser(ctx, CacheOpLogin, user, log)
 	return token, user, nil
 }
 
-// GetProfile retrieves a user profile (cache-enabled)
 // getUserWithCache is a helper function to get user data with caching logic
-func (s *cachedUserService) getUserWithCache(ctx context.Context, userID, operation string,
+func (s *cachedUserService) getUserWithCache(ctx context.Context, userID, operation, cacheOp string,
+	accessError func(status string) error,
 	serviceCall func(context.Context, string) (*domain.UserResponse, error)) (*domain.UserResponse, error) {
 
+	if s.strategies[cacheOp] != CacheStrategyReadThrough {
+		return serviceCall(ctx, userID)
+	}
+
 	log := s.logger.ForService("user", operation).WithField("user_id", userID)
 	log.Debug("Getting user with cache")
 
@@ -87,6 +284,10 @@ func (s *cachedUserService) getUserWithCache(ctx context.Context, userID, operat
 	user, err := s.cache.GetUser(ctx, userID)
 	if err == nil {
 		log.Debug("User cache hit")
+		// Cached entries get the same status checks as the underlying service
+		if accessErr := accessError(user.Status); accessErr != nil {
+			return nil, accessErr
+		}
 		return user, nil
 	}
 
@@ -103,8 +304,8 @@ func (s *cachedUserService) getUserWithCache(ctx context.Context, userID, operat
 		return nil, err
 	}
 
-	// Cache the result
-	if cacheErr := s.cache.SetUser(ctx, userID, user, s.cacheTTL); cacheErr != nil {
+	// Cache the result, unless the user was deleted since it was read
+	if cacheErr := cache.SetUserUnlessDeleted(ctx, s.cache, userID, user, s.cacheTTL); cacheErr != nil {
 		log.Warn("Failed to cache user", "user_id", userID, "error", cacheErr)
 		// Don't fail the operation if caching fails
 	} else {
@@ -114,11 +315,12 @@ func (s *cachedUserService) getUserWithCache(ctx context.Context, userID, operat
 	return user, nil
 }
 
+// GetProfile retrieves a user profile (cache-enabled)
 func (s *cachedUserService) GetProfile(ctx context.Context, userID string) (*domain.UserResponse, error) {
-	return s.getUserWithCache(ctx, userID, "get-profile", s.userService.GetProfile)
+	return s.getUserWithCache(ctx, userID, "get-profile", CacheOpGetProfile, selfAccessError, s.userService.GetProfile)
 }
 
-// UpdateProfile updates a user profile and invalidates cache
+// UpdateProfile updates a user profile and refreshes its cache entry
 func (s *cachedUserService) UpdateProfile(
 	ctx context.Context,
 	userID string,
@@ -134,25 +336,13 @@ func (s *cachedUserService) UpdateProfile(
 		return nil, err
 	}
 
-	// Invalidate cache for this user
-	if cacheErr := s.cache.DeleteUser(ctx, userID); cacheErr != nil {
-		log.Warn("Failed to invalidate user cache after update", "user_id", userID, "error", cacheErr)
-	} else {
-		log.Debug("Invalidated user cache after update", "user_id", userID)
-	}
-
-	// Cache the updated user
-	if cacheErr := s.cache.SetUser(ctx, userID, user, s.cacheTTL); cacheErr != nil {
-		log.Warn("Failed to cache updated user", "user_id", userID, "error", cacheErr)
-	} else {
-		log.Debug("Cached updated user", "user_id", userID)
-	}
-
+	s.storeUser(ctx, CacheOpUpdateProfile, user, log)
+	s.markListsStale(ctx, log)
 	return user, nil
 }
 
 // GetUsers retrieves a list of users (cache-enabled with list caching strategy)
-func (s *cachedUserService) GetUsers(ctx context.Context, limit, offset int) ([]*domain.UserResponse, int64, error) {
+func (s *cachedUserService) GetUsers(ctx context.Context, limit, offset int, fields ...string) ([]*domain.UserResponse, int64, error) {
 	log := s.logger.ForService("user", "get-users").WithFields(map[string]interface{}{
 		"limit":  limit,
 		"offset": offset,
@@ -160,36 +350,43 @@ func (s *cachedUserService) GetUsers(ctx context.Context, limit, offset int) ([]
 
 	log.Debug("Getting users list")
 
-	// For list operations, we could implement more complex caching strategies
-	// For now, we'll bypass cache for list operations and delegate to underlying service
-	// This avoids complex cache invalidation scenarios for list data
-
-	users, total, err := s.userService.GetUsers(ctx, limit, offset)
-	if err != nil {
-		return nil, 0, err
+	// Projected users are incomplete and must not end up in the cache
+	if len(fields) > 0 {
+		return s.userService.GetUsers(ctx, limit, offset, fields...)
 	}
 
-	// Opportunistically cache individual users from the list
-	go func() {
-		// Use background context to avoid cancellation
-		bgCtx := context.Background()
+	load := func(ctx context.Context) ([]*domain.UserResponse, int64, error) {
+		return s.userService.GetUsers(ctx, limit, offset)
+	}
+	// Opportunistically cache individual users from loaded pages
+	cacheUsers := func(ctx context.Context, users []*domain.UserResponse) {
 		for _, user := range users {
-			if cacheErr := s.cache.SetUser(bgCtx, user.ID, user, s.cacheTTL); cacheErr != nil {
-				log.Debug("Failed to cache user from list", "user_id", user.ID, "error", cacheErr)
-			}
+			s.storeUser(ctx, CacheOpGetUsers, user, log)
 		}
-		log.Debug("Opportunistically cached users from list", "count", len(users))
-	}()
+	}
 
+	if s.listPages != nil {
+		return s.listPages.get(ctx, limit, offset, load, cacheUsers)
+	}
+	users, total, err := load(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	cacheUsers(ctx, users)
 	return users, total, nil
 }
 
+// SearchUsers retrieves users matching a query (not cached; results depend on the criteria)
+func (s *cachedUserService) SearchUsers(ctx context.Context, query *domain.UserQuery) ([]*domain.UserResponse, int64, error) {
+	return s.userService.SearchUsers(ctx, query)
+}
+
 // GetUserByID retrieves a user by ID (cache-enabled)
 func (s *cachedUserService) GetUserByID(ctx context.Context, id string) (*domain.UserResponse, error) {
-	return s.getUserWithCache(ctx, id, "get-by-id", s.userService.GetUserByID)
+	return s.getUserWithCache(ctx, id, "get-by-id", CacheOpGetUserByID, adminAccessError, s.userService.GetUserByID)
 }
 
-// DeleteUser deletes a user and invalidates cache
+// DeleteUser deletes a user and leaves a tombstone in the cache
 func (s *cachedUserService) DeleteUser(ctx context.Context, id string) error {
 	log := s.logger.ForService("user", "delete").WithField("user_id", id)
 
@@ -201,14 +398,8 @@ func (s *cachedUserService) DeleteUser(ctx context.Context, id string) error {
 		return err
 	}
 
-	// Invalidate cache for this user
-	if cacheErr := s.cache.DeleteUser(ctx, id); cacheErr != nil {
-		log.Warn("Failed to invalidate user cache after deletion", "user_id", id, "error", cacheErr)
-		// Don't fail the operation if cache invalidation fails
-	} else {
-		log.Debug("Invalidated user cache after deletion", "user_id", id)
-	}
-
+	s.tombstoneUser(ctx, CacheOpDeleteUser, id, log)
+	s.markListsStale(ctx, log)
 	return nil
 }
 
@@ -230,6 +421,104 @@ func (s *cachedUserService) RefreshToken(ctx context.Context, userID string) (st
 	return token, nil
 }
 
+// ChangePassword changes the user's password. Cached profiles hold no
+// password, so their entries are left as they are.
+func (s *cachedUserService) ChangePassword(ctx context.Context, userID string, req *domain.ChangePasswordRequest) error {
+	return s.userService.ChangePassword(ctx, userID, req)
+}
+
+// UpdateUser applies an admin update and updates the user's cache entry
+func (s *cachedUserService) UpdateUser(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.UpdateUserRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.userService.UpdateUser(ctx, actorID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	log := s.logger.ForService("user", "update-user").WithField("user_id", id)
+	s.storeUser(ctx, CacheOpUpdateUser, user, log)
+	s.markListsStale(ctx, log)
+	return user, nil
+}
+
+// SetUserStatus changes an account's status and caches the result like an
+// admin update
+func (s *cachedUserService) SetUserStatus(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.SetUserStatusRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.userService.SetUserStatus(ctx, actorID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	log := s.logger.ForService("user", "set-status").WithField("user_id", id)
+	s.storeUser(ctx, CacheOpUpdateUser, user, log)
+	s.markListsStale(ctx, log)
+	return user, nil
+}
+
+// BulkUserAction applies a bulk admin action and invalidates cache for every affected user
+func (s *cachedUserService) BulkUserAction(
+	ctx context.Context,
+	actorID string,
+	req *domain.BulkUserActionRequest,
+) ([]domain.BulkItemResult, error) {
+	log := s.logger.ForService("user", "bulk-action").WithField("action", req.Action)
+
+	results, err := s.userService.BulkUserAction(ctx, actorID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		if req.Action == domain.BulkActionDelete {
+			s.tombstoneUser(ctx, CacheOpBulkAction, result.ID, log)
+		} else {
+			s.evictUser(ctx, CacheOpBulkAction, result.ID, log)
+		}
+	}
+	s.markListsStale(ctx, log)
+
+	return results, nil
+}
+
+// GetUserStats serves statistics from cache while fresh. When recomputing
+// fails, the last cached result is returned instead so reporting keeps working
+// through database hiccups.
+func (s *cachedUserService) GetUserStats(ctx context.Context) (*domain.UserStats, error) {
+	log := s.logger.ForService("user", "stats")
+
+	var cached domain.UserStats
+	hasCached := cache.GetVersioned(ctx, s.cache, userStatsCacheKey, &cached) == nil
+	if hasCached && time.Since(cached.ComputedAt) < userStatsFreshness {
+		log.Debug("User stats cache hit")
+		return &cached, nil
+	}
+
+	stats, err := s.userService.GetUserStats(ctx)
+	if err != nil {
+		if hasCached {
+			log.Warn("Serving stale user stats after computation failure", "computed_at", cached.ComputedAt, "error", err)
+			return &cached, nil
+		}
+		return nil, err
+	}
+
+	if cacheErr := cache.SetVersioned(ctx, s.cache, userStatsCacheKey, stats, s.cacheTTL); cacheErr != nil {
+		log.Warn("Failed to cache user stats", "error", cacheErr)
+	}
+
+	return stats, nil
+}
+
 // CacheHealthCheck checks the health of the cache service
 func (s *cachedUserService) CacheHealthCheck(ctx context.Context) error {
 	return s.cache.Ping(ctx)
@@ -261,11 +550,12 @@ func (s *cachedUserService) InvalidateAllUserCache(ctx context.Context) error {
 
 	log.Info("Invalidating all user cache")
 
-	// Delete all user cache entries
-	err := s.cache.DeleteByPattern(ctx, "user:*")
-	if err != nil {
-		log.Error("Failed to invalidate all user cache", "error", err)
-		return err
+	// Delete all user cache entries and list pages
+	for _, pattern := range []string{"user:*", "users:list:*"} {
+		if err := s.cache.DeleteByPattern(ctx, pattern); err != nil {
+			log.Error("Failed to invalidate all user cache", "pattern", pattern, "error", err)
+			return err
+		}
 	}
 
 	log.Info("All user cache invalidated successfully")
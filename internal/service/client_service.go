@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// clientIDPrefix starts every client ID
+	clientIDPrefix = "svc_"
+	// clientSecretPrefix starts every client secret so leaked secrets are easy to recognize
+	clientSecretPrefix = "dgs_"
+	// maxClientNameLength bounds client names
+	maxClientNameLength = 100
+	// clientTouchInterval bounds how often a client's last use is written
+	clientTouchInterval = time.Minute
+)
+
+// clientService implements domain.ClientService
+type clientService struct {
+	repo         domain.ClientRepository
+	tokens       domain.TokenService
+	auditService domain.AuditService
+	defaultRole  string
+	config       config.ClientCredentialsConfig
+	logger       *logger.Logger
+	now          func() time.Time
+
+	// Used by token exchanges
+	revocations domain.TokenRevocationService
+	roleScopes  map[string][]string
+}
+
+// ClientServiceOption configures optional machine client features
+type ClientServiceOption func(*clientService)
+
+// WithTokenExchange lets clients exchange users' tokens for delegated ones.
+// Subject tokens must not be revoked, and users may delegate the scopes of
+// their role as well as read, write and, unless they have the default role,
+// admin.
+func WithTokenExchange(revocations domain.TokenRevocationService, roleScopes map[string][]string) ClientServiceOption {
+	return func(s *clientService) {
+		s.revocations = revocations
+		s.roleScopes = roleScopes
+	}
+}
+
+// NewClientService creates a new machine client service. Tokens of clients
+// without the admin scope act with defaultRole.
+func NewClientService(
+	repo domain.ClientRepository,
+	tokens domain.TokenService,
+	auditService domain.AuditService,
+	defaultRole string,
+	cfg config.ClientCredentialsConfig,
+	opts ...ClientServiceOption,
+) domain.ClientService {
+	s := &clientService{
+		repo:         repo,
+		tokens:       tokens,
+		auditService: auditService,
+		defaultRole:  defaultRole,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("client-service"),
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateClient registers a new client and returns it with its secret
+func (s *clientService) CreateClient(ctx context.Context, actorID string, req *domain.CreateClientRequest) (*domain.CreatedClient, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > maxClientNameLength {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Client names are 1 to %d characters", maxClientNameLength),
+		}
+	}
+	scopes, err := normalizeScopes("Clients", req.Scopes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	rawSecret := clientSecretPrefix + secret
+	client := &domain.MachineClient{
+		ID:         clientIDPrefix + strings.ReplaceAll(uuid.New().String(), "-", "")[:16],
+		Name:       name,
+		SecretHash: hashAPIKey(rawSecret),
+		Scopes:     scopes,
+		CreatedBy:  actorID,
+		CreatedAt:  s.now().UTC(),
+	}
+	if err := s.repo.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionClientCreated, actorID, client)
+	s.logger.ForService("client", "create").Info("Machine client created",
+		"actor_id", actorID, "client_id", client.ID, "scopes", scopes)
+	return &domain.CreatedClient{MachineClient: client, ClientSecret: rawSecret}, nil
+}
+
+// ListClients returns every client, newest first, without their secrets
+func (s *clientService) ListClients(ctx context.Context) ([]*domain.MachineClient, error) {
+	return s.repo.List(ctx)
+}
+
+// DeleteClient deletes a client; it can no longer obtain tokens
+func (s *clientService) DeleteClient(ctx context.Context, actorID, clientID string) error {
+	client, err := s.repo.GetByID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, clientID); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionClientDeleted, actorID, client)
+	s.logger.ForService("client", "delete").Info("Machine client deleted", "actor_id", actorID, "client_id", clientID)
+	return nil
+}
+
+// IssueToken checks the client's credentials and issues a token with the
+// requested scopes
+func (s *clientService) IssueToken(ctx context.Context, clientID, clientSecret string, requested []string) (*domain.ClientToken, error) {
+	log := s.logger.ForService("client", "issue_token")
+
+	client, err := s.authenticate(ctx, clientID, clientSecret, log)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := client.Scopes
+	if len(requested) > 0 {
+		scopes = make([]string, 0, len(requested))
+		for _, scope := range requested {
+			if !client.HasScope(scope) {
+				return nil, domain.ErrInvalidScope
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+	role := s.defaultRole
+	for _, scope := range scopes {
+		if scope == domain.APIKeyScopeAdmin {
+			role = "admin"
+		}
+	}
+
+	token, err := s.tokens.GenerateClientToken(&domain.ClientGrant{
+		ClientID: client.ID,
+		Role:     role,
+		Scopes:   scopes,
+		TTL:      s.config.TokenTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.touch(ctx, client)
+	log.Info("Client token issued", "client_id", client.ID, "scopes", scopes)
+	return &domain.ClientToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.TokenTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// ExchangeToken checks the client's credentials and issues a token acting
+// for the subject token's user with the requested subset of its scopes. The
+// token expires after ExchangeTokenTTL, or with the subject token if sooner.
+func (s *clientService) ExchangeToken(ctx context.Context, clientID, clientSecret string, req *domain.TokenExchangeRequest) (*domain.ClientToken, error) {
+	log := s.logger.ForService("client", "exchange_token")
+
+	client, err := s.authenticate(ctx, clientID, clientSecret, log)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Scopes) == 0 {
+		return nil, domain.ErrScopeRequired
+	}
+
+	subject, err := s.tokens.ValidateToken(req.SubjectToken)
+	if errors.Is(err, domain.ErrKeysUnavailable) {
+		return nil, err
+	}
+	// Tokens of machine clients and trusted issuers have no user of this
+	// deployment to act for
+	if err != nil || subject.ClientID != "" || subject.Tenant != "" {
+		log.Warn("Token exchange with an unusable subject token", "client_id", client.ID)
+		return nil, domain.ErrInvalidSubjectToken
+	}
+	if s.revocations != nil {
+		revoked, err := s.revocations.IsRevoked(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			log.Warn("Token exchange with a revoked subject token", "client_id", client.ID, "user_id", subject.UserID)
+			return nil, domain.ErrInvalidSubjectToken
+		}
+	}
+
+	available := s.delegableScopes(subject)
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !containsString(available, scope) {
+			return nil, domain.ErrInvalidScope
+		}
+		if !containsString(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	role := s.defaultRole
+	if containsString(scopes, domain.APIKeyScopeAdmin) {
+		role = subject.Role
+	}
+
+	ttl := s.config.ExchangeTokenTTL
+	if remaining := time.Unix(subject.Exp, 0).Sub(s.now()); remaining < ttl {
+		ttl = remaining.Truncate(time.Second)
+	}
+	if ttl <= 0 {
+		return nil, domain.ErrInvalidSubjectToken
+	}
+
+	token, err := s.tokens.GenerateDelegatedToken(&domain.DelegationGrant{
+		Subject: subject,
+		Actor:   &domain.Actor{Subject: client.ID, Actor: subject.Actor},
+		Role:    role,
+		Scopes:  scopes,
+		TTL:     ttl,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.touch(ctx, client)
+	if s.auditService != nil {
+		_ = s.auditService.Record(ctx, &domain.AuditEvent{
+			Action:   domain.AuditActionTokenExchanged,
+			ActorID:  client.ID,
+			TargetID: subject.UserID,
+			Details:  map[string]interface{}{"scopes": scopes, "expires_in": int64(ttl.Seconds())},
+		})
+	}
+	log.Info("Token exchanged", "client_id", client.ID, "user_id", subject.UserID, "scopes", scopes, "ttl", ttl)
+	return &domain.ClientToken{
+		AccessToken:     token,
+		IssuedTokenType: domain.TokenTypeAccessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(ttl.Seconds()),
+		Scope:           strings.Join(scopes, " "),
+	}, nil
+}
+
+// Helper methods
+
+// authenticate returns the client with the given credentials, or
+// ErrInvalidClient
+func (s *clientService) authenticate(ctx context.Context, clientID, clientSecret string, log *logger.Logger) (*domain.MachineClient, error) {
+	client, err := s.repo.GetByID(ctx, clientID)
+	if errors.Is(err, domain.ErrClientNotFound) {
+		log.Warn("Token requested for an unknown client", "client_id", clientID)
+		return nil, domain.ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(clientSecret)), []byte(client.SecretHash)) != 1 {
+		log.Warn("Token requested with a wrong client secret", "client_id", clientID)
+		return nil, domain.ErrInvalidClient
+	}
+	return client, nil
+}
+
+// touch records that the client obtained a token, at most once per
+// clientTouchInterval
+func (s *clientService) touch(ctx context.Context, client *domain.MachineClient) {
+	now := s.now().UTC()
+	if client.LastUsedAt == nil || now.Sub(*client.LastUsedAt) >= clientTouchInterval {
+		// A missed last-use write is not worth failing the grant for
+		_ = s.repo.Touch(ctx, client.ID, now)
+	}
+}
+
+// delegableScopes returns the scopes a token exchange may grant for the
+// subject token. Delegated tokens can only be narrowed further.
+func (s *clientService) delegableScopes(subject *domain.TokenClaims) []string {
+	if subject.Actor != nil {
+		return subject.Scopes
+	}
+	scopes := []string{domain.APIKeyScopeRead, domain.APIKeyScopeWrite}
+	if subject.Role != s.defaultRole {
+		scopes = append(scopes, domain.APIKeyScopeAdmin)
+	}
+	scopes = append(scopes, subject.Scopes...)
+	return append(scopes, s.roleScopes[subject.Role]...)
+}
+
+// record writes an audit event for a client; a failed write is logged by
+// the audit service, not returned
+func (s *clientService) record(ctx context.Context, action, actorID string, client *domain.MachineClient) {
+	if s.auditService == nil {
+		return
+	}
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:   action,
+		ActorID:  actorID,
+		TargetID: client.ID,
+		Details:  map[string]interface{}{"name": client.Name, "scopes": client.Scopes},
+	})
+}
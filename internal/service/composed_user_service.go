@@ -0,0 +1,19 @@
+package service
+
+import "demo-go/internal/domain"
+
+// composedUserService serves queries and commands from separately decorated implementations
+type composedUserService struct {
+	domain.UserQueryService
+	domain.UserCommandService
+}
+
+// ComposeUserService combines a query side and a command side into one
+// UserService. Decorators that only concern reads or writes wrap one side
+// before composing, e.g. hooks wrap only the commands.
+func ComposeUserService(queries domain.UserQueryService, commands domain.UserCommandService) domain.UserService {
+	return &composedUserService{
+		UserQueryService:   queries,
+		UserCommandService: commands,
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/ratelimit"
+)
+
+// emailCheckService implements domain.EmailCheckService
+type emailCheckService struct {
+	userRepo domain.UserRepository
+	limiter  ratelimit.Limiter
+	config   config.EmailCheckConfig
+	logger   *logger.Logger
+}
+
+// NewEmailCheckService creates a new email availability service
+func NewEmailCheckService(
+	userRepo domain.UserRepository,
+	limiter ratelimit.Limiter,
+	cfg config.EmailCheckConfig,
+) domain.EmailCheckService {
+	return &emailCheckService{
+		userRepo: userRepo,
+		limiter:  limiter,
+		config:   cfg,
+		logger:   logger.GetGlobal().ForComponent("email-check-service"),
+	}
+}
+
+// IsEmailAvailable reports whether no account uses the email. Every outcome,
+// including validation and rate limit errors, is padded to the configured
+// minimum response time.
+func (s *emailCheckService) IsEmailAvailable(ctx context.Context, email, clientIP string) (bool, error) {
+	deadline := time.Now().Add(s.config.MinResponseTime)
+	defer waitUntil(ctx, deadline)
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
+		return false, &domain.Error{Code: "VALIDATION_FAILED", Message: "Invalid email format"}
+	}
+
+	result, err := s.limiter.Allow(ctx, "email-check:ip:"+clientIP, s.config.MaxAttempts, s.config.AttemptWindow)
+	if err != nil {
+		return false, err
+	}
+	if !result.Allowed {
+		s.logger.ForService("email-check", "check").Warn("Email check rate limit exceeded", "client_ip", clientIP)
+		return false, domain.ErrRateLimited
+	}
+	if s.config.GlobalMaxAttempts > 0 {
+		result, err = s.limiter.Allow(ctx, "email-check:global", s.config.GlobalMaxAttempts, s.config.AttemptWindow)
+		if err != nil {
+			return false, err
+		}
+		if !result.Allowed {
+			s.logger.ForService("email-check", "check").Warn("Global email check rate limit exceeded", "client_ip", clientIP)
+			return false, domain.ErrRateLimited
+		}
+	}
+
+	_, err = s.userRepo.GetByEmail(ctx, email)
+	if err == domain.ErrUserNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// waitUntil sleeps until deadline unless ctx is done first
+func waitUntil(ctx context.Context, deadline time.Time) {
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+)
+
+// EmailMessage is a plain-text email
+type EmailMessage struct {
+	// Kind names what the email is for, such as password_reset
+	Kind    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// EmailSender delivers transactional emails such as password reset links.
+// Implementations must honour ctx cancellation.
+type EmailSender interface {
+	Send(ctx context.Context, message *EmailMessage) error
+}
+
+// NewEmailSender creates the sender for cfg: SMTP when a host is configured,
+// otherwise a sender that only logs messages
+func NewEmailSender(cfg config.EmailConfig) EmailSender {
+	if cfg.SMTP.Host == "" {
+		return NewLogEmailSender()
+	}
+	return NewSMTPEmailSender(cfg)
+}
+
+// logEmailSender writes emails to the log instead of sending them
+type logEmailSender struct {
+	logger *logger.Logger
+}
+
+// NewLogEmailSender creates a sender for development that logs every email,
+// including any links and tokens in its body
+func NewLogEmailSender() EmailSender {
+	return &logEmailSender{logger: logger.GetGlobal().ForComponent("email")}
+}
+
+// Send logs the message
+func (s *logEmailSender) Send(ctx context.Context, message *EmailMessage) error {
+	s.logger.Info("Email not sent, no SMTP server configured", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
+
+// smtpEmailSender sends emails through an SMTP server, upgrading to TLS with
+// STARTTLS when the server offers it
+type smtpEmailSender struct {
+	cfg config.EmailConfig
+}
+
+// NewSMTPEmailSender creates a sender for the SMTP server in cfg
+func NewSMTPEmailSender(cfg config.EmailConfig) EmailSender {
+	return &smtpEmailSender{cfg: cfg}
+}
+
+// Send delivers the message, giving up when ctx is done
+func (s *smtpEmailSender) Send(ctx context.Context, message *EmailMessage) error {
+	addr := net.JoinHostPort(s.cfg.SMTP.Host, strconv.Itoa(s.cfg.SMTP.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.SMTP.Host)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return fmt.Errorf("start SMTP session: %w", err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.SMTP.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS: %w", err)
+		}
+	}
+	if s.cfg.SMTP.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.SMTP.Username, s.cfg.SMTP.Password, s.cfg.SMTP.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("SMTP RCPT TO: %w", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+	if _, err := writer.Write(formatEmail(s.cfg.From, message)); err != nil {
+		return fmt.Errorf("write email: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("write email: %w", err)
+	}
+	return client.Quit()
+}
+
+// formatEmail renders the message with the headers SMTP servers expect.
+// Header values are stripped of line breaks so they cannot inject headers.
+func formatEmail(from string, message *EmailMessage) []byte {
+	header := strings.NewReplacer("\r", "", "\n", "")
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", header.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", header.Replace(message.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", header.Replace(message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
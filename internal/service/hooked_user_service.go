@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/hooks"
+)
+
+// hookedUserService wraps the user commands and fires lifecycle hooks after
+// successful operations
+type hookedUserService struct {
+	domain.UserCommandService
+	hooks *hooks.Registry
+}
+
+// NewHookedUserService creates a user command decorator that runs registered
+// hooks. Queries fire no hooks; compose the result with ComposeUserService.
+func NewHookedUserService(commands domain.UserCommandService, registry *hooks.Registry) domain.UserCommandService {
+	return &hookedUserService{
+		UserCommandService: commands,
+		hooks:              registry,
+	}
+}
+
+// Register creates a new user account and runs OnUserRegistered hooks
+func (s *hookedUserService) Register(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error) {
+	user, err := s.UserCommandService.Register(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.UserRegistered(ctx, user)
+	return user, nil
+}
+
+// Login authenticates a user and runs OnLogin hooks, or OnLoginFailed hooks
+// when the credentials or account state are rejected
+func (s *hookedUserService) Login(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error) {
+	token, user, err := s.UserCommandService.Login(ctx, req)
+	if err != nil {
+		if err == domain.ErrInvalidCredentials || err == domain.ErrAccountSuspended || err == domain.ErrAccountBanned {
+			s.hooks.LoginFailed(ctx, req.Email, err)
+		}
+		return "", nil, err
+	}
+
+	s.hooks.LoggedIn(ctx, user)
+	return token, user, nil
+}
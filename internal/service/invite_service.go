@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// invitePrefix starts every invite code so it is not mistaken for a token
+const invitePrefix = "dgi_"
+
+// inviteService implements domain.InviteService
+type inviteService struct {
+	repo         domain.InviteRepository
+	users        domain.UserRepository
+	roles        domain.RoleCatalog
+	auditService domain.AuditService
+	config       config.InviteConfig
+	logger       *logger.Logger
+	now          func() time.Time
+}
+
+// NewInviteService creates a new invite service. Invites may only carry
+// roles in roles; a nil catalog accepts any role.
+func NewInviteService(
+	repo domain.InviteRepository,
+	users domain.UserRepository,
+	roles domain.RoleCatalog,
+	auditService domain.AuditService,
+	cfg config.InviteConfig,
+) domain.InviteService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 7 * 24 * time.Hour
+	}
+
+	return &inviteService{
+		repo:         repo,
+		users:        users,
+		roles:        roles,
+		auditService: auditService,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("invite-service"),
+		now:          time.Now,
+	}
+}
+
+// CreateInvite invites an email and returns the invite with its code
+func (s *inviteService) CreateInvite(ctx context.Context, actorID string, req *domain.CreateInviteRequest) (*domain.CreatedInvite, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Invalid email format"}
+	}
+	role := strings.TrimSpace(req.Role)
+	if role != "" && s.roles != nil && !s.roles.HasRole(role) {
+		return nil, domain.ErrUnknownRole
+	}
+	if _, err := s.users.GetByEmail(ctx, email); err == nil {
+		return nil, domain.ErrUserAlreadyExists
+	} else if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	code := invitePrefix + secret
+	now := s.now().UTC()
+	invite := &domain.Invite{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Role:      role,
+		Hash:      hashAPIKey(code),
+		CreatedBy: actorID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.TTL),
+	}
+	if err := s.repo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionInviteCreated, actorID, "", invite)
+	s.logger.ForService("invite", "create").Info("Invite created", "actor_id", actorID, "invite_id", invite.ID, "role", role)
+	return &domain.CreatedInvite{Invite: invite, Code: code}, nil
+}
+
+// ListInvites returns a page of invites, newest first, without their codes
+func (s *inviteService) ListInvites(ctx context.Context, limit, offset int) ([]*domain.Invite, int64, error) {
+	return s.repo.List(ctx, limit, offset)
+}
+
+// RevokeInvite deletes an invite
+func (s *inviteService) RevokeInvite(ctx context.Context, actorID, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionInviteRevoked, actorID, "", &domain.Invite{ID: id})
+	s.logger.ForService("invite", "revoke").Info("Invite revoked", "actor_id", actorID, "invite_id", id)
+	return nil
+}
+
+// CheckInvite looks the code up by its hash and checks it against the email
+func (s *inviteService) CheckInvite(ctx context.Context, code, email string) (*domain.Invite, error) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, invitePrefix) {
+		return nil, domain.ErrInvalidInvite
+	}
+	invite, err := s.repo.GetByHash(ctx, hashAPIKey(code))
+	if errors.Is(err, domain.ErrInviteNotFound) {
+		return nil, domain.ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invite.Usable(s.now().UTC()) || !strings.EqualFold(invite.Email, strings.TrimSpace(email)) {
+		return nil, domain.ErrInvalidInvite
+	}
+	return invite, nil
+}
+
+// AcceptInvite marks the invite used by the user, and records a role that
+// could not be assigned for admins to follow up
+func (s *inviteService) AcceptInvite(ctx context.Context, invite *domain.Invite, userID string, roleErr error) error {
+	if roleErr != nil {
+		s.record(ctx, domain.AuditActionInviteRoleFailed, invite.CreatedBy, userID, invite, "error", roleErr.Error())
+	}
+	if err := s.repo.Accept(ctx, invite.ID, userID, s.now().UTC()); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionInviteAccepted, userID, userID, invite)
+	return nil
+}
+
+// record writes an audit event for an invite, with extra details as
+// key-value pairs; a failed write is logged by the audit service, not returned
+func (s *inviteService) record(ctx context.Context, action, actorID, targetID string, invite *domain.Invite, extra ...string) {
+	if s.auditService == nil {
+		return
+	}
+	details := map[string]interface{}{"invite_id": invite.ID}
+	if invite.Email != "" {
+		details["email"] = invite.Email
+	}
+	if invite.Role != "" {
+		details["role"] = invite.Role
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		details[extra[i]] = extra[i+1]
+	}
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:   action,
+		ActorID:  actorID,
+		TargetID: targetID,
+		Details:  details,
+	})
+}
+
+// inviteUserCommands checks invite codes on registration
+type inviteUserCommands struct {
+	domain.UserCommandService
+	invites    domain.InviteService
+	inviteOnly bool
+	logger     *logger.Logger
+}
+
+// NewInviteUserCommands wraps commands so registrations with an invite code
+// are checked against it and given its role. With inviteOnly,
+// registrations without a code are refused.
+func NewInviteUserCommands(commands domain.UserCommandService, invites domain.InviteService, inviteOnly bool) domain.UserCommandService {
+	return &inviteUserCommands{
+		UserCommandService: commands,
+		invites:            invites,
+		inviteOnly:         inviteOnly,
+		logger:             logger.GetGlobal().ForComponent("invites"),
+	}
+}
+
+// Register checks the invite code, registers the user and accepts the invite
+func (s *inviteUserCommands) Register(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error) {
+	if strings.TrimSpace(req.InviteCode) == "" {
+		if s.inviteOnly {
+			return nil, domain.ErrInviteRequired
+		}
+		return s.UserCommandService.Register(ctx, req)
+	}
+
+	invite, err := s.invites.CheckInvite(ctx, req.InviteCode, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	registration := *req
+	if invite.Role != "" {
+		// The invite's role is assigned below, by its admin
+		registration.Role = ""
+	}
+	user, err := s.UserCommandService.Register(ctx, &registration)
+	if err != nil {
+		return nil, err
+	}
+
+	log := s.logger.WithField("invite_id", invite.ID).WithField("user_id", user.ID)
+	var roleErr error
+	if invite.Role != "" && invite.Role != user.Role {
+		updated, err := s.UserCommandService.UpdateUser(ctx, invite.CreatedBy, user.ID, &domain.UpdateUserRequest{Role: &invite.Role})
+		if err != nil {
+			// The account exists either way; the failure is audited so an
+			// admin can assign the role by hand
+			log.Error("Failed to assign the invite's role", "role", invite.Role, "error", err)
+			roleErr = err
+		} else {
+			user = updated
+		}
+	}
+	// Invites are bound to an email, which can only register once, so a
+	// failed write leaves no second use open
+	if err := s.invites.AcceptInvite(ctx, invite, user.ID, roleErr); err != nil {
+		log.Warn("Failed to mark invite accepted", "error", err)
+	}
+	return user, nil
+}
@@ -1,46 +1,215 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"demo-go/internal/config"
 	"demo-go/internal/domain"
+	"demo-go/internal/keys"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// keyLookupTimeout bounds fetching the key set from the key provider
+const keyLookupTimeout = 3 * time.Second
+
+// signingAlgorithms are the algorithms of the keys own tokens are signed with
+var signingAlgorithms = []string{domain.KeyAlgorithmHS256, domain.KeyAlgorithmRS256, domain.KeyAlgorithmEdDSA}
+
 // jwtTokenService implements domain.TokenService using JWT
 type jwtTokenService struct {
-	secretKey      []byte
+	keys           domain.KeyProvider
 	expirationTime time.Duration
 	issuer         string
+	audience       string
+	audiences      map[string]bool // accepted on validation; empty accepts any
+	namespace      string
+	parser         *jwt.Parser
+	trusted        map[string]*trustedIssuer // by iss claim
+}
+
+// trustedIssuer validates the tokens of another issuer
+type trustedIssuer struct {
+	tenant    string
+	secret    []byte
+	namespace string
+	roles     map[string]string // the issuer's roles mapped to ours
+	parser    *jwt.Parser
 }
 
-// NewJWTTokenService creates a new JWT token service
+// NewJWTTokenService creates a new JWT token service signing with
+// cfg.JWT.SecretKey. Tokens must carry the configured issuer and the audience
+// or one of the accepted audiences, and time-based claims are checked with
+// the configured clock skew tolerance.
 func NewJWTTokenService(cfg *config.Config) domain.TokenService {
+	return NewJWTTokenServiceWithKeys(cfg, keys.NewStaticProvider(keys.SecretKeySet(cfg.JWT.SecretKey)))
+}
+
+// NewJWTTokenServiceWithKeys creates a JWT token service whose keys come
+// from a key provider. Tokens are signed with the current key, HS256, RS256
+// or EdDSA by its type, and name it in their kid header; tokens without a kid
+// are checked against every key.
+// Tokens of the configured trusted issuers are validated with their own
+// secrets; call ValidateTrustedIssuers first, since invalid entries are skipped.
+func NewJWTTokenServiceWithKeys(cfg *config.Config, keyProvider domain.KeyProvider) domain.TokenService {
+	issuer := cfg.JWT.Issuer
+	if issuer == "" {
+		issuer = config.DefaultJWTIssuer
+	}
+
+	trusted := make(map[string]*trustedIssuer, len(cfg.JWT.TrustedIssuers))
+	for _, t := range cfg.JWT.TrustedIssuers {
+		if t.Issuer == "" || t.Secret == "" || t.Issuer == issuer {
+			continue
+		}
+		trusted[t.Issuer] = &trustedIssuer{
+			tenant:    t.Tenant,
+			secret:    []byte(t.Secret),
+			namespace: t.ClaimNamespace,
+			roles:     t.Roles,
+			parser:    newJWTParser(t.Issuer, t.Audience, cfg.JWT.ClockSkew, domain.KeyAlgorithmHS256),
+		}
+	}
+
+	audiences := make(map[string]bool, len(cfg.JWT.AcceptedAudiences)+1)
+	for _, audience := range append([]string{cfg.JWT.Audience}, cfg.JWT.AcceptedAudiences...) {
+		if audience != "" {
+			audiences[audience] = true
+		}
+	}
+
 	return &jwtTokenService{
-		secretKey:      []byte(cfg.JWT.SecretKey),
+		keys:           keyProvider,
 		expirationTime: cfg.JWT.Expiration,
-		issuer:         "demo-go-api",
+		issuer:         issuer,
+		audience:       cfg.JWT.Audience,
+		audiences:      audiences,
+		namespace:      cfg.JWT.ClaimNamespace,
+		parser:         newJWTParser(issuer, "", cfg.JWT.ClockSkew, signingAlgorithms...),
+		trusted:        trusted,
+	}
+}
+
+// ValidateTrustedIssuers reports trusted issuers the token service cannot use
+func ValidateTrustedIssuers(cfg config.JWTConfig) error {
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = config.DefaultJWTIssuer
+	}
+	seen := map[string]string{issuer: "this deployment"}
+	for _, t := range cfg.TrustedIssuers {
+		switch {
+		case t.Issuer == "":
+			return fmt.Errorf("trusted issuer %q has no issuer", t.Tenant)
+		case t.Secret == "":
+			return fmt.Errorf("trusted issuer %q has no secret", t.Tenant)
+		case seen[t.Issuer] != "":
+			return fmt.Errorf("trusted issuer %q uses the issuer of %s", t.Tenant, seen[t.Issuer])
+		}
+		seen[t.Issuer] = fmt.Sprintf("%q", t.Tenant)
+	}
+	return nil
+}
+
+// newJWTParser creates a parser for tokens of the given issuer and, when set,
+// audience, signed with one of the given algorithms
+func newJWTParser(issuer, audience string, leeway time.Duration, algorithms ...string) *jwt.Parser {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(algorithms),
+		jwt.WithIssuer(issuer),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
 	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	return jwt.NewParser(options...)
 }
 
 // GenerateToken generates a JWT token for the given user
 func (s *jwtTokenService) GenerateToken(user *domain.User) (string, error) {
+	return s.sign(jwt.MapClaims{
+		s.namespace + "user_id": user.ID,
+		s.namespace + "email":   user.Email,
+		s.namespace + "role":    user.Role,
+	}, s.expirationTime)
+}
+
+// GenerateClientToken generates a JWT token for a machine client. Its
+// subject is the client, and its scopes are in the space-separated "scope"
+// claim of RFC 9068.
+func (s *jwtTokenService) GenerateClientToken(grant *domain.ClientGrant) (string, error) {
+	return s.sign(jwt.MapClaims{
+		s.namespace + "user_id": grant.ClientID,
+		s.namespace + "email":   "",
+		s.namespace + "role":    grant.Role,
+		"client_id":             grant.ClientID,
+		"scope":                 strings.Join(grant.Scopes, " "),
+	}, grant.TTL)
+}
+
+// GenerateDelegatedToken generates a JWT token acting for the grant's
+// subject user. Its scopes are in the "scope" claim and the acting client in
+// the "act" claim of RFC 8693.
+func (s *jwtTokenService) GenerateDelegatedToken(grant *domain.DelegationGrant) (string, error) {
+	return s.sign(jwt.MapClaims{
+		s.namespace + "user_id": grant.Subject.UserID,
+		s.namespace + "email":   grant.Subject.Email,
+		s.namespace + "role":    grant.Role,
+		"scope":                 strings.Join(grant.Scopes, " "),
+		"act":                   actorClaim(grant.Actor),
+	}, grant.TTL)
+}
+
+// actorClaim returns the act claim of an actor, nesting the actors it acted for
+func actorClaim(actor *domain.Actor) map[string]interface{} {
+	claim := map[string]interface{}{"sub": actor.Subject}
+	if actor.Actor != nil {
+		claim["act"] = actorClaim(actor.Actor)
+	}
+	return claim
+}
+
+// actorOf parses an act claim; it is nil when the claim is missing or malformed
+func actorOf(claim interface{}) *domain.Actor {
+	fields, ok := claim.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	subject, _ := fields["sub"].(string)
+	if subject == "" {
+		return nil
+	}
+	return &domain.Actor{Subject: subject, Actor: actorOf(fields["act"])}
+}
+
+// sign adds the registered claims to claims and signs them with the current key
+func (s *jwtTokenService) sign(claims jwt.MapClaims, ttl time.Duration) (string, error) {
 	now := time.Now()
-	expirationTime := now.Add(s.expirationTime)
+	claims["jti"] = uuid.New().String()
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["iat"] = now.Unix()
+	claims["iss"] = s.issuer
+	if s.audience != "" {
+		claims["aud"] = s.audience
+	}
 
-	claims := &jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    user.Role,
-		"exp":     expirationTime.Unix(),
-		"iat":     now.Unix(),
-		"iss":     s.issuer,
+	set, err := s.keySet()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.secretKey)
+	method, signingKey := signingMethodOf(set.Current)
+	token := jwt.NewWithClaims(method, claims)
+	if set.Current.ID != "" {
+		token.Header["kid"] = set.Current.ID
+	}
+	tokenString, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", err
 	}
@@ -48,16 +217,30 @@ func (s *jwtTokenService) GenerateToken(user *domain.User) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates a JWT token and returns the claims. Tokens of a
+// trusted issuer are checked with that issuer's secret and claim namespace;
+// their user ID is prefixed with the tenant and their role mapped to ours.
 func (s *jwtTokenService) ValidateToken(tokenString string) (*domain.TokenClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Make sure token's signing method is what we expect
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, domain.ErrInvalidToken
-		}
-		return s.secretKey, nil
-	})
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
 
+	var token *jwt.Token
+	var tenantRoles map[string]string
+	namespace, tenant := s.namespace, ""
+	if issuer, ok := s.trustedIssuerOf(unverified); ok {
+		namespace, tenant, tenantRoles = issuer.namespace, issuer.tenant, issuer.roles
+		token, err = issuer.parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return issuer.secret, nil
+		})
+	} else {
+		set, keyErr := s.keySet()
+		if keyErr != nil {
+			return nil, keyErr
+		}
+		token, err = s.parse(tokenString, unverified, set)
+	}
 	if err != nil {
 		return nil, domain.ErrInvalidToken
 	}
@@ -70,22 +253,33 @@ func (s *jwtTokenService) ValidateToken(tokenString string) (*domain.TokenClaims
 	if !ok {
 		return nil, domain.ErrInvalidToken
 	}
+	if tenant == "" && !s.acceptsAudience(claims) {
+		return nil, domain.ErrInvalidToken
+	}
 
 	// Extract claims
-	userID, ok := claims["user_id"].(string)
+	userID, ok := claims[namespace+"user_id"].(string)
 	if !ok {
 		return nil, domain.ErrInvalidToken
 	}
 
-	email, ok := claims["email"].(string)
+	email, ok := claims[namespace+"email"].(string)
 	if !ok {
 		return nil, domain.ErrInvalidToken
 	}
 
-	role, ok := claims["role"].(string)
+	role, ok := claims[namespace+"role"].(string)
 	if !ok {
 		return nil, domain.ErrInvalidToken
 	}
+	// Users of other issuers are never users of this deployment, and only
+	// hold the roles mapped for their issuer
+	if tenant != "" {
+		if role, ok = tenantRoles[role]; !ok {
+			return nil, domain.ErrInvalidToken
+		}
+		userID = tenant + ":" + userID
+	}
 
 	exp, ok := claims["exp"].(float64)
 	if !ok {
@@ -97,13 +291,28 @@ func (s *jwtTokenService) ValidateToken(tokenString string) (*domain.TokenClaims
 		return nil, domain.ErrInvalidToken
 	}
 
-	return &domain.TokenClaims{
+	// Tokens issued before jti was added have none and can only be revoked per user
+	jti, _ := claims["jti"].(string)
+
+	tokenClaims := &domain.TokenClaims{
+		ID:     jti,
 		UserID: userID,
 		Email:  email,
 		Role:   role,
 		Exp:    int64(exp),
 		Iat:    int64(iat),
-	}, nil
+		Tenant: tenant,
+	}
+	// Only this deployment issues tokens to machine clients, scoped and
+	// delegated tokens
+	if tenant == "" {
+		tokenClaims.ClientID, _ = claims["client_id"].(string)
+		if scope, _ := claims["scope"].(string); scope != "" {
+			tokenClaims.Scopes = domain.ParseScope(scope)
+		}
+		tokenClaims.Actor = actorOf(claims["act"])
+	}
+	return tokenClaims, nil
 }
 
 // ExtractUserIDFromToken extracts user ID from a JWT token
@@ -115,3 +324,114 @@ func (s *jwtTokenService) ExtractUserIDFromToken(tokenString string) (string, er
 
 	return claims.UserID, nil
 }
+
+// keySet returns the provider's current key set
+func (s *jwtTokenService) keySet() (*domain.KeySet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyLookupTimeout)
+	defer cancel()
+	return s.keys.KeySet(ctx)
+}
+
+// PublicKeys returns the JSON Web Keys of the asymmetric keys in the key set,
+// current and previous, so other services can verify tokens without a secret
+func (s *jwtTokenService) PublicKeys(ctx context.Context) ([]domain.JSONWebKey, error) {
+	set, err := s.keys.KeySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	published := make([]domain.JSONWebKey, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		if jwk, ok := keys.PublicJWK(key); ok {
+			published = append(published, jwk)
+		}
+	}
+	// Current key first, the rest by ID, so the document is stable
+	sort.Slice(published, func(i, j int) bool {
+		if (published[i].Kid == set.Current.ID) != (published[j].Kid == set.Current.ID) {
+			return published[i].Kid == set.Current.ID
+		}
+		return published[i].Kid < published[j].Kid
+	})
+	return published, nil
+}
+
+// signingMethodOf returns the signing method of a key and what it signs with
+func signingMethodOf(key domain.SigningKey) (jwt.SigningMethod, interface{}) {
+	switch key.Alg() {
+	case domain.KeyAlgorithmRS256:
+		return jwt.SigningMethodRS256, key.PrivateKey
+	case domain.KeyAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, key.PrivateKey
+	default:
+		return jwt.SigningMethodHS256, key.Secret
+	}
+}
+
+// acceptsAudience reports whether the token is meant for one of the accepted
+// audiences; without any, every token is. It runs after parsing, since the
+// parser checks a single audience only.
+func (s *jwtTokenService) acceptsAudience(claims jwt.MapClaims) bool {
+	if len(s.audiences) == 0 {
+		return true
+	}
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	for _, audience := range audiences {
+		if s.audiences[audience] {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedIssuerOf returns the trusted issuer named by the token's iss claim
+func (s *jwtTokenService) trustedIssuerOf(unverified *jwt.Token) (*trustedIssuer, bool) {
+	claims, ok := unverified.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+	iss, _ := claims["iss"].(string)
+	issuer, ok := s.trusted[iss]
+	return issuer, ok
+}
+
+// parse verifies the token with the key named by its kid header, or with
+// each key in turn when it has none, e.g. tokens issued before rotation
+func (s *jwtTokenService) parse(tokenString string, unverified *jwt.Token, set *domain.KeySet) (*jwt.Token, error) {
+	candidates := []domain.SigningKey{set.Current}
+	if kid, ok := unverified.Header["kid"].(string); ok {
+		key, found := set.Key(kid)
+		if !found {
+			return nil, domain.ErrInvalidToken
+		}
+		candidates = []domain.SigningKey{key}
+	} else {
+		for _, key := range set.Keys {
+			if key.ID != set.Current.ID {
+				candidates = append(candidates, key)
+			}
+		}
+	}
+
+	var lastErr error
+	for _, key := range candidates {
+		token, err := s.parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			// Make sure token's signing method is the key's, so a public key
+			// is never used as an HMAC secret
+			if token.Method.Alg() != key.Alg() {
+				return nil, domain.ErrInvalidToken
+			}
+			if key.PublicKey != nil {
+				return key.PublicKey, nil
+			}
+			return key.Secret, nil
+		})
+		if err == nil {
+			return token, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
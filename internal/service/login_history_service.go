@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+
+	"github.com/google/uuid"
+)
+
+// maxLoginUserAgentLength bounds the user agents stored with login attempts
+const maxLoginUserAgentLength = 512
+
+// loginHistoryService implements domain.LoginHistoryService
+type loginHistoryService struct {
+	repo   domain.LoginHistoryRepository
+	users  domain.UserRepository
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// NewLoginHistoryService creates a login history service. users resolves
+// the accounts whose history is listed.
+func NewLoginHistoryService(repo domain.LoginHistoryRepository, users domain.UserRepository) domain.LoginHistoryService {
+	return &loginHistoryService{
+		repo:   repo,
+		users:  users,
+		logger: logger.GetGlobal().ForComponent("login-history"),
+		now:    time.Now,
+	}
+}
+
+// Record stores an attempt with the client of the request context
+func (s *loginHistoryService) Record(ctx context.Context, attempt *domain.LoginAttempt) error {
+	attempt.ID = uuid.New().String()
+	attempt.CreatedAt = s.now()
+	attempt.IPAddress, _ = middleware.GetClientIPFromContext(ctx)
+	attempt.UserAgent, _ = middleware.GetUserAgentFromContext(ctx)
+	if len(attempt.UserAgent) > maxLoginUserAgentLength {
+		attempt.UserAgent = attempt.UserAgent[:maxLoginUserAgentLength]
+	}
+	attempt.Device = describeDevice(attempt.UserAgent)
+	attempt.Country, _ = middleware.GetClientCountryFromContext(ctx)
+	return s.repo.Create(ctx, attempt)
+}
+
+// ListLoginHistory returns a page of the user's attempts, newest first.
+// Unknown users fail with ErrUserNotFound.
+func (s *loginHistoryService) ListLoginHistory(ctx context.Context, userID string, limit, offset int) ([]*domain.LoginAttempt, int64, error) {
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		return nil, 0, err
+	}
+
+	attempts, err := s.repo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return attempts, total, nil
+}
+
+// recordLogin stores an attempt, logging rather than returning failures so that
+// logins never fail because the history is unavailable
+func recordLogin(ctx context.Context, history domain.LoginHistoryService, log *logger.Logger, attempt *domain.LoginAttempt) {
+	if err := history.Record(ctx, attempt); err != nil {
+		log.Warn("Failed to record login attempt", "user_id", attempt.UserID, "method", attempt.Method, "error", err)
+	}
+}
+
+// loginFailureReason reports the domain code of a failed login, or INTERNAL_ERROR
+func loginFailureReason(err error) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return "INTERNAL_ERROR"
+}
+
+// loginHistoryUserCommands records password logins in the login history
+type loginHistoryUserCommands struct {
+	domain.UserCommandService
+	history domain.LoginHistoryService
+	users   domain.UserRepository
+	logger  *logger.Logger
+}
+
+// NewLoginHistoryUserCommands wraps commands so every password login is
+// recorded. Failed logins are recorded for the account of the email; those
+// for unknown emails have no history to go to and are not recorded.
+func NewLoginHistoryUserCommands(
+	commands domain.UserCommandService,
+	history domain.LoginHistoryService,
+	users domain.UserRepository,
+) domain.UserCommandService {
+	return &loginHistoryUserCommands{
+		UserCommandService: commands,
+		history:            history,
+		users:              users,
+		logger:             logger.GetGlobal().ForComponent("login-history"),
+	}
+}
+
+// Login authenticates the user and records the attempt
+func (s *loginHistoryUserCommands) Login(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error) {
+	token, user, err := s.UserCommandService.Login(ctx, req)
+	if err == nil {
+		recordLogin(ctx, s.history, s.logger, &domain.LoginAttempt{UserID: user.ID, Method: domain.LoginMethodPassword, Outcome: domain.LoginOutcomeSuccess})
+		return token, user, nil
+	}
+
+	if account, lookupErr := s.users.GetByEmail(ctx, req.Email); lookupErr == nil {
+		recordLogin(ctx, s.history, s.logger, &domain.LoginAttempt{
+			UserID:  account.ID,
+			Method:  domain.LoginMethodPassword,
+			Outcome: domain.LoginOutcomeFailure,
+			Reason:  loginFailureReason(err),
+		})
+	}
+	return "", nil, err
+}
+
+// loginHistoryWebAuthnService records passkey logins in the login history
+type loginHistoryWebAuthnService struct {
+	domain.WebAuthnService
+	history domain.LoginHistoryService
+	logger  *logger.Logger
+}
+
+// NewLoginHistoryWebAuthnService wraps passkeys so successful passkey logins
+// are recorded. A failed assertion does not reliably name an account, so
+// failures are not.
+func NewLoginHistoryWebAuthnService(passkeys domain.WebAuthnService, history domain.LoginHistoryService) domain.WebAuthnService {
+	return &loginHistoryWebAuthnService{
+		WebAuthnService: passkeys,
+		history:         history,
+		logger:          logger.GetGlobal().ForComponent("login-history"),
+	}
+}
+
+// FinishLogin logs the user in with a passkey and records the login
+func (s *loginHistoryWebAuthnService) FinishLogin(ctx context.Context, req *domain.FinishPasskeyLoginRequest) (string, *domain.UserResponse, error) {
+	token, user, err := s.WebAuthnService.FinishLogin(ctx, req)
+	if err == nil {
+		recordLogin(ctx, s.history, s.logger, &domain.LoginAttempt{UserID: user.ID, Method: domain.LoginMethodPasskey, Outcome: domain.LoginOutcomeSuccess})
+	}
+	return token, user, err
+}
+
+// loginHistoryOIDCService records single sign-on logins in the login history
+type loginHistoryOIDCService struct {
+	domain.OIDCService
+	history domain.LoginHistoryService
+	logger  *logger.Logger
+}
+
+// NewLoginHistoryOIDCService wraps single sign-on so successful logins are
+// recorded; failures happen at the identity provider or before the account
+// is known
+func NewLoginHistoryOIDCService(sso domain.OIDCService, history domain.LoginHistoryService) domain.OIDCService {
+	return &loginHistoryOIDCService{
+		OIDCService: sso,
+		history:     history,
+		logger:      logger.GetGlobal().ForComponent("login-history"),
+	}
+}
+
+// FinishLogin logs the user in from the provider's callback and records the login
+func (s *loginHistoryOIDCService) FinishLogin(ctx context.Context, code, state string) (string, *domain.UserResponse, error) {
+	token, user, err := s.OIDCService.FinishLogin(ctx, code, state)
+	if err == nil {
+		recordLogin(ctx, s.history, s.logger, &domain.LoginAttempt{UserID: user.ID, Method: domain.LoginMethodOIDC, Outcome: domain.LoginOutcomeSuccess})
+	}
+	return token, user, err
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/ratelimit"
+)
+
+// loginThrottle counts failed logins per email and per client IP. It is
+// shared by the login decorator, which records failures, and the lockout
+// status service. Unknown emails are counted like existing ones so the state
+// reveals no accounts.
+type loginThrottle struct {
+	limiter ratelimit.Limiter
+	config  config.LoginThrottleConfig
+}
+
+func (t loginThrottle) key(email string) string {
+	return "login-throttle:email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func (t loginThrottle) ipKey(clientIP string) string {
+	return "login-throttle:ip:" + clientIP
+}
+
+// limitsIP reports whether failures from clientIP are limited
+func (t loginThrottle) limitsIP(clientIP string) bool {
+	return t.config.IPMaxFailures > 0 && clientIP != ""
+}
+
+// status reports the throttle state of email without counting an attempt
+func (t loginThrottle) status(ctx context.Context, email string) (domain.LoginLockoutStatus, error) {
+	result, err := t.limiter.Peek(ctx, t.key(email), t.config.MaxFailures, t.config.Window)
+	if err != nil {
+		return domain.LoginLockoutStatus{}, err
+	}
+	return lockoutStatus(result), nil
+}
+
+// loginStatus reports the throttle state of a login of email from clientIP:
+// locked when either is, with the fewer remaining attempts of the two
+func (t loginThrottle) loginStatus(ctx context.Context, email, clientIP string) (domain.LoginLockoutStatus, error) {
+	status, err := t.status(ctx, email)
+	if err != nil || !t.limitsIP(clientIP) {
+		return status, err
+	}
+
+	result, err := t.limiter.Peek(ctx, t.ipKey(clientIP), t.config.IPMaxFailures, t.config.IPWindow)
+	if err != nil {
+		return domain.LoginLockoutStatus{}, err
+	}
+	ipStatus := lockoutStatus(result)
+
+	if ipStatus.RemainingAttempts < status.RemainingAttempts {
+		status.RemainingAttempts = ipStatus.RemainingAttempts
+	}
+	if ipStatus.Locked {
+		status.Locked = true
+		if ipStatus.RetryAfterSeconds > status.RetryAfterSeconds {
+			status.RetryAfterSeconds = ipStatus.RetryAfterSeconds
+		}
+	}
+	return status, nil
+}
+
+// recordFailure counts a failed login against email and clientIP
+func (t loginThrottle) recordFailure(ctx context.Context, email, clientIP string) error {
+	if _, err := t.limiter.Allow(ctx, t.key(email), t.config.MaxFailures, t.config.Window); err != nil {
+		return err
+	}
+	if t.limitsIP(clientIP) {
+		if _, err := t.limiter.Allow(ctx, t.ipKey(clientIP), t.config.IPMaxFailures, t.config.IPWindow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockoutStatus converts a limiter result into a lockout status
+func lockoutStatus(result ratelimit.Result) domain.LoginLockoutStatus {
+	status := domain.LoginLockoutStatus{Locked: !result.Allowed, RemainingAttempts: result.Remaining}
+	if status.Locked {
+		status.RetryAfterSeconds = int(math.Ceil(result.RetryAfter.Seconds()))
+	}
+	return status
+}
+
+// throttledUserCommands refuses logins for emails or client IPs with too
+// many recent failures and attaches the throttle state to failed logins
+type throttledUserCommands struct {
+	domain.UserCommandService
+	throttle loginThrottle
+	logger   *logger.Logger
+}
+
+// NewThrottledUserCommands wraps commands so an email is locked out of
+// logging in after cfg.MaxFailures failed logins within cfg.Window, and a
+// client IP after cfg.IPMaxFailures within cfg.IPWindow. The client IP is
+// taken from the request context. Failed and refused logins return a
+// *domain.LoginAttemptError. The throttle fails open when the limiter is
+// unavailable.
+func NewThrottledUserCommands(
+	commands domain.UserCommandService,
+	limiter ratelimit.Limiter,
+	cfg config.LoginThrottleConfig,
+) domain.UserCommandService {
+	return &throttledUserCommands{
+		UserCommandService: commands,
+		throttle:           loginThrottle{limiter: limiter, config: cfg},
+		logger:             logger.GetGlobal().ForComponent("login-throttle"),
+	}
+}
+
+// Login authenticates the user unless their email or client IP is locked out
+func (s *throttledUserCommands) Login(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error) {
+	clientIP, _ := middleware.GetClientIPFromContext(ctx)
+	log := s.logger.ForService("login-throttle", "login").WithField("email", req.Email).WithField("client_ip", clientIP)
+
+	status, err := s.throttle.loginStatus(ctx, req.Email, clientIP)
+	if err != nil {
+		log.Warn("Login throttle unavailable", "error", err)
+		return s.UserCommandService.Login(ctx, req)
+	}
+	if status.Locked {
+		log.Warn("Login refused for locked out email or client IP", "retry_after_seconds", status.RetryAfterSeconds)
+		return "", nil, &domain.LoginAttemptError{Err: domain.ErrLoginLocked, Status: status}
+	}
+
+	token, user, err := s.UserCommandService.Login(ctx, req)
+	if err == nil {
+		// Only the email's count is reset: one valid account must not let a
+		// client IP keep guessing the passwords of others
+		if resetErr := s.throttle.limiter.Reset(ctx, s.throttle.key(req.Email)); resetErr != nil {
+			log.Warn("Failed to reset login throttle", "error", resetErr)
+		}
+		return token, user, nil
+	}
+	if !errors.Is(err, domain.ErrInvalidCredentials) {
+		return "", nil, err
+	}
+
+	if limitErr := s.throttle.recordFailure(ctx, req.Email, clientIP); limitErr != nil {
+		log.Warn("Failed to record failed login", "error", limitErr)
+		return "", nil, err
+	}
+	status, statusErr := s.throttle.loginStatus(ctx, req.Email, clientIP)
+	if statusErr != nil {
+		log.Warn("Login throttle unavailable", "error", statusErr)
+		return "", nil, err
+	}
+	if status.Locked {
+		log.Warn("Login locked out after repeated failures", "retry_after_seconds", status.RetryAfterSeconds)
+	}
+	return "", nil, &domain.LoginAttemptError{Err: err, Status: status}
+}
+
+// loginThrottleService implements domain.LoginThrottleService
+type loginThrottleService struct {
+	throttle loginThrottle
+	logger   *logger.Logger
+}
+
+// NewLoginThrottleService creates the lockout status service. It must share
+// limiter with NewThrottledUserCommands to see the recorded failures.
+func NewLoginThrottleService(limiter ratelimit.Limiter, cfg config.LoginThrottleConfig) domain.LoginThrottleService {
+	return &loginThrottleService{
+		throttle: loginThrottle{limiter: limiter, config: cfg},
+		logger:   logger.GetGlobal().ForComponent("login-throttle-service"),
+	}
+}
+
+// LockoutStatus reports whether email is locked out and how many attempts remain
+func (s *loginThrottleService) LockoutStatus(ctx context.Context, email, clientIP string) (*domain.LoginLockoutStatus, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Invalid email format"}
+	}
+
+	result, err := s.throttle.limiter.Allow(
+		ctx,
+		"login-lockout-status:ip:"+clientIP,
+		s.throttle.config.StatusMaxChecks,
+		s.throttle.config.StatusWindow,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Allowed {
+		s.logger.ForService("login-throttle", "lockout-status").Warn("Lockout status rate limit exceeded", "client_ip", clientIP)
+		return nil, domain.ErrRateLimited
+	}
+
+	status, err := s.throttle.status(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/memo"
+)
+
+// memoizedUserQueries serves repeated user lookups within one request from the request memo
+type memoizedUserQueries struct {
+	domain.UserQueryService
+}
+
+// NewMemoizedUserQueries creates a query decorator that memoizes single-user
+// lookups per request. Requests without a memo (see middleware.RequestMemo)
+// pass straight through. Pair it with NewMemoResettingUserCommands so writes
+// do not leave stale entries behind.
+func NewMemoizedUserQueries(queries domain.UserQueryService) domain.UserQueryService {
+	return &memoizedUserQueries{UserQueryService: queries}
+}
+
+// GetProfile returns the user's own profile, memoized per request
+func (s *memoizedUserQueries) GetProfile(ctx context.Context, userID string) (*domain.UserResponse, error) {
+	return memoizeUser(ctx, "profile:"+userID, func() (*domain.UserResponse, error) {
+		return s.UserQueryService.GetProfile(ctx, userID)
+	})
+}
+
+// GetUserByID returns a user by ID, memoized per request
+func (s *memoizedUserQueries) GetUserByID(ctx context.Context, id string) (*domain.UserResponse, error) {
+	return memoizeUser(ctx, "user:"+id, func() (*domain.UserResponse, error) {
+		return s.UserQueryService.GetUserByID(ctx, id)
+	})
+}
+
+// memoizeUser returns a copy of the memoized user or fetches and stores it.
+// Errors are not memoized. Profile and admin lookups use separate keys since
+// they apply different access rules.
+func memoizeUser(ctx context.Context, key string, fetch func() (*domain.UserResponse, error)) (*domain.UserResponse, error) {
+	m := memo.FromContext(ctx)
+	if value, ok := m.Get(key); ok {
+		user := *value.(*domain.UserResponse)
+		return &user, nil
+	}
+
+	user, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	stored := *user
+	m.Set(key, &stored)
+	return user, nil
+}
+
+// memoResettingUserCommands clears the request memo after successful writes
+type memoResettingUserCommands struct {
+	domain.UserCommandService
+}
+
+// NewMemoResettingUserCommands creates a command decorator that drops memoized
+// lookups once a write succeeds, so later reads in the request see the change
+func NewMemoResettingUserCommands(commands domain.UserCommandService) domain.UserCommandService {
+	return &memoResettingUserCommands{UserCommandService: commands}
+}
+
+// UpdateProfile updates the user's own profile and resets the memo
+func (s *memoResettingUserCommands) UpdateProfile(
+	ctx context.Context,
+	userID string,
+	req *domain.UpdateUserRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.UserCommandService.UpdateProfile(ctx, userID, req)
+	if err == nil {
+		memo.FromContext(ctx).Reset()
+	}
+	return user, err
+}
+
+// UpdateUser applies an admin update and resets the memo
+func (s *memoResettingUserCommands) UpdateUser(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.UpdateUserRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.UserCommandService.UpdateUser(ctx, actorID, id, req)
+	if err == nil {
+		memo.FromContext(ctx).Reset()
+	}
+	return user, err
+}
+
+// DeleteUser deletes a user and resets the memo
+func (s *memoResettingUserCommands) DeleteUser(ctx context.Context, id string) error {
+	err := s.UserCommandService.DeleteUser(ctx, id)
+	if err == nil {
+		memo.FromContext(ctx).Reset()
+	}
+	return err
+}
+
+// BulkUserAction applies a bulk action and resets the memo
+func (s *memoResettingUserCommands) BulkUserAction(
+	ctx context.Context,
+	actorID string,
+	req *domain.BulkUserActionRequest,
+) ([]domain.BulkItemResult, error) {
+	results, err := s.UserCommandService.BulkUserAction(ctx, actorID, req)
+	if err == nil {
+		memo.FromContext(ctx).Reset()
+	}
+	return results, err
+}
+
+// SetUserStatus changes an account's status and resets the memo
+func (s *memoResettingUserCommands) SetUserStatus(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.SetUserStatusRequest,
+) (*domain.UserResponse, error) {
+	user, err := s.UserCommandService.SetUserStatus(ctx, actorID, id, req)
+	if err == nil {
+		memo.FromContext(ctx).Reset()
+	}
+	return user, err
+}
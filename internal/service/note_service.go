@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// noteService implements domain.NoteService
+type noteService struct {
+	noteRepo domain.NoteRepository
+	userRepo domain.UserRepository
+	logger   *logger.Logger
+}
+
+// NewNoteService creates a new user note service
+func NewNoteService(noteRepo domain.NoteRepository, userRepo domain.UserRepository) domain.NoteService {
+	return &noteService{
+		noteRepo: noteRepo,
+		userRepo: userRepo,
+		logger:   logger.GetGlobal().ForComponent("note-service"),
+	}
+}
+
+// AddNote attaches a new note to the user, authored by the actor
+func (s *noteService) AddNote(ctx context.Context, actorID, userID string, req *domain.NoteRequest) (*domain.UserNote, error) {
+	body, err := noteBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	note := &domain.UserNote{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Body:      body,
+		AuthorID:  actorID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.noteRepo.Create(ctx, note); err != nil {
+		return nil, err
+	}
+
+	s.logger.ForService("note", "add").Info("Note added", "actor_id", actorID, "user_id", userID, "note_id", note.ID)
+	return note, nil
+}
+
+// ListNotes returns the user's notes, newest first
+func (s *noteService) ListNotes(ctx context.Context, userID string) ([]*domain.UserNote, error) {
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.noteRepo.ListByUser(ctx, userID)
+}
+
+// UpdateNote replaces the text of a note, keeping the previous version in its history
+func (s *noteService) UpdateNote(
+	ctx context.Context,
+	actorID, userID, noteID string,
+	req *domain.NoteRequest,
+) (*domain.UserNote, error) {
+	body, err := noteBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := s.noteRepo.Get(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+	// Notes are addressed through their user; a note of another user is not found
+	if note.UserID != userID {
+		return nil, domain.ErrNoteNotFound
+	}
+	if note.Body == body {
+		return note, nil
+	}
+
+	editedBy := note.EditedBy
+	if editedBy == "" {
+		editedBy = note.AuthorID
+	}
+	note.History = append(note.History, domain.NoteRevision{
+		Body:     note.Body,
+		EditedBy: editedBy,
+		EditedAt: note.UpdatedAt,
+	})
+	note.Body = body
+	note.EditedBy = actorID
+	note.UpdatedAt = time.Now().UTC()
+	if err := s.noteRepo.Update(ctx, note); err != nil {
+		return nil, err
+	}
+
+	s.logger.ForService("note", "update").Info("Note edited", "actor_id", actorID, "user_id", userID, "note_id", noteID)
+	return note, nil
+}
+
+// checkUser returns ErrUserNotFound unless the user exists
+func (s *noteService) checkUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return adminAccessError(user.Status)
+}
+
+func noteBody(req *domain.NoteRequest) (string, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return "", &domain.Error{Code: "VALIDATION_FAILED", Message: "Note must not be empty"}
+	}
+	if utf8.RuneCountInString(body) > domain.MaxNoteLength {
+		return "", &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Note must be at most %d characters", domain.MaxNoteLength),
+		}
+	}
+	return body, nil
+}
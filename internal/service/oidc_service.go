@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcRandomBytes is the size of states, nonces and PKCE verifiers
+	oidcRandomBytes = 32
+	// maxProvisionedNameLength matches the name limit of registrations
+	maxProvisionedNameLength = 100
+)
+
+// oidcService implements domain.OIDCService
+type oidcService struct {
+	provider     *oidc.Provider
+	userRepo     domain.UserRepository
+	states       domain.OIDCStateStore
+	tokenService domain.TokenService
+	auditService domain.AuditService
+	userCache    cache.Service
+	defaultRole  string
+	config       config.OIDCConfig
+	logger       *logger.Logger
+}
+
+// NewOIDCService creates a single sign-on service. Users are matched by email
+// and pinned to the provider's subject on their first login. Changed users
+// are evicted from userCache; auditService and userCache may be nil.
+func NewOIDCService(
+	provider *oidc.Provider,
+	userRepo domain.UserRepository,
+	states domain.OIDCStateStore,
+	tokenService domain.TokenService,
+	auditService domain.AuditService,
+	userCache cache.Service,
+	defaultRole string,
+	cfg config.OIDCConfig,
+) domain.OIDCService {
+	if cfg.StateTTL <= 0 {
+		cfg.StateTTL = 10 * time.Minute
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.NameClaim == "" {
+		cfg.NameClaim = "name"
+	}
+	return &oidcService{
+		provider:     provider,
+		userRepo:     userRepo,
+		states:       states,
+		tokenService: tokenService,
+		auditService: auditService,
+		userCache:    userCache,
+		defaultRole:  defaultRole,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("oidc-service"),
+	}
+}
+
+// BeginLogin stores a new login session and returns the provider URL for it
+func (s *oidcService) BeginLogin(ctx context.Context) (string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, pkceChallenge(verifier))
+	if err != nil {
+		s.logger.Error("Failed to discover the identity provider", "error", err)
+		return "", domain.ErrOIDCProviderUnavailable
+	}
+	if err := s.states.Save(ctx, state, &domain.OIDCSession{Nonce: nonce, CodeVerifier: verifier}, s.config.StateTTL); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// FinishLogin redeems the code, verifies the ID token and logs the user in,
+// creating their account on first login when provisioning is enabled
+func (s *oidcService) FinishLogin(ctx context.Context, code, state string) (string, *domain.UserResponse, error) {
+	log := s.logger.ForService("oidc", "finish-login")
+
+	if code == "" || state == "" {
+		return "", nil, domain.ErrOIDCFailed
+	}
+	session, err := s.states.Consume(ctx, state)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawIDToken, err := s.provider.Exchange(ctx, code, session.CodeVerifier)
+	if err != nil {
+		return "", nil, s.providerError(log, "Authorization code was not redeemed", err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, rawIDToken, session.Nonce)
+	if err != nil {
+		return "", nil, s.providerError(log, "ID token was refused", err)
+	}
+
+	identity := domain.ExternalIdentity{LinkedAt: time.Now().UTC()}
+	identity.Issuer, _ = claims["iss"].(string)
+	identity.Subject, _ = claims["sub"].(string)
+	log = log.WithField("subject", identity.Subject)
+
+	email := strings.ToLower(strings.TrimSpace(claimString(claims, s.config.EmailClaim)))
+	if email == "" {
+		log.Warn("ID token has no email", "claim", s.config.EmailClaim)
+		return "", nil, domain.ErrOIDCFailed
+	}
+	if s.config.RequireVerifiedEmail && !claimBool(claims, "email_verified") {
+		log.Warn("ID token email is not verified")
+		return "", nil, domain.ErrOIDCFailed
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err == domain.ErrUserNotFound {
+		user, err = s.provision(ctx, claims, email, identity)
+	} else if err == nil {
+		if accessErr := selfAccessError(user.Status); accessErr != nil {
+			if accessErr == domain.ErrUserNotFound {
+				return "", nil, domain.ErrOIDCFailed
+			}
+			log.Warn("Single sign-on for suspended account", "user_id", user.ID)
+			return "", nil, accessErr
+		}
+		err = s.link(ctx, user, claims, identity)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := s.tokenService.GenerateToken(user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	log.Info("User logged in with single sign-on", "user_id", user.ID)
+	return token, user.ToResponse(), nil
+}
+
+// Helper methods
+
+// provision creates the account of a first login
+func (s *oidcService) provision(ctx context.Context, claims jwt.MapClaims, email string, identity domain.ExternalIdentity) (*domain.User, error) {
+	if !s.config.AutoProvision {
+		s.logger.Warn("Single sign-on for an unknown account", "subject", identity.Subject)
+		return nil, domain.ErrOIDCNotProvisioned
+	}
+
+	role := s.mappedRole(claims)
+	if role == "" {
+		role = s.defaultRole
+	}
+	// Without a password the account can only log in through the provider
+	user := &domain.User{
+		Name:               s.displayName(claims, email),
+		Email:              email,
+		Role:               role,
+		ExternalIdentities: []domain.ExternalIdentity{identity},
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if err == domain.ErrUserAlreadyExists {
+			// A concurrent first login created the account
+			existing, getErr := s.userRepo.GetByEmail(ctx, email)
+			if getErr != nil {
+				return nil, getErr
+			}
+			return existing, s.link(ctx, existing, claims, identity)
+		}
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		_ = s.auditService.Record(ctx, &domain.AuditEvent{
+			Action:   domain.AuditActionUserProvisioned,
+			ActorID:  user.ID,
+			TargetID: user.ID,
+			Details:  map[string]interface{}{"issuer": identity.Issuer, "subject": identity.Subject, "role": role},
+		})
+	}
+	s.evict(ctx, user.ID)
+	s.logger.Info("User provisioned by single sign-on", "user_id", user.ID, "role", role)
+	return user, nil
+}
+
+// link pins the account to the provider's subject on its first single
+// sign-on, refuses other subjects afterwards and, with a role claim, applies
+// the mapped role
+func (s *oidcService) link(ctx context.Context, user *domain.User, claims jwt.MapClaims, identity domain.ExternalIdentity) error {
+	changed := false
+	linked := false
+	for _, existing := range user.ExternalIdentities {
+		if existing.Issuer != identity.Issuer {
+			continue
+		}
+		if existing.Subject != identity.Subject {
+			s.logger.Warn("Email is linked to another provider account", "user_id", user.ID, "subject", identity.Subject)
+			return domain.ErrOIDCFailed
+		}
+		linked = true
+	}
+	if !linked {
+		user.ExternalIdentities = append(append([]domain.ExternalIdentity{}, user.ExternalIdentities...), identity)
+		changed = true
+	}
+
+	if s.config.RoleClaim != "" {
+		role := s.mappedRole(claims)
+		if role == "" {
+			role = s.defaultRole
+		}
+		if role != user.Role {
+			s.logger.Info("Role updated from the identity provider", "user_id", user.ID, "from", user.Role, "to", role)
+			user.Role = role
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return err
+	}
+	s.evict(ctx, user.ID)
+	return nil
+}
+
+// mappedRole returns the role of the first role claim value in the mapping,
+// or "" when there is none
+func (s *oidcService) mappedRole(claims jwt.MapClaims) string {
+	if s.config.RoleClaim == "" {
+		return ""
+	}
+	var values []string
+	switch value := claims[s.config.RoleClaim].(type) {
+	case string:
+		values = []string{value}
+	case []interface{}:
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+	for _, value := range values {
+		if role, ok := s.config.RoleMapping[value]; ok {
+			return role
+		}
+	}
+	return ""
+}
+
+// displayName is the name claim, or the local part of the email without one
+func (s *oidcService) displayName(claims jwt.MapClaims, email string) string {
+	name := strings.TrimSpace(claimString(claims, s.config.NameClaim))
+	if len([]rune(name)) < 2 {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	if runes := []rune(name); len(runes) > maxProvisionedNameLength {
+		name = string(runes[:maxProvisionedNameLength])
+	}
+	return name
+}
+
+// providerError logs a failed exchange or verification and returns the error
+// for the caller
+func (s *oidcService) providerError(log *logger.Logger, message string, err error) error {
+	if errors.Is(err, oidc.ErrUnavailable) {
+		log.Error("Identity provider is unavailable", "error", err)
+		return domain.ErrOIDCProviderUnavailable
+	}
+	log.Warn(message, "error", err)
+	return domain.ErrOIDCFailed
+}
+
+// evict drops the cached copies of a changed user
+func (s *oidcService) evict(ctx context.Context, userID string) {
+	if s.userCache == nil {
+		return
+	}
+	if err := s.userCache.DeleteUser(ctx, userID); err != nil {
+		s.logger.Warn("Failed to evict user from cache", "user_id", userID, "error", err)
+	}
+}
+
+// claimString returns a string claim, or "" when it is missing or not a string
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimBool reads a boolean claim; some providers send booleans as strings
+func claimBool(claims jwt.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+// randomToken returns a URL-safe random string
+func randomToken() (string, error) {
+	b := make([]byte, oidcRandomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge is the S256 code challenge of verifier (RFC 7636)
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Opaque token format
+const (
+	opaqueTokenPrefix = "opq_"
+	opaqueTokenBytes  = 32
+	tokenStoreTimeout = 3 * time.Second
+)
+
+// opaqueTokenService implements domain.TokenService with random tokens whose
+// claims are stored server-side, for environments that forbid stateless
+// tokens and as a lightweight test double
+type opaqueTokenService struct {
+	store          domain.TokenStore
+	expirationTime time.Duration
+}
+
+// NewOpaqueTokenService creates a token service backed by a token store
+func NewOpaqueTokenService(store domain.TokenStore, expiration time.Duration) domain.TokenService {
+	return &opaqueTokenService{
+		store:          store,
+		expirationTime: expiration,
+	}
+}
+
+// GenerateToken issues a new random token and stores the user's claims
+func (s *opaqueTokenService) GenerateToken(user *domain.User) (string, error) {
+	now := time.Now()
+	claims := &domain.TokenClaims{
+		ID:     uuid.New().String(),
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+		Exp:    now.Add(s.expirationTime).Unix(),
+		Iat:    now.Unix(),
+	}
+	return s.issue(claims, s.expirationTime)
+}
+
+// GenerateClientToken issues a new random token for a machine client
+func (s *opaqueTokenService) GenerateClientToken(grant *domain.ClientGrant) (string, error) {
+	now := time.Now()
+	return s.issue(&domain.TokenClaims{
+		ID:       uuid.New().String(),
+		UserID:   grant.ClientID,
+		Role:     grant.Role,
+		Exp:      now.Add(grant.TTL).Unix(),
+		Iat:      now.Unix(),
+		ClientID: grant.ClientID,
+		Scopes:   append([]string(nil), grant.Scopes...),
+	}, grant.TTL)
+}
+
+// GenerateDelegatedToken issues a new random token acting for the grant's
+// subject user
+func (s *opaqueTokenService) GenerateDelegatedToken(grant *domain.DelegationGrant) (string, error) {
+	now := time.Now()
+	return s.issue(&domain.TokenClaims{
+		ID:     uuid.New().String(),
+		UserID: grant.Subject.UserID,
+		Email:  grant.Subject.Email,
+		Role:   grant.Role,
+		Exp:    now.Add(grant.TTL).Unix(),
+		Iat:    now.Unix(),
+		Scopes: append([]string(nil), grant.Scopes...),
+		Actor:  grant.Actor,
+	}, grant.TTL)
+}
+
+// issue stores claims under a new random token
+func (s *opaqueTokenService) issue(claims *domain.TokenClaims, ttl time.Duration) (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := opaqueTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenStoreTimeout)
+	defer cancel()
+
+	if err := s.store.Save(ctx, token, claims, ttl); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, nil
+}
+
+// ValidateToken looks up the stored claims for the token
+func (s *opaqueTokenService) ValidateToken(tokenString string) (*domain.TokenClaims, error) {
+	if !strings.HasPrefix(tokenString, opaqueTokenPrefix) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenStoreTimeout)
+	defer cancel()
+
+	claims, err := s.store.Get(ctx, tokenString)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.Exp {
+		return nil, domain.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ExtractUserIDFromToken extracts user ID from an opaque token
+func (s *opaqueTokenService) ExtractUserIDFromToken(tokenString string) (string, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.UserID, nil
+}
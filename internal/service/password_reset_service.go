@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/ratelimit"
+)
+
+// resetTokenBytes is the amount of randomness in a password reset token
+const resetTokenBytes = 32
+
+// resetEmailTimeout bounds delivering one password reset email
+const resetEmailTimeout = 30 * time.Second
+
+// EmailKindPasswordReset is the kind of the emails with password reset links
+const EmailKindPasswordReset = "password_reset"
+
+// passwordResetService implements domain.PasswordResetService
+type passwordResetService struct {
+	userRepo    domain.UserRepository
+	tokenStore  domain.ResetTokenStore
+	sender      EmailSender
+	limiter     ratelimit.Limiter
+	passwords   domain.PasswordPolicy
+	hasher      domain.PasswordHasher
+	revocations domain.TokenRevocationService
+	config      config.PasswordResetConfig
+	logger      *logger.Logger
+}
+
+// NewPasswordResetService creates the emailed password reset flow. Reset
+// links are delivered through sender. New passwords are checked against
+// passwords and hashed with hasher; nil ones only require MinPasswordLen
+// characters and hash with bcrypt at BCryptCost. Once a password is reset,
+// revocations signs out every token issued to the user before; nil skips it.
+func NewPasswordResetService(
+	userRepo domain.UserRepository,
+	tokenStore domain.ResetTokenStore,
+	sender EmailSender,
+	limiter ratelimit.Limiter,
+	passwords domain.PasswordPolicy,
+	hasher domain.PasswordHasher,
+	revocations domain.TokenRevocationService,
+	cfg config.PasswordResetConfig,
+) domain.PasswordResetService {
+	if passwords == nil {
+		passwords = defaultPasswordPolicy()
+	}
+	if hasher == nil {
+		hasher = defaultPasswordHasher()
+	}
+	return &passwordResetService{
+		userRepo:    userRepo,
+		tokenStore:  tokenStore,
+		sender:      sender,
+		limiter:     limiter,
+		passwords:   passwords,
+		hasher:      hasher,
+		revocations: revocations,
+		config:      cfg,
+		logger:      logger.GetGlobal().ForComponent("password-reset-service"),
+	}
+}
+
+// RequestReset emails a reset link when the email belongs to an active
+// account. It answers the same way whether or not the account exists, and the
+// email is sent in the background so response times do not tell either.
+func (s *passwordResetService) RequestReset(ctx context.Context, email, clientIP string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	log := s.logger.ForService("password-reset", "request").WithField("email", email)
+
+	if email == "" {
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Email is required"}
+	}
+
+	// Both the target account and the caller are throttled
+	if err := s.checkRateLimit(ctx, "password_reset:email:"+email); err != nil {
+		log.Warn("Password reset requests rate limited for email")
+		return err
+	}
+	if err := s.checkRateLimit(ctx, "password_reset:ip:"+clientIP); err != nil {
+		log.Warn("Password reset requests rate limited for client", "client_ip", clientIP)
+		return err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err == domain.ErrUserNotFound {
+		log.Info("Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive() {
+		log.Warn("Password reset requested for inactive account", "user_id", user.ID, "status", user.Status)
+		return nil
+	}
+
+	token, err := issueResetToken(ctx, s.tokenStore, user, s.config.TokenTTL)
+	if err != nil {
+		return err
+	}
+
+	message := &EmailMessage{
+		Kind:    EmailKindPasswordReset,
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the link below to choose a new password. It expires in %s and works once.\n\n%s\n\n"+
+				"If you did not ask for a password reset, you can ignore this email.\n",
+			user.Name, s.config.TokenTTL, strings.ReplaceAll(s.config.ResetURL, "{token}", token),
+		),
+	}
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), resetEmailTimeout)
+		defer cancel()
+		if err := s.sender.Send(sendCtx, message); err != nil {
+			log.Error("Failed to send password reset email", "user_id", user.ID, "error", err)
+			return
+		}
+		log.Info("Password reset email sent", "user_id", user.ID)
+	}()
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs out
+// the tokens issued with the old one
+func (s *passwordResetService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	user, err := redeemResetToken(ctx, s.userRepo, s.tokenStore, s.passwords, s.hasher, req)
+	if err != nil {
+		return err
+	}
+
+	log := s.logger.ForService("password-reset", "reset")
+	if s.revocations != nil {
+		if err := s.revocations.RevokeUserTokens(ctx, user.ID, user.ID); err != nil {
+			log.Error("Password reset but existing tokens were not revoked", "user_id", user.ID, "error", err)
+			return fmt.Errorf("password reset, but existing tokens were not revoked: %w", err)
+		}
+	}
+	log.Info("Password reset via emailed link", "user_id", user.ID)
+	return nil
+}
+
+func (s *passwordResetService) checkRateLimit(ctx context.Context, key string) error {
+	result, err := s.limiter.Allow(ctx, key, s.config.MaxAttempts, s.config.AttemptWindow)
+	if err != nil {
+		return err
+	}
+	if !result.Allowed {
+		return domain.ErrRateLimited
+	}
+	return nil
+}
+
+// issueResetToken stores a new reset token bound to the user's current password
+func issueResetToken(ctx context.Context, store domain.ResetTokenStore, user *domain.User, ttl time.Duration) (string, error) {
+	b := make([]byte, resetTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	binding := &domain.ResetToken{UserID: user.ID, PasswordStamp: passwordStamp(user.Password)}
+	if err := store.Save(ctx, token, binding, ttl); err != nil {
+		return "", fmt.Errorf("failed to store reset token: %w", err)
+	}
+	return token, nil
+}
+
+// redeemResetToken consumes a reset token and sets the new password. Tokens
+// issued before the password last changed are rejected, as are tokens of
+// accounts suspended, banned or deleted since they were issued.
+func redeemResetToken(
+	ctx context.Context,
+	userRepo domain.UserRepository,
+	store domain.ResetTokenStore,
+	passwords domain.PasswordPolicy,
+	hasher domain.PasswordHasher,
+	req *domain.ResetPasswordRequest,
+) (*domain.User, error) {
+	if err := passwords.Check(ctx, "new_password", req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	binding, err := store.Consume(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userRepo.GetByID(ctx, binding.UserID)
+	if err == domain.ErrUserNotFound {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if binding.PasswordStamp != passwordStamp(user.Password) || !user.IsActive() {
+		return nil, domain.ErrInvalidToken
+	}
+
+	hash, err := hasher.Hash(req.NewPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = hash
+
+	if err := userRepo.Update(ctx, user.ID, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// passwordStamp fingerprints a password hash without revealing it
+func passwordStamp(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"golang.org/x/text/language"
+)
+
+// preferencesService implements domain.PreferencesService
+type preferencesService struct {
+	prefsRepo domain.PreferencesRepository
+	logger    *logger.Logger
+}
+
+// NewPreferencesService creates a new display preferences service
+func NewPreferencesService(prefsRepo domain.PreferencesRepository) domain.PreferencesService {
+	return &preferencesService{
+		prefsRepo: prefsRepo,
+		logger:    logger.GetGlobal().ForComponent("preferences-service"),
+	}
+}
+
+// GetPreferences returns the user's display preferences, empty when none are saved
+func (s *preferencesService) GetPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	prefs, err := s.prefsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		prefs = &domain.UserPreferences{UserID: userID}
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences validates and applies changes to the user's display preferences
+func (s *preferencesService) UpdatePreferences(
+	ctx context.Context,
+	userID string,
+	req *domain.UpdatePreferencesRequest,
+) (*domain.UserPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Theme != nil {
+		theme := strings.TrimSpace(*req.Theme)
+		switch theme {
+		case "", domain.ThemeLight, domain.ThemeDark, domain.ThemeSystem:
+			prefs.Theme = theme
+		default:
+			return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Theme must be light, dark or system"}
+		}
+	}
+
+	if req.Language != nil {
+		lang := strings.TrimSpace(*req.Language)
+		if lang != "" {
+			tag, err := language.Parse(lang)
+			if err != nil {
+				return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Language must be a BCP 47 tag such as en-US"}
+			}
+			lang = tag.String()
+		}
+		prefs.Language = lang
+	}
+
+	if req.Timezone != nil {
+		tz := strings.TrimSpace(*req.Timezone)
+		if tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+				return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Timezone must be an IANA zone such as Europe/Berlin"}
+			}
+		}
+		prefs.Timezone = tz
+	}
+
+	if req.PublicProfile != nil {
+		prefs.PublicProfile = *req.PublicProfile
+	}
+
+	if req.HidePresence != nil {
+		prefs.HidePresence = *req.HidePresence
+	}
+
+	prefs.UpdatedAt = time.Now()
+	if err := s.prefsRepo.Upsert(ctx, prefs); err != nil {
+		s.logger.ForService("preferences", "update").Error("Failed to save preferences", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	return prefs, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// presenceWriteTimeout bounds recording activity, so a slow store does not
+// hold up the request being served
+const presenceWriteTimeout = 100 * time.Millisecond
+
+// presenceService implements domain.PresenceService
+type presenceService struct {
+	store     domain.PresenceStore
+	prefsRepo domain.PreferencesRepository
+	cfg       config.PresenceConfig
+	logger    *logger.Logger
+	now       func() time.Time
+}
+
+// NewPresenceService creates a presence service. Users who set the
+// hide_presence preference are neither tracked nor shown.
+func NewPresenceService(store domain.PresenceStore, prefsRepo domain.PreferencesRepository, cfg config.PresenceConfig) domain.PresenceService {
+	return &presenceService{
+		store:     store,
+		prefsRepo: prefsRepo,
+		cfg:       cfg,
+		logger:    logger.GetGlobal().ForComponent("presence-service"),
+		now:       time.Now,
+	}
+}
+
+// RecordActivity records that the user was seen now, at most once per debounce
+// interval. Preferences are only read once the debounce lets a write through.
+func (s *presenceService) RecordActivity(ctx context.Context, userID string) {
+	ctx, cancel := context.WithTimeout(ctx, presenceWriteTimeout)
+	defer cancel()
+	log := s.logger.ForService("presence", "record")
+
+	fresh, err := s.store.Claim(ctx, userID, s.cfg.Debounce)
+	if err != nil {
+		log.Debug("Failed to debounce activity", "user_id", userID, "error", err)
+		return
+	}
+	if !fresh {
+		return
+	}
+
+	prefs, err := s.prefsRepo.Get(ctx, userID)
+	if err != nil {
+		log.Debug("Failed to read presence preference, skipping", "user_id", userID, "error", err)
+		return
+	}
+	if prefs != nil && prefs.HidePresence {
+		return
+	}
+	if err := s.store.Record(ctx, userID, s.now()); err != nil {
+		log.Debug("Failed to record activity", "user_id", userID, "error", err)
+	}
+}
+
+// Presence derives each user's status from their last-seen time
+func (s *presenceService) Presence(ctx context.Context, userIDs []string) (map[string]*domain.Presence, error) {
+	lastSeen, err := s.store.LastSeen(ctx, userIDs)
+	if err != nil || len(lastSeen) == 0 {
+		return map[string]*domain.Presence{}, err
+	}
+
+	seenIDs := make([]string, 0, len(lastSeen))
+	for userID := range lastSeen {
+		seenIDs = append(seenIDs, userID)
+	}
+	prefs, err := s.prefsRepo.GetMany(ctx, seenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	result := make(map[string]*domain.Presence, len(lastSeen))
+	for userID, seen := range lastSeen {
+		if p := prefs[userID]; p != nil && p.HidePresence {
+			continue
+		}
+		status := domain.PresenceOffline
+		switch idle := now.Sub(seen); {
+		case idle <= s.cfg.OnlineWindow:
+			status = domain.PresenceOnline
+		case idle <= s.cfg.RecentWindow:
+			status = domain.PresenceRecentlyActive
+		}
+		result[userID] = &domain.Presence{Status: status, LastSeenAt: seen}
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"demo-go/internal/assembler"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+)
+
+// publicProfileService implements domain.PublicProfileService
+type publicProfileService struct {
+	userRepo       domain.UserRepository
+	prefsRepo      domain.PreferencesRepository
+	baseURL        string
+	domain         string
+	avatarTemplate string
+}
+
+// NewPublicProfileService creates a public profile service. avatarTemplate is
+// the avatar storage URL template (see config.ProfileConfig); empty leaves
+// profiles without avatars.
+func NewPublicProfileService(
+	userRepo domain.UserRepository,
+	prefsRepo domain.PreferencesRepository,
+	cfg config.PublicProfileConfig,
+	avatarTemplate string,
+) domain.PublicProfileService {
+	host := cfg.Domain
+	if host == "" {
+		if parsed, err := url.Parse(cfg.BaseURL); err == nil {
+			host = parsed.Host
+		}
+	}
+
+	return &publicProfileService{
+		userRepo:       userRepo,
+		prefsRepo:      prefsRepo,
+		baseURL:        strings.TrimSuffix(cfg.BaseURL, "/"),
+		domain:         strings.ToLower(host),
+		avatarTemplate: avatarTemplate,
+	}
+}
+
+// GetProfile returns the user's public profile
+func (s *publicProfileService) GetProfile(ctx context.Context, userID string) (*domain.PublicProfile, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.profileOf(ctx, user)
+}
+
+// WebFinger resolves acct:<id>@<domain> by user ID and any other acct: URI by email
+func (s *publicProfileService) WebFinger(ctx context.Context, resource string, rels []string) (*domain.WebFingerResource, error) {
+	account, ok := strings.CutPrefix(strings.TrimSpace(resource), "acct:")
+	local, host, found := strings.Cut(account, "@")
+	if !ok || !found || local == "" || host == "" {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "resource must be an acct: URI such as acct:user@example.com"}
+	}
+
+	var user *domain.User
+	var err error
+	if strings.EqualFold(host, s.domain) {
+		user, err = s.userRepo.GetByID(ctx, local)
+	}
+	if user == nil {
+		user, err = s.userRepo.GetByEmail(ctx, strings.ToLower(account))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profileOf(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []domain.WebFingerLink{{Rel: domain.WebFingerRelProfilePage, Type: "application/json", Href: profile.ProfileURL}}
+	if profile.AvatarURL != "" {
+		links = append(links, domain.WebFingerLink{Rel: domain.WebFingerRelAvatar, Href: profile.AvatarURL})
+	}
+	if len(rels) > 0 {
+		filtered := links[:0]
+		for _, link := range links {
+			for _, rel := range rels {
+				if link.Rel == rel {
+					filtered = append(filtered, link)
+					break
+				}
+			}
+		}
+		links = filtered
+	}
+
+	return &domain.WebFingerResource{
+		Subject: "acct:" + profile.Handle,
+		Aliases: []string{profile.ProfileURL},
+		Links:   links,
+	}, nil
+}
+
+// profileOf builds the public profile of a user who opted in
+func (s *publicProfileService) profileOf(ctx context.Context, user *domain.User) (*domain.PublicProfile, error) {
+	if !user.IsActive() {
+		return nil, domain.ErrUserNotFound
+	}
+	prefs, err := s.prefsRepo.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil || !prefs.PublicProfile {
+		return nil, domain.ErrUserNotFound
+	}
+
+	profile := &domain.PublicProfile{
+		ID:         user.ID,
+		Name:       user.Name,
+		Handle:     user.ID + "@" + s.domain,
+		ProfileURL: s.baseURL + "/api/v1/public/profiles/" + url.PathEscape(user.ID),
+	}
+	if s.avatarTemplate != "" {
+		profile.AvatarURL = assembler.AvatarURL(s.avatarTemplate, user.ID)
+	}
+	return profile, nil
+}
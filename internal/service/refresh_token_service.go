@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// Refresh token format
+const (
+	refreshTokenPrefix = "rt_"
+	refreshTokenBytes  = 32
+)
+
+// refreshTokenService implements domain.RefreshTokenService
+type refreshTokenService struct {
+	store       domain.RefreshTokenStore
+	commands    domain.UserCommandService
+	events      domain.SecurityEventService
+	revocations domain.TokenRevocationService
+	sessions    domain.SessionRecorder
+	ttl         time.Duration
+	rememberTTL time.Duration
+	logger      *logger.Logger
+}
+
+// RefreshTokenServiceOption configures the refresh token service
+type RefreshTokenServiceOption func(*refreshTokenService)
+
+// WithSessionRecorder tracks every token family as a session of its user.
+// Failing to record a session is logged and does not fail the sign-in.
+func WithSessionRecorder(sessions domain.SessionRecorder) RefreshTokenServiceOption {
+	return func(s *refreshTokenService) {
+		s.sessions = sessions
+	}
+}
+
+// WithRememberMe gives token families started with remember-me the longer
+// lifetime ttl. Without it they last as long as any other family.
+func WithRememberMe(ttl time.Duration) RefreshTokenServiceOption {
+	return func(s *refreshTokenService) {
+		s.rememberTTL = ttl
+	}
+}
+
+// NewRefreshTokenService creates a refresh token service. Access tokens are
+// minted through commands.RefreshToken, so suspended and deleted users cannot
+// refresh. Detected token reuse is recorded in events, and families started
+// before their user's tokens were revoked are refused, when the respective
+// service is not nil.
+func NewRefreshTokenService(
+	store domain.RefreshTokenStore,
+	commands domain.UserCommandService,
+	events domain.SecurityEventService,
+	revocations domain.TokenRevocationService,
+	ttl time.Duration,
+	opts ...RefreshTokenServiceOption,
+) domain.RefreshTokenService {
+	s := &refreshTokenService{
+		store:       store,
+		commands:    commands,
+		events:      events,
+		revocations: revocations,
+		ttl:         ttl,
+		logger:      logger.GetGlobal().ForComponent("refresh-token-service"),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Issue starts a new token family for the user
+func (s *refreshTokenService) Issue(
+	ctx context.Context,
+	userID, accessToken string,
+	rememberMe bool,
+	client domain.SessionClient,
+) (string, time.Time, error) {
+	token, record, err := s.issue(ctx, &domain.RefreshTokenRecord{
+		UserID:     userID,
+		FamilyID:   uuid.New().String(),
+		IssuedAt:   time.Now().UTC(),
+		RememberMe: rememberMe && s.rememberTTL > 0,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	s.track(ctx, record, accessToken, client)
+	return token, record.ExpiresAt, nil
+}
+
+// Rotate exchanges a refresh token for a new token pair. A token can be used
+// once; presenting it again revokes its whole family, logging out both the
+// legitimate client and whoever replayed the token. The new pair is minted
+// before the token is used up, so a rotation failing on the way leaves the
+// token valid and retrying it is not taken for reuse.
+func (s *refreshTokenService) Rotate(ctx context.Context, refreshToken string, client domain.SessionClient) (*domain.TokenPair, error) {
+	log := s.logger.ForService("refresh-token", "rotate")
+
+	if !strings.HasPrefix(refreshToken, refreshTokenPrefix) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	record, err := s.store.Get(ctx, refreshToken)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		return nil, s.reused(ctx, record, log)
+	}
+	if err != nil {
+		return nil, err
+	}
+	familyClaims := &domain.TokenClaims{UserID: record.UserID, Iat: record.IssuedAt.Unix()}
+	if s.revocations != nil {
+		revoked, err := s.revocations.IsRevoked(ctx, familyClaims)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, domain.ErrInvalidToken
+		}
+	}
+
+	accessToken, err := s.commands.RefreshToken(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, nextRecord, err := s.issue(ctx, &domain.RefreshTokenRecord{
+		UserID:     record.UserID,
+		FamilyID:   record.FamilyID,
+		IssuedAt:   record.IssuedAt,
+		RememberMe: record.RememberMe,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Of concurrent rotations only one uses the token up; the others revoke
+	// the family, the pair minted here included
+	if record, err = s.store.Use(ctx, refreshToken); errors.Is(err, domain.ErrRefreshTokenReused) {
+		return nil, s.reused(ctx, record, log)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.track(ctx, nextRecord, accessToken, client)
+
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     next,
+		RefreshExpiresAt: nextRecord.ExpiresAt,
+	}, nil
+}
+
+// reused revokes the family of a token presented again after its rotation
+func (s *refreshTokenService) reused(ctx context.Context, record *domain.RefreshTokenRecord, log *logger.Logger) error {
+	log.Warn("Refresh token reused, revoking token family", "user_id", record.UserID, "family_id", record.FamilyID)
+	if revokeErr := s.store.RevokeFamily(ctx, record.FamilyID, s.maxTTL()); revokeErr != nil {
+		log.Error("Failed to revoke refresh token family", "family_id", record.FamilyID, "error", revokeErr)
+	}
+	s.recordReuse(ctx, record)
+	s.endSession(ctx, record)
+	return domain.ErrInvalidToken
+}
+
+// Revoke spends the token and revokes its family
+func (s *refreshTokenService) Revoke(ctx context.Context, userID, refreshToken string) error {
+	if !strings.HasPrefix(refreshToken, refreshTokenPrefix) {
+		return nil
+	}
+
+	record, err := s.store.Use(ctx, refreshToken)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, domain.ErrRefreshTokenReused) {
+		return err
+	}
+	if record.UserID != userID {
+		return nil
+	}
+	if err := s.store.RevokeFamily(ctx, record.FamilyID, s.maxTTL()); err != nil {
+		return err
+	}
+	s.endSession(ctx, record)
+	return nil
+}
+
+// issue stores a new random refresh token of the family described by record,
+// setting its expiry
+func (s *refreshTokenService) issue(ctx context.Context, record *domain.RefreshTokenRecord) (string, *domain.RefreshTokenRecord, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := refreshTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	ttl := s.ttl
+	if record.RememberMe {
+		ttl = s.rememberTTL
+	}
+	record.ExpiresAt = time.Now().Add(ttl).UTC()
+	if err := s.store.Save(ctx, token, record); err != nil {
+		return "", nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return token, record, nil
+}
+
+// maxTTL is the longest lifetime of a refresh token, which revocations of a
+// family have to outlive
+func (s *refreshTokenService) maxTTL() time.Duration {
+	if s.rememberTTL > s.ttl {
+		return s.rememberTTL
+	}
+	return s.ttl
+}
+
+// track records the session of a token family when sessions are tracked
+func (s *refreshTokenService) track(ctx context.Context, record *domain.RefreshTokenRecord, accessToken string, client domain.SessionClient) {
+	if s.sessions == nil {
+		return
+	}
+	if err := s.sessions.Track(ctx, record, accessToken, client); err != nil {
+		s.logger.Warn("Failed to record session", "user_id", record.UserID, "family_id", record.FamilyID, "error", err)
+	}
+}
+
+// endSession ends the session of a revoked token family when sessions are tracked
+func (s *refreshTokenService) endSession(ctx context.Context, record *domain.RefreshTokenRecord) {
+	if s.sessions == nil {
+		return
+	}
+	if err := s.sessions.End(ctx, record.UserID, record.FamilyID); err != nil {
+		s.logger.Warn("Failed to end session", "user_id", record.UserID, "family_id", record.FamilyID, "error", err)
+	}
+}
+
+func (s *refreshTokenService) recordReuse(ctx context.Context, record *domain.RefreshTokenRecord) {
+	if s.events == nil {
+		return
+	}
+	s.events.Record(ctx, &domain.SecurityEvent{
+		Type:     domain.SecurityEventRefreshTokenReused,
+		Severity: domain.SecuritySeverityHigh,
+		TargetID: record.UserID,
+		Reason:   domain.ErrRefreshTokenReused.Code,
+		Details:  map[string]interface{}{"family_id": record.FamilyID},
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+)
+
+// roleCheckEntry is a user's role and status as last read
+type roleCheckEntry struct {
+	role      string
+	status    string
+	checkedAt time.Time
+}
+
+// roleCheckService implements domain.RoleCheckService with a per-instance
+// cache, so each user's role is read at most once per interval per instance
+type roleCheckService struct {
+	userRepo domain.UserRepository
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]roleCheckEntry
+	lastSweep time.Time
+}
+
+// NewRoleCheckService creates a role check service. A role change reaches
+// every instance within interval.
+func NewRoleCheckService(userRepo domain.UserRepository, interval time.Duration) domain.RoleCheckService {
+	return &roleCheckService{
+		userRepo: userRepo,
+		interval: interval,
+		now:      time.Now,
+		entries:  make(map[string]roleCheckEntry),
+	}
+}
+
+// CurrentRole returns the cached role, reading it again once it is older than the interval
+func (s *roleCheckService) CurrentRole(ctx context.Context, userID string) (string, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	entry, ok := s.entries[userID]
+	s.mu.Unlock()
+	if ok && now.Sub(entry.checkedAt) < s.interval {
+		return entry.role, selfAccessError(entry.status)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.Status == domain.UserStatusDeleted {
+		return "", domain.ErrUserNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[userID] = roleCheckEntry{role: user.Role, status: user.Status, checkedAt: now}
+	if now.Sub(s.lastSweep) >= s.interval {
+		// Drop stale entries, so users who stop making requests are forgotten
+		for id, e := range s.entries {
+			if now.Sub(e.checkedAt) >= s.interval {
+				delete(s.entries, id)
+			}
+		}
+		s.lastSweep = now
+	}
+	return user.Role, selfAccessError(user.Status)
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/policy"
+)
+
+const (
+	// maxRoleDescriptionLength bounds role descriptions
+	maxRoleDescriptionLength = 200
+	// roleReloadTimeout bounds a background reload of the roles
+	roleReloadTimeout = 5 * time.Second
+)
+
+// roleNamePattern keeps role names short and safe to use in tokens and paths
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// roleSnapshot is the roles and permissions requests are checked against
+type roleSnapshot struct {
+	roles    map[string]bool
+	engine   *policy.Matrix
+	loadedAt time.Time
+}
+
+// roleService implements domain.RoleService
+type roleService struct {
+	repo            domain.RoleRepository
+	users           domain.UserRepository
+	auditService    domain.AuditService
+	grants          map[string][]string
+	builtIn         map[string]bool
+	refreshInterval time.Duration
+	logger          *logger.Logger
+	now             func() time.Time
+
+	mu        sync.Mutex
+	snapshot  *roleSnapshot
+	reloading bool
+}
+
+// NewRoleService creates a new role service. grants are the permissions
+// configured for each role, which its stored permissions add to; builtIn
+// are the roles the configuration refers to. The roles are reloaded in the
+// background once they are older than refreshInterval; 0 only reloads them
+// on changes made through this service.
+func NewRoleService(
+	repo domain.RoleRepository,
+	users domain.UserRepository,
+	auditService domain.AuditService,
+	grants map[string][]string,
+	builtIn []string,
+	refreshInterval time.Duration,
+) (domain.RoleService, error) {
+	s := &roleService{
+		repo:            repo,
+		users:           users,
+		auditService:    auditService,
+		grants:          grants,
+		builtIn:         make(map[string]bool, len(builtIn)),
+		refreshInterval: refreshInterval,
+		logger:          logger.GetGlobal().ForComponent("role-service"),
+		now:             time.Now,
+	}
+	for _, name := range builtIn {
+		s.builtIn[name] = true
+	}
+	// Until the first reload, the built-in roles hold their configured permissions
+	snapshot, err := s.build(nil)
+	if err != nil {
+		return nil, err
+	}
+	s.snapshot = snapshot
+	return s, nil
+}
+
+// Allows reports whether the role holds the permission
+func (s *roleService) Allows(role, permission string) bool {
+	return s.current().engine.Allows(role, permission)
+}
+
+// Permissions returns the permissions the role holds
+func (s *roleService) Permissions(role string) []string {
+	return s.current().engine.Permissions(role)
+}
+
+// Grants returns the permissions configured and stored for each role
+func (s *roleService) Grants() map[string][]string {
+	return s.current().engine.Grants()
+}
+
+// HasRole reports whether the role exists
+func (s *roleService) HasRole(name string) bool {
+	return s.current().roles[name]
+}
+
+// CreateRole defines a new role
+func (s *roleService) CreateRole(ctx context.Context, actorID, actorRole string, req *domain.CreateRoleRequest) (*domain.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: "Role names are 1 to 32 lowercase letters, digits, '-' or '_', starting with a letter",
+		}
+	}
+	description, err := validRoleDescription(req.Description)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := validRolePermissions(name, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkGrants(actorRole, nil, permissions); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	role := &domain.Role{
+		Name:        name,
+		Description: description,
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionRoleCreated, actorID, name, map[string]interface{}{"permissions": permissions})
+	s.logger.ForService("role", "create").Info("Role created", "actor_id", actorID, "role", name, "permissions", permissions)
+	s.reload(ctx)
+	return s.describe(role), nil
+}
+
+// ListRoles returns every role, sorted by name
+func (s *roleService) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		s.describe(role)
+	}
+	return roles, nil
+}
+
+// GetRole returns a role
+func (s *roleService) GetRole(ctx context.Context, name string) (*domain.Role, error) {
+	role, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.describe(role), nil
+}
+
+// UpdateRole changes the description or the stored permissions of a role
+func (s *roleService) UpdateRole(ctx context.Context, actorID, actorRole, name string, req *domain.UpdateRoleRequest) (*domain.Role, error) {
+	if name == actorRole {
+		return nil, domain.ErrRoleSelfEdit
+	}
+	role, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	before := role.Permissions
+
+	if req.Description != nil {
+		if role.Description, err = validRoleDescription(*req.Description); err != nil {
+			return nil, err
+		}
+	}
+	if req.Permissions != nil {
+		if role.Permissions, err = validRolePermissions(name, *req.Permissions); err != nil {
+			return nil, err
+		}
+		if err := s.checkGrants(actorRole, before, role.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	role.UpdatedAt = s.now().UTC()
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionRoleUpdated, actorID, name, map[string]interface{}{"from": before, "to": role.Permissions})
+	s.logger.ForService("role", "update").Info("Role updated", "actor_id", actorID, "role", name, "permissions", role.Permissions)
+	s.reload(ctx)
+	return s.describe(role), nil
+}
+
+// DeleteRole deletes a role no user holds
+func (s *roleService) DeleteRole(ctx context.Context, actorID, name string) error {
+	if s.builtIn[name] {
+		return domain.ErrRoleBuiltIn
+	}
+	if _, err := s.repo.GetByName(ctx, name); err != nil {
+		return err
+	}
+	_, holders, err := s.users.Search(ctx, &domain.UserQuery{
+		Filters: []domain.Filter{
+			{Field: "role", Op: domain.OpEq, Value: name},
+			{Field: "status", Op: domain.OpNe, Value: domain.UserStatusDeleted},
+		},
+		Limit: 1,
+	})
+	if err != nil {
+		return err
+	}
+	if holders > 0 {
+		return domain.ErrRoleInUse
+	}
+	if err := s.repo.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionRoleDeleted, actorID, name, nil)
+	s.logger.ForService("role", "delete").Info("Role deleted", "actor_id", actorID, "role", name)
+	s.reload(ctx)
+	return nil
+}
+
+// Reload creates the missing built-in roles and reloads the roles
+func (s *roleService) Reload(ctx context.Context) error {
+	for name := range s.builtIn {
+		_, err := s.repo.GetByName(ctx, name)
+		if !errors.Is(err, domain.ErrRoleNotFound) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		now := s.now().UTC()
+		// Another instance may have created it meanwhile
+		err = s.repo.Create(ctx, &domain.Role{Name: name, Permissions: []string{}, CreatedAt: now, UpdatedAt: now})
+		if err != nil && !errors.Is(err, domain.ErrRoleExists) {
+			return err
+		}
+	}
+	return s.load(ctx)
+}
+
+// Helper methods
+
+// current returns the roles in effect, starting a background reload when
+// they are older than the refresh interval
+func (s *roleService) current() *roleSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.refreshInterval > 0 && !s.reloading && s.now().Sub(s.snapshot.loadedAt) >= s.refreshInterval {
+		s.reloading = true
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), roleReloadTimeout)
+			defer cancel()
+			s.reload(ctx)
+			s.mu.Lock()
+			s.reloading = false
+			s.mu.Unlock()
+		}()
+	}
+	return s.snapshot
+}
+
+// reload loads the roles; on failure the roles in effect are kept until
+// the next refresh is due
+func (s *roleService) reload(ctx context.Context) {
+	err := s.load(ctx)
+	if err == nil {
+		return
+	}
+	s.logger.ForService("role", "reload").Warn("Failed to reload roles", "error", err)
+	s.mu.Lock()
+	stale := *s.snapshot
+	stale.loadedAt = s.now()
+	s.snapshot = &stale
+	s.mu.Unlock()
+}
+
+func (s *roleService) load(ctx context.Context) error {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	snapshot, err := s.build(roles)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.snapshot = snapshot
+	s.mu.Unlock()
+	return nil
+}
+
+// build combines the configured permissions of each role with the stored
+// ones. The built-in roles always exist, even before they are stored.
+func (s *roleService) build(roles []*domain.Role) (*roleSnapshot, error) {
+	grants := make(map[string][]string, len(s.grants)+len(roles))
+	for role, permissions := range s.grants {
+		grants[role] = append([]string(nil), permissions...)
+	}
+	snapshot := &roleSnapshot{roles: make(map[string]bool, len(s.builtIn)+len(roles)), loadedAt: s.now()}
+	for name := range s.builtIn {
+		snapshot.roles[name] = true
+	}
+	for _, role := range roles {
+		snapshot.roles[role.Name] = true
+		for _, permission := range role.Permissions {
+			if !containsString(grants[role.Name], permission) {
+				grants[role.Name] = append(grants[role.Name], permission)
+			}
+		}
+	}
+	engine, err := policy.NewMatrix(grants)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.engine = engine
+	return snapshot, nil
+}
+
+// describe fills in what follows from the configuration
+func (s *roleService) describe(role *domain.Role) *domain.Role {
+	role.BuiltIn = s.builtIn[role.Name]
+	role.EffectivePermissions = s.current().engine.Permissions(role.Name)
+	return role
+}
+
+// record writes an audit event for a role; a failed write is logged by the
+// audit service, not returned
+func (s *roleService) record(ctx context.Context, action, actorID, name string, details map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["role"] = name
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:  action,
+		ActorID: actorID,
+		Details: details,
+	})
+}
+
+func validRoleDescription(description string) (string, error) {
+	description = strings.TrimSpace(description)
+	if len([]rune(description)) > maxRoleDescriptionLength {
+		return "", &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Role descriptions are at most %d characters", maxRoleDescriptionLength),
+		}
+	}
+	return description, nil
+}
+
+// validRolePermissions trims and deduplicates permissions and checks that
+// each is a permission name, "<area>.*" or "*"
+func validRolePermissions(role string, permissions []string) ([]string, error) {
+	valid := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if permission != "" && !containsString(valid, permission) {
+			valid = append(valid, permission)
+		}
+	}
+	if _, err := policy.NewMatrix(map[string][]string{role: valid}); err != nil {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: err.Error()}
+	}
+	sort.Strings(valid)
+	return valid, nil
+}
+
+// checkGrants refuses permissions added to a role that the actor's role does
+// not hold, so admins cannot give another role, and then themselves, more
+// than they have. Permissions the role already had may stay.
+func (s *roleService) checkGrants(actorRole string, before, after []string) error {
+	for _, permission := range after {
+		if !containsString(before, permission) && !s.Allows(actorRole, permission) {
+			return domain.ErrRoleGrantDenied
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/pathrule"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// routeOverridesPath is where overrides are managed; no override may
+	// match it, so operators cannot lock themselves out
+	routeOverridesPath = "/api/v1/admin/route-overrides"
+	// routeOverrideLoadTimeout bounds one background reload of the overrides
+	routeOverrideLoadTimeout = 5 * time.Second
+	// maxRouteOverrideReasonLength bounds override reasons
+	maxRouteOverrideReasonLength = 200
+)
+
+// activeOverride is an override with its parsed rule
+type activeOverride struct {
+	override *domain.RouteOverride
+	rule     pathrule.Rule
+}
+
+// routeOverrideService implements domain.RouteOverrideService. Requests are
+// matched against a snapshot of the repository that is reloaded in the
+// background once it is older than the refresh interval, and right away
+// after a change on this instance.
+type routeOverrideService struct {
+	repo         domain.RouteOverrideRepository
+	auditService domain.AuditService
+	interval     time.Duration
+	logger       *logger.Logger
+	now          func() time.Time
+
+	mu        sync.RWMutex
+	active    []activeOverride
+	loadedAt  time.Time
+	reloading bool
+}
+
+// NewRouteOverrideService creates a route override service whose overrides
+// reach every instance within interval
+func NewRouteOverrideService(
+	repo domain.RouteOverrideRepository,
+	auditService domain.AuditService,
+	interval time.Duration,
+) domain.RouteOverrideService {
+	return &routeOverrideService{
+		repo:         repo,
+		auditService: auditService,
+		interval:     interval,
+		logger:       logger.GetGlobal().ForComponent("route-override-service"),
+		now:          time.Now,
+	}
+}
+
+// ListOverrides returns the overrides in effect, oldest first
+func (s *routeOverrideService) ListOverrides(ctx context.Context) ([]*domain.RouteOverride, error) {
+	overrides, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	inEffect := make([]*domain.RouteOverride, 0, len(overrides))
+	for _, override := range overrides {
+		if !override.Expired(now) {
+			inEffect = append(inEffect, override)
+		}
+	}
+	return inEffect, nil
+}
+
+// CreateOverride validates and stores an override, then applies it on this instance
+func (s *routeOverrideService) CreateOverride(
+	ctx context.Context,
+	actorID string,
+	req *domain.CreateRouteOverrideRequest,
+) (*domain.RouteOverride, error) {
+	rule, err := pathrule.Parse(req.Rule)
+	if err != nil {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: err.Error()}
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		if rule.Matches(method, routeOverridesPath) || rule.Matches(method, routeOverridesPath+"/id") {
+			return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Overrides cannot apply to " + routeOverridesPath}
+		}
+	}
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	if action != domain.RouteOverrideOpen && action != domain.RouteOverrideClose {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Action must be %s or %s", domain.RouteOverrideOpen, domain.RouteOverrideClose),
+		}
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len([]rune(reason)) > maxRouteOverrideReasonLength {
+		return nil, &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("Reasons are at most %d characters", maxRouteOverrideReasonLength),
+		}
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Override expiry must be in the future"}
+	}
+
+	override := &domain.RouteOverride{
+		ID:        uuid.New().String(),
+		Rule:      rule.String(),
+		Action:    action,
+		Reason:    reason,
+		CreatedBy: actorID,
+		CreatedAt: now,
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		override.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditActionRouteOverrideCreated, actorID, override)
+	s.logger.ForService("route_override", "create").Info("Route override created",
+		"actor_id", actorID, "override_id", override.ID, "rule", override.Rule, "action", action)
+	s.reload(ctx)
+	return override, nil
+}
+
+// DeleteOverride removes an override, then stops applying it on this instance
+func (s *routeOverrideService) DeleteOverride(ctx context.Context, actorID, id string) error {
+	overrides, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	var override *domain.RouteOverride
+	for _, o := range overrides {
+		if o.ID == id {
+			override = o
+		}
+	}
+	if override == nil {
+		return domain.ErrRouteOverrideNotFound
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditActionRouteOverrideDeleted, actorID, override)
+	s.logger.ForService("route_override", "delete").Info("Route override deleted",
+		"actor_id", actorID, "override_id", id, "rule", override.Rule)
+	s.reload(ctx)
+	return nil
+}
+
+// MatchRoute returns the override matching the request from the snapshot,
+// starting a background reload when the snapshot is stale
+func (s *routeOverrideService) MatchRoute(method, path string) (*domain.RouteOverride, bool) {
+	now := s.now()
+
+	s.mu.Lock()
+	if !s.reloading && now.Sub(s.loadedAt) >= s.interval {
+		s.reloading = true
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), routeOverrideLoadTimeout)
+			defer cancel()
+			s.reload(ctx)
+		}()
+	}
+	active := s.active
+	s.mu.Unlock()
+
+	var opened *domain.RouteOverride
+	for _, a := range active {
+		if a.override.Expired(now) || !a.rule.Matches(method, path) {
+			continue
+		}
+		if a.override.Action == domain.RouteOverrideClose {
+			return a.override, true
+		}
+		if opened == nil {
+			opened = a.override
+		}
+	}
+	return opened, opened != nil
+}
+
+// Helper methods
+
+// reload replaces the snapshot with the overrides in the repository. When
+// the repository cannot be read the last snapshot stays in use.
+func (s *routeOverrideService) reload(ctx context.Context) {
+	overrides, err := s.repo.List(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloading = false
+	if err != nil {
+		s.logger.Warn("Failed to reload route overrides; keeping the last ones", "error", err)
+		return
+	}
+
+	active := make([]activeOverride, 0, len(overrides))
+	for _, override := range overrides {
+		rule, err := pathrule.Parse(override.Rule)
+		if err != nil {
+			s.logger.Warn("Skipping invalid route override", "override_id", override.ID, "error", err)
+			continue
+		}
+		active = append(active, activeOverride{override: override, rule: rule})
+	}
+	s.active = active
+	s.loadedAt = s.now()
+}
+
+// record writes an audit event for an override; a failed write is logged by
+// the audit service, not returned
+func (s *routeOverrideService) record(ctx context.Context, action, actorID string, override *domain.RouteOverride) {
+	if s.auditService == nil {
+		return
+	}
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:   action,
+		ActorID:  actorID,
+		TargetID: override.ID,
+		Details:  map[string]interface{}{"rule": override.Rule, "action": override.Action, "reason": override.Reason},
+	})
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// maxSessionUserAgentLength bounds the user agents stored with sessions
+const maxSessionUserAgentLength = 512
+
+// sessionService implements domain.SessionService and domain.SessionRecorder
+type sessionService struct {
+	repo          domain.SessionRepository
+	refreshTokens domain.RefreshTokenStore
+	tokens        domain.TokenService
+	revocations   domain.TokenRevocationService
+	ttl           time.Duration
+	logger        *logger.Logger
+	now           func() time.Time
+}
+
+// RecordingSessionService is a session service that the refresh token
+// service also reports issued tokens to
+type RecordingSessionService interface {
+	domain.SessionService
+	domain.SessionRecorder
+}
+
+// NewSessionService creates a session service. tokens reads the IDs of the
+// access tokens issued in a session, revocations denies them when the session
+// ends, and refreshTokens revokes the session's refresh token family, which
+// has to be remembered for ttl, the lifetime of a refresh token.
+func NewSessionService(
+	repo domain.SessionRepository,
+	refreshTokens domain.RefreshTokenStore,
+	tokens domain.TokenService,
+	revocations domain.TokenRevocationService,
+	ttl time.Duration,
+) RecordingSessionService {
+	return &sessionService{
+		repo:          repo,
+		refreshTokens: refreshTokens,
+		tokens:        tokens,
+		revocations:   revocations,
+		ttl:           ttl,
+		logger:        logger.GetGlobal().ForComponent("session-service"),
+		now:           time.Now,
+	}
+}
+
+// Track records the access token and client of a sign-in or refresh. The
+// session keeps the client it was last seen from.
+func (s *sessionService) Track(
+	ctx context.Context,
+	record *domain.RefreshTokenRecord,
+	accessToken string,
+	client domain.SessionClient,
+) error {
+	now := s.now().UTC()
+	session, err := s.repo.GetByID(ctx, record.UserID, record.FamilyID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		session = &domain.Session{ID: record.FamilyID, UserID: record.UserID, CreatedAt: record.IssuedAt}
+	} else if err != nil {
+		return err
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxSessionUserAgentLength {
+		userAgent = userAgent[:maxSessionUserAgentLength]
+	}
+	session.IPAddress = client.IPAddress
+	session.UserAgent = userAgent
+	session.Device = describeDevice(userAgent)
+	session.LastSeenAt = now
+	session.ExpiresAt = record.ExpiresAt
+	session.RememberMe = record.RememberMe
+
+	live := session.AccessTokens[:0]
+	for _, token := range session.AccessTokens {
+		if token.ExpiresAt.After(now) {
+			live = append(live, token)
+		}
+	}
+	session.AccessTokens = live
+	if accessToken != "" {
+		if claims, err := s.tokens.ValidateToken(accessToken); err == nil && claims.ID != "" {
+			session.AccessTokens = append(session.AccessTokens, domain.SessionToken{
+				ID:        claims.ID,
+				ExpiresAt: time.Unix(claims.Exp, 0).UTC(),
+			})
+		}
+	}
+
+	return s.repo.Save(ctx, session)
+}
+
+// End revokes the live access tokens of the session and deletes it
+func (s *sessionService) End(ctx context.Context, userID, familyID string) error {
+	session, err := s.repo.GetByID(ctx, userID, familyID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.end(ctx, session)
+}
+
+// ListSessions returns the sessions that can still refresh. Sessions started
+// before their user's tokens were all revoked are left out.
+func (s *sessionService) ListSessions(ctx context.Context, userID, currentTokenID string) ([]*domain.Session, error) {
+	stored, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	sessions := make([]*domain.Session, 0, len(stored))
+	for _, session := range stored {
+		if !session.ExpiresAt.After(now) {
+			continue
+		}
+		revoked, err := s.revokedWithUser(ctx, session)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			continue
+		}
+		session.Current = currentTokenID != "" && session.HasAccessToken(currentTokenID)
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one session of the user
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.repo.GetByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if err := s.refreshTokens.RevokeFamily(ctx, session.ID, s.ttl); err != nil {
+		return err
+	}
+	if err := s.end(ctx, session); err != nil {
+		return err
+	}
+
+	s.logger.ForService("session", "revoke").Info("Session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// RevokeOtherSessions ends the sessions of the user that do not hold the
+// current access token
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID, currentTokenID string) (int, error) {
+	sessions, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.HasAccessToken(currentTokenID) {
+			continue
+		}
+		if err := s.refreshTokens.RevokeFamily(ctx, session.ID, s.ttl); err != nil {
+			return revoked, err
+		}
+		if err := s.end(ctx, session); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+
+	s.logger.ForService("session", "revoke_others").Info("Other sessions revoked", "user_id", userID, "count", revoked)
+	return revoked, nil
+}
+
+// Helper methods
+
+// end denies the session's unexpired access tokens and deletes it
+func (s *sessionService) end(ctx context.Context, session *domain.Session) error {
+	now := s.now()
+	for _, token := range session.AccessTokens {
+		if !token.ExpiresAt.After(now) {
+			continue
+		}
+		claims := &domain.TokenClaims{ID: token.ID, UserID: session.UserID, Exp: token.ExpiresAt.Unix()}
+		if err := s.revocations.RevokeToken(ctx, claims); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Delete(ctx, session.UserID, session.ID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// revokedWithUser reports whether the session started before a revocation of
+// every token of its user
+func (s *sessionService) revokedWithUser(ctx context.Context, session *domain.Session) (bool, error) {
+	return s.revocations.IsRevoked(ctx, &domain.TokenClaims{UserID: session.UserID, Iat: session.CreatedAt.Unix()})
+}
+
+// Browsers and systems recognized in user agents, most specific first, since
+// e.g. Edge also claims to be Chrome and Chrome to be Safari
+var (
+	sessionBrowsers = []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"}, {"Safari/", "Safari"}, {"curl/", "curl"},
+	}
+	sessionSystems = []struct{ token, name string }{
+		{"iPhone", "iOS"}, {"iPad", "iPadOS"}, {"Android", "Android"},
+		{"Windows", "Windows"}, {"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	}
+)
+
+// describeDevice names the browser and system of a user agent, e.g. "Firefox
+// on Windows", so users can tell their sessions apart
+func describeDevice(userAgent string) string {
+	browser, system := "", ""
+	for _, candidate := range sessionBrowsers {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+	for _, candidate := range sessionSystems {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "Unknown device"
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// snapshotPageSize is how many users are read per repository call while
+// taking or restoring a snapshot
+const snapshotPageSize = 500
+
+// snapshotService implements domain.SnapshotService
+type snapshotService struct {
+	store        domain.SnapshotStore
+	userRepo     domain.UserRepository
+	auditService domain.AuditService
+	userCache    cache.Service
+	config       config.SnapshotConfig
+	logger       *logger.Logger
+	now          func() time.Time
+}
+
+// NewSnapshotService creates a users snapshot service. After a restore every
+// cached user, list and stats entry in userCache is dropped.
+func NewSnapshotService(
+	store domain.SnapshotStore,
+	userRepo domain.UserRepository,
+	auditService domain.AuditService,
+	userCache cache.Service,
+	cfg config.SnapshotConfig,
+) domain.SnapshotService {
+	return &snapshotService{
+		store:        store,
+		userRepo:     userRepo,
+		auditService: auditService,
+		userCache:    userCache,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("snapshot-service"),
+		now:          time.Now,
+	}
+}
+
+// Create dumps every user into a new snapshot named after the current time
+func (s *snapshotService) Create(ctx context.Context, actorID string) (*domain.SnapshotInfo, error) {
+	log := s.logger.ForService("snapshot", "create").WithField("actor_id", actorID)
+
+	users, err := s.allUsers(ctx, nil)
+	if err != nil {
+		log.Error("Failed to load users for snapshot", "error", err)
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	snapshot := &domain.UserSnapshot{
+		SnapshotInfo: domain.SnapshotInfo{
+			Name:      "users-" + now.Format("20060102T150405.000Z"),
+			Version:   domain.UserSnapshotVersion,
+			Source:    s.config.Source,
+			CreatedBy: actorID,
+			CreatedAt: now,
+			UserCount: len(users),
+		},
+		Users: make([]*domain.ExportedUser, 0, len(users)),
+	}
+	for _, user := range users {
+		snapshot.Users = append(snapshot.Users, exportUser(user, nil, nil))
+	}
+	if snapshot.Checksum, err = snapshotChecksum(snapshot.Users); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := s.store.Put(ctx, snapshot.Name, data); err != nil {
+		log.Error("Failed to store snapshot", "name", snapshot.Name, "error", err)
+		return nil, err
+	}
+
+	s.recordAudit(ctx, domain.AuditActionSnapshotCreated, actorID, &snapshot.SnapshotInfo, nil)
+	log.Info("Snapshot created", "name", snapshot.Name, "users", snapshot.UserCount)
+	return &snapshot.SnapshotInfo, nil
+}
+
+// List returns the names of the stored snapshots, oldest first
+func (s *snapshotService) List(ctx context.Context) ([]string, error) {
+	return s.store.List(ctx)
+}
+
+// Get returns a verified snapshot's description
+func (s *snapshotService) Get(ctx context.Context, name string) (*domain.SnapshotInfo, error) {
+	snapshot, err := s.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot.SnapshotInfo, nil
+}
+
+// PreviewRestore verifies the snapshot and issues a confirmation token
+func (s *snapshotService) PreviewRestore(ctx context.Context, actorID, name string) (*domain.SnapshotRestorePreview, error) {
+	snapshot, err := s.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.userRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.now().Add(s.config.ConfirmTTL).UTC().Truncate(time.Second)
+	return &domain.SnapshotRestorePreview{
+		Snapshot:          snapshot.SnapshotInfo,
+		CurrentUsers:      int(current),
+		ConfirmationToken: s.confirmationToken(actorID, &snapshot.SnapshotInfo, expiresAt),
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// Restore replaces the users collection with the snapshot's users: users
+// missing from the snapshot are deleted first, then every snapshot user is
+// created or overwritten. The writes are not transactional; a failure midway
+// leaves a partial restore that can be completed by restoring again.
+func (s *snapshotService) Restore(
+	ctx context.Context,
+	actorID, name, confirmationToken string,
+) (*domain.SnapshotRestoreResult, error) {
+	log := s.logger.ForService("snapshot", "restore").WithField("actor_id", actorID).WithField("name", name)
+
+	snapshot, err := s.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyConfirmation(confirmationToken, actorID, &snapshot.SnapshotInfo); err != nil {
+		log.Warn("Rejected snapshot restore confirmation")
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(snapshot.Users))
+	for _, exported := range snapshot.Users {
+		keep[exported.ID] = true
+	}
+	current, err := s.allUsers(ctx, []string{"id"})
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, user := range current {
+		if !keep[user.ID] {
+			stale = append(stale, user.ID)
+		}
+	}
+
+	result := &domain.SnapshotRestoreResult{Snapshot: snapshot.SnapshotInfo}
+	for start := 0; start < len(stale); start += snapshotPageSize {
+		end := start + snapshotPageSize
+		if end > len(stale) {
+			end = len(stale)
+		}
+		deleted, err := s.userRepo.DeleteMany(ctx, stale[start:end])
+		if err != nil {
+			log.Error("Failed to delete users missing from snapshot", "error", err)
+			return nil, err
+		}
+		result.Deleted += deleted
+	}
+
+	for _, exported := range snapshot.Users {
+		user := importUser(exported)
+		_, err := s.userRepo.GetByID(ctx, user.ID)
+		switch err {
+		case nil:
+			err = s.userRepo.Update(ctx, user.ID, user)
+			result.Updated++
+		case domain.ErrUserNotFound:
+			err = s.userRepo.Create(ctx, user)
+			result.Created++
+		}
+		if err != nil {
+			log.Error("Failed to restore user", "user_id", user.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	s.invalidateCache(ctx)
+	s.recordAudit(ctx, domain.AuditActionSnapshotRestored, actorID, &snapshot.SnapshotInfo, result)
+	log.Warn("Users restored from snapshot", "created", result.Created, "updated", result.Updated, "deleted", result.Deleted)
+	return result, nil
+}
+
+// load reads a snapshot and checks its version, user count and checksum
+func (s *snapshotService) load(ctx context.Context, name string) (*domain.UserSnapshot, error) {
+	data, err := s.store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot domain.UserSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, &domain.Error{Code: domain.ErrInvalidSnapshot.Code, Message: "Snapshot is not valid JSON"}
+	}
+	if snapshot.Version != domain.UserSnapshotVersion {
+		return nil, &domain.Error{
+			Code:    domain.ErrInvalidSnapshot.Code,
+			Message: fmt.Sprintf("Unsupported snapshot version %d", snapshot.Version),
+		}
+	}
+
+	checksum, err := snapshotChecksum(snapshot.Users)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != snapshot.Checksum || len(snapshot.Users) != snapshot.UserCount {
+		return nil, &domain.Error{Code: domain.ErrInvalidSnapshot.Code, Message: "Snapshot checksum does not match its users"}
+	}
+	return &snapshot, nil
+}
+
+// allUsers reads every user in a stable order, loading only fields when given
+func (s *snapshotService) allUsers(ctx context.Context, fields []string) ([]*domain.User, error) {
+	query := &domain.UserQuery{
+		Sort:      []domain.SortField{{Field: "created_at"}, {Field: "email"}},
+		Limit:     snapshotPageSize,
+		Fields:    fields,
+		SkipTotal: true,
+	}
+
+	var users []*domain.User
+	for {
+		page, _, err := s.userRepo.Search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+		if len(page) < query.Limit {
+			return users, nil
+		}
+		query.Offset += len(page)
+	}
+}
+
+// confirmationToken binds a restore to the actor, the snapshot and its
+// checksum until expiresAt, as "<expiry unix>.<hex HMAC-SHA256>"
+func (s *snapshotService) confirmationToken(actorID string, info *domain.SnapshotInfo, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(s.config.ConfirmationKey))
+	mac.Write([]byte(strings.Join([]string{"snapshot-restore", actorID, info.Name, info.Checksum, expiry}, "|")))
+	return expiry + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *snapshotService) verifyConfirmation(token, actorID string, info *domain.SnapshotInfo) error {
+	expiry, _, found := strings.Cut(token, ".")
+	if !found {
+		return domain.ErrInvalidConfirmation
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return domain.ErrInvalidConfirmation
+	}
+
+	expiresAt := time.Unix(unix, 0)
+	expected := s.confirmationToken(actorID, info, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(token)) || !s.now().Before(expiresAt) {
+		return domain.ErrInvalidConfirmation
+	}
+	return nil
+}
+
+// invalidateCache drops cached users, lists and stats, which may all describe
+// users that were just replaced
+func (s *snapshotService) invalidateCache(ctx context.Context) {
+	if s.userCache == nil {
+		return
+	}
+	for _, pattern := range []string{"user:*", "users:list:*", "stats:*"} {
+		if err := s.userCache.DeleteByPattern(ctx, pattern); err != nil {
+			s.logger.Warn("Failed to invalidate cache after restore", "pattern", pattern, "error", err)
+		}
+	}
+}
+
+func (s *snapshotService) recordAudit(
+	ctx context.Context,
+	action, actorID string,
+	info *domain.SnapshotInfo,
+	result *domain.SnapshotRestoreResult,
+) {
+	if s.auditService == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"snapshot":   info.Name,
+		"user_count": info.UserCount,
+		"checksum":   info.Checksum,
+	}
+	if result != nil {
+		details["created"] = result.Created
+		details["updated"] = result.Updated
+		details["deleted"] = result.Deleted
+	}
+	// A failed audit write is logged by the audit service, not returned
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{Action: action, ActorID: actorID, Details: details})
+}
+
+// snapshotChecksum returns the hex SHA-256 of the JSON encoded users
+func snapshotChecksum(users []*domain.ExportedUser) (string, error) {
+	payload, err := json.Marshal(users)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot users: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// EmailKindSuspiciousLogin is the kind of the emails about flagged logins
+const EmailKindSuspiciousLogin = "suspicious_login"
+
+// suspiciousLoginEmailTimeout bounds sending one notification email
+const suspiciousLoginEmailTimeout = 30 * time.Second
+
+// suspiciousLoginDetector records logins and flags successful ones from a
+// country or device not seen in the user's recent logins
+type suspiciousLoginDetector struct {
+	domain.LoginHistoryService
+	repo   domain.LoginHistoryRepository
+	users  domain.UserRepository
+	events domain.SecurityEventService
+	sender EmailSender
+	config config.SuspiciousLoginConfig
+	logger *logger.Logger
+}
+
+// NewSuspiciousLoginDetector wraps history so every successful login it
+// records is compared with the user's earlier ones in repo. Flagged logins
+// are recorded as login.suspicious security events and, with cfg.Notify,
+// emailed to the user through sender.
+func NewSuspiciousLoginDetector(
+	history domain.LoginHistoryService,
+	repo domain.LoginHistoryRepository,
+	users domain.UserRepository,
+	events domain.SecurityEventService,
+	sender EmailSender,
+	cfg config.SuspiciousLoginConfig,
+) domain.LoginHistoryService {
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = 20
+	}
+
+	return &suspiciousLoginDetector{
+		LoginHistoryService: history,
+		repo:                repo,
+		users:               users,
+		events:              events,
+		sender:              sender,
+		config:              cfg,
+		logger:              logger.GetGlobal().ForComponent("suspicious-login"),
+	}
+}
+
+// Record stores the attempt, then checks it when it is a successful login
+func (d *suspiciousLoginDetector) Record(ctx context.Context, attempt *domain.LoginAttempt) error {
+	if err := d.LoginHistoryService.Record(ctx, attempt); err != nil {
+		return err
+	}
+	if attempt.Outcome != domain.LoginOutcomeSuccess {
+		return nil
+	}
+
+	flags, err := d.flags(ctx, attempt)
+	if err != nil {
+		d.logger.Warn("Failed to check login for anomalies", "user_id", attempt.UserID, "error", err)
+		return nil
+	}
+	if len(flags) == 0 {
+		return nil
+	}
+
+	d.events.Record(ctx, &domain.SecurityEvent{
+		Type:     domain.SecurityEventSuspiciousLogin,
+		Severity: domain.SecuritySeverityMedium,
+		Flags:    flags,
+		ActorID:  attempt.UserID,
+		TargetID: attempt.UserID,
+		ClientIP: attempt.IPAddress,
+		Details: map[string]interface{}{
+			"method":  attempt.Method,
+			"device":  attempt.Device,
+			"country": attempt.Country,
+		},
+	})
+	if d.config.Notify {
+		d.notify(ctx, attempt)
+	}
+	return nil
+}
+
+// flags compares the login with the user's earlier successful logins among
+// the last Lookback recorded ones
+func (d *suspiciousLoginDetector) flags(ctx context.Context, attempt *domain.LoginAttempt) ([]string, error) {
+	recent, err := d.repo.ListByUser(ctx, attempt.UserID, d.config.Lookback+1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var earlier int
+	knownCountry, knownDevice, countriesSeen := false, false, false
+	for _, previous := range recent {
+		if previous.ID == attempt.ID || previous.Outcome != domain.LoginOutcomeSuccess {
+			continue
+		}
+		earlier++
+		if previous.Country != "" {
+			countriesSeen = true
+			knownCountry = knownCountry || previous.Country == attempt.Country
+		}
+		knownDevice = knownDevice || previous.Device == attempt.Device
+	}
+	if earlier == 0 || earlier < d.config.MinHistory {
+		return nil, nil
+	}
+
+	var flags []string
+	// Countries are only compared when both logins know theirs
+	if d.config.NewCountry && attempt.Country != "" && countriesSeen && !knownCountry {
+		flags = append(flags, domain.SecurityFlagNewCountry)
+	}
+	if d.config.NewDevice && !knownDevice {
+		flags = append(flags, domain.SecurityFlagNewDevice)
+	}
+	return flags, nil
+}
+
+// notify emails the user about a flagged login in the background
+func (d *suspiciousLoginDetector) notify(ctx context.Context, attempt *domain.LoginAttempt) {
+	user, err := d.users.GetByID(ctx, attempt.UserID)
+	if err != nil {
+		d.logger.Warn("Failed to load user to notify of a suspicious login", "user_id", attempt.UserID, "error", err)
+		return
+	}
+
+	where := attempt.Device
+	if attempt.Country != "" {
+		where += " in " + attempt.Country
+	}
+	message := &EmailMessage{
+		Kind:    EmailKindSuspiciousLogin,
+		To:      user.Email,
+		Subject: "New login to your account",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nYour account was just logged in to from %s (IP address %s) at %s.\n\n"+
+				"If this was you, you can ignore this email. If not, change your password and sign out your other sessions.\n",
+			user.Name, where, attempt.IPAddress, attempt.CreatedAt.UTC().Format(time.RFC1123),
+		),
+	}
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), suspiciousLoginEmailTimeout)
+		defer cancel()
+		if err := d.sender.Send(sendCtx, message); err != nil {
+			d.logger.Error("Failed to send suspicious login email", "user_id", user.ID, "error", err)
+		}
+	}()
+}
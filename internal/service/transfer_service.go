@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// transferService implements domain.AccountTransferService
+type transferService struct {
+	userRepo     domain.UserRepository
+	prefsRepo    domain.PreferencesRepository
+	notesRepo    domain.NoteRepository
+	auditService domain.AuditService
+	userCache    cache.Service
+	config       config.TransferConfig
+	logger       *logger.Logger
+}
+
+// NewTransferService creates an account export/import service. Imported users
+// are evicted from userCache so cached copies do not outlive an overwrite.
+// Internal notes on users are carried in bundles unless notesRepo is nil.
+func NewTransferService(
+	userRepo domain.UserRepository,
+	prefsRepo domain.PreferencesRepository,
+	notesRepo domain.NoteRepository,
+	auditService domain.AuditService,
+	userCache cache.Service,
+	cfg config.TransferConfig,
+) domain.AccountTransferService {
+	return &transferService{
+		userRepo:     userRepo,
+		prefsRepo:    prefsRepo,
+		notesRepo:    notesRepo,
+		auditService: auditService,
+		userCache:    userCache,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("transfer-service"),
+	}
+}
+
+// Export builds a signed bundle of the requested users
+func (s *transferService) Export(
+	ctx context.Context,
+	actorID string,
+	req *domain.ExportUsersRequest,
+) (*domain.UserBundle, error) {
+	log := s.logger.ForService("transfer", "export").WithField("actor_id", actorID)
+
+	if err := s.validateCount(len(req.IDs)); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.GetByIDs(ctx, req.IDs)
+	if err != nil {
+		log.Error("Failed to load users for export", "error", err)
+		return nil, err
+	}
+	found := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		found[user.ID] = user
+	}
+	for _, id := range req.IDs {
+		if found[id] == nil {
+			return nil, &domain.Error{Code: domain.ErrUserNotFound.Code, Message: "User not found: " + id}
+		}
+	}
+
+	prefs, err := s.prefsRepo.GetMany(ctx, req.IDs)
+	if err != nil {
+		log.Error("Failed to load preferences for export", "error", err)
+		return nil, err
+	}
+
+	notes := map[string][]*domain.UserNote{}
+	if s.notesRepo != nil {
+		if notes, err = s.notesRepo.ListByUsers(ctx, req.IDs); err != nil {
+			log.Error("Failed to load notes for export", "error", err)
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	bundle := &domain.UserBundle{
+		Version:    domain.UserBundleVersion,
+		Source:     s.config.Source,
+		ExportedBy: actorID,
+		ExportedAt: now,
+		ExpiresAt:  now.Add(s.config.BundleTTL),
+		Users:      make([]*domain.ExportedUser, 0, len(users)),
+	}
+	for _, user := range users {
+		bundle.Users = append(bundle.Users, exportUser(user, prefs[user.ID], notes[user.ID]))
+	}
+	if bundle.Signature, err = s.sign(bundle); err != nil {
+		return nil, err
+	}
+
+	events := make([]*domain.AuditEvent, 0, len(users))
+	for _, user := range users {
+		events = append(events, &domain.AuditEvent{
+			Action:   domain.AuditActionUserExported,
+			ActorID:  actorID,
+			TargetID: user.ID,
+			Details:  map[string]interface{}{"email": user.Email},
+		})
+	}
+	s.recordAudit(ctx, events)
+
+	log.Info("Users exported", "count", len(users))
+	return bundle, nil
+}
+
+// Import verifies a bundle and creates its users, resolving existing IDs and
+// emails according to the conflict mode
+func (s *transferService) Import(
+	ctx context.Context,
+	actorID string,
+	req *domain.ImportUsersRequest,
+) ([]domain.ImportItemResult, error) {
+	log := s.logger.ForService("transfer", "import").WithField("actor_id", actorID)
+
+	mode := req.Conflict
+	if mode == "" {
+		mode = domain.ImportConflictSkip
+	}
+	switch mode {
+	case domain.ImportConflictSkip, domain.ImportConflictOverwrite, domain.ImportConflictFail:
+	default:
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Conflict must be skip, overwrite or fail"}
+	}
+	if err := s.verify(req.Bundle); err != nil {
+		log.Warn("Rejected import bundle", "error", err)
+		return nil, err
+	}
+	if err := s.validateCount(len(req.Bundle.Users)); err != nil {
+		return nil, err
+	}
+
+	// Work out every outcome before writing anything
+	results := make([]domain.ImportItemResult, len(req.Bundle.Users))
+	conflicts := 0
+	for i, exported := range req.Bundle.Users {
+		results[i] = s.planImport(ctx, exported, mode)
+		if results[i].Outcome == domain.ImportOutcomeConflict {
+			conflicts++
+		}
+	}
+	if mode == domain.ImportConflictFail && conflicts > 0 {
+		return nil, domain.ErrImportConflict
+	}
+	if req.DryRun {
+		return results, nil
+	}
+
+	events := make([]*domain.AuditEvent, 0, len(results))
+	for i, exported := range req.Bundle.Users {
+		result := &results[i]
+		if result.Outcome != domain.ImportOutcomeCreated && result.Outcome != domain.ImportOutcomeOverwritten {
+			continue
+		}
+		if err := s.applyImport(ctx, exported, result.Outcome); err != nil {
+			log.Warn("Failed to import user", "user_id", exported.ID, "error", err)
+			result.Outcome = domain.ImportOutcomeConflict
+			result.Error = errorCode(err)
+			continue
+		}
+		events = append(events, &domain.AuditEvent{
+			Action:   domain.AuditActionUserImported,
+			ActorID:  actorID,
+			TargetID: exported.ID,
+			Details: map[string]interface{}{
+				"email":   exported.Email,
+				"source":  req.Bundle.Source,
+				"outcome": result.Outcome,
+			},
+		})
+	}
+	s.recordAudit(ctx, events)
+
+	log.Info("Users imported", "count", len(events), "source", req.Bundle.Source)
+	return results, nil
+}
+
+// planImport decides what importing one user would do
+func (s *transferService) planImport(ctx context.Context, exported *domain.ExportedUser, mode string) domain.ImportItemResult {
+	result := domain.ImportItemResult{ID: exported.ID, Email: exported.Email}
+	if exported.ID == "" || exported.Email == "" || exported.PasswordHash == "" {
+		result.Outcome = domain.ImportOutcomeConflict
+		result.Error = domain.ErrValidationFailed.Code
+		return result
+	}
+
+	byID, err := s.userRepo.GetByID(ctx, exported.ID)
+	if err != nil && err != domain.ErrUserNotFound {
+		result.Outcome = domain.ImportOutcomeConflict
+		result.Error = errorCode(err)
+		return result
+	}
+	byEmail, err := s.userRepo.GetByEmail(ctx, exported.Email)
+	if err != nil && err != domain.ErrUserNotFound {
+		result.Outcome = domain.ImportOutcomeConflict
+		result.Error = errorCode(err)
+		return result
+	}
+
+	switch {
+	case byID == nil && byEmail == nil:
+		result.Outcome = domain.ImportOutcomeCreated
+	case byEmail != nil && byEmail.ID != exported.ID:
+		// The email belongs to a different account; overwriting cannot fix that
+		result.Outcome = domain.ImportOutcomeConflict
+		result.Error = domain.ErrUserAlreadyExists.Code
+	case mode == domain.ImportConflictOverwrite:
+		result.Outcome = domain.ImportOutcomeOverwritten
+	case mode == domain.ImportConflictSkip:
+		result.Outcome = domain.ImportOutcomeSkipped
+	default:
+		result.Outcome = domain.ImportOutcomeConflict
+		result.Error = domain.ErrUserAlreadyExists.Code
+	}
+	return result
+}
+
+// applyImport writes one planned user with its preferences and notes
+func (s *transferService) applyImport(ctx context.Context, exported *domain.ExportedUser, outcome string) error {
+	user := importUser(exported)
+
+	var err error
+	if outcome == domain.ImportOutcomeCreated {
+		err = s.userRepo.Create(ctx, user)
+	} else {
+		err = s.userRepo.Update(ctx, user.ID, user)
+	}
+	if err != nil {
+		return err
+	}
+
+	if exported.Preferences != nil {
+		prefs := *exported.Preferences
+		prefs.UserID = user.ID
+		prefs.UpdatedAt = time.Now().UTC()
+		if err := s.prefsRepo.Upsert(ctx, &prefs); err != nil {
+			return err
+		}
+	}
+
+	if exported.Notes != nil && s.notesRepo != nil {
+		if err := s.notesRepo.ReplaceForUser(ctx, user.ID, exported.Notes); err != nil {
+			return err
+		}
+	}
+
+	if s.userCache != nil {
+		if err := s.userCache.DeleteUser(ctx, user.ID); err != nil {
+			s.logger.Warn("Failed to evict imported user from cache", "user_id", user.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *transferService) validateCount(n int) error {
+	if n == 0 {
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: "At least one user is required"}
+	}
+	if s.config.MaxUsers > 0 && n > s.config.MaxUsers {
+		return &domain.Error{
+			Code:    "VALIDATION_FAILED",
+			Message: fmt.Sprintf("At most %d users can be transferred at once", s.config.MaxUsers),
+		}
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of the bundle without its signature
+func (s *transferService) sign(bundle *domain.UserBundle) (string, error) {
+	unsigned := *bundle
+	unsigned.Signature = ""
+	payload, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bundle: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.config.SigningKey))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify checks the bundle's format, signature and expiry
+func (s *transferService) verify(bundle *domain.UserBundle) error {
+	if bundle == nil || bundle.Version != domain.UserBundleVersion {
+		return &domain.Error{Code: domain.ErrInvalidBundle.Code, Message: "Unsupported or missing bundle"}
+	}
+
+	expected, err := s.sign(bundle)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(bundle.Signature))) {
+		return domain.ErrInvalidBundle
+	}
+	if time.Now().After(bundle.ExpiresAt) {
+		return &domain.Error{Code: domain.ErrInvalidBundle.Code, Message: "Bundle has expired"}
+	}
+	return nil
+}
+
+func (s *transferService) recordAudit(ctx context.Context, events []*domain.AuditEvent) {
+	if s.auditService == nil || len(events) == 0 {
+		return
+	}
+	// A failed audit write is logged by the audit service, not returned
+	_ = s.auditService.Record(ctx, events...)
+}
+
+func exportUser(user *domain.User, prefs *domain.UserPreferences, notes []*domain.UserNote) *domain.ExportedUser {
+	exported := &domain.ExportedUser{
+		ID:           user.ID,
+		Name:         user.Name,
+		Email:        user.Email,
+		PasswordHash: user.Password,
+		Role:         user.Role,
+		Status:       user.Status,
+		CreatedAt:    user.CreatedAt.UTC(),
+		UpdatedAt:    user.UpdatedAt.UTC(),
+		Preferences:  prefs,
+		Notes:        notes,
+	}
+	for _, question := range user.SecurityQuestions {
+		exported.SecurityQuestions = append(exported.SecurityQuestions, domain.ExportedSecurityQuestion{
+			Question:   question.Question,
+			AnswerHash: question.AnswerHash,
+		})
+	}
+	return exported
+}
+
+func importUser(exported *domain.ExportedUser) *domain.User {
+	user := &domain.User{
+		ID:        exported.ID,
+		Name:      exported.Name,
+		Email:     exported.Email,
+		Password:  exported.PasswordHash,
+		Role:      exported.Role,
+		Status:    exported.Status,
+		CreatedAt: exported.CreatedAt,
+		UpdatedAt: exported.UpdatedAt,
+	}
+	for _, question := range exported.SecurityQuestions {
+		user.SecurityQuestions = append(user.SecurityQuestions, domain.SecurityQuestion{
+			Question:   question.Question,
+			AnswerHash: question.AnswerHash,
+		})
+	}
+	return user
+}
+
+// errorCode reports the domain code of err, or INTERNAL_ERROR
+func errorCode(err error) string {
+	if domainErr, ok := err.(*domain.Error); ok {
+		return domainErr.Code
+	}
+	return "INTERNAL_ERROR"
+}
@@ -4,13 +4,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"demo-go/internal/config"
 	"demo-go/internal/domain"
 	"demo-go/internal/logger"
-
-	"golang.org/x/crypto/bcrypt"
+	"demo-go/internal/passwordhash"
+	"demo-go/internal/passwordpolicy"
 )
 
 // Service limits and constants
@@ -19,24 +22,128 @@ const (
 	MaxPageLimit     = 100
 	MinNameLength    = 2
 	MaxNameLength    = 100
-	MinPasswordLen   = 6
-	BCryptCost       = 10
+	MinPasswordLen   = config.DefaultPasswordMinLength
+	BCryptCost       = config.DefaultBcryptCost
+	MaxBulkItems     = 100
+	MaxStatusReason  = 500
 )
 
 // userService implements domain.UserService
 type userService struct {
 	userRepo     domain.UserRepository
 	tokenService domain.TokenService
+	auditService domain.AuditService
+	namePolicy   domain.ContentPolicy
+	emailPolicy  domain.EmailDomainPolicy
+	passwords    domain.PasswordPolicy
+	hasher       domain.PasswordHasher
+	roles        domain.RoleCatalog
 	logger       *logger.Logger
+	maxPageLimit int
+
+	// Role given to users who register without one, and the roles they may pick themselves
+	defaultRole         string
+	selfAssignableRoles map[string]bool
+}
+
+// UserServiceOption configures optional user service dependencies
+type UserServiceOption func(*userService)
+
+// WithAuditService records administrative actions through the given audit service
+func WithAuditService(auditService domain.AuditService) UserServiceOption {
+	return func(s *userService) {
+		s.auditService = auditService
+	}
+}
+
+// WithNamePolicy screens names on registration and profile updates
+func WithNamePolicy(policy domain.ContentPolicy) UserServiceOption {
+	return func(s *userService) {
+		s.namePolicy = policy
+	}
+}
+
+// WithEmailDomainPolicy screens the email domains of new accounts and of
+// email changes
+func WithEmailDomainPolicy(policy domain.EmailDomainPolicy) UserServiceOption {
+	return func(s *userService) {
+		s.emailPolicy = policy
+	}
+}
+
+// WithPasswordPolicy checks new passwords on registration and password
+// changes. Without it passwords only need MinPasswordLen characters.
+func WithPasswordPolicy(policy domain.PasswordPolicy) UserServiceOption {
+	return func(s *userService) {
+		if policy != nil {
+			s.passwords = policy
+		}
+	}
+}
+
+// WithPasswordHasher hashes new passwords with hasher. Without it they are
+// hashed with bcrypt at BCryptCost.
+func WithPasswordHasher(hasher domain.PasswordHasher) UserServiceOption {
+	return func(s *userService) {
+		if hasher != nil {
+			s.hasher = hasher
+		}
+	}
+}
+
+// WithRolePolicy sets the default role and the roles users may assign
+// themselves on registration or profile update. Without it new users get
+// "user" and no other role can be self-assigned.
+func WithRolePolicy(cfg config.RolesConfig) UserServiceOption {
+	return func(s *userService) {
+		if cfg.Default != "" {
+			s.defaultRole = cfg.Default
+		}
+		s.selfAssignableRoles = make(map[string]bool, len(cfg.SelfAssignable))
+		for _, role := range cfg.SelfAssignable {
+			s.selfAssignableRoles[role] = true
+		}
+	}
+}
+
+// WithRoleCatalog only lets admins assign roles that exist in the catalog.
+// Without it any role name can be assigned.
+func WithRoleCatalog(catalog domain.RoleCatalog) UserServiceOption {
+	return func(s *userService) {
+		s.roles = catalog
+	}
+}
+
+// WithMaxPageLimit caps the page size of user lists, overriding MaxPageLimit
+func WithMaxPageLimit(limit int) UserServiceOption {
+	return func(s *userService) {
+		if limit > 0 {
+			s.maxPageLimit = limit
+		}
+	}
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo domain.UserRepository, tokenService domain.TokenService) domain.UserService {
-	return &userService{
+func NewUserService(
+	userRepo domain.UserRepository,
+	tokenService domain.TokenService,
+	opts ...UserServiceOption,
+) domain.UserService {
+	s := &userService{
 		userRepo:     userRepo,
 		tokenService: tokenService,
+		passwords:    defaultPasswordPolicy(),
+		hasher:       defaultPasswordHasher(),
 		logger:       logger.GetGlobal().ForComponent("user-service"),
+		maxPageLimit: MaxPageLimit,
+
+		defaultRole:         "user",
+		selfAssignableRoles: map[string]bool{"user": true},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Register creates a new user account
@@ -46,7 +153,7 @@ func (s *userService) Register(ctx context.Context, req *domain.CreateUserReques
 	log.Debug("Starting user registration")
 
 	// Validate request
-	if err := s.validateCreateUserRequest(req); err != nil {
+	if err := s.validateCreateUserRequest(ctx, req); err != nil {
 		log.Warn("User registration validation failed", "error", err)
 		return nil, err
 	}
@@ -72,9 +179,9 @@ func (s *userService) Register(ctx context.Context, req *domain.CreateUserReques
 	}
 
 	// Set default role if not provided
-	role := req.Role
+	role := strings.TrimSpace(req.Role)
 	if role == "" {
-		role = "user"
+		role = s.defaultRole
 	}
 
 	log.Debug("Creating user entity", "role", role)
@@ -127,6 +234,13 @@ func (s *userService) Login(ctx context.Context, req *domain.LoginRequest) (stri
 		return "", nil, domain.ErrInvalidCredentials
 	}
 
+	if user.IsSuspended() || user.IsBanned() {
+		log.Warn("Login attempt for "+user.Status+" account", "user_id", user.ID)
+		return "", nil, selfAccessError(user.Status)
+	}
+
+	s.upgradePasswordHash(ctx, user, req.Password, log)
+
 	// Generate token
 	token, err := s.tokenService.GenerateToken(user)
 	if err != nil {
@@ -142,11 +256,38 @@ func (s *userService) GetProfile(ctx context.Context, userID string) (*domain.Us
 	if err != nil {
 		return nil, err
 	}
+	if err := selfAccessError(user.Status); err != nil {
+		return nil, err
+	}
 
 	return user.ToResponse(), nil
 }
 
-// UpdateProfile updates user profile
+// selfAccessError is returned when a user acts on their own account:
+// deleted accounts are not found and suspended or banned ones are forbidden
+func selfAccessError(status string) error {
+	switch status {
+	case domain.UserStatusDeleted:
+		return domain.ErrUserNotFound
+	case domain.UserStatusSuspended:
+		return domain.ErrAccountSuspended
+	case domain.UserStatusBanned:
+		return domain.ErrAccountBanned
+	}
+	return nil
+}
+
+// adminAccessError is returned when an admin looks up an account; suspended
+// and banned accounts stay visible so they can be reviewed
+func adminAccessError(status string) error {
+	if status == domain.UserStatusDeleted {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateProfile updates the caller's own profile. Role changes are limited to
+// self-assignable roles.
 func (s *userService) UpdateProfile(
 	ctx context.Context,
 	userID string,
@@ -157,61 +298,123 @@ func (s *userService) UpdateProfile(
 	if err != nil {
 		return nil, err
 	}
+	if err := selfAccessError(existingUser.Status); err != nil {
+		return nil, err
+	}
 
-	// Validate update request
-	if err := s.validateUpdateUserRequest(req); err != nil {
+	if req.Role != nil {
+		role := strings.TrimSpace(*req.Role)
+		if role != existingUser.Role && !s.selfAssignableRoles[role] {
+			s.logger.ForService("user", "update-profile").Warn("Rejected self-assigned role", "user_id", userID, "role", role)
+			return nil, domain.ErrRoleNotAllowed
+		}
+	}
+
+	updatedUser, err := s.applyUserUpdate(ctx, existingUser, req)
+	if err != nil {
 		return nil, err
 	}
+	s.recordUserChanges(ctx, userID, existingUser, updatedUser)
 
-	// Prepare updated user
-	updatedUser := *existingUser
+	return updatedUser.ToResponse(), nil
+}
 
-	// Update fields if provided
-	if req.Name != nil {
-		updatedUser.Name = strings.TrimSpace(*req.Name)
+// ChangePassword replaces the caller's password once the current one is
+// confirmed. Outstanding password reset tokens stop working with the old
+// password; revoking existing sessions is up to the caller.
+func (s *userService) ChangePassword(ctx context.Context, userID string, req *domain.ChangePasswordRequest) error {
+	log := s.logger.ForService("user", "change-password").WithField("user_id", userID)
+
+	if req.CurrentPassword == "" {
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Current password is required"}
+	}
+	if err := s.passwords.Check(ctx, "new_password", req.NewPassword); err != nil {
+		return err
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: "New password must differ from the current password"}
 	}
 
-	if req.Email != nil {
-		newEmail := strings.ToLower(strings.TrimSpace(*req.Email))
-		if newEmail != existingUser.Email {
-			// Check if new email already exists
-			_, err := s.userRepo.GetByEmail(ctx, newEmail)
-			if err != nil && err != domain.ErrUserNotFound {
-				return nil, err
-			}
-			if err == nil {
-				return nil, domain.ErrUserAlreadyExists
-			}
-		}
-		updatedUser.Email = newEmail
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := selfAccessError(user.Status); err != nil {
+		return err
+	}
+	if err := s.verifyPassword(user.Password, req.CurrentPassword); err != nil {
+		log.Warn("Password change with wrong current password")
+		return domain.ErrWrongPassword
+	}
+
+	hashedPassword, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		log.Error("Failed to save new password", "error", err)
+		return err
+	}
+
+	log.Info("Password changed")
+	return nil
+}
+
+// UpdateUser updates any user on behalf of an admin, including assigning
+// privileged roles. Admins cannot change their own role.
+func (s *userService) UpdateUser(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.UpdateUserRequest,
+) (*domain.UserResponse, error) {
+	existingUser, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
+	roleChanged := false
 	if req.Role != nil {
-		updatedUser.Role = *req.Role
+		role := strings.TrimSpace(*req.Role)
+		if role == "" {
+			return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "Role must not be empty"}
+		}
+		roleChanged = role != existingUser.Role
+		if roleChanged && id == actorID {
+			return nil, domain.ErrForbidden
+		}
+		if roleChanged && !s.roleExists(role) {
+			return nil, domain.ErrUnknownRole
+		}
 	}
 
-	// Update user
-	if err := s.userRepo.Update(ctx, userID, &updatedUser); err != nil {
+	updatedUser, err := s.applyUserUpdate(ctx, existingUser, req)
+	if err != nil {
 		return nil, err
 	}
+	s.recordUserChanges(ctx, actorID, existingUser, updatedUser)
 
+	if roleChanged && s.auditService != nil {
+		// The update has already been applied; a failed audit write is logged, not returned
+		_ = s.auditService.Record(ctx, &domain.AuditEvent{
+			Action:   domain.AuditActionUserRoleChanged,
+			ActorID:  actorID,
+			TargetID: id,
+			Details:  map[string]interface{}{"from": existingUser.Role, "to": updatedUser.Role},
+		})
+	}
+
+	s.logger.ForService("user", "update-user").Info("User updated by admin", "actor_id", actorID, "user_id", id)
 	return updatedUser.ToResponse(), nil
 }
 
 // GetUsers retrieves all users with pagination
-func (s *userService) GetUsers(ctx context.Context, limit, offset int) ([]*domain.UserResponse, int64, error) {
-	// Set default and max limits
-	if limit <= 0 {
-		limit = DefaultPageLimit
-	}
-	if limit > MaxPageLimit {
-		limit = MaxPageLimit
-	}
-	if offset < 0 {
-		offset = 0
-	}
+func (s *userService) GetUsers(ctx context.Context, limit, offset int, fields ...string) ([]*domain.UserResponse, int64, error) {
+	limit, offset = s.pageBounds(limit, offset)
 
-	users, err := s.userRepo.List(ctx, limit, offset)
+	users, err := s.userRepo.List(ctx, limit, offset, fields...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -230,12 +433,61 @@ func (s *userService) GetUsers(ctx context.Context, limit, offset int) ([]*domai
 	return userResponses, count, nil
 }
 
+// SearchUsers retrieves users matching a filter/sort/search query with pagination
+func (s *userService) SearchUsers(ctx context.Context, query *domain.UserQuery) ([]*domain.UserResponse, int64, error) {
+	q := *query
+	q.Limit, q.Offset = s.pageBounds(q.Limit, q.Offset)
+
+	// Without a count, fetch one extra user to learn whether another page follows
+	pageLimit := q.Limit
+	if q.SkipTotal {
+		q.Limit++
+	}
+
+	users, total, err := s.userRepo.Search(ctx, &q)
+	if err != nil {
+		s.logger.ForService("user", "search").Error("Failed to search users", "error", err)
+		return nil, 0, err
+	}
+
+	if q.SkipTotal {
+		total = int64(q.Offset + len(users))
+		if len(users) > pageLimit {
+			users = users[:pageLimit]
+		}
+	}
+
+	userResponses := make([]*domain.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, user.ToResponse())
+	}
+
+	return userResponses, total, nil
+}
+
+// pageBounds applies the default and maximum page size and rejects negative offsets
+func (s *userService) pageBounds(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > s.maxPageLimit {
+		limit = s.maxPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
 // GetUserByID retrieves a user by ID
 func (s *userService) GetUserByID(ctx context.Context, id string) (*domain.UserResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := adminAccessError(user.Status); err != nil {
+		return nil, err
+	}
 
 	return user.ToResponse(), nil
 }
@@ -251,6 +503,9 @@ func (s *userService) RefreshToken(ctx context.Context, userID string) (string,
 	if err != nil {
 		return "", err
 	}
+	if err := selfAccessError(user.Status); err != nil {
+		return "", err
+	}
 
 	token, err := s.tokenService.GenerateToken(user)
 	if err != nil {
@@ -260,9 +515,377 @@ func (s *userService) RefreshToken(ctx context.Context, userID string) (string,
 	return token, nil
 }
 
+// SetUserStatus suspends, bans or reinstates an account. Admins cannot
+// change their own status, and banned accounts stay banned.
+func (s *userService) SetUserStatus(
+	ctx context.Context,
+	actorID, id string,
+	req *domain.SetUserStatusRequest,
+) (*domain.UserResponse, error) {
+	reason, err := statusReason(req.Status, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if id == actorID {
+		return nil, domain.ErrForbidden
+	}
+
+	existingUser, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := adminAccessError(existingUser.Status); err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := s.applyStatus(ctx, actorID, existingUser, req.Status, reason)
+	if err != nil {
+		return nil, err
+	}
+	return updatedUser.ToResponse(), nil
+}
+
+// statusReason validates the reason given for a status change and returns it
+// trimmed; reinstating an account takes no reason
+func statusReason(status, reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	switch status {
+	case domain.UserStatusSuspended, domain.UserStatusBanned:
+		if reason == "" {
+			return "", &domain.Error{Code: "VALIDATION_FAILED", Message: "A reason is required to " + statusVerb(status) + " an account"}
+		}
+		if len(reason) > MaxStatusReason {
+			return "", &domain.Error{Code: "VALIDATION_FAILED", Message: fmt.Sprintf("Reason must be at most %d characters", MaxStatusReason)}
+		}
+		return reason, nil
+	case domain.UserStatusActive:
+		return "", nil
+	default:
+		return "", &domain.Error{Code: "VALIDATION_FAILED", Message: "Status must be one of active, suspended or banned"}
+	}
+}
+
+// applyStatus moves an account to the status with a validated reason and
+// records the change. Banned accounts stay banned.
+func (s *userService) applyStatus(
+	ctx context.Context,
+	actorID string,
+	existingUser *domain.User,
+	status, reason string,
+) (*domain.User, error) {
+	if existingUser.IsBanned() && status != domain.UserStatusBanned {
+		return nil, domain.ErrUserBanned
+	}
+
+	now := time.Now().UTC()
+	updatedUser := *existingUser
+	updatedUser.Status = status
+	updatedUser.StatusReason = reason
+	updatedUser.StatusChangedAt = &now
+	if status == domain.UserStatusActive {
+		updatedUser.StatusChangedAt = nil
+	}
+	if err := s.userRepo.Update(ctx, existingUser.ID, &updatedUser); err != nil {
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		event := &domain.AuditEvent{
+			Action:   domain.AuditActionUserSuspended,
+			ActorID:  actorID,
+			TargetID: existingUser.ID,
+			Details:  map[string]interface{}{"from": existingUser.Status, "reason": reason},
+		}
+		switch status {
+		case domain.UserStatusBanned:
+			event.Action = domain.AuditActionUserBanned
+		case domain.UserStatusActive:
+			event.Action = domain.AuditActionUserUnsuspended
+			delete(event.Details, "reason")
+		}
+		// The change has already been applied; a failed audit write is logged, not returned
+		_ = s.auditService.Record(ctx, event)
+	}
+
+	s.logger.ForService("user", "set-status").Info("User status changed by admin",
+		"actor_id", actorID, "user_id", existingUser.ID, "from", existingUser.Status, "to", status)
+	return &updatedUser, nil
+}
+
+// statusVerb is the action that gives an account the status
+func statusVerb(status string) string {
+	if status == domain.UserStatusBanned {
+		return "ban"
+	}
+	return "suspend"
+}
+
+// BulkUserAction applies an admin action to many users using batch repository
+// operations and reports the outcome for every requested ID
+func (s *userService) BulkUserAction(
+	ctx context.Context,
+	actorID string,
+	req *domain.BulkUserActionRequest,
+) ([]domain.BulkItemResult, error) {
+	log := s.logger.ForService("user", "bulk-action").WithFields(map[string]interface{}{
+		"action":   req.Action,
+		"actor_id": actorID,
+		"count":    len(req.IDs),
+	})
+
+	if err := s.validateBulkUserActionRequest(req); err != nil {
+		log.Warn("Bulk action validation failed", "error", err)
+		return nil, err
+	}
+
+	existing, err := s.userRepo.GetByIDs(ctx, req.IDs)
+	if err != nil {
+		log.Error("Failed to load users for bulk action", "error", err)
+		return nil, err
+	}
+	found := make(map[string]*domain.User, len(existing))
+	for _, user := range existing {
+		found[user.ID] = user
+	}
+
+	// Work out which IDs the action applies to before touching the store
+	results := make([]domain.BulkItemResult, 0, len(req.IDs))
+	targets := make([]string, 0, len(req.IDs))
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		result := domain.BulkItemResult{ID: id}
+		switch {
+		case seen[id]:
+			result.Error = "DUPLICATE_ID"
+		case found[id] == nil:
+			result.Error = domain.ErrUserNotFound.Code
+		case id == actorID:
+			// Admins cannot lock themselves out through a bulk action
+			result.Error = domain.ErrForbidden.Code
+		case req.Action == domain.BulkActionSuspend && adminAccessError(found[id].Status) != nil:
+			result.Error = domain.ErrUserNotFound.Code
+		case req.Action == domain.BulkActionSuspend && found[id].IsBanned():
+			// Suspending would let the ban be lifted with unsuspend
+			result.Error = domain.ErrUserBanned.Code
+		default:
+			result.Success = true
+			targets = append(targets, id)
+		}
+		seen[id] = true
+		results = append(results, result)
+	}
+
+	applied := len(targets)
+	switch {
+	case len(targets) == 0:
+	case req.Action == domain.BulkActionSuspend:
+		applied = s.suspendEach(ctx, actorID, req.Reason, results, found)
+	default:
+		if err := s.applyBulkAction(ctx, req, targets); err != nil {
+			log.Error("Bulk action failed", "error", err)
+			return nil, err
+		}
+		s.recordBulkAudit(ctx, actorID, req, targets, found)
+	}
+
+	log.Info("Bulk action completed", "applied", applied)
+	return results, nil
+}
+
+// suspendEach suspends the users of the successful results one at a time,
+// through the same transition as SetUserStatus, so each account gets the
+// reason and its own audit event. Users that fail are reported in their
+// result; the number suspended is returned.
+func (s *userService) suspendEach(
+	ctx context.Context,
+	actorID, reason string,
+	results []domain.BulkItemResult,
+	users map[string]*domain.User,
+) int {
+	reason = strings.TrimSpace(reason)
+	suspended := 0
+	for i := range results {
+		if !results[i].Success {
+			continue
+		}
+		if _, err := s.applyStatus(ctx, actorID, users[results[i].ID], domain.UserStatusSuspended, reason); err != nil {
+			s.logger.ForService("user", "bulk-action").Error("Failed to suspend user", "user_id", results[i].ID, "error", err)
+			results[i].Success = false
+			results[i].Error = bulkErrorCode(err)
+			continue
+		}
+		suspended++
+	}
+	return suspended
+}
+
+// bulkErrorCode is the code reported in a bulk result for err
+func bulkErrorCode(err error) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return "INTERNAL_ERROR"
+}
+
+// GetUserStats computes reporting statistics about the user base
+func (s *userService) GetUserStats(ctx context.Context) (*domain.UserStats, error) {
+	now := time.Now().UTC()
+
+	stats, err := s.userRepo.Stats(ctx, now)
+	if err != nil {
+		s.logger.ForService("user", "stats").Error("Failed to compute user statistics", "error", err)
+		return nil, err
+	}
+
+	if stats.DailySignups == nil {
+		stats.DailySignups = []domain.DailyCount{}
+	}
+	stats.ComputedAt = now
+	return stats, nil
+}
+
 // Helper methods
 
-func (s *userService) validateCreateUserRequest(req *domain.CreateUserRequest) error {
+// applyUserUpdate validates the request and saves the changed fields of an existing user
+func (s *userService) applyUserUpdate(
+	ctx context.Context,
+	existingUser *domain.User,
+	req *domain.UpdateUserRequest,
+) (*domain.User, error) {
+	// Validate update request
+	if err := s.validateUpdateUserRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Prepare updated user
+	updatedUser := *existingUser
+
+	// Update fields if provided
+	if req.Name != nil {
+		updatedUser.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Email != nil {
+		newEmail := strings.ToLower(strings.TrimSpace(*req.Email))
+		if newEmail != existingUser.Email {
+			if err := s.checkEmailDomain(newEmail); err != nil {
+				return nil, err
+			}
+			// Check if new email already exists
+			_, err := s.userRepo.GetByEmail(ctx, newEmail)
+			if err != nil && err != domain.ErrUserNotFound {
+				return nil, err
+			}
+			if err == nil {
+				return nil, domain.ErrUserAlreadyExists
+			}
+		}
+		updatedUser.Email = newEmail
+	}
+
+	if req.Role != nil {
+		updatedUser.Role = strings.TrimSpace(*req.Role)
+	}
+
+	// Update user
+	if err := s.userRepo.Update(ctx, existingUser.ID, &updatedUser); err != nil {
+		return nil, err
+	}
+
+	return &updatedUser, nil
+}
+
+// recordUserChanges reports the field changes of an applied update to the
+// request's change set and records them in the audit log
+func (s *userService) recordUserChanges(ctx context.Context, actorID string, before, after *domain.User) {
+	changes := domain.DiffUsers(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	domain.RecordChanges(ctx, changes)
+
+	if s.auditService != nil {
+		// The update has already been applied; a failed audit write is logged, not returned
+		_ = s.auditService.Record(ctx, &domain.AuditEvent{
+			Action:   domain.AuditActionUserUpdated,
+			ActorID:  actorID,
+			TargetID: after.ID,
+			Details:  map[string]interface{}{"changes": changes},
+		})
+	}
+}
+
+func (s *userService) applyBulkAction(ctx context.Context, req *domain.BulkUserActionRequest, ids []string) error {
+	var err error
+	switch req.Action {
+	case domain.BulkActionDelete:
+		_, err = s.userRepo.DeleteMany(ctx, ids)
+	case domain.BulkActionSetRole:
+		_, err = s.userRepo.UpdateMany(ctx, ids, domain.UserFieldUpdate{Role: &req.Role})
+	}
+	return err
+}
+
+func (s *userService) recordBulkAudit(
+	ctx context.Context,
+	actorID string,
+	req *domain.BulkUserActionRequest,
+	ids []string,
+	users map[string]*domain.User,
+) {
+	if s.auditService == nil {
+		return
+	}
+
+	events := make([]*domain.AuditEvent, 0, len(ids))
+	for _, id := range ids {
+		event := &domain.AuditEvent{ActorID: actorID, TargetID: id}
+		switch req.Action {
+		case domain.BulkActionDelete:
+			event.Action = domain.AuditActionUserDeleted
+			event.Details = map[string]interface{}{"email": users[id].Email}
+		case domain.BulkActionSetRole:
+			event.Action = domain.AuditActionUserRoleChanged
+			event.Details = map[string]interface{}{"from": users[id].Role, "to": req.Role}
+		}
+		events = append(events, event)
+	}
+
+	// The action has already been applied; a failed audit write is logged, not returned
+	_ = s.auditService.Record(ctx, events...)
+}
+
+func (s *userService) validateBulkUserActionRequest(req *domain.BulkUserActionRequest) error {
+	if len(req.IDs) == 0 {
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: "At least one user ID is required"}
+	}
+
+	if len(req.IDs) > MaxBulkItems {
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: fmt.Sprintf("At most %d users can be processed at once", MaxBulkItems)}
+	}
+
+	switch req.Action {
+	case domain.BulkActionDelete:
+	case domain.BulkActionSuspend:
+		if _, err := statusReason(domain.UserStatusSuspended, req.Reason); err != nil {
+			return err
+		}
+	case domain.BulkActionSetRole:
+		if strings.TrimSpace(req.Role) == "" {
+			return &domain.Error{Code: "VALIDATION_FAILED", Message: "Role is required for set-role"}
+		}
+		if !s.roleExists(req.Role) {
+			return domain.ErrUnknownRole
+		}
+	default:
+		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Action must be one of delete, suspend or set-role"}
+	}
+
+	return nil
+}
+
+func (s *userService) validateCreateUserRequest(ctx context.Context, req *domain.CreateUserRequest) error {
 	if strings.TrimSpace(req.Name) == "" {
 		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Name is required"}
 	}
@@ -279,11 +902,48 @@ func (s *userService) validateCreateUserRequest(req *domain.CreateUserRequest) e
 		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Invalid email format"}
 	}
 
-	if len(req.Password) < MinPasswordLen {
-		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Password must be at least 6 characters long"}
+	if err := s.passwords.Check(ctx, "password", req.Password); err != nil {
+		return err
 	}
 
-	return nil
+	if role := strings.TrimSpace(req.Role); role != "" && !s.selfAssignableRoles[role] {
+		return domain.ErrRoleNotAllowed
+	}
+
+	if err := s.checkEmailDomain(req.Email); err != nil {
+		return err
+	}
+
+	return s.checkName(req.Name)
+}
+
+// defaultPasswordPolicy only requires MinPasswordLen characters
+func defaultPasswordPolicy() domain.PasswordPolicy {
+	return passwordpolicy.New(config.PasswordPolicyConfig{MinLength: MinPasswordLen})
+}
+
+// defaultPasswordHasher hashes with bcrypt at BCryptCost
+func defaultPasswordHasher() domain.PasswordHasher {
+	hasher, _ := passwordhash.NewBcrypt(BCryptCost)
+	return hasher
+}
+
+func (s *userService) roleExists(role string) bool {
+	return s.roles == nil || s.roles.HasRole(role)
+}
+
+func (s *userService) checkEmailDomain(email string) error {
+	if s.emailPolicy == nil {
+		return nil
+	}
+	return s.emailPolicy.CheckEmail(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func (s *userService) checkName(name string) error {
+	if s.namePolicy == nil {
+		return nil
+	}
+	return s.namePolicy.Check("Name", strings.TrimSpace(name))
 }
 
 func (s *userService) validateLoginRequest(req *domain.LoginRequest) error {
@@ -303,6 +963,12 @@ func (s *userService) validateUpdateUserRequest(req *domain.UpdateUserRequest) e
 		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Name must be at least 2 characters long"}
 	}
 
+	if req.Name != nil {
+		if err := s.checkName(*req.Name); err != nil {
+			return err
+		}
+	}
+
 	if req.Email != nil && !s.isValidEmail(*req.Email) {
 		return &domain.Error{Code: "VALIDATION_FAILED", Message: "Invalid email format"}
 	}
@@ -315,11 +981,30 @@ func (s *userService) isValidEmail(email string) bool {
 	return strings.Contains(email, "@") && strings.Contains(email, ".")
 }
 
+// upgradePasswordHash rehashes a verified password whose stored hash was made
+// with other settings than the current ones. Failures are logged: the login
+// goes ahead and the upgrade is retried next time.
+func (s *userService) upgradePasswordHash(ctx context.Context, user *domain.User, password string, log *logger.Logger) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		log.Warn("Failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	user.Password = hash
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		log.Warn("Failed to store upgraded password hash", "user_id", user.ID, "error", err)
+		return
+	}
+	log.Info("Upgraded password hash", "user_id", user.ID)
+}
+
 func (s *userService) hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return s.hasher.Hash(password)
 }
 
 func (s *userService) verifyPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return s.hasher.Verify(hashedPassword, password)
 }
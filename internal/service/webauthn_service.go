@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+	"demo-go/internal/webauthn"
+)
+
+const (
+	// webAuthnChallengeBytes is the size of ceremony challenges
+	webAuthnChallengeBytes = 32
+	// maxPasskeysPerUser bounds the credentials stored on a user document
+	maxPasskeysPerUser = 10
+	// maxPasskeyNameLength bounds passkey names
+	maxPasskeyNameLength = 64
+)
+
+// webAuthnService implements domain.WebAuthnService
+type webAuthnService struct {
+	userRepo     domain.UserRepository
+	sessions     domain.WebAuthnSessionStore
+	tokenService domain.TokenService
+	auditService domain.AuditService
+	config       config.WebAuthnConfig
+	logger       *logger.Logger
+}
+
+// NewWebAuthnService creates a passkey service. Credentials are stored on the
+// user document; auditService may be nil.
+func NewWebAuthnService(
+	userRepo domain.UserRepository,
+	sessions domain.WebAuthnSessionStore,
+	tokenService domain.TokenService,
+	auditService domain.AuditService,
+	cfg config.WebAuthnConfig,
+) domain.WebAuthnService {
+	if cfg.ChallengeTTL <= 0 {
+		cfg.ChallengeTTL = 5 * time.Minute
+	}
+	return &webAuthnService{
+		userRepo:     userRepo,
+		sessions:     sessions,
+		tokenService: tokenService,
+		auditService: auditService,
+		config:       cfg,
+		logger:       logger.GetGlobal().ForComponent("webauthn-service"),
+	}
+}
+
+// BeginRegistration returns the options to create a passkey for the user with
+func (s *webAuthnService) BeginRegistration(ctx context.Context, userID string) (*domain.WebAuthnCreationOptions, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := selfAccessError(user.Status); err != nil {
+		return nil, err
+	}
+	if len(user.WebAuthnCredentials) >= maxPasskeysPerUser {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: fmt.Sprintf("At most %d passkeys can be registered", maxPasskeysPerUser)}
+	}
+
+	challenge, err := s.newSession(ctx, &domain.WebAuthnSession{Ceremony: webauthn.CeremonyCreate, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]domain.WebAuthnCredentialParameter, 0, len(webauthn.SupportedAlgorithms))
+	for _, alg := range webauthn.SupportedAlgorithms {
+		params = append(params, domain.WebAuthnCredentialParameter{Type: "public-key", Alg: alg})
+	}
+	return &domain.WebAuthnCreationOptions{
+		Challenge: challenge,
+		RP:        domain.WebAuthnRelyingParty{ID: s.config.RPID, Name: s.config.RPName},
+		User: domain.WebAuthnUserEntity{
+			ID:          base64.RawURLEncoding.EncodeToString([]byte(user.ID)),
+			Name:        user.Email,
+			DisplayName: user.Name,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            s.config.ChallengeTTL.Milliseconds(),
+		ExcludeCredentials: descriptors(user.WebAuthnCredentials),
+		AuthenticatorSelection: domain.WebAuthnAuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: s.config.UserVerification,
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration verifies the new credential and stores it on the user
+func (s *webAuthnService) FinishRegistration(
+	ctx context.Context,
+	userID string,
+	req *domain.FinishPasskeyRegistrationRequest,
+) (*domain.WebAuthnCredential, error) {
+	log := s.logger.ForService("webauthn", "finish-registration").WithField("user_id", userID)
+
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxPasskeyNameLength {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: fmt.Sprintf("Passkey names are at most %d characters", maxPasskeyNameLength)}
+	}
+
+	session, _, err := s.consumeSession(ctx, &req.Credential, webauthn.CeremonyCreate)
+	if err != nil {
+		log.Warn("Passkey registration refused", "error", err)
+		return nil, domain.ErrPasskeyFailed
+	}
+	if session.UserID != userID {
+		log.Warn("Passkey registration challenge was issued to another user")
+		return nil, domain.ErrPasskeyFailed
+	}
+
+	authData, err := s.verifyAttestation(&req.Credential)
+	if err != nil {
+		log.Warn("Passkey registration refused", "error", err)
+		return nil, domain.ErrPasskeyFailed
+	}
+	alg, err := webauthn.PublicKeyAlgorithm(authData.PublicKey)
+	if err != nil {
+		log.Warn("Passkey registration refused", "error", err)
+		return nil, domain.ErrPasskeyFailed
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	credentialID := base64.RawURLEncoding.EncodeToString(authData.CredentialID)
+	if findCredential(user.WebAuthnCredentials, credentialID) >= 0 {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: "This passkey is already registered"}
+	}
+	if len(user.WebAuthnCredentials) >= maxPasskeysPerUser {
+		return nil, &domain.Error{Code: "VALIDATION_FAILED", Message: fmt.Sprintf("At most %d passkeys can be registered", maxPasskeysPerUser)}
+	}
+	if name == "" {
+		name = fmt.Sprintf("Passkey %d", len(user.WebAuthnCredentials)+1)
+	}
+
+	credential := domain.WebAuthnCredential{
+		ID:        credentialID,
+		Name:      name,
+		PublicKey: base64.RawURLEncoding.EncodeToString(authData.PublicKey),
+		Algorithm: alg,
+		SignCount: authData.SignCount,
+		CreatedAt: time.Now().UTC(),
+	}
+	// The repository may share the slice with other copies of the user
+	user.WebAuthnCredentials = append(append([]domain.WebAuthnCredential{}, user.WebAuthnCredentials...), credential)
+	if err := s.userRepo.Update(ctx, userID, user); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, domain.AuditActionPasskeyRegistered, userID, credential)
+	log.Info("Passkey registered", "credential_id", credentialID, "algorithm", alg)
+	return &credential, nil
+}
+
+// ListCredentials returns the user's passkeys
+func (s *webAuthnService) ListCredentials(ctx context.Context, userID string) ([]domain.WebAuthnCredential, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := selfAccessError(user.Status); err != nil {
+		return nil, err
+	}
+	return append([]domain.WebAuthnCredential{}, user.WebAuthnCredentials...), nil
+}
+
+// DeleteCredential removes one of the user's passkeys
+func (s *webAuthnService) DeleteCredential(ctx context.Context, userID, credentialID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := selfAccessError(user.Status); err != nil {
+		return err
+	}
+
+	i := findCredential(user.WebAuthnCredentials, credentialID)
+	if i < 0 {
+		return domain.ErrPasskeyNotFound
+	}
+	removed := user.WebAuthnCredentials[i]
+	remaining := make([]domain.WebAuthnCredential, 0, len(user.WebAuthnCredentials)-1)
+	remaining = append(remaining, user.WebAuthnCredentials[:i]...)
+	user.WebAuthnCredentials = append(remaining, user.WebAuthnCredentials[i+1:]...)
+	if err := s.userRepo.Update(ctx, userID, user); err != nil {
+		return err
+	}
+
+	s.audit(ctx, domain.AuditActionPasskeyRemoved, userID, removed)
+	s.logger.ForService("webauthn", "delete").Info("Passkey removed", "user_id", userID, "credential_id", credentialID)
+	return nil
+}
+
+// BeginLogin returns the options to log in with a passkey. An unknown email,
+// or one without passkeys, gets options too, but the login cannot succeed,
+// so the endpoint does not tell which accounts exist.
+func (s *webAuthnService) BeginLogin(ctx context.Context, req *domain.BeginPasskeyLoginRequest) (*domain.WebAuthnRequestOptions, error) {
+	session := &domain.WebAuthnSession{Ceremony: webauthn.CeremonyGet}
+	allow := []domain.WebAuthnCredentialDescriptor{}
+
+	if email := strings.ToLower(strings.TrimSpace(req.Email)); email != "" {
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil && err != domain.ErrUserNotFound {
+			return nil, err
+		}
+		if user != nil && len(user.WebAuthnCredentials) > 0 {
+			session.UserID = user.ID
+			allow = descriptors(user.WebAuthnCredentials)
+		} else {
+			// A session bound to no user cannot be finished with any credential
+			session.UserID = "-"
+		}
+	}
+
+	challenge, err := s.newSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WebAuthnRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.config.RPID,
+		Timeout:          s.config.ChallengeTTL.Milliseconds(),
+		AllowCredentials: allow,
+		UserVerification: s.config.UserVerification,
+	}, nil
+}
+
+// FinishLogin verifies the assertion, records the credential's use and
+// issues an access token
+func (s *webAuthnService) FinishLogin(ctx context.Context, req *domain.FinishPasskeyLoginRequest) (string, *domain.UserResponse, error) {
+	log := s.logger.ForService("webauthn", "finish-login").WithField("credential_id", req.Credential.ID)
+
+	session, clientDataJSON, err := s.consumeSession(ctx, &req.Credential, webauthn.CeremonyGet)
+	if err != nil {
+		log.Warn("Passkey login refused", "error", err)
+		return "", nil, domain.ErrPasskeyFailed
+	}
+
+	userID := session.UserID
+	if handle := req.Credential.Response.UserHandle; handle != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(handle)
+		if err != nil || (userID != "" && string(raw) != userID) {
+			log.Warn("Passkey login user handle does not match the login")
+			return "", nil, domain.ErrPasskeyFailed
+		}
+		userID = string(raw)
+	}
+	if userID == "" || userID == "-" {
+		log.Warn("Passkey login names no user")
+		return "", nil, domain.ErrPasskeyFailed
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			log.Warn("Passkey login for unknown user")
+			return "", nil, domain.ErrPasskeyFailed
+		}
+		return "", nil, err
+	}
+	i := findCredential(user.WebAuthnCredentials, req.Credential.ID)
+	if i < 0 {
+		log.Warn("Passkey login with unregistered credential", "user_id", userID)
+		return "", nil, domain.ErrPasskeyFailed
+	}
+	credential := user.WebAuthnCredentials[i]
+
+	signCount, err := s.verifyAssertion(&req.Credential, clientDataJSON, &credential)
+	if err != nil {
+		log.Warn("Passkey login refused", "user_id", userID, "error", err)
+		return "", nil, domain.ErrPasskeyFailed
+	}
+
+	if err := selfAccessError(user.Status); err != nil {
+		if err == domain.ErrUserNotFound {
+			return "", nil, domain.ErrPasskeyFailed
+		}
+		log.Warn("Passkey login for suspended account", "user_id", userID)
+		return "", nil, err
+	}
+
+	credential.SignCount = signCount
+	credential.LastUsedAt = time.Now().UTC()
+	credentials := append([]domain.WebAuthnCredential{}, user.WebAuthnCredentials...)
+	credentials[i] = credential
+	user.WebAuthnCredentials = credentials
+	if err := s.userRepo.Update(ctx, userID, user); err != nil {
+		log.Error("Failed to record passkey use", "user_id", userID, "error", err)
+		return "", nil, err
+	}
+
+	token, err := s.tokenService.GenerateToken(user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	log.Info("User logged in with a passkey", "user_id", userID)
+	return token, user.ToResponse(), nil
+}
+
+// Helper methods
+
+// newSession stores session under a fresh challenge and returns the challenge
+func (s *webAuthnService) newSession(ctx context.Context, session *domain.WebAuthnSession) (string, error) {
+	b := make([]byte, webAuthnChallengeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(b)
+	if err := s.sessions.Save(ctx, challenge, session, s.config.ChallengeTTL); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// consumeSession verifies the credential's client data and ends the
+// ceremony session of its challenge, so each challenge is answered at most
+// once. It returns the session and the raw client data.
+func (s *webAuthnService) consumeSession(
+	ctx context.Context,
+	credential *domain.WebAuthnCredentialResponse,
+	ceremony string,
+) (*domain.WebAuthnSession, []byte, error) {
+	if credential.Type != "public-key" {
+		return nil, nil, fmt.Errorf("credential type is %q", credential.Type)
+	}
+	clientDataJSON, err := base64.RawURLEncoding.DecodeString(credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid clientDataJSON encoding: %w", err)
+	}
+	clientData, err := webauthn.ParseClientData(clientDataJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := s.sessions.Consume(ctx, clientData.Challenge)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown or expired challenge: %w", err)
+	}
+	if session.Ceremony != ceremony {
+		return nil, nil, fmt.Errorf("challenge was issued for %s", session.Ceremony)
+	}
+	if err := clientData.Verify(ceremony, clientData.Challenge, s.config.Origins); err != nil {
+		return nil, nil, err
+	}
+	return session, clientDataJSON, nil
+}
+
+// verifyAttestation checks the attestation object of a new credential
+func (s *webAuthnService) verifyAttestation(credential *domain.WebAuthnCredentialResponse) (*webauthn.AuthenticatorData, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(credential.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestationObject encoding: %w", err)
+	}
+	authData, err := webauthn.ParseAttestationObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.Verify(s.config.RPID, s.requireUserVerification()); err != nil {
+		return nil, err
+	}
+	if base64.RawURLEncoding.EncodeToString(authData.CredentialID) != credential.ID {
+		return nil, fmt.Errorf("credential ID does not match the attested credential")
+	}
+	return authData, nil
+}
+
+// verifyAssertion checks a login assertion by a stored credential and
+// returns the authenticator's new signature counter
+func (s *webAuthnService) verifyAssertion(
+	credential *domain.WebAuthnCredentialResponse,
+	clientDataJSON []byte,
+	stored *domain.WebAuthnCredential,
+) (uint32, error) {
+	rawAuthData, err := base64.RawURLEncoding.DecodeString(credential.Response.AuthenticatorData)
+	if err != nil {
+		return 0, fmt.Errorf("invalid authenticatorData encoding: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(credential.Response.Signature)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	publicKey, err := base64.RawURLEncoding.DecodeString(stored.PublicKey)
+	if err != nil {
+		return 0, fmt.Errorf("stored public key is corrupt: %w", err)
+	}
+
+	authData, err := webauthn.ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := authData.Verify(s.config.RPID, s.requireUserVerification()); err != nil {
+		return 0, err
+	}
+	if err := webauthn.VerifySignature(publicKey, rawAuthData, clientDataJSON, signature); err != nil {
+		return 0, err
+	}
+	// Authenticators with counters increase them on every use; one that goes
+	// backwards suggests a cloned authenticator
+	if (authData.SignCount != 0 || stored.SignCount != 0) && authData.SignCount <= stored.SignCount {
+		return 0, fmt.Errorf("signature counter went from %d to %d", stored.SignCount, authData.SignCount)
+	}
+	return authData.SignCount, nil
+}
+
+func (s *webAuthnService) requireUserVerification() bool {
+	return s.config.UserVerification == "required"
+}
+
+func (s *webAuthnService) audit(ctx context.Context, action, userID string, credential domain.WebAuthnCredential) {
+	if s.auditService == nil {
+		return
+	}
+	// A failed audit write is logged by the audit service, not returned
+	_ = s.auditService.Record(ctx, &domain.AuditEvent{
+		Action:   action,
+		ActorID:  userID,
+		TargetID: userID,
+		Details:  map[string]interface{}{"credential_id": credential.ID, "name": credential.Name},
+	})
+}
+
+// descriptors lists credentials for allowCredentials and excludeCredentials
+func descriptors(credentials []domain.WebAuthnCredential) []domain.WebAuthnCredentialDescriptor {
+	list := make([]domain.WebAuthnCredentialDescriptor, 0, len(credentials))
+	for _, c := range credentials {
+		list = append(list, domain.WebAuthnCredentialDescriptor{Type: "public-key", ID: c.ID})
+	}
+	return list
+}
+
+// findCredential returns the index of the credential with the given ID, or -1
+func findCredential(credentials []domain.WebAuthnCredential, id string) int {
+	for i, c := range credentials {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
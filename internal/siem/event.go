@@ -0,0 +1,43 @@
+// Package siem forwards audit, authentication and security events to a security
+// information and event management system over syslog or an HTTP event
+// collector. Events are buffered in memory and shipped in batches by a
+// background worker with retries, so request handling never waits on the SIEM.
+package siem
+
+import "time"
+
+// Event categories
+const (
+	CategoryAudit    = "audit"
+	CategoryAuth     = "auth"
+	CategorySecurity = "security"
+)
+
+// Authentication event actions
+const (
+	ActionLoginSucceeded = "login.succeeded"
+	ActionLoginFailed    = "login.failed"
+)
+
+// Outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event is the structured record shipped to the SIEM
+type Event struct {
+	Time      time.Time              `json:"time"`
+	Category  string                 `json:"category"`
+	Action    string                 `json:"action"`
+	Outcome   string                 `json:"outcome"`
+	Severity  string                 `json:"severity,omitempty"`
+	Flags     []string               `json:"flags,omitempty"`
+	ActorID   string                 `json:"actor_id,omitempty"`
+	TargetID  string                 `json:"target_id,omitempty"`
+	Email     string                 `json:"email,omitempty"`
+	ClientIP  string                 `json:"client_ip,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
@@ -0,0 +1,110 @@
+package siem
+
+import (
+	"context"
+	"fmt"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/hooks"
+	"demo-go/internal/middleware"
+	"demo-go/internal/response"
+)
+
+// NewSink creates the sink selected by configuration
+func NewSink(cfg config.SIEMConfig) (Sink, error) {
+	switch cfg.Transport {
+	case config.SIEMTransportSyslog:
+		return NewSyslogSink(cfg.SyslogNetwork, cfg.SyslogAddress, "demo-go"), nil
+	case config.SIEMTransportHTTP:
+		if cfg.HTTPURL == "" {
+			return nil, fmt.Errorf("SIEM_HTTP_URL is required for the http transport")
+		}
+		return NewHTTPSink(cfg.HTTPURL, cfg.HTTPToken, cfg.SourceType, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported SIEM transport: %s", cfg.Transport)
+	}
+}
+
+// RegisterAuthHooks forwards login successes and failures through lifecycle hooks
+func RegisterAuthHooks(registry *hooks.Registry, shipper *Shipper) {
+	registry.OnLogin("siem", func(ctx context.Context, user *domain.UserResponse) error {
+		event := authEvent(ctx, ActionLoginSucceeded, OutcomeSuccess)
+		event.ActorID = user.ID
+		event.Email = user.Email
+		shipper.Publish(event)
+		return nil
+	})
+
+	registry.OnLoginFailed("siem", func(ctx context.Context, email string, reason error) error {
+		event := authEvent(ctx, ActionLoginFailed, OutcomeFailure)
+		event.Email = email
+		if domainErr, ok := reason.(*domain.Error); ok {
+			event.Reason = domainErr.Code
+		}
+		shipper.Publish(event)
+		return nil
+	})
+}
+
+// RegisterSecurityHooks forwards recorded security events, including their anomaly flags
+func RegisterSecurityHooks(registry *hooks.Registry, shipper *Shipper) {
+	registry.OnSecurityEvent("siem", func(_ context.Context, event *domain.SecurityEvent) error {
+		shipper.Publish(Event{
+			Time:      event.CreatedAt,
+			Category:  CategorySecurity,
+			Action:    event.Type,
+			Outcome:   OutcomeSuccess,
+			Severity:  event.Severity,
+			Flags:     event.Flags,
+			ActorID:   event.ActorID,
+			TargetID:  event.TargetID,
+			Email:     event.Email,
+			ClientIP:  event.ClientIP,
+			RequestID: event.RequestID,
+			Reason:    event.Reason,
+			Details:   event.Details,
+		})
+		return nil
+	})
+}
+
+func authEvent(ctx context.Context, action, outcome string) Event {
+	event := Event{Category: CategoryAuth, Action: action, Outcome: outcome}
+	event.ClientIP, _ = middleware.GetClientIPFromContext(ctx)
+	event.RequestID, _ = response.RequestIDFromContext(ctx)
+	return event
+}
+
+// auditService forwards recorded audit events to the SIEM
+type auditService struct {
+	domain.AuditService
+	shipper *Shipper
+}
+
+// NewAuditService wraps an audit service so every recorded event is also shipped to the SIEM
+func NewAuditService(inner domain.AuditService, shipper *Shipper) domain.AuditService {
+	return &auditService{AuditService: inner, shipper: shipper}
+}
+
+// Record persists the events and queues them for shipping
+func (s *auditService) Record(ctx context.Context, events ...*domain.AuditEvent) error {
+	err := s.AuditService.Record(ctx, events...)
+
+	requestID, _ := response.RequestIDFromContext(ctx)
+	clientIP, _ := middleware.GetClientIPFromContext(ctx)
+	for _, event := range events {
+		s.shipper.Publish(Event{
+			Time:      event.CreatedAt,
+			Category:  CategoryAudit,
+			Action:    event.Action,
+			Outcome:   OutcomeSuccess,
+			ActorID:   event.ActorID,
+			TargetID:  event.TargetID,
+			ClientIP:  clientIP,
+			RequestID: requestID,
+			Details:   event.Details,
+		})
+	}
+	return err
+}
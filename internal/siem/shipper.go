@@ -0,0 +1,162 @@
+package siem
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+)
+
+// sendTimeout bounds a single delivery attempt
+const sendTimeout = 10 * time.Second
+
+// Shipper buffers events and ships them to a sink in batches
+type Shipper struct {
+	sink          Sink
+	events        chan Event
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration
+	dropped       atomic.Int64
+	logger        *logger.Logger
+
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex // guards closed against concurrent Publish
+	closed    bool
+}
+
+// NewShipper creates a shipper and starts its background worker
+func NewShipper(sink Sink, cfg config.SIEMConfig) *Shipper {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = 2 * time.Second
+	}
+
+	s := &Shipper{
+		sink:          sink,
+		events:        make(chan Event, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		maxRetries:    cfg.MaxRetries,
+		retryBackoff:  cfg.RetryBackoff,
+		logger:        logger.GetGlobal().ForComponent("siem-shipper"),
+		done:          make(chan struct{}),
+	}
+
+	go s.run()
+	return s
+}
+
+// Publish queues an event without blocking. When the buffer is full the event
+// is dropped and counted so a slow SIEM cannot stall requests.
+func (s *Shipper) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		if s.dropped.Add(1)%100 == 1 {
+			s.logger.Warn("SIEM buffer full, dropping events", "dropped_total", s.dropped.Load())
+		}
+	}
+}
+
+// Dropped returns how many events have been dropped since startup
+func (s *Shipper) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops accepting events, flushes the buffer and closes the sink. It
+// returns early if ctx expires before the flush completes.
+func (s *Shipper) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		s.mu.Unlock()
+	})
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return s.sink.Close()
+}
+
+func (s *Shipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, s.batchSize)
+	for {
+		select {
+		case event, ok := <-s.events:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= s.batchSize {
+				s.flush(batch)
+				batch = make([]Event, 0, s.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = make([]Event, 0, s.batchSize)
+			}
+		}
+	}
+}
+
+// flush delivers a batch, retrying transient failures with exponential backoff
+func (s *Shipper) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	backoff := s.retryBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := s.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		if isPermanent(err) || attempt >= s.maxRetries {
+			s.dropped.Add(int64(len(batch)))
+			s.logger.Error("Failed to ship events to SIEM, dropping batch",
+				"events", len(batch), "attempts", attempt+1, "error", err)
+			return
+		}
+
+		s.logger.Warn("SIEM delivery failed, retrying", "attempt", attempt+1, "backoff", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
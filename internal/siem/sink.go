@@ -0,0 +1,231 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sink delivers a batch of events to the SIEM
+type Sink interface {
+	Send(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// PermanentError marks a delivery failure that retrying will not fix, such as
+// a rejected token
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func isPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// Syslog facility and severities used for RFC 5424 priorities
+const (
+	syslogFacilityAuthPriv = 10
+	syslogSeverityWarning  = 4
+	syslogSeverityInfo     = 6
+	syslogDialTimeout      = 5 * time.Second
+)
+
+// syslogSink writes RFC 5424 messages with a JSON payload over UDP or TCP
+type syslogSink struct {
+	network  string
+	address  string
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a sink that sends events to a syslog receiver.
+// The connection is established lazily and re-established after failures.
+func NewSyslogSink(network, address, appName string) Sink {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &syslogSink{
+		network:  network,
+		address:  address,
+		appName:  appName,
+		hostname: hostname,
+	}
+}
+
+// Send writes each event as one syslog message
+func (s *syslogSink) Send(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: syslogDialTimeout}
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+
+	for _, event := range events {
+		message, err := s.format(event)
+		if err != nil {
+			return &PermanentError{Err: err}
+		}
+		if _, err := s.conn.Write(message); err != nil {
+			_ = s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// format renders an RFC 5424 message. TCP messages use octet-counting framing (RFC 6587).
+func (s *syslogSink) format(event Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	severity := syslogSeverityInfo
+	if event.Outcome == OutcomeFailure {
+		severity = syslogSeverityWarning
+	}
+
+	message := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s",
+		syslogFacilityAuthPriv*8+severity,
+		event.Time.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		s.appName,
+		os.Getpid(),
+		event.Action,
+		payload,
+	)
+
+	if s.network == "tcp" {
+		message = strconv.Itoa(len(message)) + " " + message
+	}
+	return []byte(message), nil
+}
+
+// Close closes the syslog connection
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// httpSink posts batches to a Splunk HEC compatible event collector
+type httpSink struct {
+	url        string
+	token      string
+	sourceType string
+	hostname   string
+	client     *http.Client
+}
+
+// hecEvent is the Splunk HTTP Event Collector envelope
+type hecEvent struct {
+	Time       float64 `json:"time"`
+	Host       string  `json:"host"`
+	Source     string  `json:"source"`
+	SourceType string  `json:"sourcetype"`
+	Event      Event   `json:"event"`
+}
+
+// NewHTTPSink creates a sink that posts events to an HTTP event collector
+func NewHTTPSink(url, token, sourceType string, client *http.Client) Sink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	hostname, _ := os.Hostname()
+	return &httpSink{
+		url:        url,
+		token:      token,
+		sourceType: sourceType,
+		hostname:   hostname,
+		client:     client,
+	}
+}
+
+// Send posts the batch as concatenated HEC events in a single request
+func (s *httpSink) Send(ctx context.Context, events []Event) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(hecEvent{
+			Time:       float64(event.Time.UnixNano()) / float64(time.Second),
+			Host:       s.hostname,
+			Source:     "demo-go",
+			SourceType: s.sourceType,
+			Event:      event,
+		}); err != nil {
+			return &PermanentError{Err: err}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, &body)
+	if err != nil {
+		return &PermanentError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Splunk "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post events: %w", err)
+	}
+	defer resp.Body.Close()               //nolint:errcheck
+	_, _ = io.Copy(io.Discard, resp.Body) // allow connection reuse
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	default:
+		return &PermanentError{Err: fmt.Errorf("collector rejected events with status %d", resp.StatusCode)}
+	}
+}
+
+// Close releases idle connections
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package snapshot stores snapshots of the users collection on the local
+// filesystem or in an S3 bucket
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+)
+
+// fileExtension is appended to snapshot names to form file and object names
+const fileExtension = ".json"
+
+// namePattern restricts snapshot names so they are safe as file and object names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// ValidateName checks that name can be used as a snapshot name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) || strings.Contains(name, "..") {
+		return &domain.Error{
+			Code:    domain.ErrValidationFailed.Code,
+			Message: "Snapshot names may contain only letters, digits, '.', '_' and '-'",
+		}
+	}
+	return nil
+}
+
+// NewStore creates the snapshot store selected by cfg.Storage
+func NewStore(cfg config.SnapshotConfig) (domain.SnapshotStore, error) {
+	switch cfg.Storage {
+	case config.SnapshotStorageFile:
+		return NewFileStore(cfg.Dir)
+	case config.SnapshotStorageS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported snapshot storage: %s", cfg.Storage)
+	}
+}
+
+// fileStore implements domain.SnapshotStore with one file per snapshot
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates a snapshot store writing to dir, creating it if needed
+func NewFileStore(dir string) (domain.SnapshotStore, error) {
+	if dir == "" {
+		return nil, errors.New("snapshot directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create snapshot directory: %w", err)
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+// Put writes the snapshot through a temporary file, so a failed write never
+// leaves a truncated snapshot behind. Existing snapshots are not replaced.
+func (s *fileStore) Put(ctx context.Context, name string, data []byte) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	path := s.path(name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("snapshot %s already exists", name)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get reads a snapshot
+func (s *fileStore) Get(ctx context.Context, name string) ([]byte, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrSnapshotNotFound
+	}
+	return data, err
+}
+
+// List returns the snapshot names in the directory. Generated names embed
+// their creation time, so lexical order is creation order.
+func (s *fileStore) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), fileExtension)
+		if entry.IsDir() || name == entry.Name() || ValidateName(name) != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *fileStore) path(name string) string {
+	return filepath.Join(s.dir, name+fileExtension)
+}
@@ -0,0 +1,264 @@
+// Package telemetry implements opt-in anonymous usage reporting. When enabled
+// the collector periodically posts a small report of coarse instance facts
+// (version, a user count bucket and the enabled features) to a configured
+// endpoint. It never sends user data, and the exact payload can be inspected
+// through the admin API before anything is enabled.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// UserCounter reports how many users exist; domain.UserRepository satisfies it
+type UserCounter interface {
+	Count(ctx context.Context) (int64, error)
+}
+
+// Report is the complete payload sent to the telemetry endpoint
+type Report struct {
+	// InstanceID is random per process, so reports cannot be linked across restarts
+	InstanceID  string    `json:"instance_id"`
+	Version     string    `json:"version"`
+	GoVersion   string    `json:"go_version"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	UserCount   string    `json:"user_count"`
+	Features    []string  `json:"features"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Status describes the collector for the payload inspection endpoint
+type Status struct {
+	Enabled    bool       `json:"enabled"`
+	Endpoint   string     `json:"endpoint,omitempty"`
+	Interval   string     `json:"interval"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	// Payload is the report that would be sent now
+	Payload *Report `json:"payload"`
+}
+
+// userCountBuckets are the upper bounds of the reported user count ranges
+var userCountBuckets = []struct {
+	max   int64
+	label string
+}{
+	{0, "0"},
+	{10, "1-10"},
+	{100, "11-100"},
+	{1000, "101-1000"},
+	{10000, "1001-10000"},
+	{100000, "10001-100000"},
+}
+
+// UserCountBucket reduces an exact user count to a coarse range
+func UserCountBucket(count int64) string {
+	for _, bucket := range userCountBuckets {
+		if count <= bucket.max {
+			return bucket.label
+		}
+	}
+	return "100000+"
+}
+
+// Collector builds telemetry reports and, when enabled, sends them periodically
+type Collector struct {
+	cfg        config.TelemetryConfig
+	instanceID string
+	version    string
+	features   []string
+	users      UserCounter
+	client     *http.Client
+	logger     *logger.Logger
+
+	mu         sync.Mutex
+	started    bool
+	lastSentAt time.Time
+	lastError  string
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCollector creates a collector. Nothing is sent until Start is called,
+// and only if telemetry is enabled with an endpoint.
+func NewCollector(cfg config.TelemetryConfig, version string, features []string, users UserCounter) *Collector {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &Collector{
+		cfg:        cfg,
+		instanceID: uuid.New().String(),
+		version:    version,
+		features:   features,
+		users:      users,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		logger:     logger.GetGlobal().ForComponent("telemetry"),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Enabled reports whether the collector sends reports
+func (c *Collector) Enabled() bool {
+	return c.cfg.Enabled && c.cfg.Endpoint != ""
+}
+
+// Start begins periodic reporting in the background when telemetry is enabled
+func (c *Collector) Start() {
+	if !c.Enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return
+	}
+	c.started = true
+	c.logger.Info("Telemetry enabled", "endpoint", c.cfg.Endpoint, "interval", c.cfg.Interval)
+	go c.run()
+}
+
+// Close stops periodic reporting and waits for an in-flight report, or for ctx to expire
+func (c *Collector) Close(ctx context.Context) error {
+	c.mu.Lock()
+	started := c.started
+	c.mu.Unlock()
+
+	c.closeOnce.Do(func() { close(c.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Report builds the payload that would be sent now
+func (c *Collector) Report(ctx context.Context) (*Report, error) {
+	count, err := c.users.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("count users: %w", err)
+	}
+
+	return &Report{
+		InstanceID:  c.instanceID,
+		Version:     c.version,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		UserCount:   UserCountBucket(count),
+		Features:    c.features,
+		GeneratedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Status returns the collector state together with the current payload
+func (c *Collector) Status(ctx context.Context) (*Status, error) {
+	report, err := c.Report(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Enabled:  c.Enabled(),
+		Endpoint: c.cfg.Endpoint,
+		Interval: c.cfg.Interval.String(),
+		Payload:  report,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.lastSentAt.IsZero() {
+		sentAt := c.lastSentAt
+		status.LastSentAt = &sentAt
+	}
+	status.LastError = c.lastError
+	return status, nil
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	c.send()
+	for {
+		select {
+		case <-ticker.C:
+			c.send()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// send posts one report; failures are recorded and retried at the next interval
+func (c *Collector) send() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	err := c.post(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.lastError = err.Error()
+		c.logger.Debug("Failed to send telemetry report", "error", err)
+		return
+	}
+	c.lastSentAt = time.Now().UTC()
+	c.lastError = ""
+}
+
+func (c *Collector) post(ctx context.Context) error {
+	report, err := c.Report(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
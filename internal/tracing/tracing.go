@@ -0,0 +1,181 @@
+// Package tracing implements W3C Trace Context (traceparent/tracestate)
+// propagation. It parses incoming headers, generates IDs when absent and
+// injects them into outbound requests, independently of any trace exporter.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context header names
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// flagSampled is the W3C "sampled" trace flag
+const flagSampled = 0x01
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Flags      byte
+	TraceState string
+}
+
+// TraceIDString returns the hex-encoded trace ID
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDString returns the hex-encoded span ID
+func (sc SpanContext) SpanIDString() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// IsValid reports whether both trace and span IDs are non-zero
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent formats the span context as a version 00 traceparent header value
+func (sc SpanContext) TraceParent() string {
+	return "00-" + sc.TraceIDString() + "-" + sc.SpanIDString() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// NewChild returns a new span in the same trace, inheriting flags and state
+func (sc SpanContext) NewChild() SpanContext {
+	child := sc
+	child.SpanID = newSpanID()
+	return child
+}
+
+// NewRoot starts a new sampled trace
+func NewRoot() SpanContext {
+	var traceID [16]byte
+	for traceID == ([16]byte{}) {
+		_, _ = rand.Read(traceID[:])
+	}
+
+	return SpanContext{
+		TraceID: traceID,
+		SpanID:  newSpanID(),
+		Flags:   flagSampled,
+	}
+}
+
+// Parse parses traceparent and tracestate header values. It returns false
+// when traceparent is missing or malformed, in which case callers should
+// start a new trace.
+func Parse(traceParent, traceState string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	// Version ff is forbidden; version 00 must have exactly four fields
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if !decodeLowerHex(sc.TraceID[:], traceID) || !decodeLowerHex(sc.SpanID[:], spanID) {
+		return SpanContext{}, false
+	}
+	var flagBytes [1]byte
+	if !decodeLowerHex(flagBytes[:], flags) {
+		return SpanContext{}, false
+	}
+	sc.Flags = flagBytes[0]
+
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+
+	sc.TraceState = strings.TrimSpace(traceState)
+	return sc, true
+}
+
+// FromRequest extracts the caller's span context from request headers
+func FromRequest(r *http.Request) (SpanContext, bool) {
+	return Parse(r.Header.Get(TraceParentHeader), r.Header.Get(TraceStateHeader))
+}
+
+type contextKey struct{}
+
+// ContextWithSpan returns a context carrying the span context
+func ContextWithSpan(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// SpanFromContext returns the span context stored in ctx
+func SpanFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// Inject writes trace headers for an outbound call made on behalf of the
+// span in ctx. Each outbound call gets its own child span ID.
+func Inject(ctx context.Context, header http.Header) {
+	sc, ok := SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	child := sc.NewChild()
+	header.Set(TraceParentHeader, child.TraceParent())
+	if child.TraceState != "" {
+		header.Set(TraceStateHeader, child.TraceState)
+	}
+}
+
+// Transport is an http.RoundTripper that propagates trace headers from the
+// request context on every outbound call
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base (or http.DefaultTransport when nil) with trace propagation
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// RoundTrip injects trace headers and delegates to the base transport
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := SpanFromContext(req.Context()); ok {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		Inject(req.Context(), req.Header)
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// newSpanID generates a random non-zero span ID
+func newSpanID() [8]byte {
+	var spanID [8]byte
+	for spanID == ([8]byte{}) {
+		_, _ = rand.Read(spanID[:])
+	}
+	return spanID
+}
+
+// decodeLowerHex decodes hex into dst, rejecting upper-case digits as the spec requires
+func decodeLowerHex(dst []byte, s string) bool {
+	if strings.ToLower(s) != s {
+		return false
+	}
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
@@ -0,0 +1,219 @@
+// Package usage counts the API requests of every authenticated principal,
+// their errors and latency, per day. Requests are counted in memory and
+// added to the shared daily counters in the background; the counters of
+// today and yesterday are rolled up into the repository, which answers the
+// usage queries.
+package usage
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/logger"
+)
+
+// syncTimeout bounds one flush or rollup
+const syncTimeout = 30 * time.Second
+
+// Tracker records requests and serves the usage rollups. It implements
+// domain.UsageRecorder.
+type Tracker struct {
+	cfg      config.UsageConfig
+	counters domain.UsageCounterStore
+	repo     domain.UsageRepository
+	logger   *logger.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[pendingKey]*domain.UsageCounts
+	started bool
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// pendingKey identifies the counts not yet flushed for a day and principal
+type pendingKey struct {
+	day       string
+	principal domain.UsagePrincipal
+}
+
+// New creates a tracker. Nothing is flushed or rolled up until Start is called.
+func New(cfg config.UsageConfig, counters domain.UsageCounterStore, repo domain.UsageRepository) *Tracker {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	if cfg.RollupInterval <= 0 {
+		cfg.RollupInterval = 5 * time.Minute
+	}
+	if cfg.MaxDays <= 0 {
+		cfg.MaxDays = 90
+	}
+
+	return &Tracker{
+		cfg:      cfg,
+		counters: counters,
+		repo:     repo,
+		logger:   logger.GetGlobal().ForComponent("usage-tracker"),
+		now:      time.Now,
+		pending:  make(map[pendingKey]*domain.UsageCounts),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record counts a request; it never blocks on storage
+func (t *Tracker) Record(principal domain.UsagePrincipal, status int, latency time.Duration) {
+	counts := domain.UsageCounts{Requests: 1, LatencyMs: latency.Milliseconds()}
+	switch {
+	case status >= http.StatusInternalServerError:
+		counts.ServerErrors = 1
+	case status >= http.StatusBadRequest:
+		counts.ClientErrors = 1
+	}
+
+	key := pendingKey{day: t.now().UTC().Format(domain.UsageDayFormat), principal: principal}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pending, ok := t.pending[key]
+	if !ok {
+		pending = &domain.UsageCounts{}
+		t.pending[key] = pending
+	}
+	pending.Add(counts)
+}
+
+// Flush adds the counts recorded since the last flush to the shared
+// counters. Counts that cannot be added are dropped and logged.
+func (t *Tracker) Flush(ctx context.Context) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[pendingKey]*domain.UsageCounts)
+	t.mu.Unlock()
+
+	for key, counts := range pending {
+		if err := t.counters.Add(ctx, key.day, key.principal, *counts); err != nil {
+			t.logger.Warn("Failed to add usage counts, dropping them",
+				"day", key.day, "principal_id", key.principal.ID, "requests", counts.Requests, "error", err)
+		}
+	}
+}
+
+// Rollup persists the counters of today and yesterday to the repository
+func (t *Tracker) Rollup(ctx context.Context) error {
+	now := t.now().UTC()
+	for _, day := range []string{now.AddDate(0, 0, -1).Format(domain.UsageDayFormat), now.Format(domain.UsageDayFormat)} {
+		records, err := t.counters.List(ctx, day)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			record.UpdatedAt = now
+			if err := t.repo.Save(ctx, record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// List returns the matching daily usage, newest day first. Without From,
+// it covers the last MaxDays days; wider ranges fail with ErrInvalidUsageRange.
+func (t *Tracker) List(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageRecord, error) {
+	today := t.now().UTC()
+	if filter.To == "" {
+		filter.To = today.Format(domain.UsageDayFormat)
+	}
+	to, err := time.Parse(domain.UsageDayFormat, filter.To)
+	if err != nil {
+		return nil, domain.ErrInvalidUsageRange
+	}
+	if filter.From == "" {
+		filter.From = to.AddDate(0, 0, 1-t.cfg.MaxDays).Format(domain.UsageDayFormat)
+	}
+	from, err := time.Parse(domain.UsageDayFormat, filter.From)
+	if err != nil || from.After(to) || to.Sub(from) >= time.Duration(t.cfg.MaxDays)*24*time.Hour {
+		return nil, domain.ErrInvalidUsageRange
+	}
+
+	records, err := t.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		record.WithRates()
+	}
+	return records, nil
+}
+
+// Start begins flushing and rolling up in the background
+func (t *Tracker) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.started {
+		return
+	}
+	t.started = true
+	t.logger.Info("Usage tracking started", "flush_interval", t.cfg.FlushInterval, "rollup_interval", t.cfg.RollupInterval)
+	go t.run()
+}
+
+// Close stops the background work after a last flush and rollup, or once
+// ctx expires
+func (t *Tracker) Close(ctx context.Context) error {
+	t.mu.Lock()
+	started := t.started
+	t.mu.Unlock()
+
+	t.closeOnce.Do(func() { close(t.stop) })
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracker) run() {
+	defer close(t.done)
+
+	flush := time.NewTicker(t.cfg.FlushInterval)
+	defer flush.Stop()
+	rollup := time.NewTicker(t.cfg.RollupInterval)
+	defer rollup.Stop()
+
+	for {
+		select {
+		case <-flush.C:
+			t.sync(false)
+		case <-rollup.C:
+			t.sync(true)
+		case <-t.stop:
+			t.sync(true)
+			return
+		}
+	}
+}
+
+// sync flushes, then rolls up when asked to
+func (t *Tracker) sync(rollup bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	t.Flush(ctx)
+	if !rollup {
+		return
+	}
+	if err := t.Rollup(ctx); err != nil {
+		t.logger.Warn("Failed to roll up usage", "error", err)
+	}
+}
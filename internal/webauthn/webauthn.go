@@ -0,0 +1,296 @@
+// Package webauthn verifies the WebAuthn (passkey) ceremonies browsers run
+// with navigator.credentials: the client data, the authenticator data, the
+// attestation object of a new credential and the signature of a login.
+//
+// Credentials are registered with the "none" attestation conveyance, so
+// attestation statements are not verified: the server trusts the public key
+// the browser reports for a new credential, as most relying parties do.
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Client data types of the two ceremonies
+const (
+	CeremonyCreate = "webauthn.create"
+	CeremonyGet    = "webauthn.get"
+)
+
+// COSE algorithms of the public keys accepted for credentials
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms lists the accepted algorithms in order of preference
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// Authenticator data flags
+const (
+	FlagUserPresent  = 0x01
+	FlagUserVerified = 0x04
+	FlagAttestedData = 0x40
+	FlagExtensions   = 0x80
+)
+
+// ClientData is the part of clientDataJSON the server checks
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ParseClientData decodes clientDataJSON
+func ParseClientData(raw []byte) (*ClientData, error) {
+	var data ClientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("invalid client data: %w", err)
+	}
+	if data.Type == "" || data.Challenge == "" {
+		return nil, errors.New("client data has no type or challenge")
+	}
+	return &data, nil
+}
+
+// Verify checks that the client data belongs to the ceremony with the given
+// type and challenge, run from one of origins
+func (d *ClientData) Verify(ceremony, challenge string, origins []string) error {
+	if d.Type != ceremony {
+		return fmt.Errorf("client data type is %q, want %q", d.Type, ceremony)
+	}
+	if subtle.ConstantTimeCompare([]byte(d.Challenge), []byte(challenge)) != 1 {
+		return errors.New("client data challenge does not match")
+	}
+	if d.CrossOrigin {
+		return errors.New("cross-origin ceremonies are not accepted")
+	}
+	for _, origin := range origins {
+		if d.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q is not allowed", d.Origin)
+}
+
+// AuthenticatorData is the authenticator's signed report of a ceremony.
+// CredentialID and PublicKey are only set for a new credential.
+type AuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte
+	PublicKey    []byte // COSE_Key
+}
+
+// ParseAuthenticatorData decodes authenticator data
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+	data := &AuthenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if data.Flags&FlagAttestedData != 0 {
+		// aaguid (16) || credentialIdLength (2) || credentialId || credentialPublicKey
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLen == 0 || len(rest) < idLen {
+			return nil, errors.New("invalid credential ID length")
+		}
+		data.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, keyLen, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential public key: %w", err)
+		}
+		data.PublicKey = rest[:keyLen]
+		rest = rest[keyLen:]
+	}
+	if data.Flags&FlagExtensions != 0 {
+		_, extLen, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extensions: %w", err)
+		}
+		rest = rest[extLen:]
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing bytes after authenticator data")
+	}
+	return data, nil
+}
+
+// Verify checks that the authenticator data is for rpID and that the user was
+// present, and verified when userVerification is required
+func (d *AuthenticatorData) Verify(rpID string, requireUserVerification bool) error {
+	sum := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(d.RPIDHash, sum[:]) {
+		return errors.New("authenticator data is for another relying party")
+	}
+	if d.Flags&FlagUserPresent == 0 {
+		return errors.New("user was not present")
+	}
+	if requireUserVerification && d.Flags&FlagUserVerified == 0 {
+		return errors.New("user was not verified")
+	}
+	return nil
+}
+
+// ParseAttestationObject decodes the attestation object of a new credential
+// and returns its authenticator data, which must carry the credential
+func ParseAttestationObject(raw []byte) (*AuthenticatorData, error) {
+	item, n, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+	object, ok := item.(map[interface{}]interface{})
+	if !ok || n != len(raw) {
+		return nil, errors.New("attestation object is not a CBOR map")
+	}
+	if _, ok := object["fmt"].(string); !ok {
+		return nil, errors.New("attestation object has no format")
+	}
+	authData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object has no authenticator data")
+	}
+
+	data, err := ParseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if data.CredentialID == nil {
+		return nil, errors.New("attestation object has no credential")
+	}
+	return data, nil
+}
+
+// PublicKeyAlgorithm returns the COSE algorithm of coseKey, and an error when
+// the key is malformed or uses an unsupported algorithm
+func PublicKeyAlgorithm(coseKey []byte) (int64, error) {
+	key, err := parsePublicKey(coseKey)
+	if err != nil {
+		return 0, err
+	}
+	return key.alg, nil
+}
+
+// VerifySignature checks a login assertion: sig must be the signature of
+// authData and the hash of clientDataJSON by the credential's key
+func VerifySignature(coseKey, authData, clientDataJSON, sig []byte) error {
+	key, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	var ok bool
+	switch pub := key.public.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(pub, digest[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, signed, sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	}
+	if !ok {
+		return errors.New("signature does not verify")
+	}
+	return nil
+}
+
+// publicKey is a decoded COSE_Key
+type publicKey struct {
+	alg    int64
+	public crypto.PublicKey
+}
+
+// COSE_Key labels and values (RFC 9053)
+const (
+	coseKty = 1
+	coseAlg = 3
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+func parsePublicKey(coseKey []byte) (*publicKey, error) {
+	item, n, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	m, ok := item.(map[interface{}]interface{})
+	if !ok || n != len(coseKey) {
+		return nil, errors.New("public key is not a COSE key")
+	}
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 public key")
+		}
+		// ecdh rejects points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, errors.New("invalid P-256 public key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return &publicKey{alg: alg, public: pub}, nil
+
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		if crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return &publicKey{alg: alg, public: ed25519.PublicKey(x)}, nil
+
+	case kty == coseKtyRSA && alg == AlgRS256:
+		modulus, _ := m[int64(-1)].([]byte)
+		exponent, _ := m[int64(-2)].([]byte)
+		if len(modulus) < 256 || len(exponent) == 0 || len(exponent) > 4 {
+			return nil, errors.New("invalid RSA public key")
+		}
+		e := 0
+		for _, b := range exponent {
+			e = e<<8 | int(b)
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: e}
+		return &publicKey{alg: alg, public: pub}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %d with algorithm %d", kty, alg)
+}
@@ -0,0 +1,341 @@
+// Package client is a Go SDK for the demo-go HTTP API. It wraps the JSON
+// envelope, retries idempotent requests on transient failures and keeps the
+// bearer token fresh, so services calling the API don't hand-roll HTTP.
+//
+//	c := client.New("https://users.internal")
+//	if _, err := c.Login(ctx, "svc@example.com", "secret"); err != nil { ... }
+//	profile, err := c.GetProfile(ctx)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used when no option overrides them
+const (
+	DefaultTimeout       = 30 * time.Second
+	DefaultMaxRetries    = 3
+	DefaultRetryBackoff  = 200 * time.Millisecond
+	DefaultRefreshBefore = 5 * time.Minute
+)
+
+// Client calls the demo-go API. It is safe for concurrent use.
+type Client struct {
+	baseURL       string
+	httpClient    *http.Client
+	maxRetries    int
+	retryBackoff  time.Duration
+	refreshBefore time.Duration
+	userAgent     string
+
+	mu           sync.Mutex
+	token        string
+	expiresAt    time.Time // zero when the token expiry is unknown
+	refreshToken string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken starts the client with an existing bearer token
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.setToken(token)
+	}
+}
+
+// WithRetries sets how often idempotent requests are retried on network
+// errors, 429 and 502-504 responses, and the initial backoff, which doubles
+// per attempt. Zero retries disables retrying.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithRefreshBefore sets how long before expiry a JWT is refreshed. Zero
+// disables automatic refresh.
+func WithRefreshBefore(d time.Duration) Option {
+	return func(c *Client) {
+		c.refreshBefore = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client for the API at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		httpClient:    &http.Client{Timeout: DefaultTimeout},
+		maxRetries:    DefaultMaxRetries,
+		retryBackoff:  DefaultRetryBackoff,
+		refreshBefore: DefaultRefreshBefore,
+		userAgent:     "demo-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token returns the current bearer token, empty before login
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken replaces the bearer token
+func (c *Client) SetToken(token string) {
+	c.setToken(token)
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.expiresAt = tokenExpiry(token)
+}
+
+// setTokens stores an access token together with the refresh token that renews it
+func (c *Client) setTokens(token, refreshToken string) {
+	c.setToken(token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshToken = refreshToken
+}
+
+// ErrNoRefreshToken is returned by RefreshToken before a login returned a refresh token
+var ErrNoRefreshToken = errors.New("demo-go api: no refresh token")
+
+// APIError is returned for responses with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("demo-go api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("demo-go api: %d: %s", e.StatusCode, e.Message)
+}
+
+// IsStatus reports whether err is an APIError with the given HTTP status
+func IsStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// envelope is the standard response body of every endpoint
+type envelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code string `json:"code"`
+	} `json:"error"`
+	Meta struct {
+		RequestID string `json:"request_id"`
+	} `json:"meta"`
+}
+
+// do sends a request and decodes the envelope data into out (when not nil).
+// Authenticated requests refresh a JWT that is about to expire first.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, authenticated bool) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	if authenticated {
+		if err := c.refreshIfExpiring(ctx); err != nil {
+			return err
+		}
+	}
+	return c.call(ctx, method, path, payload, out, authenticated)
+}
+
+// call sends an encoded request, retrying idempotent methods on transient failures
+func (c *Client) call(ctx context.Context, method, path string, payload []byte, out interface{}, authenticated bool) error {
+	retries := 0
+	if isIdempotent(method) {
+		retries = c.maxRetries
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, payload, authenticated)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return decodeResponse(resp, out)
+		}
+		if attempt >= retries {
+			if err != nil {
+				return err
+			}
+			return decodeResponse(resp, out)
+		}
+
+		wait := backoff
+		if resp != nil {
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+				wait = retryAfter
+			}
+			drain(resp)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, authenticated bool) (*http.Response, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authenticated {
+		if token := c.Token(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// refreshIfExpiring renews a JWT within refreshBefore of its expiry. Tokens
+// without a readable expiry (opaque tokens) or without a refresh token are
+// left alone.
+func (c *Client) refreshIfExpiring(ctx context.Context) error {
+	c.mu.Lock()
+	expiring := c.refreshBefore > 0 && !c.expiresAt.IsZero() && time.Until(c.expiresAt) < c.refreshBefore
+	expiring = expiring && c.refreshToken != ""
+	c.mu.Unlock()
+
+	if !expiring {
+		return nil
+	}
+	_, err := c.RefreshToken(ctx)
+	return err
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close() //nolint:errcheck
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		if resp.StatusCode >= 300 {
+			return &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 || !env.Success {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: env.Message, RequestID: env.Meta.RequestID}
+		if env.Error != nil {
+			apiErr.Code = env.Error.Code
+		}
+		return apiErr
+	}
+
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("decode response data: %w", err)
+	}
+	return nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it. The server
+// verifies tokens; the client only needs to know when to refresh.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.ExpiresAt, 0)
+}
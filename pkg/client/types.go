@@ -0,0 +1,119 @@
+package client
+
+import "time"
+
+// User is a user as returned by the API
+type User struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Email       string       `json:"email"`
+	Role        string       `json:"role"`
+	Status      string       `json:"status,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	AvatarURL   string       `json:"avatar_url,omitempty"`
+	Preferences *Preferences `json:"preferences,omitempty"`
+	// Set on suspended and banned users
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+}
+
+// Preferences holds a user's display preferences
+type Preferences struct {
+	Theme    string `json:"theme,omitempty"`
+	Language string `json:"language,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// RegisterRequest creates a user account
+type RegisterRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role,omitempty"`
+}
+
+// UpdateUserRequest changes a user; nil fields are left unchanged
+type UpdateUserRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Email *string `json:"email,omitempty"`
+	Role  *string `json:"role,omitempty"`
+}
+
+// UpdatePreferencesRequest changes display preferences; nil fields are left
+// unchanged and an empty string clears a preference
+type UpdatePreferencesRequest struct {
+	Theme    *string `json:"theme,omitempty"`
+	Language *string `json:"language,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
+}
+
+// ListUsersOptions selects a page of users for ListUsers
+type ListUsersOptions struct {
+	Limit  int
+	Offset int
+	// Search matches name and email
+	Search string
+	// Filters maps "field" or "field][op" to a value, e.g.
+	// {"role": "admin", "created_at][gte": "2025-01-01"}
+	Filters map[string]string
+	// Sort is a list of fields, "-" prefixed for descending
+	Sort   []string
+	Fields []string
+	// SkipTotal avoids counting every match; UserList.HasMore is set instead of Total
+	SkipTotal bool
+}
+
+// UserList is a page of users
+type UserList struct {
+	Users   []*User `json:"users"`
+	Total   int64   `json:"total"`
+	HasMore bool    `json:"has_more"`
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+}
+
+// Bulk actions accepted by BulkUserAction
+const (
+	BulkActionDelete  = "delete"
+	BulkActionSuspend = "suspend"
+	BulkActionSetRole = "set-role"
+)
+
+// BulkActionRequest applies one action to many users
+type BulkActionRequest struct {
+	IDs    []string `json:"ids"`
+	Action string   `json:"action"`
+	Role   string   `json:"role,omitempty"`
+}
+
+// BulkActionResult reports the outcome of a bulk action
+type BulkActionResult struct {
+	Action    string           `json:"action"`
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+// BulkItemResult is the outcome for one user of a bulk action
+type BulkItemResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// UserStats summarizes the user base
+type UserStats struct {
+	Total    int64 `json:"total"`
+	NewUsers struct {
+		LastDay   int64 `json:"last_day"`
+		LastWeek  int64 `json:"last_week"`
+		LastMonth int64 `json:"last_month"`
+	} `json:"new_users"`
+	DailySignups []struct {
+		Date  string `json:"date"`
+		Count int64  `json:"count"`
+	} `json:"daily_signups"`
+	RoleDistribution map[string]int64 `json:"role_distribution"`
+	ComputedAt       time.Time        `json:"computed_at"`
+}
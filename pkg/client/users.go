@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Register creates a user account
+func (c *Client) Register(ctx context.Context, req *RegisterRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPost, "/auth/register", req, &user, false); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Login authenticates and stores the returned token for later calls
+func (c *Client) Login(ctx context.Context, email, password string) (*User, error) {
+	req := map[string]string{"email": email, "password": password}
+	var result struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+		User         *User  `json:"user"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/auth/login", req, &result, false); err != nil {
+		return nil, err
+	}
+	c.setTokens(result.Token, result.RefreshToken)
+	return result.User, nil
+}
+
+// RefreshToken exchanges the refresh token from the last login or refresh
+// for a new token pair and stores it. Authenticated calls do this
+// automatically shortly before a JWT expires.
+func (c *Client) RefreshToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	refreshToken := c.refreshToken
+	c.mu.Unlock()
+	if refreshToken == "" {
+		return "", ErrNoRefreshToken
+	}
+
+	req := map[string]string{"refresh_token": refreshToken}
+	var result struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/auth/refresh", req, &result, false); err != nil {
+		return "", err
+	}
+	c.setTokens(result.Token, result.RefreshToken)
+	return result.Token, nil
+}
+
+// Logout revokes the current access token and refresh token on the server
+// and forgets both
+func (c *Client) Logout(ctx context.Context) error {
+	c.mu.Lock()
+	req := map[string]string{"refresh_token": c.refreshToken}
+	c.mu.Unlock()
+
+	if err := c.do(ctx, http.MethodPost, "/auth/logout", req, nil, true); err != nil {
+		return err
+	}
+	c.setTokens("", "")
+	return nil
+}
+
+// GetProfile returns the authenticated user
+func (c *Client) GetProfile(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/api/v1/profile", nil, &user, true); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile changes the authenticated user
+func (c *Client) UpdateProfile(ctx context.Context, req *UpdateUserRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPut, "/api/v1/profile", req, &user, true); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ChangePassword changes the authenticated user's password. The server signs
+// out every session, so the client drops its tokens; log in again afterwards.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	req := map[string]string{"current_password": currentPassword, "new_password": newPassword}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/profile/password", req, nil, true); err != nil {
+		return err
+	}
+	c.setTokens("", "")
+	return nil
+}
+
+// GetPreferences returns the authenticated user's display preferences
+func (c *Client) GetPreferences(ctx context.Context) (*Preferences, error) {
+	var prefs Preferences
+	if err := c.do(ctx, http.MethodGet, "/api/v1/profile/preferences", nil, &prefs, true); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences changes the authenticated user's display preferences
+func (c *Client) UpdatePreferences(ctx context.Context, req *UpdatePreferencesRequest) (*Preferences, error) {
+	var prefs Preferences
+	if err := c.do(ctx, http.MethodPut, "/api/v1/profile/preferences", req, &prefs, true); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// ListUsers returns a page of users (admin only)
+func (c *Client) ListUsers(ctx context.Context, opts *ListUsersOptions) (*UserList, error) {
+	path := "/api/v1/admin/users"
+	if query := opts.values().Encode(); query != "" {
+		path += "?" + query
+	}
+
+	var list UserList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list, true); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetUser returns a user by ID (admin only)
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/users/"+url.PathEscape(id), nil, &user, true); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser changes a user, including their role (admin only)
+func (c *Client) UpdateUser(ctx context.Context, id string, req *UpdateUserRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPut, "/api/v1/admin/users/"+url.PathEscape(id), req, &user, true); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser deletes a user (admin only)
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/admin/users/"+url.PathEscape(id), nil, nil, true)
+}
+
+// BulkUserAction applies one action to many users (admin only)
+func (c *Client) BulkUserAction(ctx context.Context, req *BulkActionRequest) (*BulkActionResult, error) {
+	var result BulkActionResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/users/bulk", req, &result, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SuspendUser suspends a user with a reason (admin only)
+func (c *Client) SuspendUser(ctx context.Context, id, reason string) (*User, error) {
+	return c.setUserStatus(ctx, id, "suspend", reason)
+}
+
+// UnsuspendUser lifts a user's suspension (admin only)
+func (c *Client) UnsuspendUser(ctx context.Context, id string) (*User, error) {
+	return c.setUserStatus(ctx, id, "unsuspend", "")
+}
+
+// BanUser bans a user for good, with a reason (admin only)
+func (c *Client) BanUser(ctx context.Context, id, reason string) (*User, error) {
+	return c.setUserStatus(ctx, id, "ban", reason)
+}
+
+func (c *Client) setUserStatus(ctx context.Context, id, action, reason string) (*User, error) {
+	var user User
+	body := map[string]string{"reason": reason}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/users/"+url.PathEscape(id)+"/"+action, body, &user, true); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserStats returns user count and growth statistics (admin only)
+func (c *Client) GetUserStats(ctx context.Context) (*UserStats, error) {
+	var stats UserStats
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/stats/users", nil, &stats, true); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// values encodes the options as list query parameters
+func (o *ListUsersOptions) values() url.Values {
+	values := url.Values{}
+	if o == nil {
+		return values
+	}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		values.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Search != "" {
+		values.Set("q", o.Search)
+	}
+	for field, value := range o.Filters {
+		values.Add("filter["+field+"]", value)
+	}
+	if len(o.Sort) > 0 {
+		values.Set("sort", strings.Join(o.Sort, ","))
+	}
+	if len(o.Fields) > 0 {
+		values.Set("fields", strings.Join(o.Fields, ","))
+	}
+	if o.SkipTotal {
+		values.Set("include_total", "false")
+	}
+	return values
+}
@@ -102,10 +102,10 @@ build_project() {
     
     case $target in
         "server")
-            go build -o "$output_dir/server" cmd/server/main.go
+            go build -o "$output_dir/server" ./cmd/server
             ;;
         "all")
-            go build -o "$output_dir/server" cmd/server/main.go
+            go build -o "$output_dir/server" ./cmd/server
             ;;
         *)
             print_error "Unknown build target: $target"
@@ -88,7 +88,7 @@ echo ""
 echo "🔧 Next Steps:"
 echo "   1. Run: gqlgen generate"
 echo "   2. Update server routes in cmd/server/main.go"
-echo "   3. Start server: go run cmd/server/main.go"
+echo "   3. Start server: go run ./cmd/server"
 echo "   4. Visit: http://localhost:8080/playground"
 echo "   5. Test GraphQL queries and mutations"
 
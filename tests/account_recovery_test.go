@@ -0,0 +1,134 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountRecovery(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+	recoveryService := service.NewAccountRecoveryService(userRepo, repository.NewMemoryResetTokenStore(), ratelimit.NewMemoryLimiter(), nil, nil, revocations, config.RecoveryConfig{
+		Questions:       []string{"First pet?", "Birth city?"},
+		RequiredAnswers: 2,
+		MaxAttempts:     10,
+		AttemptWindow:   time.Minute,
+		TokenTTL:        time.Minute,
+	})
+
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Account Recovery Routes", routes.NewRecoveryRoutes(handler.NewRecoveryHandler(recoveryService)))
+	server := router.SetupRoutes()
+
+	answers := []domain.SecurityAnswer{{Question: "First pet?", Answer: "Rex"}, {Question: "Birth city?", Answer: "Lisbon"}}
+	register := func(email string) *domain.UserResponse {
+		t.Helper()
+		user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Recovery Tester", Email: email, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		if err := recoveryService.SetSecurityQuestions(ctx, user.ID, &domain.SetSecurityQuestionsRequest{Answers: answers}); err != nil {
+			t.Fatalf("SetSecurityQuestions failed: %v", err)
+		}
+		return user
+	}
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	verify := func(email string) (*httptest.ResponseRecorder, string) {
+		rec := send(http.MethodPost, "/auth/recovery/verify", "", domain.VerifyRecoveryRequest{Email: email, Answers: answers})
+		var body struct {
+			Data struct {
+				ResetToken string `json:"reset_token"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body.Data.ResetToken
+	}
+
+	// A recovered account is signed out everywhere
+	register("recover@example.com")
+	accessToken, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "recover@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	rec, resetToken := verify("recover@example.com")
+	if rec.Code != http.StatusOK || resetToken == "" {
+		t.Fatalf("Expected a reset token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodPost, "/auth/recovery/reset", "", domain.ResetPasswordRequest{Token: resetToken, NewPassword: "newpassword1"}); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the reset to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodGet, "/api/v1/profile", accessToken, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected tokens issued before the recovery to be revoked, got %d", rec.Code)
+	}
+
+	// Suspended accounts cannot be recovered, and look like unknown ones
+	suspended := register("suspended@example.com")
+	stored, _ := userRepo.GetByID(ctx, suspended.ID)
+	stored.Status = domain.UserStatusSuspended
+	_ = userRepo.Update(ctx, stored.ID, stored)
+	if rec, token := verify("suspended@example.com"); rec.Code != http.StatusUnauthorized || token != "" {
+		t.Errorf("Expected the recovery of a suspended account to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A reset token stops working once its account is suspended
+	pending := register("pending@example.com")
+	rec, resetToken = verify("pending@example.com")
+	if rec.Code != http.StatusOK || resetToken == "" {
+		t.Fatalf("Expected a reset token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored, _ = userRepo.GetByID(ctx, pending.ID)
+	stored.Status = domain.UserStatusSuspended
+	_ = userRepo.Update(ctx, stored.ID, stored)
+	if rec := send(http.MethodPost, "/auth/recovery/reset", "", domain.ResetPasswordRequest{Token: resetToken, NewPassword: "newpassword1"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the reset of a suspended account to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Unknown and inactive accounts take as many hash comparisons as the
+	// questions they are shown, like wrong answers do
+	hash, _ := bcrypt.GenerateFromPassword([]byte("answer"), bcrypt.DefaultCost)
+	compare := time.Hour
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		_ = bcrypt.CompareHashAndPassword(hash, []byte("x"))
+		compare = min(compare, time.Since(start))
+	}
+	for _, email := range []string{"unknown@example.com", "suspended@example.com"} {
+		start := time.Now()
+		verify(email)
+		if elapsed := time.Since(start); elapsed < compare*3/2 {
+			t.Errorf("Expected %s to take two hash comparisons (%v each), took %v", email, compare, elapsed)
+		}
+	}
+}
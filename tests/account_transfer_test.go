@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestAccountTransfer(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.TransferConfig{SigningKey: "shared-secret", Source: "staging", BundleTTL: time.Hour, MaxUsers: 10}
+	tokens := service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour)
+
+	sourceRepo := repository.NewMemoryUserRepository()
+	registered, err := service.NewUserService(sourceRepo, tokens).Register(ctx, &domain.CreateUserRequest{
+		Name: "Moving User", Email: "mover@example.com", Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	source := service.NewTransferService(sourceRepo, repository.NewMemoryPreferencesRepository(), nil, nil, nil, cfg)
+	bundle, err := source.Export(ctx, "admin", &domain.ExportUsersRequest{IDs: []string{registered.ID}})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	targetRepo := repository.NewMemoryUserRepository()
+	target := service.NewTransferService(targetRepo, repository.NewMemoryPreferencesRepository(), nil, nil, nil, cfg)
+
+	tampered := *bundle
+	tampered.Users = []*domain.ExportedUser{{ID: "9", Email: "evil@example.com", PasswordHash: "x", Role: "admin"}}
+	if _, err := target.Import(ctx, "admin", &domain.ImportUsersRequest{Bundle: &tampered}); err != domain.ErrInvalidBundle {
+		t.Errorf("Expected tampered bundle to be rejected, got %v", err)
+	}
+
+	results, err := target.Import(ctx, "admin", &domain.ImportUsersRequest{Bundle: bundle})
+	if err != nil || len(results) != 1 || results[0].Outcome != domain.ImportOutcomeCreated {
+		t.Fatalf("Expected user to be created, got %+v (%v)", results, err)
+	}
+
+	// The imported password hash works on the target instance
+	if _, _, err := service.NewUserService(targetRepo, tokens).Login(ctx, &domain.LoginRequest{
+		Email: "mover@example.com", Password: "password123",
+	}); err != nil {
+		t.Errorf("Expected login with imported credentials, got %v", err)
+	}
+
+	results, _ = target.Import(ctx, "admin", &domain.ImportUsersRequest{Bundle: bundle})
+	if results[0].Outcome != domain.ImportOutcomeSkipped {
+		t.Errorf("Expected existing user to be skipped by default, got %s", results[0].Outcome)
+	}
+	results, _ = target.Import(ctx, "admin", &domain.ImportUsersRequest{Bundle: bundle, Conflict: domain.ImportConflictOverwrite})
+	if results[0].Outcome != domain.ImportOutcomeOverwritten {
+		t.Errorf("Expected existing user to be overwritten, got %s", results[0].Outcome)
+	}
+	if _, err := target.Import(ctx, "admin", &domain.ImportUsersRequest{Bundle: bundle, Conflict: domain.ImportConflictFail}); err != domain.ErrImportConflict {
+		t.Errorf("Expected ErrImportConflict in fail mode, got %v", err)
+	}
+}
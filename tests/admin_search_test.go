@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/policy"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// slowAuditService never answers before its context expires
+type slowAuditService struct {
+	domain.AuditService
+}
+
+func (slowAuditService) ListEvents(ctx context.Context, _ domain.AuditFilter, _, _ int) ([]*domain.AuditEvent, int64, error) {
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+func TestAdminSearch(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	audit := service.NewAuditService(repository.NewMemoryAuditRepository())
+	sessions := repository.NewMemorySessionRepository()
+	cfg := config.AdminSearchConfig{SourceTimeout: 100 * time.Millisecond, MaxConcurrent: 2}
+
+	// Support staff investigate with user.read and audit.read, without being admins
+	policyEngine, _ := policy.NewMatrix(map[string][]string{"admin": {"*"}, "support": {domain.PermissionUserRead, domain.PermissionAuditRead}, "viewer": {domain.PermissionUserRead}})
+	newServer := func(search domain.AdminSearchService) http.Handler {
+		jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+		jwtMiddleware.SetPolicyEngine(policyEngine)
+		router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+		router.AddRouteGroup("Admin Search Routes", routes.NewAdminSearchRoutes(handler.NewAdminSearchHandler(search, jwtMiddleware)))
+		return router.SetupRoutes()
+	}
+	server := newServer(service.NewAdminSearchService(userService, audit, sessions, cfg))
+
+	user := &domain.User{ID: "user-42", Name: "Support Case", Email: "case@example.com", Role: "user"}
+	_ = userRepo.Create(ctx, user)
+	_ = audit.Record(ctx,
+		&domain.AuditEvent{Action: domain.AuditActionUserUpdated, ActorID: "admin-1", TargetID: user.ID},
+		&domain.AuditEvent{Action: domain.AuditActionUserUpdated, ActorID: "admin-1", TargetID: "someone-else"},
+	)
+	now := time.Now()
+	_ = sessions.Save(ctx, &domain.Session{ID: "s1", UserID: user.ID, IPAddress: "203.0.113.7", LastSeenAt: now, ExpiresAt: now.Add(time.Hour)})
+	_ = sessions.Save(ctx, &domain.Session{ID: "s2", UserID: "other", IPAddress: "203.0.113.7", LastSeenAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour)})
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: user.ID, Email: user.Email, Role: "user"})
+	search := func(server http.Handler, query, token string) (*httptest.ResponseRecorder, domain.AdminSearchResults) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/search?q="+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		var body struct {
+			Data domain.AdminSearchResults `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body.Data
+	}
+
+	// A user ID is found in every source
+	rec, results := search(server, user.ID, adminToken)
+	if rec.Code != http.StatusOK || len(results.AuditEvents) != 1 || len(results.Sessions) != 1 || len(results.Errors) != 0 {
+		t.Fatalf("Expected the user's audit event and session, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// An email finds the user, and through it the user's audit event and session
+	_, results = search(server, "case@example.com", adminToken)
+	if len(results.Users) != 1 || results.Users[0].ID != user.ID {
+		t.Errorf("Expected the user to be found by email, got %+v", results.Users)
+	}
+	if len(results.AuditEvents) != 1 || results.AuditEvents[0].TargetID != user.ID || len(results.Sessions) != 1 || results.Sessions[0].ID != "s1" {
+		t.Errorf("Expected the email to find the user's audit event and session, got %+v, %+v", results.AuditEvents, results.Sessions)
+	}
+
+	// An IP address finds every session from it
+	if _, results := search(server, "203.0.113.7", adminToken); len(results.Sessions) != 2 || results.Sessions[0].ID != "s1" {
+		t.Errorf("Expected both sessions from the IP, most recent first, got %+v", results.Sessions)
+	}
+
+	// A slow source times out on its own
+	slow := newServer(service.NewAdminSearchService(userService, slowAuditService{}, sessions, cfg))
+	rec, results = search(slow, user.ID, adminToken)
+	if rec.Code != http.StatusOK || results.Errors[domain.AdminSearchSourceAudit] != "timed out" || len(results.Sessions) != 1 {
+		t.Errorf("Expected the audit source to time out and the others to answer, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec, _ := search(server, "ab", adminToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected short queries to be rejected, got %d", rec.Code)
+	}
+	if rec, _ := search(server, user.ID, userToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected users to be refused the admin search, got %d", rec.Code)
+	}
+	supportToken, _ := tokenService.GenerateToken(&domain.User{ID: "support-1", Email: "support@example.com", Role: "support"})
+	if rec, results := search(server, user.ID, supportToken); rec.Code != http.StatusOK || len(results.AuditEvents) != 1 {
+		t.Errorf("Expected support staff to search, got %d: %s", rec.Code, rec.Body.String())
+	}
+	viewerToken, _ := tokenService.GenerateToken(&domain.User{ID: "viewer-1", Email: "viewer@example.com", Role: "viewer"})
+	if rec, _ := search(server, user.ID, viewerToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected roles without audit.read to be refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestAnnouncements(t *testing.T) {
+	announcements := service.NewAnnouncementService(repository.NewMemoryAnnouncementStore(), nil, time.Minute)
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userHandler := handler.NewUserHandler(service.NewUserService(repository.NewMemoryUserRepository(), tokenService))
+	userHandler.SetAnnouncementService(announcements)
+	router := routes.NewRouter(userHandler, middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Announcement Routes", routes.NewAnnouncementRoutes(handler.NewAnnouncementHandler(announcements)))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodGet, "/api/v1/announcements", "", ""); rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"announcements":[]`)) {
+		t.Fatalf("Expected no announcements, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/announcement", userToken, `{"message":"Hi"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected users to be refused, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/announcement", adminToken, `{"message":"Hi","severity":"loud"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected unknown severities to be rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/announcement", adminToken, `{"message":"Hi","expires_at":"2020-01-01T00:00:00Z"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected past expiries to be rejected, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodPut, "/api/v1/admin/announcement", adminToken, `{"message":"Maintenance at 22:00","severity":"warning"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the announcement to be set, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Clients see the announcement without the admin who set it
+	for _, path := range []string{"/api/v1/announcements", "/health"} {
+		rec := do(http.MethodGet, path, "", "")
+		if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte("Maintenance at 22:00")) {
+			t.Errorf("Expected %s to include the announcement, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		if bytes.Contains(rec.Body.Bytes(), []byte("admin-1")) {
+			t.Errorf("Expected %s not to reveal the admin, got %s", path, rec.Body.String())
+		}
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/admin/announcement", adminToken, ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the announcement to be cleared, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/announcement", adminToken, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected no announcement after clearing, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/health", "", ""); bytes.Contains(rec.Body.Bytes(), []byte("Maintenance")) {
+		t.Errorf("Expected the cleared announcement to be gone from health, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestAPIIndex(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.SetVersion("2.3.4")
+	router.DisablePasswordLogin()
+	server := router.SetupRoutes()
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// API clients get the index without a token
+	rec := get("/", "application/json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the index to be public, got %d", rec.Code)
+	}
+	var envelope struct {
+		Data routes.APIIndex `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+	index := envelope.Data
+	if index.Version != "2.3.4" || index.Links["health"] != "/health" || index.Links["openapi"] != "/openapi.json" || index.Links["docs"] != "/docs" {
+		t.Errorf("Expected the version and links in the index, got %+v", index)
+	}
+	paths := map[string]bool{}
+	for _, endpoint := range index.Auth {
+		paths[endpoint.Path] = true
+	}
+	if !paths["/auth/refresh"] || paths["/auth/login"] {
+		t.Errorf("Expected the registered auth endpoints only, got %+v", index.Auth)
+	}
+
+	// Browsers get a landing page linking to the same places
+	rec = get("/", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") || !strings.Contains(rec.Body.String(), `href="/openapi.json"`) ||
+		!strings.Contains(rec.Body.String(), "/auth/refresh") {
+		t.Errorf("Expected an HTML landing page, got %s: %s", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	// The docs page lists the routes
+	rec = get("/docs", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/api/v1/profile") {
+		t.Errorf("Expected the docs page to list the routes, got %d", rec.Code)
+	}
+}
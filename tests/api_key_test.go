@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	auditService := service.NewAuditService(repository.NewMemoryAuditRepository())
+	apiKeys := service.NewAPIKeyService(repository.NewMemoryAPIKeyRepository(), userRepo, auditService, "user", config.APIKeyConfig{MaxPerUser: 3})
+
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal(),
+		mux.MiddlewareFunc(middleware.NewAPIKeyMiddleware(apiKeys, "user").Authenticate))
+	router.AddRouteGroup("API Key Routes", routes.NewAPIKeyRoutes(handler.NewAPIKeyHandler(apiKeys)))
+	server := router.SetupRoutes()
+
+	member, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Key User", Email: "keys@example.com", Password: "password123"})
+	memberToken, _ := tokenService.GenerateToken(&domain.User{ID: member.ID, Email: member.Email, Role: member.Role})
+	admin := &domain.User{Name: "Key Admin", Email: "key-admin@example.com", Role: "admin"}
+	_ = userRepo.Create(ctx, admin)
+	adminToken, _ := tokenService.GenerateToken(admin)
+
+	// send authenticates with a bearer token, or with an API key when it has the key prefix
+	send := func(method, path, credential string, body interface{}) (int, string) {
+		var payload bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&payload).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &payload)
+		if strings.HasPrefix(credential, "dgk_") {
+			req.Header.Set(middleware.HeaderAPIKey, credential)
+		} else if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+	create := func(path, token string, scopes ...string) *domain.CreatedAPIKey {
+		status, body := send(http.MethodPost, path, token, domain.CreateAPIKeyRequest{Name: "ci", Scopes: scopes})
+		var created struct {
+			Data domain.CreatedAPIKey `json:"data"`
+		}
+		_ = json.Unmarshal([]byte(body), &created)
+		if status != http.StatusCreated || !strings.HasPrefix(created.Data.Key, created.Data.Prefix) {
+			t.Fatalf("Expected an API key to be created, got %d: %s", status, body)
+		}
+		return &created.Data
+	}
+
+	readKey := create("/api/v1/profile/api-keys", memberToken, "read")
+	writeKey := create("/api/v1/profile/api-keys", memberToken, "write")
+
+	// Keys authenticate as their owner within their scopes
+	if status, body := send(http.MethodGet, "/api/v1/profile", readKey.Key, nil); status != http.StatusOK || !strings.Contains(body, member.ID) {
+		t.Errorf("Expected a read key to read the profile, got %d: %s", status, body)
+	}
+	if status, _ := send(http.MethodPut, "/api/v1/profile", readKey.Key, map[string]string{"name": "Renamed"}); status != http.StatusForbidden {
+		t.Errorf("Expected a read key to be refused writes, got %d", status)
+	}
+	if status, _ := send(http.MethodPut, "/api/v1/profile", writeKey.Key, map[string]string{"name": "Renamed"}); status != http.StatusOK {
+		t.Errorf("Expected a write key to update the profile, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", "dgk_unknown", nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown key to be refused, got %d", status)
+	}
+
+	// Keys cannot manage keys, and secrets are never listed
+	if status, _ := send(http.MethodPost, "/api/v1/profile/api-keys", writeKey.Key, domain.CreateAPIKeyRequest{Name: "more", Scopes: []string{"write"}}); status != http.StatusForbidden {
+		t.Errorf("Expected a key to be refused creating keys, got %d", status)
+	}
+	if status, body := send(http.MethodGet, "/api/v1/profile/api-keys", memberToken, nil); status != http.StatusOK ||
+		strings.Contains(body, readKey.Key) || strings.Contains(body, `"hash"`) || !strings.Contains(body, readKey.Prefix) {
+		t.Errorf("Expected the key list without secrets, got %d: %s", status, body)
+	}
+
+	// The admin scope needs a privileged owner, and limits apply
+	if status, _ := send(http.MethodPost, "/api/v1/profile/api-keys", memberToken, domain.CreateAPIKeyRequest{Name: "x", Scopes: []string{"admin"}}); status != http.StatusBadRequest {
+		t.Errorf("Expected the admin scope to be refused for a regular user, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/api/v1/profile/api-keys", memberToken, domain.CreateAPIKeyRequest{Name: "x", Scopes: []string{"delete"}}); status != http.StatusBadRequest {
+		t.Errorf("Expected an unknown scope to be refused, got %d", status)
+	}
+	create("/api/v1/profile/api-keys", memberToken, "read")
+	if status, _ := send(http.MethodPost, "/api/v1/profile/api-keys", memberToken, domain.CreateAPIKeyRequest{Name: "x", Scopes: []string{"read"}}); status != http.StatusBadRequest {
+		t.Errorf("Expected the per-user limit to apply, got %d", status)
+	}
+
+	// Admin keys reach admin routes only with the admin scope
+	adminKey := create("/api/v1/profile/api-keys", adminToken, "read", "admin")
+	plainAdminKey := create("/api/v1/profile/api-keys", adminToken, "read")
+	if status, _ := send(http.MethodGet, "/api/v1/admin/users", adminKey.Key, nil); status != http.StatusOK {
+		t.Errorf("Expected an admin-scoped key to list users, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/admin/users", plainAdminKey.Key, nil); status != http.StatusForbidden {
+		t.Errorf("Expected a key without the admin scope to be refused admin routes, got %d", status)
+	}
+
+	// Admins manage the keys of users; revoked keys stop working
+	if status, _ := send(http.MethodDelete, "/api/v1/admin/users/"+member.ID+"/api-keys/"+readKey.ID, adminToken, nil); status != http.StatusOK {
+		t.Errorf("Expected an admin to revoke the key, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", readKey.Key, nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected a revoked key to be refused, got %d", status)
+	}
+	serviceKey := create("/api/v1/admin/users/"+member.ID+"/api-keys", adminToken, "read")
+	if serviceKey.UserID != member.ID || serviceKey.CreatedBy != admin.ID {
+		t.Errorf("Expected the key to belong to the user and name its creator, got %+v", serviceKey.APIKey)
+	}
+	if status, _ := send(http.MethodDelete, "/api/v1/profile/api-keys/"+adminKey.ID, memberToken, nil); status != http.StatusNotFound {
+		t.Errorf("Expected keys of other users not to be found, got %d", status)
+	}
+
+	// Keys of suspended users stop working
+	suspended, _ := userRepo.GetByID(ctx, member.ID)
+	suspended.Status = domain.UserStatusSuspended
+	_ = userRepo.Update(ctx, member.ID, suspended)
+	if status, _ := send(http.MethodGet, "/api/v1/profile", writeKey.Key, nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected keys of a suspended user to be refused, got %d", status)
+	}
+}
@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthMetrics(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Auth Metrics Routes", routes.NewAuthMetricsRoutes(handler.NewAuthMetricsHandler(jwtMiddleware.Metrics())))
+	server := router.SetupRoutes()
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1", "email": "user@example.com", "role": "user",
+		"exp": time.Now().Add(-time.Hour).Unix(), "iat": time.Now().Add(-2 * time.Hour).Unix(),
+	}).SignedString([]byte("test-secret"))
+
+	get("/health", "")
+	get("/api/v1/profile", "")
+	get("/api/v1/profile", "not-a-token")
+	get("/api/v1/profile", expired)
+	get("/api/v1/profile", userToken)
+
+	// Only admins see the metrics
+	if status := get("/api/v1/admin/auth/metrics", userToken); status != http.StatusForbidden {
+		t.Errorf("Expected non-admins to be refused, got %d", status)
+	}
+
+	snapshot := jwtMiddleware.Metrics().Snapshot()
+	outcomes := snapshot.Outcomes
+	if outcomes[middleware.AuthOutcomeMissingHeader] != 1 || outcomes[middleware.AuthOutcomeInvalidToken] != 1 ||
+		outcomes[middleware.AuthOutcomeExpiredToken] != 1 || outcomes[middleware.AuthOutcomeSuccess] != 2 {
+		t.Errorf("Expected each outcome to be counted, got %v", outcomes)
+	}
+	if snapshot.SkipHits["/health"] != 1 {
+		t.Errorf("Expected the skip rule hit to be counted, got %v", snapshot.SkipHits)
+	}
+	if len(snapshot.RecentFailures) != 3 || snapshot.RecentFailures[0].Reason != middleware.AuthOutcomeExpiredToken ||
+		snapshot.RecentFailures[0].Path != "/api/v1/profile" || snapshot.RecentReasons[middleware.AuthOutcomeInvalidToken] != 1 {
+		t.Errorf("Expected the recent failures newest first, got %+v", snapshot.RecentFailures)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/auth/metrics", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	var envelope struct {
+		Data middleware.AuthMetricsSnapshot `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+	if rec.Code != http.StatusOK || envelope.Data.RecentReasons[middleware.AuthOutcomeMissingHeader] != 1 {
+		t.Errorf("Expected the admin endpoint to report the metrics, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), expired) {
+		t.Error("Expected tokens not to be reported")
+	}
+}
@@ -0,0 +1,126 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+)
+
+// flakyCache is a cache.Service whose availability can be toggled
+type flakyCache struct {
+	mu    sync.Mutex
+	down  bool
+	calls int
+}
+
+func (c *flakyCache) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func (c *flakyCache) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func (c *flakyCache) result() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.down {
+		return errors.New("dial tcp: connection refused")
+	}
+	return nil
+}
+
+func (c *flakyCache) GetUser(context.Context, string) (*domain.UserResponse, error) {
+	if err := c.result(); err != nil {
+		return nil, err
+	}
+	return nil, domain.ErrUserNotFound
+}
+func (c *flakyCache) SetUser(context.Context, string, *domain.UserResponse, time.Duration) error {
+	return c.result()
+}
+func (c *flakyCache) DeleteUser(context.Context, string) error       { return c.result() }
+func (c *flakyCache) Get(context.Context, string, interface{}) error { return c.result() }
+func (c *flakyCache) Set(context.Context, string, interface{}, time.Duration) error {
+	return c.result()
+}
+func (c *flakyCache) Delete(context.Context, string) error         { return c.result() }
+func (c *flakyCache) Exists(context.Context, string) (bool, error) { return false, c.result() }
+func (c *flakyCache) SetNX(context.Context, string, interface{}, time.Duration) (bool, error) {
+	return true, c.result()
+}
+func (c *flakyCache) GetDel(context.Context, string, interface{}) error { return c.result() }
+func (c *flakyCache) SlidingWindow(context.Context, string, int, time.Duration, bool) (int, time.Time, error) {
+	return 0, time.Time{}, c.result()
+}
+func (c *flakyCache) IncrementFields(context.Context, string, map[string]int64, time.Duration) error {
+	return c.result()
+}
+func (c *flakyCache) GetFields(context.Context, string) (map[string]int64, error) {
+	return nil, c.result()
+}
+func (c *flakyCache) DeleteByPattern(context.Context, string) error { return c.result() }
+func (c *flakyCache) Ping(context.Context) error                    { return c.result() }
+func (c *flakyCache) Close() error                                  { return nil }
+
+func TestDegradingCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &flakyCache{}
+	degrading := cache.NewDegradingCache(inner, config.CacheDegradationConfig{
+		FailureThreshold: 3,
+		Cooldown:         20 * time.Millisecond,
+		ProbeTimeout:     time.Second,
+	})
+	defer degrading.Close()
+
+	// Cache misses are not failures
+	for i := 0; i < 5; i++ {
+		if _, err := degrading.GetUser(ctx, "1"); err != domain.ErrUserNotFound {
+			t.Fatalf("Expected cache miss, got %v", err)
+		}
+	}
+	if health := degrading.CheckHealth(ctx); health.Status != domain.HealthStatusHealthy {
+		t.Fatalf("Expected healthy cache after misses, got %s", health.Status)
+	}
+
+	inner.setDown(true)
+	for i := 0; i < 3; i++ {
+		_ = degrading.Set(ctx, "key", "value", time.Minute)
+	}
+	if health := degrading.CheckHealth(ctx); health.Status != domain.HealthStatusDegraded {
+		t.Fatalf("Expected degraded cache after repeated failures, got %s", health.Status)
+	}
+
+	// Calls are short-circuited while degraded
+	before := inner.callCount()
+	if err := degrading.Set(ctx, "key", "value", time.Minute); err != cache.ErrCacheDegraded {
+		t.Errorf("Expected ErrCacheDegraded, got %v", err)
+	}
+	if inner.callCount() != before {
+		t.Error("Expected degraded cache not to call the underlying cache")
+	}
+
+	// The background probe restores the cache once it answers again
+	inner.setDown(false)
+	deadline := time.Now().Add(time.Second)
+	for degrading.CheckHealth(ctx).Status != domain.HealthStatusHealthy {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected cache to recover after probe succeeded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := degrading.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Errorf("Expected cache calls to resume after recovery, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package handler_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+// countingListService counts the user list pages it loads
+type countingListService struct {
+	domain.UserService
+	loads int32
+}
+
+func (s *countingListService) GetUsers(ctx context.Context, limit, offset int, fields ...string) ([]*domain.UserResponse, int64, error) {
+	atomic.AddInt32(&s.loads, 1)
+	return s.UserService.GetUsers(ctx, limit, offset, fields...)
+}
+
+func TestListPageCacheStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	underlying := &countingListService{UserService: service.NewUserService(repository.NewMemoryUserRepository(), tokenService)}
+	users := service.NewCachedUserService(underlying, &jsonMapCache{values: map[string][]byte{}}, time.Minute,
+		service.WithListPageCache(config.ListPageCacheConfig{Enabled: true, SoftTTL: 100 * time.Millisecond, HardTTL: time.Minute}))
+
+	register := func(svc domain.UserService, email string) {
+		t.Helper()
+		if _, err := svc.Register(ctx, &domain.CreateUserRequest{Name: "Test User", Email: email, Password: "password123"}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+	list := func() int {
+		t.Helper()
+		page, total, err := users.GetUsers(ctx, 10, 0)
+		if err != nil || int(total) != len(page) {
+			t.Fatalf("GetUsers failed: %v (total %d for %d users)", err, total, len(page))
+		}
+		return len(page)
+	}
+	waitForLoads := func(want int32) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for atomic.LoadInt32(&underlying.loads) < want && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if loads := atomic.LoadInt32(&underlying.loads); loads != want {
+			t.Fatalf("Expected %d page loads, got %d", want, loads)
+		}
+	}
+
+	register(underlying, "one@example.com")
+	register(underlying, "two@example.com")
+	if n := list(); n != 2 || list() != 2 {
+		t.Fatalf("Expected the page of 2 users, got %d", n)
+	}
+	waitForLoads(1)
+
+	// A write leaves the cached page in place but has it refreshed
+	register(users, "three@example.com")
+	if n := list(); n != 2 {
+		t.Errorf("Expected the stale page to be served right away, got %d users", n)
+	}
+	deadline := time.Now().Add(time.Second)
+	for list() != 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := list(); n != 3 {
+		t.Errorf("Expected the refreshed page, got %d users", n)
+	}
+	waitForLoads(2)
+
+	// Pages past the soft TTL are refreshed as well
+	time.Sleep(120 * time.Millisecond)
+	list()
+	waitForLoads(3)
+
+	// Projected pages always go to the service
+	if _, _, err := users.GetUsers(ctx, 10, 0, "id"); err != nil || atomic.LoadInt32(&underlying.loads) != 4 {
+		t.Errorf("Expected projected pages to bypass the cache, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package handler_test
+
+import (
+	"testing"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+)
+
+func TestCacheKeyPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		ns      config.CacheNamespaceConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "no namespace", want: ""},
+		{name: "all parts", ns: config.CacheNamespaceConfig{Environment: "prod", Region: "eu-west-1", Tenant: "acme"}, want: "prod:eu-west-1:acme:"},
+		{name: "empty parts are left out", ns: config.CacheNamespaceConfig{Environment: "staging", Tenant: "acme"}, want: "staging:acme:"},
+		{name: "separator in a part", ns: config.CacheNamespaceConfig{Environment: "prod:eu"}, wantErr: true},
+		{name: "glob in a part", ns: config.CacheNamespaceConfig{Tenant: "acme*"}, wantErr: true},
+		{name: "collides with a key family", ns: config.CacheNamespaceConfig{Environment: "user"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.KeyPrefix(tt.ns)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got prefix %q", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Expected %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
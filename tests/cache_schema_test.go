@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// jsonMapCache is a cache.Service that stores values as JSON, like Redis
+type jsonMapCache struct {
+	flakyCache
+	valuesMu sync.Mutex
+	values   map[string][]byte
+}
+
+func (c *jsonMapCache) Get(_ context.Context, key string, result interface{}) error {
+	c.valuesMu.Lock()
+	raw, ok := c.values[key]
+	c.valuesMu.Unlock()
+	if !ok {
+		return redis.Nil
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func (c *jsonMapCache) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	raw, err := json.Marshal(value)
+	c.valuesMu.Lock()
+	defer c.valuesMu.Unlock()
+	c.values[key] = raw
+	return err
+}
+
+func TestCacheSchemaVersioning(t *testing.T) {
+	ctx := context.Background()
+	c := &jsonMapCache{values: map[string][]byte{}}
+	user := &domain.UserResponse{ID: "user-1", Name: "Test User", Email: "test@example.com", Status: domain.UserStatusActive}
+
+	if err := cache.SetVersioned(ctx, c, "user:user-1", user, time.Minute); err != nil {
+		t.Fatalf("SetVersioned failed: %v", err)
+	}
+	var cached domain.UserResponse
+	if err := cache.GetVersioned(ctx, c, "user:user-1", &cached); err != nil || cached.Email != user.Email {
+		t.Fatalf("Expected the cached user back, got %+v %v", cached, err)
+	}
+
+	// Entries written before versioning, or by a release with another shape, are misses
+	_ = c.Set(ctx, "user:legacy", user, time.Minute)
+	if err := cache.GetVersioned(ctx, c, "user:legacy", &cached); !errors.Is(err, cache.ErrSchemaMismatch) {
+		t.Errorf("Expected an unversioned entry to mismatch, got %v", err)
+	}
+	type olderUserResponse struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	}
+	_ = cache.SetVersioned(ctx, c, "user:older", &olderUserResponse{ID: "user-2", Username: "old"}, time.Minute)
+	cached = domain.UserResponse{}
+	if err := cache.GetVersioned(ctx, c, "user:older", &cached); !errors.Is(err, cache.ErrSchemaMismatch) || cached.ID != "" {
+		t.Errorf("Expected an entry of another shape to mismatch untouched, got %+v %v", cached, err)
+	}
+
+	if cache.SchemaOf(user) != cache.SchemaOf(domain.UserResponse{}) || cache.SchemaOf(user) == cache.SchemaOf(&olderUserResponse{}) {
+		t.Error("Expected the schema to follow the type's JSON shape only")
+	}
+	if err := cache.GetVersioned(ctx, c, "user:missing", &cached); err != redis.Nil {
+		t.Errorf("Expected misses to pass through, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package handler_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+// userMapCache is a cache.Service that only stores users
+type userMapCache struct {
+	flakyCache
+	mu    sync.Mutex
+	users map[string]*domain.UserResponse
+}
+
+func newUserMapCache() *userMapCache {
+	return &userMapCache{users: make(map[string]*domain.UserResponse)}
+}
+
+func (c *userMapCache) GetUser(_ context.Context, userID string) (*domain.UserResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if user, ok := c.users[userID]; ok {
+		return user, nil
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (c *userMapCache) SetUser(_ context.Context, userID string, user *domain.UserResponse, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users[userID] = user
+	return nil
+}
+
+func (c *userMapCache) DeleteUser(_ context.Context, userID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, userID)
+	return nil
+}
+
+func TestParseCacheStrategies(t *testing.T) {
+	if _, err := service.ParseCacheStrategies(map[string]string{"get_profile": "write-behind"}); err == nil {
+		t.Error("Expected write-behind to be rejected for a read operation")
+	}
+	if _, err := service.ParseCacheStrategies(map[string]string{"unknown_op": "none"}); err == nil {
+		t.Error("Expected unknown operation to be rejected")
+	}
+
+	strategies, err := service.ParseCacheStrategies(map[string]string{"update_profile": "Write-Behind"})
+	if err != nil {
+		t.Fatalf("ParseCacheStrategies failed: %v", err)
+	}
+	if strategies[service.CacheOpUpdateProfile] != service.CacheStrategyWriteBehind {
+		t.Errorf("Expected write-behind for update_profile, got %q", strategies[service.CacheOpUpdateProfile])
+	}
+}
+
+func TestCachedUserServiceStrategies(t *testing.T) {
+	ctx := context.Background()
+	userCache := newUserMapCache()
+	queue := cache.NewWriteBehindQueue(userCache, config.WriteBehindConfig{QueueSize: 10})
+
+	strategies, err := service.ParseCacheStrategies(map[string]string{
+		"register":       "none",
+		"get_profile":    "none",
+		"update_profile": "write-behind",
+	})
+	if err != nil {
+		t.Fatalf("ParseCacheStrategies failed: %v", err)
+	}
+	userService := service.NewCachedUserService(
+		service.NewUserService(
+			repository.NewMemoryUserRepository(),
+			service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour),
+		),
+		userCache,
+		time.Minute,
+		service.WithCacheStrategies(strategies),
+		service.WithWriteBehindQueue(queue),
+	)
+
+	user, err := userService.Register(ctx, &domain.CreateUserRequest{
+		Name: "Cache Tester", Email: "cache@example.com", Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := userCache.GetUser(ctx, user.ID); err == nil {
+		t.Error("Expected register with strategy none not to cache the user")
+	}
+
+	if _, err := userService.GetProfile(ctx, user.ID); err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if _, err := userCache.GetUser(ctx, user.ID); err == nil {
+		t.Error("Expected get_profile with strategy none not to populate the cache")
+	}
+
+	// Read-through is still the default for lookups by ID
+	if _, err := userService.GetUserByID(ctx, user.ID); err != nil {
+		t.Fatalf("GetUserByID failed: %v", err)
+	}
+	if _, err := userCache.GetUser(ctx, user.ID); err != nil {
+		t.Error("Expected get_user_by_id to populate the cache")
+	}
+
+	name := "Renamed Tester"
+	if _, err := userService.UpdateProfile(ctx, user.ID, &domain.UpdateUserRequest{Name: &name}); err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+
+	// Closing the queue flushes the pending write-behind update
+	if err := queue.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	cached, err := userCache.GetUser(ctx, user.ID)
+	if err != nil || cached.Name != name {
+		t.Errorf("Expected flushed cache entry named %q, got %+v (%v)", name, cached, err)
+	}
+}
+
+func TestCachedUserServiceTombstones(t *testing.T) {
+	ctx := context.Background()
+	userCache := newUserMapCache()
+	queue := cache.NewWriteBehindQueue(userCache, config.WriteBehindConfig{QueueSize: 10})
+	userService := service.NewCachedUserService(
+		service.NewUserService(
+			repository.NewMemoryUserRepository(),
+			service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour),
+		),
+		userCache,
+		time.Minute,
+		service.WithWriteBehindQueue(queue),
+	)
+
+	register := func(email string) *domain.UserResponse {
+		user, err := userService.Register(ctx, &domain.CreateUserRequest{
+			Name: "Tombstone Tester", Email: email, Password: "password123",
+		})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		return user
+	}
+	deleted := register("deleted@example.com")
+	suspended := register("suspended@example.com")
+
+	stale := *deleted
+	if err := userService.DeleteUser(ctx, deleted.ID); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	// A write queued from an older read must not resurrect the deleted user
+	queue.SetUser(deleted.ID, &stale, time.Minute)
+	if err := queue.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := userService.GetUserByID(ctx, deleted.ID); err != domain.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for a deleted user, got %v", err)
+	}
+
+	// A suspended user cached while active is refused from the cache as well
+	if _, err := userService.BulkUserAction(ctx, "admin-1", &domain.BulkUserActionRequest{
+		IDs: []string{suspended.ID}, Action: domain.BulkActionSuspend, Reason: "Spam reports",
+	}); err != nil {
+		t.Fatalf("BulkUserAction failed: %v", err)
+	}
+	if err := userCache.SetUser(ctx, suspended.ID, &domain.UserResponse{ID: suspended.ID, Status: domain.UserStatusSuspended}, time.Minute); err != nil {
+		t.Fatalf("SetUser failed: %v", err)
+	}
+	if _, err := userService.GetProfile(ctx, suspended.ID); err != domain.ErrAccountSuspended {
+		t.Errorf("Expected ErrAccountSuspended from the cache path, got %v", err)
+	}
+	if _, err := userService.GetUserByID(ctx, suspended.ID); err != nil {
+		t.Errorf("Expected admins to still see suspended users, got %v", err)
+	}
+}
+
+func TestCachedUserServiceMissFillAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	userCache := newUserMapCache()
+	read, release := make(chan struct{}), make(chan struct{})
+	inner := &mockUserService{
+		getUserByIDFunc: func(ctx context.Context, id string) (*domain.UserResponse, error) {
+			// The user is read before the delete and returned after it
+			close(read)
+			<-release
+			return &domain.UserResponse{ID: id, Status: domain.UserStatusActive}, nil
+		},
+		deleteUserFunc: func(ctx context.Context, id string) error { return nil },
+	}
+	userService := service.NewCachedUserService(inner, userCache, time.Minute)
+
+	done := make(chan error)
+	go func() {
+		_, err := userService.GetUserByID(ctx, "user-1")
+		done <- err
+	}()
+	<-read
+	if err := userService.DeleteUser(ctx, "user-1"); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("GetUserByID failed: %v", err)
+	}
+
+	if cached, err := userCache.GetUser(ctx, "user-1"); err != nil || !cache.IsTombstone(cached) {
+		t.Errorf("Expected the miss-fill to leave the tombstone in place, got %+v (%v)", cached, err)
+	}
+}
@@ -0,0 +1,154 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+)
+
+// stalledCache is a userMapCache whose writes for one user hang until their
+// context ends or release is closed, keeping a write-behind worker busy
+type stalledCache struct {
+	*userMapCache
+	stalledID string
+	stalled   chan struct{}
+	release   chan struct{}
+}
+
+func (c *stalledCache) SetUser(ctx context.Context, userID string, user *domain.UserResponse, ttl time.Duration) error {
+	if userID == c.stalledID {
+		close(c.stalled)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.release:
+		}
+	}
+	return c.userMapCache.SetUser(ctx, userID, user, ttl)
+}
+
+func TestWriteBehindQueueBackpressure(t *testing.T) {
+	if err := cache.ValidateWriteBehindConfig(config.WriteBehindConfig{DropPolicy: "oldest"}); err == nil {
+		t.Error("Expected an unknown drop policy to be rejected")
+	}
+
+	ctx := context.Background()
+	userCache := &stalledCache{userMapCache: newUserMapCache(), stalledID: "stalled", stalled: make(chan struct{})}
+	queue := cache.NewWriteBehindQueue(userCache, config.WriteBehindConfig{QueueSize: 1, Workers: 1, DropPolicy: cache.WriteBehindDropFills})
+	user := func(id string) *domain.UserResponse { return &domain.UserResponse{ID: id, Name: id} }
+	stats := func() (string, map[string]interface{}) {
+		health := queue.CheckHealth(ctx)
+		return health.Status, health.Details
+	}
+
+	// The only worker hangs on its first write, so the next one fills the queue
+	queue.FillUser("stalled", user("stalled"), time.Minute)
+	<-userCache.stalled
+	queue.FillUser("queued", user("queued"), time.Minute)
+
+	// A full queue drops further fills but applies updates synchronously
+	queue.FillUser("dropped", user("dropped"), time.Minute)
+	queue.SetUser("updated", user("updated"), time.Minute)
+	if _, err := userCache.GetUser(ctx, "updated"); err != nil {
+		t.Error("Expected an update to be applied synchronously when the queue is full")
+	}
+	if _, err := userCache.GetUser(ctx, "dropped"); err == nil {
+		t.Error("Expected a cache fill to be dropped when the queue is full")
+	}
+	status, details := stats()
+	if status != domain.HealthStatusDegraded || details["queue_depth"] != 1 || details["peak_queue_depth"] != 1 ||
+		details["dropped_writes"] != int64(1) || details["synchronous_writes"] != int64(1) {
+		t.Errorf("Expected a degraded queue with one dropped and one synchronous write, got %s %v", status, details)
+	}
+
+	// A flush that times out discards what is still queued
+	flushCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := queue.Close(flushCtx); err == nil {
+		t.Fatal("Expected the flush to time out behind the stalled write")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, details = stats(); details["dropped_writes"] == int64(2) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if details["dropped_writes"] != int64(2) || details["failed_writes"] != int64(1) || details["queue_depth"] != 0 {
+		t.Errorf("Expected the queued write to be discarded after the timeout, got %v", details)
+	}
+	if _, err := userCache.GetUser(ctx, "queued"); err == nil {
+		t.Error("Expected the discarded write not to reach the cache")
+	}
+
+	// Writes after Close are applied synchronously
+	queue.SetUser("late", user("late"), time.Minute)
+	if _, err := userCache.GetUser(ctx, "late"); err != nil {
+		t.Error("Expected a write after Close to be applied synchronously")
+	}
+}
+
+func TestWriteBehindQueueKeepsOrderPerUser(t *testing.T) {
+	ctx := context.Background()
+	userCache := newUserMapCache()
+	queue := cache.NewWriteBehindQueue(userCache, config.WriteBehindConfig{QueueSize: 200, Workers: 4})
+
+	for i := 0; i < 20; i++ {
+		queue.SetUser("user-1", &domain.UserResponse{ID: "user-1", Name: string(rune('a' + i))}, time.Minute)
+		queue.SetUser("user-2", &domain.UserResponse{ID: "user-2", Name: string(rune('a' + i))}, time.Minute)
+	}
+	queue.DeleteUser("user-2")
+	if err := queue.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if cached, err := userCache.GetUser(ctx, "user-1"); err != nil || cached.Name != "t" {
+		t.Errorf("Expected the last write for a user to win, got %+v (%v)", cached, err)
+	}
+	if _, err := userCache.GetUser(ctx, "user-2"); err == nil {
+		t.Error("Expected the invalidation queued last to win")
+	}
+	if details := queue.CheckHealth(ctx).Details; details["applied_writes"] != int64(41) {
+		t.Errorf("Expected every write to be applied, got %v", details)
+	}
+}
+
+func TestWriteBehindQueueKeepsOrderWhenFull(t *testing.T) {
+	ctx := context.Background()
+	userCache := &stalledCache{userMapCache: newUserMapCache(), stalledID: "stalled", stalled: make(chan struct{}), release: make(chan struct{})}
+	queue := cache.NewWriteBehindQueue(userCache, config.WriteBehindConfig{QueueSize: 1, Workers: 1})
+
+	// The only worker hangs, and the user's first write fills the queue
+	queue.FillUser("stalled", &domain.UserResponse{ID: "stalled"}, time.Minute)
+	<-userCache.stalled
+	queue.SetUser("user-1", &domain.UserResponse{ID: "user-1", Name: "Old"}, time.Minute)
+
+	// The user's next write waits behind the queued one instead of overtaking it
+	written := make(chan struct{})
+	go func() {
+		queue.SetUser("user-1", &domain.UserResponse{ID: "user-1", Name: "New"}, time.Minute)
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("Expected the write to wait for room behind the user's queued write")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(userCache.release)
+	<-written
+	if err := queue.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	cached, err := userCache.GetUser(ctx, "user-1")
+	if err != nil || cached.Name != "New" {
+		t.Errorf("Expected the later write to be applied last, got %+v (%v)", cached, err)
+	}
+	if details := queue.CheckHealth(ctx).Details; details["waited_writes"] != int64(1) || details["synchronous_writes"] != int64(0) {
+		t.Errorf("Expected one write to have waited and none applied synchronously, got %v", details)
+	}
+}
@@ -0,0 +1,79 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestUpdateChangesAreAuditedAndReported(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	auditService := service.NewAuditService(repository.NewMemoryAuditRepository())
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService, service.WithAuditService(auditService))
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal()).SetupRoutes()
+
+	user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Diff Tester", Email: "jane@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	token, _ := tokenService.GenerateToken(&domain.User{ID: user.ID, Email: user.Email, Role: "user"})
+
+	update := func(body string) (int, []domain.FieldChange) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/profile", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var envelope struct {
+			Meta struct {
+				Changes []domain.FieldChange `json:"changes"`
+			} `json:"meta"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Meta.Changes
+	}
+
+	status, changes := update(`{"name": "Diff Tester", "email": "jane.doe@example.com"}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected the update to succeed, got %d", status)
+	}
+	want := domain.FieldChange{Field: "email", From: "j**e@example.com", To: "j******e@example.com"}
+	if len(changes) != 1 || changes[0] != want {
+		t.Fatalf("Expected only the masked email change in the response, got %+v", changes)
+	}
+
+	// Support can see who changed the email and when
+	events, total, err := auditService.ListEvents(ctx, domain.AuditFilter{TargetID: user.ID, Action: domain.AuditActionUserUpdated}, 10, 0)
+	if err != nil || total != 1 {
+		t.Fatalf("Expected one audit event, got %d, %v", total, err)
+	}
+	if events[0].ActorID != user.ID || events[0].CreatedAt.IsZero() {
+		t.Errorf("Expected the event to name the user as actor, got %+v", events[0])
+	}
+	recorded, _ := json.Marshal(events[0].Details["changes"])
+	if strings.Contains(string(recorded), "jane.doe@") {
+		t.Errorf("Expected the audit log to mask email addresses, got %s", recorded)
+	}
+
+	// An update that changes nothing records nothing
+	if status, changes := update(`{"name": "Diff Tester"}`); status != http.StatusOK || len(changes) != 0 {
+		t.Errorf("Expected no changes, got %d %+v", status, changes)
+	}
+	if _, total, _ := auditService.ListEvents(ctx, domain.AuditFilter{TargetID: user.ID, Action: domain.AuditActionUserUpdated}, 10, 0); total != 1 {
+		t.Errorf("Expected no audit event for a no-op update, got %d", total)
+	}
+}
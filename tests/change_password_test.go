@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetTokenRevocationService(revocations)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	server := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	if _, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Password Tester", Email: "password@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	token, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "password@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	send := func(method, path string, body interface{}) int {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	rejected := []struct {
+		name   string
+		req    domain.ChangePasswordRequest
+		status int
+	}{
+		{"wrong current password", domain.ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "newpassword1"}, http.StatusForbidden},
+		{"too short", domain.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "short"}, http.StatusBadRequest},
+		{"unchanged", domain.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "password123"}, http.StatusBadRequest},
+	}
+	for _, tt := range rejected {
+		if status := send(http.MethodPut, "/api/v1/profile/password", tt.req); status != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, status)
+		}
+	}
+
+	status := send(http.MethodPut, "/api/v1/profile/password", domain.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword1"})
+	if status != http.StatusOK {
+		t.Fatalf("Expected the password change to succeed, got %d", status)
+	}
+	if status := send(http.MethodGet, "/api/v1/profile", nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected existing tokens to be revoked, got %d", status)
+	}
+	if _, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "password@example.com", Password: "password123"}); err == nil {
+		t.Error("Expected the old password to stop working")
+	}
+	if _, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "password@example.com", Password: "newpassword1"}); err != nil {
+		t.Errorf("Expected login with the new password, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestClientCredentialsGrant(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	clientService := service.NewClientService(repository.NewMemoryClientRepository(), tokenService, nil, "user",
+		config.ClientCredentialsConfig{Enabled: true, TokenTTL: 10 * time.Minute})
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Client Routes", routes.NewClientRoutes(handler.NewClientHandler(clientService), false))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	createClient := func(scopes ...string) domain.CreatedClient {
+		body, _ := json.Marshal(domain.CreateClientRequest{Name: "billing", Scopes: scopes})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/clients", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		rec := do(req)
+		var envelope struct {
+			Data domain.CreatedClient `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		if rec.Code != http.StatusCreated || envelope.Data.MachineClient == nil || envelope.Data.ClientSecret == "" {
+			t.Fatalf("Expected admins to create clients, got %d: %s", rec.Code, rec.Body.String())
+		}
+		return envelope.Data
+	}
+	requestToken := func(form url.Values, id, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if id != "" {
+			req.SetBasicAuth(id, secret)
+		}
+		return do(req)
+	}
+	callWith := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return do(req).Code
+	}
+
+	reader := createClient(domain.APIKeyScopeRead)
+	listReq := httptest.NewRequest(http.MethodGet, "/api/v1/admin/clients", nil)
+	listReq.Header.Set("Authorization", "Bearer "+adminToken)
+	if strings.Contains(do(listReq).Body.String(), reader.ClientSecret) {
+		t.Error("Expected listed clients to omit their secrets")
+	}
+
+	// Credentials in the form body
+	rec := requestToken(url.Values{"grant_type": {"client_credentials"}, "client_id": {reader.ID}, "client_secret": {reader.ClientSecret}}, "", "")
+	var granted domain.ClientToken
+	_ = json.Unmarshal(rec.Body.Bytes(), &granted)
+	if rec.Code != http.StatusOK || granted.TokenType != "Bearer" || granted.Scope != "read" || granted.ExpiresIn != 600 {
+		t.Fatalf("Expected a token for the client, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("Expected token responses not to be cached")
+	}
+
+	// A read-only client may not write
+	if status := callWith(http.MethodPut, "/api/v1/profile", granted.AccessToken); status != http.StatusForbidden {
+		t.Errorf("Expected a read-only client to be refused a write, got %d", status)
+	}
+
+	// Wrong secrets and unowned scopes are refused as RFC 6749 errors
+	rec = requestToken(url.Values{"grant_type": {"client_credentials"}}, reader.ID, "dgs_wrong")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), `"invalid_client"`) {
+		t.Errorf("Expected a wrong secret to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = requestToken(url.Values{"grant_type": {"client_credentials"}, "scope": {"admin"}}, reader.ID, reader.ClientSecret)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"invalid_scope"`) {
+		t.Errorf("Expected an unowned scope to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = requestToken(url.Values{"grant_type": {"password"}}, reader.ID, reader.ClientSecret)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"unsupported_grant_type"`) {
+		t.Errorf("Expected other grants to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// An admin client reaches the admin API, and can narrow its token
+	admin := createClient(domain.APIKeyScopeRead, domain.APIKeyScopeAdmin)
+	rec = requestToken(url.Values{"grant_type": {"client_credentials"}}, admin.ID, admin.ClientSecret)
+	_ = json.Unmarshal(rec.Body.Bytes(), &granted)
+	if status := callWith(http.MethodGet, "/api/v1/admin/users", granted.AccessToken); status != http.StatusOK {
+		t.Errorf("Expected an admin client to list users, got %d", status)
+	}
+	rec = requestToken(url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}}, admin.ID, admin.ClientSecret)
+	_ = json.Unmarshal(rec.Body.Bytes(), &granted)
+	if status := callWith(http.MethodGet, "/api/v1/admin/users", granted.AccessToken); status != http.StatusForbidden {
+		t.Errorf("Expected a narrowed token to lose the admin role, got %d", status)
+	}
+}
@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+	"demo-go/pkg/client"
+)
+
+func TestClientAgainstServer(t *testing.T) {
+	tokenService := service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour)
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetRefreshTokenService(service.NewRefreshTokenService(
+		repository.NewMemoryRefreshTokenStore(), userService, nil, nil, time.Hour,
+	))
+	router := routes.NewRouter(userHandler, middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	server := httptest.NewServer(router.SetupRoutes())
+	defer server.Close()
+
+	ctx := context.Background()
+	c := client.New(server.URL)
+
+	if _, err := c.Register(ctx, &client.RegisterRequest{
+		Name: "SDK Tester", Email: "sdk@example.com", Password: "password123",
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, err := c.GetProfile(ctx); !client.IsStatus(err, http.StatusUnauthorized) {
+		t.Errorf("Expected 401 before login, got %v", err)
+	}
+
+	if _, err := c.RefreshToken(ctx); err != client.ErrNoRefreshToken {
+		t.Errorf("Expected ErrNoRefreshToken before login, got %v", err)
+	}
+
+	if _, err := c.Login(ctx, "sdk@example.com", "password123"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	loginToken := c.Token()
+	if token, err := c.RefreshToken(ctx); err != nil || token == loginToken {
+		t.Fatalf("Expected a new token from RefreshToken, got %q, %v", token, err)
+	}
+
+	name := "Renamed SDK Tester"
+	user, err := c.UpdateProfile(ctx, &client.UpdateUserRequest{Name: &name})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if user.Name != name || user.Email != "sdk@example.com" {
+		t.Errorf("Unexpected updated user: %+v", user)
+	}
+
+	if _, err := c.ListUsers(ctx, nil); !client.IsStatus(err, http.StatusForbidden) {
+		t.Errorf("Expected 403 listing users as a regular user, got %v", err)
+	}
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"1","name":"Retry Tester"}}`))
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, client.WithToken("token"), client.WithRetries(3, time.Millisecond))
+	user, err := c.GetProfile(context.Background())
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if user.Name != "Retry Tester" || calls.Load() != 3 {
+		t.Errorf("Expected success on the third attempt, got %+v after %d calls", user, calls.Load())
+	}
+}
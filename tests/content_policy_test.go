@@ -0,0 +1,52 @@
+package handler_test
+
+import (
+	"testing"
+
+	"demo-go/internal/config"
+	"demo-go/internal/contentpolicy"
+	"demo-go/internal/domain"
+)
+
+func TestContentPolicy_Check(t *testing.T) {
+	policy, err := contentpolicy.New(config.ContentPolicyConfig{
+		Enabled:           true,
+		Blocklist:         []string{"admin", "badword"},
+		BlockMixedScripts: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		expectAllow bool
+	}{
+		{name: "clean name", value: "Alice Smith", expectAllow: true},
+		{name: "non-Latin name", value: "Иван Петров", expectAllow: true},
+		{name: "blocklisted term", value: "Site Admin", expectAllow: false},
+		{name: "spaced out term", value: "b a d w o r d", expectAllow: false},
+		{name: "leetspeak", value: "4dm1n", expectAllow: false},
+		{name: "fullwidth characters", value: "ａｄｍｉｎ", expectAllow: false},
+		{name: "mixed scripts", value: "P\u0430ypal", expectAllow: false}, // Cyrillic a
+		{name: "zero-width character", value: "Ali\u200bce", expectAllow: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check("Name", tt.value)
+			if tt.expectAllow {
+				if err != nil {
+					t.Errorf("Expected %q to be allowed, got %v", tt.value, err)
+				}
+				return
+			}
+
+			domainErr, ok := err.(*domain.Error)
+			if !ok || domainErr.Code != contentpolicy.ViolationCode {
+				t.Errorf("Expected %s for %q, got %v", contentpolicy.ViolationCode, tt.value, err)
+			}
+		})
+	}
+}
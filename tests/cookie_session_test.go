@@ -0,0 +1,130 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestCookieSessionAuth(t *testing.T) {
+	if _, err := middleware.NewCookieSessions(config.CookieSessionConfig{Name: "session", CSRFCookieName: "csrf_token", SameSite: "none"}, time.Hour); err == nil {
+		t.Error("Expected SameSite=None without Secure to be rejected")
+	}
+
+	cookies, err := middleware.NewCookieSessions(config.CookieSessionConfig{
+		Name: "session", CSRFCookieName: "csrf_token", Path: "/", Secure: true, SameSite: "strict", CSRFSecret: "csrf-secret",
+	}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewCookieSessions failed: %v", err)
+	}
+
+	tokenService := service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour)
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetCookieSessions(cookies)
+	revocationHandler := handler.NewTokenRevocationHandler(revocations, nil)
+	revocationHandler.SetCookieSessions(cookies)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	router := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal(), cookies.Authenticate)
+	router.AddRouteGroup("Token Revocation Routes", routes.NewTokenRevocationRoutes(revocationHandler))
+	server := router.SetupRoutes()
+
+	_, _ = userService.Register(context.Background(), &domain.CreateUserRequest{Name: "Cookie User", Email: "cookie@example.com", Password: "password123"})
+
+	send := func(method, path string, session *http.Cookie, header http.Header, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		for name, values := range header {
+			req.Header.Set(name, values[0])
+		}
+		if session != nil {
+			req.AddCookie(session)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Login sets an HttpOnly session cookie and answers with the CSRF token only
+	rec := send(http.MethodPost, "/auth/login", nil, nil, domain.LoginRequest{Email: "cookie@example.com", Password: "password123"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected login to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var login struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &login)
+	if _, ok := login.Data["token"]; ok {
+		t.Error("Expected the token not to be returned in cookie mode")
+	}
+	set := map[string]*http.Cookie{}
+	for _, cookie := range rec.Result().Cookies() {
+		set[cookie.Name] = cookie
+	}
+	session, csrfCookie := set["session"], set["csrf_token"]
+	if session == nil || !session.HttpOnly || !session.Secure || session.SameSite != http.SameSiteStrictMode || session.MaxAge != 3600 {
+		t.Fatalf("Expected an HttpOnly, secure, strict session cookie, got %+v", session)
+	}
+	csrfToken, _ := login.Data["csrf_token"].(string)
+	if csrfCookie == nil || csrfCookie.HttpOnly || csrfToken == "" || csrfCookie.Value != csrfToken {
+		t.Fatalf("Expected a readable CSRF cookie matching the response, got %+v and %q", csrfCookie, csrfToken)
+	}
+
+	// Reads with the cookie need no CSRF token
+	if rec := send(http.MethodGet, "/api/v1/profile", session, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected the session cookie to authenticate, got %d", rec.Code)
+	}
+
+	// State-changing requests with the cookie need the CSRF token
+	name := "Renamed User"
+	update := domain.UpdateUserRequest{Name: &name}
+	for _, header := range []http.Header{nil, {middleware.HeaderCSRFToken: {"forged"}}} {
+		rec := send(http.MethodPut, "/api/v1/profile", session, header, update)
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "CSRF_TOKEN_INVALID") {
+			t.Errorf("Expected a missing or wrong CSRF token to be refused, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec := send(http.MethodPut, "/api/v1/profile", session, http.Header{middleware.HeaderCSRFToken: {csrfToken}}, update); rec.Code != http.StatusOK {
+		t.Errorf("Expected an update with the CSRF token to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Bearer tokens keep working without CSRF tokens
+	token, _, err := userService.Login(context.Background(), &domain.LoginRequest{Email: "cookie@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if rec := send(http.MethodPut, "/api/v1/profile", nil, http.Header{"Authorization": {"Bearer " + token}}, update); rec.Code != http.StatusOK {
+		t.Errorf("Expected bearer requests to skip CSRF checks, got %d", rec.Code)
+	}
+
+	// Logout ends the session and clears the cookies
+	rec = send(http.MethodPost, "/auth/logout", session, http.Header{middleware.HeaderCSRFToken: {csrfToken}}, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected logout to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.MaxAge >= 0 || cookie.Value != "" {
+			t.Errorf("Expected logout to clear the %s cookie, got %+v", cookie.Name, cookie)
+		}
+	}
+	if rec := send(http.MethodGet, "/api/v1/profile", session, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the session cookie to stop working after logout, got %d", rec.Code)
+	}
+}
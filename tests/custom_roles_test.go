@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestCustomRoles(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	roleRepo := repository.NewMemoryRoleRepository()
+	roleService, err := service.NewRoleService(roleRepo, userRepo, nil, map[string][]string{"admin": {"*"}}, []string{"admin", "user"}, 0)
+	if err != nil {
+		t.Fatalf("NewRoleService failed: %v", err)
+	}
+	if err := roleService.Reload(ctx); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if roles, _ := roleRepo.List(ctx); len(roles) != 2 {
+		t.Fatalf("Expected the built-in roles to be created, got %d", len(roles))
+	}
+
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(userRepo, tokenService, service.WithRoleCatalog(roleService))
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetPolicyEngine(roleService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Role Routes", routes.NewRoleRoutes(handler.NewRoleHandler(roleService, userService)))
+	server := router.SetupRoutes()
+
+	agent := &domain.User{Name: "Agent", Email: "agent@example.com", Role: "user"}
+	_ = userRepo.Create(ctx, agent)
+	agentToken, _ := tokenService.GenerateToken(agent)
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Roles are defined with permissions, which are validated
+	if rec := do(http.MethodPost, "/api/v1/admin/roles", adminToken, `{"name":"support","permissions":["user.**"]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected malformed permissions to be rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/roles", adminToken, `{"name":"support","permissions":["user.list","user.read"]}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the role to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/roles", adminToken, `{"name":"support"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected duplicate roles to be rejected, got %d", rec.Code)
+	}
+
+	// Only existing roles can be assigned
+	rec := do(http.MethodPut, "/api/v1/admin/users/"+agent.ID+"/role", adminToken, `{"role":"superhero"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "UNKNOWN_ROLE") {
+		t.Errorf("Expected unknown roles to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/users/"+agent.ID+"/role", adminToken, `{"role":"support"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the role to be assigned, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The role's permissions apply to its holders
+	agent, _ = userRepo.GetByID(ctx, agent.ID)
+	agentToken, _ = tokenService.GenerateToken(agent)
+	if rec := do(http.MethodGet, "/api/v1/admin/users", agentToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected the support role to list users, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/roles", agentToken, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the support role to be refused role management, got %d", rec.Code)
+	}
+	rec = do(http.MethodPut, "/api/v1/admin/roles/support", adminToken, `{"permissions":["user.*"]}`)
+	var updated struct {
+		Data domain.Role `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &updated)
+	if rec.Code != http.StatusOK || !roleService.Allows("support", domain.PermissionUserDelete) || len(updated.Data.EffectivePermissions) != 7 {
+		t.Errorf("Expected the update to take effect, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Roles in use and built-in roles cannot be deleted
+	if rec := do(http.MethodDelete, "/api/v1/admin/roles/support", adminToken, ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected a role in use to be kept, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/admin/roles/user", adminToken, ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected a built-in role to be kept, got %d", rec.Code)
+	}
+	_ = do(http.MethodPut, "/api/v1/admin/users/"+agent.ID+"/role", adminToken, `{"role":"user"}`)
+	if rec := do(http.MethodDelete, "/api/v1/admin/roles/support", adminToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected the unused role to be deleted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if roleService.HasRole("support") {
+		t.Error("Expected the deleted role to be gone")
+	}
+
+	// Role managers cannot grant more than they hold, nor change their own role
+	if rec := do(http.MethodPost, "/api/v1/admin/roles", adminToken, `{"name":"roles","permissions":["role.manage","user.read"]}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the role to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	managerToken, _ := tokenService.GenerateToken(&domain.User{ID: "manager-1", Email: "manager@example.com", Role: "roles"})
+	rec = do(http.MethodPost, "/api/v1/admin/roles", managerToken, `{"name":"deleter","permissions":["user.delete"]}`)
+	if rec.Code != http.StatusForbidden || roleService.HasRole("deleter") {
+		t.Errorf("Expected a grant the manager lacks to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/roles", managerToken, `{"name":"reader","permissions":["user.read"]}`); rec.Code != http.StatusCreated {
+		t.Errorf("Expected a grant the manager holds to be allowed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/roles/reader", managerToken, `{"permissions":["user.*"]}`); rec.Code != http.StatusForbidden || roleService.Allows("reader", domain.PermissionUserDelete) {
+		t.Errorf("Expected widening a role past the manager's permissions to be refused, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/roles/roles", managerToken, `{"permissions":["role.manage","user.read","user.delete"]}`); rec.Code != http.StatusForbidden || roleService.Allows("roles", domain.PermissionUserDelete) {
+		t.Errorf("Expected the manager's own role to be kept, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/roles/roles", adminToken, `{"permissions":["role.manage"]}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected removing a grant the admin holds anyway to be allowed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,149 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+	"demo-go/internal/response"
+	"demo-go/internal/service"
+)
+
+func TestDedupMiddlewareCoalescesConcurrentGETs(t *testing.T) {
+	const callers = 5
+
+	var calls int32
+	release := make(chan struct{})
+	handler := middleware.NewDedupMiddleware().Deduplicate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, callers)
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(rr *httptest.ResponseRecorder) {
+			defer wg.Done()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/users?limit=5", http.NoBody))
+		}(recorders[i])
+	}
+
+	// Wait for the first execution to start, then give the rest time to join it
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected concurrent requests to be coalesced, handler ran %d times", got)
+	}
+	for i, rr := range recorders {
+		if rr.Code != http.StatusOK || rr.Body.String() != `{"success":true}` {
+			t.Errorf("Caller %d got status %d body %q", i, rr.Code, rr.Body.String())
+		}
+		if rr.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Caller %d missing shared headers", i)
+		}
+	}
+}
+
+func TestDedupMiddlewareIgnoresNonGET(t *testing.T) {
+	var calls int32
+	handler := middleware.NewDedupMiddleware().Deduplicate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/users", http.NoBody))
+		if rr.Code != http.StatusCreated {
+			t.Errorf("Expected status code %d, got %d", http.StatusCreated, rr.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected POST requests to run separately, handler ran %d times", calls)
+	}
+}
+
+// serveConcurrently sends the requests at once through a deduplicated handler
+// that echoes the caller's role and naming header, and returns how often the
+// handler ran and the responses
+func serveConcurrently(t *testing.T, wrap func(http.Handler) http.Handler, requests ...*http.Request) (int32, []*httptest.ResponseRecorder) {
+	t.Helper()
+	var calls int32
+	release := make(chan struct{})
+	handler := wrap(middleware.NewDedupMiddleware().Deduplicate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		role, _ := middleware.GetUserRoleFromContext(r.Context())
+		_, _ = w.Write([]byte(role + " " + r.Header.Get(response.NamingHeader)))
+	})))
+
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, len(requests))
+	for i, req := range requests {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(rr *httptest.ResponseRecorder, req *http.Request) {
+			defer wg.Done()
+			handler.ServeHTTP(rr, req)
+		}(recorders[i], req)
+	}
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return atomic.LoadInt32(&calls), recorders
+}
+
+func TestDedupMiddlewareSeparatesResponseHeaders(t *testing.T) {
+	snake := httptest.NewRequest(http.MethodGet, "/api/v1/users", http.NoBody)
+	camel := httptest.NewRequest(http.MethodGet, "/api/v1/users", http.NoBody)
+	camel.Header.Set(response.NamingHeader, "camel")
+
+	calls, recorders := serveConcurrently(t, func(h http.Handler) http.Handler { return h }, snake, camel)
+	if calls != 2 {
+		t.Errorf("Expected requests with different response headers to run separately, handler ran %d times", calls)
+	}
+	if recorders[1].Body.String() != " camel" || recorders[1].Header().Get(middleware.HeaderDeduplicated) != "" {
+		t.Errorf("Expected the camel request to get its own response, got %q", recorders[1].Body.String())
+	}
+}
+
+func TestDedupMiddlewareSeparatesCredentials(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+
+	// The same user through a full token and a narrower one
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "admin"})
+	narrowToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	requests := make([]*http.Request, 2)
+	for i, token := range []string{adminToken, narrowToken} {
+		requests[i] = httptest.NewRequest(http.MethodGet, "/api/v1/profile", http.NoBody)
+		requests[i].Header.Set("Authorization", "Bearer "+token)
+	}
+
+	calls, recorders := serveConcurrently(t, jwtMiddleware.Authenticate, requests...)
+	if calls != 2 {
+		t.Errorf("Expected requests with different credentials to run separately, handler ran %d times", calls)
+	}
+	if recorders[1].Body.String() != "user " {
+		t.Errorf("Expected the narrower token to get its own response, got %q", recorders[1].Body.String())
+	}
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/demo"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/hygiene"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/passwordhash"
+	"demo-go/internal/repository"
+	"demo-go/internal/response"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestDemoMode(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DemoConfig{Enabled: true, AdminEmail: "admin@demo.example.com", AdminPassword: "demo-admin-password", UserPassword: "demo-user-password", Users: 3}
+	hasher, _ := passwordhash.NewBcrypt(4)
+	userRepo := repository.NewMemoryUserRepository()
+	auditRepo := repository.NewMemoryAuditRepository()
+	seeder := demo.New(cfg, userRepo, auditRepo, hasher, nil, "user")
+
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	server := routes.NewRouter(handler.NewUserHandler(service.NewUserService(userRepo, tokenService)), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal()).SetupRoutes()
+	login := func(email, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"`+email+`","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	_ = userRepo.Create(ctx, &domain.User{Name: "Visitor", Email: "visitor@example.com", Role: "user"})
+	_ = auditRepo.Create(ctx, &domain.AuditEvent{Action: domain.AuditActionUserDeleted, ActorID: "someone", CreatedAt: time.Now()})
+	if err := seeder.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	// Only the fixtures remain, with one suspended sample user
+	users, total, _ := userRepo.Search(ctx, &domain.UserQuery{Limit: 100})
+	if total != 4 || len(users) != 4 {
+		t.Fatalf("Expected the admin and 3 sample users, got %d", total)
+	}
+	if _, err := userRepo.GetByEmail(ctx, "visitor@example.com"); err != domain.ErrUserNotFound {
+		t.Errorf("Expected other users to be deleted, got %v", err)
+	}
+	if suspended, _ := userRepo.GetByID(ctx, "sample-user-3"); suspended == nil || suspended.Status != domain.UserStatusSuspended {
+		t.Errorf("Expected the last sample user to be suspended, got %+v", suspended)
+	}
+	events, _ := auditRepo.List(ctx, domain.AuditFilter{}, 100, 0)
+	if len(events) != 5 {
+		t.Fatalf("Expected only the sample audit events, got %d", len(events))
+	}
+	for _, event := range events {
+		if event.Action == domain.AuditActionUserDeleted {
+			t.Errorf("Expected earlier audit events to be cleared, got %+v", event)
+		}
+	}
+
+	// The fixtures log in with the configured passwords
+	if rec := login(cfg.AdminEmail, cfg.AdminPassword); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the demo admin to log in, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := login("ada.lovelace@demo.example.com", cfg.UserPassword); rec.Code != http.StatusOK {
+		t.Errorf("Expected sample users to log in, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Changes to the fixtures are undone by the next reset
+	admin, _ := userRepo.GetByID(ctx, demo.AdminID)
+	admin.Name, admin.Role = "Vandalized", "user"
+	_ = userRepo.Update(ctx, admin.ID, admin)
+	if err := seeder.Reset(ctx); err != nil {
+		t.Fatalf("Second reset failed: %v", err)
+	}
+	if admin, _ := userRepo.GetByID(ctx, demo.AdminID); admin.Name != "Demo Admin" || admin.Role != "admin" {
+		t.Errorf("Expected the admin to be restored, got %+v", admin)
+	}
+	if count, _ := auditRepo.Count(ctx, domain.AuditFilter{}); count != 5 {
+		t.Errorf("Expected the sample events not to pile up across resets, got %d", count)
+	}
+
+	// Responses are flagged for a banner
+	defer func() { response.DemoMode = false }()
+	response.DemoMode = true
+	rec := login(cfg.AdminEmail, cfg.AdminPassword)
+	var body struct {
+		Meta struct {
+			Demo bool `json:"demo"`
+		} `json:"meta"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if rec.Header().Get(response.DemoHeader) != "true" || !body.Meta.Demo {
+		t.Errorf("Expected the response to be flagged as a demo, got %v: %s", rec.Header(), rec.Body.String())
+	}
+
+	// Demo mode is refused in production unless acknowledged
+	findings := hygiene.Check(&config.Config{JWT: config.JWTConfig{SecretKey: "a-strong-secret"}, Demo: cfg})
+	found := false
+	for _, finding := range findings {
+		found = found || finding.ID == hygiene.FindingDemoMode
+	}
+	if !found {
+		t.Errorf("Expected demo mode to be reported, got %+v", findings)
+	}
+}
+
+func TestDemoResetRestoresAdminState(t *testing.T) {
+	ctx := context.Background()
+	hasher, _ := passwordhash.NewBcrypt(4)
+	userRepo := repository.NewMemoryUserRepository()
+	roleService, err := service.NewRoleService(repository.NewMemoryRoleRepository(), userRepo, nil, map[string][]string{"admin": {"*"}}, []string{"admin", "user"}, 0)
+	if err != nil {
+		t.Fatalf("NewRoleService failed: %v", err)
+	}
+	_ = roleService.Reload(ctx)
+	overrides := service.NewRouteOverrideService(repository.NewMemoryRouteOverrideRepository(), nil, 0)
+	invites := service.NewInviteService(repository.NewMemoryInviteRepository(), userRepo, roleService, nil, config.InviteConfig{Enabled: true})
+	seeder := demo.New(config.DemoConfig{Enabled: true, AdminEmail: "admin@demo.example.com", AdminPassword: "demo-admin-password", UserPassword: "demo-user-password", Users: 2},
+		userRepo, repository.NewMemoryAuditRepository(), hasher, nil, "user",
+		demo.WithRoles(roleService), demo.WithRouteOverrides(overrides), demo.WithInvites(invites))
+	if err := seeder.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	// What a visitor signed in as the demo admin can break
+	if _, err := overrides.CreateOverride(ctx, demo.AdminID, &domain.CreateRouteOverrideRequest{Rule: "POST /auth/login", Action: domain.RouteOverrideClose}); err != nil {
+		t.Fatalf("CreateOverride failed: %v", err)
+	}
+	_, _ = roleService.CreateRole(ctx, demo.AdminID, "admin", &domain.CreateRoleRequest{Name: "intruder", Permissions: []string{domain.PermissionRoleAssign}})
+	_, _ = roleService.UpdateRole(ctx, demo.AdminID, "admin", "user", &domain.UpdateRoleRequest{Permissions: &[]string{domain.PermissionRoleAssign}})
+	_, _ = invites.CreateInvite(ctx, demo.AdminID, &domain.CreateInviteRequest{Email: "friend@example.com"})
+	if _, closed := overrides.MatchRoute(http.MethodPost, "/auth/login"); !closed {
+		t.Fatal("Expected the login to be closed before the reset")
+	}
+
+	if err := seeder.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if list, _ := overrides.ListOverrides(ctx); len(list) != 0 {
+		t.Errorf("Expected route overrides to be deleted, got %+v", list)
+	}
+	if _, closed := overrides.MatchRoute(http.MethodPost, "/auth/login"); closed {
+		t.Error("Expected the login to be open again after the reset")
+	}
+	if roleService.HasRole("intruder") || roleService.Allows("user", domain.PermissionRoleAssign) {
+		t.Error("Expected custom roles to be deleted and built-in roles to get their configured permissions back")
+	}
+	if list, total, _ := invites.ListInvites(ctx, 10, 0); total != 0 {
+		t.Errorf("Expected invites to be deleted, got %+v", list)
+	}
+}
@@ -0,0 +1,61 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestEmailCheckService(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	if err := userRepo.Create(ctx, &domain.User{Name: "Taken", Email: "taken@example.com", Role: "user"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	minResponse := 30 * time.Millisecond
+	checker := service.NewEmailCheckService(userRepo, ratelimit.NewMemoryLimiter(), config.EmailCheckConfig{
+		MaxAttempts: 3, AttemptWindow: time.Minute, MinResponseTime: minResponse,
+	})
+
+	start := time.Now()
+	available, err := checker.IsEmailAvailable(ctx, " Taken@Example.com ", "10.0.0.1")
+	if err != nil || available {
+		t.Errorf("Expected taken email to be unavailable, got %v (%v)", available, err)
+	}
+	if elapsed := time.Since(start); elapsed < minResponse {
+		t.Errorf("Expected response padded to %v, took %v", minResponse, elapsed)
+	}
+
+	if available, err := checker.IsEmailAvailable(ctx, "free@example.com", "10.0.0.1"); err != nil || !available {
+		t.Errorf("Expected free email to be available, got %v (%v)", available, err)
+	}
+	if _, err := checker.IsEmailAvailable(ctx, "free@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("Expected third check to be allowed, got %v", err)
+	}
+	if _, err := checker.IsEmailAvailable(ctx, "free@example.com", "10.0.0.1"); err != domain.ErrRateLimited {
+		t.Errorf("Expected ErrRateLimited after the limit, got %v", err)
+	}
+	if _, err := checker.IsEmailAvailable(ctx, "free@example.com", "10.0.0.2"); err != nil {
+		t.Errorf("Expected other clients to be unaffected, got %v", err)
+	}
+
+	// Rotating addresses does not get around the global limit
+	global := service.NewEmailCheckService(userRepo, ratelimit.NewMemoryLimiter(), config.EmailCheckConfig{
+		MaxAttempts: 3, AttemptWindow: time.Minute, GlobalMaxAttempts: 2,
+	})
+	for _, clientIP := range []string{"10.0.1.1", "10.0.1.2"} {
+		if _, err := global.IsEmailAvailable(ctx, "free@example.com", clientIP); err != nil {
+			t.Fatalf("Expected check from %s to be allowed, got %v", clientIP, err)
+		}
+	}
+	if _, err := global.IsEmailAvailable(ctx, "free@example.com", "10.0.1.3"); err != domain.ErrRateLimited {
+		t.Errorf("Expected ErrRateLimited past the global limit, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/emailpolicy"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestEmailDomainPolicy_AllowAndDeny(t *testing.T) {
+	policy, err := emailpolicy.New(config.EmailDomainConfig{
+		Enabled: true,
+		Allow:   []string{"corp.example", "@partner.example"},
+		Deny:    []string{"contractors.corp.example"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+
+	tests := []struct {
+		email       string
+		expectAllow bool
+	}{
+		{"alice@corp.example", true},
+		{"bob@EU.Corp.Example", true}, // subdomains of allowed domains
+		{"carol@partner.example", true},
+		{"dave@contractors.corp.example", false}, // denial wins over the allow list
+		{"erin@gmail.example", false},
+		{"frank@notcorp.example", false}, // suffix but not a subdomain
+	}
+	for _, tt := range tests {
+		err := policy.CheckEmail(tt.email)
+		if tt.expectAllow && err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", tt.email, err)
+		}
+		var domainErr *domain.Error
+		if !tt.expectAllow && (!errors.As(err, &domainErr) || domainErr.Code != domain.ErrEmailDomainNotAllowed.Code) {
+			t.Errorf("Expected %s for %q, got %v", domain.ErrEmailDomainNotAllowed.Code, tt.email, err)
+		}
+	}
+}
+
+func TestEmailDomainPolicy_DenyListReload(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	list := "# disposable providers\nmailinator.example\n\n*.trash.example\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(list))
+	}))
+	defer server.Close()
+
+	policy, err := emailpolicy.New(config.EmailDomainConfig{Enabled: true, DenyListURL: server.URL, RefreshInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	policy.Start()
+	defer func() { _ = policy.Close(ctx) }()
+
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), nil, service.WithEmailDomainPolicy(policy))
+	register := func(email string) (*domain.UserResponse, error) {
+		return userService.Register(ctx, &domain.CreateUserRequest{Name: "Domain User", Email: email, Password: "password123"})
+	}
+	for _, email := range []string{"a@mailinator.example", "b@x.trash.example"} {
+		if _, err := register(email); err == nil {
+			t.Errorf("Expected %q to be refused by the deny-list", email)
+		}
+	}
+	user, err := register("c@fresh.example")
+	if err != nil {
+		t.Fatalf("Expected an unlisted domain to register, got %v", err)
+	}
+	listed := "d@mailinator.example"
+	if _, err := userService.UpdateProfile(ctx, user.ID, &domain.UpdateUserRequest{Email: &listed}); err == nil {
+		t.Error("Expected an email change to a listed domain to be refused")
+	}
+
+	// Reloads pick up list changes
+	mu.Lock()
+	list = "fresh.example\n"
+	mu.Unlock()
+	if err := policy.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if _, err := register("d@fresh.example"); err == nil {
+		t.Error("Expected a newly listed domain to be refused")
+	}
+	if _, err := register("e@mailinator.example"); err != nil {
+		t.Errorf("Expected a domain dropped from the list to register, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "missing.txt")
+	filePolicy, _ := emailpolicy.New(config.EmailDomainConfig{Enabled: true, DenyListFile: path})
+	if err := filePolicy.Refresh(ctx); err == nil {
+		t.Error("Expected a missing deny-list file to fail to load")
+	}
+	_ = os.WriteFile(path, []byte("fresh.example\n"), 0o600)
+	if err := filePolicy.Refresh(ctx); err != nil || filePolicy.CheckEmail("f@fresh.example") == nil {
+		t.Errorf("Expected the deny-list file to load and apply, got %v", err)
+	}
+}
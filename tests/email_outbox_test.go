@@ -0,0 +1,169 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/outbox"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// fakeEmailSender fails with the queued errors, then succeeds
+type fakeEmailSender struct {
+	mu     sync.Mutex
+	errs   []error
+	sent   []*service.EmailMessage
+	called int
+}
+
+func (s *fakeEmailSender) Send(ctx context.Context, message *service.EmailMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func TestEmailOutboxDelivery(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryEmailOutboxRepository()
+	sender := &fakeEmailSender{errs: []error{errors.New("connection refused")}}
+	box := outbox.New(config.EmailOutboxConfig{MaxAttempts: 2, RetryBackoff: time.Hour}, repo, sender, nil)
+
+	if err := box.Send(ctx, &service.EmailMessage{Kind: "password_reset", To: "a@example.com", Subject: "Reset", Body: "token"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	emails, counts, _ := box.List(ctx, domain.OutboxEmailFilter{})
+	if len(emails) != 1 || emails[0].Status != domain.EmailStatusQueued || counts[domain.EmailStatusQueued] != 1 {
+		t.Fatalf("Expected the email to be queued, got %+v %v", emails, counts)
+	}
+	id := emails[0].ID
+
+	// A failed attempt is retried after the backoff, not right away
+	box.DeliverDue(ctx)
+	email, _ := box.Get(ctx, id)
+	if email.Status != domain.EmailStatusQueued || email.Attempts != 1 || email.LastError == "" {
+		t.Fatalf("Expected the email to wait for a retry, got %+v", email)
+	}
+	if email.NextAttemptAt == nil || time.Until(*email.NextAttemptAt) < 50*time.Minute {
+		t.Errorf("Expected the retry to be delayed by the backoff, got %v", email.NextAttemptAt)
+	}
+	box.DeliverDue(ctx)
+	if sender.called != 1 {
+		t.Errorf("Expected no attempt before the retry is due, got %d", sender.called)
+	}
+
+	// A delivered email is marked sent and its body dropped
+	email.NextAttemptAt = &time.Time{}
+	_ = repo.Update(ctx, email)
+	box.DeliverDue(ctx)
+	email, _ = box.Get(ctx, id)
+	if email.Status != domain.EmailStatusSent || email.SentAt == nil || email.Body != "" {
+		t.Fatalf("Expected the email to be sent, got %+v", email)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Body != "token" {
+		t.Errorf("Expected the body to be delivered, got %+v", sender.sent)
+	}
+
+	// Permanent refusals bounce; running out of attempts fails
+	sender.errs = []error{&textproto.Error{Code: 550, Msg: "no such user"}}
+	_ = box.Send(ctx, &service.EmailMessage{To: "gone@example.com", Subject: "Reset"})
+	sender.errs = append(sender.errs, errors.New("timeout"), errors.New("timeout"))
+	_ = box.Send(ctx, &service.EmailMessage{To: "slow@example.com", Subject: "Reset"})
+	box.DeliverDue(ctx)
+	for _, email := range repoEmails(t, repo, "slow@example.com") {
+		email.NextAttemptAt = &time.Time{}
+		_ = repo.Update(ctx, email)
+	}
+	box.DeliverDue(ctx)
+	if emails := repoEmails(t, repo, "gone@example.com"); emails[0].Status != domain.EmailStatusBounced {
+		t.Errorf("Expected the refused email to bounce, got %s", emails[0].Status)
+	}
+	if emails := repoEmails(t, repo, "slow@example.com"); emails[0].Status != domain.EmailStatusFailed || emails[0].Attempts != 2 {
+		t.Errorf("Expected the email to fail after 2 attempts, got %+v", emails[0])
+	}
+}
+
+func TestEmailOutboxRoutes(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryEmailOutboxRepository()
+	sender := &fakeEmailSender{errs: []error{errors.New("connection refused")}}
+	box := outbox.New(config.EmailOutboxConfig{MaxAttempts: 1}, repo, sender, nil)
+	_ = box.Send(ctx, &service.EmailMessage{To: "a@example.com", Subject: "Reset", Body: "secret-token"})
+	box.DeliverDue(ctx)
+	id := repoEmails(t, repo, "a@example.com")[0].ID
+
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Email Outbox Routes", routes.NewEmailOutboxRoutes(handler.NewEmailOutboxHandler(box)))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(`{"reason":"mailbox full"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodGet, "/api/v1/admin/emails", userToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected users to be refused, got %d", rec.Code)
+	}
+	rec := do(http.MethodGet, "/api/v1/admin/emails?status=failed", adminToken)
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(id)) || bytes.Contains(rec.Body.Bytes(), []byte("secret-token")) {
+		t.Fatalf("Expected the failed email without its body, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/emails?status=lost", adminToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected unknown statuses to be rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/emails/"+id+"/bounce", adminToken); rec.Code != http.StatusConflict {
+		t.Errorf("Expected unsent emails not to bounce, got %d", rec.Code)
+	}
+
+	// Resending queues the email again and it is delivered
+	if rec := do(http.MethodPost, "/api/v1/admin/emails/"+id+"/resend", adminToken); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected the email to be queued again, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/emails/"+id+"/resend", adminToken); rec.Code != http.StatusConflict {
+		t.Errorf("Expected queued emails not to be resent, got %d", rec.Code)
+	}
+	box.DeliverDue(ctx)
+	if rec := do(http.MethodPost, "/api/v1/admin/emails/"+id+"/bounce", adminToken); rec.Code != http.StatusOK {
+		t.Errorf("Expected the sent email to be marked bounced, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/emails/unknown", adminToken); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown emails to be not found, got %d", rec.Code)
+	}
+}
+
+func repoEmails(t *testing.T, repo domain.EmailOutboxRepository, to string) []*domain.OutboxEmail {
+	t.Helper()
+	emails, err := repo.List(context.Background(), domain.OutboxEmailFilter{To: to})
+	if err != nil || len(emails) == 0 {
+		t.Fatalf("Expected emails to %s, got %v", to, err)
+	}
+	return emails
+}
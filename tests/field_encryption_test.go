@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/fieldcrypt"
+	"demo-go/internal/keys"
+	"demo-go/internal/repository"
+)
+
+func TestFieldEncryptionRoundTripAndTampering(t *testing.T) {
+	ctx := context.Background()
+	cipher := fieldcrypt.New(keys.NewStaticProvider(mustParseKeySet(t, `{"current": "k1", "keys": {"k1": "field-secret"}}`)))
+
+	sealed, err := cipher.Encrypt(ctx, "call back on +1 555 0100", "user_note:n1")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(sealed) || fieldcrypt.KeyID(sealed) != "k1" || strings.Contains(sealed, "555") {
+		t.Fatalf("Expected a key-tagged ciphertext, got %q", sealed)
+	}
+	if plain, err := cipher.Decrypt(ctx, sealed, "user_note:n1"); err != nil || plain != "call back on +1 555 0100" {
+		t.Errorf("Expected the round trip to restore the value, got %q, %v", plain, err)
+	}
+
+	// A value moved to another record or altered does not decrypt
+	if _, err := cipher.Decrypt(ctx, sealed, "user_note:n2"); !errors.Is(err, domain.ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed for another record, got %v", err)
+	}
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if _, err := cipher.Decrypt(ctx, tampered, "user_note:n1"); !errors.Is(err, domain.ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed for a tampered value, got %v", err)
+	}
+
+	// Plaintext written before encryption was enabled is passed through
+	if plain, err := cipher.Decrypt(ctx, "legacy note", "user_note:n1"); err != nil || plain != "legacy note" {
+		t.Errorf("Expected plaintext to pass through, got %q, %v", plain, err)
+	}
+}
+
+func TestEncryptedNoteRepositoryAndReencryption(t *testing.T) {
+	ctx := context.Background()
+	source := &switchableKeySource{document: `{"current": "k1", "keys": {"k1": "first-secret"}}`}
+	provider := keys.NewNamedProvider("field encryption", source, config.JWTKeysConfig{Source: config.JWTKeySourceURL})
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	inner := repository.NewMemoryNoteRepository()
+	notes := repository.NewEncryptedNoteRepository(inner, fieldcrypt.New(provider))
+	now := time.Now()
+	_ = inner.Create(ctx, &domain.UserNote{ID: "legacy", UserID: "u1", Body: "written in plaintext", CreatedAt: now})
+	if err := notes.Create(ctx, &domain.UserNote{
+		ID: "n1", UserID: "u1", Body: "current text", CreatedAt: now.Add(time.Second),
+		History: []domain.NoteRevision{{Body: "first draft"}},
+	}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	stored, _ := inner.Get(ctx, "n1")
+	if fieldcrypt.KeyID(stored.Body) != "k1" || fieldcrypt.KeyID(stored.History[0].Body) != "k1" {
+		t.Fatalf("Expected bodies to be stored encrypted, got %+v", stored)
+	}
+	listed, err := notes.ListByUser(ctx, "u1")
+	if err != nil || len(listed) != 2 || listed[0].Body != "current text" || listed[0].History[0].Body != "first draft" || listed[1].Body != "written in plaintext" {
+		t.Fatalf("Expected decrypted notes, got %+v, %v", listed, err)
+	}
+
+	// After a rotation both the old-key and the plaintext note are rewritten
+	source.set(`{"current": "k2", "keys": {"k1": "first-secret", "k2": "second-secret"}}`, nil)
+	_ = provider.Refresh(ctx)
+	if count, err := notes.Reencrypt(ctx, []string{"u1"}, true); err != nil || count != 2 {
+		t.Fatalf("Expected a dry run to count 2 notes, got %d, %v", count, err)
+	}
+	if stored, _ := inner.Get(ctx, "n1"); fieldcrypt.KeyID(stored.Body) != "k1" {
+		t.Error("Expected a dry run to leave notes unchanged")
+	}
+	if count, err := notes.Reencrypt(ctx, []string{"u1"}, false); err != nil || count != 2 {
+		t.Fatalf("Expected 2 notes to be re-encrypted, got %d, %v", count, err)
+	}
+	for _, id := range []string{"n1", "legacy"} {
+		if stored, _ := inner.Get(ctx, id); fieldcrypt.KeyID(stored.Body) != "k2" {
+			t.Errorf("Expected note %s under the new key, got %q", id, stored.Body)
+		}
+	}
+
+	// The old key can now be retired
+	source.set(`{"current": "k2", "keys": {"k2": "second-secret"}}`, nil)
+	_ = provider.Refresh(ctx)
+	if note, err := notes.Get(ctx, "n1"); err != nil || note.Body != "current text" || note.History[0].Body != "first draft" {
+		t.Errorf("Expected the note to decrypt after retiring the old key, got %+v, %v", note, err)
+	}
+}
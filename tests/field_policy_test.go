@@ -0,0 +1,93 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/fieldpolicy"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/policy"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestFieldPolicy(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(userRepo, tokenService)
+	engine, err := policy.NewMatrix(map[string][]string{"admin": {"*"}, "support": {"user.list", "user.read"}})
+	if err != nil {
+		t.Fatalf("NewMatrix failed: %v", err)
+	}
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetPolicyEngine(engine)
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetFieldFilter(fieldpolicy.New(engine))
+	server := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	target := &domain.User{Name: "Target", Email: "target@example.com", Role: "user", Status: domain.UserStatusActive}
+	_ = userRepo.Create(ctx, target)
+	agent := &domain.User{Name: "Agent", Email: "agent@example.com", Role: "support", Status: domain.UserStatusActive}
+	_ = userRepo.Create(ctx, agent)
+	agentToken, _ := tokenService.GenerateToken(agent)
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	get := func(path, token string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body.Data
+	}
+
+	// Admins see private fields, other roles only the public ones
+	if rec, user := get("/api/v1/admin/users/"+target.ID, adminToken); rec.Code != http.StatusOK || user["email"] != target.Email || user["status"] != domain.UserStatusActive {
+		t.Errorf("Expected admins to see private fields, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec, user := get("/api/v1/admin/users/"+target.ID, agentToken)
+	if rec.Code != http.StatusOK || user["name"] != "Target" {
+		t.Fatalf("Expected the user, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, field := range []string{"email", "role", "status"} {
+		if _, ok := user[field]; ok {
+			t.Errorf("Expected %s to be hidden, got %s", field, rec.Body.String())
+		}
+	}
+
+	// Viewers see their own private fields, also in lists
+	rec, list := get("/api/v1/admin/users?fields=id,email", agentToken)
+	users, _ := list["users"].([]interface{})
+	if rec.Code != http.StatusOK || len(users) != 2 {
+		t.Fatalf("Expected two users, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, item := range users {
+		user := item.(map[string]interface{})
+		if _, ok := user["email"]; ok != (user["id"] == agent.ID) {
+			t.Errorf("Expected only the viewer's own email, got %v", user)
+		}
+	}
+
+	// Hidden fields cannot be probed through filters or sorting
+	if rec, _ := get("/api/v1/admin/users?filter[email]=target@example.com", agentToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected filtering on a hidden field to be refused, got %d", rec.Code)
+	}
+	if rec, _ := get("/api/v1/admin/users?sort=-status", agentToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected sorting on a hidden field to be refused, got %d", rec.Code)
+	}
+	if rec, _ := get("/api/v1/admin/users?filter[email]=target@example.com", adminToken); rec.Code != http.StatusOK {
+		t.Errorf("Expected admins to filter on any field, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/middleware"
+)
+
+func TestHMACMiddleware_Authenticate(t *testing.T) {
+	const (
+		clientID = "billing-service"
+		secret   = "test-secret"
+	)
+
+	cfg := &config.HMACConfig{
+		Enabled:      true,
+		Clients:      map[string]string{clientID: secret},
+		MaxClockSkew: time.Minute,
+	}
+
+	signedRequest := func(nonce string, timestamp time.Time, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users", strings.NewReader(body))
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		sig := middleware.ComputeSignature([]byte(secret), req.Method, req.URL.RequestURI(), ts, nonce, []byte(body))
+		req.Header.Set(middleware.HeaderClientID, clientID)
+		req.Header.Set(middleware.HeaderTimestamp, ts)
+		req.Header.Set(middleware.HeaderNonce, nonce)
+		req.Header.Set(middleware.HeaderSignature, hex.EncodeToString(sig))
+		return req
+	}
+
+	tests := []struct {
+		name           string
+		buildRequest   func() *http.Request
+		replay         bool
+		expectedStatus int
+	}{
+		{
+			name: "valid signature",
+			buildRequest: func() *http.Request {
+				return signedRequest("nonce-1", time.Now(), `{"name":"x"}`)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "tampered body",
+			buildRequest: func() *http.Request {
+				req := signedRequest("nonce-2", time.Now(), `{"name":"x"}`)
+				req.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"y"}`)).Body
+				return req
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "stale timestamp",
+			buildRequest: func() *http.Request {
+				return signedRequest("nonce-3", time.Now().Add(-time.Hour), "")
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "replayed nonce",
+			buildRequest: func() *http.Request {
+				return signedRequest("nonce-4", time.Now(), "")
+			},
+			replay:         true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "body at the size limit",
+			buildRequest: func() *http.Request {
+				return signedRequest("nonce-5", time.Now(), strings.Repeat("a", 1<<20))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "body over the size limit",
+			buildRequest: func() *http.Request {
+				return signedRequest("nonce-6", time.Now(), strings.Repeat("a", 1<<20+1))
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "unsigned request passes through",
+			buildRequest: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/health", http.NoBody)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := middleware.NewHMACMiddleware(cfg, middleware.NewMemoryNonceStore())
+			handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			if tt.replay {
+				handler.ServeHTTP(httptest.NewRecorder(), tt.buildRequest())
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, tt.buildRequest())
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
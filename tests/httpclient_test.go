@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"demo-go/internal/httpclient"
+	"demo-go/internal/tracing"
+)
+
+func TestHTTPClientRetries(t *testing.T) {
+	var calls int32
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get(tracing.TraceParentHeader))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := httpclient.New("test", httpclient.Config{RetryBackoff: time.Millisecond})
+	span := tracing.NewRoot()
+	req, _ := http.NewRequestWithContext(tracing.ContextWithSpan(context.Background(), span), http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the third attempt to succeed, got %v %v", resp, err)
+	}
+	_ = resp.Body.Close()
+
+	metrics := client.Metrics()
+	if metrics.Requests != 1 || metrics.Attempts != 3 || metrics.Retries != 2 || metrics.Failures != 0 {
+		t.Errorf("Expected one request in 3 attempts, got %+v", metrics)
+	}
+	for _, traceParent := range traceParents {
+		if !strings.Contains(traceParent, span.TraceIDString()) {
+			t.Errorf("Expected every attempt to carry the trace, got %q", traceParent)
+		}
+	}
+
+	// Requests that are not idempotent are sent once
+	atomic.StoreInt32(&calls, 0)
+	resp, err = client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected a POST to be sent once, got %v %v after %d calls", resp, err, calls)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestHTTPClientCircuitBreaker(t *testing.T) {
+	var calls int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := httpclient.New("test", httpclient.Config{MaxAttempts: 1, BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond})
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected the failing response, got %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// The open circuit refuses requests without calling the service
+	if _, err := client.Get(server.URL); !errors.Is(err, httpclient.ErrCircuitOpen) || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("Expected the circuit to be open after %d calls, got %v", calls, err)
+	}
+	if metrics := client.Metrics(); metrics.CircuitState != httpclient.CircuitOpen || metrics.Rejected != 1 || metrics.Failures != 2 {
+		t.Errorf("Expected an open circuit in the metrics, got %+v", metrics)
+	}
+
+	// After the cooldown a successful trial closes it
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	resp, err := client.Get(server.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the trial request to succeed, got %v %v", resp, err)
+	}
+	_ = resp.Body.Close()
+	if state := client.Metrics().CircuitState; state != httpclient.CircuitClosed {
+		t.Errorf("Expected the circuit to close, got %s", state)
+	}
+}
@@ -0,0 +1,184 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestInviteOnlyRegistration(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	invites := service.NewInviteService(repository.NewMemoryInviteRepository(), userRepo, nil, nil, config.InviteConfig{Enabled: true, InviteOnly: true})
+	userService := service.NewUserService(userRepo, tokenService)
+	composed := service.ComposeUserService(userService, service.NewInviteUserCommands(userService, invites, true))
+	router := routes.NewRouter(handler.NewUserHandler(composed), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Invite Routes", routes.NewInviteRoutes(handler.NewInviteHandler(invites, nil)))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	register := func(email, code string) *httptest.ResponseRecorder {
+		return do(http.MethodPost, "/auth/register", "",
+			`{"name":"Invited","email":"`+email+`","password":"password123","invite_code":"`+code+`"}`)
+	}
+	errorCode := func(rec *httptest.ResponseRecorder) string {
+		var body struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return body.Error.Code
+	}
+	invite := func(body string) domain.CreatedInvite {
+		t.Helper()
+		rec := do(http.MethodPost, "/api/v1/admin/invites", adminToken, body)
+		var created struct {
+			Data domain.CreatedInvite `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &created)
+		if rec.Code != http.StatusCreated || created.Data.Code == "" {
+			t.Fatalf("Expected an invite with its code, got %d: %s", rec.Code, rec.Body.String())
+		}
+		return created.Data
+	}
+
+	if rec := register("new@example.com", ""); rec.Code != http.StatusForbidden || errorCode(rec) != domain.ErrInviteRequired.Code {
+		t.Fatalf("Expected registrations without an invite to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/invites", userToken, `{"email":"new@example.com"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected users to be refused creating invites, got %d", rec.Code)
+	}
+
+	// The code only works for the invited email, and registers it with the invite's role
+	created := invite(`{"email":"New@Example.com","role":"editor"}`)
+	if created.Email != "new@example.com" || created.Role != "editor" {
+		t.Errorf("Expected the invite for the normalized email and role, got %+v", created.Invite)
+	}
+	if rec := register("other@example.com", created.Code); rec.Code != http.StatusForbidden || errorCode(rec) != domain.ErrInvalidInvite.Code {
+		t.Errorf("Expected the invite to be refused for another email, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := register("new@example.com", created.Code)
+	var registered struct {
+		Data domain.UserResponse `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &registered)
+	if rec.Code != http.StatusCreated || registered.Data.Role != "editor" {
+		t.Fatalf("Expected the invited user to register as editor, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := register("new@example.com", created.Code); errorCode(rec) != domain.ErrInvalidInvite.Code {
+		t.Errorf("Expected a used invite to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Revoked invites cannot be used, and codes are never listed
+	revoked := invite(`{"email":"late@example.com"}`)
+	if rec := do(http.MethodDelete, "/api/v1/admin/invites/"+revoked.ID, adminToken, ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the invite to be revoked, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := register("late@example.com", revoked.Code); errorCode(rec) != domain.ErrInvalidInvite.Code {
+		t.Errorf("Expected a revoked invite to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/api/v1/admin/invites", adminToken, "")
+	var listed struct {
+		Data struct {
+			Invites []*domain.Invite `json:"invites"`
+			Total   int64            `json:"total"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &listed)
+	if listed.Data.Total != 1 || listed.Data.Invites[0].AcceptedBy != registered.Data.ID || strings.Contains(rec.Body.String(), created.Code) {
+		t.Errorf("Expected the accepted invite without its code, got %s", rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/invites", adminToken, `{"email":"new@example.com"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected registered emails to be refused an invite, got %d", rec.Code)
+	}
+}
+
+func TestInviteRoles(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	roleService, err := service.NewRoleService(repository.NewMemoryRoleRepository(), userRepo, nil, map[string][]string{"admin": {"*"}}, []string{"admin", "user"}, 0)
+	if err != nil {
+		t.Fatalf("NewRoleService failed: %v", err)
+	}
+	_ = roleService.Reload(ctx)
+	_, _ = roleService.CreateRole(ctx, "admin-1", "admin", &domain.CreateRoleRequest{Name: "recruiter", Permissions: []string{domain.PermissionInviteManage}})
+	_, _ = roleService.CreateRole(ctx, "admin-1", "admin", &domain.CreateRoleRequest{Name: "editor", Permissions: []string{domain.PermissionUserRead}})
+
+	auditRepo := repository.NewMemoryAuditRepository()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	invites := service.NewInviteService(repository.NewMemoryInviteRepository(), userRepo, roleService, service.NewAuditService(auditRepo), config.InviteConfig{Enabled: true})
+	userService := service.NewUserService(userRepo, tokenService, service.WithRoleCatalog(roleService))
+	composed := service.ComposeUserService(userService, service.NewInviteUserCommands(userService, invites, false))
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetPolicyEngine(roleService)
+	router := routes.NewRouter(handler.NewUserHandler(composed), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Invite Routes", routes.NewInviteRoutes(handler.NewInviteHandler(invites, jwtMiddleware)))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	recruiterToken, _ := tokenService.GenerateToken(&domain.User{ID: "recruiter-1", Email: "recruiter@example.com", Role: "recruiter"})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Granting a role through an invite takes role.assign
+	if rec := do(http.MethodPost, "/api/v1/admin/invites", recruiterToken, `{"email":"mole@example.com","role":"admin"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected invites with a role to require role.assign, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/invites", recruiterToken, `{"email":"hire@example.com"}`); rec.Code != http.StatusCreated {
+		t.Errorf("Expected invites without a role to be allowed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A role that cannot be assigned on registration is audited, not dropped silently
+	rec := do(http.MethodPost, "/api/v1/admin/invites", adminToken, `{"email":"editor@example.com","role":"editor"}`)
+	var created struct {
+		Data domain.CreatedInvite `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the admin to invite an editor, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := roleService.DeleteRole(ctx, "admin-1", "editor"); err != nil {
+		t.Fatalf("DeleteRole failed: %v", err)
+	}
+	rec = do(http.MethodPost, "/auth/register", "", `{"name":"Editor","email":"editor@example.com","password":"password123","invite_code":"`+created.Data.Code+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the invited user to register, got %d: %s", rec.Code, rec.Body.String())
+	}
+	failures, _ := auditRepo.List(ctx, domain.AuditFilter{Action: domain.AuditActionInviteRoleFailed}, 10, 0)
+	if len(failures) != 1 || failures[0].Details["role"] != "editor" || failures[0].Details["error"] == nil {
+		t.Errorf("Expected the failed role assignment to be audited, got %+v", failures)
+	}
+}
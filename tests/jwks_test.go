@@ -0,0 +1,134 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/keys"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keySetDocument encodes a key set document with PEM values
+func keySetDocument(t *testing.T, current string, pems map[string]string) string {
+	data, err := json.Marshal(map[string]interface{}{"current": current, "keys": pems})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return string(data)
+}
+
+func TestAsymmetricJWTKeysAndJWKS(t *testing.T) {
+	ctx := context.Background()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	rsaPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	rsaPublic, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	rsaPublicPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublic}))
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	edPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}))
+
+	source := &switchableKeySource{}
+	source.set(keySetDocument(t, "rsa-1", map[string]string{"rsa-1": rsaPEM}), nil)
+	provider := keys.NewProvider(source, config.JWTKeysConfig{Source: config.JWTKeySourceFile})
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	tokenService := service.NewJWTTokenServiceWithKeys(&config.Config{JWT: config.JWTConfig{Expiration: time.Hour}}, provider)
+	user := &domain.User{ID: "1", Email: "jwks@example.com", Role: "user"}
+
+	router := routes.NewRouter(handler.NewUserHandler(service.NewUserService(repository.NewMemoryUserRepository(), tokenService)),
+		middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("JWKS Routes", routes.NewJWKSRoutes(handler.NewJWKSHandler(tokenService.(domain.PublicKeyPublisher))))
+	server := router.SetupRoutes()
+	publishedKeys := func() []domain.JSONWebKey {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+		var doc struct {
+			Keys []domain.JSONWebKey `json:"keys"`
+		}
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &doc) != nil {
+			t.Fatalf("Expected a JWKS document, got %d: %s", rec.Code, rec.Body.String())
+		}
+		return doc.Keys
+	}
+
+	// Tokens are signed with RS256, name their key, and verify with the published key
+	rsaToken, err := tokenService.GenerateToken(user)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(rsaToken, jwt.MapClaims{})
+	if parsed.Method.Alg() != "RS256" || parsed.Header["kid"] != "rsa-1" {
+		t.Errorf("Expected an RS256 token with kid rsa-1, got %v %v", parsed.Method.Alg(), parsed.Header["kid"])
+	}
+	jwks := publishedKeys()
+	if len(jwks) != 1 || jwks[0].Kty != "RSA" || jwks[0].Kid != "rsa-1" || jwks[0].Alg != "RS256" ||
+		jwks[0].N != base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()) {
+		t.Errorf("Unexpected published keys %+v", jwks)
+	}
+
+	// After rotating to Ed25519, the old key verifies only, and both are published
+	source.set(keySetDocument(t, "ed-1", map[string]string{"ed-1": edPEM, "rsa-1": rsaPublicPEM}), nil)
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	edToken, _ := tokenService.GenerateToken(user)
+	parsed, _, _ = jwt.NewParser().ParseUnverified(edToken, jwt.MapClaims{})
+	if parsed.Method.Alg() != "EdDSA" || parsed.Header["kid"] != "ed-1" {
+		t.Errorf("Expected an EdDSA token with kid ed-1, got %v %v", parsed.Method.Alg(), parsed.Header["kid"])
+	}
+	for _, token := range []string{rsaToken, edToken} {
+		if _, err := tokenService.ValidateToken(token); err != nil {
+			t.Errorf("Expected the token to validate after rotation, got %v", err)
+		}
+	}
+	jwks = publishedKeys()
+	if len(jwks) != 2 || jwks[0].Kid != "ed-1" || jwks[0].Kty != "OKP" || jwks[0].Crv != "Ed25519" || jwks[1].Kid != "rsa-1" {
+		t.Errorf("Expected the current key first, then the previous one, got %+v", jwks)
+	}
+
+	// An HS256 token signed with the public key as its secret is refused
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "1", "email": "jwks@example.com", "role": "admin", "iss": config.DefaultJWTIssuer,
+		"iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = "rsa-1"
+	forgedToken, _ := forged.SignedString([]byte(rsaPublicPEM))
+	if _, err := tokenService.ValidateToken(forgedToken); err == nil {
+		t.Error("Expected an HS256 token for an RSA key to be refused")
+	}
+
+	// Public keys cannot be the current key, and short RSA keys are refused
+	if _, err := keys.ParseKeySet([]byte(keySetDocument(t, "rsa-1", map[string]string{"rsa-1": rsaPublicPEM}))); err == nil {
+		t.Error("Expected a public current key to be refused")
+	}
+	shortKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	shortPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(shortKey)}))
+	if _, err := keys.ParseKeySet([]byte(keySetDocument(t, "short", map[string]string{"short": shortPEM}))); err == nil ||
+		!strings.Contains(err.Error(), "2048") {
+		t.Errorf("Expected a 1024-bit RSA key to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/keys"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// switchableKeySource serves a key set document, or fails while err is set
+type switchableKeySource struct {
+	mu       sync.Mutex
+	document string
+	err      error
+}
+
+func (s *switchableKeySource) set(document string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.document, s.err = document, err
+}
+
+func (s *switchableKeySource) Load(ctx context.Context) (*domain.KeySet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return keys.ParseKeySet([]byte(s.document))
+}
+
+func TestJWTKeyRotationAndGraceMode(t *testing.T) {
+	ctx := context.Background()
+	source := &switchableKeySource{err: errors.New("secret store unreachable")}
+	provider := keys.NewProvider(source, config.JWTKeysConfig{Source: config.JWTKeySourceURL, GracePeriod: 50 * time.Millisecond})
+	cfg := &config.Config{JWT: config.JWTConfig{Expiration: time.Hour}}
+	tokenService := service.NewJWTTokenServiceWithKeys(cfg, provider)
+	user := &domain.User{ID: "1", Email: "keys@example.com", Role: "user"}
+
+	// Not ready until a key set has loaded
+	_ = provider.Refresh(ctx)
+	if _, err := tokenService.GenerateToken(user); !errors.Is(err, domain.ErrKeysUnavailable) {
+		t.Fatalf("Expected ErrKeysUnavailable before keys load, got %v", err)
+	}
+	if health := provider.CheckHealth(ctx); health.Status != domain.HealthStatusDegraded || health.Details["mode"] != "unavailable" {
+		t.Errorf("Expected unavailable health, got %+v", health)
+	}
+
+	source.set(`{"current": "k1", "keys": {"k1": "first-secret"}}`, nil)
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	oldToken, err := tokenService.GenerateToken(user)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	// After a rotation new tokens use the new key and old ones still validate
+	source.set(`{"current": "k2", "keys": {"k1": "first-secret", "k2": "second-secret"}}`, nil)
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	newToken, _ := tokenService.GenerateToken(user)
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := tokenService.ValidateToken(token); err != nil {
+			t.Errorf("Expected token to validate after rotation, got %v", err)
+		}
+	}
+	retired := service.NewJWTTokenServiceWithKeys(cfg, keys.NewStaticProvider(mustParseKeySet(t, `{"current": "k2", "keys": {"k2": "second-secret"}}`)))
+	if _, err := retired.ValidateToken(oldToken); err == nil {
+		t.Error("Expected tokens of a retired key to be rejected")
+	}
+
+	// A failing reload keeps serving the last key set while reporting degraded health
+	source.set("", errors.New("secret store unreachable"))
+	_ = provider.Refresh(ctx)
+	if _, err := tokenService.ValidateToken(newToken); err != nil {
+		t.Errorf("Expected tokens to validate in grace mode, got %v", err)
+	}
+	if health := provider.CheckHealth(ctx); health.Status != domain.HealthStatusDegraded || health.Details["mode"] != "grace" {
+		t.Errorf("Expected grace mode health, got %+v", health)
+	}
+
+	// Past the grace period the middleware asks clients to retry instead of rejecting their tokens
+	time.Sleep(60 * time.Millisecond)
+	router := routes.NewRouter(
+		handler.NewUserHandler(service.NewUserService(repository.NewMemoryUserRepository(), tokenService)),
+		middleware.NewJWTMiddleware(tokenService),
+		logger.GetGlobal(),
+	).SetupRoutes()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+newToken)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After after the grace period, got %d", rec.Code)
+	}
+
+	// Recovery leaves grace mode
+	source.set(`{"current": "k2", "keys": {"k1": "first-secret", "k2": "second-secret"}}`, nil)
+	if err := provider.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if health := provider.CheckHealth(ctx); health.Status != domain.HealthStatusHealthy || health.Details["current_key_id"] != "k2" {
+		t.Errorf("Expected healthy health after recovery, got %+v", health)
+	}
+}
+
+func TestJWTKeyFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt-keys.json")
+	if err := os.WriteFile(path, []byte(`{"current": "2025-10", "keys": {"2025-10": "file-secret"}}`), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	source, err := keys.NewSource(config.JWTKeysConfig{Source: config.JWTKeySourceFile, File: path})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	set, err := source.Load(context.Background())
+	if err != nil || set.Current.ID != "2025-10" || string(set.Current.Secret) != "file-secret" {
+		t.Errorf("Unexpected key set %+v, %v", set, err)
+	}
+
+	if _, err := keys.ParseKeySet([]byte(`{"current": "missing", "keys": {"k1": "secret"}}`)); err == nil {
+		t.Error("Expected a key set whose current key is missing to be rejected")
+	}
+	if _, err := keys.NewSource(config.JWTKeysConfig{Source: config.JWTKeySourceURL}); err == nil {
+		t.Error("Expected the url source to require JWT_KEYS_URL")
+	}
+}
+
+func mustParseKeySet(t *testing.T, document string) *domain.KeySet {
+	t.Helper()
+	set, err := keys.ParseKeySet([]byte(document))
+	if err != nil {
+		t.Fatalf("ParseKeySet failed: %v", err)
+	}
+	return set
+}
@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestJWTMiddlewareSkipRules(t *testing.T) {
+	jwtMiddleware := middleware.NewJWTMiddleware(service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour))
+	if err := jwtMiddleware.AddSkipRules("GET /.well-known/*", "/public/**", "post /auth/verify"); err != nil {
+		t.Fatalf("AddSkipRules failed: %v", err)
+	}
+
+	handler := jwtMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		method     string
+		path       string
+		expectCode int
+	}{
+		{http.MethodGet, "/health", http.StatusOK},
+		{http.MethodGet, "/admin-ui/js/app.js", http.StatusOK},
+		{http.MethodGet, "/.well-known/jwks.json", http.StatusOK},
+		{http.MethodPost, "/.well-known/jwks.json", http.StatusUnauthorized},
+		{http.MethodGet, "/.well-known/a/b", http.StatusUnauthorized},
+		{http.MethodGet, "/public/docs/index.html", http.StatusOK},
+		{http.MethodGet, "/public", http.StatusUnauthorized},
+		{http.MethodPost, "/auth/verify", http.StatusOK},
+		{http.MethodGet, "/auth/verify", http.StatusUnauthorized},
+		{http.MethodGet, "/api/v1/profile", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, http.NoBody))
+			if rr.Code != tt.expectCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectCode, rr.Code)
+			}
+		})
+	}
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		for _, rule := range []string{"health", "GET /bad/[pattern"} {
+			if err := jwtMiddleware.AddSkipRules(rule); err == nil {
+				t.Errorf("Expected error for rule %q", rule)
+			}
+		}
+	})
+
+	t.Run("rules can be added while serving", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				jwtMiddleware.AddSkipPaths("/auth/recovery/catalog")
+			}()
+			go func() {
+				defer wg.Done()
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+			}()
+		}
+		wg.Wait()
+	})
+}
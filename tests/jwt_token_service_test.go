@@ -0,0 +1,197 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/policy"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTTokenService_IssuerAudience(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{
+		SecretKey:  "test-secret",
+		Expiration: time.Hour,
+		Issuer:     "demo-go-staging",
+		Audience:   "demo-go-api",
+		ClockSkew:  30 * time.Second,
+		// The audience before a rename, still accepted but no longer issued
+		AcceptedAudiences: []string{"demo-api"},
+	}}
+	tokenService := service.NewJWTTokenService(cfg)
+
+	token, err := tokenService.GenerateToken(&domain.User{ID: "1", Email: "jwt@example.com", Role: "user"})
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if _, err := tokenService.ValidateToken(token); err != nil {
+		t.Errorf("Expected own token to validate, got %v", err)
+	}
+
+	sign := func(overrides jwt.MapClaims) string {
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"user_id": "1",
+			"email":   "jwt@example.com",
+			"role":    "user",
+			"exp":     now.Add(time.Hour).Unix(),
+			"iat":     now.Unix(),
+			"iss":     "demo-go-staging",
+			"aud":     "demo-go-api",
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name        string
+		overrides   jwt.MapClaims
+		expectValid bool
+	}{
+		{name: "other environment issuer", overrides: jwt.MapClaims{"iss": "demo-go-production"}},
+		{name: "missing issuer", overrides: jwt.MapClaims{"iss": nil}},
+		{name: "other service audience", overrides: jwt.MapClaims{"aud": "billing-api"}},
+		{name: "missing audience", overrides: jwt.MapClaims{"aud": nil}},
+		{name: "audience list containing ours", overrides: jwt.MapClaims{"aud": []string{"billing-api", "demo-go-api"}}, expectValid: true},
+		{name: "accepted previous audience", overrides: jwt.MapClaims{"aud": "demo-api"}, expectValid: true},
+		{name: "audience list containing an accepted one", overrides: jwt.MapClaims{"aud": []string{"billing-api", "demo-api"}}, expectValid: true},
+		{name: "expired within clock skew", overrides: jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()}, expectValid: true},
+		{name: "expired beyond clock skew", overrides: jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+		{name: "issued in the future", overrides: jwt.MapClaims{"iat": time.Now().Add(5 * time.Minute).Unix()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokenService.ValidateToken(sign(tt.overrides))
+			if tt.expectValid && err != nil {
+				t.Errorf("Expected token to validate, got %v", err)
+			}
+			if !tt.expectValid && err != domain.ErrInvalidToken {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJWTTokenService_TrustedIssuers(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{
+		SecretKey:      "own-secret",
+		Expiration:     time.Hour,
+		Issuer:         "demo-go-api",
+		Audience:       "demo-go-api",
+		ClaimNamespace: "https://demo-go/",
+		TrustedIssuers: []config.TrustedIssuerConfig{{
+			Tenant:         "acme",
+			Issuer:         "https://auth.acme.example",
+			Secret:         "acme-secret",
+			Audience:       "demo-go-api",
+			ClaimNamespace: "https://acme.example/",
+			Roles:          map[string]string{"admin": "user", "operator": "admin"},
+		}},
+	}}
+	if err := service.ValidateTrustedIssuers(cfg.JWT); err != nil {
+		t.Fatalf("ValidateTrustedIssuers failed: %v", err)
+	}
+	tokenService := service.NewJWTTokenService(cfg)
+
+	// Own tokens carry namespaced claims and no tenant
+	token, _ := tokenService.GenerateToken(&domain.User{ID: "1", Email: "own@example.com", Role: "user"})
+	claims, err := tokenService.ValidateToken(token)
+	if err != nil || claims.UserID != "1" || claims.Tenant != "" {
+		t.Fatalf("Expected own token to validate, got %+v, %v", claims, err)
+	}
+
+	sign := func(secret string, claims jwt.MapClaims) string {
+		now := time.Now()
+		claims["exp"], claims["iat"], claims["aud"] = now.Add(time.Hour).Unix(), now.Unix(), "demo-go-api"
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return signed
+	}
+	acmeClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                          "https://auth.acme.example",
+			"https://acme.example/user_id": "2",
+			"https://acme.example/email":   "tenant@acme.example",
+			"https://acme.example/role":    "admin",
+			"role":                         "user",
+		}
+	}
+
+	// Tenant users are namespaced by the tenant and get the role mapped for it
+	claims, err = tokenService.ValidateToken(sign("acme-secret", acmeClaims()))
+	if err != nil || claims.UserID != "acme:2" || claims.Role != "user" || claims.Tenant != "acme" {
+		t.Fatalf("Expected the tenant token to validate with its namespaced claims, got %+v, %v", claims, err)
+	}
+	unmapped := acmeClaims()
+	unmapped["https://acme.example/role"] = "owner"
+	if _, err := tokenService.ValidateToken(sign("acme-secret", unmapped)); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a tenant role without a mapping to be rejected, got %v", err)
+	}
+
+	// A tenant's token must be signed with its own secret and use its namespace
+	if _, err := tokenService.ValidateToken(sign("own-secret", acmeClaims())); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a tenant token signed with another secret to be rejected, got %v", err)
+	}
+	if _, err := tokenService.ValidateToken(sign("acme-secret", jwt.MapClaims{
+		"iss": "https://auth.acme.example", "user_id": "2", "email": "tenant@acme.example", "role": "admin",
+	})); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a tenant token without namespaced claims to be rejected, got %v", err)
+	}
+	if _, err := tokenService.ValidateToken(sign("acme-secret", jwt.MapClaims{
+		"iss": "https://auth.other.example", "https://acme.example/user_id": "2", "https://acme.example/email": "x@acme.example", "https://acme.example/role": "user",
+	})); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a token of an unknown issuer to be rejected, got %v", err)
+	}
+
+	// Tenant principals only use the permissions granted to their tenant,
+	// whatever their role holds
+	operator := acmeClaims()
+	operator["https://acme.example/role"] = "operator"
+	operatorToken := sign("acme-secret", operator)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	server := routes.NewRouter(handler.NewUserHandler(service.NewUserService(repository.NewMemoryUserRepository(), tokenService)), jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+	do := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+operatorToken)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := do(http.MethodGet, "/api/v1/admin/users"); code != http.StatusForbidden {
+		t.Errorf("Expected tenant principals to hold no permissions by default, got %d", code)
+	}
+	tenantPolicy, _ := policy.NewMatrix(map[string][]string{"acme": {domain.PermissionUserList}})
+	jwtMiddleware.SetTenantPolicy(tenantPolicy)
+	if code := do(http.MethodGet, "/api/v1/admin/users"); code != http.StatusOK {
+		t.Errorf("Expected the tenant's granted permission to be usable, got %d", code)
+	}
+	if code := do(http.MethodDelete, "/api/v1/admin/users/2"); code != http.StatusForbidden {
+		t.Errorf("Expected permissions not granted to the tenant to be refused, got %d", code)
+	}
+
+	cfg.JWT.TrustedIssuers = append(cfg.JWT.TrustedIssuers, config.TrustedIssuerConfig{Tenant: "impostor", Issuer: "demo-go-api", Secret: "x"})
+	if err := service.ValidateTrustedIssuers(cfg.JWT); err == nil {
+		t.Error("Expected a trusted issuer reusing this deployment's issuer to be rejected")
+	}
+}
@@ -0,0 +1,189 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/lifecycle"
+)
+
+func TestLifecycleOrder(t *testing.T) {
+	var events []string
+	hook := func(name string, dependsOn ...string) lifecycle.Hook {
+		return lifecycle.Hook{
+			Name:      name,
+			DependsOn: dependsOn,
+			Start: func(context.Context) error {
+				events = append(events, "start "+name)
+				return nil
+			},
+			Stop: func(context.Context) error {
+				events = append(events, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	manager := lifecycle.NewManager()
+	manager.MustRegister(hook("store"))
+	manager.MustRegister(hook("cache"))
+	manager.MustRegister(hook("server", "store", "cache"))
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	want := []string{"start store", "start cache", "start server", "stop server", "stop cache", "stop store"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected %v, got %v", want, events)
+	}
+
+	// Stop runs once
+	if err := manager.Stop(context.Background()); err != nil || len(events) != len(want) {
+		t.Errorf("Expected a second Stop to do nothing, got %v and %v", err, events)
+	}
+
+	// Dependencies must be registered first
+	if err := lifecycle.NewManager().Register(hook("server", "store")); err == nil {
+		t.Error("Expected an unregistered dependency to be rejected")
+	}
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	var stopped []string
+	manager := lifecycle.NewManager()
+	// A hook without Start, such as an open connection, is released too
+	manager.MustRegister(lifecycle.Hook{Name: "connection", Stop: func(context.Context) error {
+		stopped = append(stopped, "connection")
+		return nil
+	}})
+	manager.MustRegister(lifecycle.Hook{
+		Name:  "worker",
+		Start: func(context.Context) error { return nil },
+		Stop: func(context.Context) error {
+			stopped = append(stopped, "worker")
+			return nil
+		},
+	})
+	manager.MustRegister(lifecycle.Hook{
+		Name:  "server",
+		Start: func(context.Context) error { return errors.New("address in use") },
+		Stop: func(context.Context) error {
+			stopped = append(stopped, "server")
+			return nil
+		},
+	})
+
+	err := manager.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "address in use") {
+		t.Fatalf("Expected the start failure, got %v", err)
+	}
+	if want := []string{"worker", "connection"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("Expected the started hooks to stop as %v, got %v", want, stopped)
+	}
+}
+
+func TestLifecycleStopTimeout(t *testing.T) {
+	released := false
+	manager := lifecycle.NewManager()
+	manager.MustRegister(lifecycle.Hook{Name: "store", Stop: func(context.Context) error {
+		released = true
+		return nil
+	}})
+	manager.MustRegister(lifecycle.Hook{
+		Name:      "stuck",
+		DependsOn: []string{"store"},
+		Stop: func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+		Timeout: 20 * time.Millisecond,
+	})
+
+	began := time.Now()
+	err := manager.Stop(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the stuck hook to time out, got %v", err)
+	}
+	if elapsed := time.Since(began); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected Stop to stop waiting after the timeout, took %v", elapsed)
+	}
+	if !released {
+		t.Error("Expected the hooks after a timed-out one to stop")
+	}
+}
+
+// fakeServer serves until shut down, or fails with err once serving
+type fakeServer struct {
+	name    string
+	err     error
+	stopped *[]string
+	done    chan struct{}
+}
+
+func (s *fakeServer) Serve(listener net.Listener) error {
+	defer listener.Close()
+	if s.err != nil {
+		return s.err
+	}
+	<-s.done
+	return http.ErrServerClosed
+}
+
+func (s *fakeServer) Shutdown(context.Context) error {
+	*s.stopped = append(*s.stopped, s.name)
+	close(s.done)
+	return nil
+}
+
+func TestLifecycleListeners(t *testing.T) {
+	var stopped []string
+	manager := lifecycle.NewManager()
+	manager.MustRegister(lifecycle.Hook{Name: "store", Stop: func(context.Context) error {
+		stopped = append(stopped, "store")
+		return nil
+	}})
+	manager.MustRegister(manager.ListenerHook("http", "127.0.0.1:0",
+		&fakeServer{name: "http", stopped: &stopped, done: make(chan struct{})}, time.Second, "store"))
+	manager.MustRegister(manager.ListenerHook("admin", "127.0.0.1:0",
+		&fakeServer{name: "admin", err: errors.New("accept failed"), stopped: &stopped, done: make(chan struct{})}, time.Second, "store"))
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// The first listener to stop serving is reported, then everything stops in order
+	select {
+	case err := <-manager.Failed():
+		if !strings.Contains(err.Error(), "admin") || !strings.Contains(err.Error(), "accept failed") {
+			t.Errorf("Expected the admin listener's failure, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the failed listener to be reported")
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if want := []string{"admin", "http", "store"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("Expected the listeners to stop before the store as %v, got %v", want, stopped)
+	}
+
+	// An address in use fails startup
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer taken.Close()
+	busy := lifecycle.NewManager()
+	busy.MustRegister(busy.ListenerHook("http", taken.Addr().String(),
+		&fakeServer{name: "http", stopped: &stopped, done: make(chan struct{})}, time.Second))
+	if err := busy.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "start http") {
+		t.Errorf("Expected the busy address to fail startup, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestLoginHistory(t *testing.T) {
+	if err := middleware.SetTrustedProxies([]string{"192.0.2.1"}); err != nil {
+		t.Fatalf("SetTrustedProxies failed: %v", err)
+	}
+	defer middleware.SetTrustedProxies(nil)
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	history := service.NewLoginHistoryService(repository.NewMemoryLoginHistoryRepository(time.Hour), userRepo)
+	userService := service.NewUserService(userRepo, tokenService)
+	composed := service.ComposeUserService(userService, service.NewLoginHistoryUserCommands(userService, history, userRepo))
+	router := routes.NewRouter(handler.NewUserHandler(composed), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Login History Routes", routes.NewLoginHistoryRoutes(handler.NewLoginHistoryHandler(history)))
+	server := router.SetupRoutes()
+
+	user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Member", Email: "member@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: user.ID, Email: user.Email, Role: "user"})
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+
+	login := func(email, password string) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"`+email+`","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "history-test/1.0")
+		req.Header.Set("X-Real-IP", "203.0.113.7")
+		server.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	type page struct {
+		Data struct {
+			Logins []*domain.LoginAttempt `json:"logins"`
+			Total  int64                  `json:"total"`
+		} `json:"data"`
+	}
+
+	login("member@example.com", "wrong-password")
+	login("member@example.com", "password123")
+	login("nobody@example.com", "password123")
+
+	rec := get("/api/v1/profile/login-history", userToken)
+	var mine page
+	_ = json.Unmarshal(rec.Body.Bytes(), &mine)
+	if rec.Code != http.StatusOK || mine.Data.Total != 2 || len(mine.Data.Logins) != 2 {
+		t.Fatalf("Expected two recorded logins, got %d: %s", rec.Code, rec.Body.String())
+	}
+	latest, failed := mine.Data.Logins[0], mine.Data.Logins[1]
+	if latest.Outcome != domain.LoginOutcomeSuccess || latest.Method != domain.LoginMethodPassword || latest.IPAddress != "203.0.113.7" || latest.UserAgent != "history-test/1.0" {
+		t.Errorf("Expected the successful login first with its client, got %+v", latest)
+	}
+	if failed.Outcome != domain.LoginOutcomeFailure || failed.Reason != domain.ErrInvalidCredentials.Code {
+		t.Errorf("Expected the failed login with its reason, got %+v", failed)
+	}
+
+	// Admins see any user's history; users cannot
+	if rec := get("/api/v1/admin/users/"+user.ID+"/login-history?limit=1", adminToken); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"total":2`) {
+		t.Errorf("Expected admins to see the user's history, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get("/api/v1/admin/users/missing/login-history", adminToken); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown users to be not found, got %d", rec.Code)
+	}
+	if rec := get("/api/v1/admin/users/"+user.ID+"/login-history", userToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected users to be refused other histories, got %d", rec.Code)
+	}
+}
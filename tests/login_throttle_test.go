@@ -0,0 +1,223 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/service"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	cfg := config.LoginThrottleConfig{
+		MaxFailures:     2,
+		Window:          time.Minute,
+		StatusMaxChecks: 2,
+		StatusWindow:    time.Minute,
+	}
+	limiter := ratelimit.NewMemoryLimiter()
+	inner := &mockUserService{
+		loginFunc: func(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error) {
+			if req.Password != "right" {
+				return "", nil, domain.ErrInvalidCredentials
+			}
+			return "token", &domain.UserResponse{ID: "1", Email: req.Email}, nil
+		},
+	}
+	userHandler := handler.NewUserHandler(service.ComposeUserService(
+		inner, service.NewThrottledUserCommands(inner, limiter, cfg),
+	))
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body := `{"email":"locked@example.com","password":"` + password + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		userHandler.Login(rec, req)
+		return rec
+	}
+
+	rec := login("wrong")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("X-Auth-Remaining-Attempts") != "1" {
+		t.Fatalf("Expected 401 with one attempt remaining, got %d %q", rec.Code, rec.Header().Get("X-Auth-Remaining-Attempts"))
+	}
+	rec = login("wrong")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("X-Auth-Retry-After") == "" {
+		t.Fatalf("Expected the last failure to report when to retry, got %d %v", rec.Code, rec.Header())
+	}
+
+	// Locked out even with the right password
+	rec = login("right")
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "LOGIN_LOCKED") {
+		t.Fatalf("Expected 429 LOGIN_LOCKED, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After on a locked out login")
+	}
+
+	statusService := service.NewLoginThrottleService(limiter, cfg)
+	status, err := statusService.LockoutStatus(context.Background(), "Locked@Example.com", "10.0.0.1")
+	if err != nil || !status.Locked || status.RemainingAttempts != 0 || status.RetryAfterSeconds <= 0 {
+		t.Errorf("Expected locked status, got %+v (%v)", status, err)
+	}
+	status, err = statusService.LockoutStatus(context.Background(), "other@example.com", "10.0.0.1")
+	if err != nil || status.Locked || status.RemainingAttempts != 2 {
+		t.Errorf("Expected untouched email to have every attempt left, got %+v (%v)", status, err)
+	}
+	if _, err := statusService.LockoutStatus(context.Background(), "other@example.com", "10.0.0.1"); err != domain.ErrRateLimited {
+		t.Errorf("Expected status checks to be rate limited, got %v", err)
+	}
+}
+
+func TestLoginThrottlePerClientIP(t *testing.T) {
+	if err := middleware.SetTrustedProxies([]string{"192.0.2.0/24"}); err != nil {
+		t.Fatalf("SetTrustedProxies failed: %v", err)
+	}
+	defer middleware.SetTrustedProxies(nil)
+	cfg := config.LoginThrottleConfig{MaxFailures: 5, Window: time.Minute, IPMaxFailures: 3, IPWindow: time.Minute}
+	inner := &mockUserService{
+		loginFunc: func(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error) {
+			return "", nil, domain.ErrInvalidCredentials
+		},
+	}
+	userHandler := handler.NewUserHandler(service.ComposeUserService(
+		inner, service.NewThrottledUserCommands(inner, ratelimit.NewMemoryLimiter(), cfg),
+	))
+	server := middleware.LoggingMiddleware(logger.GetGlobal())(http.HandlerFunc(userHandler.Login))
+
+	login := func(email, clientIP string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"`+email+`","password":"guess"}`))
+		req.Header.Set("X-Forwarded-For", clientIP)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Spraying different emails from one IP locks the IP out
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if rec := login(email, "203.0.113.7"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected attempt %d to fail with 401, got %d", i+1, rec.Code)
+		}
+	}
+	rec := login("d@example.com", "203.0.113.7")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("Expected 429 with Retry-After for the sprayed IP, got %d %v", rec.Code, rec.Header())
+	}
+
+	// Other clients are unaffected
+	if rec := login("d@example.com", "198.51.100.2"); rec.Code != http.StatusUnauthorized || rec.Header().Get("X-Auth-Remaining-Attempts") != "2" {
+		t.Errorf("Expected another IP to keep its attempts, got %d %q", rec.Code, rec.Header().Get("X-Auth-Remaining-Attempts"))
+	}
+
+	// Forwarding headers from peers that are not trusted proxies are ignored
+	if err := middleware.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatalf("SetTrustedProxies failed: %v", err)
+	}
+	for _, spoofed := range []string{"198.51.100.3", "198.51.100.4", "198.51.100.5"} {
+		login("e@example.com", spoofed)
+	}
+	if rec := login("f@example.com", "198.51.100.6"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected spoofed X-Forwarded-For values to count against the peer, got %d", rec.Code)
+	}
+}
+
+func TestGetClientIP(t *testing.T) {
+	if err := middleware.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatalf("SetTrustedProxies failed: %v", err)
+	}
+	defer middleware.SetTrustedProxies(nil)
+
+	tests := []struct {
+		name, remoteAddr, forwardedFor, want string
+	}{
+		{"untrusted peer", "203.0.113.9:4000", "198.51.100.1", "203.0.113.9"},
+		{"trusted peer", "10.0.0.2:4000", "198.51.100.1", "198.51.100.1"},
+		{"spoofed leftmost entry", "10.0.0.2:4000", "1.2.3.4, 198.51.100.1, 10.0.0.3", "198.51.100.1"},
+		{"no header", "10.0.0.2:4000", "", "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+		if got := middleware.GetClientIP(req); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	if err := middleware.SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Error("Expected an invalid trusted proxy to be rejected")
+	}
+}
+
+// slidingWindowCache is the part of cache.Service the Redis rate limiter
+// uses, keeping event times in memory
+type slidingWindowCache struct {
+	cache.Service
+	events map[string][]time.Time
+}
+
+func (c *slidingWindowCache) SlidingWindow(_ context.Context, key string, limit int, window time.Duration, record bool) (int, time.Time, error) {
+	now := time.Now()
+	var kept []time.Time
+	for _, at := range c.events[key] {
+		if now.Sub(at) < window {
+			kept = append(kept, at)
+		}
+	}
+	count := len(kept)
+	if record && count < limit {
+		kept = append(kept, now)
+	}
+	c.events[key] = kept
+	if len(kept) == 0 {
+		return count, time.Time{}, nil
+	}
+	return count, kept[0], nil
+}
+
+func (c *slidingWindowCache) Delete(_ context.Context, key string) error {
+	delete(c.events, key)
+	return nil
+}
+
+func TestSlidingWindowRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := cache.NewRateLimiter(&slidingWindowCache{events: make(map[string][]time.Time)})
+
+	for i := 0; i < 2; i++ {
+		if result, err := limiter.Allow(ctx, "k", 2, 200*time.Millisecond); err != nil || !result.Allowed || result.Remaining != 1-i {
+			t.Fatalf("Expected event %d to be allowed, got %+v, %v", i+1, result, err)
+		}
+	}
+	result, _ := limiter.Allow(ctx, "k", 2, 200*time.Millisecond)
+	if result.Allowed || result.RetryAfter <= 0 {
+		t.Errorf("Expected a full window to refuse with RetryAfter, got %+v", result)
+	}
+	if peek, _ := limiter.Peek(ctx, "k", 2, 200*time.Millisecond); peek.Allowed {
+		t.Error("Expected Peek to report the full window")
+	}
+
+	// Events leave the window individually as they age
+	time.Sleep(250 * time.Millisecond)
+	if peek, _ := limiter.Peek(ctx, "k", 2, 200*time.Millisecond); !peek.Allowed || peek.Remaining != 2 {
+		t.Errorf("Expected the window to have emptied, got %+v", peek)
+	}
+	_, _ = limiter.Allow(ctx, "k", 2, time.Minute)
+	if err := limiter.Reset(ctx, "k"); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if peek, _ := limiter.Peek(ctx, "k", 2, time.Minute); peek.Remaining != 2 {
+		t.Errorf("Expected Reset to clear the window, got %+v", peek)
+	}
+}
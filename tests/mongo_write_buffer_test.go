@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// electingUserRepository fails writes with NotWritablePrimary while electing
+// is set. With applyFirst the first failing insert is stored anyway, like an
+// insert acknowledged by a primary that stepped down before replying.
+type electingUserRepository struct {
+	domain.UserRepository
+	applyFirst bool
+
+	mu       sync.Mutex
+	electing bool
+	attempts int
+}
+
+func (r *electingUserRepository) setElecting(electing bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.electing = electing
+}
+
+func (r *electingUserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	electing := r.electing
+	r.attempts++
+	first := r.attempts == 1
+	r.mu.Unlock()
+
+	if electing {
+		if first && r.applyFirst {
+			_ = r.UserRepository.Create(ctx, user)
+		}
+		return mongo.CommandError{Code: 10107, Message: "not primary"}
+	}
+	return r.UserRepository.Create(ctx, user)
+}
+
+func TestMongoWriteBuffer(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.WriteBufferConfig{Enabled: true, MaxPending: 1, Timeout: 200 * time.Millisecond, RetryInterval: 5 * time.Millisecond}
+
+	// A registration during an election waits for the new primary
+	inner := &electingUserRepository{UserRepository: repository.NewMemoryUserRepository(), applyFirst: true, electing: true}
+	buffer := repository.NewFailoverUserRepository(inner, cfg)
+	done := make(chan error, 1)
+	go func() {
+		done <- buffer.Create(ctx, &domain.User{ID: "u1", Email: "election@example.com", Name: "Election"})
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for buffer.CheckHealth(ctx).Details["queue_depth"] != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if health := buffer.CheckHealth(ctx); health.Status != domain.HealthStatusDegraded {
+		t.Errorf("Expected degraded health while a write is queued, got %+v", health)
+	}
+
+	// The buffer is full, so further writes fail at once
+	if err := buffer.Create(ctx, &domain.User{ID: "u2", Email: "overflow@example.com"}); !errors.Is(err, domain.ErrStorageUnavailable) {
+		t.Errorf("Expected ErrStorageUnavailable for a full buffer, got %v", err)
+	}
+
+	// The retried insert was already stored before the failover, which counts as success
+	inner.setElecting(false)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected the buffered registration to succeed, got %v", err)
+	}
+	health := buffer.CheckHealth(ctx)
+	if health.Status != domain.HealthStatusHealthy || health.Details["retried_writes"] != int64(1) || health.Details["dropped_writes"] != int64(1) {
+		t.Errorf("Unexpected health after recovery: %+v", health)
+	}
+
+	// Writes still failing after the timeout are dropped
+	inner.setElecting(true)
+	if err := buffer.Create(ctx, &domain.User{ID: "u3", Email: "timeout@example.com"}); !errors.Is(err, domain.ErrStorageUnavailable) {
+		t.Errorf("Expected ErrStorageUnavailable after the timeout, got %v", err)
+	}
+
+	// Other errors are returned unchanged
+	inner.setElecting(false)
+	if err := buffer.Create(ctx, &domain.User{ID: "u4", Email: "election@example.com"}); !errors.Is(err, domain.ErrUserAlreadyExists) {
+		t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/oidc"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeIdentityProvider is an OpenID Connect provider that issues an ID token
+// with the claims of the next login for any code
+type fakeIdentityProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+	// challenges remembers the PKCE challenge of each issued code
+	challenges map[string]string
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	idp := &fakeIdentityProvider{key: key, challenges: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		code := r.FormValue("code")
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if clientID != "demo-client" || secret != "demo-secret" || idp.challenges[code] != base64.RawURLEncoding.EncodeToString(verifier[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims)
+		token.Header["kid"] = "k1"
+		signed, _ := token.SignedString(key)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func TestOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdentityProvider(t)
+	cfg := config.OIDCConfig{
+		Enabled: true, IssuerURL: idp.URL, ClientID: "demo-client", ClientSecret: "demo-secret",
+		RedirectURL: "http://localhost:8080/auth/oidc/callback", Scopes: []string{"openid", "email"},
+		AutoProvision: true, RequireVerifiedEmail: true, RoleClaim: "groups",
+		RoleMapping: map[string]string{"platform-admins": "admin"}, Leeway: time.Minute,
+	}
+	if err := oidc.ValidateConfig(cfg); err != nil {
+		t.Fatalf("ValidateConfig failed: %v", err)
+	}
+
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	oidcService := service.NewOIDCService(oidc.NewProvider(cfg, nil), userRepo, repository.NewMemoryOIDCStateStore(),
+		tokenService, nil, nil, "user", cfg)
+
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Single Sign-On Routes", routes.NewOIDCRoutes(handler.NewOIDCHandler(oidcService, nil)))
+	router.DisablePasswordLogin()
+	server := router.SetupRoutes()
+
+	get := func(path, token string) (int, json.RawMessage) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Data
+	}
+	// login walks through the redirect to the provider and back with claims
+	login := func(claims jwt.MapClaims) (int, json.RawMessage, string) {
+		status, data := get("/auth/oidc/login", "")
+		var started struct {
+			AuthorizationURL string `json:"authorization_url"`
+		}
+		_ = json.Unmarshal(data, &started)
+		authURL, err := url.Parse(started.AuthorizationURL)
+		if status != http.StatusOK || err != nil {
+			t.Fatalf("Expected an authorization URL, got %d: %s", status, data)
+		}
+		query := authURL.Query()
+		if query.Get("client_id") != "demo-client" || query.Get("code_challenge_method") != "S256" || query.Get("scope") != "openid email" {
+			t.Fatalf("Unexpected authorization URL %s", authURL)
+		}
+
+		code := "code-" + query.Get("state")[:8]
+		idp.challenges[code] = query.Get("code_challenge")
+		idp.claims = jwt.MapClaims{
+			"iss": idp.URL, "aud": "demo-client", "nonce": query.Get("nonce"),
+			"iat": time.Now().Unix(), "exp": time.Now().Add(5 * time.Minute).Unix(),
+		}
+		for name, value := range claims {
+			idp.claims[name] = value
+		}
+		callback := "/auth/oidc/callback?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+		status, data = get(callback, "")
+		return status, data, callback
+	}
+
+	// First login provisions the user, with the mapped role
+	status, data, callback := login(jwt.MapClaims{
+		"sub": "idp-user-1", "email": "SSO@example.com", "email_verified": true, "name": "Single Sign", "groups": []string{"staff", "platform-admins"},
+	})
+	var session struct {
+		Token string               `json:"token"`
+		User  *domain.UserResponse `json:"user"`
+	}
+	_ = json.Unmarshal(data, &session)
+	if status != http.StatusOK || session.Token == "" || session.User.Email != "sso@example.com" || session.User.Role != "admin" || session.User.Name != "Single Sign" {
+		t.Fatalf("Expected the first login to provision an admin, got %d: %s", status, data)
+	}
+	if status, _ := get("/api/v1/profile", session.Token); status != http.StatusOK {
+		t.Errorf("Expected the single sign-on token to authenticate, got %d", status)
+	}
+	if status, _ := get(callback, ""); status != http.StatusUnauthorized {
+		t.Errorf("Expected a replayed callback to be refused, got %d", status)
+	}
+
+	// The next login from the provider demotes the user when the group is gone
+	status, data, _ = login(jwt.MapClaims{"sub": "idp-user-1", "email": "sso@example.com", "email_verified": true})
+	_ = json.Unmarshal(data, &session)
+	if status != http.StatusOK || session.User.Role != "user" {
+		t.Errorf("Expected the role to follow the provider, got %d: %s", status, data)
+	}
+
+	// Another provider account with the same email, unverified emails and
+	// tokens for another client are refused
+	if status, _, _ := login(jwt.MapClaims{"sub": "idp-user-2", "email": "sso@example.com", "email_verified": true}); status != http.StatusUnauthorized {
+		t.Errorf("Expected another subject for a linked email to be refused, got %d", status)
+	}
+	if status, _, _ := login(jwt.MapClaims{"sub": "idp-user-3", "email": "new@example.com", "email_verified": false}); status != http.StatusUnauthorized {
+		t.Errorf("Expected an unverified email to be refused, got %d", status)
+	}
+	if status, _, _ := login(jwt.MapClaims{"sub": "idp-user-3", "email": "new@example.com", "email_verified": true, "aud": "other-client"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected an ID token for another client to be refused, got %d", status)
+	}
+	if status, _, _ := login(jwt.MapClaims{"sub": "idp-user-3", "email": "new@example.com", "email_verified": true, "nonce": "stale"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected an ID token with another nonce to be refused, got %d", status)
+	}
+
+	// Existing local accounts are linked on their first single sign-on
+	local, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Local User", Email: "local@example.com", Password: "password123"})
+	status, data, _ = login(jwt.MapClaims{"sub": "idp-local", "email": "local@example.com", "email_verified": "true"})
+	_ = json.Unmarshal(data, &session)
+	if status != http.StatusOK || session.User.ID != local.ID {
+		t.Errorf("Expected the local account to be linked, got %d: %s", status, data)
+	}
+
+	// Password login is off
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected password login to be disabled, got %d", rec.Code)
+	}
+}
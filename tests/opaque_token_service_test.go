@@ -0,0 +1,61 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestOpaqueTokenService(t *testing.T) {
+	store := repository.NewMemoryTokenStore()
+	tokenService := service.NewOpaqueTokenService(store, time.Hour)
+	user := &domain.User{ID: "42", Email: "opaque@example.com", Role: "admin"}
+
+	token, err := tokenService.GenerateToken(user)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	claims, err := tokenService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.UserID != user.ID || claims.Role != user.Role {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err := tokenService.ValidateToken("opq_unknown"); err != domain.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for unknown token, got %v", err)
+	}
+
+	// The JWT middleware accepts opaque tokens through the TokenService interface
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	handler := jwtMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, _ := middleware.GetUserIDFromContext(r.Context()); userID != user.ID {
+			t.Errorf("Expected user ID %s in context, got %s", user.ID, userID)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", http.NoBody)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	// Deleting the token from the store revokes it immediately
+	if err := store.Delete(req.Context(), token); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := tokenService.ValidateToken(token); err != domain.ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken after revocation, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/policy"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestOwnershipChecks(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(userRepo, tokenService)
+	engine, err := policy.NewMatrix(map[string][]string{"admin": {"*"}, "moderator": {"user.read", "user.update", "user.bulk"}})
+	if err != nil {
+		t.Fatalf("NewMatrix failed: %v", err)
+	}
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetPolicyEngine(engine)
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetAuthorizer(jwtMiddleware)
+	router := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Policy Routes", routes.NewPolicyRoutes(handler.NewPolicyHandler(engine, userService)))
+	server := router.SetupRoutes()
+
+	member := &domain.User{Name: "Member", Email: "member@example.com", Role: "user"}
+	_ = userRepo.Create(ctx, member)
+	other := &domain.User{Name: "Other", Email: "other@example.com", Role: "user"}
+	_ = userRepo.Create(ctx, other)
+	memberToken, _ := tokenService.GenerateToken(member)
+	moderatorToken, _ := tokenService.GenerateToken(&domain.User{ID: "moderator-1", Email: "moderator@example.com", Role: "moderator"})
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Owners act on their own resources; others need the route's permission
+	if rec := do(http.MethodGet, "/api/v1/admin/users/"+member.ID+"/permissions", memberToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected users to see their own permissions, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/users/"+other.ID+"/permissions", memberToken, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected other users' permissions to be refused, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/users/"+other.ID+"/permissions", adminToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected admins to see any user's permissions, got %d", rec.Code)
+	}
+
+	// Updating users does not allow changing their role without role.assign
+	if rec := do(http.MethodPut, "/api/v1/admin/users/"+member.ID, moderatorToken, `{"role":"admin"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the role change to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if user, _ := userRepo.GetByID(ctx, member.ID); user.Role != "user" {
+		t.Fatalf("Expected the role to be unchanged, got %s", user.Role)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/users/"+member.ID, moderatorToken, `{"name":"Renamed","role":"user"}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected an update keeping the role to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := `{"ids":["` + member.ID + `"],"action":"set-role","role":"admin"}`
+	if rec := do(http.MethodPost, "/api/v1/admin/users/bulk", moderatorToken, body); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the bulk role change to be refused, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/users/"+member.ID, adminToken, `{"role":"admin"}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected admins to change roles, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
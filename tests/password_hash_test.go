@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/passwordhash"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestPasswordHashers(t *testing.T) {
+	bcryptHasher, err := passwordhash.New(config.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("New bcrypt failed: %v", err)
+	}
+	argonHasher, err := passwordhash.New(config.PasswordHashConfig{Algorithm: "argon2id", Argon2: config.Argon2Config{Memory: 1024, Iterations: 1, Parallelism: 1}})
+	if err != nil {
+		t.Fatalf("New argon2id failed: %v", err)
+	}
+
+	bcryptHash, _ := bcryptHasher.Hash("correct horse")
+	argonHash, _ := argonHasher.Hash("correct horse")
+	if !strings.HasPrefix(bcryptHash, "$2a$04$") || !strings.HasPrefix(argonHash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("Expected self-describing hashes, got %q and %q", bcryptHash, argonHash)
+	}
+	if again, _ := argonHasher.Hash("correct horse"); again == argonHash {
+		t.Error("Expected every argon2id hash to have its own salt")
+	}
+
+	// Either hasher verifies hashes of both algorithms
+	for _, hasher := range []domain.PasswordHasher{bcryptHasher, argonHasher} {
+		for _, hash := range []string{bcryptHash, argonHash} {
+			if err := hasher.Verify(hash, "correct horse"); err != nil {
+				t.Errorf("Expected %q to verify, got %v", hash, err)
+			}
+			if err := hasher.Verify(hash, "wrong horse"); !errors.Is(err, passwordhash.ErrMismatch) {
+				t.Errorf("Expected a mismatch for %q, got %v", hash, err)
+			}
+		}
+	}
+	if err := passwordhash.Verify("plaintext", "plaintext"); !errors.Is(err, passwordhash.ErrUnknownFormat) {
+		t.Errorf("Expected unknown formats to be rejected, got %v", err)
+	}
+
+	for _, cfg := range []config.PasswordHashConfig{
+		{Algorithm: "md5"},
+		{BcryptCost: 40},
+		{Algorithm: "argon2id", Argon2: config.Argon2Config{Memory: 8, Parallelism: 4}},
+	} {
+		if _, err := passwordhash.New(cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+
+	// Users keep logging in after switching to argon2id
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	_ = userRepo.Create(ctx, &domain.User{ID: "old-user", Name: "Old User", Email: "old@example.com", Password: bcryptHash, Role: "user", Status: domain.UserStatusActive})
+	userService := service.NewUserService(userRepo, tokenService, service.WithPasswordHasher(argonHasher))
+	if _, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "New User", Email: "new@example.com", Password: "battery staple"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	stored, _ := userRepo.GetByEmail(ctx, "new@example.com")
+	if !strings.HasPrefix(stored.Password, "$argon2id$") {
+		t.Errorf("Expected new passwords to be hashed with argon2id, got %q", stored.Password)
+	}
+	for email, password := range map[string]string{"new@example.com": "battery staple", "old@example.com": "correct horse"} {
+		if _, _, err := userService.Login(ctx, &domain.LoginRequest{Email: email, Password: password}); err != nil {
+			t.Errorf("Expected %s to log in, got %v", email, err)
+		}
+	}
+}
+
+func TestPasswordHashUpgradeOnLogin(t *testing.T) {
+	bcryptHasher, _ := passwordhash.NewBcrypt(4)
+	strongerBcrypt, _ := passwordhash.NewBcrypt(5)
+	argonHasher, _ := passwordhash.NewArgon2id(config.Argon2Config{Memory: 1024, Iterations: 1, Parallelism: 1})
+	biggerArgon, _ := passwordhash.NewArgon2id(config.Argon2Config{Memory: 2048, Iterations: 1, Parallelism: 1})
+
+	bcryptHash, _ := bcryptHasher.Hash("correct horse")
+	argonHash, _ := argonHasher.Hash("correct horse")
+	if bcryptHasher.NeedsRehash(bcryptHash) || argonHasher.NeedsRehash(argonHash) {
+		t.Error("Expected hashes of the current settings to be kept")
+	}
+	if !strongerBcrypt.NeedsRehash(bcryptHash) || !biggerArgon.NeedsRehash(argonHash) ||
+		!bcryptHasher.NeedsRehash(argonHash) || !argonHasher.NeedsRehash(bcryptHash) {
+		t.Error("Expected hashes of other algorithms or parameters to need a rehash")
+	}
+
+	// A successful login stores the password under the current settings
+	ctx := context.Background()
+	userRepo := repository.NewMemoryUserRepository()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	_ = userRepo.Create(ctx, &domain.User{ID: "old-user", Name: "Old User", Email: "old@example.com", Password: bcryptHash, Role: "user", Status: domain.UserStatusActive})
+	userService := service.NewUserService(userRepo, tokenService, service.WithPasswordHasher(argonHasher))
+
+	if _, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "old@example.com", Password: "wrong horse"}); err == nil {
+		t.Fatal("Expected a wrong password to fail")
+	}
+	if stored, _ := userRepo.GetByID(ctx, "old-user"); stored.Password != bcryptHash {
+		t.Error("Expected failed logins to leave the hash alone")
+	}
+	if _, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "old@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	stored, _ := userRepo.GetByID(ctx, "old-user")
+	if !strings.HasPrefix(stored.Password, "$argon2id$v=19$m=1024,t=1,p=1$") || passwordhash.Verify(stored.Password, "correct horse") != nil {
+		t.Errorf("Expected the hash to be upgraded to argon2id, got %q", stored.Password)
+	}
+}
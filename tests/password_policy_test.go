@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/passwordpolicy"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	breachedHash := sha1.Sum([]byte("Password1!"))
+	breached := strings.ToUpper(hex.EncodeToString(breachedHash[:]))
+	var prefixes []string
+	rangeAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+		prefixes = append(prefixes, prefix)
+		if prefix == breached[:5] {
+			fmt.Fprintf(w, "0000000000000000000000000000000000A:0\r\n%s:42\r\n", breached[5:])
+			return
+		}
+		fmt.Fprint(w, breached[5:]+":0\r\n")
+	}))
+	defer rangeAPI.Close()
+
+	policy := passwordpolicy.New(config.PasswordPolicyConfig{
+		MinLength: 8, RequireUppercase: true, RequireDigit: true, RequireSymbol: true,
+		BreachCheck: true, BreachCheckURL: rangeAPI.URL + "/range/",
+	})
+
+	// Every broken rule is reported, and breaches are not looked up for them
+	var fieldsErr *domain.InvalidFieldsError
+	if err := policy.Check(ctx, "password", "short"); !errors.As(err, &fieldsErr) || len(fieldsErr.Fields) != 4 {
+		t.Fatalf("Expected 4 violations, got %v", err)
+	}
+	if !errors.Is(fieldsErr, domain.ErrValidationFailed) || fieldsErr.Fields[0].Rule != passwordpolicy.RuleMinLength || len(prefixes) != 0 {
+		t.Errorf("Expected a min_length validation failure first without a lookup, got %+v", fieldsErr.Fields)
+	}
+
+	// Only the hash prefix leaves the process
+	if err := policy.Check(ctx, "password", "Password1!"); !errors.As(err, &fieldsErr) || fieldsErr.Fields[0].Rule != passwordpolicy.RuleBreached {
+		t.Errorf("Expected a breached password to be rejected, got %v", err)
+	}
+	if err := policy.Check(ctx, "password", "Unlisted-Passw0rd"); err != nil {
+		t.Errorf("Expected a strong unlisted password to pass, got %v", err)
+	}
+	for _, prefix := range prefixes {
+		if len(prefix) != 5 {
+			t.Errorf("Expected 5-character hash prefixes, got %q", prefix)
+		}
+	}
+
+	// Lookups that fail accept the password
+	rangeAPI.Close()
+	if err := policy.Check(ctx, "password", "Password1!"); err != nil {
+		t.Errorf("Expected the password to be accepted while the range API is down, got %v", err)
+	}
+
+	// Registration answers with the field errors
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService,
+		service.WithPasswordPolicy(passwordpolicy.New(config.PasswordPolicyConfig{MinLength: 10, RequireDigit: true})))
+	server := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal()).SetupRoutes()
+
+	body, _ := json.Marshal(map[string]string{"name": "Test User", "email": "test@example.com", "password": "password"})
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body)))
+	var resp struct {
+		Error struct {
+			Code    string              `json:"code"`
+			Details []domain.FieldError `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusBadRequest || resp.Error.Code != "VALIDATION_FAILED" || len(resp.Error.Details) != 2 || resp.Error.Details[1].Rule != passwordpolicy.RuleDigit {
+		t.Errorf("Expected the broken password rules, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,130 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// capturingEmailSender hands every sent email to the test
+type capturingEmailSender struct {
+	sent chan *service.EmailMessage
+}
+
+func (s *capturingEmailSender) Send(ctx context.Context, message *service.EmailMessage) error {
+	s.sent <- message
+	return nil
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	sender := &capturingEmailSender{sent: make(chan *service.EmailMessage, 10)}
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+	resetService := service.NewPasswordResetService(userRepo, repository.NewMemoryResetTokenStore(), sender, ratelimit.NewMemoryLimiter(), nil, nil, revocations, config.PasswordResetConfig{
+		ResetURL:      "https://app.example.com/reset?token={token}",
+		TokenTTL:      time.Minute,
+		MaxAttempts:   3,
+		AttemptWindow: time.Minute,
+	})
+
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.SetPasswordResetHandler(handler.NewPasswordResetHandler(resetService))
+	server := router.SetupRoutes()
+
+	if _, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Reset Tester", Email: "reset@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	send := func(path string, body interface{}) int {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	requestToken := func() string {
+		if status := send("/auth/forgot-password", domain.ForgotPasswordRequest{Email: "reset@example.com"}); status != http.StatusAccepted {
+			t.Fatalf("Expected 202 from forgot-password, got %d", status)
+		}
+		select {
+		case message := <-sender.sent:
+			_, token, found := strings.Cut(message.Body, "https://app.example.com/reset?token=")
+			if !found || message.To != "reset@example.com" {
+				t.Fatalf("Unexpected reset email %+v", message)
+			}
+			return strings.Fields(token)[0]
+		case <-time.After(time.Second):
+			t.Fatal("Expected a reset email")
+			return ""
+		}
+	}
+
+	// Unknown emails get the same answer and no email
+	if status := send("/auth/forgot-password", domain.ForgotPasswordRequest{Email: "nobody@example.com"}); status != http.StatusAccepted {
+		t.Errorf("Expected 202 for an unknown email, got %d", status)
+	}
+	select {
+	case message := <-sender.sent:
+		t.Errorf("Expected no email for an unknown address, got %+v", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Tokens work once, and resetting signs out what the old password signed in
+	accessToken, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "reset@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	profile := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if status := profile(); status != http.StatusOK {
+		t.Fatalf("Expected the token to work before the reset, got %d", status)
+	}
+	stale, token := requestToken(), requestToken()
+	if status := send("/auth/reset-password", domain.ResetPasswordRequest{Token: token, NewPassword: "newpassword1"}); status != http.StatusOK {
+		t.Fatalf("Expected the reset to succeed, got %d", status)
+	}
+	if status := profile(); status != http.StatusUnauthorized {
+		t.Errorf("Expected tokens issued before the reset to be revoked, got %d", status)
+	}
+	if status := send("/auth/reset-password", domain.ResetPasswordRequest{Token: token, NewPassword: "newpassword2"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected a used token to be refused, got %d", status)
+	}
+
+	// Tokens issued before the password changed no longer work
+	if status := send("/auth/reset-password", domain.ResetPasswordRequest{Token: stale, NewPassword: "newpassword3"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected a token issued before the password change to be refused, got %d", status)
+	}
+	if _, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "reset@example.com", Password: "newpassword1"}); err != nil {
+		t.Errorf("Expected login with the new password, got %v", err)
+	}
+
+	// Requests are rate limited per email and per client IP
+	if status := send("/auth/forgot-password", domain.ForgotPasswordRequest{Email: "reset@example.com"}); status != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 once the requests are used up, got %d", status)
+	}
+}
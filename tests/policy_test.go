@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/policy"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestPolicyEngine(t *testing.T) {
+	if _, err := policy.NewMatrix(map[string][]string{"admin": {"user.**"}}); err == nil {
+		t.Error("Expected malformed wildcards to be rejected")
+	}
+	engine, err := policy.NewMatrix(map[string][]string{
+		"admin":     {policy.Wildcard},
+		"moderator": {"user.list", "user.read", "route.read", "reports.*"},
+	})
+	if err != nil {
+		t.Fatalf("NewMatrix failed: %v", err)
+	}
+
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	repo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(repo, tokenService)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetPolicyEngine(engine)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Policy Routes", routes.NewPolicyRoutes(handler.NewPolicyHandler(engine, userService)))
+	server := router.SetupRoutes()
+
+	moderator := &domain.User{Name: "Moderator", Email: "mod@example.com", Role: "moderator"}
+	_ = repo.Create(context.Background(), moderator)
+	moderatorToken, _ := tokenService.GenerateToken(moderator)
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Routes check the permission, not the role
+	if rec := do(http.MethodGet, "/api/v1/admin/users", moderatorToken); rec.Code != http.StatusOK {
+		t.Errorf("Expected the moderator to list users, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/admin/users/"+moderator.ID, moderatorToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the moderator to be refused deletes, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/permissions", moderatorToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the moderator to be refused the policy, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/routes", moderatorToken); rec.Code != http.StatusOK {
+		t.Errorf("Expected route.read to list the routes without the admin role, got %d", rec.Code)
+	}
+
+	// Admins can inspect the policy and a user's effective permissions
+	rec := do(http.MethodGet, "/api/v1/admin/permissions", adminToken)
+	var overview struct {
+		Data domain.PolicyOverview `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &overview)
+	if rec.Code != http.StatusOK || len(overview.Data.Roles["admin"]) != len(domain.Permissions) || overview.Data.Grants["admin"][0] != policy.Wildcard {
+		t.Errorf("Expected the policy overview, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/api/v1/admin/users/"+moderator.ID+"/permissions", adminToken)
+	var effective struct {
+		Data domain.EffectivePermissions `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &effective)
+	if rec.Code != http.StatusOK || effective.Data.Role != "moderator" || len(effective.Data.Permissions) != 3 {
+		t.Errorf("Expected the moderator's permissions, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !engine.Allows("moderator", "reports.export") || engine.Allows("moderator", "reportsx.export") {
+		t.Error("Expected area wildcards to cover exactly their area")
+	}
+
+	// Route listings show who may call each route under the policy
+	for _, info := range router.GetAllRouteInfo() {
+		if info.Method == "GET" && info.Path == "/api/v1/admin/users" && (len(info.Roles) != 2 || info.AdminOnly || info.Permission != domain.PermissionUserList) {
+			t.Errorf("Unexpected route info %+v", info)
+		}
+	}
+}
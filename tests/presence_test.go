@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/assembler"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestPresenceTracking(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	prefsRepo := repository.NewMemoryPreferencesRepository()
+	store := repository.NewMemoryPresenceStore()
+	presence := service.NewPresenceService(store, prefsRepo, config.PresenceConfig{
+		OnlineWindow: 5 * time.Minute, RecentWindow: time.Hour, Debounce: time.Minute,
+	})
+
+	responses := assembler.New()
+	responses.Register(assembler.NewPresenceSource(presence), 0)
+	userService := service.NewAssembledUserService(service.NewUserService(userRepo, tokenService), responses)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetPresenceService(presence)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	register := func(email string) *domain.UserResponse {
+		user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Presence Tester", Email: email, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		return user
+	}
+	active, hidden, idle, never := register("active@example.com"), register("hidden@example.com"), register("idle@example.com"), register("never@example.com")
+	_ = prefsRepo.Upsert(ctx, &domain.UserPreferences{UserID: hidden.ID, HidePresence: true})
+	_ = store.Record(ctx, idle.ID, time.Now().Add(-30*time.Minute))
+
+	get := func(path string, user *domain.UserResponse, role string) *httptest.ResponseRecorder {
+		token, _ := tokenService.GenerateToken(&domain.User{ID: user.ID, Email: user.Email, Role: role})
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	for _, user := range []*domain.UserResponse{active, hidden} {
+		if rec := get("/api/v1/profile", user, "user"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected profile request to succeed, got %d", rec.Code)
+		}
+	}
+
+	rec := get("/api/v1/admin/users?limit=10", &domain.UserResponse{ID: "admin-1", Email: "admin@example.com"}, "admin")
+	var envelope struct {
+		Data struct {
+			Users []domain.UserResponse `json:"users"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected admin listing to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	statuses := make(map[string]string)
+	for _, user := range envelope.Data.Users {
+		if user.Presence != nil {
+			statuses[user.ID] = user.Presence.Status
+		}
+	}
+
+	if statuses[active.ID] != domain.PresenceOnline {
+		t.Errorf("Expected the active user to be online, got %q", statuses[active.ID])
+	}
+	if statuses[idle.ID] != domain.PresenceRecentlyActive {
+		t.Errorf("Expected the idle user to be recently active, got %q", statuses[idle.ID])
+	}
+	if _, shown := statuses[hidden.ID]; shown {
+		t.Error("Expected no presence for a user who opted out")
+	}
+	if _, shown := statuses[never.ID]; shown {
+		t.Error("Expected no presence for a user never seen")
+	}
+
+	// Activity within the debounce interval is not written again
+	if fresh, _ := store.Claim(ctx, active.ID, time.Minute); fresh {
+		t.Error("Expected the debounce claim to still be held")
+	}
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestPublicProfileDiscovery(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	prefsRepo := repository.NewMemoryPreferencesRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	profiles := service.NewPublicProfileService(userRepo, prefsRepo, config.PublicProfileConfig{
+		BaseURL: "https://api.example.com",
+	}, "https://cdn.example.com/avatars/{id}.png")
+
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Public Profile Routes", routes.NewPublicProfileRoutes(handler.NewPublicProfileHandler(profiles)))
+	server := router.SetupRoutes()
+
+	public, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Public User", Email: "public@example.com", Password: "password123"})
+	private, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Private User", Email: "private@example.com", Password: "password123"})
+	optIn := true
+	if _, err := service.NewPreferencesService(prefsRepo).UpdatePreferences(ctx, public.ID, &domain.UpdatePreferencesRequest{PublicProfile: &optIn}); err != nil {
+		t.Fatalf("UpdatePreferences failed: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	webfinger := func(resource string) *httptest.ResponseRecorder {
+		return get("/.well-known/webfinger?resource=" + url.QueryEscape(resource))
+	}
+
+	// Opted-in users are visible without a token
+	rec := get("/api/v1/public/profiles/" + public.ID)
+	var envelope struct {
+		Data domain.PublicProfile `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+	if rec.Code != http.StatusOK || envelope.Data.Name != "Public User" || envelope.Data.Handle != public.ID+"@api.example.com" {
+		t.Fatalf("Unexpected public profile %d %+v", rec.Code, envelope.Data)
+	}
+	if envelope.Data.AvatarURL != "https://cdn.example.com/avatars/"+public.ID+".png" {
+		t.Errorf("Unexpected avatar URL %q", envelope.Data.AvatarURL)
+	}
+
+	// WebFinger resolves the handle and the email to the same descriptor
+	for _, resource := range []string{"acct:" + envelope.Data.Handle, "acct:public@example.com"} {
+		rec := webfinger(resource)
+		var jrd domain.WebFingerResource
+		_ = json.Unmarshal(rec.Body.Bytes(), &jrd)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/jrd+json" {
+			t.Fatalf("Expected a JRD for %s, got %d %s", resource, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if jrd.Subject != "acct:"+envelope.Data.Handle || len(jrd.Links) != 2 || jrd.Links[0].Href != envelope.Data.ProfileURL {
+			t.Errorf("Unexpected JRD for %s: %+v", resource, jrd)
+		}
+	}
+	rec = get("/.well-known/webfinger?resource=" + url.QueryEscape("acct:public@example.com") + "&rel=" + url.QueryEscape(domain.WebFingerRelAvatar))
+	var filtered domain.WebFingerResource
+	_ = json.Unmarshal(rec.Body.Bytes(), &filtered)
+	if len(filtered.Links) != 1 || filtered.Links[0].Rel != domain.WebFingerRelAvatar {
+		t.Errorf("Expected only the avatar link, got %+v", filtered.Links)
+	}
+
+	// Users who did not opt in look the same as unknown users
+	for _, rec := range []*httptest.ResponseRecorder{
+		get("/api/v1/public/profiles/" + private.ID),
+		webfinger("acct:private@example.com"),
+		webfinger("acct:nobody@example.com"),
+	} {
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", rec.Code)
+		}
+	}
+	for _, resource := range []string{"", "https://example.com/public", "acct:missing-at-sign"} {
+		if rec := webfinger(resource); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for resource %q, got %d", resource, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"demo-go/internal/domain"
+)
+
+func TestQueryBinder(t *testing.T) {
+	tests := []struct {
+		name          string
+		rawQuery      string
+		expectLimit   int
+		expectOffset  int
+		expectInvalid []string
+	}{
+		{name: "defaults", rawQuery: "", expectLimit: 10},
+		{name: "valid values", rawQuery: "limit=25&offset=50", expectLimit: 25, expectOffset: 50},
+		{name: "non-numeric", rawQuery: "limit=ten&offset=x", expectLimit: 10, expectInvalid: []string{"limit", "offset"}},
+		{name: "below range", rawQuery: "limit=0&offset=-1", expectLimit: 10, expectInvalid: []string{"limit", "offset"}},
+		{name: "above range", rawQuery: "limit=5000", expectLimit: 10, expectInvalid: []string{"limit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.rawQuery)
+			params := domain.NewQueryBinder(values)
+			limit, offset := params.Limit(), params.Offset()
+			if limit != tt.expectLimit || offset != tt.expectOffset {
+				t.Errorf("Expected limit %d and offset %d, got %d and %d", tt.expectLimit, tt.expectOffset, limit, offset)
+			}
+
+			err := params.Err()
+			if len(tt.expectInvalid) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var paramsErr *domain.InvalidParamsError
+			if !errors.As(err, &paramsErr) || len(paramsErr.Params) != len(tt.expectInvalid) {
+				t.Fatalf("Expected %v to be invalid, got %v", tt.expectInvalid, err)
+			}
+			for i, name := range tt.expectInvalid {
+				if paramsErr.Params[i].Name != name {
+					t.Errorf("Expected %s to be invalid, got %s", name, paramsErr.Params[i].Name)
+				}
+			}
+			if !errors.Is(err, domain.ErrValidationFailed) {
+				t.Error("Expected the error to unwrap to ErrValidationFailed")
+			}
+		})
+	}
+}
+
+func TestParseUserQueryReportsEveryInvalidParam(t *testing.T) {
+	values, _ := url.ParseQuery("limit=-5&include_total=maybe&sort=password&fields=secret")
+	_, err := domain.ParseUserQuery(values)
+
+	var paramsErr *domain.InvalidParamsError
+	if !errors.As(err, &paramsErr) {
+		t.Fatalf("Expected InvalidParamsError, got %v", err)
+	}
+	invalid := make(map[string]bool)
+	for _, param := range paramsErr.Params {
+		invalid[param.Name] = true
+	}
+	for _, name := range []string{"limit", "include_total", "sort", "fields"} {
+		if !invalid[name] {
+			t.Errorf("Expected %s to be reported, got %v", name, paramsErr.Params)
+		}
+	}
+}
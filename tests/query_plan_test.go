@@ -0,0 +1,45 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"demo-go/internal/queryplan"
+	"demo-go/internal/response"
+)
+
+func TestQueryPlansInResponseMeta(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+
+	// Without a collector the meta carries no plans
+	rec := httptest.NewRecorder()
+	response.Success(rec, req, http.StatusOK, "ok", nil)
+	var plain struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &plain); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if _, ok := plain.Meta["query_plans"]; ok {
+		t.Error("Expected no query plans without an explain request")
+	}
+
+	req = req.WithContext(queryplan.NewContext(req.Context()))
+	queryplan.FromContext(req.Context()).Add(queryplan.Plan{
+		Collection: "users", Operation: "search", Stages: "LIMIT > FETCH > IXSCAN", Index: "email_1",
+	})
+
+	rec = httptest.NewRecorder()
+	response.Success(rec, req, http.StatusOK, "ok", nil)
+	var explained struct {
+		Meta response.Meta `json:"meta"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &explained); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(explained.Meta.QueryPlans) != 1 || explained.Meta.QueryPlans[0].Index != "email_1" {
+		t.Errorf("Expected the collected plan in the meta, got %+v", explained.Meta.QueryPlans)
+	}
+}
@@ -0,0 +1,43 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+	redis := &slidingWindowCache{events: make(map[string][]time.Time)}
+
+	// Without Redis, auto and memory still limit
+	for _, store := range []string{config.RateLimitStoreAuto, config.RateLimitStoreMemory} {
+		limiter, err := cache.NewLimiter(store, nil)
+		if err != nil {
+			t.Fatalf("Expected the %s store without Redis to work, got %v", store, err)
+		}
+		_, _ = limiter.Allow(ctx, "k", 1, time.Minute)
+		if result, _ := limiter.Allow(ctx, "k", 1, time.Minute); result.Allowed {
+			t.Errorf("Expected the %s store to limit without Redis", store)
+		}
+	}
+
+	// Redis is used when available, unless memory is asked for
+	limiter, _ := cache.NewLimiter(config.RateLimitStoreAuto, redis)
+	_, _ = limiter.Allow(ctx, "auto", 1, time.Minute)
+	limiter, _ = cache.NewLimiter(config.RateLimitStoreMemory, redis)
+	_, _ = limiter.Allow(ctx, "memory", 1, time.Minute)
+	if len(redis.events["ratelimit:auto"]) != 1 || len(redis.events["ratelimit:memory"]) != 0 {
+		t.Errorf("Expected only the auto store to count in Redis, got %v", redis.events)
+	}
+
+	if _, err := cache.NewLimiter(config.RateLimitStoreRedis, nil); err == nil {
+		t.Error("Expected the redis store to refuse a missing Redis")
+	}
+	if _, err := cache.NewLimiter("disk", redis); err == nil {
+		t.Error("Expected unknown stores to be refused")
+	}
+}
@@ -0,0 +1,186 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// recordingSecurityEvents keeps recorded security events for inspection
+type recordingSecurityEvents struct {
+	events []*domain.SecurityEvent
+}
+
+func (r *recordingSecurityEvents) Record(ctx context.Context, event *domain.SecurityEvent) {
+	r.events = append(r.events, event)
+}
+
+func (r *recordingSecurityEvents) ListEvents(
+	ctx context.Context, filter domain.SecurityEventFilter, limit, offset int,
+) ([]*domain.SecurityEvent, int64, error) {
+	return r.events, int64(len(r.events)), nil
+}
+
+func TestRefreshTokenRotationAndReuse(t *testing.T) {
+	tokenService := service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour)
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	events := &recordingSecurityEvents{}
+
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetRefreshTokenService(service.NewRefreshTokenService(
+		repository.NewMemoryRefreshTokenStore(), userService, events, nil, time.Hour,
+	))
+	router := routes.NewRouter(userHandler, middleware.NewJWTMiddleware(tokenService), logger.GetGlobal()).SetupRoutes()
+
+	if _, err := userService.Register(context.Background(), &domain.CreateUserRequest{
+		Name: "Refresh Tester", Email: "refresh@example.com", Password: "password123",
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	post := func(path string, body interface{}) (int, domain.TokenPair) {
+		payload, _ := json.Marshal(body)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload)))
+
+		var envelope struct {
+			Data domain.TokenPair `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Data
+	}
+	refresh := func(token string) (int, domain.TokenPair) {
+		return post("/auth/refresh", domain.RefreshRequest{RefreshToken: token})
+	}
+
+	status, login := post("/auth/login", domain.LoginRequest{Email: "refresh@example.com", Password: "password123"})
+	if status != http.StatusOK || login.AccessToken == "" || login.RefreshToken == "" {
+		t.Fatalf("Expected login to return a token pair, got %d %+v", status, login)
+	}
+
+	// Refreshing needs no access token and rotates the refresh token
+	status, rotated := refresh(login.RefreshToken)
+	if status != http.StatusOK || rotated.RefreshToken == "" || rotated.RefreshToken == login.RefreshToken {
+		t.Fatalf("Expected a rotated token pair, got %d %+v", status, rotated)
+	}
+	if _, err := tokenService.ValidateToken(rotated.AccessToken); err != nil {
+		t.Errorf("Expected a valid access token after refresh: %v", err)
+	}
+
+	// Replaying the spent token is rejected and revokes the family
+	if status, _ := refresh(login.RefreshToken); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 when reusing a refresh token, got %d", status)
+	}
+	if status, _ := refresh(rotated.RefreshToken); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for the latest token of a revoked family, got %d", status)
+	}
+	if len(events.events) != 1 || events.events[0].Type != domain.SecurityEventRefreshTokenReused {
+		t.Errorf("Expected one refresh token reuse security event, got %+v", events.events)
+	}
+
+	// A new login starts a fresh family
+	_, relogin := post("/auth/login", domain.LoginRequest{Email: "refresh@example.com", Password: "password123"})
+	if status, _ := refresh(relogin.RefreshToken); status != http.StatusOK {
+		t.Errorf("Expected refresh after a new login to succeed, got %d", status)
+	}
+}
+
+func TestRefreshTokenRememberMe(t *testing.T) {
+	tokenService := service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour)
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetRefreshTokenService(service.NewRefreshTokenService(
+		repository.NewMemoryRefreshTokenStore(), userService, nil, nil, time.Hour,
+		service.WithRememberMe(30*24*time.Hour),
+	))
+	router := routes.NewRouter(userHandler, middleware.NewJWTMiddleware(tokenService), logger.GetGlobal()).SetupRoutes()
+
+	if _, err := userService.Register(context.Background(), &domain.CreateUserRequest{
+		Name: "Remember Tester", Email: "remember@example.com", Password: "password123",
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	post := func(path string, body interface{}) domain.TokenPair {
+		payload, _ := json.Marshal(body)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload)))
+		var envelope struct {
+			Data domain.TokenPair `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected %s to succeed, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		return envelope.Data
+	}
+
+	short := post("/auth/login", domain.LoginRequest{Email: "remember@example.com", Password: "password123"})
+	if time.Until(short.RefreshExpiresAt) > 2*time.Hour {
+		t.Errorf("Expected a regular refresh token to last an hour, expires at %v", short.RefreshExpiresAt)
+	}
+
+	long := post("/auth/login", domain.LoginRequest{Email: "remember@example.com", Password: "password123", RememberMe: true})
+	if time.Until(long.RefreshExpiresAt) < 29*24*time.Hour {
+		t.Fatalf("Expected a remember-me refresh token to last 30 days, expires at %v", long.RefreshExpiresAt)
+	}
+
+	// Rotation keeps the long lifetime; the access token lifetime is unchanged
+	rotated := post("/auth/refresh", domain.RefreshRequest{RefreshToken: long.RefreshToken})
+	if time.Until(rotated.RefreshExpiresAt) < 29*24*time.Hour {
+		t.Errorf("Expected the rotated token to stay long-lived, expires at %v", rotated.RefreshExpiresAt)
+	}
+	claims, err := tokenService.ValidateToken(rotated.AccessToken)
+	if err != nil || time.Until(time.Unix(claims.Exp, 0)) > 2*time.Hour {
+		t.Errorf("Expected a short-lived access token, got %+v %v", claims, err)
+	}
+}
+
+func TestRefreshTokenRotateRetry(t *testing.T) {
+	ctx := context.Background()
+	failures := 1
+	commands := &mockUserService{
+		refreshTokenFunc: func(ctx context.Context, userID string) (string, error) {
+			if failures > 0 {
+				failures--
+				return "", domain.ErrStorageUnavailable
+			}
+			return "access-" + userID, nil
+		},
+	}
+	refreshTokens := service.NewRefreshTokenService(repository.NewMemoryRefreshTokenStore(), commands, nil, nil, time.Hour)
+	token, _, err := refreshTokens.Issue(ctx, "user-1", "access-user-1", false, domain.SessionClient{})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	// A rotation failing before the new pair is minted leaves the token usable
+	if _, err := refreshTokens.Rotate(ctx, token, domain.SessionClient{}); err != domain.ErrStorageUnavailable {
+		t.Fatalf("Expected the first rotation to fail, got %v", err)
+	}
+	pair, err := refreshTokens.Rotate(ctx, token, domain.SessionClient{})
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if _, err := refreshTokens.Rotate(ctx, pair.RefreshToken, domain.SessionClient{}); err != nil {
+		t.Errorf("Expected the retry not to be taken for reuse, got %v", err)
+	}
+
+	// A token rotated once is still refused the second time
+	if _, err := refreshTokens.Rotate(ctx, token, domain.SessionClient{}); err != domain.ErrInvalidToken {
+		t.Errorf("Expected a reused token to be refused, got %v", err)
+	}
+}
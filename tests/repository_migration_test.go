@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+)
+
+func TestRepositoryMigration(t *testing.T) {
+	ctx := context.Background()
+	primary := repository.NewMemoryUserRepository()
+	target := repository.NewMemoryUserRepository()
+	migrating := repository.NewMigratingUserRepository(primary, target, config.RepositoryMigrationConfig{ShadowReads: true, Timeout: time.Second})
+	waitFor := func(detail string, want int64) map[string]interface{} {
+		deadline := time.Now().Add(time.Second)
+		for {
+			details := migrating.CheckHealth(ctx).Details
+			if details[detail] == want || time.Now().After(deadline) {
+				return details
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Writes reach both stores with the primary's IDs
+	user := &domain.User{Name: "Mira", Email: "mira@example.com", Password: "hash", Role: "user"}
+	if err := migrating.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if copied, err := target.GetByID(ctx, user.ID); err != nil || copied.Email != user.Email {
+		t.Fatalf("Expected the user to be mirrored with ID %s, got %v", user.ID, err)
+	}
+
+	// Matching reads are compared without divergence
+	if _, err := migrating.GetByID(ctx, user.ID); err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if details := waitFor("compared_reads", 1); details["mismatched_reads"] != int64(0) {
+		t.Errorf("Expected a matching shadow read, got %v", details)
+	}
+
+	// Users created before the migration are copied on their next update
+	existing := &domain.User{ID: "legacy", Name: "Legacy", Email: "legacy@example.com", Role: "user"}
+	_ = primary.Create(ctx, existing)
+	if _, err := migrating.GetByEmail(ctx, existing.Email); err != nil {
+		t.Fatalf("GetByEmail failed: %v", err)
+	}
+	if details := waitFor("missing_in_target", 1); details["mismatched_reads"] != int64(1) {
+		t.Errorf("Expected the missing user to be reported, got %v", details)
+	}
+	existing.Name = "Legacy Renamed"
+	if err := migrating.Update(ctx, existing.ID, existing); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if copied, err := target.GetByID(ctx, existing.ID); err != nil || copied.Name != "Legacy Renamed" {
+		t.Errorf("Expected the user to be backfilled, got %v", err)
+	}
+
+	// Divergent data is reported by field
+	drifted := *user
+	drifted.Role = "admin"
+	_ = target.Update(ctx, user.ID, &drifted)
+	_, _ = migrating.GetByID(ctx, user.ID)
+	details := waitFor("mismatched_reads", 2)
+	if details["mismatched_reads"] != int64(2) || migrating.CheckHealth(ctx).Status != domain.HealthStatusDegraded {
+		t.Errorf("Expected the divergence to degrade the migration check, got %v", details)
+	}
+
+	// Deletes reach both stores, and the primary's answer is what callers see
+	if err := migrating.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := target.GetByID(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected the delete to be mirrored, got %v", err)
+	}
+	if err := migrating.Delete(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected the primary's error, got %v", err)
+	}
+	if details := waitFor("failed_writes", 0); details["mirrored_writes"] != int64(3) {
+		t.Errorf("Expected three mirrored writes, got %v", details)
+	}
+}
@@ -0,0 +1,42 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/budget"
+	"demo-go/internal/domain"
+)
+
+func TestRequestBudget(t *testing.T) {
+	// Without a request deadline the configured timeout applies
+	ctx, cancel, err := budget.WithTimeout(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if remaining, ok := budget.Remaining(ctx); !ok || remaining > time.Second {
+		t.Errorf("Expected deadline within the configured timeout, got %v (%v)", remaining, ok)
+	}
+	cancel()
+
+	// A shorter request deadline wins over the configured timeout
+	request, cancelRequest := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelRequest()
+	ctx, cancel, err = budget.WithTimeout(request, 10*time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if remaining, _ := budget.Remaining(ctx); remaining > 100*time.Millisecond {
+		t.Errorf("Expected operation bounded by the request budget, got %v", remaining)
+	}
+	cancel()
+
+	// A spent budget fails fast
+	spent, cancelSpent := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelSpent()
+	time.Sleep(2 * time.Millisecond)
+	if _, _, err := budget.WithTimeout(spent, time.Second); err != domain.ErrRequestTimeout {
+		t.Errorf("Expected ErrRequestTimeout for a spent budget, got %v", err)
+	}
+}
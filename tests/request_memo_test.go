@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/memo"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+// countingUserRepository counts lookups by ID
+type countingUserRepository struct {
+	domain.UserRepository
+	lookups int
+}
+
+func (r *countingUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	r.lookups++
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func TestRequestMemo(t *testing.T) {
+	repo := &countingUserRepository{UserRepository: repository.NewMemoryUserRepository()}
+	inner := service.NewUserService(repo, service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour))
+	userService := service.ComposeUserService(
+		service.NewMemoizedUserQueries(inner),
+		service.NewMemoResettingUserCommands(inner),
+	)
+
+	user, err := userService.Register(context.Background(), &domain.CreateUserRequest{
+		Name: "Memo User", Email: "memo@example.com", Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	ctx := memo.NewContext(context.Background())
+	repo.lookups = 0
+	for i := 0; i < 3; i++ {
+		if _, err := userService.GetUserByID(ctx, user.ID); err != nil {
+			t.Fatalf("GetUserByID failed: %v", err)
+		}
+	}
+	if repo.lookups != 1 {
+		t.Errorf("Expected one lookup within a request, got %d", repo.lookups)
+	}
+
+	// A write drops memoized users so the change is visible in the same request
+	name := "Renamed User"
+	if _, err := userService.UpdateProfile(ctx, user.ID, &domain.UpdateUserRequest{Name: &name}); err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if got, _ := userService.GetUserByID(ctx, user.ID); got.Name != name {
+		t.Errorf("Expected updated name after write, got %q", got.Name)
+	}
+
+	// Without a request memo every call reaches the repository
+	repo.lookups = 0
+	_, _ = userService.GetUserByID(context.Background(), user.ID)
+	_, _ = userService.GetUserByID(context.Background(), user.ID)
+	if repo.lookups != 2 {
+		t.Errorf("Expected lookups outside a request to pass through, got %d", repo.lookups)
+	}
+}
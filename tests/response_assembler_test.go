@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"demo-go/internal/assembler"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+)
+
+// stubSource is a response source with a fixed delay and outcome
+type stubSource struct {
+	field string
+	delay time.Duration
+	err   error
+}
+
+func (s *stubSource) Field() string { return s.field }
+
+func (s *stubSource) Fetch(ctx context.Context, _ []*domain.UserResponse) (assembler.ApplyFunc, error) {
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return func(user *domain.UserResponse) { user.AvatarURL = "slow" }, nil
+}
+
+func TestResponseAssembler(t *testing.T) {
+	prefsRepo := repository.NewMemoryPreferencesRepository()
+	if err := prefsRepo.Upsert(context.Background(), &domain.UserPreferences{UserID: "1", Theme: domain.ThemeDark}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	t.Run("enriches from all sources", func(t *testing.T) {
+		a := assembler.New()
+		a.Register(assembler.NewPreferencesSource(prefsRepo), 0)
+		a.Register(assembler.NewAvatarSource("https://cdn.example.com/avatars/{id}.png"), 0)
+
+		users := []*domain.UserResponse{{ID: "1"}, {ID: "2"}}
+		a.Assemble(context.Background(), users)
+
+		if users[0].AvatarURL != "https://cdn.example.com/avatars/1.png" {
+			t.Errorf("Unexpected avatar URL %q", users[0].AvatarURL)
+		}
+		if users[0].Preferences == nil || users[0].Preferences.Theme != domain.ThemeDark {
+			t.Errorf("Expected saved preferences, got %+v", users[0].Preferences)
+		}
+		if users[1].Preferences != nil {
+			t.Errorf("Expected no preferences for user without saved ones, got %+v", users[1].Preferences)
+		}
+	})
+
+	t.Run("slow and failing sources are skipped", func(t *testing.T) {
+		a := assembler.New()
+		a.Register(assembler.NewPreferencesSource(prefsRepo), 0)
+		a.Register(&stubSource{field: "avatar_url", delay: time.Second}, 20*time.Millisecond)
+		a.Register(&stubSource{field: "avatar_url", err: errors.New("storage down")}, 0)
+
+		users := []*domain.UserResponse{{ID: "1"}}
+		start := time.Now()
+		a.Assemble(context.Background(), users)
+
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected slow source to time out, assembly took %v", elapsed)
+		}
+		if users[0].AvatarURL != "" {
+			t.Errorf("Expected avatar URL to be left empty, got %q", users[0].AvatarURL)
+		}
+		if users[0].Preferences == nil {
+			t.Error("Expected preferences despite other sources failing")
+		}
+	})
+
+	t.Run("sparse fieldset skips unselected sources", func(t *testing.T) {
+		a := assembler.New()
+		a.Register(assembler.NewPreferencesSource(prefsRepo), 0)
+		a.Register(assembler.NewAvatarSource("/avatars/{id}"), 0)
+
+		users := []*domain.UserResponse{{ID: "1"}}
+		a.Assemble(context.Background(), users, "id", "avatar_url")
+
+		if users[0].Preferences != nil {
+			t.Error("Expected preferences source to be skipped")
+		}
+		if users[0].AvatarURL != "/avatars/1" {
+			t.Errorf("Unexpected avatar URL %q", users[0].AvatarURL)
+		}
+	})
+}
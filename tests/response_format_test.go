@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"demo-go/internal/response"
+)
+
+func TestResponseFormat(t *testing.T) {
+	write := func(headers map[string]string, fail bool) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		if fail {
+			response.ErrorWithDetails(rec, req, http.StatusBadRequest, "Invalid query parameters", "VALIDATION_FAILED",
+				[]map[string]string{{"param_name": "limit"}})
+		} else {
+			response.Success(rec, req, http.StatusOK, "ok", map[string]interface{}{"user_id": "1", "created_at": "now"})
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Expected a JSON body, got %q", rec.Body.String())
+		}
+		return body
+	}
+
+	// The default is the snake_case envelope
+	body := write(nil, false)
+	if data, ok := body["data"].(map[string]interface{}); !ok || data["user_id"] != "1" || body["meta"] == nil {
+		t.Fatalf("Expected the standard envelope, got %v", body)
+	}
+
+	// Clients can ask for camelCase, without the envelope
+	body = write(map[string]string{response.NamingHeader: "camel"}, false)
+	meta, _ := body["meta"].(map[string]interface{})
+	if data, _ := body["data"].(map[string]interface{}); data["userId"] != "1" || data["createdAt"] != "now" || meta["apiVersion"] == nil {
+		t.Errorf("Expected camelCase fields, got %v", body)
+	}
+	body = write(map[string]string{response.EnvelopeHeader: "bare"}, false)
+	if body["user_id"] != "1" || body["success"] != nil {
+		t.Errorf("Expected the bare data, got %v", body)
+	}
+	body = write(map[string]string{response.EnvelopeHeader: "bare", response.NamingHeader: "camel"}, true)
+	details, _ := body["details"].([]interface{})
+	if body["code"] != "VALIDATION_FAILED" || body["message"] != "Invalid query parameters" || len(details) != 1 ||
+		details[0].(map[string]interface{})["paramName"] != "limit" {
+		t.Errorf("Expected the bare camelCase error, got %v", body)
+	}
+
+	// The configured default applies, and headers are ignored when disabled
+	defer func(format response.Format, headers bool) {
+		response.DefaultFormat, response.FormatHeaders = format, headers
+	}(response.DefaultFormat, response.FormatHeaders)
+	response.DefaultFormat = response.Format{Naming: response.NamingCamel, Envelope: false}
+	response.FormatHeaders = false
+	body = write(map[string]string{response.EnvelopeHeader: "wrapped", response.NamingHeader: "snake"}, false)
+	if body["userId"] != "1" || body["data"] != nil {
+		t.Errorf("Expected the configured bare camelCase format, got %v", body)
+	}
+
+	if _, err := response.ParseNaming("kebab"); err == nil {
+		t.Error("Expected unknown namings to be rejected")
+	}
+}
@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/retention"
+)
+
+func TestRetentionPurge(t *testing.T) {
+	ctx := context.Background()
+	old := time.Now().Add(-48 * time.Hour)
+
+	users := repository.NewMemoryUserRepository()
+	for _, user := range []*domain.User{
+		{Name: "Gone Long Ago", Email: "gone@example.com", Status: domain.UserStatusDeleted, CreatedAt: old, UpdatedAt: old},
+		{Name: "Gone Recently", Email: "recent@example.com", Status: domain.UserStatusDeleted},
+		{Name: "Still Here", Email: "here@example.com", CreatedAt: old, UpdatedAt: old},
+	} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	audit := repository.NewMemoryAuditRepository()
+	if err := audit.CreateMany(ctx, []*domain.AuditEvent{
+		{Action: domain.AuditActionUserDeleted, ActorID: "1", CreatedAt: old},
+		{Action: domain.AuditActionUserDeleted, ActorID: "1"},
+	}); err != nil {
+		t.Fatalf("CreateMany failed: %v", err)
+	}
+
+	engine := retention.NewEngine(config.RetentionConfig{
+		AuditEvents:  24 * time.Hour,
+		DeletedUsers: 24 * time.Hour,
+		BatchSize:    1,
+	}, users, audit)
+
+	report, err := engine.DryRun(ctx)
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	for _, class := range report.Classes {
+		if class.Eligible != 1 || class.Deleted != 0 {
+			t.Errorf("Expected one eligible and nothing deleted for %s, got %+v", class.Class, class)
+		}
+	}
+	if count, _ := users.Count(ctx); count != 3 {
+		t.Fatalf("Expected dry run to keep every user, got %d", count)
+	}
+
+	report, err = engine.Purge(ctx)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	for _, class := range report.Classes {
+		if class.Deleted != 1 {
+			t.Errorf("Expected one record purged for %s, got %+v", class.Class, class)
+		}
+	}
+	if _, err := users.GetByEmail(ctx, "gone@example.com"); err != domain.ErrUserNotFound {
+		t.Errorf("Expected expired deleted user to be purged, got %v", err)
+	}
+	for _, email := range []string{"recent@example.com", "here@example.com"} {
+		if _, err := users.GetByEmail(ctx, email); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", email, err)
+		}
+	}
+	if count, _ := audit.Count(ctx, domain.AuditFilter{}); count != 1 {
+		t.Errorf("Expected only the recent audit event to remain, got %d", count)
+	}
+}
@@ -0,0 +1,58 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestRoleRecheck(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRoleCheckService(service.NewRoleCheckService(userRepo, 50*time.Millisecond))
+	server := routes.NewRouter(handler.NewUserHandler(service.NewUserService(userRepo, tokenService)), jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	admin := &domain.User{Name: "Demoted Admin", Email: "demoted@example.com", Role: "admin"}
+	_ = userRepo.Create(ctx, admin)
+	token, _ := tokenService.GenerateToken(admin)
+	listUsers := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if status := listUsers(); status != http.StatusOK {
+		t.Fatalf("Expected the admin token to list users, got %d", status)
+	}
+
+	// The demotion applies to the existing token once the cached role is stale
+	demoted, _ := userRepo.GetByID(ctx, admin.ID)
+	demoted.Role = "user"
+	_ = userRepo.Update(ctx, admin.ID, demoted)
+	time.Sleep(60 * time.Millisecond)
+	if status := listUsers(); status != http.StatusForbidden {
+		t.Errorf("Expected the demoted user's token to lose admin access, got %d", status)
+	}
+
+	// Tokens of deleted users stop working
+	_ = userRepo.Delete(ctx, admin.ID)
+	time.Sleep(60 * time.Millisecond)
+	if status := listUsers(); status != http.StatusUnauthorized {
+		t.Errorf("Expected the token of a deleted user to be refused, got %d", status)
+	}
+}
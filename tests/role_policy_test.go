@@ -0,0 +1,61 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestRolePolicy(t *testing.T) {
+	ctx := context.Background()
+	userService := service.NewUserService(
+		repository.NewMemoryUserRepository(),
+		service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour),
+		service.WithRolePolicy(config.RolesConfig{Default: "member", SelfAssignable: []string{"member", "viewer"}}),
+	)
+
+	register := func(email, role string) (*domain.UserResponse, error) {
+		return userService.Register(ctx, &domain.CreateUserRequest{
+			Name: "Role Tester", Email: email, Password: "password123", Role: role,
+		})
+	}
+
+	if _, err := register("admin@example.com", "admin"); err != domain.ErrRoleNotAllowed {
+		t.Fatalf("Expected ErrRoleNotAllowed when self-registering as admin, got %v", err)
+	}
+
+	user, err := register("member@example.com", "")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if user.Role != "member" {
+		t.Errorf("Expected default role member, got %s", user.Role)
+	}
+
+	viewer := "viewer"
+	if _, err := userService.UpdateProfile(ctx, user.ID, &domain.UpdateUserRequest{Role: &viewer}); err != nil {
+		t.Errorf("Expected self-assignable role change to succeed, got %v", err)
+	}
+
+	admin := "admin"
+	if _, err := userService.UpdateProfile(ctx, user.ID, &domain.UpdateUserRequest{Role: &admin}); err != domain.ErrRoleNotAllowed {
+		t.Errorf("Expected ErrRoleNotAllowed for profile role escalation, got %v", err)
+	}
+
+	if _, err := userService.UpdateUser(ctx, user.ID, user.ID, &domain.UpdateUserRequest{Role: &admin}); err != domain.ErrForbidden {
+		t.Errorf("Expected ErrForbidden when changing own role as admin, got %v", err)
+	}
+
+	updated, err := userService.UpdateUser(ctx, "admin-1", user.ID, &domain.UpdateUserRequest{Role: &admin})
+	if err != nil {
+		t.Fatalf("Admin role assignment failed: %v", err)
+	}
+	if updated.Role != "admin" {
+		t.Errorf("Expected role admin after admin update, got %s", updated.Role)
+	}
+}
@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// reportRoutes is a protected route that answers without needing a user
+type reportRoutes struct{}
+
+func (reportRoutes) Routes() []routes.Route {
+	return []routes.Route{{Method: "GET", Path: "/api/v1/reports/status", Description: "Status report",
+		Handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }}}
+}
+
+func TestRouteOverrides(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	overrideRepo := repository.NewMemoryRouteOverrideRepository()
+	overrides := service.NewRouteOverrideService(overrideRepo, nil, 50*time.Millisecond)
+	// Another instance sharing the repository
+	otherInstance := service.NewRouteOverrideService(overrideRepo, nil, 50*time.Millisecond)
+
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRouteOverrideService(overrides)
+	router := routes.NewRouter(handler.NewUserHandler(service.NewUserService(repository.NewMemoryUserRepository(), tokenService)),
+		jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Route Override Routes", routes.NewRouteOverrideRoutes(handler.NewRouteOverrideHandler(overrides, jwtMiddleware.SkipRules)))
+	router.AddRouteGroup("Report Routes", reportRoutes{})
+	server := router.SetupRoutes()
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+
+	send := func(method, path, token string, body interface{}) (int, string) {
+		var payload bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&payload).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &payload)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+	create := func(req domain.CreateRouteOverrideRequest) *domain.RouteOverride {
+		status, body := send(http.MethodPost, "/api/v1/admin/route-overrides", adminToken, req)
+		var created struct {
+			Data domain.RouteOverride `json:"data"`
+		}
+		_ = json.Unmarshal([]byte(body), &created)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected the override to be created, got %d: %s", status, body)
+		}
+		return &created.Data
+	}
+
+	// Closing self-registration refuses it right away on this instance
+	closed := create(domain.CreateRouteOverrideRequest{Rule: "post /auth/register", Action: "close", Reason: "Registration is paused"})
+	if closed.Rule != "POST /auth/register" {
+		t.Errorf("Expected the rule to be normalized, got %q", closed.Rule)
+	}
+	status, body := send(http.MethodPost, "/auth/register", "", map[string]string{"name": "New User", "email": "new@example.com", "password": "password123"})
+	if status != http.StatusForbidden || !strings.Contains(body, "ENDPOINT_CLOSED") || !strings.Contains(body, "Registration is paused") {
+		t.Errorf("Expected registration to be closed, got %d: %s", status, body)
+	}
+
+	// Opening a protected endpoint skips authentication
+	if status, _ := send(http.MethodGet, "/api/v1/reports/status", "", nil); status != http.StatusUnauthorized {
+		t.Fatalf("Expected the report to require a token, got %d", status)
+	}
+	opened := create(domain.CreateRouteOverrideRequest{Rule: "GET /api/v1/reports/*", Action: "open"})
+	if status, _ := send(http.MethodGet, "/api/v1/reports/status", "", nil); status != http.StatusOK {
+		t.Errorf("Expected the opened report to skip authentication, got %d", status)
+	}
+
+	// Listing shows the configured skip rules next to the overrides
+	status, body = send(http.MethodGet, "/api/v1/admin/route-overrides", adminToken, nil)
+	if status != http.StatusOK || !strings.Contains(body, `"/health"`) || !strings.Contains(body, opened.ID) || !strings.Contains(body, closed.ID) {
+		t.Errorf("Expected skip rules and overrides to be listed, got %d: %s", status, body)
+	}
+
+	// Other instances pick overrides up within the refresh interval
+	if _, ok := otherInstance.MatchRoute(http.MethodPost, "/auth/register"); ok {
+		t.Error("Expected another instance to apply overrides only after a reload")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if override, ok := otherInstance.MatchRoute(http.MethodPost, "/auth/register"); !ok || override.Action != domain.RouteOverrideClose {
+		t.Errorf("Expected another instance to apply the override after a reload, got %+v", override)
+	}
+
+	// Overrides cannot lock admins out of the overrides, and deleting reopens
+	if status, _ := send(http.MethodPost, "/api/v1/admin/route-overrides", adminToken,
+		domain.CreateRouteOverrideRequest{Rule: "/api/v1/admin/**", Action: "close"}); status != http.StatusBadRequest {
+		t.Errorf("Expected an override of the override routes to be refused, got %d", status)
+	}
+	if status, _ := send(http.MethodDelete, "/api/v1/admin/route-overrides/"+closed.ID, adminToken, nil); status != http.StatusOK {
+		t.Errorf("Expected the override to be deleted, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/auth/register", "", map[string]string{"name": "New User", "email": "new@example.com", "password": "password123"}); status != http.StatusCreated {
+		t.Errorf("Expected registration to reopen, got %d", status)
+	}
+
+	// Expired overrides stop applying
+	soon := time.Now().Add(50 * time.Millisecond)
+	create(domain.CreateRouteOverrideRequest{Rule: "/auth/login", Action: "close", ExpiresAt: &soon})
+	if _, ok := overrides.MatchRoute(http.MethodPost, "/auth/login"); !ok {
+		t.Error("Expected the override to apply before it expires")
+	}
+	time.Sleep(80 * time.Millisecond)
+	if _, ok := overrides.MatchRoute(http.MethodPost, "/auth/login"); ok {
+		t.Error("Expected the expired override to stop applying")
+	}
+	if list, _ := overrides.ListOverrides(context.Background()); len(list) != 1 {
+		t.Errorf("Expected only the open override to be listed, got %d", len(list))
+	}
+}
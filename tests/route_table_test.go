@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestRouteTable(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	profiles := service.NewPublicProfileService(userRepo, repository.NewMemoryPreferencesRepository(), config.PublicProfileConfig{}, "")
+	notes := service.NewNoteService(repository.NewMemoryNoteRepository(), userRepo)
+
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.SetVersion("2.3.4")
+	router.AddRouteGroup("Public Profile Routes", routes.NewPublicProfileRoutes(handler.NewPublicProfileHandler(profiles)))
+	router.AddRouteGroup("Note Routes", routes.NewNoteRoutes(handler.NewNoteHandler(notes), []string{"admin", "support"}))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	supportToken, _ := tokenService.GenerateToken(&domain.User{ID: "support-1", Email: "support@example.com", Role: "support"})
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Public routes skip authentication without registering skip rules by hand
+	if rec := get("/api/v1/public/profiles/missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a public route to be reachable without a token, got %d", rec.Code)
+	}
+	if rec := get("/api/v1/profile", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a protected route to require a token, got %d", rec.Code)
+	}
+
+	// Roles are enforced per route
+	if rec := get("/api/v1/admin/routes", userToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected non-admins to be refused the route listing, got %d", rec.Code)
+	}
+	if rec := get("/api/v1/admin/users/user-1/notes", supportToken); rec.Code == http.StatusForbidden {
+		t.Errorf("Expected a note role to reach the note routes")
+	}
+	if rec := get("/api/v1/admin/users", supportToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected a note role to be refused the admin routes, got %d", rec.Code)
+	}
+
+	// The route listing is built from the same table
+	rec := get("/api/v1/admin/routes", adminToken)
+	var listing struct {
+		Data struct {
+			Routes []routes.RouteInfo `json:"routes"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &listing)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the route listing, got %d", rec.Code)
+	}
+	byRoute := make(map[string]routes.RouteInfo)
+	for _, info := range listing.Data.Routes {
+		byRoute[info.Method+" "+info.Path] = info
+	}
+	if info := byRoute["POST /auth/login"]; info.Handler != "UserHandler.Login" || info.Protected {
+		t.Errorf("Unexpected login route %+v", info)
+	}
+	if info := byRoute["DELETE /api/v1/admin/users/{id}"]; !info.AdminOnly || !info.Protected {
+		t.Errorf("Unexpected admin route %+v", info)
+	}
+	if info := byRoute["PUT /api/v1/admin/users/{id}/notes/{noteId}"]; info.AdminOnly || len(info.Roles) != 2 || info.Group != "Note Routes" {
+		t.Errorf("Unexpected note route %+v", info)
+	}
+	if len(listing.Data.Routes) != len(router.GetAllRouteInfo()) {
+		t.Errorf("Expected the listing to match GetAllRouteInfo")
+	}
+
+	// So is the OpenAPI document
+	rec = get("/openapi.json", "")
+	var doc routes.OpenAPIDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected the OpenAPI document, got %d: %v", rec.Code, err)
+	}
+	if doc.Info.Version != "2.3.4" {
+		t.Errorf("Expected the configured version, got %q", doc.Info.Version)
+	}
+	operation, ok := doc.Paths["/api/v1/admin/users/{id}"]["delete"]
+	if !ok || len(operation.Security) != 1 || len(operation.Parameters) != 1 || operation.Parameters[0].Name != "id" {
+		t.Errorf("Unexpected delete user operation %+v", operation)
+	}
+	if operation := doc.Paths["/auth/register"]["post"]; operation.Security != nil || operation.Tags[0] != "Authentication Routes" {
+		t.Errorf("Unexpected register operation %+v", operation)
+	}
+	if _, ok := doc.Paths["/admin-ui/"]; ok {
+		t.Errorf("Expected static prefix routes to be left out of the document")
+	}
+}
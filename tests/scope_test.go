@@ -0,0 +1,85 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type scopedRoutes struct{}
+
+func (scopedRoutes) Routes() []routes.Route {
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	return []routes.Route{
+		{Method: "POST", Path: "/api/v1/reports", Handler: ok, Description: "Create a report", Scopes: []string{domain.ScopeUsersWrite}},
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	jwtCfg := config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: jwtCfg})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRoleScopes(map[string][]string{"admin": {domain.ScopeUsersRead, domain.ScopeUsersWrite}})
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Scoped Routes", scopedRoutes{})
+	server := router.SetupRoutes()
+	post := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reports", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Users have the scopes of their role
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	if status := post(adminToken); status != http.StatusNoContent {
+		t.Errorf("Expected the admin role's scope to be accepted, got %d", status)
+	}
+	if status := post(userToken); status != http.StatusForbidden {
+		t.Errorf("Expected a user without the scope to be refused, got %d", status)
+	}
+
+	// Scopes in the token are accepted on top of the role's
+	scoped, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1", "email": "user@example.com", "role": "user", "scope": "users:write",
+		"iss": config.DefaultJWTIssuer, "aud": config.DefaultJWTAudience,
+		"exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix(),
+	}).SignedString([]byte("test-secret"))
+	if status := post(scoped); status != http.StatusNoContent {
+		t.Errorf("Expected the token's scope to be accepted, got %d", status)
+	}
+
+	// Machine clients have only the scopes of their token, whatever their role
+	tokenFor := func(scopes ...string) string {
+		token, _ := tokenService.GenerateClientToken(&domain.ClientGrant{ClientID: "svc_1", Role: "admin", Scopes: scopes, TTL: time.Minute})
+		return token
+	}
+	if status := post(tokenFor(domain.APIKeyScopeWrite, domain.APIKeyScopeAdmin)); status != http.StatusForbidden {
+		t.Errorf("Expected a client without the scope to be refused, got %d", status)
+	}
+	if status := post(tokenFor(domain.APIKeyScopeWrite, domain.ScopeUsersWrite)); status != http.StatusNoContent {
+		t.Errorf("Expected a client with the scope to be accepted, got %d", status)
+	}
+
+	// Route listings show the scopes
+	for _, info := range router.GetAllRouteInfo() {
+		if info.Path == "/api/v1/reports" && (len(info.Scopes) != 1 || info.Scopes[0] != domain.ScopeUsersWrite) {
+			t.Errorf("Expected the route info to list the scope, got %+v", info)
+		}
+	}
+}
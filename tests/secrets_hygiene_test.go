@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/handler"
+	"demo-go/internal/hygiene"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/response"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+	"demo-go/internal/tracing"
+)
+
+func TestSecretsHygiene(t *testing.T) {
+	cfg := &config.Config{
+		JWT:     config.JWTConfig{SecretKey: config.DefaultJWTSecret},
+		Cache:   config.CacheConfig{Type: "redis", Redis: config.RedisConfig{Address: "redis.internal:6379"}},
+		CORS:    config.CORSConfig{AllowedOrigins: []string{"*"}},
+		Hygiene: config.SecretsHygieneConfig{Environment: "development"},
+	}
+	log := logger.GetGlobal()
+
+	if findings := hygiene.Check(cfg); len(findings) != 3 {
+		t.Fatalf("Expected three findings, got %+v", findings)
+	}
+	if err := hygiene.Enforce(cfg, log); err != nil {
+		t.Errorf("Expected development to only warn, got %v", err)
+	}
+
+	cfg.Hygiene.Environment = "production"
+	if err := hygiene.Enforce(cfg, log); err == nil {
+		t.Error("Expected production to refuse insecure settings")
+	}
+
+	// Each finding is fixed or acknowledged
+	cfg.Cache.Redis.Address = "127.0.0.1:6379"
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	cfg.Hygiene.Acknowledged = []string{hygiene.FindingDefaultJWTSecret}
+	if findings := hygiene.Check(cfg); len(findings) != 1 || !findings[0].Acknowledged {
+		t.Errorf("Expected only the acknowledged JWT secret, got %+v", findings)
+	}
+	if err := hygiene.Enforce(cfg, log); err != nil {
+		t.Errorf("Expected acknowledged findings to be allowed, got %v", err)
+	}
+
+	cfg.Hygiene.Acknowledged = []string{"default_jwt_secrte"}
+	if err := hygiene.Enforce(cfg, log); err == nil {
+		t.Error("Expected unknown acknowledgements to be refused")
+	}
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.SetCORSOrigins([]string{"https://app.example.com"})
+	server := router.SetupRoutes()
+
+	fromOrigin := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := fromOrigin("https://app.example.com"); rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || rec.Header().Get("Vary") != "Origin" {
+		t.Errorf("Expected the allowed origin to be echoed, got %v", rec.Header())
+	}
+	allowedHeaders := fromOrigin("https://app.example.com").Header().Get("Access-Control-Allow-Headers")
+	for _, header := range []string{
+		response.NamingHeader, response.EnvelopeHeader, middleware.ExplainHeader,
+		middleware.HeaderAPIKey, middleware.HeaderCSRFToken, tracing.TraceParentHeader, tracing.TraceStateHeader,
+	} {
+		if !strings.Contains(allowedHeaders, header) {
+			t.Errorf("Expected %s to be allowed on cross-origin requests, got %q", header, allowedHeaders)
+		}
+	}
+	if rec := fromOrigin("https://evil.example.com"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected other origins to get no CORS headers, got %v", rec.Header())
+	}
+}
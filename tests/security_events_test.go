@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/hooks"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/repository"
+	"demo-go/internal/security"
+	"demo-go/internal/service"
+)
+
+func TestSecurityEvents(t *testing.T) {
+	ctx := context.Background()
+	registry := hooks.NewRegistry(time.Second)
+	var alerted []*domain.SecurityEvent
+	registry.OnSecurityEvent("test", func(_ context.Context, event *domain.SecurityEvent) error {
+		alerted = append(alerted, event)
+		return nil
+	})
+
+	events := security.NewEventService(ratelimit.NewMemoryLimiter(), registry, config.SecurityEventsConfig{
+		BufferSize: 100, FailureThreshold: 2, FailureWindow: time.Minute,
+	})
+	security.RegisterLoginHooks(registry, events)
+
+	// The third failure for the same email exceeds the threshold
+	for i := 0; i < 3; i++ {
+		registry.LoginFailed(ctx, "victim@example.com", domain.ErrInvalidCredentials)
+	}
+	failures, total, err := events.ListEvents(ctx, domain.SecurityEventFilter{Type: domain.SecurityEventLoginFailed}, 10, 0)
+	if err != nil || total != 3 {
+		t.Fatalf("Expected 3 login failures, got %d (%v)", total, err)
+	}
+	if latest := failures[0]; latest.Severity != domain.SecuritySeverityHigh || len(latest.Flags) != 1 ||
+		latest.Flags[0] != domain.SecurityFlagRepeatedFailuresEmail {
+		t.Errorf("Expected newest failure flagged as repeated, got %s %v", latest.Severity, latest.Flags)
+	}
+	if failures[2].Severity != domain.SecuritySeverityLow || len(failures[2].Flags) != 0 {
+		t.Errorf("Expected first failure unflagged, got %s %v", failures[2].Severity, failures[2].Flags)
+	}
+
+	// Promotions to admin are recorded through the audit decorator
+	audit := security.NewAuditService(service.NewAuditService(repository.NewMemoryAuditRepository()), events)
+	if err := audit.Record(ctx, &domain.AuditEvent{
+		Action: domain.AuditActionUserRoleChanged, ActorID: "1", TargetID: "2",
+		Details: map[string]interface{}{"from": "user", "to": "admin"}, CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	escalations, _, _ := events.ListEvents(ctx, domain.SecurityEventFilter{TargetID: "2"}, 10, 0)
+	if len(escalations) != 1 || escalations[0].Flags[0] != domain.SecurityFlagPrivilegeEscalation {
+		t.Errorf("Expected privilege escalation event, got %+v", escalations)
+	}
+
+	high, total, _ := events.ListEvents(ctx, domain.SecurityEventFilter{MinSeverity: domain.SecuritySeverityHigh}, 10, 0)
+	if total != 2 || len(high) != 2 {
+		t.Errorf("Expected 2 high severity events, got %d", total)
+	}
+	if len(alerted) != 4 {
+		t.Errorf("Expected hooks for all 4 events, got %d", len(alerted))
+	}
+	if _, _, err := events.ListEvents(ctx, domain.SecurityEventFilter{MinSeverity: "urgent"}, 10, 0); err == nil {
+		t.Error("Expected unknown severity to be rejected")
+	}
+}
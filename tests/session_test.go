@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestSessionManagement(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+	refreshStore := repository.NewMemoryRefreshTokenStore()
+	sessions := service.NewSessionService(repository.NewMemorySessionRepository(), refreshStore, tokenService, revocations, time.Hour)
+	refreshTokens := service.NewRefreshTokenService(refreshStore, userService, nil, revocations, time.Hour,
+		service.WithSessionRecorder(sessions))
+
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetRefreshTokenService(refreshTokens)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	router := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Token Revocation Routes", routes.NewTokenRevocationRoutes(
+		handler.NewTokenRevocationHandler(revocations, refreshTokens),
+	))
+	router.AddRouteGroup("Session Routes", routes.NewSessionRoutes(handler.NewSessionHandler(sessions)))
+	server := router.SetupRoutes()
+
+	user, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Session User", Email: "sessions@example.com", Password: "password123"})
+
+	send := func(method, path, token, userAgent string, body interface{}) (int, json.RawMessage) {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("User-Agent", userAgent)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Data
+	}
+	login := func(userAgent string) domain.TokenPair {
+		status, data := send(http.MethodPost, "/auth/login", "", userAgent, domain.LoginRequest{Email: "sessions@example.com", Password: "password123"})
+		var pair domain.TokenPair
+		_ = json.Unmarshal(data, &pair)
+		if status != http.StatusOK || pair.RefreshToken == "" {
+			t.Fatalf("Expected login to succeed, got %d: %s", status, data)
+		}
+		return pair
+	}
+	list := func(token string) []*domain.Session {
+		status, data := send(http.MethodGet, "/api/v1/sessions", token, "", nil)
+		var listed struct {
+			Sessions []*domain.Session `json:"sessions"`
+		}
+		_ = json.Unmarshal(data, &listed)
+		if status != http.StatusOK {
+			t.Fatalf("Expected sessions to be listed, got %d: %s", status, data)
+		}
+		return listed.Sessions
+	}
+
+	laptop := login("Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15")
+	phone := login("Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36")
+	tablet := login("curl/8.4.0")
+
+	// Each sign-in is a session, described by its device, and the caller's is marked
+	listed := list(laptop.AccessToken)
+	if len(listed) != 3 {
+		t.Fatalf("Expected three sessions, got %d", len(listed))
+	}
+	devices := map[string]*domain.Session{}
+	for _, session := range listed {
+		devices[session.Device] = session
+	}
+	if devices["Safari on macOS"] == nil || devices["Chrome on Android"] == nil || devices["curl"] == nil {
+		t.Errorf("Expected the devices to be recognized, got %v", devices)
+	}
+	if !devices["Safari on macOS"].Current || devices["Chrome on Android"].Current || devices["Safari on macOS"].UserID != user.ID {
+		t.Errorf("Expected only the caller's session to be current, got %+v", listed)
+	}
+
+	// Refreshing keeps the session, and its new access token identifies it
+	status, data := send(http.MethodPost, "/auth/refresh", "", "Mozilla/5.0 (Linux; Android 14) Chrome/121.0", domain.RefreshRequest{RefreshToken: phone.RefreshToken})
+	var refreshed domain.TokenPair
+	_ = json.Unmarshal(data, &refreshed)
+	if status != http.StatusOK {
+		t.Fatalf("Expected refresh to succeed, got %d", status)
+	}
+	listed = list(refreshed.AccessToken)
+	if len(listed) != 3 || !listed[0].Current || listed[0].Device != "Chrome on Android" {
+		t.Errorf("Expected the refreshed session to be seen last and current, got %+v", listed[0])
+	}
+
+	// Revoking a session stops both its old and new access tokens and its refresh token
+	phoneSession := listed[0].ID
+	if status, _ := send(http.MethodDelete, "/api/v1/sessions/"+phoneSession, laptop.AccessToken, "", nil); status != http.StatusOK {
+		t.Fatalf("Expected the session to be revoked, got %d", status)
+	}
+	for _, token := range []string{phone.AccessToken, refreshed.AccessToken} {
+		if status, _ := send(http.MethodGet, "/api/v1/profile", token, "", nil); status != http.StatusUnauthorized {
+			t.Errorf("Expected access tokens of a revoked session to be refused, got %d", status)
+		}
+	}
+	if status, _ := send(http.MethodPost, "/auth/refresh", "", "", domain.RefreshRequest{RefreshToken: refreshed.RefreshToken}); status != http.StatusUnauthorized {
+		t.Errorf("Expected the refresh token of a revoked session to be refused, got %d", status)
+	}
+	if status, _ := send(http.MethodDelete, "/api/v1/sessions/"+phoneSession, laptop.AccessToken, "", nil); status != http.StatusNotFound {
+		t.Errorf("Expected a revoked session not to be found, got %d", status)
+	}
+
+	// Revoking the other sessions keeps only the caller's
+	login("Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:120.0) Gecko/20100101 Firefox/120.0")
+	status, data = send(http.MethodDelete, "/api/v1/sessions", laptop.AccessToken, "", nil)
+	if status != http.StatusOK || string(data) != `{"revoked":2}` {
+		t.Errorf("Expected two other sessions to be revoked, got %d: %s", status, data)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", tablet.AccessToken, "", nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected another session's token to be refused, got %d", status)
+	}
+	if listed = list(laptop.AccessToken); len(listed) != 1 || !listed[0].Current {
+		t.Errorf("Expected only the current session to remain, got %+v", listed)
+	}
+
+	// Logging out ends the session
+	if status, _ := send(http.MethodPost, "/auth/logout", laptop.AccessToken, "", map[string]string{"refresh_token": laptop.RefreshToken}); status != http.StatusOK {
+		t.Fatalf("Expected logout to succeed, got %d", status)
+	}
+	if listed = list(login("curl/8.4.0").AccessToken); len(listed) != 1 || listed[0].ID == devices["Safari on macOS"].ID {
+		t.Errorf("Expected the logged out session to be gone, got %+v", listed)
+	}
+
+	// Revoking all tokens of the user hides the sessions started before
+	if err := revocations.RevokeUserTokens(ctx, "admin-1", user.ID); err != nil {
+		t.Fatalf("RevokeUserTokens failed: %v", err)
+	}
+	if sessionsLeft, _ := sessions.ListSessions(ctx, user.ID, ""); len(sessionsLeft) != 0 {
+		t.Errorf("Expected no sessions after revoking every token, got %d", len(sessionsLeft))
+	}
+}
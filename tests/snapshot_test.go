@@ -0,0 +1,156 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+	"demo-go/internal/snapshot"
+)
+
+func TestSnapshotCreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := snapshot.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+
+	userRepo := repository.NewMemoryUserRepository()
+	for _, id := range []string{"u1", "u2"} {
+		if err := userRepo.Create(ctx, &domain.User{ID: id, Name: "User " + id, Email: id + "@example.com", Password: "hash-" + id, Role: "user"}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	cfg := config.SnapshotConfig{Source: "staging", ConfirmationKey: "test-key", ConfirmTTL: time.Minute}
+	snapshots := service.NewSnapshotService(store, userRepo, nil, nil, cfg)
+
+	info, err := snapshots.Create(ctx, "admin-1")
+	if err != nil {
+		t.Fatalf("Create snapshot failed: %v", err)
+	}
+	if info.UserCount != 2 || info.Checksum == "" || info.Version != domain.UserSnapshotVersion {
+		t.Fatalf("Unexpected snapshot info: %+v", info)
+	}
+	if names, _ := snapshots.List(ctx); len(names) != 1 || names[0] != info.Name {
+		t.Errorf("Expected the snapshot to be listed, got %v", names)
+	}
+
+	// Diverge from the snapshot: one user gone, one changed, one added
+	_ = userRepo.Delete(ctx, "u1")
+	_ = userRepo.Update(ctx, "u2", &domain.User{ID: "u2", Name: "Renamed", Email: "u2@example.com", Password: "changed", Role: "admin"})
+	_ = userRepo.Create(ctx, &domain.User{ID: "u3", Name: "User u3", Email: "u3@example.com", Password: "hash-u3", Role: "user"})
+
+	preview, err := snapshots.PreviewRestore(ctx, "admin-1", info.Name)
+	if err != nil {
+		t.Fatalf("PreviewRestore failed: %v", err)
+	}
+	if preview.CurrentUsers != 2 || preview.ConfirmationToken == "" {
+		t.Errorf("Unexpected preview: %+v", preview)
+	}
+
+	// Tokens are bound to the admin who previewed the restore
+	if _, err := snapshots.Restore(ctx, "admin-2", info.Name, preview.ConfirmationToken); !errors.Is(err, domain.ErrInvalidConfirmation) {
+		t.Errorf("Expected ErrInvalidConfirmation for another admin, got %v", err)
+	}
+	if _, err := userRepo.GetByID(ctx, "u3"); err != nil {
+		t.Errorf("Expected nothing restored without a valid token, got %v", err)
+	}
+
+	result, err := snapshots.Restore(ctx, "admin-1", info.Name, preview.ConfirmationToken)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Created != 1 || result.Updated != 1 || result.Deleted != 1 {
+		t.Errorf("Expected 1 created, 1 updated and 1 deleted, got %+v", result)
+	}
+	if user, err := userRepo.GetByID(ctx, "u2"); err != nil || user.Name != "User u2" || user.Password != "hash-u2" {
+		t.Errorf("Expected u2 restored with its password hash, got %+v, %v", user, err)
+	}
+	if _, err := userRepo.GetByID(ctx, "u1"); err != nil {
+		t.Errorf("Expected u1 restored, got %v", err)
+	}
+	if _, err := userRepo.GetByID(ctx, "u3"); err != domain.ErrUserNotFound {
+		t.Errorf("Expected u3 removed, got %v", err)
+	}
+
+	// A snapshot that was altered after it was taken is refused
+	path := filepath.Join(dir, info.Name+".json")
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), "hash-u1", "hash-xx", 1)), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	var domainErr *domain.Error
+	if _, err := snapshots.Get(ctx, info.Name); !errors.As(err, &domainErr) || domainErr.Code != domain.ErrInvalidSnapshot.Code {
+		t.Errorf("Expected ErrInvalidSnapshot for a tampered snapshot, got %v", err)
+	}
+	if _, err := snapshots.Get(ctx, "../etc/passwd"); err == nil {
+		t.Error("Expected unsafe snapshot names to be rejected")
+	}
+}
+
+func TestSnapshotS3Store(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.TrimPrefix(r.URL.Path, "/backups/")
+		switch {
+		case r.Method == http.MethodPut:
+			objects[key], _ = io.ReadAll(r.Body)
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`)
+			for name := range objects {
+				fmt.Fprintf(w, `<Contents><Key>%s</Key></Contents>`, name)
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+		case r.Method == http.MethodGet && objects[key] != nil:
+			_, _ = w.Write(objects[key])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store, err := snapshot.NewS3Store(config.SnapshotS3Config{
+		Bucket: "backups", Prefix: "staging/", Region: "us-east-1", Endpoint: server.URL,
+		AccessKeyID: "AKID", SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, "users-1", []byte(`{"version":1}`)); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if data, err := store.Get(ctx, "users-1"); err != nil || string(data) != `{"version":1}` {
+		t.Errorf("Expected the stored object back, got %q, %v", data, err)
+	}
+	if names, err := store.List(ctx); err != nil || len(names) != 1 || names[0] != "users-1" {
+		t.Errorf("Expected [users-1], got %v, %v", names, err)
+	}
+	if _, err := store.Get(ctx, "missing"); err != domain.ErrSnapshotNotFound {
+		t.Errorf("Expected ErrSnapshotNotFound, got %v", err)
+	}
+}
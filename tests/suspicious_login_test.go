@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/hooks"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/ratelimit"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/security"
+	"demo-go/internal/service"
+)
+
+func TestSuspiciousLoginDetection(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	logins := repository.NewMemoryLoginHistoryRepository(time.Hour)
+	events := security.NewEventService(ratelimit.NewMemoryLimiter(), hooks.NewRegistry(time.Second), config.SecurityEventsConfig{BufferSize: 100})
+	sender := &fakeEmailSender{}
+	history := service.NewSuspiciousLoginDetector(
+		service.NewLoginHistoryService(logins, userRepo), logins, userRepo, events, sender,
+		config.SuspiciousLoginConfig{Enabled: true, NewCountry: true, NewDevice: true, Lookback: 20, MinHistory: 1, Notify: true},
+	)
+	userService := service.NewUserService(userRepo, tokenService)
+	composed := service.ComposeUserService(userService, service.NewLoginHistoryUserCommands(userService, history, userRepo))
+	server := routes.NewRouter(handler.NewUserHandler(composed), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal(),
+		middleware.ClientCountry("CF-IPCountry")).SetupRoutes()
+
+	if _, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Member", Email: "member@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	login := func(userAgent, country string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"member@example.com","password":"password123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("CF-IPCountry", country)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the login to succeed, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	suspicious := func() []*domain.SecurityEvent {
+		flagged, _, _ := events.ListEvents(ctx, domain.SecurityEventFilter{Type: domain.SecurityEventSuspiciousLogin}, 10, 0)
+		return flagged
+	}
+	const firefox = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0"
+	const safari = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 Version/17.5 Mobile/15E148 Safari/604.1"
+
+	// The first login has nothing to compare with, and known ones are not flagged
+	login(firefox, "de")
+	login(firefox, "DE")
+	if flagged := suspicious(); len(flagged) != 0 {
+		t.Fatalf("Expected no suspicious logins yet, got %+v", flagged)
+	}
+
+	login(safari, "FR")
+	flagged := suspicious()
+	if len(flagged) != 1 || len(flagged[0].Flags) != 2 || flagged[0].Details["device"] != "Safari on iOS" || flagged[0].Details["country"] != "FR" {
+		t.Fatalf("Expected a login flagged for its new country and device, got %+v", flagged)
+	}
+
+	// A new device in a known country is only flagged for the device
+	login("curl/8.5.0", "DE")
+	flagged = suspicious()
+	if len(flagged) != 2 || len(flagged[0].Flags) != 1 || flagged[0].Flags[0] != domain.SecurityFlagNewDevice {
+		t.Fatalf("Expected the login flagged for its new device only, got %+v", flagged)
+	}
+
+	// Users are emailed in the background about every flagged login
+	deadline := time.Now().Add(time.Second)
+	for {
+		sender.mu.Lock()
+		sent := len(sender.sent)
+		sender.mu.Unlock()
+		if sent == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 2 suspicious login emails, got %d", sent)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if message := sender.sent[0]; message.Kind != service.EmailKindSuspiciousLogin || message.To != "member@example.com" {
+		t.Errorf("Expected the email to go to the user, got %+v", message)
+	}
+}
@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/telemetry"
+)
+
+type fixedUserCounter int64
+
+func (c fixedUserCounter) Count(context.Context) (int64, error) {
+	return int64(c), nil
+}
+
+func TestUserCountBucket(t *testing.T) {
+	cases := map[int64]string{0: "0", 7: "1-10", 100: "11-100", 5000: "1001-10000", 250000: "100000+"}
+	for count, want := range cases {
+		if got := telemetry.UserCountBucket(count); got != want {
+			t.Errorf("UserCountBucket(%d) = %q, want %q", count, got, want)
+		}
+	}
+}
+
+func TestTelemetryCollector(t *testing.T) {
+	received := make(chan telemetry.Report, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report telemetry.Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("Failed to decode report: %v", err)
+		}
+		received <- report
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Disabled collectors never send but still expose their payload
+	disabled := telemetry.NewCollector(config.TelemetryConfig{Endpoint: server.URL}, "1.2.3", nil, fixedUserCounter(42))
+	disabled.Start()
+	status, err := disabled.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Enabled || status.Payload.UserCount != "11-100" || status.Payload.Version != "1.2.3" {
+		t.Errorf("Unexpected disabled status: %+v", status)
+	}
+
+	collector := telemetry.NewCollector(
+		config.TelemetryConfig{Enabled: true, Endpoint: server.URL, Interval: time.Hour},
+		"1.2.3", []string{"redis"}, fixedUserCounter(42),
+	)
+	collector.Start()
+	defer collector.Close(context.Background()) //nolint:errcheck
+
+	select {
+	case report := <-received:
+		if report.UserCount != "11-100" || len(report.Features) != 1 || report.InstanceID == "" {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a report on start")
+	}
+	select {
+	case <-received:
+		t.Error("Disabled collector sent a report")
+	default:
+	}
+}
@@ -0,0 +1,121 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestTokenExchange(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	user := &domain.User{ID: "user-1", Email: "user@example.com", Name: "User", Role: "user"}
+	_ = userRepo.Create(ctx, user)
+
+	clientService := service.NewClientService(repository.NewMemoryClientRepository(), tokenService, nil, "user",
+		config.ClientCredentialsConfig{Enabled: true, TokenTTL: 10 * time.Minute, TokenExchange: true, ExchangeTokenTTL: 5 * time.Minute},
+		service.WithTokenExchange(nil, nil))
+	router := routes.NewRouter(handler.NewUserHandler(service.NewUserService(userRepo, tokenService)), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.AddRouteGroup("Client Routes", routes.NewClientRoutes(handler.NewClientHandler(clientService), true))
+	server := router.SetupRoutes()
+
+	gateway, _ := clientService.CreateClient(ctx, "admin-1", &domain.CreateClientRequest{Name: "gateway", Scopes: []string{domain.APIKeyScopeRead}})
+	reports, _ := clientService.CreateClient(ctx, "admin-1", &domain.CreateClientRequest{Name: "reports", Scopes: []string{domain.APIKeyScopeRead}})
+	userToken, _ := tokenService.GenerateToken(user)
+
+	exchange := func(client *domain.CreatedClient, subjectToken, scope string) (*httptest.ResponseRecorder, domain.ClientToken) {
+		form := url.Values{
+			"grant_type":         {domain.GrantTypeTokenExchange},
+			"subject_token":      {subjectToken},
+			"subject_token_type": {domain.TokenTypeAccessToken},
+			"scope":              {scope},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/auth/token/exchange", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(client.ID, client.ClientSecret)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		var token domain.ClientToken
+		_ = json.Unmarshal(rec.Body.Bytes(), &token)
+		return rec, token
+	}
+	oauthError := func(rec *httptest.ResponseRecorder) string {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return body.Error
+	}
+	callWith := func(method, token string) int {
+		req := httptest.NewRequest(method, "/api/v1/profile", strings.NewReader(`{"name":"Renamed"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	rec, delegated := exchange(gateway, userToken, "read")
+	if rec.Code != http.StatusOK || delegated.IssuedTokenType != domain.TokenTypeAccessToken || delegated.Scope != "read" || delegated.ExpiresIn != 300 {
+		t.Fatalf("Expected a delegated read token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	claims, err := tokenService.ValidateToken(delegated.AccessToken)
+	if err != nil || claims.UserID != user.ID || claims.Actor == nil || claims.Actor.Subject != gateway.ID {
+		t.Fatalf("Expected the token to act for the user on behalf of the client, got %+v, %v", claims, err)
+	}
+
+	// The delegated token only has its own scopes
+	if code := callWith(http.MethodGet, delegated.AccessToken); code != http.StatusOK {
+		t.Errorf("Expected the delegated token to read the profile, got %d", code)
+	}
+	if code := callWith(http.MethodPut, delegated.AccessToken); code != http.StatusForbidden {
+		t.Errorf("Expected the delegated token to be refused writes, got %d", code)
+	}
+
+	// Delegated tokens can be narrowed further, not widened, and keep the chain of actors
+	if rec, _ := exchange(reports, delegated.AccessToken, "write"); rec.Code != http.StatusBadRequest || oauthError(rec) != "invalid_scope" {
+		t.Errorf("Expected widening a delegated token to fail, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec, chained := exchange(reports, delegated.AccessToken, "read")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the delegated token to be exchanged again, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if claims, _ := tokenService.ValidateToken(chained.AccessToken); claims == nil || claims.Actor.Subject != reports.ID || claims.Actor.Actor == nil || claims.Actor.Actor.Subject != gateway.ID {
+		t.Errorf("Expected nested actors, got %+v", claims)
+	}
+
+	// Users with the default role cannot delegate admin, and scopes must be asked for
+	if rec, _ := exchange(gateway, userToken, "admin"); oauthError(rec) != "invalid_scope" {
+		t.Errorf("Expected the admin scope to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := exchange(gateway, userToken, ""); rec.Code != http.StatusBadRequest || oauthError(rec) != "invalid_request" {
+		t.Errorf("Expected an exchange without scopes to fail, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Client tokens have no user to act for
+	clientToken, _ := clientService.IssueToken(ctx, gateway.ID, gateway.ClientSecret, nil)
+	if rec, _ := exchange(gateway, clientToken.AccessToken, "read"); oauthError(rec) != "invalid_grant" {
+		t.Errorf("Expected client tokens to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := exchange(gateway, "not-a-token", "read"); oauthError(rec) != "invalid_grant" {
+		t.Errorf("Expected invalid subject tokens to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := exchange(&domain.CreatedClient{MachineClient: gateway.MachineClient, ClientSecret: "wrong"}, userToken, "read"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected wrong client credentials to be refused, got %d", rec.Code)
+	}
+}
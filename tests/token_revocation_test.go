@@ -0,0 +1,141 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/cache"
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestLogoutAndAdminTokenRevocation(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+	refreshTokens := service.NewRefreshTokenService(repository.NewMemoryRefreshTokenStore(), userService, nil, revocations, time.Hour)
+
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetRefreshTokenService(refreshTokens)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	router := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Token Revocation Routes", routes.NewTokenRevocationRoutes(
+		handler.NewTokenRevocationHandler(revocations, refreshTokens),
+	))
+	server := router.SetupRoutes()
+
+	user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Logout Tester", Email: "logout@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+
+	send := func(method, path, token string, body interface{}) (int, domain.TokenPair) {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		var envelope struct {
+			Data domain.TokenPair `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Data
+	}
+	login := func() domain.TokenPair {
+		status, pair := send(http.MethodPost, "/auth/login", "", domain.LoginRequest{Email: "logout@example.com", Password: "password123"})
+		if status != http.StatusOK {
+			t.Fatalf("Expected login to succeed, got %d", status)
+		}
+		return pair
+	}
+
+	// Logging out revokes the access token and ends the refresh token's family
+	first, second := login(), login()
+	if status, _ := send(http.MethodPost, "/auth/logout", first.AccessToken, map[string]string{"refresh_token": first.RefreshToken}); status != http.StatusOK {
+		t.Fatalf("Expected logout to succeed, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", first.AccessToken, nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a logged out token, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/auth/refresh", "", domain.RefreshRequest{RefreshToken: first.RefreshToken}); status != http.StatusUnauthorized {
+		t.Errorf("Expected the logged out refresh token to be refused, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", second.AccessToken, nil); status != http.StatusOK {
+		t.Errorf("Expected other sessions to stay logged in, got %d", status)
+	}
+
+	// Only admins revoke every token of a user
+	path := "/api/v1/admin/users/" + user.ID + "/revoke-tokens"
+	if status, _ := send(http.MethodPost, path, second.AccessToken, nil); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for a regular user, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/api/v1/admin/users/missing/revoke-tokens", adminToken, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown user, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, path, adminToken, nil); status != http.StatusOK {
+		t.Fatalf("Expected the admin revocation to succeed, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", second.AccessToken, nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after all tokens were revoked, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/auth/refresh", "", domain.RefreshRequest{RefreshToken: second.RefreshToken}); status != http.StatusUnauthorized {
+		t.Errorf("Expected refresh tokens issued before the revocation to be refused, got %d", status)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/admin/users", adminToken, nil); status != http.StatusOK {
+		t.Errorf("Expected other users' tokens to stay valid, got %d", status)
+	}
+}
+
+func TestTokenRevocationFailsClosed(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	denylist := &flakyCache{}
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(service.NewTokenRevocationService(cache.NewTokenRevocationStore(denylist), userRepo, nil, time.Hour))
+	server := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	if _, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Outage Tester", Email: "outage@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	token, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "outage@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	profile := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := profile(); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the token to be accepted while the denylist is up, got %d", rec.Code)
+	}
+
+	// A token that may have been revoked is not accepted while the denylist is down
+	denylist.setDown(true)
+	if rec := profile(); rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After while the denylist is down, got %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+	"demo-go/internal/usage"
+)
+
+func TestUsageAnalytics(t *testing.T) {
+	ctx := context.Background()
+	tracker := usage.New(config.UsageConfig{MaxDays: 30}, repository.NewMemoryUsageCounterStore(), repository.NewMemoryUsageRepository())
+
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), middleware.NewJWTMiddleware(tokenService), logger.GetGlobal())
+	router.UseAfterAuth(middleware.UsageTracking(tracker))
+	router.AddRouteGroup("Usage Routes", routes.NewUsageRoutes(handler.NewUsageHandler(tracker)))
+	server := router.SetupRoutes()
+
+	adminToken, _ := tokenService.GenerateToken(&domain.User{ID: "admin-1", Email: "admin@example.com", Role: "admin"})
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: "user-1", Email: "user@example.com", Role: "user"})
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	type report struct {
+		Data struct {
+			Usage  []*domain.UsageRecord `json:"usage"`
+			Totals domain.UsageTotals    `json:"totals"`
+		} `json:"data"`
+	}
+	decode := func(rec *httptest.ResponseRecorder) report {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected usage, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var r report
+		_ = json.Unmarshal(rec.Body.Bytes(), &r)
+		return r
+	}
+
+	// Two successful requests and a refused one by the user; anonymous ones are not counted
+	get("/api/v1/usage", userToken)
+	get("/api/v1/usage", userToken)
+	if rec := get("/api/v1/admin/usage", userToken); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected users to be refused the admin usage, got %d", rec.Code)
+	}
+	get("/health", "")
+	tracker.Flush(ctx)
+	if err := tracker.Rollup(ctx); err != nil {
+		t.Fatalf("Rollup failed: %v", err)
+	}
+
+	mine := decode(get("/api/v1/usage", userToken))
+	if len(mine.Data.Usage) != 1 || mine.Data.Usage[0].Type != domain.UsagePrincipalUser || mine.Data.Usage[0].Day != time.Now().UTC().Format(domain.UsageDayFormat) {
+		t.Fatalf("Expected one day of user usage, got %+v", mine.Data.Usage)
+	}
+	totals := mine.Data.Totals
+	if totals.Requests != 3 || totals.ClientErrors != 1 || totals.ServerErrors != 0 || totals.ErrorRate < 0.33 || totals.ErrorRate > 0.34 {
+		t.Errorf("Expected 3 requests with one client error, got %+v", totals)
+	}
+
+	// Admins see everyone's usage, or one user's
+	get("/api/v1/admin/usage", adminToken)
+	tracker.Flush(ctx)
+	_ = tracker.Rollup(ctx)
+	all := decode(get("/api/v1/admin/usage", adminToken))
+	if len(all.Data.Usage) != 2 {
+		t.Errorf("Expected the usage of the user and the admin, got %+v", all.Data.Usage)
+	}
+	one := decode(get("/api/v1/admin/usage?user_id=user-1", adminToken))
+	if len(one.Data.Usage) != 1 || one.Data.Usage[0].UserID != "user-1" || one.Data.Totals.Requests != 4 {
+		t.Errorf("Expected the user's 4 requests, got %+v", one.Data)
+	}
+
+	if rec := get("/api/v1/usage?from=2020-01-01", userToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected ranges over the maximum to be rejected, got %d", rec.Code)
+	}
+	if rec := get("/api/v1/usage?principal_type=robot", userToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected unknown principal types to be rejected, got %d", rec.Code)
+	}
+}
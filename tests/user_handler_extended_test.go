@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"demo-go/internal/domain"
 	"demo-go/internal/handler"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
 
 	"github.com/gorilla/mux"
 )
@@ -207,25 +210,23 @@ func TestUserHandler_GetUsers(t *testing.T) {
 			},
 			mockSetup: func(m *mockUserService) {
 				m.getUsersFunc = func(ctx context.Context, limit, offset int) ([]*domain.UserResponse, int64, error) {
-					// Should use defaults (10, 0) when invalid params provided
-					if limit == 10 && offset == 0 {
-						return []*domain.UserResponse{}, 0, nil
-					}
+					t.Error("Expected invalid query parameters to be rejected before listing users")
 					return []*domain.UserResponse{}, 0, nil
 				}
 			},
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, body map[string]interface{}) {
-				if !body["success"].(bool) {
-					t.Error("Expected success to be true")
+				if body["success"].(bool) {
+					t.Error("Expected success to be false")
 				}
-				// Should use default values when invalid params provided
-				data := body["data"].(map[string]interface{})
-				if data["limit"].(float64) != 10 {
-					t.Error("Expected default limit of 10")
+				// Every invalid parameter is listed
+				errorBody := body["error"].(map[string]interface{})
+				if errorBody["code"] != "VALIDATION_FAILED" {
+					t.Errorf("Expected VALIDATION_FAILED, got %v", errorBody["code"])
 				}
-				if data["offset"].(float64) != 0 {
-					t.Error("Expected default offset of 0")
+				details, _ := errorBody["details"].([]interface{})
+				if len(details) != 2 {
+					t.Errorf("Expected limit and offset to be reported, got %v", errorBody["details"])
 				}
 			},
 		},
@@ -511,14 +512,15 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 func TestUserHandler_RefreshToken(t *testing.T) {
 	tests := []struct {
 		name           string
-		userID         string
+		issueFor       string // user to issue the presented refresh token for, empty to send refreshToken
+		refreshToken   string
 		mockSetup      func(*mockUserService)
 		expectedStatus int
 		checkResponse  func(t *testing.T, body map[string]interface{})
 	}{
 		{
-			name:   "successful token refresh",
-			userID: testUserID,
+			name:     "successful token refresh",
+			issueFor: testUserID,
 			mockSetup: func(m *mockUserService) {
 				m.refreshTokenFunc = func(ctx context.Context, userID string) (string, error) {
 					if userID == testUserID {
@@ -536,11 +538,14 @@ func TestUserHandler_RefreshToken(t *testing.T) {
 				if data["token"].(string) != "new-jwt-token-456" {
 					t.Error("Expected new JWT token in response")
 				}
+				if data["refresh_token"].(string) == "" {
+					t.Error("Expected a rotated refresh token in response")
+				}
 			},
 		},
 		{
-			name:   "user not found for token refresh",
-			userID: "nonexistent-user",
+			name:     "user not found for token refresh",
+			issueFor: "nonexistent-user",
 			mockSetup: func(m *mockUserService) {
 				m.refreshTokenFunc = func(ctx context.Context, userID string) (string, error) {
 					return "", domain.ErrUserNotFound
@@ -554,8 +559,8 @@ func TestUserHandler_RefreshToken(t *testing.T) {
 			},
 		},
 		{
-			name:           "missing user ID in context",
-			userID:         "",
+			name:           "unknown refresh token",
+			refreshToken:   "rt_unknown",
 			mockSetup:      func(m *mockUserService) {},
 			expectedStatus: http.StatusUnauthorized,
 			checkResponse: func(t *testing.T, body map[string]interface{}) {
@@ -564,6 +569,16 @@ func TestUserHandler_RefreshToken(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "missing refresh token",
+			mockSetup:      func(m *mockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body map[string]interface{}) {
+				if body["success"].(bool) {
+					t.Error("Expected success to be false")
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -573,17 +588,22 @@ func TestUserHandler_RefreshToken(t *testing.T) {
 			tt.mockSetup(mockService)
 
 			// Create handler
+			refreshTokens := service.NewRefreshTokenService(repository.NewMemoryRefreshTokenStore(), mockService, nil, nil, time.Hour)
 			userHandler := handler.NewUserHandler(mockService)
+			userHandler.SetRefreshTokenService(refreshTokens)
 
-			// Create request
-			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", http.NoBody)
-
-			// Add user ID to context if provided
-			if tt.userID != "" {
-				ctx := context.WithValue(req.Context(), "user_id", tt.userID)
-				req = req.WithContext(ctx)
+			refreshToken := tt.refreshToken
+			if tt.issueFor != "" {
+				var err error
+				if refreshToken, _, err = refreshTokens.Issue(context.Background(), tt.issueFor, "", false, domain.SessionClient{}); err != nil {
+					t.Fatalf("Issue failed: %v", err)
+				}
 			}
 
+			// Create request
+			body, _ := json.Marshal(domain.RefreshRequest{RefreshToken: refreshToken})
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+
 			// Create response recorder
 			rr := httptest.NewRecorder()
 
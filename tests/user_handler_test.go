@@ -17,14 +17,19 @@ import (
 
 // mockUserService implements domain.UserService for testing
 type mockUserService struct {
-	registerFunc      func(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error)
-	loginFunc         func(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error)
-	getProfileFunc    func(ctx context.Context, userID string) (*domain.UserResponse, error)
-	updateProfileFunc func(ctx context.Context, userID string, req *domain.UpdateUserRequest) (*domain.UserResponse, error)
-	getUsersFunc      func(ctx context.Context, limit, offset int) ([]*domain.UserResponse, int64, error)
-	getUserByIDFunc   func(ctx context.Context, id string) (*domain.UserResponse, error)
-	deleteUserFunc    func(ctx context.Context, id string) error
-	refreshTokenFunc  func(ctx context.Context, userID string) (string, error)
+	registerFunc       func(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error)
+	loginFunc          func(ctx context.Context, req *domain.LoginRequest) (string, *domain.UserResponse, error)
+	getProfileFunc     func(ctx context.Context, userID string) (*domain.UserResponse, error)
+	updateProfileFunc  func(ctx context.Context, userID string, req *domain.UpdateUserRequest) (*domain.UserResponse, error)
+	changePasswordFunc func(ctx context.Context, userID string, req *domain.ChangePasswordRequest) error
+	getUsersFunc       func(ctx context.Context, limit, offset int) ([]*domain.UserResponse, int64, error)
+	getUserByIDFunc    func(ctx context.Context, id string) (*domain.UserResponse, error)
+	deleteUserFunc     func(ctx context.Context, id string) error
+	refreshTokenFunc   func(ctx context.Context, userID string) (string, error)
+	updateUserFunc     func(ctx context.Context, actorID, id string, req *domain.UpdateUserRequest) (*domain.UserResponse, error)
+	bulkActionFunc     func(ctx context.Context, actorID string, req *domain.BulkUserActionRequest) ([]domain.BulkItemResult, error)
+	getUserStatsFunc   func(ctx context.Context) (*domain.UserStats, error)
+	searchUsersFunc    func(ctx context.Context, query *domain.UserQuery) ([]*domain.UserResponse, int64, error)
 }
 
 func (m *mockUserService) Register(ctx context.Context, req *domain.CreateUserRequest) (*domain.UserResponse, error) {
@@ -48,7 +53,14 @@ func (m *mockUserService) UpdateProfile(ctx context.Context, userID string, req
 	return nil, fmt.Errorf("not implemented")
 }
 
-func (m *mockUserService) GetUsers(ctx context.Context, limit, offset int) ([]*domain.UserResponse, int64, error) {
+func (m *mockUserService) ChangePassword(ctx context.Context, userID string, req *domain.ChangePasswordRequest) error {
+	if m.changePasswordFunc != nil {
+		return m.changePasswordFunc(ctx, userID, req)
+	}
+	return fmt.Errorf("not implemented")
+}
+
+func (m *mockUserService) GetUsers(ctx context.Context, limit, offset int, fields ...string) ([]*domain.UserResponse, int64, error) {
 	if m.getUsersFunc != nil {
 		return m.getUsersFunc(ctx, limit, offset)
 	}
@@ -76,6 +88,38 @@ func (m *mockUserService) RefreshToken(ctx context.Context, userID string) (stri
 	return "", fmt.Errorf("not implemented")
 }
 
+func (m *mockUserService) UpdateUser(ctx context.Context, actorID, id string, req *domain.UpdateUserRequest) (*domain.UserResponse, error) {
+	if m.updateUserFunc != nil {
+		return m.updateUserFunc(ctx, actorID, id, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockUserService) BulkUserAction(ctx context.Context, actorID string, req *domain.BulkUserActionRequest) ([]domain.BulkItemResult, error) {
+	if m.bulkActionFunc != nil {
+		return m.bulkActionFunc(ctx, actorID, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockUserService) SetUserStatus(ctx context.Context, actorID, id string, req *domain.SetUserStatusRequest) (*domain.UserResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockUserService) GetUserStats(ctx context.Context) (*domain.UserStats, error) {
+	if m.getUserStatsFunc != nil {
+		return m.getUserStatsFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockUserService) SearchUsers(ctx context.Context, query *domain.UserQuery) ([]*domain.UserResponse, int64, error) {
+	if m.searchUsersFunc != nil {
+		return m.searchUsersFunc(ctx, query)
+	}
+	return nil, 0, fmt.Errorf("not implemented")
+}
+
 func (m *mockUserService) GetProfile(ctx context.Context, userID string) (*domain.UserResponse, error) {
 	if m.getProfileFunc != nil {
 		return m.getProfileFunc(ctx, userID)
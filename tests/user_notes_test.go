@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestUserNotes(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	user := &domain.User{Name: "Noted User", Email: "noted@example.com", Password: "hash", Role: "user"}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	notes := repository.NewMemoryNoteRepository()
+	svc := service.NewNoteService(notes, users)
+
+	if _, err := svc.AddNote(ctx, "admin-1", "missing", &domain.NoteRequest{Body: "hello"}); err != domain.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for an unknown user, got %v", err)
+	}
+	if _, err := svc.AddNote(ctx, "admin-1", user.ID, &domain.NoteRequest{Body: "  "}); err == nil {
+		t.Error("Expected an empty note to be rejected")
+	}
+
+	note, err := svc.AddNote(ctx, "admin-1", user.ID, &domain.NoteRequest{Body: "Asked for a refund"})
+	if err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if note.AuthorID != "admin-1" {
+		t.Errorf("Expected author admin-1, got %q", note.AuthorID)
+	}
+
+	edited, err := svc.UpdateNote(ctx, "mod-2", user.ID, note.ID, &domain.NoteRequest{Body: "Refund issued"})
+	if err != nil {
+		t.Fatalf("UpdateNote failed: %v", err)
+	}
+	if edited.Body != "Refund issued" || edited.EditedBy != "mod-2" || edited.AuthorID != "admin-1" {
+		t.Errorf("Unexpected edited note: %+v", edited)
+	}
+	if len(edited.History) != 1 || edited.History[0].Body != "Asked for a refund" || edited.History[0].EditedBy != "admin-1" {
+		t.Errorf("Expected the original text in the history, got %+v", edited.History)
+	}
+	if _, err := svc.UpdateNote(ctx, "mod-2", "other", note.ID, &domain.NoteRequest{Body: "x"}); err != domain.ErrNoteNotFound {
+		t.Errorf("Expected ErrNoteNotFound through another user, got %v", err)
+	}
+
+	// Notes travel with the account in export bundles
+	cfg := config.TransferConfig{SigningKey: "shared-secret", BundleTTL: time.Hour, MaxUsers: 10}
+	source := service.NewTransferService(users, repository.NewMemoryPreferencesRepository(), notes, nil, nil, cfg)
+	bundle, err := source.Export(ctx, "admin-1", &domain.ExportUsersRequest{IDs: []string{user.ID}})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	targetNotes := repository.NewMemoryNoteRepository()
+	target := service.NewTransferService(
+		repository.NewMemoryUserRepository(), repository.NewMemoryPreferencesRepository(), targetNotes, nil, nil, cfg,
+	)
+	if _, err := target.Import(ctx, "admin-1", &domain.ImportUsersRequest{Bundle: bundle}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	imported, _ := targetNotes.ListByUser(ctx, user.ID)
+	if len(imported) != 1 || imported[0].Body != "Refund issued" || len(imported[0].History) != 1 {
+		t.Errorf("Expected the note and its history to be imported, got %+v", imported)
+	}
+}
@@ -0,0 +1,141 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"demo-go/internal/domain"
+	"demo-go/internal/repository"
+	"demo-go/internal/service"
+)
+
+func TestParseUserQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		rawQuery      string
+		expectError   bool
+		expectFilters int
+		expectSort    []domain.SortField
+	}{
+		{
+			name:     "plain pagination",
+			rawQuery: "limit=5&offset=10",
+		},
+		{
+			name:          "filters and sort",
+			rawQuery:      "filter[role]=admin&filter[created_at][gte]=2025-01-01&sort=-created_at,name",
+			expectFilters: 2,
+			expectSort:    []domain.SortField{{Field: "created_at", Desc: true}, {Field: "name"}},
+		},
+		{
+			name:          "in operator",
+			rawQuery:      "filter[role][in]=admin,user",
+			expectFilters: 1,
+		},
+		{
+			name:        "unknown filter field",
+			rawQuery:    "filter[password]=secret",
+			expectError: true,
+		},
+		{
+			name:        "operator not valid for field",
+			rawQuery:    "filter[role][gte]=admin",
+			expectError: true,
+		},
+		{
+			name:        "invalid date",
+			rawQuery:    "filter[created_at][lt]=yesterday",
+			expectError: true,
+		},
+		{
+			name:        "unknown sort field",
+			rawQuery:    "sort=-password",
+			expectError: true,
+		},
+		{
+			name:     "total opt-out",
+			rawQuery: "include_total=false",
+		},
+		{
+			name:        "invalid include_total",
+			rawQuery:    "include_total=maybe",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.rawQuery)
+			if err != nil {
+				t.Fatalf("Failed to parse raw query: %v", err)
+			}
+
+			query, err := domain.ParseUserQuery(values)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected validation error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(query.Filters) != tt.expectFilters {
+				t.Errorf("Expected %d filters, got %d", tt.expectFilters, len(query.Filters))
+			}
+			if len(query.Sort) != len(tt.expectSort) {
+				t.Fatalf("Expected %d sort fields, got %d", len(tt.expectSort), len(query.Sort))
+			}
+			for i, sf := range tt.expectSort {
+				if query.Sort[i] != sf {
+					t.Errorf("Expected sort %v at %d, got %v", sf, i, query.Sort[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchUsersPagination(t *testing.T) {
+	ctx := context.Background()
+	userService := service.NewUserService(
+		repository.NewMemoryUserRepository(),
+		service.NewOpaqueTokenService(repository.NewMemoryTokenStore(), time.Hour),
+		service.WithMaxPageLimit(2),
+	)
+	for i := 0; i < 3; i++ {
+		if _, err := userService.Register(ctx, &domain.CreateUserRequest{
+			Name: "Page Tester", Email: fmt.Sprintf("page%d@example.com", i), Password: "password123",
+		}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	users, total, err := userService.SearchUsers(ctx, &domain.UserQuery{Limit: 50})
+	if err != nil {
+		t.Fatalf("SearchUsers failed: %v", err)
+	}
+	if len(users) != 2 || total != 3 {
+		t.Errorf("Expected 2 of 3 users under the page cap, got %d of %d", len(users), total)
+	}
+
+	// Without a count the total only tells whether another page follows
+	users, total, err = userService.SearchUsers(ctx, &domain.UserQuery{Limit: 2, SkipTotal: true})
+	if err != nil {
+		t.Fatalf("SearchUsers failed: %v", err)
+	}
+	if len(users) != 2 || total <= int64(len(users)) {
+		t.Errorf("Expected a full first page with more to follow, got %d users and total %d", len(users), total)
+	}
+
+	users, total, err = userService.SearchUsers(ctx, &domain.UserQuery{Limit: 2, Offset: 2, SkipTotal: true})
+	if err != nil {
+		t.Fatalf("SearchUsers failed: %v", err)
+	}
+	if len(users) != 1 || total != 3 {
+		t.Errorf("Expected a last page of 1 user and no more, got %d users and total %d", len(users), total)
+	}
+}
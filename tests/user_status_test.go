@@ -0,0 +1,177 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+func TestUserStatusLifecycle(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRoleCheckService(service.NewRoleCheckService(userRepo, time.Nanosecond))
+	server := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	admin := &domain.User{Name: "Admin", Email: "admin@example.com", Role: "admin"}
+	_ = userRepo.Create(ctx, admin)
+	adminToken, _ := tokenService.GenerateToken(admin)
+	user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Member", Email: "member@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	login := func() *httptest.ResponseRecorder {
+		return do(http.MethodPost, "/auth/login", "", `{"email":"member@example.com","password":"password123"}`)
+	}
+	errorCode := func(rec *httptest.ResponseRecorder) string {
+		var body struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return body.Error.Code
+	}
+	statusPath := func(action string) string { return "/api/v1/admin/users/" + user.ID + "/" + action }
+
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: user.ID, Email: user.Email, Role: user.Role})
+
+	if rec := do(http.MethodPost, statusPath("suspend"), adminToken, `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a suspension without a reason to be rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/users/"+admin.ID+"/suspend", adminToken, `{"reason":"testing"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected admins to be refused suspending themselves, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodPost, statusPath("suspend"), adminToken, `{"reason":"Spam reports"}`)
+	var suspended struct {
+		Data domain.UserResponse `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &suspended)
+	if rec.Code != http.StatusOK || suspended.Data.Status != domain.UserStatusSuspended || suspended.Data.StatusReason != "Spam reports" || suspended.Data.StatusChangedAt == nil {
+		t.Fatalf("Expected the user to be suspended with the reason, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Suspended users can neither log in nor use the tokens they hold
+	if rec := login(); rec.Code != http.StatusForbidden || errorCode(rec) != domain.ErrAccountSuspended.Code {
+		t.Errorf("Expected the login of a suspended user to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/profile", userToken, ""); rec.Code != http.StatusForbidden || errorCode(rec) != domain.ErrAccountSuspended.Code {
+		t.Errorf("Expected the token of a suspended user to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Lifting the suspension restores access and clears the reason
+	rec = do(http.MethodPost, statusPath("unsuspend"), adminToken, "")
+	var reinstated struct {
+		Data domain.UserResponse `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &reinstated)
+	if rec.Code != http.StatusOK || reinstated.Data.Status != domain.UserStatusActive || reinstated.Data.StatusReason != "" {
+		t.Fatalf("Expected the suspension to be lifted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := login(); rec.Code != http.StatusOK {
+		t.Errorf("Expected the reinstated user to log in, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Bans cannot be lifted
+	if rec := do(http.MethodPost, statusPath("ban"), adminToken, `{"reason":"Fraud"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the user to be banned, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := login(); rec.Code != http.StatusForbidden || errorCode(rec) != domain.ErrAccountBanned.Code {
+		t.Errorf("Expected the login of a banned user to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, statusPath("unsuspend"), adminToken, ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected banned users to stay banned, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, statusPath("suspend"), userToken, `{"reason":"x"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected users to be refused changing statuses, got %d", rec.Code)
+	}
+}
+
+func TestBulkSuspend(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(userRepo, tokenService)
+	revocations := service.NewTokenRevocationService(repository.NewMemoryTokenRevocationStore(), userRepo, nil, time.Hour)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware.SetRevocationService(revocations)
+	userHandler := handler.NewUserHandler(userService)
+	userHandler.SetTokenRevocationService(revocations)
+	server := routes.NewRouter(userHandler, jwtMiddleware, logger.GetGlobal()).SetupRoutes()
+
+	admin := &domain.User{Name: "Admin", Email: "admin@example.com", Role: "admin"}
+	_ = userRepo.Create(ctx, admin)
+	adminToken, _ := tokenService.GenerateToken(admin)
+	member, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Member", Email: "member@example.com", Password: "password123"})
+	banned, _ := userService.Register(ctx, &domain.CreateUserRequest{Name: "Banned", Email: "banned@example.com", Password: "password123"})
+	if _, err := userService.SetUserStatus(ctx, admin.ID, banned.ID, &domain.SetUserStatusRequest{Status: domain.UserStatusBanned, Reason: "Fraud"}); err != nil {
+		t.Fatalf("SetUserStatus failed: %v", err)
+	}
+	memberToken, _, err := userService.Login(ctx, &domain.LoginRequest{Email: "member@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+	ids := `"ids":["` + member.ID + `","` + banned.ID + `"]`
+
+	if rec := do(http.MethodPost, "/api/v1/admin/users/bulk", adminToken, `{`+ids+`,"action":"suspend"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a bulk suspension without a reason to be rejected, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodPost, "/api/v1/admin/users/bulk", adminToken, `{`+ids+`,"action":"suspend","reason":"Spam wave"}`)
+	var body struct {
+		Data struct {
+			Results []domain.BulkItemResult `json:"results"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if rec.Code != http.StatusOK || len(body.Data.Results) != 2 || !body.Data.Results[0].Success || body.Data.Results[1].Error != domain.ErrUserBanned.Code {
+		t.Fatalf("Expected the member to be suspended and the banned user to be skipped, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The suspension is the same as suspending one user
+	if stored, _ := userRepo.GetByID(ctx, member.ID); stored.Status != domain.UserStatusSuspended || stored.StatusReason != "Spam wave" || stored.StatusChangedAt == nil {
+		t.Errorf("Expected the member to be suspended with the reason, got %+v", stored)
+	}
+	if rec := do(http.MethodGet, "/api/v1/profile", memberToken, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the tokens of bulk-suspended users to be revoked, got %d", rec.Code)
+	}
+	if stored, _ := userRepo.GetByID(ctx, banned.ID); stored.Status != domain.UserStatusBanned || stored.StatusReason != "Fraud" {
+		t.Errorf("Expected the banned user to stay banned, got %+v", stored)
+	}
+}
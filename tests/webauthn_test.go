@@ -0,0 +1,243 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo-go/internal/config"
+	"demo-go/internal/domain"
+	"demo-go/internal/handler"
+	"demo-go/internal/logger"
+	"demo-go/internal/middleware"
+	"demo-go/internal/repository"
+	"demo-go/internal/routes"
+	"demo-go/internal/service"
+)
+
+// testAuthenticator is a software ES256 passkey
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return &testAuthenticator{key: key, credentialID: []byte("test-credential-1")}
+}
+
+var b64 = base64.RawURLEncoding
+
+// cborBytes encodes a CBOR byte string of up to 65535 bytes
+func cborBytes(b []byte) []byte {
+	switch {
+	case len(b) < 24:
+		return append([]byte{0x40 | byte(len(b))}, b...)
+	case len(b) < 256:
+		return append([]byte{0x58, byte(len(b))}, b...)
+	}
+	return append([]byte{0x59, byte(len(b) >> 8), byte(len(b))}, b...)
+}
+
+func (a *testAuthenticator) coseKey() []byte {
+	x, y := make([]byte, 32), make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	// {1: 2 (EC2), 3: -7 (ES256), -1: 1 (P-256), -2: x, -3: y}
+	key := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21}
+	key = append(key, cborBytes(x)...)
+	key = append(key, 0x22)
+	return append(key, cborBytes(y)...)
+}
+
+func (a *testAuthenticator) authData(flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // aaguid
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func clientDataJSON(ceremony, challenge, origin string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"type": ceremony, "challenge": challenge, "origin": origin})
+	return data
+}
+
+// create answers navigator.credentials.create with "none" attestation
+func (a *testAuthenticator) create(challenge string) domain.WebAuthnCredentialResponse {
+	// {"fmt": "none", "attStmt": {}, "authData": ...}
+	object := []byte{0xa3, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e', 0x67, 'a', 't', 't', 'S', 't', 'm', 't', 0xa0, 0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a'}
+	object = append(object, cborBytes(a.authData(0x45, true))...)
+
+	var credential domain.WebAuthnCredentialResponse
+	credential.ID = b64.EncodeToString(a.credentialID)
+	credential.Type = "public-key"
+	credential.Response.ClientDataJSON = b64.EncodeToString(clientDataJSON("webauthn.create", challenge, "http://localhost:8080"))
+	credential.Response.AttestationObject = b64.EncodeToString(object)
+	return credential
+}
+
+// get answers navigator.credentials.get, signing with the given origin
+func (a *testAuthenticator) get(t *testing.T, challenge, origin, userID string) domain.WebAuthnCredentialResponse {
+	a.signCount++
+	authData := a.authData(0x05, false)
+	clientData := clientDataJSON("webauthn.get", challenge, origin)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("SignASN1 failed: %v", err)
+	}
+
+	var credential domain.WebAuthnCredentialResponse
+	credential.ID = b64.EncodeToString(a.credentialID)
+	credential.Type = "public-key"
+	credential.Response.ClientDataJSON = b64.EncodeToString(clientData)
+	credential.Response.AuthenticatorData = b64.EncodeToString(authData)
+	credential.Response.Signature = b64.EncodeToString(signature)
+	credential.Response.UserHandle = b64.EncodeToString([]byte(userID))
+	return credential
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	ctx := context.Background()
+	tokenService := service.NewJWTTokenService(&config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiration: time.Hour}})
+	userRepo := repository.NewMemoryUserRepository()
+	auditService := service.NewAuditService(repository.NewMemoryAuditRepository())
+	userService := service.NewUserService(userRepo, tokenService)
+	webAuthnService := service.NewWebAuthnService(userRepo, repository.NewMemoryWebAuthnSessionStore(), tokenService, auditService, config.WebAuthnConfig{
+		RPID: "localhost", RPName: "demo-go", Origins: []string{"http://localhost:8080"},
+		UserVerification: "required", ChallengeTTL: time.Minute,
+	})
+
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	router := routes.NewRouter(handler.NewUserHandler(userService), jwtMiddleware, logger.GetGlobal())
+	router.AddRouteGroup("Passkey Routes", routes.NewWebAuthnRoutes(handler.NewWebAuthnHandler(webAuthnService, nil)))
+	server := router.SetupRoutes()
+
+	user, err := userService.Register(ctx, &domain.CreateUserRequest{Name: "Passkey User", Email: "passkey@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	userToken, _ := tokenService.GenerateToken(&domain.User{ID: user.ID, Email: user.Email, Role: "user"})
+
+	send := func(method, path, token string, body interface{}) (int, json.RawMessage) {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Data
+	}
+	challenge := func(data json.RawMessage) string {
+		var options struct {
+			PublicKey struct {
+				Challenge string `json:"challenge"`
+			} `json:"publicKey"`
+		}
+		_ = json.Unmarshal(data, &options)
+		return options.PublicKey.Challenge
+	}
+
+	// Registration
+	authenticator := newTestAuthenticator(t)
+	status, data := send(http.MethodPost, "/api/v1/profile/passkeys/register/begin", userToken, nil)
+	if status != http.StatusOK || challenge(data) == "" {
+		t.Fatalf("Expected registration options, got %d: %s", status, data)
+	}
+	registration := domain.FinishPasskeyRegistrationRequest{Name: "Laptop", Credential: authenticator.create(challenge(data))}
+	if status, data := send(http.MethodPost, "/api/v1/profile/passkeys/register/finish", userToken, registration); status != http.StatusCreated {
+		t.Fatalf("Expected the passkey to be registered, got %d: %s", status, data)
+	}
+	if status, _ := send(http.MethodPost, "/api/v1/profile/passkeys/register/finish", userToken, registration); status != http.StatusUnauthorized {
+		t.Errorf("Expected a replayed registration to be refused, got %d", status)
+	}
+
+	// Login with the passkey
+	login := func(email, origin string) domain.FinishPasskeyLoginRequest {
+		status, data := send(http.MethodPost, "/auth/passkeys/login/begin", "", domain.BeginPasskeyLoginRequest{Email: email})
+		if status != http.StatusOK {
+			t.Fatalf("Expected login options, got %d", status)
+		}
+		return domain.FinishPasskeyLoginRequest{Credential: authenticator.get(t, challenge(data), origin, user.ID)}
+	}
+	assertion := login("passkey@example.com", "http://localhost:8080")
+	status, data = send(http.MethodPost, "/auth/passkeys/login/finish", "", assertion)
+	var session struct {
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(data, &session)
+	if status != http.StatusOK || session.Token == "" {
+		t.Fatalf("Expected the passkey login to succeed, got %d: %s", status, data)
+	}
+	if status, _ := send(http.MethodGet, "/api/v1/profile", session.Token, nil); status != http.StatusOK {
+		t.Errorf("Expected the passkey token to authenticate, got %d", status)
+	}
+
+	// Discoverable login without an email, identified by the user handle
+	if status, data := send(http.MethodPost, "/auth/passkeys/login/finish", "", login("", "http://localhost:8080")); status != http.StatusOK {
+		t.Errorf("Expected a discoverable passkey login to succeed, got %d: %s", status, data)
+	}
+
+	// Replays, other origins and counters that do not grow are refused
+	if status, _ := send(http.MethodPost, "/auth/passkeys/login/finish", "", assertion); status != http.StatusUnauthorized {
+		t.Errorf("Expected a replayed assertion to be refused, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/auth/passkeys/login/finish", "", login("", "https://evil.example")); status != http.StatusUnauthorized {
+		t.Errorf("Expected an assertion from another origin to be refused, got %d", status)
+	}
+	authenticator.signCount = 0
+	if status, _ := send(http.MethodPost, "/auth/passkeys/login/finish", "", login("", "http://localhost:8080")); status != http.StatusUnauthorized {
+		t.Errorf("Expected an assertion with a stale counter to be refused, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/auth/passkeys/login/finish", "", login("other@example.com", "http://localhost:8080")); status != http.StatusUnauthorized {
+		t.Errorf("Expected a login naming another account to be refused, got %d", status)
+	}
+
+	// Listing and removal
+	status, data = send(http.MethodGet, "/api/v1/profile/passkeys", userToken, nil)
+	var listing struct {
+		Passkeys []domain.WebAuthnCredential `json:"passkeys"`
+	}
+	_ = json.Unmarshal(data, &listing)
+	if status != http.StatusOK || len(listing.Passkeys) != 1 || listing.Passkeys[0].Name != "Laptop" || listing.Passkeys[0].LastUsedAt.IsZero() {
+		t.Fatalf("Unexpected passkey listing %d: %s", status, data)
+	}
+	path := "/api/v1/profile/passkeys/" + listing.Passkeys[0].ID
+	if status, _ := send(http.MethodDelete, path, userToken, nil); status != http.StatusOK {
+		t.Errorf("Expected the passkey to be removed, got %d", status)
+	}
+	if status, _ := send(http.MethodDelete, path, userToken, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a removed passkey, got %d", status)
+	}
+	authenticator.signCount = 10
+	if status, _ := send(http.MethodPost, "/auth/passkeys/login/finish", "", login("", "http://localhost:8080")); status != http.StatusUnauthorized {
+		t.Errorf("Expected a removed passkey to be refused, got %d", status)
+	}
+}